@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/terrascope/core/internal/analysis"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+// analyzeUsage describes the analyze subcommand.
+const analyzeUsage = "usage: terrascope analyze [--analyzers LIST] [--format text|json|sarif|junit] [--fail-on SEVERITY] [--baseline STATE] STATE"
+
+// errGateFailed is wrapped by the errors of the subcommands whose result
+// crossed the threshold a pipeline set, which exit with status 1 rather than
+// the status 2 of other errors.
+var errGateFailed = errors.New("gate failed")
+
+// exitStatus is the exit status of a subcommand that returned err.
+func exitStatus(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errGateFailed):
+		return 1
+	}
+
+	return 2
+}
+
+// exitCommand prints the error of a subcommand, if any, and exits with its
+// status.
+func exitCommand(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	os.Exit(exitStatus(err))
+}
+
+// analyze runs the analyze subcommand: it runs the analyzers named by
+// --analyzers, every analyzer by default, over a state file and prints their
+// findings. With --fail-on it fails the gate when a finding is at or above
+// that severity. With --baseline it only prints, and gates on, the findings
+// that the baseline state file, usually an earlier version of the state, did
+// not have.
+func analyze(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	list := flags.String("analyzers", "", "")
+	format := flags.String("format", "text", "")
+	failOn := flags.String("fail-on", "", "")
+	baseline := flags.String("baseline", "", "")
+
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		return errors.New(analyzeUsage)
+	}
+
+	if *format != "text" && *format != "json" && *format != "sarif" && *format != "junit" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	threshold := ""
+	if *failOn != "" {
+		var err error
+		if threshold, err = analysis.ParseSeverity(*failOn); err != nil {
+			return err
+		}
+	}
+
+	selected, err := analysis.SelectAnalyzers(*list)
+	if err != nil {
+		return err
+	}
+
+	path := flags.Arg(0)
+
+	state, err := readStateFile(path)
+	if err != nil {
+		return err
+	}
+
+	graph := parser.BuildGraph(state)
+	findings := analysis.RunAnalyzers(selected, graph, state)
+
+	if *baseline != "" {
+		previous, err := readStateFile(*baseline)
+		if err != nil {
+			return err
+		}
+
+		known := analysis.RunAnalyzers(selected, parser.BuildGraph(previous), previous)
+		findings, _ = analysis.NewFindings(findings, known)
+	}
+
+	if err := writeFindings(out, *format, path, selected, findings, threshold); err != nil {
+		return err
+	}
+
+	if threshold == "" {
+		return nil
+	}
+
+	failed := 0
+	for _, finding := range findings {
+		if analysis.AtLeast(finding.Severity, threshold) {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%w: %d findings at or above %s", errGateFailed, failed, threshold)
+	}
+
+	return nil
+}
+
+// writeFindings prints the findings in the given format. The JUnit report
+// fails the findings at or above threshold, every finding when it is empty.
+func writeFindings(out io.Writer, format, path string, selected []analysis.Analyzer, findings []models.Finding, threshold string) error {
+	switch format {
+	case "json":
+		return json.NewEncoder(out).Encode(findings)
+	case "sarif":
+		return json.NewEncoder(out).Encode(analysis.SARIF(findings, path))
+	case "junit":
+		if threshold == "" {
+			threshold = models.SeverityInfo
+		}
+
+		names := make([]string, len(selected))
+		for i, analyzer := range selected {
+			names[i] = analyzer.Name()
+		}
+
+		body, err := xml.MarshalIndent(analysis.JUnit(names, findings, threshold), "", "  ")
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(out, "%s%s\n", xml.Header, body)
+		return err
+	}
+
+	for _, finding := range findings {
+		address := finding.Address
+		if address == "" {
+			address = finding.NodeID
+		}
+
+		if _, err := fmt.Fprintf(out, "%-8s %s %s/%s: %s\n", finding.Severity, address, finding.Analyzer, finding.Check, finding.Message); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(out, "%d findings\n", len(findings))
+	return err
+}
+
+// readStateFile reads and parses the state file at path.
+func readStateFile(path string) (*models.TerraformState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := parser.ParseTfstate(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return state, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// writeStateFile writes a state with a tainted, public S3 bucket of the given
+// name to a temporary file and returns its path.
+func writeStateFile(t *testing.T, buckets ...string) string {
+	t.Helper()
+
+	resources := []map[string]any{}
+	for _, name := range buckets {
+		resources = append(resources, map[string]any{
+			"mode": "managed", "type": "aws_s3_bucket", "name": name,
+			"provider": `provider["registry.terraform.io/hashicorp/aws"]`,
+			"instances": []map[string]any{{
+				"status":     "tainted",
+				"attributes": map[string]any{"id": name, "acl": "public-read", "tags": map[string]any{"env": "prod"}},
+			}},
+		})
+	}
+
+	data, err := json.Marshal(map[string]any{"version": 4, "terraform_version": "1.5.0", "serial": 1, "lineage": "cli", "resources": resources})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "terraform.tfstate")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	return path
+}
+
+func TestAnalyze(t *testing.T) {
+	path := writeStateFile(t, "site")
+
+	t.Run("prints the findings", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, analyze([]string{"--analyzers", "security,stale", path}, &out))
+
+		assert.Equal(t, "error    aws_s3_bucket.site security/public_bucket: the bucket ACL public-read grants public access\n"+
+			"error    aws_s3_bucket.site stale/tainted: the instance is tainted and will be replaced on the next apply\n"+
+			"2 findings\n", out.String())
+	})
+
+	t.Run("fails the gate at or above the severity", func(t *testing.T) {
+		err := analyze([]string{"--analyzers", "security", "--fail-on", "high", path}, &bytes.Buffer{})
+		assert.ErrorIs(t, err, errGateFailed)
+		assert.EqualError(t, err, "gate failed: 1 findings at or above error")
+		assert.Equal(t, 1, exitStatus(err))
+
+		assert.NoError(t, analyze([]string{"--analyzers", "security", "--fail-on", "critical", path}, &bytes.Buffer{}))
+	})
+
+	t.Run("prints SARIF", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, analyze([]string{"--analyzers", "security", "--format", "sarif", path}, &out))
+
+		var log models.SarifLog
+		require.NoError(t, json.Unmarshal(out.Bytes(), &log))
+		require.Len(t, log.Runs[0].Results, 1)
+		assert.Equal(t, path, log.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	})
+
+	t.Run("prints JUnit XML", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, analyze([]string{"--analyzers", "security", "--format", "junit", path}, &out))
+		assert.Contains(t, out.String(), `<testsuite name="security" tests="1" failures="1">`)
+	})
+
+	t.Run("gates only on the findings missing from the baseline", func(t *testing.T) {
+		changed := writeStateFile(t, "site", "logs")
+
+		var out bytes.Buffer
+		err := analyze([]string{"--analyzers", "security", "--fail-on", "error", "--baseline", path, changed}, &out)
+		assert.EqualError(t, err, "gate failed: 1 findings at or above error")
+		assert.Equal(t, "error    aws_s3_bucket.logs security/public_bucket: the bucket ACL public-read grants public access\n"+
+			"1 findings\n", out.String())
+
+		assert.NoError(t, analyze([]string{"--analyzers", "security", "--fail-on", "error", "--baseline", changed, path}, &bytes.Buffer{}))
+	})
+
+	t.Run("rejects invalid arguments", func(t *testing.T) {
+		for _, args := range [][]string{nil, {"--fail-on"}, {"--baseline"}, {path, path}, {"--verbose", path}} {
+			assert.EqualError(t, analyze(args, &bytes.Buffer{}), analyzeUsage, "args %q", args)
+		}
+
+		assert.EqualError(t, analyze([]string{"--format", "csv", path}, &bytes.Buffer{}), `unknown format "csv"`)
+		assert.ErrorContains(t, analyze([]string{"--fail-on", "severe", path}, &bytes.Buffer{}), `unknown severity "severe"`)
+		assert.ErrorContains(t, analyze([]string{"--analyzers", "lint", path}, &bytes.Buffer{}), `unknown analyzer "lint"`)
+
+		err := analyze([]string{filepath.Join(t.TempDir(), "missing.tfstate")}, &bytes.Buffer{})
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		assert.Equal(t, 2, exitStatus(err))
+
+		err = analyze([]string{"--baseline", filepath.Join(t.TempDir(), "missing.tfstate"), path}, &bytes.Buffer{})
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestExitStatus(t *testing.T) {
+	assert.Equal(t, 0, exitStatus(nil))
+	assert.Equal(t, 1, exitStatus(errGateFailed))
+	assert.Equal(t, 2, exitStatus(errors.New(analyzeUsage)))
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/terrascope/core/internal/diff"
+	"github.com/terrascope/core/internal/models"
+)
+
+// diffUsage describes the diff subcommand.
+const diffUsage = "usage: terrascope diff [--format text|json] [--max-nodes-changed N] [BEFORE] AFTER"
+
+// diffStates runs the diff subcommand: it prints the changes between two
+// state files or, given a single state, since its .backup sibling, which
+// Terraform writes before each change of the state. With --max-nodes-changed
+// it fails the gate when more nodes than that were added, removed or
+// modified.
+func diffStates(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	format := flags.String("format", "text", "")
+	maxChanged := flags.Int("max-nodes-changed", -1, "")
+
+	if err := flags.Parse(args); err != nil || flags.NArg() < 1 || flags.NArg() > 2 {
+		return errors.New(diffUsage)
+	}
+
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	afterPath := flags.Arg(flags.NArg() - 1)
+	beforePath := afterPath + ".backup"
+	if flags.NArg() == 2 {
+		beforePath = flags.Arg(0)
+	}
+
+	before, err := readStateFile(beforePath)
+	if err != nil {
+		return err
+	}
+
+	after, err := readStateFile(afterPath)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() == 1 {
+		if err := diff.CheckBackup(before, after); err != nil {
+			return fmt.Errorf("%s: %w", beforePath, err)
+		}
+	}
+
+	if *format == "json" {
+		if err := json.NewEncoder(out).Encode(diff.States(before, after)); err != nil {
+			return err
+		}
+	}
+
+	changed := 0
+	for _, node := range diff.Graph(before, after).Nodes {
+		if node.Change == models.ChangeUnchanged {
+			continue
+		}
+		changed++
+
+		if *format == "text" {
+			line := node.Change + " " + node.ID
+			if node.RenamedFrom != "" {
+				line += " (renamed from " + node.RenamedFrom + ")"
+			}
+			if _, err := fmt.Fprintln(out, line); err != nil {
+				return err
+			}
+		}
+	}
+
+	if *format == "text" {
+		if _, err := fmt.Fprintf(out, "%d nodes changed\n", changed); err != nil {
+			return err
+		}
+	}
+
+	if *maxChanged >= 0 && changed > *maxChanged {
+		return fmt.Errorf("%w: %d nodes changed, more than %d", errGateFailed, changed, *maxChanged)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestDiffStates(t *testing.T) {
+	before := writeStateFile(t, "site", "logs")
+	after := writeStateFile(t, "site", "assets", "backups")
+
+	t.Run("prints the changed nodes", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, diffStates([]string{before, after}, &out))
+
+		assert.Contains(t, out.String(), "added aws_s3_bucket.backups\n")
+		assert.Contains(t, out.String(), "\n3 nodes changed\n")
+		assert.NotContains(t, out.String(), "aws_s3_bucket.site")
+	})
+
+	t.Run("prints JSON", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, diffStates([]string{"--format", "json", before, after}, &out))
+
+		var stateDiff models.StateDiff
+		require.NoError(t, json.Unmarshal(out.Bytes(), &stateDiff))
+		assert.NotEmpty(t, stateDiff.Added)
+	})
+
+	t.Run("fails the gate above the maximum", func(t *testing.T) {
+		err := diffStates([]string{"--max-nodes-changed", "2", before, after}, &bytes.Buffer{})
+		assert.ErrorIs(t, err, errGateFailed)
+		assert.EqualError(t, err, "gate failed: 3 nodes changed, more than 2")
+
+		assert.NoError(t, diffStates([]string{"--max-nodes-changed", "3", before, after}, &bytes.Buffer{}))
+		assert.NoError(t, diffStates([]string{"--max-nodes-changed", "0", before, before}, &bytes.Buffer{}))
+	})
+
+	t.Run("compares a state with its backup", func(t *testing.T) {
+		data, err := os.ReadFile(before)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(after+".backup", data, 0o600))
+
+		var out bytes.Buffer
+		require.NoError(t, diffStates([]string{after}, &out))
+		assert.Contains(t, out.String(), "\n3 nodes changed\n")
+
+		require.NoError(t, os.WriteFile(after+".backup", bytes.Replace(data, []byte(`"lineage":"cli"`), []byte(`"lineage":"other"`), 1), 0o600))
+		assert.ErrorContains(t, diffStates([]string{after}, &bytes.Buffer{}), `backup lineage "other" differs from state lineage "cli"`)
+
+		assert.ErrorIs(t, diffStates([]string{before}, &bytes.Buffer{}), os.ErrNotExist)
+	})
+
+	t.Run("rejects invalid arguments", func(t *testing.T) {
+		for _, args := range [][]string{nil, {before, after, after}, {"--max-nodes-changed", "many", before, after}} {
+			assert.EqualError(t, diffStates(args, &bytes.Buffer{}), diffUsage, "args %q", args)
+		}
+
+		assert.EqualError(t, diffStates([]string{"--format", "dot", before, after}, &bytes.Buffer{}), `unknown format "dot"`)
+	})
+}
@@ -4,20 +4,190 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"time"
 
 	"github.com/terrascope/core/internal/handlers"
+	"github.com/terrascope/core/internal/web"
 	"github.com/terrascope/core/cmd/api/middlewares"
 )
 
-func main() {
+// apiPrefix is the path prefix of the current API version.
+const apiPrefix = "/api/v1"
+
+// The unprefixed API paths are deprecated in favour of apiPrefix and will be
+// removed at legacySunset.
+var (
+	legacyDeprecation = time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	legacySunset      = time.Date(2027, 4, 16, 0, 0, 0, 0, time.UTC)
+)
+
+// apiRoutes maps the API paths, relative to apiPrefix, to their handlers and
+// the methods they accept. The routes of stored states, state locks, share
+// links and exports require a login session once login is enabled, and the
+// routes that parse states or run analyses count against the quota of the
+// request's project. The results of diffs and of the analyses that only depend
+// on their request are cached, and analyses of stored versions are announced
+// to the clients following their lineage.
+var apiRoutes = []struct {
+	path    string
+	handler http.HandlerFunc
+	methods []string
+}{
+	{"/health", handlers.HealthHandler, []string{http.MethodGet}},
+	{"/auth/login", handlers.LoginHandler, []string{http.MethodPost}},
+	{"/auth/logout", handlers.LogoutHandler, []string{http.MethodPost}},
+	{"/auth/session", handlers.SessionHandler, []string{http.MethodGet}},
+	{"/auth/accept", handlers.AcceptInvitationHandler, []string{http.MethodPost}},
+	{"/parse", handlers.MeterParses(handlers.ParseHandler), []string{http.MethodPost}},
+	{"/schemas", handlers.SchemasHandler, []string{http.MethodPost}},
+	{"/schemas/{name}", handlers.JSONSchemaHandler, []string{http.MethodGet}},
+	{"/validate", handlers.ValidateHandler, []string{http.MethodPost}},
+	{"/sanitize", handlers.SanitizeHandler, []string{http.MethodPost}},
+	{"/demo/state", handlers.DemoStateHandler, []string{http.MethodGet}},
+	{"/diff", handlers.MeterParses(handlers.CacheResults(handlers.DiffHandler)), []string{http.MethodPost}},
+	{"/diff/backup", handlers.MeterParses(handlers.CacheResults(handlers.BackupDiffHandler)), []string{http.MethodPost}},
+	{"/compare/environments", handlers.RequireSession(handlers.CompareEnvironmentsHandler), []string{http.MethodGet}},
+	{"/export", handlers.RequireSession(handlers.MeterParses(handlers.ExportHandler)), []string{http.MethodPost}},
+	{"/export/servicenow", handlers.RequireSession(handlers.ServiceNowExportHandler), []string{http.MethodGet, http.MethodPost}},
+	{"/graph/subgraph", handlers.SubgraphHandler, []string{http.MethodGet}},
+	{"/graph/plan", handlers.PlanOverlayHandler, []string{http.MethodPost}},
+	{"/graph/search", handlers.RequireSession(handlers.SearchHandler), []string{http.MethodGet}},
+	{"/lookup", handlers.RequireSession(handlers.LookupHandler), []string{http.MethodGet}},
+	{"/shares", handlers.RequireSession(handlers.SharesHandler), []string{http.MethodPost}},
+	{"/shares/{token}", handlers.ShareHandler, []string{http.MethodGet}},
+	{"/locks", handlers.RequireSession(handlers.LocksHandler), []string{http.MethodGet, http.MethodPost, http.MethodDelete}},
+	{"/states", handlers.RequireSession(handlers.StatesHandler), []string{http.MethodGet, http.MethodPost}},
+	{"/states/{lineage}", handlers.RequireSession(handlers.StateHistoryHandler), []string{http.MethodGet, http.MethodDelete}},
+	{"/states/{lineage}/events", handlers.RequireSession(handlers.StateEventsHandler), []string{http.MethodGet}},
+	{"/states/{lineage}/graph", handlers.RequireSession(handlers.StateGraphHandler), []string{http.MethodGet}},
+	{"/states/{lineage}/{serial}", handlers.RequireSession(handlers.StateVersionHandler), []string{http.MethodGet}},
+	{"/states/{lineage}/{serial}/graph", handlers.RequireSession(handlers.StateGraphHandler), []string{http.MethodGet}},
+	{"/uploads", handlers.UploadsHandler, []string{http.MethodPost}},
+	{"/uploads/{id}", handlers.UploadHandler, []string{http.MethodGet, http.MethodDelete}},
+	{"/uploads/{id}/parts/{part}", handlers.UploadPartHandler, []string{http.MethodPut}},
+	{"/uploads/{id}/complete", handlers.UploadCompleteHandler, []string{http.MethodPost}},
+	{"/trash", handlers.RequireSession(handlers.TrashHandler), []string{http.MethodGet}},
+	{"/trash/{lineage}/restore", handlers.RequireSession(handlers.RestoreHandler), []string{http.MethodPost}},
+	{"/alerts", handlers.RequireSession(handlers.AlertsHandler), []string{http.MethodGet}},
+	{"/alerts/rules", handlers.RequireSession(handlers.AlertRulesHandler), []string{http.MethodGet, http.MethodPost}},
+	{"/alerts/rules/{id}", handlers.RequireSession(handlers.AlertRuleHandler), []string{http.MethodGet, http.MethodDelete}},
+	{"/decorators", handlers.RequireSession(handlers.DecoratorsHandler), []string{http.MethodGet, http.MethodPost}},
+	{"/decorators/{name}", handlers.RequireSession(handlers.DecoratorHandler), []string{http.MethodGet, http.MethodDelete}},
+	{"/golden-modules", handlers.RequireSession(handlers.GoldenModulesHandler), []string{http.MethodGet, http.MethodPost}},
+	{"/golden-modules/{name}", handlers.RequireSession(handlers.GoldenModuleHandler), []string{http.MethodGet, http.MethodDelete}},
+	{"/golden-modules/{name}/conformance", handlers.RequireSession(handlers.GoldenConformanceHandler), []string{http.MethodGet}},
+	{"/backstage/entities", handlers.RequireSession(handlers.BackstageEntitiesHandler), []string{http.MethodGet}},
+	{"/usage", handlers.RequireSession(handlers.UsageHandler), []string{http.MethodGet}},
+	{"/metrics/infrastructure", handlers.RequireSession(handlers.InfrastructureMetricsHandler), []string{http.MethodGet}},
+	{"/metrics/cache", handlers.RequireSession(handlers.GraphCacheMetricsHandler), []string{http.MethodGet}},
+	{"/analyze", handlers.MeterJobs(handlers.PublishAnalysis("analyze", handlers.CacheResults(handlers.AnalyzeHandler))), []string{http.MethodPost}},
+	{"/analyzers", handlers.AnalyzersHandler, []string{http.MethodGet}},
+	{"/codes", handlers.CodesHandler, []string{http.MethodGet}},
+	{"/analyze/clusters", handlers.MeterJobs(handlers.PublishAnalysis("clusters", handlers.CacheResults(handlers.ClustersHandler))), []string{http.MethodPost}},
+	{"/analyze/compliance", handlers.MeterJobs(handlers.PublishAnalysis("compliance", handlers.CacheResults(handlers.ComplianceHandler))), []string{http.MethodPost}},
+	{"/analyze/endpoints", handlers.MeterJobs(handlers.PublishAnalysis("endpoints", handlers.CacheResults(handlers.EndpointsHandler))), []string{http.MethodPost}},
+	{"/analyze/import-suggestions", handlers.MeterJobs(handlers.PublishAnalysis("import-suggestions", handlers.CacheResults(handlers.ImportSuggestionsHandler))), []string{http.MethodPost}},
+	{"/analyze/limits", handlers.RequireSession(handlers.MeterJobs(handlers.PublishAnalysis("limits", handlers.LimitsHandler))), []string{http.MethodPost}},
+	{"/analyze/modules", handlers.MeterJobs(handlers.PublishAnalysis("modules", handlers.ModulesHandler)), []string{http.MethodPost}},
+	{"/analyze/module-suggestions", handlers.MeterJobs(handlers.PublishAnalysis("module-suggestions", handlers.CacheResults(handlers.ModuleExtractionsHandler))), []string{http.MethodPost}},
+	{"/analyze/naming", handlers.MeterJobs(handlers.PublishAnalysis("naming", handlers.NamingHandler)), []string{http.MethodPost}},
+	{"/analyze/outputs", handlers.RequireSession(handlers.MeterJobs(handlers.PublishAnalysis("outputs", handlers.OutputsHandler))), []string{http.MethodPost}},
+	{"/analyze/providers", handlers.MeterJobs(handlers.PublishAnalysis("providers", handlers.ProvidersHandler)), []string{http.MethodPost}},
+	{"/analyze/split-suggestions", handlers.MeterJobs(handlers.PublishAnalysis("split-suggestions", handlers.CacheResults(handlers.SplitSuggestionsHandler))), []string{http.MethodPost}},
+	{"/analyze/unmanaged", handlers.RequireSession(handlers.MeterJobs(handlers.UnmanagedHandler)), []string{http.MethodPost}},
+	{"/simulate/destroy", handlers.MeterJobs(handlers.PublishAnalysis("destroy", handlers.CacheResults(handlers.SimulateDestroyHandler))), []string{http.MethodPost}},
+	{"/admin/export", handlers.AdminExportHandler, []string{http.MethodGet}},
+	{"/admin/import", handlers.AdminImportHandler, []string{http.MethodPost}},
+	{"/admin/users", handlers.AdminUsersHandler, []string{http.MethodGet, http.MethodPost}},
+	{"/admin/users/{username}", handlers.AdminUserHandler, []string{http.MethodGet, http.MethodDelete}},
+	{"/admin/users/{username}/role", handlers.AdminUserRoleHandler, []string{http.MethodPut}},
+	{"/admin/keys", handlers.AdminKeysHandler, []string{http.MethodGet, http.MethodPost}},
+	{"/admin/keys/{id}", handlers.AdminKeyHandler, []string{http.MethodGet, http.MethodDelete}},
+	{"/admin/usage", handlers.AdminUsageHandler, []string{http.MethodGet}},
+}
+
+// newRouter serves the API under apiPrefix, and with deprecation headers at
+// the unprefixed paths it was first published on, next to the embedded web
+// UI and its sign-in form. Unknown /api paths are not found rather than
+// routed to the UI.
+func newRouter() *http.ServeMux {
 	mux := http.NewServeMux()
+	deprecated := middlewares.Deprecated(legacyDeprecation, legacySunset, apiPrefix)
+
+	for _, route := range apiRoutes {
+		handler := middlewares.JSONFormat(route.handler)
+		mux.Handle(apiPrefix+route.path, handler)
+		mux.Handle(route.path, deprecated(handler))
+	}
+
+	mux.HandleFunc("/api/", http.NotFound)
+	mux.HandleFunc("/login", handlers.LoginPageHandler)
+	mux.Handle("/", handlers.RequireSession(middlewares.Static(web.Files()).ServeHTTP))
+
+	return mux
+}
+
+// routeMethods returns the methods of the API route a request targets, for
+// the CORS preflight responses.
+func routeMethods(mux *http.ServeMux) func(r *http.Request) []string {
+	methods := make(map[string][]string, 2*len(apiRoutes))
+	for _, route := range apiRoutes {
+		methods[apiPrefix+route.path] = route.methods
+		methods[route.path] = route.methods
+	}
+
+	return func(r *http.Request) []string {
+		_, pattern := mux.Handler(r)
+		return methods[pattern]
+	}
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate(context.Background(), os.Args[2:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "analyze" {
+		exitCommand(analyze(os.Args[2:], os.Stdout))
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		exitCommand(diffStates(os.Args[2:], os.Stdout))
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "watch" {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		err := watch(ctx, os.Args[2:], os.Stdout)
+		stop()
+		exitCommand(err)
+		return
+	}
+
+	if err := handlers.OpenStorage(context.Background()); err != nil {
+		log.Fatalf("Opening storage: %v", err)
+	}
+
+	go handlers.RelayEvents(context.Background())
+	go handlers.ArchiveStates(context.Background())
 
-	mux.HandleFunc("/health", handlers.HealthHandler)
-	mux.HandleFunc("/parse", handlers.ParseHandler)
+	router := newRouter()
 
-	handler := middlewares.Cors(mux)
+	cors := middlewares.CorsPolicyFromEnv()
+	if err := cors.Validate(); err != nil {
+		log.Fatalf("Configuring CORS: %v", err)
+	}
+	cors.Methods = routeMethods(router)
+	handler := middlewares.Recover(cors.Handler(middlewares.CSRF(handlers.TrackAPIKeys(router))))
 
 	log.Printf("🚀 Server starting on 8080")
 	log.Fatal(http.ListenAndServe(":8080", handler))
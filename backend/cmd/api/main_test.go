@@ -17,10 +17,7 @@ import (
 )
 
 func setupRouter() *http.ServeMux {
-	mux := http.NewServeMux()
-	mux.HandleFunc("/health", handlers.HealthHandler)
-	mux.HandleFunc("/parse", handlers.ParseHandler)
-	return mux
+	return newRouter()
 }
 
 func TestMainRoutes(t *testing.T) {
@@ -54,8 +51,8 @@ func TestMainRoutes(t *testing.T) {
 		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
 	})
 
-	t.Run("non-existent route returns 404", func(t *testing.T) {
-		req := httptest.NewRequest(http.MethodGet, "/nonexistent", nil)
+	t.Run("non-existent API route returns 404", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/nonexistent", nil)
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -63,13 +60,14 @@ func TestMainRoutes(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, w.Code)
 	})
 
-	t.Run("root path returns 404", func(t *testing.T) {
+	t.Run("root path serves the web UI", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
 
-		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
 	})
 }
 
@@ -100,6 +98,15 @@ func TestHealthEndpointIntegration(t *testing.T) {
 
 		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
 	})
+	t.Run("health is indented on request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/health?pretty=true", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "{\n  \"status\": \"healthy\"")
+	})
 }
 
 func TestParseEndpointIntegration(t *testing.T) {
@@ -224,11 +231,120 @@ func TestRoutePaths(t *testing.T) {
 	}{
 		{"health with GET", "/health", http.MethodGet, http.StatusOK},
 		{"health with POST", "/health", http.MethodPost, http.StatusMethodNotAllowed},
+		{"login without users", "/auth/login", http.MethodPost, http.StatusServiceUnavailable},
+		{"login with GET", "/auth/login", http.MethodGet, http.StatusMethodNotAllowed},
+		{"logout with POST", "/auth/logout", http.MethodPost, http.StatusNoContent},
+		{"session without login", "/auth/session", http.MethodGet, http.StatusUnauthorized},
+		{"accept invitation with GET", "/auth/accept", http.MethodGet, http.StatusMethodNotAllowed},
 		{"parse with POST", "/parse", http.MethodPost, http.StatusBadRequest},
 		{"parse with GET", "/parse", http.MethodGet, http.StatusMethodNotAllowed},
-		{"unknown path", "/unknown", http.MethodGet, http.StatusNotFound},
-		{"root path", "/", http.MethodGet, http.StatusNotFound},
-		{"health with trailing slash", "/health/", http.MethodGet, http.StatusNotFound},
+		{"validate with POST", "/validate", http.MethodPost, http.StatusUnprocessableEntity},
+		{"validate with GET", "/validate", http.MethodGet, http.StatusMethodNotAllowed},
+		{"sanitize with POST", "/sanitize", http.MethodPost, http.StatusBadRequest},
+		{"sanitize with GET", "/sanitize", http.MethodGet, http.StatusMethodNotAllowed},
+		{"demo state with GET", "/demo/state", http.MethodGet, http.StatusOK},
+		{"demo state with POST", "/demo/state", http.MethodPost, http.StatusMethodNotAllowed},
+		{"diff with POST", "/diff", http.MethodPost, http.StatusBadRequest},
+		{"diff with GET", "/diff", http.MethodGet, http.StatusMethodNotAllowed},
+		{"backup diff with POST", "/diff/backup", http.MethodPost, http.StatusBadRequest},
+		{"backup diff with GET", "/diff/backup", http.MethodGet, http.StatusMethodNotAllowed},
+		{"environment comparison with GET", "/compare/environments", http.MethodGet, http.StatusBadRequest},
+		{"export with GET", "/export", http.MethodGet, http.StatusMethodNotAllowed},
+		{"servicenow export with GET", "/export/servicenow", http.MethodGet, http.StatusOK},
+		{"servicenow export with POST", "/export/servicenow", http.MethodPost, http.StatusServiceUnavailable},
+		{"servicenow export with DELETE", "/export/servicenow", http.MethodDelete, http.StatusMethodNotAllowed},
+		{"subgraph with GET", "/graph/subgraph", http.MethodGet, http.StatusBadRequest},
+		{"subgraph with POST", "/graph/subgraph", http.MethodPost, http.StatusMethodNotAllowed},
+		{"plan overlay with POST", "/graph/plan", http.MethodPost, http.StatusBadRequest},
+		{"plan overlay with GET", "/graph/plan", http.MethodGet, http.StatusMethodNotAllowed},
+		{"search without query", "/graph/search", http.MethodGet, http.StatusBadRequest},
+		{"search with GET", "/graph/search?q=10.0.0.0/16", http.MethodGet, http.StatusOK},
+		{"search with POST", "/graph/search", http.MethodPost, http.StatusMethodNotAllowed},
+		{"lookup without id", "/lookup", http.MethodGet, http.StatusBadRequest},
+		{"lookup of unmanaged id", "/lookup?id=i-0abc123", http.MethodGet, http.StatusNotFound},
+		{"shares with POST", "/shares", http.MethodPost, http.StatusBadRequest},
+		{"shares with GET", "/shares", http.MethodGet, http.StatusMethodNotAllowed},
+		{"unknown share", "/shares/unknown", http.MethodGet, http.StatusNotFound},
+		{"locks with POST", "/locks", http.MethodPost, http.StatusBadRequest},
+		{"locks with GET", "/locks", http.MethodGet, http.StatusBadRequest},
+		{"locks with PUT", "/locks", http.MethodPut, http.StatusMethodNotAllowed},
+		{"states with GET", "/states", http.MethodGet, http.StatusOK},
+		{"states with POST", "/states", http.MethodPost, http.StatusBadRequest},
+		{"state history of unknown lineage", "/states/unknown", http.MethodGet, http.StatusNotFound},
+		{"state version with invalid serial", "/states/unknown/latest", http.MethodGet, http.StatusBadRequest},
+		{"state events with POST", "/states/unknown/events", http.MethodPost, http.StatusMethodNotAllowed},
+		{"graph of unknown lineage", "/states/unknown/graph", http.MethodGet, http.StatusNotFound},
+		{"state graph of unknown version", "/states/unknown/1/graph", http.MethodGet, http.StatusNotFound},
+		{"state graph with POST", "/states/unknown/1/graph", http.MethodPost, http.StatusMethodNotAllowed},
+		{"schemas with POST", "/schemas", http.MethodPost, http.StatusBadRequest},
+		{"schemas with GET", "/schemas", http.MethodGet, http.StatusMethodNotAllowed},
+		{"graph schema with GET", "/schemas/graph", http.MethodGet, http.StatusOK},
+		{"unknown schema with GET", "/schemas/unknown", http.MethodGet, http.StatusNotFound},
+		{"graph schema with POST", "/schemas/graph", http.MethodPost, http.StatusMethodNotAllowed},
+		{"parse with unknown schemas", "/parse?schemas=unknown", http.MethodPost, http.StatusNotFound},
+		{"uploads with POST", "/uploads", http.MethodPost, http.StatusBadRequest},
+		{"uploads with GET", "/uploads", http.MethodGet, http.StatusMethodNotAllowed},
+		{"unknown upload", "/uploads/unknown", http.MethodGet, http.StatusNotFound},
+		{"part of unknown upload", "/uploads/unknown/parts/1", http.MethodPut, http.StatusNotFound},
+		{"part with POST", "/uploads/unknown/parts/1", http.MethodPost, http.StatusMethodNotAllowed},
+		{"completion of unknown upload", "/uploads/unknown/complete", http.MethodPost, http.StatusNotFound},
+		{"trash with GET", "/trash", http.MethodGet, http.StatusOK},
+		{"restore of unknown lineage", "/trash/unknown/restore", http.MethodPost, http.StatusNotFound},
+		{"restore with GET", "/trash/unknown/restore", http.MethodGet, http.StatusMethodNotAllowed},
+		{"alerts with GET", "/alerts", http.MethodGet, http.StatusOK},
+		{"alert rules with POST", "/alerts/rules", http.MethodPost, http.StatusBadRequest},
+		{"unknown alert rule", "/alerts/rules/unknown", http.MethodDelete, http.StatusNotFound},
+		{"decorators with POST", "/decorators", http.MethodPost, http.StatusBadRequest},
+		{"unknown decorator", "/decorators/unknown", http.MethodDelete, http.StatusNotFound},
+		{"golden modules with GET", "/golden-modules", http.MethodGet, http.StatusOK},
+		{"unknown golden module conformance", "/golden-modules/unknown/conformance", http.MethodGet, http.StatusNotFound},
+		{"backstage entities with GET", "/backstage/entities", http.MethodGet, http.StatusOK},
+		{"backstage entities of unknown lineage", "/backstage/entities?lineage=unknown", http.MethodGet, http.StatusNotFound},
+		{"usage with GET", "/usage", http.MethodGet, http.StatusOK},
+		{"usage with POST", "/usage", http.MethodPost, http.StatusMethodNotAllowed},
+		{"infrastructure metrics with GET", "/metrics/infrastructure", http.MethodGet, http.StatusOK},
+		{"infrastructure metrics with POST", "/metrics/infrastructure", http.MethodPost, http.StatusMethodNotAllowed},
+		{"graph cache metrics with GET", "/metrics/cache", http.MethodGet, http.StatusOK},
+		{"graph cache metrics with POST", "/metrics/cache", http.MethodPost, http.StatusMethodNotAllowed},
+		{"clusters analysis with POST", "/analyze/clusters", http.MethodPost, http.StatusBadRequest},
+		{"clusters analysis with GET", "/analyze/clusters", http.MethodGet, http.StatusMethodNotAllowed},
+		{"compliance analysis with POST", "/analyze/compliance", http.MethodPost, http.StatusBadRequest},
+		{"endpoints analysis with POST", "/analyze/endpoints", http.MethodPost, http.StatusBadRequest},
+		{"endpoints analysis with GET", "/analyze/endpoints", http.MethodGet, http.StatusMethodNotAllowed},
+		{"import suggestions with POST", "/analyze/import-suggestions", http.MethodPost, http.StatusBadRequest},
+		{"limits analysis with POST", "/analyze/limits", http.MethodPost, http.StatusBadRequest},
+		{"modules analysis with POST", "/analyze/modules", http.MethodPost, http.StatusBadRequest},
+		{"providers analysis with POST", "/analyze/providers", http.MethodPost, http.StatusBadRequest},
+		{"naming analysis with POST", "/analyze/naming", http.MethodPost, http.StatusBadRequest},
+		{"outputs analysis with POST", "/analyze/outputs", http.MethodPost, http.StatusBadRequest},
+		{"split suggestions with POST", "/analyze/split-suggestions", http.MethodPost, http.StatusBadRequest},
+		{"unmanaged resources with POST", "/analyze/unmanaged", http.MethodPost, http.StatusBadRequest},
+		{"unmanaged resources with GET", "/analyze/unmanaged", http.MethodGet, http.StatusMethodNotAllowed},
+		{"module suggestions with POST", "/analyze/module-suggestions", http.MethodPost, http.StatusBadRequest},
+		{"analyze with POST", "/analyze", http.MethodPost, http.StatusBadRequest},
+		{"analyze with GET", "/analyze", http.MethodGet, http.StatusMethodNotAllowed},
+		{"analyzers with GET", "/analyzers", http.MethodGet, http.StatusOK},
+		{"codes with GET", "/codes", http.MethodGet, http.StatusOK},
+		{"codes with POST", "/codes", http.MethodPost, http.StatusMethodNotAllowed},
+		{"destroy simulation with POST", "/simulate/destroy", http.MethodPost, http.StatusBadRequest},
+		{"destroy simulation with GET", "/simulate/destroy", http.MethodGet, http.StatusMethodNotAllowed},
+		{"admin export with GET", "/admin/export", http.MethodGet, http.StatusServiceUnavailable},
+		{"admin import with GET", "/admin/import", http.MethodGet, http.StatusMethodNotAllowed},
+		{"admin users with GET", "/admin/users", http.MethodGet, http.StatusServiceUnavailable},
+		{"admin user with GET", "/admin/users/alice", http.MethodGet, http.StatusServiceUnavailable},
+		{"admin user role with GET", "/admin/users/alice/role", http.MethodGet, http.StatusMethodNotAllowed},
+		{"admin keys with GET", "/admin/keys", http.MethodGet, http.StatusServiceUnavailable},
+		{"admin key with GET", "/admin/keys/abc", http.MethodGet, http.StatusServiceUnavailable},
+		{"admin usage with GET", "/admin/usage", http.MethodGet, http.StatusServiceUnavailable},
+		{"versioned health with GET", "/api/v1/health", http.MethodGet, http.StatusOK},
+		{"versioned parse with GET", "/api/v1/parse", http.MethodGet, http.StatusMethodNotAllowed},
+		{"versioned state history", "/api/v1/states/unknown", http.MethodGet, http.StatusNotFound},
+		{"unknown API path", "/api/v1/unknown", http.MethodGet, http.StatusNotFound},
+		{"unknown path serves the web UI", "/unknown", http.MethodGet, http.StatusOK},
+		{"root path serves the web UI", "/", http.MethodGet, http.StatusOK},
+		{"login page", "/login", http.MethodGet, http.StatusOK},
+		{"unknown asset", "/unknown.js", http.MethodGet, http.StatusNotFound},
+		{"health with trailing slash serves the web UI", "/health/", http.MethodGet, http.StatusOK},
 		{"parse with trailing slash", "/parse/", http.MethodPost, http.StatusNotFound},
 	}
 
@@ -244,6 +360,55 @@ func TestRoutePaths(t *testing.T) {
 	}
 }
 
+func TestLegacyRoutes(t *testing.T) {
+	router := setupRouter()
+
+	t.Run("unprefixed paths are deprecated", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, w.Header().Get("Deprecation"))
+		assert.NotEmpty(t, w.Header().Get("Sunset"))
+		assert.Equal(t, `</api/v1/health>; rel="successor-version"`, w.Header().Get("Link"))
+	})
+
+	t.Run("versioned paths are not deprecated", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Deprecation"))
+		assert.Empty(t, w.Header().Get("Sunset"))
+	})
+}
+
+func TestRouteMethods(t *testing.T) {
+	router := setupRouter()
+	methods := routeMethods(router)
+
+	testCases := []struct {
+		path     string
+		expected []string
+	}{
+		{"/api/v1/parse", []string{http.MethodPost}},
+		{"/locks", []string{http.MethodGet, http.MethodPost, http.MethodDelete}},
+		{"/api/v1/states/abc-123", []string{http.MethodGet, http.MethodDelete}},
+		{"/visualize", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, tc.path, nil)
+			assert.Equal(t, tc.expected, methods(req))
+		})
+	}
+}
+
 func TestConcurrentRequests(t *testing.T) {
 	router := setupRouter()
 
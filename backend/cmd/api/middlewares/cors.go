@@ -4,11 +4,13 @@
 package middlewares
 
 import (
+	"errors"
 	"net/http"
 	"os"
+	"strings"
 )
 
-var allowedOrigin = getEnv("CORS_ALLOWED_ORIGIN", "*")
+const defaultCorsMethods = "GET, POST, PUT, DELETE, OPTIONS"
 
 func getEnv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
@@ -17,11 +19,74 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// CorsPolicy configures which cross-origin requests Cors allows.
+type CorsPolicy struct {
+	// AllowedOrigins lists exact origins, "*" for any origin, or wildcard
+	// subdomain patterns such as https://*.example.com.
+	AllowedOrigins []string
+	// AllowCredentials lets browsers send cookies and authorization headers.
+	// The allowed origin is then reflected. It requires an explicit
+	// allowlist: with "*", any site could make credentialed requests.
+	AllowCredentials bool
+	// Methods returns the methods of the route a request targets; when it is
+	// nil or returns none, every method is announced.
+	Methods func(r *http.Request) []string
+}
+
+// CorsPolicyFromEnv reads the comma-separated CORS_ALLOWED_ORIGIN allowlist,
+// which defaults to any origin, and CORS_ALLOW_CREDENTIALS.
+func CorsPolicyFromEnv() CorsPolicy {
+	var origins []string
+	for _, origin := range strings.Split(getEnv("CORS_ALLOWED_ORIGIN", "*"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+
+	return CorsPolicy{
+		AllowedOrigins:   origins,
+		AllowCredentials: os.Getenv("CORS_ALLOW_CREDENTIALS") == "true",
+	}
+}
+
+// ErrCredentialsWithAnyOrigin rejects policies that allow credentials from
+// any origin.
+var ErrCredentialsWithAnyOrigin = errors.New("CORS_ALLOW_CREDENTIALS requires an explicit CORS_ALLOWED_ORIGIN allowlist, not *")
+
+// Validate reports policies that would let any site make credentialed
+// requests, which the server refuses to start with.
+func (p CorsPolicy) Validate() error {
+	for _, allowed := range p.AllowedOrigins {
+		if p.AllowCredentials && allowed == "*" {
+			return ErrCredentialsWithAnyOrigin
+		}
+	}
+
+	return nil
+}
+
+// Cors applies the policy configured through the environment.
 func Cors(next http.Handler) http.Handler {
+	return CorsPolicyFromEnv().Handler(next)
+}
+
+// Handler sets the CORS headers allowed by the policy and answers preflight
+// requests.
+func (p CorsPolicy) Handler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		if origin := p.allowOrigin(r.Header.Get("Origin")); origin != "" {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			if origin != "*" {
+				w.Header().Add("Vary", "Origin")
+			}
+			if p.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+		}
+
+		w.Header().Set("Access-Control-Allow-Methods", p.methods(r))
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Content-SHA256, X-CSRF-Token, X-Terrascope-Project")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Graph-Digest, X-Redacted-Values, Deprecation, Sunset, Link, Retry-After, X-Cache")
 		w.Header().Set("Access-Control-Max-Age", "3600")
 
 		if r.Method == http.MethodOptions {
@@ -32,3 +97,60 @@ func Cors(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// allowOrigin returns the Access-Control-Allow-Origin value for a request
+// from origin, or "" when the origin is not allowed. With credentials, "*"
+// allows no origin, as Validate rejects it.
+func (p CorsPolicy) allowOrigin(origin string) string {
+	for _, allowed := range p.AllowedOrigins {
+		if allowed == "*" {
+			if !p.AllowCredentials {
+				return "*"
+			}
+			continue
+		}
+
+		if origin != "" && matchOrigin(allowed, origin) {
+			return origin
+		}
+	}
+
+	return ""
+}
+
+// matchOrigin reports whether origin equals allowed or, for patterns such as
+// https://*.example.com, is a subdomain of it with the same scheme and port.
+func matchOrigin(allowed, origin string) bool {
+	if strings.EqualFold(allowed, origin) {
+		return true
+	}
+
+	prefix, suffix, ok := strings.Cut(allowed, "*.")
+	if !ok {
+		return false
+	}
+
+	origin = strings.ToLower(origin)
+	prefix, suffix = strings.ToLower(prefix), "."+strings.ToLower(suffix)
+
+	if !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+		return false
+	}
+
+	subdomain := origin[len(prefix) : len(origin)-len(suffix)]
+
+	return subdomain != "" && !strings.ContainsAny(subdomain, "/:@")
+}
+
+func (p CorsPolicy) methods(r *http.Request) string {
+	if p.Methods == nil {
+		return defaultCorsMethods
+	}
+
+	methods := p.Methods(r)
+	if len(methods) == 0 {
+		return defaultCorsMethods
+	}
+
+	return strings.Join(methods, ", ") + ", " + http.MethodOptions
+}
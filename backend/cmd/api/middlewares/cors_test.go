@@ -1,6 +1,7 @@
 package middlewares
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -52,10 +53,11 @@ func TestCors(t *testing.T) {
 		Cors(handler).ServeHTTP(rec, req)
 
 		headers := map[string]bool{
-			"Access-Control-Allow-Origin":  false,
-			"Access-Control-Allow-Methods": false,
-			"Access-Control-Allow-Headers": false,
-			"Access-Control-Max-Age":       false,
+			"Access-Control-Allow-Origin":   false,
+			"Access-Control-Allow-Methods":  false,
+			"Access-Control-Allow-Headers":  false,
+			"Access-Control-Expose-Headers": false,
+			"Access-Control-Max-Age":        false,
 		}
 
 		for header := range headers {
@@ -71,3 +73,122 @@ func TestCors(t *testing.T) {
 		}
 	})
 }
+
+func TestCorsPolicy(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	request := func(policy CorsPolicy, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		rec := httptest.NewRecorder()
+
+		policy.Handler(handler).ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	allowlist := []string{"https://app.example.com", "https://*.staging.example.com"}
+
+	tests := []struct {
+		name        string
+		policy      CorsPolicy
+		origin      string
+		allowOrigin string
+		credentials string
+	}{
+		{"any origin", CorsPolicy{AllowedOrigins: []string{"*"}}, "https://other.test", "*", ""},
+		{"exact origin is reflected", CorsPolicy{AllowedOrigins: allowlist}, "https://app.example.com", "https://app.example.com", ""},
+		{"wildcard subdomain is reflected", CorsPolicy{AllowedOrigins: allowlist}, "https://pr-42.staging.example.com", "https://pr-42.staging.example.com", ""},
+		{"wildcard does not match the apex", CorsPolicy{AllowedOrigins: allowlist}, "https://staging.example.com", "", ""},
+		{"wildcard checks the scheme", CorsPolicy{AllowedOrigins: allowlist}, "http://pr-42.staging.example.com", "", ""},
+		{"wildcard does not match another host", CorsPolicy{AllowedOrigins: allowlist}, "https://evil.test/.staging.example.com", "", ""},
+		{"unlisted origin", CorsPolicy{AllowedOrigins: allowlist}, "https://evil.test", "", ""},
+		{"credentials", CorsPolicy{AllowedOrigins: allowlist, AllowCredentials: true}, "https://app.example.com", "https://app.example.com", "true"},
+		{"credentials never allow any origin", CorsPolicy{AllowedOrigins: []string{"*"}, AllowCredentials: true}, "https://other.test", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := request(tt.policy, tt.origin)
+
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+				t.Errorf("expected Access-Control-Allow-Origin %q, got %q", tt.allowOrigin, got)
+			}
+
+			if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != tt.credentials {
+				t.Errorf("expected Access-Control-Allow-Credentials %q, got %q", tt.credentials, got)
+			}
+		})
+	}
+
+	t.Run("reflected origins vary the response", func(t *testing.T) {
+		rec := request(CorsPolicy{AllowedOrigins: allowlist}, "https://app.example.com")
+
+		if vary := rec.Header().Get("Vary"); vary != "Origin" {
+			t.Errorf("expected Vary Origin, got %q", vary)
+		}
+	})
+
+	t.Run("announces the methods of the route", func(t *testing.T) {
+		policy := CorsPolicy{
+			AllowedOrigins: []string{"*"},
+			Methods: func(r *http.Request) []string {
+				return []string{http.MethodGet}
+			},
+		}
+
+		rec := request(policy, "https://other.test")
+
+		if methods := rec.Header().Get("Access-Control-Allow-Methods"); methods != "GET, OPTIONS" {
+			t.Errorf("expected methods %q, got %q", "GET, OPTIONS", methods)
+		}
+	})
+}
+
+func TestCorsPolicyFromEnv(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGIN", "https://app.example.com, https://*.staging.example.com")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+
+	policy := CorsPolicyFromEnv()
+
+	if len(policy.AllowedOrigins) != 2 || policy.AllowedOrigins[1] != "https://*.staging.example.com" {
+		t.Errorf("unexpected origins %v", policy.AllowedOrigins)
+	}
+
+	if !policy.AllowCredentials {
+		t.Error("expected credentials to be allowed")
+	}
+}
+
+func TestCorsPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy CorsPolicy
+		err    error
+	}{
+		{"any origin", CorsPolicy{AllowedOrigins: []string{"*"}}, nil},
+		{"credentials with an allowlist", CorsPolicy{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true}, nil},
+		{"credentials with any origin", CorsPolicy{AllowedOrigins: []string{"https://app.example.com", "*"}, AllowCredentials: true}, ErrCredentialsWithAnyOrigin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); !errors.Is(err, tt.err) {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+
+	t.Run("credentials default to any origin", func(t *testing.T) {
+		t.Setenv("CORS_ALLOWED_ORIGIN", "")
+		t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+
+		if err := CorsPolicyFromEnv().Validate(); !errors.Is(err, ErrCredentialsWithAnyOrigin) {
+			t.Errorf("expected %v, got %v", ErrCredentialsWithAnyOrigin, err)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCSRF(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("safe requests receive a token cookie", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+
+		CSRF(handler).ServeHTTP(rec, req)
+
+		cookies := rec.Result().Cookies()
+		if len(cookies) != 1 || cookies[0].Name != CSRFCookieName || len(cookies[0].Value) != 64 {
+			t.Fatalf("expected a %s cookie, got %v", CSRFCookieName, cookies)
+		}
+
+		if cookies[0].SameSite != http.SameSiteStrictMode {
+			t.Errorf("expected a SameSite=Strict cookie")
+		}
+	})
+
+	t.Run("existing tokens are kept", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: CSRFCookieName, Value: "token"})
+		rec := httptest.NewRecorder()
+
+		CSRF(handler).ServeHTTP(rec, req)
+
+		if cookies := rec.Result().Cookies(); len(cookies) != 0 {
+			t.Errorf("expected no new cookie, got %v", cookies)
+		}
+	})
+
+	tests := []struct {
+		name         string
+		cookies      map[string]string
+		header       string
+		expectedCode int
+	}{
+		{"requests without cookies pass", nil, "", http.StatusOK},
+		{"matching token passes", map[string]string{CSRFCookieName: "token"}, "token", http.StatusOK},
+		{"missing header is rejected", map[string]string{CSRFCookieName: "token"}, "", http.StatusForbidden},
+		{"mismatching header is rejected", map[string]string{CSRFCookieName: "token"}, "other", http.StatusForbidden},
+		{"session without token is rejected", map[string]string{"session": "abc"}, "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/parse", nil)
+			for name, value := range tt.cookies {
+				req.AddCookie(&http.Cookie{Name: name, Value: value})
+			}
+			if tt.header != "" {
+				req.Header.Set(CSRFHeaderName, tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			CSRF(handler).ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d", tt.expectedCode, rec.Code)
+			}
+		})
+	}
+}
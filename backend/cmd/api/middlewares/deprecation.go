@@ -0,0 +1,28 @@
+// Package middlewares provides HTTP middleware functions for request processing,
+// including authentication, logging, rate limiting, and other cross-cutting concerns
+// that wrap HTTP handlers.
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Deprecated marks the responses of a legacy route as deprecated since the
+// given time (RFC 9745), announces when it will be removed (RFC 8594), and
+// links to the same path under successorPrefix.
+func Deprecated(since, sunset time.Time, successorPrefix string) func(http.Handler) http.Handler {
+	deprecation := fmt.Sprintf("@%d", since.Unix())
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", deprecation)
+			w.Header().Set("Sunset", sunsetDate)
+			w.Header().Add("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, successorPrefix, r.URL.EscapedPath()))
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeprecated(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	since := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, 4, 16, 0, 0, 0, 0, time.UTC)
+
+	req := httptest.NewRequest(http.MethodPost, "/parse", nil)
+	rec := httptest.NewRecorder()
+
+	Deprecated(since, sunset, "/api/v1")(handler).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	expected := map[string]string{
+		"Deprecation": "@1792108800",
+		"Sunset":      "Fri, 16 Apr 2027 00:00:00 GMT",
+		"Link":        `</api/v1/parse>; rel="successor-version"`,
+	}
+
+	for header, value := range expected {
+		if got := rec.Header().Get(header); got != value {
+			t.Errorf("expected %s %q, got %q", header, value, got)
+		}
+	}
+}
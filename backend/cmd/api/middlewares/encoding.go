@@ -0,0 +1,123 @@
+// Package middlewares provides HTTP middleware functions for request processing,
+// including authentication, logging, rate limiting, and other cross-cutting concerns
+// that wrap HTTP handlers.
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// JSONLinesContentType is the media type of JSON Lines responses, which a
+// client asks for in the Accept header.
+const JSONLinesContentType = "application/x-ndjson"
+
+// JSONFormat encodes the JSON responses of next as the request asks: indented
+// with ?pretty=true, or as JSON Lines, one element of a list per line, when
+// the client accepts application/x-ndjson. Otherwise responses stay compact.
+// Responses that are not JSON, and responses that are not lists when JSON
+// Lines are asked for, are passed on unchanged.
+func JSONFormat(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+
+		pretty := r.URL.Query().Get("pretty") == "true"
+		lines := acceptsJSONLines(r.Header.Get("Accept"))
+		if !pretty && !lines {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		buffered := &bufferedResponse{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(buffered, r)
+
+		body := buffered.body.Bytes()
+		if isJSON(w.Header().Get("Content-Type")) {
+			if lines {
+				if encoded, ok := jsonLines(body); ok {
+					w.Header().Set("Content-Type", JSONLinesContentType)
+					body = encoded
+				}
+			} else {
+				body = indent(body)
+			}
+		}
+
+		if w.Header().Get("Content-Length") != "" {
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		}
+
+		w.WriteHeader(buffered.status)
+		if _, err := w.Write(body); err != nil {
+			log.Printf("Error writing response: %v", err)
+		}
+	})
+}
+
+// bufferedResponse holds the response of a handler so that its body can be
+// re-encoded before it is sent.
+type bufferedResponse struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	b.status = status
+}
+
+func (b *bufferedResponse) Write(data []byte) (int, error) {
+	return b.body.Write(data)
+}
+
+func acceptsJSONLines(accept string) bool {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(mediaRange)
+		if err == nil && (mediaType == JSONLinesContentType || mediaType == "application/jsonl") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/json"
+}
+
+// jsonLines re-encodes a JSON array as one compact element per line. It
+// returns false when the body is not an array.
+func jsonLines(body []byte) ([]byte, bool) {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(body, &elements); err != nil {
+		return nil, false
+	}
+
+	var out bytes.Buffer
+	for _, element := range elements {
+		if err := json.Compact(&out, element); err != nil {
+			return nil, false
+		}
+		out.WriteByte('\n')
+	}
+
+	return out.Bytes(), true
+}
+
+// indent returns the JSON body indented by two spaces, or unchanged when it is
+// not valid JSON.
+func indent(body []byte) []byte {
+	var out bytes.Buffer
+	if err := json.Indent(&out, bytes.TrimSpace(body), "", "  "); err != nil {
+		return body
+	}
+	out.WriteByte('\n')
+
+	return out.Bytes()
+}
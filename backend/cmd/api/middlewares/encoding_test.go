@@ -0,0 +1,83 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestJSONFormat(t *testing.T) {
+	respond := func(contentType, body string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(body))
+		})
+	}
+
+	serve := func(handler http.Handler, url, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rec := httptest.NewRecorder()
+
+		JSONFormat(handler).ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	list := respond("application/json", `[{"id":"a","n":1},{"id":"b","n":2}]`+"\n")
+
+	t.Run("responses stay compact by default", func(t *testing.T) {
+		rec := serve(list, "/states", "")
+
+		if got := rec.Body.String(); got != `[{"id":"a","n":1},{"id":"b","n":2}]`+"\n" {
+			t.Errorf("unexpected body %q", got)
+		}
+		if rec.Code != http.StatusCreated {
+			t.Errorf("expected status 201, got %d", rec.Code)
+		}
+	})
+
+	t.Run("pretty responses are indented", func(t *testing.T) {
+		rec := serve(respond("application/json; charset=utf-8", `{"status":"healthy"}`), "/health?pretty=true", "")
+
+		if got := rec.Body.String(); got != "{\n  \"status\": \"healthy\"\n}\n" {
+			t.Errorf("unexpected body %q", got)
+		}
+		if rec.Code != http.StatusCreated {
+			t.Errorf("expected status 201, got %d", rec.Code)
+		}
+	})
+
+	t.Run("lists are split into JSON Lines", func(t *testing.T) {
+		rec := serve(list, "/states?pretty=true", "application/x-ndjson")
+
+		if got := rec.Body.String(); got != "{\"id\":\"a\",\"n\":1}\n{\"id\":\"b\",\"n\":2}\n" {
+			t.Errorf("unexpected body %q", got)
+		}
+		if got := rec.Header().Get("Content-Type"); got != JSONLinesContentType {
+			t.Errorf("expected %s, got %q", JSONLinesContentType, got)
+		}
+	})
+
+	t.Run("objects are not split into JSON Lines", func(t *testing.T) {
+		rec := serve(respond("application/json", `{"status":"healthy"}`), "/health", "text/html, application/jsonl;q=0.9")
+
+		if got := rec.Body.String(); got != `{"status":"healthy"}` {
+			t.Errorf("unexpected body %q", got)
+		}
+		if got := rec.Header().Get("Content-Type"); got != "application/json" {
+			t.Errorf("expected application/json, got %q", got)
+		}
+	})
+
+	t.Run("other content is passed through", func(t *testing.T) {
+		rec := serve(respond("text/plain; charset=utf-8", "Lineage not found: prod\n"), "/states/prod?pretty=true", "")
+
+		if got := rec.Body.String(); got != "Lineage not found: prod\n" {
+			t.Errorf("unexpected body %q", got)
+		}
+	})
+}
@@ -0,0 +1,32 @@
+// Package middlewares provides HTTP middleware functions for request processing,
+// including authentication, logging, rate limiting, and other cross-cutting concerns
+// that wrap HTTP handlers.
+package middlewares
+
+import (
+	"log"
+	"net/http"
+	"runtime/debug"
+)
+
+// Recover answers 500 Internal Server Error when a handler panics, instead of
+// dropping the connection, and logs the panic with its stack so that the
+// input that caused it can be added to the parser fuzz corpus.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			err := recover()
+			if err == nil {
+				return
+			}
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
+
+			log.Printf("panic serving %s %s: %v\n%s", r.Method, r.URL.Path, err, debug.Stack())
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,50 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecover(t *testing.T) {
+	t.Run("panics answer 500", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var attrs map[string]any
+			_ = attrs["id"].(string)
+		})
+
+		rec := httptest.NewRecorder()
+		Recover(handler).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graph", nil))
+
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", rec.Code)
+		}
+	})
+
+	t.Run("other requests pass through", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		})
+
+		rec := httptest.NewRecorder()
+		Recover(handler).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graph", nil))
+
+		if rec.Code != http.StatusCreated {
+			t.Errorf("expected status 201, got %d", rec.Code)
+		}
+	})
+
+	t.Run("aborted handlers are not recovered", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		})
+
+		defer func() {
+			if err := recover(); err != http.ErrAbortHandler {
+				t.Errorf("expected http.ErrAbortHandler, got %v", err)
+			}
+		}()
+
+		Recover(handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
@@ -0,0 +1,54 @@
+// Package middlewares provides HTTP middleware functions for request processing,
+// including authentication, logging, rate limiting, and other cross-cutting concerns
+// that wrap HTTP handlers.
+package middlewares
+
+import (
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Static serves a single-page application from files. Hashed build assets are
+// cached for a year and HTML is always revalidated. Unknown paths without a
+// file extension are client-side routes and get index.html; other unknown
+// paths and non-GET requests are not found.
+func Static(files fs.FS) http.Handler {
+	fileServer := http.FileServerFS(files)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.NotFound(w, r)
+			return
+		}
+
+		name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+		if name == "" {
+			name = "index.html"
+		}
+
+		info, err := fs.Stat(files, name)
+		if err != nil || info.IsDir() {
+			if path.Ext(name) != "" {
+				http.NotFound(w, r)
+				return
+			}
+
+			w.Header().Set("Cache-Control", "no-cache")
+			http.ServeFileFS(w, r, files, "index.html")
+			return
+		}
+
+		switch {
+		case path.Ext(name) == ".html":
+			w.Header().Set("Cache-Control", "no-cache")
+		case strings.HasPrefix(name, "assets/"):
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		default:
+			w.Header().Set("Cache-Control", "public, max-age=3600")
+		}
+
+		fileServer.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,59 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestStatic(t *testing.T) {
+	files := fstest.MapFS{
+		"index.html":           {Data: []byte("<html>app</html>")},
+		"vite.svg":             {Data: []byte("<svg></svg>")},
+		"assets/index-1a2b.js": {Data: []byte("console.log('app')")},
+	}
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		Static(files).ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+
+		return rec
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		expectedCode int
+		cacheControl string
+		body         string
+	}{
+		{"root serves the index", http.MethodGet, "/", http.StatusOK, "no-cache", "app"},
+		{"hashed assets are immutable", http.MethodGet, "/assets/index-1a2b.js", http.StatusOK, "public, max-age=31536000, immutable", "console.log"},
+		{"other files are cached briefly", http.MethodGet, "/vite.svg", http.StatusOK, "public, max-age=3600", "svg"},
+		{"client-side routes fall back to the index", http.MethodGet, "/visualize", http.StatusOK, "no-cache", "app"},
+		{"directories fall back to the index", http.MethodGet, "/assets", http.StatusOK, "no-cache", "app"},
+		{"missing assets are not found", http.MethodGet, "/assets/missing.js", http.StatusNotFound, "", ""},
+		{"non-GET requests are not found", http.MethodPost, "/visualize", http.StatusNotFound, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(tt.method, tt.path)
+
+			if rec.Code != tt.expectedCode {
+				t.Fatalf("expected status %d, got %d", tt.expectedCode, rec.Code)
+			}
+
+			if cache := rec.Header().Get("Cache-Control"); cache != tt.cacheControl {
+				t.Errorf("expected Cache-Control %q, got %q", tt.cacheControl, cache)
+			}
+
+			if !strings.Contains(rec.Body.String(), tt.body) {
+				t.Errorf("expected body to contain %q, got %q", tt.body, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/terrascope/core/internal/handlers"
+	"github.com/terrascope/core/internal/store"
+)
+
+// migrateUsage describes the migrate subcommand.
+const migrateUsage = "usage: terrascope migrate status | up | down VERSION"
+
+// migrate runs the migrate subcommand against the database selected by
+// TERRASCOPE_STORAGE: status prints the schema version, up migrates to the
+// latest version and down VERSION back to an earlier one.
+func migrate(ctx context.Context, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	target := -1
+	switch args[0] {
+	case "status", "up":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+	case "down":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		target = version
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	db, dialect, err := handlers.StorageDatabase()
+	if err != nil {
+		return err
+	}
+	if db == nil {
+		return errors.New("TERRASCOPE_STORAGE selects no database: set it to sqlite or postgres")
+	}
+	defer db.Close()
+
+	migrations, err := store.Migrations(dialect)
+	if err != nil {
+		return err
+	}
+
+	if args[0] == "status" {
+		version, err := store.SchemaVersion(ctx, db)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "schema version %d, latest %d\n", version, len(migrations))
+		return nil
+	}
+
+	if target < 0 {
+		target = len(migrations)
+	}
+
+	ran, err := store.Migrate(ctx, db, dialect, target)
+	for _, migration := range ran {
+		fmt.Fprintf(out, "%s %04d_%s\n", args[0], migration.Version, migration.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(ran) == 0 {
+		fmt.Fprintf(out, "schema version %d is up to date\n", target)
+	}
+
+	return nil
+}
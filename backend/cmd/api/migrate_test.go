@@ -0,0 +1,24 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrate(t *testing.T) {
+	t.Run("rejects invalid arguments", func(t *testing.T) {
+		for _, args := range [][]string{nil, {"sideways"}, {"up", "3"}, {"down"}} {
+			assert.EqualError(t, migrate(context.Background(), args, &bytes.Buffer{}), migrateUsage, "args %q", args)
+		}
+
+		assert.EqualError(t, migrate(context.Background(), []string{"down", "one"}, &bytes.Buffer{}), `invalid version "one"`)
+	})
+
+	t.Run("needs a database", func(t *testing.T) {
+		t.Setenv("TERRASCOPE_STORAGE", "memory")
+		assert.ErrorContains(t, migrate(context.Background(), []string{"status"}, &bytes.Buffer{}), "selects no database")
+	})
+}
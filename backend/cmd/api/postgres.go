@@ -0,0 +1,7 @@
+//go:build postgres
+
+package main
+
+// The postgres build tag links the Postgres driver for
+// TERRASCOPE_STORAGE=postgres.
+import _ "github.com/jackc/pgx/v5/stdlib"
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+	"github.com/terrascope/core/internal/render"
+)
+
+// watchUsage describes the watch subcommand.
+const watchUsage = "usage: terrascope watch [--addr HOST:PORT] [--interval DURATION] STATE|DIR"
+
+// watchCandidates are the state files watched in a directory, in order of
+// preference: the local state, then the copy of the remote state Terraform
+// keeps in .terraform.
+var watchCandidates = []string{
+	"terraform.tfstate",
+	filepath.Join(".terraform", "terraform.tfstate"),
+}
+
+// watchPage shows the rendered graph of the watched state and reloads itself
+// whenever the server says the state changed.
+var watchPage = template.Must(template.New("watch").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Path}} - terrascope watch</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; margin: 2rem; color: #1f2933; }
+.error { color: #ab091e; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>{{.Path}}</h1>
+{{if .Err}}<p class="error">{{.Err}}</p>
+{{end}}{{if .Graph}}<p>{{len .Graph.Nodes}} resources, {{len .Graph.Edges}} dependencies, loaded {{.Loaded.Format "15:04:05"}}</p>
+{{.SVG}}
+{{else}}<p>Waiting for a state to show.</p>
+{{end}}<script>
+new EventSource("/events").addEventListener("reload", () => location.reload());
+</script>
+</body>
+</html>
+`))
+
+// watchState serves the graph of a state file, which it reloads whenever the
+// file changes. A state that fails to load keeps the last graph on show along
+// with the error.
+type watchState struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	graph   *models.Graph
+	svg     []byte
+	loaded  time.Time
+	err     error
+	changed chan struct{}
+}
+
+func newWatchState(path string) *watchState {
+	return &watchState{path: path, changed: make(chan struct{})}
+}
+
+// watch runs the watch subcommand: it serves a page on localhost that shows
+// the graph of a state file, or of the state found in a directory, and
+// reloads it in the browser whenever the file changes.
+func watch(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("watch", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	addr := flags.String("addr", "localhost:8090", "")
+	interval := flags.Duration("interval", time.Second, "")
+
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 || *interval <= 0 {
+		return errors.New(watchUsage)
+	}
+
+	path, err := watchedPath(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+
+	state := newWatchState(path)
+	state.poll()
+
+	server := &http.Server{Handler: state, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	go func() {
+		ticker := time.NewTicker(*interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if state.poll() {
+					fmt.Fprintf(out, "reloaded %s\n", path)
+				}
+			}
+		}
+	}()
+
+	fmt.Fprintf(out, "watching %s on http://%s\n", path, listener.Addr())
+
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// watchedPath returns the state file to watch for the argument: the file
+// itself, or the first of watchCandidates in a directory.
+func watchedPath(arg string) (string, error) {
+	info, err := os.Stat(arg)
+	if err != nil {
+		return "", err
+	}
+
+	if !info.IsDir() {
+		return arg, nil
+	}
+
+	for _, candidate := range watchCandidates {
+		path := filepath.Join(arg, candidate)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("no state file in %s", arg)
+}
+
+// poll reloads the state when its file changed since the last poll and tells
+// the pages shown to reload. It reports whether the state was reloaded.
+func (s *watchState) poll() bool {
+	info, err := os.Stat(s.path)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return false
+	}
+
+	if err != nil {
+		if s.err != nil && s.err.Error() == err.Error() {
+			return false
+		}
+		s.err = err
+	} else {
+		s.modTime, s.size = info.ModTime(), info.Size()
+		s.err = s.load()
+	}
+
+	close(s.changed)
+	s.changed = make(chan struct{})
+
+	return true
+}
+
+// load parses the state file and renders its graph.
+func (s *watchState) load() error {
+	state, err := readStateFile(s.path)
+	if err != nil {
+		return err
+	}
+
+	graph := parser.BuildGraph(state)
+	graph.Stats = parser.ComputeStats(graph)
+
+	svg, err := render.SVG(graph)
+	if err != nil {
+		return err
+	}
+
+	s.graph, s.svg, s.loaded = graph, svg, time.Now()
+
+	return nil
+}
+
+// ServeHTTP serves the page at /, the graph as JSON at /graph and the reload
+// notifications as server-sent events at /events.
+func (s *watchState) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/":
+		s.servePage(w)
+	case "/graph":
+		s.mu.Lock()
+		graph := s.graph
+		s.mu.Unlock()
+
+		if graph == nil {
+			http.Error(w, "No graph loaded", http.StatusServiceUnavailable)
+			return
+		}
+
+		body, err := render.JSON(graph)
+		if err != nil {
+			http.Error(w, "Failed to render graph", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	case "/events":
+		s.serveEvents(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *watchState) servePage(w http.ResponseWriter) {
+	s.mu.Lock()
+	data := struct {
+		Path   string
+		Graph  *models.Graph
+		SVG    template.HTML
+		Loaded time.Time
+		Err    error
+	}{s.path, s.graph, template.HTML(s.svg), s.loaded, s.err}
+	s.mu.Unlock()
+
+	var buf bytes.Buffer
+	if err := watchPage.Execute(&buf, data); err != nil {
+		log.Printf("Error rendering page: %v", err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(buf.Bytes())
+}
+
+// serveEvents sends a reload event whenever the state is reloaded, until the
+// client goes away.
+func (s *watchState) serveEvents(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	stream := http.NewResponseController(w)
+	if err := stream.Flush(); err != nil {
+		return
+	}
+
+	for {
+		s.mu.Lock()
+		changed := s.changed
+		s.mu.Unlock()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changed:
+		}
+
+		fmt.Fprint(w, "event: reload\ndata: {}\n\n")
+
+		if err := stream.Flush(); err != nil {
+			return
+		}
+	}
+}
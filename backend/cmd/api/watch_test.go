@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchedPath(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := watchedPath(dir)
+	assert.EqualError(t, err, "no state file in "+dir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".terraform"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".terraform", "terraform.tfstate"), []byte("{}"), 0o600))
+
+	path, err := watchedPath(dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, ".terraform", "terraform.tfstate"), path)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "terraform.tfstate"), []byte("{}"), 0o600))
+
+	path, err = watchedPath(dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "terraform.tfstate"), path, "prefers the local state")
+
+	path, err = watchedPath(filepath.Join(dir, "terraform.tfstate"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "terraform.tfstate"), path)
+
+	_, err = watchedPath(filepath.Join(dir, "missing"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestWatchState(t *testing.T) {
+	path := writeStateFile(t, "site")
+	state := newWatchState(path)
+
+	page := func() string {
+		w := httptest.NewRecorder()
+		state.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		return w.Body.String()
+	}
+
+	t.Run("waits for a state", func(t *testing.T) {
+		assert.Contains(t, page(), "Waiting for a state to show.")
+
+		w := httptest.NewRecorder()
+		state.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graph", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("loads the state", func(t *testing.T) {
+		assert.True(t, state.poll())
+		assert.False(t, state.poll(), "the file did not change")
+
+		assert.Contains(t, page(), "1 resources, 0 dependencies")
+		assert.Contains(t, page(), "<svg")
+
+		w := httptest.NewRecorder()
+		state.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graph", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "aws_s3_bucket.site")
+	})
+
+	t.Run("keeps the graph when the state breaks", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("{not json"), 0o600))
+		require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+
+		assert.True(t, state.poll())
+		assert.Contains(t, page(), `class="error"`)
+		assert.Contains(t, page(), "1 resources, 0 dependencies")
+	})
+
+	t.Run("tells pages to reload", func(t *testing.T) {
+		server := httptest.NewServer(state)
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events", nil)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		fresh := writeStateFile(t, "site", "logs")
+		data, err := os.ReadFile(fresh)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+		require.True(t, state.poll())
+
+		line, err := bufio.NewReader(resp.Body).ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "event: reload\n", line)
+
+		assert.Contains(t, page(), "2 resources")
+		assert.NotContains(t, page(), `class="error"`)
+	})
+}
+
+func TestWatch(t *testing.T) {
+	assert.EqualError(t, watch(context.Background(), nil, &bytes.Buffer{}), watchUsage)
+	assert.EqualError(t, watch(context.Background(), []string{"--interval", "0s", "x"}, &bytes.Buffer{}), watchUsage)
+
+	path := writeStateFile(t, "site")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var out bytes.Buffer
+	done := make(chan error)
+	go func() { done <- watch(ctx, []string{"--addr", "127.0.0.1:0", path}, &out) }()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	require.NoError(t, <-done)
+	assert.True(t, strings.HasPrefix(out.String(), "watching "+path+" on http://127.0.0.1:"))
+}
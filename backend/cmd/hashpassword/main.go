@@ -0,0 +1,29 @@
+// Package main prints the hash of a password read from standard input, for
+// the users file that TERRASCOPE_USERS_FILE points the API at:
+//
+//	echo -n 'correct horse' | go run ./cmd/hashpassword
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/terrascope/core/internal/auth"
+)
+
+func main() {
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		log.Fatal("Expected a password on standard input")
+	}
+
+	hash, err := auth.HashPassword(strings.TrimRight(password, "\r\n"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(hash)
+}
@@ -0,0 +1,139 @@
+// Package alerts evaluates user-defined rules against successive versions of a
+// state and delivers the resulting alerts to the channels of each rule.
+package alerts
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/notifications"
+	"github.com/terrascope/core/internal/parser"
+)
+
+// AnomalyThresholds tune DetectAnomalies; a zero threshold disables its check.
+type AnomalyThresholds struct {
+	// DeletionPercent is the share of the previous managed instances whose
+	// removal is a mass deletion.
+	DeletionPercent int
+	// SerialJump is the serial increase above which a version is flagged.
+	SerialJump int
+	// LineageOverlapPercent is the share of the resources of a new lineage
+	// found in another lineage above which the lineage is deemed changed.
+	LineageOverlapPercent int
+}
+
+// DefaultAnomalyThresholds are used unless the server is configured otherwise.
+var DefaultAnomalyThresholds = AnomalyThresholds{
+	DeletionPercent:       50,
+	SerialJump:            100,
+	LineageOverlapPercent: 50,
+}
+
+// DetectAnomalies flags the suspicious changes from before to after, such as
+// an accidental terraform destroy or an apply in the wrong workspace. When
+// before is the latest version of another lineage, after being the first
+// version of its own, only a lineage change is looked for. The caller fills
+// in the time the alerts were triggered.
+func DetectAnomalies(thresholds AnomalyThresholds, before, after *models.TerraformState) []models.Alert {
+	anomalies := []models.Alert{}
+
+	anomaly := func(condition, severity, message string, resources []string) {
+		anomalies = append(anomalies, models.Alert{
+			RuleName:       anomalyNames[condition],
+			Lineage:        after.Lineage,
+			Serial:         after.Serial,
+			PreviousSerial: before.Serial,
+			Condition:      condition,
+			Severity:       severity,
+			Message:        message,
+			Resources:      resources,
+		})
+	}
+
+	if before.Lineage != after.Lineage {
+		if thresholds.LineageOverlapPercent <= 0 {
+			return anomalies
+		}
+
+		previous, current := resourceProviders(before), resourceProviders(after)
+
+		var shared []string
+		for address := range current {
+			if _, ok := previous[address]; ok {
+				shared = append(shared, address)
+			}
+		}
+		sort.Strings(shared)
+
+		if len(shared) > 0 && len(shared)*100 >= thresholds.LineageOverlapPercent*len(current) {
+			anomaly(models.AnomalyLineageChange, notifications.SeverityCritical,
+				fmt.Sprintf("%d of %d resources were already in lineage %s", len(shared), len(current), before.Lineage),
+				shared)
+		}
+
+		return anomalies
+	}
+
+	if thresholds.DeletionPercent > 0 {
+		previous, current := managedTypes(before), managedTypes(after)
+
+		var removed []string
+		for id := range previous {
+			if _, ok := current[id]; !ok {
+				removed = append(removed, id)
+			}
+		}
+		sort.Strings(removed)
+
+		if len(removed) > 0 && len(removed)*100 >= thresholds.DeletionPercent*len(previous) {
+			anomaly(models.AnomalyMassDeletion, notifications.SeverityCritical,
+				fmt.Sprintf("%d of %d resource instances were removed", len(removed), len(previous)),
+				removed)
+		}
+	}
+
+	previous, current := resourceProviders(before), resourceProviders(after)
+
+	var swapped []string
+	for address, provider := range current {
+		if was, ok := previous[address]; ok && was != provider {
+			swapped = append(swapped, address)
+		}
+	}
+	sort.Strings(swapped)
+
+	if len(swapped) > 0 {
+		anomaly(models.AnomalyProviderSwap, notifications.SeverityWarning,
+			fmt.Sprintf("%d resources changed provider", len(swapped)),
+			swapped)
+	}
+
+	if jump := after.Serial - before.Serial; thresholds.SerialJump > 0 && jump > thresholds.SerialJump {
+		anomaly(models.AnomalySerialJump, notifications.SeverityWarning,
+			fmt.Sprintf("serial jumped by %d", jump),
+			nil)
+	}
+
+	return anomalies
+}
+
+// anomalyNames name anomaly alerts in place of a rule name.
+var anomalyNames = map[string]string{
+	models.AnomalyMassDeletion:  "Mass deletion",
+	models.AnomalyProviderSwap:  "Provider swap",
+	models.AnomalySerialJump:    "Serial jump",
+	models.AnomalyLineageChange: "Lineage change",
+}
+
+// resourceProviders maps the address of every resource in the state to its
+// provider configuration.
+func resourceProviders(state *models.TerraformState) map[string]string {
+	providers := make(map[string]string, len(state.Resources))
+
+	for _, res := range state.Resources {
+		providers[parser.ResourceAddress(res)] = res.Provider
+	}
+
+	return providers
+}
@@ -0,0 +1,105 @@
+// Package alerts evaluates user-defined rules against successive versions of a
+// state and delivers the resulting alerts to the channels of each rule.
+package alerts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestDetectAnomalies(t *testing.T) {
+	before := &models.TerraformState{
+		Lineage: "prod-eu",
+		Serial:  4,
+		Resources: []models.ResourceState{
+			resource("aws_vpc", "main", map[string]any{"id": "vpc-1"}),
+			resource("aws_subnet", "a", map[string]any{"id": "subnet-1"}),
+			resource("aws_instance", "web", map[string]any{"id": "i-1"}),
+			resource("aws_iam_user", "ci", map[string]any{"id": "ci"}),
+		},
+	}
+
+	conditions := func(anomalies []models.Alert) []string {
+		result := []string{}
+		for _, anomaly := range anomalies {
+			result = append(result, anomaly.Condition)
+		}
+
+		return result
+	}
+
+	t.Run("ordinary change", func(t *testing.T) {
+		after := &models.TerraformState{Lineage: "prod-eu", Serial: 5, Resources: before.Resources[:3]}
+
+		assert.Empty(t, DetectAnomalies(DefaultAnomalyThresholds, before, after))
+	})
+
+	t.Run("mass deletion", func(t *testing.T) {
+		after := &models.TerraformState{Lineage: "prod-eu", Serial: 5, Resources: before.Resources[:2]}
+
+		anomalies := DetectAnomalies(DefaultAnomalyThresholds, before, after)
+
+		require.Len(t, anomalies, 1)
+		assert.Equal(t, models.Alert{
+			RuleName:       "Mass deletion",
+			Lineage:        "prod-eu",
+			Serial:         5,
+			PreviousSerial: 4,
+			Condition:      models.AnomalyMassDeletion,
+			Severity:       "critical",
+			Message:        "2 of 4 resource instances were removed",
+			Resources:      []string{"aws_iam_user.ci", "aws_instance.web"},
+		}, anomalies[0])
+
+		assert.Empty(t, DetectAnomalies(AnomalyThresholds{DeletionPercent: 60}, before, after))
+	})
+
+	t.Run("provider swap", func(t *testing.T) {
+		swapped := resource("aws_instance", "web", map[string]any{"id": "i-1"})
+		swapped.Provider = `provider["registry.terraform.io/hashicorp/aws"].us_east`
+		after := &models.TerraformState{
+			Lineage:   "prod-eu",
+			Serial:    5,
+			Resources: []models.ResourceState{before.Resources[0], before.Resources[1], swapped, before.Resources[3]},
+		}
+
+		anomalies := DetectAnomalies(DefaultAnomalyThresholds, before, after)
+
+		require.Len(t, anomalies, 1)
+		assert.Equal(t, models.AnomalyProviderSwap, anomalies[0].Condition)
+		assert.Equal(t, []string{"aws_instance.web"}, anomalies[0].Resources)
+	})
+
+	t.Run("serial jump", func(t *testing.T) {
+		after := &models.TerraformState{Lineage: "prod-eu", Serial: 500, Resources: before.Resources}
+
+		anomalies := DetectAnomalies(DefaultAnomalyThresholds, before, after)
+
+		assert.Equal(t, []string{models.AnomalySerialJump}, conditions(anomalies))
+		assert.Equal(t, "serial jumped by 496", anomalies[0].Message)
+		assert.Empty(t, DetectAnomalies(AnomalyThresholds{}, before, after))
+	})
+
+	t.Run("lineage change", func(t *testing.T) {
+		after := &models.TerraformState{Lineage: "prod-eu-2", Serial: 1, Resources: before.Resources[:2]}
+
+		anomalies := DetectAnomalies(DefaultAnomalyThresholds, before, after)
+
+		assert.Equal(t, []string{models.AnomalyLineageChange}, conditions(anomalies))
+		assert.Equal(t, "2 of 2 resources were already in lineage prod-eu", anomalies[0].Message)
+		assert.Equal(t, "prod-eu-2", anomalies[0].Lineage)
+	})
+
+	t.Run("unrelated new lineage", func(t *testing.T) {
+		after := &models.TerraformState{
+			Lineage:   "staging",
+			Serial:    1,
+			Resources: []models.ResourceState{resource("aws_vpc", "staging", nil)},
+		}
+
+		assert.Empty(t, DetectAnomalies(DefaultAnomalyThresholds, before, after))
+	})
+}
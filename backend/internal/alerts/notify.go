@@ -0,0 +1,59 @@
+// Package alerts evaluates user-defined rules against successive versions of a
+// state and delivers the resulting alerts to the channels of each rule.
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/notifications"
+)
+
+// Notifier delivers an alert to the channels configured on its rule.
+type Notifier interface {
+	Notify(ctx context.Context, rule models.AlertRule, alert models.Alert) error
+}
+
+// ChannelNotifier delivers alerts through notification channels: the rule's
+// named channels, or the dispatcher's channels for the lineage when the rule
+// names none, plus the rule's ad-hoc webhooks.
+type ChannelNotifier struct {
+	Dispatcher *notifications.Dispatcher
+}
+
+// NewChannelNotifier returns a notifier that routes through the dispatcher.
+func NewChannelNotifier(dispatcher *notifications.Dispatcher) *ChannelNotifier {
+	return &ChannelNotifier{Dispatcher: dispatcher}
+}
+
+func (n *ChannelNotifier) Notify(ctx context.Context, rule models.AlertRule, alert models.Alert) error {
+	channels, err := n.Dispatcher.Resolve(alert.Lineage, rule.Channels)
+	if err != nil {
+		return err
+	}
+
+	for _, webhook := range rule.Webhooks {
+		channels = append(channels, notifications.NewWebhookChannel(webhook))
+	}
+
+	return notifications.Send(ctx, channels, Message(alert))
+}
+
+// Message describes the alert as a notification, with the alert itself as the
+// details.
+func Message(alert models.Alert) notifications.Message {
+	text := fmt.Sprintf("%s (serial %d → %d)", alert.Message, alert.PreviousSerial, alert.Serial)
+	if len(alert.Resources) > 0 {
+		text += "\n" + strings.Join(alert.Resources, "\n")
+	}
+
+	return notifications.Message{
+		Title:    alert.RuleName + " in " + alert.Lineage,
+		Text:     text,
+		Severity: alert.Severity,
+		Lineage:  alert.Lineage,
+		Details:  alert,
+	}
+}
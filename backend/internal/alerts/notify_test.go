@@ -0,0 +1,72 @@
+// Package alerts evaluates user-defined rules against successive versions of a
+// state and delivers the resulting alerts to the channels of each rule.
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/notifications"
+)
+
+func TestChannelNotifier(t *testing.T) {
+	received := map[string][]notifications.Message{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg notifications.Message
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+		received[r.URL.Path] = append(received[r.URL.Path], msg)
+	}))
+	t.Cleanup(server.Close)
+
+	dispatcher, err := notifications.New(notifications.Config{
+		Channels: map[string]notifications.ChannelConfig{
+			"ops":    {Type: notifications.TypeWebhook, URL: server.URL + "/ops"},
+			"oncall": {Type: notifications.TypeWebhook, URL: server.URL + "/oncall"},
+		},
+		Defaults:  []string{"ops"},
+		Overrides: []notifications.Override{{Lineage: "prod", Channels: []string{"oncall"}}},
+	})
+	require.NoError(t, err)
+
+	notifier := NewChannelNotifier(dispatcher)
+	alert := models.Alert{
+		RuleName:       "iam users",
+		Lineage:        "prod",
+		Serial:         5,
+		PreviousSerial: 4,
+		Severity:       notifications.SeverityCritical,
+		Message:        "1 aws_iam_user instances added",
+		Resources:      []string{"aws_iam_user.intruder"},
+	}
+
+	t.Run("uses the lineage channels and ad-hoc webhooks", func(t *testing.T) {
+		rule := models.AlertRule{Name: "iam users", Webhooks: []string{server.URL + "/adhoc"}}
+
+		require.NoError(t, notifier.Notify(context.Background(), rule, alert))
+
+		require.Len(t, received["/oncall"], 1)
+		require.Len(t, received["/adhoc"], 1)
+		assert.Empty(t, received["/ops"])
+
+		msg := received["/oncall"][0]
+		assert.Equal(t, "iam users in prod", msg.Title)
+		assert.Equal(t, "1 aws_iam_user instances added (serial 4 → 5)\naws_iam_user.intruder", msg.Text)
+		assert.Equal(t, notifications.SeverityCritical, msg.Severity)
+	})
+
+	t.Run("named channels replace the lineage channels", func(t *testing.T) {
+		rule := models.AlertRule{Name: "iam users", Channels: []string{"ops"}}
+
+		require.NoError(t, notifier.Notify(context.Background(), rule, alert))
+
+		assert.Len(t, received["/ops"], 1)
+		assert.Len(t, received["/oncall"], 1)
+	})
+}
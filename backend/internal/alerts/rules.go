@@ -0,0 +1,189 @@
+// Package alerts evaluates user-defined rules against successive versions of a
+// state and delivers the resulting alerts to the channels of each rule.
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+
+	"github.com/terrascope/core/internal/diff"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/notifications"
+	"github.com/terrascope/core/internal/parser"
+)
+
+var severities = map[string]bool{
+	notifications.SeverityInfo:     true,
+	notifications.SeverityWarning:  true,
+	notifications.SeverityError:    true,
+	notifications.SeverityCritical: true,
+}
+
+var conditions = map[string]bool{
+	models.AlertOnAdded:        true,
+	models.AlertOnRemoved:      true,
+	models.AlertOnModified:     true,
+	models.AlertOnCountChanged: true,
+}
+
+// ValidateRule checks that the rule has a name, a known condition and
+// severity, a valid lineage pattern and absolute http(s) webhook URLs.
+func ValidateRule(rule models.AlertRule) error {
+	if rule.Name == "" {
+		return errors.New("missing name")
+	}
+
+	if !conditions[rule.Condition] {
+		return fmt.Errorf("unknown condition %q", rule.Condition)
+	}
+
+	if rule.Severity != "" && !severities[rule.Severity] {
+		return fmt.Errorf("unknown severity %q", rule.Severity)
+	}
+
+	if _, err := path.Match(rule.Lineage, ""); err != nil {
+		return fmt.Errorf("invalid lineage pattern %q", rule.Lineage)
+	}
+
+	for _, webhook := range rule.Webhooks {
+		u, err := url.Parse(webhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook URL %q", webhook)
+		}
+	}
+
+	return nil
+}
+
+// MatchesLineage reports whether the rule applies to the lineage.
+func MatchesLineage(rule models.AlertRule, lineage string) bool {
+	if rule.Lineage == "" {
+		return true
+	}
+
+	matched, _ := path.Match(rule.Lineage, lineage)
+
+	return matched
+}
+
+// Evaluate returns an alert for every rule that matches the change from
+// before to after. The caller fills in the time the alerts were triggered.
+func Evaluate(rules []models.AlertRule, before, after *models.TerraformState) []models.Alert {
+	alerts := []models.Alert{}
+
+	var changes *models.StateDiff
+	types := managedTypes(before, after)
+
+	for _, rule := range rules {
+		if !MatchesLineage(rule, after.Lineage) {
+			continue
+		}
+
+		alert := models.Alert{
+			RuleID:         rule.ID,
+			RuleName:       rule.Name,
+			Lineage:        after.Lineage,
+			Serial:         after.Serial,
+			PreviousSerial: before.Serial,
+			Condition:      rule.Condition,
+			Severity:       rule.Severity,
+		}
+
+		if alert.Severity == "" {
+			alert.Severity = notifications.SeverityWarning
+		}
+
+		if rule.Condition == models.AlertOnCountChanged {
+			prev, next := countResources(before, rule.ResourceType), countResources(after, rule.ResourceType)
+			if prev == next {
+				continue
+			}
+
+			alert.Before, alert.After = &prev, &next
+			alert.Message = fmt.Sprintf("%s count changed from %d to %d", resourceNoun(rule), prev, next)
+			alerts = append(alerts, alert)
+
+			continue
+		}
+
+		if changes == nil {
+			changes = diff.States(before, after)
+		}
+
+		var ids []string
+		switch rule.Condition {
+		case models.AlertOnAdded:
+			ids = ofType(changes.Added, types, rule.ResourceType)
+		case models.AlertOnRemoved:
+			ids = ofType(changes.Removed, types, rule.ResourceType)
+		case models.AlertOnModified:
+			modified := make([]string, 0, len(changes.Modified))
+			for _, change := range changes.Modified {
+				modified = append(modified, change.ID)
+			}
+			ids = ofType(modified, types, rule.ResourceType)
+		}
+
+		if len(ids) == 0 {
+			continue
+		}
+
+		alert.Resources = ids
+		alert.Message = fmt.Sprintf("%d %s instances %s", len(ids), resourceNoun(rule), rule.Condition)
+		alerts = append(alerts, alert)
+	}
+
+	return alerts
+}
+
+// managedTypes maps the node ID of every current managed instance in the
+// states to its resource type.
+func managedTypes(states ...*models.TerraformState) map[string]string {
+	types := make(map[string]string)
+
+	for _, state := range states {
+		for _, ref := range parser.Instances(state) {
+			if ref.Resource.Mode == "managed" && ref.Instance.Deposed == "" {
+				types[ref.NodeID] = ref.Resource.Type
+			}
+		}
+	}
+
+	return types
+}
+
+// ofType keeps the IDs of managed instances of the given type, or of any type
+// when resType is empty.
+func ofType(ids []string, types map[string]string, resType string) []string {
+	var matched []string
+
+	for _, id := range ids {
+		if t, ok := types[id]; ok && (resType == "" || t == resType) {
+			matched = append(matched, id)
+		}
+	}
+
+	return matched
+}
+
+func countResources(state *models.TerraformState, resType string) int {
+	count := 0
+
+	for _, t := range managedTypes(state) {
+		if resType == "" || t == resType {
+			count++
+		}
+	}
+
+	return count
+}
+
+func resourceNoun(rule models.AlertRule) string {
+	if rule.ResourceType == "" {
+		return "resource"
+	}
+
+	return rule.ResourceType
+}
@@ -0,0 +1,98 @@
+// Package alerts evaluates user-defined rules against successive versions of a
+// state and delivers the resulting alerts to the channels of each rule.
+package alerts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func resource(resType, name string, attrs map[string]any) models.ResourceState {
+	return models.ResourceState{
+		Mode:      "managed",
+		Type:      resType,
+		Name:      name,
+		Provider:  `provider["registry.terraform.io/hashicorp/aws"]`,
+		Instances: []models.ResourceInstance{{Attributes: attrs}},
+	}
+}
+
+func TestValidateRule(t *testing.T) {
+	valid := models.AlertRule{Name: "iam users", Condition: models.AlertOnAdded, Lineage: "prod-*", Webhooks: []string{"https://hooks.example.com/x"}}
+	require.NoError(t, ValidateRule(valid))
+
+	tests := []struct {
+		name   string
+		modify func(*models.AlertRule)
+		err    string
+	}{
+		{"missing name", func(r *models.AlertRule) { r.Name = "" }, "missing name"},
+		{"unknown condition", func(r *models.AlertRule) { r.Condition = "exploded" }, "unknown condition"},
+		{"unknown severity", func(r *models.AlertRule) { r.Severity = "apocalyptic" }, "unknown severity"},
+		{"bad pattern", func(r *models.AlertRule) { r.Lineage = "prod-[" }, "invalid lineage pattern"},
+		{"bad webhook", func(r *models.AlertRule) { r.Webhooks = []string{"ftp://example.com"} }, "invalid webhook URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := valid
+			tt.modify(&rule)
+
+			assert.ErrorContains(t, ValidateRule(rule), tt.err)
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	before := &models.TerraformState{
+		Lineage: "prod-eu",
+		Serial:  4,
+		Resources: []models.ResourceState{
+			resource("aws_security_group", "web", map[string]any{"id": "sg-1"}),
+			resource("aws_instance", "web", map[string]any{"id": "i-1", "instance_type": "t3.micro"}),
+			resource("aws_iam_user", "ci", map[string]any{"id": "ci"}),
+		},
+	}
+
+	after := &models.TerraformState{
+		Lineage: "prod-eu",
+		Serial:  5,
+		Resources: []models.ResourceState{
+			resource("aws_security_group", "web", map[string]any{"id": "sg-1"}),
+			resource("aws_security_group", "db", map[string]any{"id": "sg-2"}),
+			resource("aws_instance", "web", map[string]any{"id": "i-1", "instance_type": "t3.large"}),
+			resource("aws_iam_user", "ci", map[string]any{"id": "ci"}),
+			resource("aws_iam_user", "intruder", map[string]any{"id": "intruder"}),
+		},
+	}
+
+	rules := []models.AlertRule{
+		{ID: "1", Name: "prod security groups", Lineage: "prod-*", Condition: models.AlertOnCountChanged, ResourceType: "aws_security_group"},
+		{ID: "2", Name: "iam users", Condition: models.AlertOnAdded, ResourceType: "aws_iam_user", Severity: "critical"},
+		{ID: "3", Name: "modified", Condition: models.AlertOnModified},
+		{ID: "4", Name: "removed", Condition: models.AlertOnRemoved},
+		{ID: "5", Name: "staging", Lineage: "staging", Condition: models.AlertOnAdded},
+	}
+
+	alerts := Evaluate(rules, before, after)
+	require.Len(t, alerts, 3)
+
+	assert.Equal(t, "1", alerts[0].RuleID)
+	assert.Equal(t, "aws_security_group count changed from 1 to 2", alerts[0].Message)
+	assert.Equal(t, 1, *alerts[0].Before)
+	assert.Equal(t, 2, *alerts[0].After)
+	assert.Equal(t, 4, alerts[0].PreviousSerial)
+	assert.Equal(t, 5, alerts[0].Serial)
+	assert.Equal(t, "warning", alerts[0].Severity)
+
+	assert.Equal(t, "2", alerts[1].RuleID)
+	assert.Equal(t, []string{"aws_iam_user.intruder"}, alerts[1].Resources)
+	assert.Equal(t, "1 aws_iam_user instances added", alerts[1].Message)
+	assert.Equal(t, "critical", alerts[1].Severity)
+
+	assert.Equal(t, "3", alerts[2].RuleID)
+	assert.Equal(t, []string{"aws_instance.web"}, alerts[2].Resources)
+}
@@ -0,0 +1,208 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/terrascope/core/internal/codes"
+	"github.com/terrascope/core/internal/models"
+)
+
+// Analyzer is an analysis that reports findings about the nodes of a graph,
+// which it can correlate with the state the graph was built from. Analyzers
+// register under their name, by which requests select them. Analyze returns
+// early once the context is done, since its findings are then dropped.
+type Analyzer interface {
+	Name() string
+	Description() string
+	Analyze(ctx context.Context, graph *models.Graph, state *models.TerraformState) []models.Finding
+}
+
+var (
+	analyzersMu sync.RWMutex
+	analyzers   = make(map[string]Analyzer)
+)
+
+// Register makes an analyzer available by its name, replacing the analyzer
+// registered under the same name.
+func Register(analyzer Analyzer) {
+	analyzersMu.Lock()
+	defer analyzersMu.Unlock()
+
+	analyzers[analyzer.Name()] = analyzer
+}
+
+// Analyzers returns every registered analyzer ordered by name.
+func Analyzers() []Analyzer {
+	analyzersMu.RLock()
+	defer analyzersMu.RUnlock()
+
+	registered := make([]Analyzer, 0, len(analyzers))
+	for _, analyzer := range analyzers {
+		registered = append(registered, analyzer)
+	}
+
+	sort.Slice(registered, func(i, j int) bool { return registered[i].Name() < registered[j].Name() })
+
+	return registered
+}
+
+// SelectAnalyzers picks analyzers from a comma-separated list of names, such
+// as security,tags. Names prefixed with - are left out, of every analyzer when
+// no other name is listed; an empty list selects every analyzer.
+func SelectAnalyzers(list string) ([]Analyzer, error) {
+	registered := Analyzers()
+
+	byName := make(map[string]Analyzer, len(registered))
+	for _, analyzer := range registered {
+		byName[analyzer.Name()] = analyzer
+	}
+
+	var included []Analyzer
+	excluded := make(map[string]bool)
+
+	for name := range strings.SplitSeq(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		exclude := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		analyzer, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown analyzer %q", name)
+		}
+
+		if exclude {
+			excluded[name] = true
+		} else if !slices.ContainsFunc(included, func(a Analyzer) bool { return a.Name() == name }) {
+			included = append(included, analyzer)
+		}
+	}
+
+	if included == nil {
+		included = registered
+	}
+
+	selected := []Analyzer{}
+	for _, analyzer := range included {
+		if !excluded[analyzer.Name()] {
+			selected = append(selected, analyzer)
+		}
+	}
+
+	return selected, nil
+}
+
+// RunAnalyzers runs the analyzers in turn and returns their findings in that
+// order, identified by the analyzer that reported them.
+func RunAnalyzers(selected []Analyzer, graph *models.Graph, state *models.TerraformState) []models.Finding {
+	findings := []models.Finding{}
+
+	for _, analyzer := range selected {
+		findings = append(findings, identify(analyzer.Name(), analyzer.Analyze(context.Background(), graph, state))...)
+	}
+
+	return findings
+}
+
+// RunAnalyzersContext runs the analyzers concurrently until the context is
+// done, so that a slow analyzer does not hold up the others. It then returns
+// the findings of the analyzers that completed, in the order of the
+// analyzers, with the names of those that did not. The analyzers still
+// running stop with the context, and their findings are dropped.
+func RunAnalyzersContext(ctx context.Context, selected []Analyzer, graph *models.Graph, state *models.TerraformState) (findings []models.Finding, incomplete []string) {
+	type result struct {
+		index    int
+		findings []models.Finding
+		panic    any
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan result, len(selected))
+	for i, analyzer := range selected {
+		go func() {
+			// A panicking analyzer panics the caller, as with
+			// RunAnalyzers, rather than the whole process.
+			defer func() {
+				if p := recover(); p != nil {
+					results <- result{index: i, panic: p}
+				}
+			}()
+
+			results <- result{index: i, findings: identify(analyzer.Name(), analyzer.Analyze(ctx, graph, state))}
+		}()
+	}
+
+	completed := make([][]models.Finding, len(selected))
+	done := make([]bool, len(selected))
+
+	for range selected {
+		select {
+		case r := <-results:
+			if r.panic != nil {
+				panic(r.panic)
+			}
+			completed[r.index], done[r.index] = r.findings, true
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	findings = []models.Finding{}
+	for i, analyzer := range selected {
+		if done[i] {
+			findings = append(findings, completed[i]...)
+		} else {
+			incomplete = append(incomplete, analyzer.Name())
+		}
+	}
+
+	return findings, incomplete
+}
+
+// identify sets the analyzer, the code and the ID of findings. The ID digests
+// the analyzer, the check, the node and the message, which tells apart the
+// findings of a check on the same node.
+func identify(analyzer string, findings []models.Finding) []models.Finding {
+	for i := range findings {
+		finding := &findings[i]
+		finding.Analyzer = analyzer
+		finding.Code = codes.ForFinding(analyzer, finding.Check)
+
+		sum := sha256.Sum256([]byte(strings.Join([]string{analyzer, finding.Check, finding.NodeID, finding.Message}, "\x00")))
+		finding.ID = hex.EncodeToString(sum[:8])
+	}
+
+	return findings
+}
+
+// Manifest describes the registered analyzers.
+func Manifest() *models.AnalyzerManifest {
+	manifest := &models.AnalyzerManifest{Analyzers: []models.AnalyzerInfo{}}
+
+	for _, analyzer := range Analyzers() {
+		manifest.Analyzers = append(manifest.Analyzers, models.AnalyzerInfo{
+			Name:        analyzer.Name(),
+			Description: analyzer.Description(),
+		})
+	}
+
+	return manifest
+}
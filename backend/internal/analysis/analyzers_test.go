@@ -0,0 +1,229 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/codes"
+	"github.com/terrascope/core/internal/models"
+)
+
+type fakeAnalyzer string
+
+func (a fakeAnalyzer) Name() string        { return string(a) }
+func (a fakeAnalyzer) Description() string { return "fake " + string(a) }
+
+func (a fakeAnalyzer) Analyze(_ context.Context, graph *models.Graph, _ *models.TerraformState) []models.Finding {
+	findings := []models.Finding{}
+	for _, node := range graph.Nodes {
+		findings = append(findings, models.Finding{Check: string(a), NodeID: node.ID})
+	}
+
+	return findings
+}
+
+func TestRegister(t *testing.T) {
+	Register(fakeAnalyzer("zz-fake"))
+	t.Cleanup(func() {
+		analyzersMu.Lock()
+		delete(analyzers, "zz-fake")
+		analyzersMu.Unlock()
+	})
+
+	registered := Analyzers()
+	require.NotEmpty(t, registered)
+	assert.Equal(t, "zz-fake", registered[len(registered)-1].Name(), "analyzers are ordered by name")
+
+	var names []string
+	for _, analyzer := range Manifest().Analyzers {
+		names = append(names, analyzer.Name)
+		assert.NotEmpty(t, analyzer.Description)
+	}
+	assert.Subset(t, names, []string{"security", "stale", "tags", "zz-fake"})
+}
+
+func TestSelectAnalyzers(t *testing.T) {
+	names := func(selected []Analyzer) []string {
+		names := []string{}
+		for _, analyzer := range selected {
+			names = append(names, analyzer.Name())
+		}
+		return names
+	}
+
+	all, err := SelectAnalyzers("")
+	require.NoError(t, err)
+	assert.Equal(t, names(Analyzers()), names(all))
+
+	selected, err := SelectAnalyzers("tags, security,tags")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tags", "security"}, names(selected))
+
+	selected, err = SelectAnalyzers("-stale")
+	require.NoError(t, err)
+	assert.NotContains(t, names(selected), "stale")
+	assert.Len(t, selected, len(all)-1)
+
+	selected, err = SelectAnalyzers("stale,tags,-stale")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tags"}, names(selected))
+
+	_, err = SelectAnalyzers("security,lint")
+	assert.EqualError(t, err, `unknown analyzer "lint"`)
+}
+
+func TestRunAnalyzers(t *testing.T) {
+	graph := &models.Graph{Nodes: []models.Node{{ID: "a"}, {ID: "b"}}}
+
+	findings := RunAnalyzers([]Analyzer{fakeAnalyzer("first"), fakeAnalyzer("second")}, graph, &models.TerraformState{})
+
+	type result struct{ analyzer, check, node string }
+
+	var results []result
+	ids := make(map[string]bool)
+	for _, finding := range findings {
+		results = append(results, result{finding.Analyzer, finding.Check, finding.NodeID})
+		assert.Len(t, finding.ID, 16)
+		ids[finding.ID] = true
+	}
+
+	assert.Equal(t, []result{
+		{"first", "first", "a"}, {"first", "first", "b"},
+		{"second", "second", "a"}, {"second", "second", "b"},
+	}, results)
+	assert.Len(t, ids, 4, "every finding has its own ID")
+
+	again := RunAnalyzers([]Analyzer{fakeAnalyzer("first")}, graph, nil)
+	assert.Equal(t, findings[0].ID, again[0].ID, "IDs are stable across runs")
+
+	assert.NotNil(t, RunAnalyzers(nil, graph, nil))
+}
+
+// stoppableAnalyzer runs until its context is done, telling when it stopped.
+type stoppableAnalyzer struct {
+	stopped chan struct{}
+}
+
+func (a stoppableAnalyzer) Name() string        { return "stoppable" }
+func (a stoppableAnalyzer) Description() string { return "runs until stopped" }
+
+func (a stoppableAnalyzer) Analyze(ctx context.Context, _ *models.Graph, _ *models.TerraformState) []models.Finding {
+	<-ctx.Done()
+	close(a.stopped)
+
+	return []models.Finding{{Check: "late"}}
+}
+
+// meetingAnalyzer completes once the analyzer it meets has started, which it
+// only does when they run at the same time.
+type meetingAnalyzer struct {
+	name           string
+	started, other chan struct{}
+}
+
+func (a meetingAnalyzer) Name() string        { return a.name }
+func (a meetingAnalyzer) Description() string { return "meets another analyzer" }
+
+func (a meetingAnalyzer) Analyze(ctx context.Context, _ *models.Graph, _ *models.TerraformState) []models.Finding {
+	close(a.started)
+
+	select {
+	case <-a.other:
+		return []models.Finding{{Check: "met"}}
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+func TestRunAnalyzersContext(t *testing.T) {
+	graph := &models.Graph{Nodes: []models.Node{{ID: "a"}}}
+
+	t.Run("runs every analyzer in time", func(t *testing.T) {
+		findings, incomplete := RunAnalyzersContext(context.Background(), []Analyzer{fakeAnalyzer("first"), fakeAnalyzer("second")}, graph, nil)
+
+		assert.Len(t, findings, 2)
+		assert.Nil(t, incomplete)
+	})
+
+	t.Run("returns the findings of the analyzers that completed", func(t *testing.T) {
+		stoppable := stoppableAnalyzer{stopped: make(chan struct{})}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		findings, incomplete := RunAnalyzersContext(ctx, []Analyzer{fakeAnalyzer("first"), stoppable, fakeAnalyzer("last")}, graph, nil)
+
+		require.Len(t, findings, 2)
+		assert.Equal(t, "first", findings[0].Analyzer)
+		assert.Equal(t, "last", findings[1].Analyzer)
+		assert.Equal(t, []string{"stoppable"}, incomplete)
+
+		select {
+		case <-stoppable.stopped:
+		case <-time.After(time.Second):
+			t.Fatal("the incomplete analyzer kept running")
+		}
+	})
+
+	t.Run("runs the analyzers at the same time", func(t *testing.T) {
+		left, right := make(chan struct{}), make(chan struct{})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		findings, incomplete := RunAnalyzersContext(ctx, []Analyzer{
+			meetingAnalyzer{name: "left", started: left, other: right},
+			meetingAnalyzer{name: "right", started: right, other: left},
+		}, graph, nil)
+
+		assert.Len(t, findings, 2)
+		assert.Nil(t, incomplete)
+	})
+
+	t.Run("panics with the analyzer", func(t *testing.T) {
+		assert.PanicsWithValue(t, "boom", func() {
+			RunAnalyzersContext(context.Background(), []Analyzer{panickingAnalyzer{}}, graph, nil)
+		})
+	})
+}
+
+type panickingAnalyzer struct{}
+
+func (panickingAnalyzer) Name() string        { return "panicking" }
+func (panickingAnalyzer) Description() string { return "panics" }
+
+func (panickingAnalyzer) Analyze(context.Context, *models.Graph, *models.TerraformState) []models.Finding {
+	panic("boom")
+}
+
+func TestCodes(t *testing.T) {
+	for _, warningType := range []string{
+		warningTypeMissingConstraint, warningTypeInvalidConstraint, warningTypeConstraintMismatch,
+		warningTypeUnlockedProvider, warningTypeOutdatedProvider, warningTypeRegistryError, warningTypeUnusedLock,
+		warningTypeAtLimit, warningTypeApproachingLimit, warningTypeLimitForecast,
+		warningTypeProtected, warningTypeUnknownTarget, warningTypeCycle,
+	} {
+		assert.NotEmpty(t, codes.ForWarning(warningType), "warnings of type %s have a code", warningType)
+	}
+
+	for _, c := range controls {
+		for _, check := range c.checks {
+			analyzer, name, _ := strings.Cut(check, "/")
+			if name != "*" {
+				assert.NotEmpty(t, codes.ForFinding(analyzer, name), "findings of %s have a code", check)
+			}
+		}
+	}
+
+	for _, pattern := range secretPatterns {
+		assert.NotEmpty(t, codes.ForFinding("secrets", pattern.check), "findings of secrets/%s have a code", pattern.check)
+	}
+	assert.NotEmpty(t, codes.ForFinding("secrets", "high_entropy"))
+}
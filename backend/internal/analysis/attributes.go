@@ -0,0 +1,88 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"sort"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+func managedInstances(state *models.TerraformState) []parser.InstanceRef {
+	refs := []parser.InstanceRef{}
+
+	for _, ref := range parser.Instances(state) {
+		if ref.Resource.Mode == "managed" {
+			refs = append(refs, ref)
+		}
+	}
+
+	return refs
+}
+
+func stringAttr(attrs map[string]any, key string) string {
+	value, _ := attrs[key].(string)
+
+	return value
+}
+
+func boolAttr(attrs map[string]any, key string) bool {
+	value, _ := attrs[key].(bool)
+
+	return value
+}
+
+// stringList returns the string elements of a list or set attribute.
+func stringList(value any) []string {
+	items, ok := value.([]any)
+	if !ok {
+		return nil
+	}
+
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok && s != "" {
+			values = append(values, s)
+		}
+	}
+
+	return values
+}
+
+// objectList returns the elements of a nested block attribute that are objects.
+func objectList(value any) []map[string]any {
+	items, ok := value.([]any)
+	if !ok {
+		return nil
+	}
+
+	objects := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		if obj, ok := item.(map[string]any); ok {
+			objects = append(objects, obj)
+		}
+	}
+
+	return objects
+}
+
+func uniqueSorted(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" && !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+
+	sort.Strings(unique)
+
+	return unique
+}
@@ -0,0 +1,61 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestManagedInstances(t *testing.T) {
+	t.Run("skips data sources", func(t *testing.T) {
+		data := managedResource("aws_ami", "ubuntu", map[string]any{"id": "ami-1"})
+		data.Mode = "data"
+		state := &models.TerraformState{
+			Resources: []models.ResourceState{
+				data,
+				managedResource("aws_instance", "web", map[string]any{"id": "i-1"}),
+			},
+		}
+
+		refs := managedInstances(state)
+
+		assert.Len(t, refs, 1)
+		assert.Equal(t, "aws_instance.web", refs[0].NodeID)
+	})
+}
+
+func TestStringList(t *testing.T) {
+	t.Run("keeps non-empty strings", func(t *testing.T) {
+		assert.Equal(t, []string{"a", "b"}, stringList([]any{"a", "", 1, "b"}))
+	})
+
+	t.Run("non-list returns nil", func(t *testing.T) {
+		assert.Nil(t, stringList("a"))
+	})
+}
+
+func TestObjectList(t *testing.T) {
+	t.Run("keeps objects only", func(t *testing.T) {
+		objects := objectList([]any{map[string]any{"a": 1}, "b"})
+
+		assert.Len(t, objects, 1)
+	})
+
+	t.Run("non-list returns nil", func(t *testing.T) {
+		assert.Nil(t, objectList(map[string]any{}))
+	})
+}
+
+func TestUniqueSorted(t *testing.T) {
+	t.Run("deduplicates and sorts", func(t *testing.T) {
+		assert.Equal(t, []string{"a", "b"}, uniqueSorted([]string{"b", "a", "", "b"}))
+	})
+
+	t.Run("empty input returns nil", func(t *testing.T) {
+		assert.Nil(t, uniqueSorted(nil))
+	})
+}
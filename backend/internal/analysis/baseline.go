@@ -0,0 +1,29 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"github.com/terrascope/core/internal/models"
+)
+
+// NewFindings drops the findings already reported in baseline, the findings
+// of an earlier version of the state, so that checks can be adopted on
+// existing infrastructure by failing only on the problems a change
+// introduces. Findings are matched by ID. It also returns how many findings
+// were known.
+func NewFindings(findings, baseline []models.Finding) ([]models.Finding, int) {
+	known := make(map[string]bool, len(baseline))
+	for _, finding := range baseline {
+		known[finding.ID] = true
+	}
+
+	fresh := []models.Finding{}
+	for _, finding := range findings {
+		if !known[finding.ID] {
+			fresh = append(fresh, finding)
+		}
+	}
+
+	return fresh, len(findings) - len(fresh)
+}
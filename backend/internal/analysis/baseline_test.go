@@ -0,0 +1,40 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestNewFindings(t *testing.T) {
+	bucket := models.Finding{ID: "0123456789abcdef", Analyzer: "security", Check: "public_bucket"}
+	volume := models.Finding{ID: "fedcba9876543210", Analyzer: "security", Check: "unencrypted_storage"}
+	fixed := models.Finding{ID: "00112233445566ff", Analyzer: "stale", Check: "tainted"}
+
+	t.Run("keeps the findings missing from the baseline", func(t *testing.T) {
+		fresh, known := NewFindings([]models.Finding{bucket, volume}, []models.Finding{bucket, fixed})
+
+		assert.Equal(t, []models.Finding{volume}, fresh)
+		assert.Equal(t, 1, known)
+	})
+
+	t.Run("keeps every finding without a baseline", func(t *testing.T) {
+		fresh, known := NewFindings([]models.Finding{bucket, volume}, nil)
+
+		assert.Equal(t, []models.Finding{bucket, volume}, fresh)
+		assert.Zero(t, known)
+	})
+
+	t.Run("returns no findings when all are known", func(t *testing.T) {
+		fresh, known := NewFindings([]models.Finding{bucket}, []models.Finding{bucket})
+
+		assert.Empty(t, fresh)
+		assert.NotNil(t, fresh)
+		assert.Equal(t, 1, known)
+	})
+}
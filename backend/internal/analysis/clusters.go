@@ -0,0 +1,181 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+// maxPropagationRounds bounds label propagation on graphs that keep
+// oscillating between equally good labellings.
+const maxPropagationRounds = 100
+
+// Clusters groups tightly connected resources by label propagation over the
+// dependency graph, ignoring edge direction. Each node starts with its own
+// label and repeatedly adopts the label most common among its neighbours,
+// visiting nodes in ID order and breaking ties towards the current, then the
+// smallest, label so the result is deterministic. Clusters smaller than
+// minSize are counted as unclustered. Each cluster is labelled after its
+// dominant module when most of its nodes share one, otherwise after its
+// dominant resource type.
+func Clusters(state *models.TerraformState, minSize int) *models.ClusterReport {
+	graph := parser.BuildGraphWithOptions(state, parser.GraphOptions{InstanceEdges: true})
+
+	nodes := make(map[string]models.Node, len(graph.Nodes))
+	ids := make([]string, 0, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		nodes[node.ID] = node
+		ids = append(ids, node.ID)
+	}
+	sort.Strings(ids)
+
+	neighbours := make(map[string]map[string]int)
+	for _, edge := range graph.Edges {
+		if _, ok := nodes[edge.Target]; !ok || edge.Source == edge.Target {
+			continue
+		}
+		link(neighbours, edge.Source, edge.Target)
+		link(neighbours, edge.Target, edge.Source)
+	}
+
+	labels := propagateLabels(ids, neighbours)
+
+	members := make(map[string][]string)
+	for _, id := range ids {
+		members[labels[id]] = append(members[labels[id]], id)
+	}
+
+	report := &models.ClusterReport{Clusters: []models.Cluster{}}
+
+	for _, group := range members {
+		if len(group) < minSize {
+			report.Unclustered += len(group)
+			continue
+		}
+
+		report.Clusters = append(report.Clusters, describeCluster(group, nodes, neighbours, labels))
+	}
+
+	sort.Slice(report.Clusters, func(i, j int) bool {
+		a, b := report.Clusters[i], report.Clusters[j]
+		if a.Size != b.Size {
+			return a.Size > b.Size
+		}
+
+		return a.Nodes[0] < b.Nodes[0]
+	})
+
+	for i := range report.Clusters {
+		report.Clusters[i].ID = fmt.Sprintf("cluster-%d", i+1)
+	}
+
+	return report
+}
+
+func link(neighbours map[string]map[string]int, from, to string) {
+	if neighbours[from] == nil {
+		neighbours[from] = make(map[string]int)
+	}
+	neighbours[from][to]++
+}
+
+func propagateLabels(ids []string, neighbours map[string]map[string]int) map[string]string {
+	labels := make(map[string]string, len(ids))
+	for _, id := range ids {
+		labels[id] = id
+	}
+
+	for round := 0; round < maxPropagationRounds; round++ {
+		changed := false
+
+		for _, id := range ids {
+			weights := make(map[string]int)
+			for neighbour, count := range neighbours[id] {
+				weights[labels[neighbour]] += count
+			}
+
+			maxWeight := 0
+			for _, weight := range weights {
+				maxWeight = max(maxWeight, weight)
+			}
+
+			if maxWeight == 0 || weights[labels[id]] == maxWeight {
+				continue
+			}
+
+			best := ""
+			for label, weight := range weights {
+				if weight == maxWeight && (best == "" || label < best) {
+					best = label
+				}
+			}
+
+			labels[id] = best
+			changed = true
+		}
+
+		if !changed {
+			break
+		}
+	}
+
+	return labels
+}
+
+func describeCluster(group []string, nodes map[string]models.Node, neighbours map[string]map[string]int, labels map[string]string) models.Cluster {
+	modules := make(map[string]int)
+	types := make(map[string]int)
+	cluster := models.Cluster{Size: len(group), Nodes: group}
+
+	for _, id := range group {
+		node := nodes[id]
+		modules[node.Module]++
+		types[node.Type]++
+
+		for neighbour, count := range neighbours[id] {
+			if labels[neighbour] == labels[id] {
+				cluster.InternalEdges += count
+			} else {
+				cluster.ExternalEdges += count
+			}
+		}
+	}
+
+	// Internal edges were counted from both ends.
+	cluster.InternalEdges /= 2
+
+	module, moduleCount := dominant(modules)
+	cluster.DominantType, _ = dominant(types)
+
+	if module != "" && moduleCount*2 > len(group) {
+		cluster.DominantModule = module
+		cluster.Label = module
+		return cluster
+	}
+
+	cluster.Label = cluster.DominantType
+	if len(types) > 1 {
+		cluster.Label = fmt.Sprintf("%s and %d other types", cluster.DominantType, len(types)-1)
+	}
+
+	return cluster
+}
+
+// dominant returns the most frequent key, preferring the smallest on ties.
+func dominant(counts map[string]int) (string, int) {
+	var best string
+	bestCount := 0
+
+	for key, count := range counts {
+		if count > bestCount || count == bestCount && key < best {
+			best, bestCount = key, count
+		}
+	}
+
+	return best, bestCount
+}
@@ -0,0 +1,115 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+// clusterState holds two densely connected groups, a network group and an
+// application group, joined by a single edge, plus one isolated bucket.
+func clusterState() *models.TerraformState {
+	app := func(resType, name string, deps ...string) models.ResourceState {
+		res := dependentResource(resType, name, nil, deps...)
+		res.Module = "module.app"
+
+		return res
+	}
+
+	return &models.TerraformState{
+		Resources: []models.ResourceState{
+			dependentResource("aws_vpc", "main", nil),
+			dependentResource("aws_subnet", "a", nil, "aws_vpc.main"),
+			dependentResource("aws_subnet", "b", nil, "aws_vpc.main"),
+			dependentResource("aws_route_table", "main", nil, "aws_vpc.main", "aws_subnet.a", "aws_subnet.b"),
+			app("aws_iam_role", "web"),
+			app("aws_iam_role_policy", "web", "module.app.aws_iam_role.web"),
+			app("aws_instance", "web", "module.app.aws_iam_role.web", "module.app.aws_iam_role_policy.web", "aws_subnet.a"),
+			app("aws_eip", "web", "module.app.aws_instance.web", "module.app.aws_iam_role.web"),
+			dependentResource("aws_s3_bucket", "logs", nil),
+		},
+	}
+}
+
+func TestClusters(t *testing.T) {
+	t.Run("groups connected resources", func(t *testing.T) {
+		report := Clusters(clusterState(), 2)
+
+		require.Len(t, report.Clusters, 2)
+		assert.Equal(t, 1, report.Unclustered)
+
+		assert.ElementsMatch(t, []string{
+			"aws_route_table.main",
+			"aws_subnet.a",
+			"aws_subnet.b",
+			"aws_vpc.main",
+		}, report.Clusters[0].Nodes)
+		assert.ElementsMatch(t, []string{
+			"module.app.aws_eip.web",
+			"module.app.aws_iam_role.web",
+			"module.app.aws_iam_role_policy.web",
+			"module.app.aws_instance.web",
+		}, report.Clusters[1].Nodes)
+	})
+
+	t.Run("labels clusters by dominant module or type", func(t *testing.T) {
+		report := Clusters(clusterState(), 2)
+
+		network, app := report.Clusters[0], report.Clusters[1]
+
+		assert.Equal(t, "module.app", app.Label)
+		assert.Equal(t, "module.app", app.DominantModule)
+		assert.Equal(t, "aws_subnet and 2 other types", network.Label)
+		assert.Empty(t, network.DominantModule)
+		assert.Equal(t, "aws_subnet", network.DominantType)
+	})
+
+	t.Run("counts internal and external edges", func(t *testing.T) {
+		report := Clusters(clusterState(), 2)
+
+		assert.Equal(t, 5, report.Clusters[0].InternalEdges)
+		assert.Equal(t, 1, report.Clusters[0].ExternalEdges)
+		assert.Equal(t, 5, report.Clusters[1].InternalEdges)
+		assert.Equal(t, 1, report.Clusters[1].ExternalEdges)
+	})
+
+	t.Run("clusters are ordered and numbered by size", func(t *testing.T) {
+		state := clusterState()
+		state.Resources = append(state.Resources, dependentResource("aws_vpc_endpoint", "s3", nil, "aws_vpc.main", "aws_route_table.main"))
+
+		report := Clusters(state, 2)
+
+		assert.Equal(t, "cluster-1", report.Clusters[0].ID)
+		assert.Equal(t, 5, report.Clusters[0].Size)
+		assert.Equal(t, "cluster-2", report.Clusters[1].ID)
+		assert.Equal(t, 4, report.Clusters[1].Size)
+	})
+
+	t.Run("min size one keeps isolated resources", func(t *testing.T) {
+		report := Clusters(clusterState(), 1)
+
+		require.Len(t, report.Clusters, 3)
+		assert.Equal(t, 0, report.Unclustered)
+		assert.Equal(t, []string{"aws_s3_bucket.logs"}, report.Clusters[2].Nodes)
+		assert.Equal(t, "aws_s3_bucket", report.Clusters[2].Label)
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		first := Clusters(clusterState(), 2)
+		for range 10 {
+			assert.Equal(t, first, Clusters(clusterState(), 2))
+		}
+	})
+
+	t.Run("empty state", func(t *testing.T) {
+		report := Clusters(&models.TerraformState{}, 2)
+
+		assert.Empty(t, report.Clusters)
+		assert.Equal(t, 0, report.Unclustered)
+	})
+}
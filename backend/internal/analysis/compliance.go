@@ -0,0 +1,116 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// Frameworks the built-in checks map to.
+const (
+	FrameworkCIS  = "CIS AWS Foundations Benchmark v3.0.0"
+	FrameworkNIST = "NIST SP 800-53 Rev. 5"
+	FrameworkSOC2 = "SOC 2"
+)
+
+// control is a control of a framework and the checks, as <analyzer>/<check>,
+// that give evidence for it. A check of * stands for every check of its
+// analyzer.
+type control struct {
+	framework string
+	id        string
+	title     string
+	checks    []string
+}
+
+// controls maps the built-in checks to framework controls, in report order.
+var controls = []control{
+	{FrameworkCIS, "2.1.4", "Ensure that S3 buckets are configured with Block public access", []string{"security/public_bucket"}},
+	{FrameworkCIS, "2.2.1", "Ensure that EBS volume encryption is enabled", []string{"security/unencrypted_storage"}},
+	{FrameworkCIS, "2.3.1", "Ensure that encryption-at-rest is enabled for RDS instances", []string{"security/unencrypted_storage"}},
+	{FrameworkCIS, "2.3.3", "Ensure that public access is not given to RDS instances", []string{"security/public_database"}},
+	{FrameworkCIS, "5.2", "Ensure no security groups allow ingress from 0.0.0.0/0 to remote server administration ports", []string{"security/open_ingress"}},
+	{FrameworkCIS, "5.3", "Ensure no security groups allow ingress from ::/0 to remote server administration ports", []string{"security/open_ingress"}},
+
+	{FrameworkNIST, "AC-3", "Access Enforcement", []string{"security/public_bucket", "security/public_database"}},
+	{FrameworkNIST, "CM-8", "System Component Inventory", []string{"tags/untagged", "unused/unused_data_source"}},
+	{FrameworkNIST, "IA-5", "Authenticator Management", []string{"secrets/*"}},
+	{FrameworkNIST, "SC-7", "Boundary Protection", []string{"security/open_ingress", "security/public_database"}},
+	{FrameworkNIST, "SC-28", "Protection of Information at Rest", []string{"security/unencrypted_storage", "secrets/*"}},
+	{FrameworkNIST, "SI-2", "Flaw Remediation", []string{"stale/tainted", "stale/deposed"}},
+
+	{FrameworkSOC2, "CC6.1", "Logical access security over protected information assets", []string{"security/public_bucket", "security/public_database", "security/unencrypted_storage", "secrets/*"}},
+	{FrameworkSOC2, "CC6.6", "Security measures against threats from sources outside system boundaries", []string{"security/open_ingress"}},
+	{FrameworkSOC2, "CC7.1", "Detection of configuration changes that introduce vulnerabilities", []string{"stale/tainted", "stale/deposed"}},
+	{FrameworkSOC2, "CC8.1", "Authorized and documented changes to infrastructure", []string{"tags/untagged"}},
+}
+
+// Compliance assesses the framework controls against the findings of the
+// named analyzers. A control passes when its analyzers ran without findings
+// of its checks, fails when there are some, and is not assessed when none of
+// its analyzers ran. Frameworks are reported in the order of controls.
+func Compliance(analyzers []string, findings []models.Finding) *models.ComplianceReport {
+	report := &models.ComplianceReport{Frameworks: []models.FrameworkCoverage{}}
+
+	for _, c := range controls {
+		i := slices.IndexFunc(report.Frameworks, func(f models.FrameworkCoverage) bool { return f.Framework == c.framework })
+		if i < 0 {
+			report.Frameworks = append(report.Frameworks, models.FrameworkCoverage{Framework: c.framework, Controls: []models.ControlStatus{}})
+			i = len(report.Frameworks) - 1
+		}
+		framework := &report.Frameworks[i]
+
+		status := models.ControlStatus{ID: c.id, Title: c.title, Checks: c.checks, Status: models.ControlNotAssessed}
+
+		for _, check := range c.checks {
+			analyzer, _, _ := strings.Cut(check, "/")
+			if slices.Contains(analyzers, analyzer) {
+				status.Status = models.ControlPass
+			}
+		}
+
+		for _, finding := range findings {
+			if c.covers(finding) {
+				status.Status = models.ControlFail
+				status.Findings = append(status.Findings, finding.ID)
+			}
+		}
+
+		switch status.Status {
+		case models.ControlPass:
+			framework.Assessed++
+			framework.Passed++
+		case models.ControlFail:
+			framework.Assessed++
+			framework.Failed++
+		}
+
+		framework.Controls = append(framework.Controls, status)
+	}
+
+	for i := range report.Frameworks {
+		framework := &report.Frameworks[i]
+		if framework.Assessed > 0 {
+			framework.Coverage = math.Round(float64(framework.Passed)/float64(framework.Assessed)*1000) / 10
+		}
+	}
+
+	return report
+}
+
+// covers reports whether the finding is of one of the checks of the control.
+func (c control) covers(finding models.Finding) bool {
+	for _, check := range c.checks {
+		analyzer, name, _ := strings.Cut(check, "/")
+		if finding.Analyzer == analyzer && (name == "*" || finding.Check == name) {
+			return true
+		}
+	}
+
+	return false
+}
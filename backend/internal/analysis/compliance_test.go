@@ -0,0 +1,75 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestCompliance(t *testing.T) {
+	findings := []models.Finding{
+		{ID: "0123456789abcdef", Analyzer: "security", Check: "public_bucket", Severity: models.SeverityError},
+		{ID: "fedcba9876543210", Analyzer: "secrets", Check: "aws_access_key", Severity: models.SeverityError},
+	}
+
+	report := Compliance([]string{"security", "secrets"}, findings)
+
+	require.Len(t, report.Frameworks, 3)
+
+	control := func(framework, id string) models.ControlStatus {
+		t.Helper()
+		i := slices.IndexFunc(report.Frameworks, func(f models.FrameworkCoverage) bool { return f.Framework == framework })
+		require.GreaterOrEqual(t, i, 0)
+		j := slices.IndexFunc(report.Frameworks[i].Controls, func(c models.ControlStatus) bool { return c.ID == id })
+		require.GreaterOrEqual(t, j, 0)
+		return report.Frameworks[i].Controls[j]
+	}
+
+	t.Run("fails the controls of the checks with findings", func(t *testing.T) {
+		cis := control(FrameworkCIS, "2.1.4")
+		assert.Equal(t, models.ControlFail, cis.Status)
+		assert.Equal(t, []string{"0123456789abcdef"}, cis.Findings)
+
+		soc2 := control(FrameworkSOC2, "CC6.1")
+		assert.Equal(t, models.ControlFail, soc2.Status)
+		assert.Equal(t, []string{"0123456789abcdef", "fedcba9876543210"}, soc2.Findings)
+
+		assert.Equal(t, models.ControlFail, control(FrameworkNIST, "IA-5").Status)
+	})
+
+	t.Run("passes the controls of the checks without findings", func(t *testing.T) {
+		assert.Equal(t, models.ControlPass, control(FrameworkCIS, "5.2").Status)
+		assert.Equal(t, models.ControlPass, control(FrameworkNIST, "SC-7").Status)
+		assert.Empty(t, control(FrameworkNIST, "SC-7").Findings)
+	})
+
+	t.Run("does not assess the controls of analyzers that did not run", func(t *testing.T) {
+		assert.Equal(t, models.ControlNotAssessed, control(FrameworkNIST, "CM-8").Status)
+		assert.Equal(t, models.ControlNotAssessed, control(FrameworkSOC2, "CC7.1").Status)
+	})
+
+	t.Run("summarizes the coverage of each framework", func(t *testing.T) {
+		cis := report.Frameworks[0]
+		assert.Equal(t, FrameworkCIS, cis.Framework)
+		assert.Equal(t, 6, cis.Assessed)
+		assert.Equal(t, 5, cis.Passed)
+		assert.Equal(t, 1, cis.Failed)
+		assert.Equal(t, 83.3, cis.Coverage)
+	})
+
+	t.Run("reports no coverage without assessed controls", func(t *testing.T) {
+		report := Compliance(nil, nil)
+
+		for _, framework := range report.Frameworks {
+			assert.Zero(t, framework.Assessed)
+			assert.Zero(t, framework.Coverage)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"math"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// hoursPerMonth is the month length AWS uses for on-demand pricing.
+const hoursPerMonth = 730
+
+// ebsGBMonth is the gp3 price per provisioned GB-month.
+const ebsGBMonth = 0.08
+
+// instanceHourly lists us-east-1 on-demand Linux prices in USD per hour for
+// common EC2 instance types.
+var instanceHourly = map[string]float64{
+	"t3.nano":    0.0052,
+	"t3.micro":   0.0104,
+	"t3.small":   0.0208,
+	"t3.medium":  0.0416,
+	"t3.large":   0.0832,
+	"t3.xlarge":  0.1664,
+	"t3.2xlarge": 0.3328,
+	"m5.large":   0.096,
+	"m5.xlarge":  0.192,
+	"m5.2xlarge": 0.384,
+	"c5.large":   0.085,
+	"c5.xlarge":  0.17,
+	"r5.large":   0.126,
+	"r5.xlarge":  0.252,
+}
+
+// dbInstanceHourly lists us-east-1 single-AZ MySQL prices in USD per hour for
+// common RDS instance classes.
+var dbInstanceHourly = map[string]float64{
+	"db.t3.micro":  0.017,
+	"db.t3.small":  0.034,
+	"db.t3.medium": 0.068,
+	"db.t3.large":  0.136,
+	"db.m5.large":  0.171,
+	"db.m5.xlarge": 0.342,
+	"db.r5.large":  0.25,
+	"db.r5.xlarge": 0.5,
+}
+
+// flatHourly lists resource types billed at a fixed hourly rate, ignoring
+// usage-based charges such as processed bytes.
+var flatHourly = map[string]float64{
+	"aws_nat_gateway": 0.045,
+	"aws_lb":          0.0225,
+	"aws_alb":         0.0225,
+	"aws_elb":         0.025,
+	"aws_eks_cluster": 0.10,
+}
+
+// EstimateCost estimates the monthly on-demand cost of the managed resources
+// in the state from a built-in price table. It is meant to show trends, not
+// to predict a bill: usage charges, discounts and regions are ignored.
+func EstimateCost(state *models.TerraformState) models.CostEstimate {
+	estimate := models.CostEstimate{}
+
+	for _, ref := range managedInstances(state) {
+		if ref.Instance.Deposed != "" {
+			continue
+		}
+
+		monthly, ok := monthlyCost(ref.Resource.Type, ref.Instance.Attributes)
+		if !ok {
+			estimate.Unpriced++
+			continue
+		}
+
+		estimate.Priced++
+		estimate.MonthlyUSD += monthly
+	}
+
+	estimate.MonthlyUSD = math.Round(estimate.MonthlyUSD*100) / 100
+
+	return estimate
+}
+
+func monthlyCost(resType string, attrs map[string]any) (float64, bool) {
+	var hourly float64
+	var ok bool
+
+	switch resType {
+	case "aws_instance":
+		hourly, ok = instanceHourly[stringAttr(attrs, "instance_type")]
+	case "aws_db_instance":
+		hourly, ok = dbInstanceHourly[stringAttr(attrs, "instance_class")]
+		if ok && boolAttr(attrs, "multi_az") {
+			hourly *= 2
+		}
+	case "aws_ebs_volume":
+		size, isNumber := attrs["size"].(float64)
+		return size * ebsGBMonth, isNumber
+	default:
+		hourly, ok = flatHourly[resType]
+	}
+
+	return hourly * hoursPerMonth, ok
+}
@@ -0,0 +1,44 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestEstimateCost(t *testing.T) {
+	state := &models.TerraformState{
+		Resources: []models.ResourceState{
+			managedResource("aws_instance", "web", map[string]any{"instance_type": "t3.micro"}),
+			managedResource("aws_instance", "gpu", map[string]any{"instance_type": "p4d.24xlarge"}),
+			managedResource("aws_db_instance", "main", map[string]any{"instance_class": "db.t3.small", "multi_az": true}),
+			managedResource("aws_ebs_volume", "data", map[string]any{"size": float64(100)}),
+			managedResource("aws_nat_gateway", "main", nil),
+			managedResource("aws_security_group", "web", nil),
+		},
+	}
+
+	estimate := EstimateCost(state)
+
+	// 0.0104*730 + 0.034*2*730 + 100*0.08 + 0.045*730
+	assert.InDelta(t, 98.08, estimate.MonthlyUSD, 0.001)
+	assert.Equal(t, 4, estimate.Priced)
+	assert.Equal(t, 2, estimate.Unpriced)
+}
+
+func TestEstimateCostSkipsDeposed(t *testing.T) {
+	res := managedResource("aws_instance", "web", map[string]any{"instance_type": "t3.micro"})
+	res.Instances = append(res.Instances, models.ResourceInstance{
+		Deposed:    "00000001",
+		Attributes: map[string]any{"instance_type": "t3.micro"},
+	})
+
+	estimate := EstimateCost(&models.TerraformState{Resources: []models.ResourceState{res}})
+
+	assert.Equal(t, 1, estimate.Priced)
+	assert.InDelta(t, 7.59, estimate.MonthlyUSD, 0.001)
+}
@@ -0,0 +1,223 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+
+	"github.com/terrascope/core/internal/codes"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+const (
+	warningTypeProtected     = "protected_resource"
+	warningTypeUnknownTarget = "unknown_target"
+	warningTypeCycle         = "dependency_cycle"
+)
+
+// protectedTypes lists resource types that hold data or identity which cannot
+// be recreated. The state does not record prevent_destroy, so destroying one
+// of these is flagged by type instead.
+var protectedTypes = map[string]bool{
+	"aws_db_instance":                    true,
+	"aws_rds_cluster":                    true,
+	"aws_dynamodb_table":                 true,
+	"aws_s3_bucket":                      true,
+	"aws_efs_file_system":                true,
+	"aws_ebs_volume":                     true,
+	"aws_elasticache_cluster":            true,
+	"aws_elasticache_replication_group":  true,
+	"aws_kms_key":                        true,
+	"aws_route53_zone":                   true,
+	"aws_secretsmanager_secret":          true,
+	"google_sql_database_instance":       true,
+	"google_storage_bucket":              true,
+	"google_kms_crypto_key":              true,
+	"google_bigquery_dataset":            true,
+	"azurerm_storage_account":            true,
+	"azurerm_key_vault":                  true,
+	"azurerm_mssql_database":             true,
+	"azurerm_postgresql_flexible_server": true,
+}
+
+// SimulateDestroy computes what Terraform would have to destroy to remove the
+// target nodes: every managed resource that transitively depends on a target,
+// ordered so that dependents are destroyed before the resources they depend
+// on. Data sources are read rather than destroyed and are left out.
+func SimulateDestroy(state *models.TerraformState, targets []string) *models.DestroySimulation {
+	simulation := &models.DestroySimulation{
+		Targets: targets,
+		Cascade: []models.DestroyStep{},
+	}
+
+	refs := make(map[string]parser.InstanceRef)
+	byAddress := make(map[string][]string)
+	for _, ref := range managedInstances(state) {
+		refs[ref.NodeID] = ref
+		address := parser.ResourceAddress(ref.Resource)
+		byAddress[address] = append(byAddress[address], ref.NodeID)
+		if config := parser.ConfigAddress(ref.Resource); config != address {
+			byAddress[config] = append(byAddress[config], ref.NodeID)
+		}
+	}
+
+	dependents := make(map[string][]string)
+	for nodeID, ref := range refs {
+		deps := append(append([]string{}, ref.Resource.DependsOn...), ref.Instance.Dependencies...)
+		linked := make(map[string]bool)
+		for _, dep := range uniqueSorted(deps) {
+			for _, target := range byAddress[dep] {
+				if target != nodeID && !linked[target] {
+					linked[target] = true
+					dependents[target] = append(dependents[target], nodeID)
+				}
+			}
+		}
+	}
+
+	requiredBy := make(map[string][]string)
+	isTarget := make(map[string]bool)
+	queue := []string{}
+	for _, target := range targets {
+		if _, ok := refs[target]; !ok {
+			simulation.Warnings = append(simulation.Warnings, models.Warning{
+				Code:    codes.ForWarning(warningTypeUnknownTarget),
+				Type:    warningTypeUnknownTarget,
+				Address: target,
+				Message: fmt.Sprintf("%s is not a managed resource in the state", target),
+			})
+			continue
+		}
+
+		if !isTarget[target] {
+			isTarget[target] = true
+			queue = append(queue, target)
+		}
+	}
+
+	closure := make(map[string]bool)
+	for _, nodeID := range queue {
+		closure[nodeID] = true
+	}
+
+	for len(queue) > 0 {
+		nodeID := queue[0]
+		queue = queue[1:]
+
+		for _, dependent := range dependents[nodeID] {
+			requiredBy[dependent] = append(requiredBy[dependent], nodeID)
+			if !closure[dependent] {
+				closure[dependent] = true
+				queue = append(queue, dependent)
+			}
+		}
+	}
+
+	for _, nodeID := range destroyOrder(closure, dependents, simulation) {
+		ref := refs[nodeID]
+		protected := protectedTypes[ref.Resource.Type] || boolAttr(ref.Instance.Attributes, "deletion_protection")
+
+		simulation.Cascade = append(simulation.Cascade, models.DestroyStep{
+			ID:         nodeID,
+			Type:       ref.Resource.Type,
+			Target:     isTarget[nodeID],
+			RequiredBy: uniqueSorted(requiredBy[nodeID]),
+			Protected:  protected,
+
+			CreateBeforeDestroy: ref.Instance.CreateBeforeDestroy,
+		})
+
+		if protected {
+			simulation.Warnings = append(simulation.Warnings, models.Warning{
+				Code:    codes.ForWarning(warningTypeProtected),
+				Type:    warningTypeProtected,
+				Address: nodeID,
+				Message: fmt.Sprintf("%s is a %s, which usually holds data that cannot be recovered once destroyed", nodeID, ref.Resource.Type),
+			})
+		}
+	}
+
+	return simulation
+}
+
+// destroyOrder sorts the nodes in reverse topological order: a node comes out
+// only once every dependent of it in the set has. Ties are broken by ID so the
+// order is deterministic. Nodes caught in a dependency cycle are appended at
+// the end with a warning.
+func destroyOrder(nodes map[string]bool, dependents map[string][]string, simulation *models.DestroySimulation) []string {
+	pending := make(map[string]int, len(nodes))
+	dependencies := make(map[string][]string, len(nodes))
+	for nodeID := range nodes {
+		pending[nodeID] = 0
+		for _, dependent := range dependents[nodeID] {
+			if nodes[dependent] {
+				pending[nodeID]++
+				dependencies[dependent] = append(dependencies[dependent], nodeID)
+			}
+		}
+	}
+
+	ready := &idHeap{}
+	for nodeID, count := range pending {
+		if count == 0 {
+			*ready = append(*ready, nodeID)
+		}
+	}
+	heap.Init(ready)
+
+	order := make([]string, 0, len(nodes))
+	for ready.Len() > 0 {
+		nodeID := heap.Pop(ready).(string)
+		order = append(order, nodeID)
+		delete(pending, nodeID)
+
+		for _, dependency := range dependencies[nodeID] {
+			pending[dependency]--
+			if pending[dependency] == 0 {
+				heap.Push(ready, dependency)
+			}
+		}
+	}
+
+	if len(pending) > 0 {
+		cyclic := make([]string, 0, len(pending))
+		for nodeID := range pending {
+			cyclic = append(cyclic, nodeID)
+		}
+		sort.Strings(cyclic)
+
+		for _, nodeID := range cyclic {
+			simulation.Warnings = append(simulation.Warnings, models.Warning{
+				Code:    codes.ForWarning(warningTypeCycle),
+				Type:    warningTypeCycle,
+				Address: nodeID,
+				Message: fmt.Sprintf("%s is part of a dependency cycle; its destroy order is undefined", nodeID),
+			})
+		}
+
+		order = append(order, cyclic...)
+	}
+
+	return order
+}
+
+// idHeap is a min-heap of node IDs, which pops the nodes ready to destroy in
+// ID order.
+type idHeap []string
+
+func (h idHeap) Len() int           { return len(h) }
+func (h idHeap) Less(i, j int) bool { return h[i] < h[j] }
+func (h idHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *idHeap) Push(x any)        { *h = append(*h, x.(string)) }
+
+func (h *idHeap) Pop() any {
+	old := *h
+	id := old[len(old)-1]
+	*h = old[:len(old)-1]
+
+	return id
+}
@@ -0,0 +1,200 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func dependentResource(resType, name string, attrs map[string]any, deps ...string) models.ResourceState {
+	res := managedResource(resType, name, attrs)
+	res.Instances[0].Dependencies = deps
+
+	return res
+}
+
+func destroyState() *models.TerraformState {
+	return &models.TerraformState{
+		Resources: []models.ResourceState{
+			dependentResource("aws_vpc", "main", map[string]any{"id": "vpc-1"}),
+			dependentResource("aws_subnet", "a", map[string]any{"id": "subnet-a"}, "aws_vpc.main"),
+			dependentResource("aws_subnet", "b", map[string]any{"id": "subnet-b"}, "aws_vpc.main"),
+			dependentResource("aws_instance", "web", map[string]any{"id": "i-1"}, "aws_subnet.a"),
+			dependentResource("aws_db_instance", "main", map[string]any{"id": "db-1"}, "aws_subnet.a", "aws_subnet.b"),
+			dependentResource("aws_s3_bucket", "logs", map[string]any{"id": "logs"}),
+			{
+				Mode:      "data",
+				Type:      "aws_ami",
+				Name:      "ubuntu",
+				Provider:  awsProvider,
+				Instances: []models.ResourceInstance{{Dependencies: []string{"aws_vpc.main"}}},
+			},
+		},
+	}
+}
+
+func cascadeIDs(simulation *models.DestroySimulation) []string {
+	ids := make([]string, 0, len(simulation.Cascade))
+	for _, step := range simulation.Cascade {
+		ids = append(ids, step.ID)
+	}
+
+	return ids
+}
+
+func indexOf(ids []string, id string) int {
+	for i, candidate := range ids {
+		if candidate == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func TestSimulateDestroy(t *testing.T) {
+	t.Run("destroys dependents before their dependencies", func(t *testing.T) {
+		simulation := SimulateDestroy(destroyState(), []string{"aws_vpc.main"})
+
+		ids := cascadeIDs(simulation)
+		require.ElementsMatch(t, []string{"aws_vpc.main", "aws_subnet.a", "aws_subnet.b", "aws_instance.web", "aws_db_instance.main"}, ids)
+
+		assert.Equal(t, "aws_vpc.main", ids[len(ids)-1])
+		assert.Less(t, indexOf(ids, "aws_instance.web"), indexOf(ids, "aws_subnet.a"))
+		assert.Less(t, indexOf(ids, "aws_db_instance.main"), indexOf(ids, "aws_subnet.a"))
+		assert.Less(t, indexOf(ids, "aws_db_instance.main"), indexOf(ids, "aws_subnet.b"))
+	})
+
+	t.Run("flags create_before_destroy resources", func(t *testing.T) {
+		state := destroyState()
+		state.Resources[3].Instances[0].CreateBeforeDestroy = true
+
+		simulation := SimulateDestroy(state, []string{"aws_subnet.a"})
+
+		for _, step := range simulation.Cascade {
+			assert.Equal(t, step.ID == "aws_instance.web", step.CreateBeforeDestroy, step.ID)
+		}
+	})
+
+	t.Run("leaves out data sources and unrelated resources", func(t *testing.T) {
+		ids := cascadeIDs(SimulateDestroy(destroyState(), []string{"aws_vpc.main"}))
+
+		assert.NotContains(t, ids, "aws_ami.ubuntu")
+		assert.NotContains(t, ids, "aws_s3_bucket.logs")
+	})
+
+	t.Run("records targets and what required each step", func(t *testing.T) {
+		simulation := SimulateDestroy(destroyState(), []string{"aws_subnet.a"})
+
+		require.Equal(t, []string{"aws_db_instance.main", "aws_instance.web", "aws_subnet.a"}, cascadeIDs(simulation))
+		assert.Equal(t, []string{"aws_subnet.a"}, simulation.Cascade[0].RequiredBy)
+		assert.False(t, simulation.Cascade[0].Target)
+		assert.True(t, simulation.Cascade[2].Target)
+		assert.Empty(t, simulation.Cascade[2].RequiredBy)
+	})
+
+	t.Run("warns about protected resource types", func(t *testing.T) {
+		simulation := SimulateDestroy(destroyState(), []string{"aws_subnet.b", "aws_s3_bucket.logs"})
+
+		require.Equal(t, []string{"aws_db_instance.main", "aws_s3_bucket.logs", "aws_subnet.b"}, cascadeIDs(simulation))
+		assert.True(t, simulation.Cascade[0].Protected)
+		assert.True(t, simulation.Cascade[1].Protected)
+		assert.False(t, simulation.Cascade[2].Protected)
+
+		require.Len(t, simulation.Warnings, 2)
+		assert.Equal(t, "protected_resource", simulation.Warnings[0].Type)
+		assert.Equal(t, "aws_db_instance.main", simulation.Warnings[0].Address)
+	})
+
+	t.Run("flags deletion protection from attributes", func(t *testing.T) {
+		state := &models.TerraformState{
+			Resources: []models.ResourceState{
+				managedResource("aws_lb", "web", map[string]any{"id": "arn:lb/web", "deletion_protection": true}),
+			},
+		}
+
+		simulation := SimulateDestroy(state, []string{"aws_lb.web"})
+
+		require.Len(t, simulation.Cascade, 1)
+		assert.True(t, simulation.Cascade[0].Protected)
+	})
+
+	t.Run("resolves dependencies on every instance of a resource", func(t *testing.T) {
+		subnets := managedResource("aws_subnet", "private", nil)
+		subnets.Instances = []models.ResourceInstance{
+			{IndexKey: 0, Attributes: map[string]any{"id": "subnet-0"}},
+			{IndexKey: 1, Attributes: map[string]any{"id": "subnet-1"}},
+		}
+		state := &models.TerraformState{
+			Resources: []models.ResourceState{
+				dependentResource("aws_vpc", "main", map[string]any{"id": "vpc-1"}),
+				subnets,
+				dependentResource("aws_instance", "web", map[string]any{"id": "i-1"}, "aws_subnet.private"),
+			},
+		}
+		state.Resources[1].Instances[0].Dependencies = []string{"aws_vpc.main"}
+
+		ids := cascadeIDs(SimulateDestroy(state, []string{"aws_vpc.main"}))
+
+		assert.Equal(t, []string{"aws_instance.web", "aws_subnet.private[0]", "aws_vpc.main"}, ids)
+	})
+
+	t.Run("resolves dependencies recorded as configuration addresses", func(t *testing.T) {
+		vpc := dependentResource("aws_vpc", "main", map[string]any{"id": "vpc-1"})
+		vpc.Module = "module.network[0]"
+		state := &models.TerraformState{
+			Resources: []models.ResourceState{
+				vpc,
+				dependentResource("aws_subnet", "a", map[string]any{"id": "subnet-a"}, "module.network.aws_vpc.main", "module.network[0].aws_vpc.main"),
+			},
+		}
+
+		simulation := SimulateDestroy(state, []string{"module.network[0].aws_vpc.main"})
+
+		assert.Equal(t, []string{"aws_subnet.a", "module.network[0].aws_vpc.main"}, cascadeIDs(simulation))
+	})
+
+	t.Run("warns about unknown targets", func(t *testing.T) {
+		simulation := SimulateDestroy(destroyState(), []string{"aws_vpc.missing", "aws_ami.ubuntu"})
+
+		assert.Empty(t, simulation.Cascade)
+		require.Len(t, simulation.Warnings, 2)
+		assert.Equal(t, "unknown_target", simulation.Warnings[0].Type)
+		assert.Equal(t, "aws_vpc.missing", simulation.Warnings[0].Address)
+	})
+
+	t.Run("orders wide cascades by ID", func(t *testing.T) {
+		state := &models.TerraformState{Resources: []models.ResourceState{dependentResource("aws_vpc", "main", nil)}}
+		for i := range 5000 {
+			state.Resources = append(state.Resources, dependentResource("aws_subnet", fmt.Sprintf("s%04d", 4999-i), nil, "aws_vpc.main"))
+		}
+
+		ids := cascadeIDs(SimulateDestroy(state, []string{"aws_vpc.main"}))
+
+		require.Len(t, ids, 5001)
+		assert.True(t, sort.StringsAreSorted(ids[:5000]), "ready subnets come out in ID order")
+		assert.Equal(t, "aws_vpc.main", ids[5000])
+	})
+
+	t.Run("appends dependency cycles with a warning", func(t *testing.T) {
+		state := &models.TerraformState{
+			Resources: []models.ResourceState{
+				dependentResource("aws_security_group", "a", nil, "aws_security_group.b"),
+				dependentResource("aws_security_group", "b", nil, "aws_security_group.a"),
+			},
+		}
+
+		simulation := SimulateDestroy(state, []string{"aws_security_group.a"})
+
+		assert.Equal(t, []string{"aws_security_group.a", "aws_security_group.b"}, cascadeIDs(simulation))
+		require.Len(t, simulation.Warnings, 2)
+		assert.Equal(t, "dependency_cycle", simulation.Warnings[0].Type)
+	})
+}
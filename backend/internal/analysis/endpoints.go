@@ -0,0 +1,302 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+var loadBalancerTypes = map[string]bool{
+	"aws_lb":                      true,
+	"aws_alb":                     true,
+	"aws_elb":                     true,
+	"aws_cloudfront_distribution": true,
+}
+
+var listenerTypes = map[string]bool{
+	"aws_lb_listener":  true,
+	"aws_alb_listener": true,
+}
+
+var domainAttributes = []string{
+	"dns_name",
+	"domain_name",
+	"bucket_domain_name",
+	"bucket_regional_domain_name",
+}
+
+type endpointIndex struct {
+	byID         map[string]string
+	domains      map[string]string
+	balancers    map[string]*models.LoadBalancer
+	balancerARNs map[string]string
+	groups       map[string][]string
+	privateZones map[string]bool
+}
+
+// Endpoints builds an exposure map of DNS hostnames to the load balancers and
+// compute resources that ultimately serve them.
+func Endpoints(state *models.TerraformState) *models.EndpointReport {
+	refs := managedInstances(state)
+	idx := &endpointIndex{
+		byID:         make(map[string]string),
+		domains:      make(map[string]string),
+		balancers:    make(map[string]*models.LoadBalancer),
+		balancerARNs: make(map[string]string),
+		groups:       make(map[string][]string),
+		privateZones: make(map[string]bool),
+	}
+
+	for _, ref := range refs {
+		idx.indexInstance(ref)
+	}
+
+	for _, ref := range refs {
+		idx.linkTargets(ref)
+	}
+
+	for _, ref := range refs {
+		idx.linkListeners(ref)
+	}
+
+	report := &models.EndpointReport{
+		Endpoints:     []models.Endpoint{},
+		LoadBalancers: []models.LoadBalancer{},
+	}
+
+	for _, ref := range refs {
+		if endpoint, ok := idx.endpoint(ref); ok {
+			report.Endpoints = append(report.Endpoints, endpoint)
+		}
+	}
+
+	for _, lb := range idx.balancers {
+		lb.Targets = uniqueSorted(lb.Targets)
+		lb.Hostnames = uniqueSorted(lb.Hostnames)
+		report.LoadBalancers = append(report.LoadBalancers, *lb)
+	}
+
+	sort.Slice(report.Endpoints, func(i, j int) bool {
+		return report.Endpoints[i].Hostname < report.Endpoints[j].Hostname
+	})
+	sort.Slice(report.LoadBalancers, func(i, j int) bool {
+		return report.LoadBalancers[i].ID < report.LoadBalancers[j].ID
+	})
+
+	return report
+}
+
+func (idx *endpointIndex) indexInstance(ref parser.InstanceRef) {
+	attrs := ref.Instance.Attributes
+
+	for _, key := range []string{"id", "arn", "name"} {
+		if value := stringAttr(attrs, key); value != "" {
+			if _, exists := idx.byID[value]; !exists || key != "name" {
+				idx.byID[value] = ref.NodeID
+			}
+		}
+	}
+
+	for _, key := range domainAttributes {
+		if domain := normalizeHostname(stringAttr(attrs, key)); domain != "" {
+			idx.domains[domain] = ref.NodeID
+		}
+	}
+
+	switch ref.Resource.Type {
+	case "aws_route53_zone":
+		if len(objectList(attrs["vpc"])) > 0 {
+			idx.privateZones[stringAttr(attrs, "zone_id")] = true
+			idx.privateZones[stringAttr(attrs, "id")] = true
+		}
+	case "google_dns_managed_zone":
+		if stringAttr(attrs, "visibility") == "private" {
+			idx.privateZones[stringAttr(attrs, "name")] = true
+		}
+	}
+
+	if !loadBalancerTypes[ref.Resource.Type] {
+		return
+	}
+
+	dnsName := stringAttr(attrs, "dns_name")
+	internetFacing := !boolAttr(attrs, "internal")
+	if ref.Resource.Type == "aws_cloudfront_distribution" {
+		dnsName = stringAttr(attrs, "domain_name")
+		internetFacing = true
+	}
+
+	idx.balancers[ref.NodeID] = &models.LoadBalancer{
+		ID:             ref.NodeID,
+		Type:           ref.Resource.Type,
+		DNSName:        normalizeHostname(dnsName),
+		InternetFacing: internetFacing,
+	}
+
+	if arn := stringAttr(attrs, "arn"); arn != "" {
+		idx.balancerARNs[arn] = ref.NodeID
+	}
+}
+
+// linkTargets records which resources are registered behind each target group
+// and which instances sit directly behind classic load balancers.
+func (idx *endpointIndex) linkTargets(ref parser.InstanceRef) {
+	attrs := ref.Instance.Attributes
+
+	switch ref.Resource.Type {
+	case "aws_lb_target_group_attachment", "aws_alb_target_group_attachment":
+		group := stringAttr(attrs, "target_group_arn")
+		idx.groups[group] = append(idx.groups[group], idx.resolve(stringAttr(attrs, "target_id")))
+	case "aws_autoscaling_group":
+		for _, group := range stringList(attrs["target_group_arns"]) {
+			idx.groups[group] = append(idx.groups[group], ref.NodeID)
+		}
+	case "aws_autoscaling_attachment":
+		group := stringAttr(attrs, "lb_target_group_arn")
+		if group == "" {
+			group = stringAttr(attrs, "alb_target_group_arn")
+		}
+		idx.groups[group] = append(idx.groups[group], idx.resolve(stringAttr(attrs, "autoscaling_group_name")))
+	case "aws_elb":
+		if lb, ok := idx.balancers[ref.NodeID]; ok {
+			for _, instance := range stringList(attrs["instances"]) {
+				lb.Targets = append(lb.Targets, idx.resolve(instance))
+			}
+		}
+	case "aws_cloudfront_distribution":
+		if lb, ok := idx.balancers[ref.NodeID]; ok {
+			for _, origin := range objectList(attrs["origin"]) {
+				if target, ok := idx.domains[normalizeHostname(stringAttr(origin, "domain_name"))]; ok {
+					lb.Targets = append(lb.Targets, target)
+				}
+			}
+		}
+	}
+}
+
+// linkListeners follows listener default actions from load balancers to the
+// members of the forwarded target groups.
+func (idx *endpointIndex) linkListeners(ref parser.InstanceRef) {
+	if !listenerTypes[ref.Resource.Type] {
+		return
+	}
+
+	attrs := ref.Instance.Attributes
+	lb, ok := idx.balancers[idx.balancerARNs[stringAttr(attrs, "load_balancer_arn")]]
+	if !ok {
+		return
+	}
+
+	for _, action := range objectList(attrs["default_action"]) {
+		groups := []string{stringAttr(action, "target_group_arn")}
+		for _, forward := range objectList(action["forward"]) {
+			for _, group := range objectList(forward["target_group"]) {
+				groups = append(groups, stringAttr(group, "arn"))
+			}
+		}
+
+		for _, group := range groups {
+			lb.Targets = append(lb.Targets, idx.groups[group]...)
+		}
+	}
+}
+
+func (idx *endpointIndex) endpoint(ref parser.InstanceRef) (models.Endpoint, bool) {
+	attrs := ref.Instance.Attributes
+
+	var hostname, recordType, zone string
+	var values []string
+
+	switch ref.Resource.Type {
+	case "aws_route53_record":
+		hostname = stringAttr(attrs, "fqdn")
+		if hostname == "" {
+			hostname = stringAttr(attrs, "name")
+		}
+		recordType = stringAttr(attrs, "type")
+		zone = stringAttr(attrs, "zone_id")
+		values = stringList(attrs["records"])
+		for _, alias := range objectList(attrs["alias"]) {
+			values = append(values, stringAttr(alias, "name"))
+		}
+	case "google_dns_record_set":
+		hostname = stringAttr(attrs, "name")
+		recordType = stringAttr(attrs, "type")
+		zone = stringAttr(attrs, "managed_zone")
+		values = stringList(attrs["rrdatas"])
+	case "cloudflare_record":
+		hostname = stringAttr(attrs, "hostname")
+		if hostname == "" {
+			hostname = stringAttr(attrs, "name")
+		}
+		recordType = stringAttr(attrs, "type")
+		values = append(values, stringAttr(attrs, "content"), stringAttr(attrs, "value"))
+	default:
+		return models.Endpoint{}, false
+	}
+
+	endpoint := models.Endpoint{
+		Hostname:   normalizeHostname(hostname),
+		RecordID:   ref.NodeID,
+		RecordType: recordType,
+		Values:     []string{},
+	}
+
+	publicZone := !idx.privateZones[zone]
+
+	for _, value := range values {
+		value = normalizeHostname(value)
+		if value == "" {
+			continue
+		}
+		endpoint.Values = append(endpoint.Values, value)
+
+		if ip := net.ParseIP(value); ip != nil && publicZone && isPublicIP(ip) {
+			endpoint.InternetFacing = true
+		}
+
+		target, ok := idx.domains[value]
+		if !ok {
+			continue
+		}
+
+		if lb, ok := idx.balancers[target]; ok {
+			endpoint.LoadBalancer = lb.ID
+			endpoint.Targets = append(endpoint.Targets, lb.Targets...)
+			endpoint.InternetFacing = endpoint.InternetFacing || (publicZone && lb.InternetFacing)
+			lb.Hostnames = append(lb.Hostnames, endpoint.Hostname)
+		} else {
+			endpoint.Targets = append(endpoint.Targets, target)
+		}
+	}
+
+	endpoint.Targets = uniqueSorted(endpoint.Targets)
+
+	return endpoint, true
+}
+
+// resolve maps a cloud identifier to its node ID, keeping the raw identifier
+// when the referenced resource is not managed in this state.
+func (idx *endpointIndex) resolve(id string) string {
+	if nodeID, ok := idx.byID[id]; ok {
+		return nodeID
+	}
+
+	return id
+}
+
+func normalizeHostname(hostname string) string {
+	hostname = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(hostname)), ".")
+
+	return strings.TrimPrefix(hostname, "dualstack.")
+}
+
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && !ip.IsUnspecified()
+}
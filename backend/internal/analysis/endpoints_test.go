@@ -0,0 +1,195 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+const awsProvider = "provider[\"registry.terraform.io/hashicorp/aws\"]"
+
+func managedResource(resType, name string, attrs map[string]any) models.ResourceState {
+	return models.ResourceState{
+		Mode:      "managed",
+		Type:      resType,
+		Name:      name,
+		Provider:  awsProvider,
+		Instances: []models.ResourceInstance{{Attributes: attrs}},
+	}
+}
+
+func endpointState() *models.TerraformState {
+	return &models.TerraformState{
+		Resources: []models.ResourceState{
+			managedResource("aws_route53_zone", "public", map[string]any{"id": "Z1", "zone_id": "Z1"}),
+			managedResource("aws_route53_zone", "internal", map[string]any{
+				"id":      "Z2",
+				"zone_id": "Z2",
+				"vpc":     []any{map[string]any{"vpc_id": "vpc-1"}},
+			}),
+			managedResource("aws_lb", "web", map[string]any{
+				"id":       "arn:lb/web",
+				"arn":      "arn:lb/web",
+				"dns_name": "web-123.eu-west-1.elb.amazonaws.com",
+				"internal": false,
+			}),
+			managedResource("aws_lb", "admin", map[string]any{
+				"id":       "arn:lb/admin",
+				"arn":      "arn:lb/admin",
+				"dns_name": "internal-admin-1.eu-west-1.elb.amazonaws.com",
+				"internal": true,
+			}),
+			managedResource("aws_lb_target_group", "web", map[string]any{"id": "arn:tg/web", "arn": "arn:tg/web"}),
+			managedResource("aws_lb_listener", "https", map[string]any{
+				"id":                "arn:listener/https",
+				"load_balancer_arn": "arn:lb/web",
+				"default_action":    []any{map[string]any{"type": "forward", "target_group_arn": "arn:tg/web"}},
+			}),
+			managedResource("aws_lb_target_group_attachment", "web", map[string]any{
+				"id":               "attach-1",
+				"target_group_arn": "arn:tg/web",
+				"target_id":        "i-1",
+			}),
+			managedResource("aws_instance", "web", map[string]any{"id": "i-1"}),
+			managedResource("aws_route53_record", "www", map[string]any{
+				"id":      "Z1_www",
+				"zone_id": "Z1",
+				"name":    "www.example.com",
+				"fqdn":    "www.example.com",
+				"type":    "A",
+				"alias":   []any{map[string]any{"name": "dualstack.web-123.eu-west-1.elb.amazonaws.com."}},
+			}),
+			managedResource("aws_route53_record", "admin", map[string]any{
+				"id":      "Z2_admin",
+				"zone_id": "Z2",
+				"name":    "admin.internal",
+				"type":    "CNAME",
+				"records": []any{"internal-admin-1.eu-west-1.elb.amazonaws.com"},
+			}),
+			managedResource("aws_route53_record", "bastion", map[string]any{
+				"id":      "Z1_bastion",
+				"zone_id": "Z1",
+				"name":    "bastion.example.com",
+				"type":    "A",
+				"records": []any{"203.0.113.10"},
+			}),
+		},
+	}
+}
+
+func findEndpoint(report *models.EndpointReport, hostname string) (models.Endpoint, bool) {
+	for _, endpoint := range report.Endpoints {
+		if endpoint.Hostname == hostname {
+			return endpoint, true
+		}
+	}
+
+	return models.Endpoint{}, false
+}
+
+func TestEndpoints(t *testing.T) {
+	t.Run("empty state returns empty report", func(t *testing.T) {
+		report := Endpoints(&models.TerraformState{})
+
+		assert.Empty(t, report.Endpoints)
+		assert.Empty(t, report.LoadBalancers)
+	})
+
+	t.Run("alias record resolves to load balancer targets", func(t *testing.T) {
+		report := Endpoints(endpointState())
+
+		endpoint, ok := findEndpoint(report, "www.example.com")
+		require.True(t, ok)
+
+		assert.Equal(t, "aws_route53_record.www", endpoint.RecordID)
+		assert.Equal(t, "aws_lb.web", endpoint.LoadBalancer)
+		assert.Equal(t, []string{"aws_instance.web"}, endpoint.Targets)
+		assert.True(t, endpoint.InternetFacing)
+	})
+
+	t.Run("internal load balancer in private zone is not internet facing", func(t *testing.T) {
+		report := Endpoints(endpointState())
+
+		endpoint, ok := findEndpoint(report, "admin.internal")
+		require.True(t, ok)
+
+		assert.Equal(t, "aws_lb.admin", endpoint.LoadBalancer)
+		assert.False(t, endpoint.InternetFacing)
+	})
+
+	t.Run("public ip record is internet facing", func(t *testing.T) {
+		report := Endpoints(endpointState())
+
+		endpoint, ok := findEndpoint(report, "bastion.example.com")
+		require.True(t, ok)
+
+		assert.Empty(t, endpoint.LoadBalancer)
+		assert.True(t, endpoint.InternetFacing)
+		assert.Equal(t, []string{"203.0.113.10"}, endpoint.Values)
+	})
+
+	t.Run("load balancers list hostnames and targets", func(t *testing.T) {
+		report := Endpoints(endpointState())
+
+		require.Len(t, report.LoadBalancers, 2)
+		assert.Equal(t, "aws_lb.admin", report.LoadBalancers[0].ID)
+		assert.False(t, report.LoadBalancers[0].InternetFacing)
+
+		web := report.LoadBalancers[1]
+		assert.Equal(t, "aws_lb.web", web.ID)
+		assert.True(t, web.InternetFacing)
+		assert.Equal(t, []string{"www.example.com"}, web.Hostnames)
+		assert.Equal(t, []string{"aws_instance.web"}, web.Targets)
+	})
+
+	t.Run("classic elb targets instances directly", func(t *testing.T) {
+		state := &models.TerraformState{
+			Resources: []models.ResourceState{
+				managedResource("aws_elb", "legacy", map[string]any{
+					"id":        "legacy",
+					"dns_name":  "legacy-1.elb.amazonaws.com",
+					"instances": []any{"i-1", "i-external"},
+				}),
+				managedResource("aws_instance", "app", map[string]any{"id": "i-1"}),
+			},
+		}
+
+		report := Endpoints(state)
+
+		require.Len(t, report.LoadBalancers, 1)
+		assert.Equal(t, []string{"aws_instance.app", "i-external"}, report.LoadBalancers[0].Targets)
+	})
+
+	t.Run("endpoints are sorted by hostname", func(t *testing.T) {
+		report := Endpoints(endpointState())
+
+		require.Len(t, report.Endpoints, 3)
+		assert.Equal(t, "admin.internal", report.Endpoints[0].Hostname)
+		assert.Equal(t, "bastion.example.com", report.Endpoints[1].Hostname)
+		assert.Equal(t, "www.example.com", report.Endpoints[2].Hostname)
+	})
+}
+
+func TestNormalizeHostname(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "trailing dot", input: "example.com.", expected: "example.com"},
+		{name: "dualstack prefix", input: "dualstack.lb.amazonaws.com", expected: "lb.amazonaws.com"},
+		{name: "mixed case", input: "WWW.Example.COM", expected: "www.example.com"},
+		{name: "empty", input: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, normalizeHostname(tt.input))
+		})
+	}
+}
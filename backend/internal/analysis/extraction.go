@@ -0,0 +1,481 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+// extractionIgnored are the attributes the provider computes, which differ
+// between occurrences without being inputs of a module.
+var extractionIgnored = map[string]bool{
+	"id":       true,
+	"arn":      true,
+	"tags_all": true,
+}
+
+// ModuleExtractions finds the groups of connected resources of the root
+// module that are repeated across the state with the same types and the same
+// dependencies between them, and suggests refactoring them into a module
+// whose variables are the attributes that differ between occurrences.
+// Resources of a type that occurs once, such as a shared VPC, are left out of
+// the groups, and so are the resources of the types that several resources
+// of a same type depend on within a group that is not repeated, which tie
+// repeated groups together. Groups of fewer than minSize resources are not
+// reported, nor groups already repeated with count or for_each.
+func ModuleExtractions(state *models.TerraformState, minSize int) *models.ExtractionReport {
+	g := &repetitionGraph{
+		refs:   make(map[string]parser.InstanceRef),
+		edges:  make(map[[2]string]bool),
+		colors: make(map[string]string),
+	}
+
+	typeCounts := make(map[string]int)
+	for _, ref := range managedInstances(state) {
+		if ref.Resource.Module != "" || ref.Instance.Deposed != "" {
+			continue
+		}
+		g.refs[ref.NodeID] = ref
+		typeCounts[ref.Resource.Type]++
+	}
+
+	ids := make([]string, 0, len(g.refs))
+	for id, ref := range g.refs {
+		if typeCounts[ref.Resource.Type] > 1 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	graph := parser.BuildGraphWithOptions(state, parser.GraphOptions{InstanceEdges: true})
+	for _, edge := range graph.Edges {
+		_, source := g.refs[edge.Source]
+		_, target := g.refs[edge.Target]
+		if source && target && edge.Source != edge.Target {
+			g.edges[[2]string{edge.Source, edge.Target}] = true
+		}
+	}
+
+	groups := make(map[string][][]string)
+	g.group(groups, g.components(ids))
+
+	var rest []string
+	hubs := make(map[string]bool)
+	for signature, group := range groups {
+		if len(group) > 1 {
+			continue
+		}
+
+		rest = append(rest, group[0]...)
+		for resourceType := range g.hubTypes(group[0]) {
+			hubs[resourceType] = true
+		}
+		delete(groups, signature)
+	}
+
+	if len(hubs) > 0 {
+		kept := rest[:0]
+		for _, id := range rest {
+			if !hubs[g.refs[id].Resource.Type] {
+				kept = append(kept, id)
+			}
+		}
+		sort.Strings(kept)
+		g.group(groups, g.components(kept))
+	}
+
+	signatures := make([]string, 0, len(groups))
+	for signature := range groups {
+		signatures = append(signatures, signature)
+	}
+	sort.Strings(signatures)
+
+	report := &models.ExtractionReport{Suggestions: []models.ModuleExtraction{}}
+
+	for _, signature := range signatures {
+		group := groups[signature]
+		if len(group) < 2 || len(group[0]) < minSize {
+			continue
+		}
+
+		sort.Slice(group, func(i, j int) bool { return group[i][0] < group[j][0] })
+
+		occurrences := g.occurrences(group)
+		if len(occurrences) < 2 {
+			continue
+		}
+
+		suggestion := models.ModuleExtraction{
+			Types:       make([]string, len(occurrences[0])),
+			Occurrences: make([][]string, len(occurrences)),
+			Variables:   g.variables(occurrences),
+		}
+
+		for i, id := range occurrences[0] {
+			suggestion.Types[i] = g.refs[id].Resource.Type
+
+			for _, other := range occurrences[0] {
+				if g.edges[[2]string{id, other}] {
+					suggestion.Edges++
+				}
+			}
+		}
+
+		for i, occurrence := range occurrences {
+			for _, id := range occurrence {
+				suggestion.Occurrences[i] = append(suggestion.Occurrences[i], g.address(id))
+			}
+		}
+
+		report.Suggestions = append(report.Suggestions, suggestion)
+	}
+
+	sort.Slice(report.Suggestions, func(i, j int) bool {
+		a, b := report.Suggestions[i], report.Suggestions[j]
+		if len(a.Occurrences) != len(b.Occurrences) {
+			return len(a.Occurrences) > len(b.Occurrences)
+		}
+		if len(a.Types) != len(b.Types) {
+			return len(a.Types) > len(b.Types)
+		}
+		return a.Occurrences[0][0] < b.Occurrences[0][0]
+	})
+
+	for i := range report.Suggestions {
+		report.Suggestions[i].ID = fmt.Sprintf("module-%d", i+1)
+	}
+
+	return report
+}
+
+// repetitionGraph is the dependency graph between the resource instances of
+// the root module, whose edges point from a resource to its dependency.
+// colors holds the colors of the nodes of the components grouped so far.
+type repetitionGraph struct {
+	refs   map[string]parser.InstanceRef
+	edges  map[[2]string]bool
+	colors map[string]string
+}
+
+// components returns the connected components of the graph between ids.
+func (g *repetitionGraph) components(ids []string) [][]string {
+	in := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		in[id] = true
+	}
+
+	neighbours := make(map[string]map[string]int)
+	for edge := range g.edges {
+		if in[edge[0]] && in[edge[1]] {
+			link(neighbours, edge[0], edge[1])
+			link(neighbours, edge[1], edge[0])
+		}
+	}
+
+	return connectedComponents(ids, neighbours)
+}
+
+// group colors the components and adds them to groups by the multiset of
+// their colors, which isomorphic components share.
+func (g *repetitionGraph) group(groups map[string][][]string, components [][]string) {
+	for _, component := range components {
+		g.color(component)
+
+		colors := make([]string, len(component))
+		for i, id := range component {
+			colors[i] = g.colors[id]
+		}
+		sort.Strings(colors)
+
+		signature := strings.Join(colors, ",")
+		groups[signature] = append(groups[signature], component)
+	}
+}
+
+// color colors the nodes of a component with their types, refined by the
+// colors of their dependencies and dependents until no more nodes are told
+// apart, as in the Weisfeiler-Lehman test.
+func (g *repetitionGraph) color(component []string) {
+	in := make(map[string]bool, len(component))
+	for _, id := range component {
+		in[id] = true
+	}
+
+	dependencies := make(map[string][]string, len(component))
+	dependents := make(map[string][]string, len(component))
+	for edge := range g.edges {
+		if in[edge[0]] && in[edge[1]] {
+			dependencies[edge[0]] = append(dependencies[edge[0]], edge[1])
+			dependents[edge[1]] = append(dependents[edge[1]], edge[0])
+		}
+	}
+
+	colors := make(map[string]string, len(component))
+	for _, id := range component {
+		colors[id] = g.refs[id].Resource.Type
+	}
+	distinct := countDistinct(colors)
+
+	for {
+		next := make(map[string]string, len(component))
+		for _, id := range component {
+			sum := sha256.Sum256([]byte(colors[id] + "|" + neighbourColors(dependencies[id], colors) + "|" + neighbourColors(dependents[id], colors)))
+			next[id] = hex.EncodeToString(sum[:8])
+		}
+
+		n := countDistinct(next)
+		if n == distinct {
+			break
+		}
+		colors, distinct = next, n
+	}
+
+	for id, color := range colors {
+		g.colors[id] = color
+	}
+}
+
+func neighbourColors(ids []string, colors map[string]string) string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = colors[id]
+	}
+	sort.Strings(values)
+
+	return strings.Join(values, ",")
+}
+
+func countDistinct(colors map[string]string) int {
+	distinct := make(map[string]bool, len(colors))
+	for _, color := range colors {
+		distinct[color] = true
+	}
+
+	return len(distinct)
+}
+
+// hubTypes returns the types of the nodes of a component that several nodes
+// of a same type depend on.
+func (g *repetitionGraph) hubTypes(component []string) map[string]bool {
+	in := make(map[string]bool, len(component))
+	for _, id := range component {
+		in[id] = true
+	}
+
+	dependents := make(map[string]int)
+	for edge := range g.edges {
+		if in[edge[0]] && in[edge[1]] {
+			dependents[edge[1]+" "+g.refs[edge[0]].Resource.Type]++
+		}
+	}
+
+	types := make(map[string]bool)
+	for key, count := range dependents {
+		if count > 1 {
+			id, _, _ := strings.Cut(key, " ")
+			types[g.refs[id].Resource.Type] = true
+		}
+	}
+
+	return types
+}
+
+// occurrences matches the nodes of the components of a group with those of
+// the first one, and returns the components that match, each ordered like
+// the first one. Components with the same resources as one before them,
+// which are other instances of the same resources, are left out.
+func (g *repetitionGraph) occurrences(group [][]string) [][]string {
+	var occurrences [][]string
+	seen := make(map[string]bool)
+
+	for _, component := range group {
+		mapped, ok := g.match(group[0], component)
+		if !ok {
+			continue
+		}
+
+		addresses := make([]string, len(mapped))
+		for i, id := range mapped {
+			addresses[i] = parser.ConfigAddress(g.refs[id].Resource)
+		}
+		sort.Strings(addresses)
+
+		key := strings.Join(addresses, " ")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		occurrences = append(occurrences, mapped)
+	}
+
+	return occurrences
+}
+
+// match returns the nodes of other that correspond to those of template, by
+// backtracking over the nodes of the same color, or false when the
+// components are not isomorphic.
+func (g *repetitionGraph) match(template, other []string) ([]string, bool) {
+	if len(template) != len(other) {
+		return nil, false
+	}
+
+	mapped := make([]string, len(template))
+	used := make(map[string]bool, len(other))
+
+	var assign func(i int) bool
+	assign = func(i int) bool {
+		if i == len(template) {
+			return true
+		}
+
+		for _, candidate := range other {
+			if used[candidate] || g.colors[candidate] != g.colors[template[i]] {
+				continue
+			}
+
+			consistent := true
+			for j := 0; j < i && consistent; j++ {
+				consistent = g.edges[[2]string{template[i], template[j]}] == g.edges[[2]string{candidate, mapped[j]}] &&
+					g.edges[[2]string{template[j], template[i]}] == g.edges[[2]string{mapped[j], candidate}]
+			}
+			if !consistent {
+				continue
+			}
+
+			used[candidate], mapped[i] = true, candidate
+			if assign(i + 1) {
+				return true
+			}
+			used[candidate] = false
+		}
+
+		return false
+	}
+
+	return mapped, assign(0)
+}
+
+// variables returns the attributes whose values differ between occurrences,
+// except the computed ones and those that only reference other resources of
+// their own occurrence, which the module would wire itself. Attributes that take
+// the same values in every occurrence share a variable, named after the
+// first of them, and prefixed with its type when variables would share a
+// name.
+func (g *repetitionGraph) variables(occurrences [][]string) []models.ModuleVariable {
+	variables := []models.ModuleVariable{}
+	var types []string
+	byValues := make(map[string]int)
+
+	for i, id := range occurrences[0] {
+		keys := make(map[string]bool)
+		for _, occurrence := range occurrences {
+			for key := range g.refs[occurrence[i]].Instance.Attributes {
+				keys[key] = !extractionIgnored[key]
+			}
+		}
+
+		for _, key := range sortedKeys(keys) {
+			values := make([]any, len(occurrences))
+			differ := false
+			for k, occurrence := range occurrences {
+				values[k] = g.refs[occurrence[i]].Instance.Attributes[key]
+				differ = differ || !reflect.DeepEqual(values[k], values[0])
+			}
+
+			if !differ || g.wiring(occurrences, i, values) {
+				continue
+			}
+
+			attribute := g.address(id) + "." + key
+			encoded, _ := json.Marshal(values)
+			if index, ok := byValues[string(encoded)]; ok {
+				variables[index].Attributes = append(variables[index].Attributes, attribute)
+				continue
+			}
+
+			byValues[string(encoded)] = len(variables)
+			types = append(types, g.refs[id].Resource.Type)
+			variables = append(variables, models.ModuleVariable{Name: key, Attributes: []string{attribute}, Values: values})
+		}
+	}
+
+	names := make(map[string]int, len(variables))
+	for _, variable := range variables {
+		names[variable.Name]++
+	}
+
+	for i := range variables {
+		if names[variables[i].Name] > 1 {
+			_, name, _ := strings.Cut(types[i], "_")
+			variables[i].Name = name + "_" + variables[i].Name
+		}
+	}
+
+	return variables
+}
+
+// wiring reports whether the values of an attribute of the resource at
+// position i only reference other resources of their own occurrence, by ID
+// or ARN. Values that are the ID or ARN of the resource itself, like the
+// names that are their resource's ID, are inputs even when other resources
+// share the ID.
+func (g *repetitionGraph) wiring(occurrences [][]string, i int, values []any) bool {
+	for k, occurrence := range occurrences {
+		owned := make(map[string]bool)
+		for _, id := range occurrence {
+			attrs := g.refs[id].Instance.Attributes
+			owned[stringAttr(attrs, "id")] = true
+			owned[stringAttr(attrs, "arn")] = true
+		}
+		delete(owned, "")
+
+		self := g.refs[occurrence[i]].Instance.Attributes
+		owned[stringAttr(self, "id")] = false
+		owned[stringAttr(self, "arn")] = false
+
+		found, foreign := false, false
+		walkStrings(values[k], func(s string) {
+			if owned[s] {
+				found = true
+			} else {
+				foreign = true
+			}
+		})
+
+		if !found || foreign {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (g *repetitionGraph) address(id string) string {
+	ref := g.refs[id]
+
+	return parser.InstanceAddress(ref.Resource, ref.Instance)
+}
+
+// sortedKeys returns the keys set to true, sorted.
+func sortedKeys(keys map[string]bool) []string {
+	sorted := make([]string, 0, len(keys))
+	for key, ok := range keys {
+		if ok {
+			sorted = append(sorted, key)
+		}
+	}
+	sort.Strings(sorted)
+
+	return sorted
+}
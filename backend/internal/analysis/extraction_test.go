@@ -0,0 +1,171 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+// serviceResources returns a security group and an instance using it in the
+// VPC, named after the service.
+func serviceResources(name, vpc, instanceType string) []models.ResourceState {
+	return []models.ResourceState{
+		dependentResource("aws_security_group", name, map[string]any{
+			"id":     "sg-" + name,
+			"name":   name,
+			"vpc_id": vpc,
+		}, "aws_vpc."+vpc),
+		dependentResource("aws_instance", name, map[string]any{
+			"id":                     "i-" + name,
+			"ami":                    "ami-1",
+			"instance_type":          instanceType,
+			"vpc_security_group_ids": []any{"sg-" + name},
+			"tags":                   map[string]any{"Name": name},
+			"tags_all":               map[string]any{"Name": name, "Team": "platform"},
+		}, "aws_security_group."+name),
+	}
+}
+
+func extractionState(resources ...[]models.ResourceState) *models.TerraformState {
+	state := &models.TerraformState{}
+	for _, group := range resources {
+		state.Resources = append(state.Resources, group...)
+	}
+
+	return state
+}
+
+func TestModuleExtractions(t *testing.T) {
+	t.Run("suggests repeated groups", func(t *testing.T) {
+		state := extractionState(
+			[]models.ResourceState{dependentResource("aws_vpc", "main", map[string]any{"id": "main"})},
+			serviceResources("api", "main", "t3.small"),
+			serviceResources("web", "main", "t3.small"),
+			serviceResources("worker", "main", "t3.large"),
+		)
+
+		report := ModuleExtractions(state, 2)
+
+		require.Len(t, report.Suggestions, 1)
+		suggestion := report.Suggestions[0]
+
+		assert.Equal(t, "module-1", suggestion.ID)
+		assert.Equal(t, []string{"aws_instance", "aws_security_group"}, suggestion.Types)
+		assert.Equal(t, 1, suggestion.Edges)
+		assert.Equal(t, [][]string{
+			{"aws_instance.api", "aws_security_group.api"},
+			{"aws_instance.web", "aws_security_group.web"},
+			{"aws_instance.worker", "aws_security_group.worker"},
+		}, suggestion.Occurrences)
+
+		assert.Equal(t, []models.ModuleVariable{
+			{
+				Name:       "instance_type",
+				Attributes: []string{"aws_instance.api.instance_type"},
+				Values:     []any{"t3.small", "t3.small", "t3.large"},
+			},
+			{
+				Name:       "tags",
+				Attributes: []string{"aws_instance.api.tags"},
+				Values:     []any{map[string]any{"Name": "api"}, map[string]any{"Name": "web"}, map[string]any{"Name": "worker"}},
+			},
+			{
+				Name:       "name",
+				Attributes: []string{"aws_security_group.api.name"},
+				Values:     []any{"api", "web", "worker"},
+			},
+		}, suggestion.Variables, "computed attributes and references within an occurrence are not variables")
+	})
+
+	t.Run("separates groups tied by shared resources", func(t *testing.T) {
+		state := extractionState(
+			[]models.ResourceState{
+				dependentResource("aws_vpc", "a", map[string]any{"id": "a"}),
+				dependentResource("aws_vpc", "b", map[string]any{"id": "b"}),
+			},
+			serviceResources("api", "a", "t3.small"),
+			serviceResources("web", "a", "t3.small"),
+			serviceResources("worker", "b", "t3.small"),
+		)
+
+		report := ModuleExtractions(state, 2)
+
+		require.Len(t, report.Suggestions, 1)
+		assert.Len(t, report.Suggestions[0].Occurrences, 3)
+		assert.Equal(t, []models.ModuleVariable{
+			{
+				Name:       "tags",
+				Attributes: []string{"aws_instance.api.tags"},
+				Values:     []any{map[string]any{"Name": "api"}, map[string]any{"Name": "web"}, map[string]any{"Name": "worker"}},
+			},
+			{
+				Name:       "name",
+				Attributes: []string{"aws_security_group.api.name"},
+				Values:     []any{"api", "web", "worker"},
+			},
+			{
+				Name:       "vpc_id",
+				Attributes: []string{"aws_security_group.api.vpc_id"},
+				Values:     []any{"a", "a", "b"},
+			},
+		}, report.Suggestions[0].Variables)
+	})
+
+	t.Run("shares variables between attributes with the same values", func(t *testing.T) {
+		state := extractionState(
+			[]models.ResourceState{
+				dependentResource("aws_s3_bucket", "a", map[string]any{"bucket": "a"}),
+				dependentResource("aws_s3_bucket_policy", "a", map[string]any{"bucket": "a"}, "aws_s3_bucket.a"),
+				dependentResource("aws_s3_bucket", "b", map[string]any{"bucket": "b"}),
+				dependentResource("aws_s3_bucket_policy", "b", map[string]any{"bucket": "b"}, "aws_s3_bucket.b"),
+			},
+		)
+
+		report := ModuleExtractions(state, 2)
+
+		require.Len(t, report.Suggestions, 1)
+		assert.Equal(t, []models.ModuleVariable{{
+			Name:       "bucket",
+			Attributes: []string{"aws_s3_bucket.a.bucket", "aws_s3_bucket_policy.a.bucket"},
+			Values:     []any{"a", "b"},
+		}}, report.Suggestions[0].Variables)
+	})
+
+	t.Run("skips groups repeated with count", func(t *testing.T) {
+		sg := dependentResource("aws_security_group", "web", nil)
+		sg.Instances = []models.ResourceInstance{
+			{IndexKey: float64(0), Attributes: map[string]any{"id": "sg-0"}},
+			{IndexKey: float64(1), Attributes: map[string]any{"id": "sg-1"}},
+		}
+		instance := dependentResource("aws_instance", "web", nil)
+		instance.Instances = []models.ResourceInstance{
+			{IndexKey: float64(0), Attributes: map[string]any{"id": "i-0"}, Dependencies: []string{"aws_security_group.web"}},
+			{IndexKey: float64(1), Attributes: map[string]any{"id": "i-1"}, Dependencies: []string{"aws_security_group.web"}},
+		}
+
+		report := ModuleExtractions(&models.TerraformState{Resources: []models.ResourceState{sg, instance}}, 1)
+
+		assert.Empty(t, report.Suggestions)
+	})
+
+	t.Run("min_size", func(t *testing.T) {
+		state := extractionState(
+			serviceResources("api", "main", "t3.small"),
+			serviceResources("web", "main", "t3.small"),
+		)
+
+		assert.Empty(t, ModuleExtractions(state, 3).Suggestions)
+	})
+
+	t.Run("empty state", func(t *testing.T) {
+		report := ModuleExtractions(&models.TerraformState{}, 2)
+
+		assert.NotNil(t, report.Suggestions)
+		assert.Empty(t, report.Suggestions)
+	})
+}
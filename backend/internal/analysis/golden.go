@@ -0,0 +1,123 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+// ModuleSignature returns the addresses of the resources of a module
+// instance of the state, such as module.logs or module.buckets["a"], relative
+// to it and without instance keys, sorted. It returns nil when the state has
+// no resources in the instance.
+func ModuleSignature(state *models.TerraformState, instance string) []string {
+	var signature []string
+
+	for _, res := range state.Resources {
+		if res.Module != instance && !strings.HasPrefix(res.Module, instance+".") {
+			continue
+		}
+
+		relative := res
+		relative.Module = strings.TrimPrefix(strings.TrimPrefix(res.Module, instance), ".")
+		signature = append(signature, parser.ConfigAddress(relative))
+	}
+
+	sort.Strings(signature)
+
+	return slices.Compact(signature)
+}
+
+// Conformance compares the instances of the golden module's module calls in
+// each state, by lineage, with its signature. Instances are reported by
+// lineage and address.
+func Conformance(golden models.GoldenModule, states map[string]*models.TerraformState) *models.ConformanceReport {
+	report := &models.ConformanceReport{Golden: golden.Name, Instances: []models.ModuleConformance{}}
+
+	lineages := make([]string, 0, len(states))
+	for lineage := range states {
+		lineages = append(lineages, lineage)
+	}
+	sort.Strings(lineages)
+
+	for _, lineage := range lineages {
+		state := states[lineage]
+
+		for _, instance := range moduleInstances(state) {
+			if moduleCallName(instance) != golden.Module {
+				continue
+			}
+
+			signature := ModuleSignature(state, instance)
+			conformance := models.ModuleConformance{
+				Lineage:   lineage,
+				Serial:    state.Serial,
+				Instance:  instance,
+				Missing:   []string{},
+				Extra:     []string{},
+				Resources: len(signature),
+			}
+
+			for _, address := range golden.Resources {
+				if !slices.Contains(signature, address) {
+					conformance.Missing = append(conformance.Missing, address)
+				}
+			}
+
+			for _, address := range signature {
+				if !slices.Contains(golden.Resources, address) {
+					conformance.Extra = append(conformance.Extra, address)
+				}
+			}
+
+			conformance.Conforms = len(conformance.Missing) == 0 && len(conformance.Extra) == 0
+			if !conformance.Conforms {
+				report.Deviating++
+			}
+
+			report.Instances = append(report.Instances, conformance)
+		}
+	}
+
+	return report
+}
+
+// moduleInstances returns the module instance paths of the state, including
+// the parents of nested instances, sorted.
+func moduleInstances(state *models.TerraformState) []string {
+	var instances []string
+
+	for _, res := range state.Resources {
+		module := res.Module
+		for module != "" {
+			instances = append(instances, module)
+
+			i := strings.LastIndex(module, ".module.")
+			if i < 0 {
+				break
+			}
+			module = module[:i]
+		}
+	}
+
+	sort.Strings(instances)
+
+	return slices.Compact(instances)
+}
+
+// moduleCallName returns the name of the module call of a module instance
+// path: app for module.network.module.app["a"].
+func moduleCallName(instance string) string {
+	name := instance[strings.LastIndex(instance, "module.")+len("module."):]
+	if i := strings.Index(name, "["); i >= 0 {
+		name = name[:i]
+	}
+
+	return name
+}
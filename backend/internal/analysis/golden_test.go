@@ -0,0 +1,95 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+func goldenState() *models.TerraformState {
+	policy := moduleResource(`module.buckets["logs"]`, "aws_iam_policy_document", "this", 1)
+	policy.Mode = "data"
+
+	return &models.TerraformState{
+		Serial: 7,
+		Resources: []models.ResourceState{
+			managedResource("aws_vpc", "main", map[string]any{"id": "vpc-1"}),
+			moduleResource(`module.buckets["assets"]`, "aws_s3_bucket", "this", 1),
+			moduleResource(`module.buckets["assets"]`, "aws_s3_bucket_versioning", "this", 1),
+			moduleResource(`module.buckets["assets"].module.replica[0]`, "aws_s3_bucket", "this", 1),
+			moduleResource(`module.buckets["logs"]`, "aws_s3_bucket", "this", 1),
+			policy,
+			moduleResource("module.app.module.buckets", "aws_s3_bucket", "this", 1),
+			moduleResource("module.app.module.buckets", "aws_s3_bucket_versioning", "this", 1),
+		},
+	}
+}
+
+func TestModuleSignature(t *testing.T) {
+	t.Run("lists resources relative to the instance", func(t *testing.T) {
+		assert.Equal(t, []string{
+			"aws_s3_bucket.this",
+			"aws_s3_bucket_versioning.this",
+			"module.replica.aws_s3_bucket.this",
+		}, ModuleSignature(goldenState(), `module.buckets["assets"]`))
+	})
+
+	t.Run("prefixes data sources", func(t *testing.T) {
+		assert.Equal(t, []string{"aws_s3_bucket.this", "data.aws_iam_policy_document.this"}, ModuleSignature(goldenState(), `module.buckets["logs"]`))
+	})
+
+	t.Run("returns nothing for unknown instances", func(t *testing.T) {
+		assert.Empty(t, ModuleSignature(goldenState(), "module.missing"))
+	})
+}
+
+func TestConformance(t *testing.T) {
+	golden := models.GoldenModule{
+		Name:      "s3",
+		Module:    "buckets",
+		Resources: []string{"aws_s3_bucket.this", "aws_s3_bucket_versioning.this"},
+	}
+
+	report := Conformance(golden, map[string]*models.TerraformState{"prod": goldenState()})
+
+	assert.Equal(t, "s3", report.Golden)
+	require.Len(t, report.Instances, 3)
+	assert.Equal(t, 2, report.Deviating)
+
+	t.Run("reports extra resources", func(t *testing.T) {
+		assert.Equal(t, models.ModuleConformance{
+			Lineage:   "prod",
+			Serial:    7,
+			Instance:  `module.buckets["assets"]`,
+			Missing:   []string{},
+			Extra:     []string{"module.replica.aws_s3_bucket.this"},
+			Resources: 3,
+		}, report.Instances[1])
+	})
+
+	t.Run("reports missing resources", func(t *testing.T) {
+		instance := report.Instances[2]
+		assert.Equal(t, `module.buckets["logs"]`, instance.Instance)
+		assert.False(t, instance.Conforms)
+		assert.Equal(t, []string{"aws_s3_bucket_versioning.this"}, instance.Missing)
+		assert.Equal(t, []string{"data.aws_iam_policy_document.this"}, instance.Extra)
+	})
+
+	t.Run("finds nested instances", func(t *testing.T) {
+		instance := report.Instances[0]
+		assert.Equal(t, "module.app.module.buckets", instance.Instance)
+		assert.True(t, instance.Conforms)
+	})
+
+	t.Run("ignores other modules", func(t *testing.T) {
+		report := Conformance(models.GoldenModule{Name: "app", Module: "network"}, map[string]*models.TerraformState{"prod": goldenState()})
+
+		assert.Empty(t, report.Instances)
+	})
+}
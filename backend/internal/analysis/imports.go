@@ -0,0 +1,101 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+// sharedIDTypes are the types whose IDs are often shared by other accounts,
+// such as public images, and so cannot be imported from a reference alone.
+var sharedIDTypes = map[string]bool{
+	"aws_ami":          true,
+	"aws_ebs_snapshot": true,
+}
+
+// ImportSuggestions finds provider IDs referenced from resource attributes
+// that no resource or data source in the state owns, and proposes the
+// terraform import command that would bring each one under management.
+func ImportSuggestions(state *models.TerraformState) *models.ImportReport {
+	owned := make(map[string]bool)
+	for _, ref := range parser.Instances(state) {
+		if id := stringAttr(ref.Instance.Attributes, "id"); id != "" {
+			owned[id] = true
+		}
+	}
+
+	referencedBy := make(map[string][]string)
+	types := make(map[string]string)
+
+	for _, ref := range managedInstances(state) {
+		for key, value := range ref.Instance.Attributes {
+			if key == "id" || key == "arn" {
+				continue
+			}
+
+			walkStrings(value, func(s string) {
+				resourceType, ok := importType(s)
+				if !ok || owned[s] {
+					return
+				}
+
+				types[s] = resourceType
+				referencedBy[s] = append(referencedBy[s], ref.NodeID)
+			})
+		}
+	}
+
+	report := &models.ImportReport{Suggestions: []models.ImportSuggestion{}}
+
+	ids := make([]string, 0, len(types))
+	for id := range types {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		address := fmt.Sprintf("%s.%s", types[id], strings.ReplaceAll(id, "-", "_"))
+		report.Suggestions = append(report.Suggestions, models.ImportSuggestion{
+			ID:           id,
+			ResourceType: types[id],
+			Address:      address,
+			Command:      fmt.Sprintf("terraform import %s %s", address, id),
+			ReferencedBy: uniqueSorted(referencedBy[id]),
+		})
+	}
+
+	return report
+}
+
+// importType returns the resource type of a provider ID that can be passed
+// verbatim to terraform import.
+func importType(value string) (string, bool) {
+	resourceType, ok := parser.IDResourceType(value)
+	if !ok || resourceType == "" || sharedIDTypes[resourceType] {
+		return "", false
+	}
+
+	return resourceType, true
+}
+
+// walkStrings calls fn for every string nested anywhere inside value.
+func walkStrings(value any, fn func(string)) {
+	switch v := value.(type) {
+	case string:
+		fn(v)
+	case []any:
+		for _, item := range v {
+			walkStrings(item, fn)
+		}
+	case map[string]any:
+		for _, item := range v {
+			walkStrings(item, fn)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestImportSuggestions(t *testing.T) {
+	t.Run("empty state has no suggestions", func(t *testing.T) {
+		report := ImportSuggestions(&models.TerraformState{})
+
+		assert.Empty(t, report.Suggestions)
+	})
+
+	t.Run("hardcoded unmanaged ids are suggested", func(t *testing.T) {
+		state := &models.TerraformState{
+			Resources: []models.ResourceState{
+				managedResource("aws_instance", "web", map[string]any{
+					"id":                     "i-0123456789abcdef0",
+					"subnet_id":              "subnet-0abc12345",
+					"vpc_security_group_ids": []any{"sg-0def67890", "sg-0aaa11111"},
+				}),
+				managedResource("aws_instance", "worker", map[string]any{
+					"id":        "i-0fedcba9876543210",
+					"subnet_id": "subnet-0abc12345",
+				}),
+				managedResource("aws_security_group", "web", map[string]any{"id": "sg-0aaa11111"}),
+			},
+		}
+
+		report := ImportSuggestions(state)
+
+		require.Len(t, report.Suggestions, 2)
+
+		sg := report.Suggestions[0]
+		assert.Equal(t, "sg-0def67890", sg.ID)
+		assert.Equal(t, "aws_security_group", sg.ResourceType)
+		assert.Equal(t, []string{"aws_instance.web"}, sg.ReferencedBy)
+
+		subnet := report.Suggestions[1]
+		assert.Equal(t, "subnet-0abc12345", subnet.ID)
+		assert.Equal(t, "aws_subnet.subnet_0abc12345", subnet.Address)
+		assert.Equal(t, "terraform import aws_subnet.subnet_0abc12345 subnet-0abc12345", subnet.Command)
+		assert.Equal(t, []string{"aws_instance.web", "aws_instance.worker"}, subnet.ReferencedBy)
+	})
+
+	t.Run("ids owned by data sources are not suggested", func(t *testing.T) {
+		lookup := managedResource("aws_vpc", "shared", map[string]any{"id": "vpc-0123abcd"})
+		lookup.Mode = "data"
+		state := &models.TerraformState{
+			Resources: []models.ResourceState{
+				lookup,
+				managedResource("aws_subnet", "app", map[string]any{"id": "subnet-0abc12345", "vpc_id": "vpc-0123abcd"}),
+			},
+		}
+
+		report := ImportSuggestions(state)
+
+		assert.Empty(t, report.Suggestions)
+	})
+
+	t.Run("values that only resemble ids are ignored", func(t *testing.T) {
+		state := &models.TerraformState{
+			Resources: []models.ResourceState{
+				managedResource("aws_s3_bucket", "logs", map[string]any{"id": "logs", "bucket": "vpc-logs-bucket"}),
+			},
+		}
+
+		report := ImportSuggestions(state)
+
+		assert.Empty(t, report.Suggestions)
+	})
+}
+
+func TestWalkStrings(t *testing.T) {
+	var found []string
+	walkStrings(map[string]any{
+		"a": []any{"x", map[string]any{"b": "y"}},
+		"c": float64(1),
+	}, func(s string) { found = append(found, s) })
+
+	assert.ElementsMatch(t, []string{"x", "y"}, found)
+}
@@ -0,0 +1,61 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"github.com/terrascope/core/internal/models"
+)
+
+// JUnit reports findings as a JUnit XML report with a test suite for each of
+// the analyzers that ran. Each finding is a test case of its analyzer, which
+// fails when the finding is at or above the failOn severity and passes with
+// the finding as output otherwise. An analyzer without findings gets a single
+// passing test case, so that CI shows it ran.
+func JUnit(analyzers []string, findings []models.Finding, failOn string) *models.JUnitTestSuites {
+	report := &models.JUnitTestSuites{Name: "terrascope", Suites: []models.JUnitTestSuite{}}
+
+	byAnalyzer := make(map[string][]models.Finding, len(analyzers))
+	for _, finding := range findings {
+		byAnalyzer[finding.Analyzer] = append(byAnalyzer[finding.Analyzer], finding)
+	}
+
+	for _, analyzer := range analyzers {
+		suite := models.JUnitTestSuite{Name: analyzer}
+
+		for _, finding := range byAnalyzer[analyzer] {
+			name := finding.Address
+			if name == "" {
+				name = finding.NodeID
+			}
+
+			testCase := models.JUnitTestCase{Name: name, ClassName: analyzer + "." + finding.Check}
+
+			details := finding.Message
+			if finding.Remediation != "" {
+				details += "\n" + finding.Remediation
+			}
+			details += "\nfinding " + finding.ID
+
+			if AtLeast(finding.Severity, failOn) {
+				testCase.Failure = &models.JUnitFailure{Message: finding.Message, Type: finding.Severity, Text: details}
+				suite.Failures++
+			} else {
+				testCase.SystemOut = finding.Severity + ": " + details
+			}
+
+			suite.Cases = append(suite.Cases, testCase)
+		}
+
+		if len(suite.Cases) == 0 {
+			suite.Cases = append(suite.Cases, models.JUnitTestCase{Name: analyzer, ClassName: analyzer})
+		}
+
+		suite.Tests = len(suite.Cases)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Suites = append(report.Suites, suite)
+	}
+
+	return report
+}
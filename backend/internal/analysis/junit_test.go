@@ -0,0 +1,72 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestJUnit(t *testing.T) {
+	findings := []models.Finding{
+		{
+			ID: "0123456789abcdef", Analyzer: "security", Check: "public_bucket", Severity: models.SeverityError,
+			NodeID: "aws_s3_bucket.site", Address: "aws_s3_bucket.site",
+			Message: "bucket is public", Remediation: "set the acl to private",
+		},
+		{
+			ID: "fedcba9876543210", Analyzer: "security", Check: "unencrypted_storage", Severity: models.SeverityWarning,
+			NodeID: "aws_ebs_volume.data", Address: "aws_ebs_volume.data",
+			Message: "volume is not encrypted",
+		},
+	}
+
+	report := JUnit([]string{"security", "stale"}, findings, models.SeverityError)
+
+	assert.Equal(t, 3, report.Tests)
+	assert.Equal(t, 1, report.Failures)
+	require.Len(t, report.Suites, 2)
+
+	t.Run("fails the findings at or above the threshold", func(t *testing.T) {
+		suite := report.Suites[0]
+		assert.Equal(t, "security", suite.Name)
+		assert.Equal(t, 2, suite.Tests)
+		assert.Equal(t, 1, suite.Failures)
+
+		assert.Equal(t, models.JUnitTestCase{
+			Name:      "aws_s3_bucket.site",
+			ClassName: "security.public_bucket",
+			Failure: &models.JUnitFailure{
+				Message: "bucket is public",
+				Type:    "error",
+				Text:    "bucket is public\nset the acl to private\nfinding 0123456789abcdef",
+			},
+		}, suite.Cases[0])
+
+		assert.Nil(t, suite.Cases[1].Failure)
+		assert.Equal(t, "warning: volume is not encrypted\nfinding fedcba9876543210", suite.Cases[1].SystemOut)
+	})
+
+	t.Run("passes analyzers without findings", func(t *testing.T) {
+		assert.Equal(t, models.JUnitTestSuite{
+			Name:  "stale",
+			Tests: 1,
+			Cases: []models.JUnitTestCase{{Name: "stale", ClassName: "stale"}},
+		}, report.Suites[1])
+	})
+
+	t.Run("encodes as JUnit XML", func(t *testing.T) {
+		data, err := xml.Marshal(JUnit([]string{"stale"}, nil, models.SeverityInfo))
+		require.NoError(t, err)
+
+		assert.Equal(t, `<testsuites name="terrascope" tests="1" failures="0">`+
+			`<testsuite name="stale" tests="1" failures="0"><testcase name="stale" classname="stale"></testcase></testsuite>`+
+			`</testsuites>`, string(data))
+	})
+}
@@ -0,0 +1,235 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/terrascope/core/internal/codes"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+// Scopes of service limits.
+const (
+	LimitScopeAccount  = "account"
+	LimitScopeRegion   = "region"
+	LimitScopeVPC      = "vpc"
+	LimitScopeResource = "resource"
+)
+
+const (
+	warningTypeAtLimit          = "at_limit"
+	warningTypeApproachingLimit = "approaching_limit"
+	warningTypeLimitForecast    = "limit_forecast"
+)
+
+// approachingLimit is the share of a quota from which its usage is reported
+// as approaching the limit.
+const approachingLimit = 0.8
+
+// serviceLimit is a default quota of a provider on the number of resources of
+// a type within a scope. VPC-scoped limits group resources by the VPC named
+// by attribute, and resource-scoped limits count the elements of attribute
+// in each instance.
+type serviceLimit struct {
+	name      string
+	resType   string
+	scope     string
+	quota     int
+	attribute string
+}
+
+// serviceLimits are the default AWS quotas Limits checks. Accounts with
+// raised quotas will see warnings early.
+var serviceLimits = []serviceLimit{
+	{"VPCs per region", "aws_vpc", LimitScopeRegion, 5, ""},
+	{"Internet gateways per region", "aws_internet_gateway", LimitScopeRegion, 5, ""},
+	{"Elastic IP addresses per region", "aws_eip", LimitScopeRegion, 5, ""},
+	{"NAT gateways per region", "aws_nat_gateway", LimitScopeRegion, 5, ""},
+	{"Security groups per region", "aws_security_group", LimitScopeRegion, 2500, ""},
+	{"Subnets per VPC", "aws_subnet", LimitScopeVPC, 200, "vpc_id"},
+	{"Route tables per VPC", "aws_route_table", LimitScopeVPC, 200, "vpc_id"},
+	{"S3 buckets per account", "aws_s3_bucket", LimitScopeAccount, 100, ""},
+	{"IAM roles per account", "aws_iam_role", LimitScopeAccount, 1000, ""},
+	{"Security groups per network interface", "aws_network_interface", LimitScopeResource, 5, "security_groups"},
+	{"Security groups per network interface", "aws_instance", LimitScopeResource, 5, "vpc_security_group_ids"},
+	{"Inbound rules per security group", "aws_security_group", LimitScopeResource, 60, "ingress"},
+}
+
+// LimitSample is a version of a state and the time it was stored.
+type LimitSample struct {
+	At    time.Time
+	State *models.TerraformState
+}
+
+type limitKey struct {
+	limit int
+	group string
+}
+
+// Limits compares the resource counts of the last sample with serviceLimits
+// and fits their growth over the samples, ordered from the oldest, to
+// project them horizonDays ahead. It warns about quotas that are reached,
+// nearly reached, or projected to be reached within the horizon.
+// Resource-scoped quotas only depend on the last sample.
+func Limits(samples []LimitSample, horizonDays int) *models.LimitsReport {
+	report := &models.LimitsReport{Samples: len(samples), HorizonDays: horizonDays, Usage: []models.LimitUsage{}}
+	if len(samples) == 0 {
+		return report
+	}
+
+	last := samples[len(samples)-1]
+	report.Lineage = last.State.Lineage
+
+	history := make(map[limitKey][]float64)
+	days := make([]float64, len(samples))
+	for i, sample := range samples {
+		days[i] = sample.At.Sub(samples[0].At).Hours() / 24
+
+		for key, count := range limitCounts(sample.State) {
+			if history[key] == nil {
+				history[key] = make([]float64, len(samples))
+			}
+			history[key][i] = float64(count)
+		}
+	}
+
+	current := limitCounts(last.State)
+
+	keys := make([]limitKey, 0, len(current))
+	for key := range current {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].limit != keys[j].limit {
+			return keys[i].limit < keys[j].limit
+		}
+		return keys[i].group < keys[j].group
+	})
+
+	warn := func(warningType, address, message string) {
+		report.Warnings = append(report.Warnings, models.Warning{Code: codes.ForWarning(warningType), Type: warningType, Address: address, Message: message})
+	}
+
+	for _, key := range keys {
+		limit := serviceLimits[key.limit]
+		usage := models.LimitUsage{
+			Limit:     limit.name,
+			Type:      limit.resType,
+			Scope:     limit.scope,
+			Group:     key.group,
+			Quota:     limit.quota,
+			Current:   current[key],
+			Projected: current[key],
+		}
+
+		if limit.scope != LimitScopeResource {
+			usage.GrowthPerDay = math.Round(growthPerDay(days, history[key])*100) / 100
+			if usage.GrowthPerDay > 0 {
+				usage.Projected = int(math.Round(float64(usage.Current) + usage.GrowthPerDay*float64(horizonDays)))
+
+				if usage.Current < limit.quota {
+					daysToLimit := int(math.Ceil(float64(limit.quota-usage.Current) / usage.GrowthPerDay))
+					usage.DaysToLimit = &daysToLimit
+				}
+			}
+		}
+
+		subject := limit.name
+		if key.group != "" {
+			subject += " in " + key.group
+		}
+
+		switch {
+		case usage.Current >= limit.quota:
+			warn(warningTypeAtLimit, key.group, fmt.Sprintf("%s: %d of %d used", subject, usage.Current, limit.quota))
+		case float64(usage.Current) >= approachingLimit*float64(limit.quota):
+			warn(warningTypeApproachingLimit, key.group, fmt.Sprintf("%s: %d of %d used", subject, usage.Current, limit.quota))
+		case usage.DaysToLimit != nil && *usage.DaysToLimit <= horizonDays:
+			warn(warningTypeLimitForecast, key.group, fmt.Sprintf("%s: %d of %d used, projected to reach the limit in %d days", subject, usage.Current, limit.quota, *usage.DaysToLimit))
+		}
+
+		report.Usage = append(report.Usage, usage)
+	}
+
+	return report
+}
+
+// limitCounts counts the managed instances of the state against each
+// service limit, by group.
+func limitCounts(state *models.TerraformState) map[limitKey]int {
+	counts := make(map[limitKey]int)
+
+	for _, ref := range managedInstances(state) {
+		if ref.Instance.Deposed != "" {
+			continue
+		}
+
+		attrs := ref.Instance.Attributes
+
+		for i, limit := range serviceLimits {
+			if limit.resType != ref.Resource.Type {
+				continue
+			}
+
+			switch limit.scope {
+			case LimitScopeAccount:
+				counts[limitKey{i, ""}]++
+			case LimitScopeRegion:
+				counts[limitKey{i, instanceRegion(attrs)}]++
+			case LimitScopeVPC:
+				counts[limitKey{i, stringAttr(attrs, limit.attribute)}]++
+			case LimitScopeResource:
+				if items, ok := attrs[limit.attribute].([]any); ok && len(items) > 0 {
+					counts[limitKey{i, parser.InstanceAddress(ref.Resource, ref.Instance)}] = len(items)
+				}
+			}
+		}
+	}
+
+	return counts
+}
+
+// instanceRegion returns the region of an instance, from its region
+// attribute or its ARN, or an empty string when it tells neither.
+func instanceRegion(attrs map[string]any) string {
+	if region := stringAttr(attrs, "region"); region != "" {
+		return region
+	}
+
+	if parts := strings.SplitN(stringAttr(attrs, "arn"), ":", 5); len(parts) == 5 {
+		return parts[3]
+	}
+
+	return ""
+}
+
+// growthPerDay fits the counts over the days of their samples with a least
+// squares line and returns its slope, or 0 without growth to fit.
+func growthPerDay(days, counts []float64) float64 {
+	n := float64(len(days))
+	if n < 2 {
+		return 0
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	for i := range days {
+		sumX += days[i]
+		sumY += counts[i]
+		sumXY += days[i] * counts[i]
+		sumXX += days[i] * days[i]
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+
+	return (n*sumXY - sumX*sumY) / denominator
+}
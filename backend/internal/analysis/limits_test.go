@@ -0,0 +1,143 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// limitState returns a state with the given numbers of VPCs in eu-west-1 and
+// of S3 buckets.
+func limitState(vpcs, buckets int) *models.TerraformState {
+	state := &models.TerraformState{Lineage: "prod"}
+
+	for i := range vpcs {
+		state.Resources = append(state.Resources, managedResource("aws_vpc", fmt.Sprintf("vpc%d", i), map[string]any{
+			"id": fmt.Sprintf("vpc-%d", i), "arn": fmt.Sprintf("arn:aws:ec2:eu-west-1:123456789012:vpc/vpc-%d", i),
+		}))
+	}
+
+	for i := range buckets {
+		state.Resources = append(state.Resources, managedResource("aws_s3_bucket", fmt.Sprintf("bucket%d", i), map[string]any{
+			"id": fmt.Sprintf("bucket-%d", i), "arn": fmt.Sprintf("arn:aws:s3:::bucket-%d", i),
+		}))
+	}
+
+	return state
+}
+
+func findUsage(report *models.LimitsReport, limit, group string) (models.LimitUsage, bool) {
+	for _, usage := range report.Usage {
+		if usage.Limit == limit && usage.Group == group {
+			return usage, true
+		}
+	}
+
+	return models.LimitUsage{}, false
+}
+
+func TestLimits(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("counts resources by scope", func(t *testing.T) {
+		report := Limits([]LimitSample{{At: start, State: limitState(2, 3)}}, 90)
+
+		assert.Equal(t, "prod", report.Lineage)
+		assert.Equal(t, 1, report.Samples)
+
+		vpcs, ok := findUsage(report, "VPCs per region", "eu-west-1")
+		require.True(t, ok)
+		assert.Equal(t, models.LimitUsage{
+			Limit: "VPCs per region", Type: "aws_vpc", Scope: LimitScopeRegion, Group: "eu-west-1",
+			Quota: 5, Current: 2, Projected: 2,
+		}, vpcs)
+
+		buckets, ok := findUsage(report, "S3 buckets per account", "")
+		require.True(t, ok)
+		assert.Equal(t, 3, buckets.Current)
+
+		assert.Empty(t, report.Warnings)
+	})
+
+	t.Run("projects growth toward the limit", func(t *testing.T) {
+		report := Limits([]LimitSample{
+			{At: start, State: limitState(1, 40)},
+			{At: start.AddDate(0, 0, 10), State: limitState(1, 50)},
+			{At: start.AddDate(0, 0, 20), State: limitState(1, 60)},
+		}, 90)
+
+		buckets, ok := findUsage(report, "S3 buckets per account", "")
+		require.True(t, ok)
+		assert.Equal(t, 1.0, buckets.GrowthPerDay)
+		assert.Equal(t, 150, buckets.Projected)
+		require.NotNil(t, buckets.DaysToLimit)
+		assert.Equal(t, 40, *buckets.DaysToLimit)
+
+		require.Len(t, report.Warnings, 1)
+		assert.Equal(t, models.Warning{
+			Code:    "TS303",
+			Type:    "limit_forecast",
+			Message: "S3 buckets per account: 60 of 100 used, projected to reach the limit in 40 days",
+		}, report.Warnings[0])
+	})
+
+	t.Run("does not forecast beyond the horizon", func(t *testing.T) {
+		report := Limits([]LimitSample{
+			{At: start, State: limitState(0, 40)},
+			{At: start.AddDate(0, 0, 20), State: limitState(0, 60)},
+		}, 30)
+
+		assert.Empty(t, report.Warnings)
+	})
+
+	t.Run("warns about quotas reached or nearly reached", func(t *testing.T) {
+		report := Limits([]LimitSample{{At: start, State: limitState(5, 80)}}, 90)
+
+		_, ok := findWarning(report.Warnings, "at_limit", "eu-west-1")
+		assert.True(t, ok)
+		warning, ok := findWarning(report.Warnings, "approaching_limit", "")
+		require.True(t, ok)
+		assert.Equal(t, "S3 buckets per account: 80 of 100 used", warning.Message)
+	})
+
+	t.Run("counts per-resource quotas", func(t *testing.T) {
+		state := &models.TerraformState{Resources: []models.ResourceState{
+			managedResource("aws_network_interface", "web", map[string]any{
+				"id": "eni-1", "security_groups": []any{"sg-1", "sg-2", "sg-3", "sg-4", "sg-5"},
+			}),
+			managedResource("aws_instance", "app", map[string]any{"id": "i-1", "vpc_security_group_ids": []any{"sg-1"}}),
+		}}
+
+		report := Limits([]LimitSample{{At: start, State: state}}, 90)
+
+		usage, ok := findUsage(report, "Security groups per network interface", "aws_network_interface.web")
+		require.True(t, ok)
+		assert.Equal(t, 5, usage.Current)
+		_, ok = findWarning(report.Warnings, "at_limit", "aws_network_interface.web")
+		assert.True(t, ok)
+
+		_, ok = findUsage(report, "Security groups per network interface", "aws_instance.app")
+		assert.True(t, ok)
+	})
+
+	t.Run("reports nothing without samples", func(t *testing.T) {
+		report := Limits(nil, 90)
+
+		assert.Empty(t, report.Usage)
+		assert.Empty(t, report.Warnings)
+	})
+}
+
+func TestGrowthPerDay(t *testing.T) {
+	assert.Equal(t, 2.0, growthPerDay([]float64{0, 1, 2}, []float64{1, 3, 5}))
+	assert.Zero(t, growthPerDay([]float64{0}, []float64{4}))
+	assert.Zero(t, growthPerDay([]float64{3, 3}, []float64{1, 2}), "samples stored at once have no growth")
+}
@@ -0,0 +1,37 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"path"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+// Measure counts the current managed resource instances of the state by type
+// and provider and estimates their cost. The caller fills in the time the
+// state was stored.
+func Measure(state *models.TerraformState) models.InfrastructurePoint {
+	point := models.InfrastructurePoint{
+		Serial:     state.Serial,
+		ByType:     make(map[string]int),
+		ByProvider: make(map[string]int),
+		Cost:       EstimateCost(state),
+	}
+
+	for _, ref := range managedInstances(state) {
+		if ref.Instance.Deposed != "" {
+			continue
+		}
+
+		point.Resources++
+		point.ByType[ref.Resource.Type]++
+		if source := parser.ProviderSource(ref.Resource.Provider); source != "" {
+			point.ByProvider[path.Base(source)]++
+		}
+	}
+
+	return point
+}
@@ -0,0 +1,47 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestMeasure(t *testing.T) {
+	random := models.ResourceState{
+		Mode:      "managed",
+		Type:      "random_id",
+		Name:      "suffix",
+		Provider:  `provider["registry.terraform.io/hashicorp/random"]`,
+		Instances: []models.ResourceInstance{{}, {}},
+	}
+
+	state := &models.TerraformState{
+		Serial: 7,
+		Resources: []models.ResourceState{
+			managedResource("aws_instance", "web", map[string]any{"instance_type": "t3.micro"}),
+			managedResource("aws_instance", "worker", map[string]any{"instance_type": "t3.micro"}),
+			managedResource("aws_s3_bucket", "logs", nil),
+			random,
+			{
+				Mode:      "data",
+				Type:      "aws_ami",
+				Name:      "ubuntu",
+				Provider:  awsProvider,
+				Instances: []models.ResourceInstance{{}},
+			},
+		},
+	}
+
+	point := Measure(state)
+
+	assert.Equal(t, 7, point.Serial)
+	assert.Equal(t, 5, point.Resources)
+	assert.Equal(t, map[string]int{"aws_instance": 2, "aws_s3_bucket": 1, "random_id": 2}, point.ByType)
+	assert.Equal(t, map[string]int{"aws": 3, "random": 2}, point.ByProvider)
+	assert.Equal(t, 2, point.Cost.Priced)
+	assert.Equal(t, 3, point.Cost.Unpriced)
+}
@@ -0,0 +1,110 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+	"github.com/terrascope/core/internal/registry"
+)
+
+// ModuleRegistry looks up the newest published version of a module source.
+type ModuleRegistry interface {
+	LatestModuleVersion(ctx context.Context, source string) (string, error)
+}
+
+// maxRegistryLookups caps the registry requests of one analysis, so that a
+// state naming many sources cannot fan out into as many outbound calls.
+const maxRegistryLookups = 50
+
+// errTooManyLookups stands in for the lookups past maxRegistryLookups.
+var errTooManyLookups = fmt.Errorf("not looked up: more than %d registry lookups", maxRegistryLookups)
+
+// Modules lists the modules that own resources in the state. When a module
+// manifest is supplied each module gets its source and version, and when a
+// registry is supplied registry modules are compared with their latest
+// published version.
+func Modules(ctx context.Context, state *models.TerraformState, manifest *models.ModuleManifest, reg ModuleRegistry) *models.ModuleReport {
+	modules := make(map[string]*models.ModuleInfo)
+
+	for _, res := range state.Resources {
+		key := parser.ModuleKey(res.Module)
+		if key == "" {
+			continue
+		}
+
+		info, ok := modules[key]
+		if !ok {
+			info = &models.ModuleInfo{Key: key}
+			modules[key] = info
+		}
+
+		info.Instances = append(info.Instances, res.Module)
+		info.Resources += len(res.Instances)
+	}
+
+	if manifest != nil {
+		for _, entry := range manifest.Modules {
+			if entry.Key == "" {
+				continue
+			}
+
+			info, ok := modules[entry.Key]
+			if !ok {
+				info = &models.ModuleInfo{Key: entry.Key}
+				modules[entry.Key] = info
+			}
+
+			info.Source = entry.Source
+			info.Version = entry.Version
+		}
+	}
+
+	latest := make(map[string]string)
+	failures := make(map[string]error)
+
+	report := &models.ModuleReport{Modules: []models.ModuleInfo{}}
+	for _, key := range sortedModuleKeys(modules) {
+		info := modules[key]
+		info.Instances = uniqueSorted(info.Instances)
+
+		if reg != nil && info.Version != "" {
+			if _, seen := latest[info.Source]; !seen {
+				if len(latest) < maxRegistryLookups {
+					version, err := reg.LatestModuleVersion(ctx, info.Source)
+					latest[info.Source], failures[info.Source] = version, err
+				} else {
+					latest[info.Source], failures[info.Source] = "", errTooManyLookups
+				}
+			}
+
+			switch err := failures[info.Source]; {
+			case err == nil:
+				info.LatestVersion = latest[info.Source]
+				info.Outdated = registry.CompareVersions(info.Version, info.LatestVersion) < 0
+			case !errors.Is(err, registry.ErrNotRegistrySource):
+				info.RegistryError = err.Error()
+			}
+		}
+
+		report.Modules = append(report.Modules, *info)
+	}
+
+	return report
+}
+
+func sortedModuleKeys(modules map[string]*models.ModuleInfo) []string {
+	keys := make([]string, 0, len(modules))
+	for key := range modules {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
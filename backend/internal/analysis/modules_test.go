@@ -0,0 +1,172 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/registry"
+)
+
+type fakeRegistry struct {
+	versions map[string]string
+	calls    map[string]int
+}
+
+func (f *fakeRegistry) LatestModuleVersion(_ context.Context, source string) (string, error) {
+	f.calls[source]++
+
+	if _, ok := registry.ModuleAddress(source); !ok {
+		return "", registry.ErrNotRegistrySource
+	}
+
+	version, ok := f.versions[source]
+	if !ok {
+		return "", errors.New("registry returned 404 Not Found")
+	}
+
+	return version, nil
+}
+
+func moduleResource(module, resType, name string, instances int) models.ResourceState {
+	res := managedResource(resType, name, map[string]any{"id": name})
+	res.Module = module
+	res.Instances = make([]models.ResourceInstance, instances)
+
+	return res
+}
+
+func moduleState() *models.TerraformState {
+	return &models.TerraformState{
+		Resources: []models.ResourceState{
+			managedResource("aws_s3_bucket", "root", map[string]any{"id": "root"}),
+			moduleResource("module.vpc", "aws_vpc", "this", 1),
+			moduleResource("module.vpc", "aws_subnet", "private", 3),
+			moduleResource("module.app[0]", "aws_instance", "web", 1),
+			moduleResource("module.app[1]", "aws_instance", "web", 1),
+			moduleResource("module.app[0].module.db", "aws_db_instance", "main", 1),
+		},
+	}
+}
+
+func moduleManifest() *models.ModuleManifest {
+	return &models.ModuleManifest{
+		Modules: []models.ModuleManifestEntry{
+			{Key: "", Source: "", Dir: "."},
+			{Key: "vpc", Source: "registry.terraform.io/terraform-aws-modules/vpc/aws", Version: "5.1.0"},
+			{Key: "app", Source: "./modules/app"},
+			{Key: "app.db", Source: "registry.terraform.io/acme/db/aws", Version: "1.0.0"},
+			{Key: "cdn", Source: "registry.terraform.io/terraform-aws-modules/cloudfront/aws", Version: "3.2.0"},
+		},
+	}
+}
+
+func findModule(report *models.ModuleReport, key string) (models.ModuleInfo, bool) {
+	for _, info := range report.Modules {
+		if info.Key == key {
+			return info, true
+		}
+	}
+
+	return models.ModuleInfo{}, false
+}
+
+func TestModules(t *testing.T) {
+	t.Run("lists modules from the state", func(t *testing.T) {
+		report := Modules(context.Background(), moduleState(), nil, nil)
+
+		require.Len(t, report.Modules, 3)
+		assert.Equal(t, "app", report.Modules[0].Key)
+		assert.Equal(t, []string{"module.app[0]", "module.app[1]"}, report.Modules[0].Instances)
+		assert.Equal(t, 2, report.Modules[0].Resources)
+
+		vpc, ok := findModule(report, "vpc")
+		require.True(t, ok)
+		assert.Equal(t, 4, vpc.Resources)
+		assert.Empty(t, vpc.Source)
+	})
+
+	t.Run("records sources and versions from the manifest", func(t *testing.T) {
+		report := Modules(context.Background(), moduleState(), moduleManifest(), nil)
+
+		vpc, _ := findModule(report, "vpc")
+		assert.Equal(t, "registry.terraform.io/terraform-aws-modules/vpc/aws", vpc.Source)
+		assert.Equal(t, "5.1.0", vpc.Version)
+
+		cdn, ok := findModule(report, "cdn")
+		require.True(t, ok)
+		assert.Equal(t, 0, cdn.Resources)
+		assert.Empty(t, cdn.Instances)
+	})
+
+	t.Run("flags outdated registry modules", func(t *testing.T) {
+		reg := &fakeRegistry{
+			versions: map[string]string{
+				"registry.terraform.io/terraform-aws-modules/vpc/aws":        "5.8.1",
+				"registry.terraform.io/terraform-aws-modules/cloudfront/aws": "3.2.0",
+			},
+			calls: map[string]int{},
+		}
+
+		report := Modules(context.Background(), moduleState(), moduleManifest(), reg)
+
+		vpc, _ := findModule(report, "vpc")
+		assert.Equal(t, "5.8.1", vpc.LatestVersion)
+		assert.True(t, vpc.Outdated)
+
+		cdn, _ := findModule(report, "cdn")
+		assert.Equal(t, "3.2.0", cdn.LatestVersion)
+		assert.False(t, cdn.Outdated)
+
+		db, _ := findModule(report, "app.db")
+		assert.False(t, db.Outdated)
+		assert.Contains(t, db.RegistryError, "404")
+
+		app, _ := findModule(report, "app")
+		assert.Empty(t, app.RegistryError)
+		assert.Zero(t, reg.calls["./modules/app"])
+	})
+
+	t.Run("queries each source once", func(t *testing.T) {
+		manifest := moduleManifest()
+		manifest.Modules = append(manifest.Modules, models.ModuleManifestEntry{
+			Key: "vpc_secondary", Source: "registry.terraform.io/terraform-aws-modules/vpc/aws", Version: "5.8.1",
+		})
+		reg := &fakeRegistry{versions: map[string]string{}, calls: map[string]int{}}
+
+		Modules(context.Background(), moduleState(), manifest, reg)
+
+		assert.Equal(t, 1, reg.calls["registry.terraform.io/terraform-aws-modules/vpc/aws"])
+	})
+
+	t.Run("caps the registry lookups of one analysis", func(t *testing.T) {
+		manifest := &models.ModuleManifest{}
+		for i := range maxRegistryLookups + 10 {
+			manifest.Modules = append(manifest.Modules, models.ModuleManifestEntry{
+				Key: fmt.Sprintf("m%02d", i), Source: fmt.Sprintf("acme/m%02d/aws", i), Version: "1.0.0",
+			})
+		}
+		reg := &fakeRegistry{versions: map[string]string{}, calls: map[string]int{}}
+
+		report := Modules(context.Background(), &models.TerraformState{}, manifest, reg)
+
+		assert.Len(t, reg.calls, maxRegistryLookups)
+		last, ok := findModule(report, fmt.Sprintf("m%02d", maxRegistryLookups+9))
+		require.True(t, ok)
+		assert.Equal(t, errTooManyLookups.Error(), last.RegistryError)
+	})
+
+	t.Run("state without modules", func(t *testing.T) {
+		report := Modules(context.Background(), &models.TerraformState{}, nil, nil)
+
+		assert.NotNil(t, report.Modules)
+		assert.Empty(t, report.Modules)
+	})
+}
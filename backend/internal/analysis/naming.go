@@ -0,0 +1,184 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+// defaultNameAttribute is the attribute a naming rule checks unless it names
+// another.
+const defaultNameAttribute = "name"
+
+// NamingPolicy is an organization's naming standard: every managed resource
+// matched by a rule must have a name matching the rule's pattern.
+type NamingPolicy struct {
+	Rules []NamingRule `json:"rules"`
+}
+
+// NamingRule applies to the managed resources whose type matches the
+// ResourceType glob and whose provider is Provider (e.g. hashicorp/aws), any
+// type or provider when empty. It checks the Attribute holding the cloud
+// name, a dotted path such as tags.Name, "name" by default; resources without
+// that attribute are skipped.
+type NamingRule struct {
+	Name         string `json:"name"`
+	ResourceType string `json:"resource_type,omitempty"`
+	Provider     string `json:"provider,omitempty"`
+	Attribute    string `json:"attribute,omitempty"`
+	Pattern      string `json:"pattern"`
+	Severity     string `json:"severity,omitempty"`
+	Remediation  string `json:"remediation,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// ParseNamingPolicy decodes a JSON naming policy and checks its rules: each
+// needs a name, a valid pattern and type glob, and a known severity.
+func ParseNamingPolicy(data []byte) (*NamingPolicy, error) {
+	var policy NamingPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, err
+	}
+
+	for i := range policy.Rules {
+		rule := &policy.Rules[i]
+
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule %d: missing name", i+1)
+		}
+
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf("rule %s: missing pattern", rule.Name)
+		}
+
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: invalid pattern: %w", rule.Name, err)
+		}
+		rule.pattern = pattern
+
+		if _, err := path.Match(rule.ResourceType, ""); err != nil {
+			return nil, fmt.Errorf("rule %s: invalid resource_type pattern %q", rule.Name, rule.ResourceType)
+		}
+
+		if rule.Severity == "" {
+			rule.Severity = models.SeverityWarning
+		} else if _, ok := severityRanks[rule.Severity]; !ok {
+			return nil, fmt.Errorf("rule %s: unknown severity %q", rule.Name, rule.Severity)
+		}
+
+		if rule.Provider != "" {
+			rule.Provider = parser.NormalizeProviderSource(rule.Provider)
+		}
+
+		if rule.Attribute == "" {
+			rule.Attribute = defaultNameAttribute
+		}
+	}
+
+	return &policy, nil
+}
+
+// CheckNaming reports a finding for every managed resource instance whose
+// name does not match a rule of the policy that applies to it. A nil policy
+// finds nothing.
+func CheckNaming(state *models.TerraformState, policy *NamingPolicy) *models.FindingsReport {
+	return checkNaming(context.Background(), state, policy)
+}
+
+func checkNaming(ctx context.Context, state *models.TerraformState, policy *NamingPolicy) *models.FindingsReport {
+	report := &models.FindingsReport{Findings: []models.Finding{}}
+	if policy == nil {
+		return report
+	}
+
+	for _, ref := range managedInstances(state) {
+		if ctx.Err() != nil {
+			return report
+		}
+
+		for _, rule := range policy.Rules {
+			if !rule.applies(ref.Resource) {
+				continue
+			}
+
+			name, ok := nameAttr(ref.Instance.Attributes, rule.Attribute)
+			if !ok || rule.pattern.MatchString(name) {
+				continue
+			}
+
+			remediation := rule.Remediation
+			if remediation == "" {
+				remediation = fmt.Sprintf("rename it to match %s", rule.Pattern)
+			}
+
+			report.Findings = append(report.Findings, models.Finding{
+				Check:       rule.Name,
+				Severity:    rule.Severity,
+				NodeID:      ref.NodeID,
+				Address:     parser.InstanceAddress(ref.Resource, ref.Instance),
+				Message:     fmt.Sprintf("%s %q does not match the naming convention %s", rule.Attribute, name, rule.Pattern),
+				Remediation: remediation,
+			})
+		}
+	}
+
+	report.Findings = identify(namingAnalyzerName, report.Findings)
+
+	return report
+}
+
+// namingAnalyzerName names the naming analyzer and its findings.
+const namingAnalyzerName = "naming"
+
+// NamingAnalyzer checks resource names against a naming policy.
+type NamingAnalyzer struct {
+	Policy *NamingPolicy
+}
+
+func (NamingAnalyzer) Name() string { return namingAnalyzerName }
+
+func (NamingAnalyzer) Description() string {
+	return "Resource names that break the naming policy"
+}
+
+func (a NamingAnalyzer) Analyze(ctx context.Context, _ *models.Graph, state *models.TerraformState) []models.Finding {
+	return checkNaming(ctx, state, a.Policy).Findings
+}
+
+func (rule NamingRule) applies(res models.ResourceState) bool {
+	if rule.ResourceType != "" {
+		if matched, _ := path.Match(rule.ResourceType, res.Type); !matched {
+			return false
+		}
+	}
+
+	return rule.Provider == "" || parser.ProviderSource(res.Provider) == rule.Provider
+}
+
+// nameAttr follows a dotted attribute path, such as tags.Name, to a string.
+func nameAttr(attrs map[string]any, attribute string) (string, bool) {
+	var value any = attrs
+
+	for key := range strings.SplitSeq(attribute, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		value = object[key]
+	}
+
+	name, ok := value.(string)
+
+	return name, ok
+}
@@ -0,0 +1,122 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestParseNamingPolicy(t *testing.T) {
+	policy, err := ParseNamingPolicy([]byte(`{"rules": [{"name": "lowercase", "provider": "google", "pattern": "^[a-z-]+$"}]}`))
+	require.NoError(t, err)
+
+	rule := policy.Rules[0]
+	assert.Equal(t, "registry.terraform.io/hashicorp/google", rule.Provider)
+	assert.Equal(t, "name", rule.Attribute)
+	assert.Equal(t, models.SeverityWarning, rule.Severity)
+
+	tests := []struct {
+		name   string
+		policy string
+		err    string
+	}{
+		{"invalid json", `{"rules": [`, "unexpected end"},
+		{"missing name", `{"rules": [{"pattern": "x"}]}`, "rule 1: missing name"},
+		{"missing pattern", `{"rules": [{"name": "a"}]}`, "rule a: missing pattern"},
+		{"invalid pattern", `{"rules": [{"name": "a", "pattern": "("}]}`, "rule a: invalid pattern"},
+		{"invalid type glob", `{"rules": [{"name": "a", "pattern": "x", "resource_type": "aws_["}]}`, "invalid resource_type pattern"},
+		{"unknown severity", `{"rules": [{"name": "a", "pattern": "x", "severity": "dire"}]}`, `unknown severity "dire"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseNamingPolicy([]byte(tt.policy))
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestCheckNaming(t *testing.T) {
+	state := &models.TerraformState{
+		Resources: []models.ResourceState{
+			{
+				Mode: "managed", Type: "aws_instance", Name: "web",
+				Provider: `provider["registry.terraform.io/hashicorp/aws"]`,
+				Instances: []models.ResourceInstance{
+					{IndexKey: float64(0), Attributes: map[string]any{"id": "i-1", "tags": map[string]any{"Name": "prod-web-0"}}},
+					{IndexKey: float64(1), Attributes: map[string]any{"id": "i-2", "tags": map[string]any{"Name": "Web 1"}}},
+				},
+			},
+			{
+				Mode: "managed", Type: "google_storage_bucket", Name: "assets",
+				Provider:  `provider["registry.terraform.io/hashicorp/google"]`,
+				Instances: []models.ResourceInstance{{Attributes: map[string]any{"name": "Assets"}}},
+			},
+			{
+				Mode: "data", Type: "aws_instance", Name: "legacy",
+				Provider:  `provider["registry.terraform.io/hashicorp/aws"]`,
+				Instances: []models.ResourceInstance{{Attributes: map[string]any{"tags": map[string]any{"Name": "Legacy"}}}},
+			},
+		},
+	}
+
+	policy, err := ParseNamingPolicy([]byte(`{"rules": [
+		{"name": "aws name tags", "resource_type": "aws_*", "attribute": "tags.Name", "pattern": "^(prod|dev)-[a-z0-9-]+$", "severity": "error"},
+		{"name": "gcp lowercase", "provider": "hashicorp/google", "pattern": "^[a-z0-9-]+$", "remediation": "use lowercase names"}
+	]}`))
+	require.NoError(t, err)
+
+	report := CheckNaming(state, policy)
+
+	for i, finding := range report.Findings {
+		assert.Len(t, finding.ID, 16)
+		report.Findings[i].ID = ""
+	}
+
+	assert.Equal(t, []models.Finding{
+		{
+			Analyzer:    "naming",
+			Check:       "aws name tags",
+			Code:        "TS551",
+			Severity:    "error",
+			NodeID:      "aws_instance.web[1]",
+			Address:     "aws_instance.web[1]",
+			Message:     `tags.Name "Web 1" does not match the naming convention ^(prod|dev)-[a-z0-9-]+$`,
+			Remediation: "rename it to match ^(prod|dev)-[a-z0-9-]+$",
+		},
+		{
+			Analyzer:    "naming",
+			Check:       "gcp lowercase",
+			Code:        "TS551",
+			Severity:    "warning",
+			NodeID:      "google_storage_bucket.assets",
+			Address:     "google_storage_bucket.assets",
+			Message:     `name "Assets" does not match the naming convention ^[a-z0-9-]+$`,
+			Remediation: "use lowercase names",
+		},
+	}, report.Findings)
+
+	assert.Empty(t, CheckNaming(state, nil).Findings)
+}
+
+func TestNamingAnalyzer(t *testing.T) {
+	policy, err := ParseNamingPolicy([]byte(`{"rules": [{"name": "lowercase", "pattern": "^[a-z]+$"}]}`))
+	require.NoError(t, err)
+
+	state := &models.TerraformState{
+		Resources: []models.ResourceState{{
+			Mode: "managed", Type: "aws_s3_bucket", Name: "logs",
+			Provider:  `provider["registry.terraform.io/hashicorp/aws"]`,
+			Instances: []models.ResourceInstance{{Attributes: map[string]any{"name": "Logs"}}},
+		}},
+	}
+
+	assert.Len(t, NamingAnalyzer{Policy: policy}.Analyze(context.Background(), nil, state), 1)
+	assert.Empty(t, NamingAnalyzer{}.Analyze(context.Background(), nil, state))
+}
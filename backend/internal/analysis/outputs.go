@@ -0,0 +1,170 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+// remoteStateType is the data source through which a configuration reads the
+// outputs of another state.
+const remoteStateType = "terraform_remote_state"
+
+// minOutputValue is the length below which output values are too common,
+// like booleans and counts, to tell which resource they come from.
+const minOutputValue = 4
+
+// Outputs builds the output graph of a state. An output is linked to every
+// resource instance with an attribute equal to its value, or to one of the
+// strings it holds. consumers are the latest states of other lineages, by
+// lineage: their terraform_remote_state data sources are linked to the
+// outputs they read, by name and value.
+func Outputs(state *models.TerraformState, consumers map[string]*models.TerraformState) *models.OutputGraph {
+	graph := &models.OutputGraph{Nodes: []models.OutputNode{}, Edges: []models.OutputEdge{}}
+
+	names := make([]string, 0, len(state.Outputs))
+	for name := range state.Outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	byValue := make(map[string][]string)
+	for _, name := range names {
+		output := state.Outputs[name]
+		graph.Nodes = append(graph.Nodes, models.OutputNode{
+			ID:        "output." + name,
+			Kind:      models.OutputNodeOutput,
+			Name:      name,
+			Sensitive: output.Sensitive,
+		})
+
+		for _, value := range outputStrings(output.Value) {
+			byValue[value] = append(byValue[value], name)
+		}
+	}
+
+	for _, ref := range parser.Instances(state) {
+		linked := make(map[string]bool)
+
+		walkStringPaths(ref.Instance.Attributes, "", func(path, value string) {
+			for _, name := range byValue[value] {
+				if linked[name] {
+					continue
+				}
+				linked[name] = true
+
+				graph.Edges = append(graph.Edges, models.OutputEdge{
+					Source:    "output." + name,
+					Target:    ref.NodeID,
+					Type:      models.OutputEdgeValue,
+					Attribute: path,
+				})
+			}
+		})
+
+		if len(linked) > 0 {
+			graph.Nodes = append(graph.Nodes, models.OutputNode{ID: ref.NodeID, Kind: models.OutputNodeResource, Name: ref.NodeID})
+		}
+	}
+
+	lineages := make([]string, 0, len(consumers))
+	for lineage := range consumers {
+		if lineage != state.Lineage {
+			lineages = append(lineages, lineage)
+		}
+	}
+	sort.Strings(lineages)
+
+	for _, lineage := range lineages {
+		for _, ref := range parser.Instances(consumers[lineage]) {
+			if ref.Resource.Mode != "data" || ref.Resource.Type != remoteStateType {
+				continue
+			}
+
+			read := remoteStateOutputs(ref.Instance.Attributes)
+			id := lineage + ":" + ref.NodeID
+			consumes := false
+
+			for _, name := range names {
+				value, ok := read[name]
+				if !ok || !reflect.DeepEqual(value, state.Outputs[name].Value) {
+					continue
+				}
+				consumes = true
+
+				graph.Edges = append(graph.Edges, models.OutputEdge{Source: id, Target: "output." + name, Type: models.OutputEdgeConsumer})
+			}
+
+			if consumes {
+				graph.Nodes = append(graph.Nodes, models.OutputNode{ID: id, Kind: models.OutputNodeRemoteState, Name: ref.NodeID, Lineage: lineage})
+			}
+		}
+	}
+
+	return graph
+}
+
+// outputStrings returns the strings an output value holds, long enough to be
+// told apart.
+func outputStrings(value any) []string {
+	var values []string
+
+	walkStrings(value, func(s string) {
+		if len(s) >= minOutputValue {
+			values = append(values, s)
+		}
+	})
+
+	return values
+}
+
+// walkStringPaths calls fn with the path and value of every string in v long
+// enough to be told apart, in the order of sorted keys.
+func walkStringPaths(v any, path string, fn func(path, value string)) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+
+	switch v := v.(type) {
+	case string:
+		if len(v) >= minOutputValue {
+			fn(path, v)
+		}
+	case []any:
+		for i, elem := range v {
+			walkStringPaths(elem, join(strconv.Itoa(i)), fn)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			walkStringPaths(v[key], join(key), fn)
+		}
+	}
+}
+
+// remoteStateOutputs returns the outputs a terraform_remote_state data source
+// read. The attribute is dynamically typed, which states record as its value
+// next to its type.
+func remoteStateOutputs(attrs map[string]any) map[string]any {
+	outputs, _ := attrs["outputs"].(map[string]any)
+
+	if value, ok := outputs["value"].(map[string]any); ok && len(outputs) == 2 && outputs["type"] != nil {
+		return value
+	}
+
+	return outputs
+}
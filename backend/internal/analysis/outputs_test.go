@@ -0,0 +1,98 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestOutputs(t *testing.T) {
+	aws := `provider["registry.terraform.io/hashicorp/aws"]`
+
+	network := &models.TerraformState{
+		Lineage: "network",
+		Outputs: map[string]models.Output{
+			"vpc_id":     {Value: "vpc-0a1b2c"},
+			"subnet_ids": {Value: []any{"subnet-1111", "subnet-2222"}},
+			"nat_count":  {Value: float64(2)},
+			"db_secret":  {Value: "hunter22", Sensitive: true},
+		},
+		Resources: []models.ResourceState{
+			{
+				Mode: "managed", Type: "aws_vpc", Name: "main", Provider: aws,
+				Instances: []models.ResourceInstance{{Attributes: map[string]any{"id": "vpc-0a1b2c", "count": float64(2)}}},
+			},
+			{
+				Mode: "managed", Type: "aws_subnet", Name: "private", Provider: aws,
+				Instances: []models.ResourceInstance{
+					{IndexKey: float64(0), Attributes: map[string]any{"id": "subnet-1111", "vpc_id": "vpc-0a1b2c"}},
+					{IndexKey: float64(1), Attributes: map[string]any{"id": "subnet-2222", "vpc_id": "vpc-0a1b2c"}},
+				},
+			},
+			{
+				Mode: "managed", Type: "aws_s3_bucket", Name: "logs", Provider: aws,
+				Instances: []models.ResourceInstance{{Attributes: map[string]any{"id": "logs"}}},
+			},
+		},
+	}
+
+	remoteState := func(outputs map[string]any) models.ResourceState {
+		return models.ResourceState{
+			Mode: "data", Type: "terraform_remote_state", Name: "network",
+			Provider:  `provider["terraform.io/builtin/terraform"]`,
+			Instances: []models.ResourceInstance{{Attributes: map[string]any{"backend": "s3", "outputs": outputs}}},
+		}
+	}
+
+	consumers := map[string]*models.TerraformState{
+		"network": network,
+		"app": {Lineage: "app", Resources: []models.ResourceState{
+			remoteState(map[string]any{
+				"value": map[string]any{"vpc_id": "vpc-0a1b2c", "subnet_ids": []any{"subnet-1111", "subnet-2222"}},
+				"type":  []any{"object", map[string]any{}},
+			}),
+		}},
+		"legacy": {Lineage: "legacy", Resources: []models.ResourceState{
+			remoteState(map[string]any{"vpc_id": "vpc-0a1b2c"}),
+		}},
+		"other": {Lineage: "other", Resources: []models.ResourceState{
+			remoteState(map[string]any{"vpc_id": "vpc-ffffff"}),
+		}},
+	}
+
+	graph := Outputs(network, consumers)
+
+	assert.Equal(t, []models.OutputNode{
+		{ID: "output.db_secret", Kind: "output", Name: "db_secret", Sensitive: true},
+		{ID: "output.nat_count", Kind: "output", Name: "nat_count"},
+		{ID: "output.subnet_ids", Kind: "output", Name: "subnet_ids"},
+		{ID: "output.vpc_id", Kind: "output", Name: "vpc_id"},
+		{ID: "aws_vpc.main", Kind: "resource", Name: "aws_vpc.main"},
+		{ID: "aws_subnet.private[0]", Kind: "resource", Name: "aws_subnet.private[0]"},
+		{ID: "aws_subnet.private[1]", Kind: "resource", Name: "aws_subnet.private[1]"},
+		{ID: "app:terraform_remote_state.network", Kind: "remote_state", Name: "terraform_remote_state.network", Lineage: "app"},
+		{ID: "legacy:terraform_remote_state.network", Kind: "remote_state", Name: "terraform_remote_state.network", Lineage: "legacy"},
+	}, graph.Nodes)
+
+	assert.Equal(t, []models.OutputEdge{
+		{Source: "output.vpc_id", Target: "aws_vpc.main", Type: "value", Attribute: "id"},
+		{Source: "output.subnet_ids", Target: "aws_subnet.private[0]", Type: "value", Attribute: "id"},
+		{Source: "output.vpc_id", Target: "aws_subnet.private[0]", Type: "value", Attribute: "vpc_id"},
+		{Source: "output.subnet_ids", Target: "aws_subnet.private[1]", Type: "value", Attribute: "id"},
+		{Source: "output.vpc_id", Target: "aws_subnet.private[1]", Type: "value", Attribute: "vpc_id"},
+		{Source: "app:terraform_remote_state.network", Target: "output.subnet_ids", Type: "consumer"},
+		{Source: "app:terraform_remote_state.network", Target: "output.vpc_id", Type: "consumer"},
+		{Source: "legacy:terraform_remote_state.network", Target: "output.vpc_id", Type: "consumer"},
+	}, graph.Edges)
+
+	t.Run("without outputs", func(t *testing.T) {
+		graph := Outputs(&models.TerraformState{Resources: network.Resources}, nil)
+		assert.Empty(t, graph.Nodes)
+		assert.NotNil(t, graph.Edges)
+	})
+}
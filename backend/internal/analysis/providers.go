@@ -0,0 +1,154 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/terrascope/core/internal/codes"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+	"github.com/terrascope/core/internal/registry"
+)
+
+const (
+	warningTypeMissingConstraint  = "missing_constraint"
+	warningTypeInvalidConstraint  = "invalid_constraint"
+	warningTypeConstraintMismatch = "constraint_mismatch"
+	warningTypeUnlockedProvider   = "unlocked_provider"
+	warningTypeOutdatedProvider   = "outdated_provider"
+	warningTypeRegistryError      = "registry_error"
+	warningTypeUnusedLock         = "unused_locked_provider"
+)
+
+// ProviderRegistry looks up the newest published version of a provider.
+type ProviderRegistry interface {
+	LatestProviderVersion(ctx context.Context, address string) (string, error)
+}
+
+// Providers audits the providers used by the state against the
+// required_providers constraints and the provider versions selected in the
+// dependency lock file, whose hashes it reports. The constraints recorded in
+// the lock file stand in for missing required_providers entries. It warns
+// about providers without a constraint, selected versions that violate their
+// constraints, providers used by the state but missing from the lock file and
+// the reverse, and, when a registry is supplied, providers a major version or
+// more behind the latest release.
+func Providers(ctx context.Context, state *models.TerraformState, required map[string]models.ProviderRequirement, lock *models.DependencyLock, reg ProviderRegistry) *models.ProviderReport {
+	providers := make(map[string]*models.ProviderInfo)
+	provider := func(address string) *models.ProviderInfo {
+		info, ok := providers[address]
+		if !ok {
+			info = &models.ProviderInfo{Address: address}
+			providers[address] = info
+		}
+		return info
+	}
+
+	inState := make(map[string]bool)
+	for _, res := range state.Resources {
+		if address := parser.ProviderSource(res.Provider); address != "" {
+			provider(address).Resources += len(res.Instances)
+			inState[address] = true
+		}
+	}
+
+	constrained := make(map[string]bool)
+	for localName, req := range required {
+		source := req.Source
+		if source == "" {
+			source = localName
+		}
+
+		info := provider(parser.NormalizeProviderSource(source))
+		info.LocalName = localName
+		info.Constraints = append(info.Constraints, req.VersionConstraints...)
+		constrained[info.Address] = true
+	}
+
+	if lock != nil {
+		for address, locked := range lock.Providers {
+			info := provider(parser.NormalizeProviderSource(address))
+			info.Version = locked.Version
+			info.Hashes = locked.Hashes
+
+			if !constrained[info.Address] && locked.Constraints != "" {
+				info.Constraints = append(info.Constraints, locked.Constraints)
+			}
+		}
+	}
+
+	addresses := make([]string, 0, len(providers))
+	for address := range providers {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	report := &models.ProviderReport{Providers: []models.ProviderInfo{}}
+	warn := func(warningType, address, message string) {
+		report.Warnings = append(report.Warnings, models.Warning{Code: codes.ForWarning(warningType), Type: warningType, Address: address, Message: message})
+	}
+
+	lookups := 0
+	for _, address := range addresses {
+		info := providers[address]
+
+		if inState[address] && required != nil && !constrained[address] {
+			warn(warningTypeMissingConstraint, address, fmt.Sprintf("%s is used by the state but has no required_providers entry", address))
+		}
+
+		if info.Version == "" && lock != nil {
+			warn(warningTypeUnlockedProvider, address, fmt.Sprintf("%s has no selected version in the lock file", address))
+		}
+
+		if info.Version != "" && !inState[address] && len(state.Resources) > 0 {
+			warn(warningTypeUnusedLock, address, fmt.Sprintf("%s is selected in the lock file but not used by the state", address))
+		}
+
+		if info.Version != "" {
+			for _, constraint := range info.Constraints {
+				ok, err := registry.MatchesConstraint(info.Version, constraint)
+				switch {
+				case err != nil:
+					warn(warningTypeInvalidConstraint, address, err.Error())
+				case !ok:
+					warn(warningTypeConstraintMismatch, address, fmt.Sprintf("%s %s does not satisfy %q", address, info.Version, constraint))
+				}
+			}
+		}
+
+		if reg != nil && info.Version != "" {
+			latest, err := "", errTooManyLookups
+			if lookups < maxRegistryLookups {
+				lookups++
+				latest, err = reg.LatestProviderVersion(ctx, address)
+			}
+			switch {
+			case errors.Is(err, registry.ErrNotRegistrySource):
+			case err != nil:
+				warn(warningTypeRegistryError, address, err.Error())
+			default:
+				info.LatestVersion = latest
+				if majorVersionsBehind(info.Version, latest) > 0 {
+					warn(warningTypeOutdatedProvider, address, fmt.Sprintf("%s %s is a major version or more behind the latest release %s", address, info.Version, latest))
+				}
+			}
+		}
+
+		report.Providers = append(report.Providers, *info)
+	}
+
+	return report
+}
+
+func majorVersionsBehind(version, latest string) int {
+	if registry.CompareVersions(version, latest) >= 0 {
+		return 0
+	}
+
+	return registry.MajorVersion(latest) - registry.MajorVersion(version)
+}
@@ -0,0 +1,201 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/registry"
+)
+
+type fakeProviderRegistry map[string]string
+
+func (f fakeProviderRegistry) LatestProviderVersion(_ context.Context, address string) (string, error) {
+	if _, ok := registry.ProviderAddress(address); !ok {
+		return "", registry.ErrNotRegistrySource
+	}
+
+	version, ok := f[address]
+	if !ok {
+		return "", errors.New("registry returned 404 Not Found")
+	}
+
+	return version, nil
+}
+
+func providerState() *models.TerraformState {
+	random := managedResource("random_id", "suffix", map[string]any{"id": "abc"})
+	random.Provider = `provider["registry.terraform.io/hashicorp/random"]`
+	custom := managedResource("acme_thing", "main", map[string]any{"id": "thing"})
+	custom.Provider = `provider["terraform.example.com/acme/acme"]`
+	west := managedResource("aws_vpc", "west", map[string]any{"id": "vpc-2"})
+	west.Provider = `provider["registry.terraform.io/hashicorp/aws"].west`
+
+	return &models.TerraformState{
+		Resources: []models.ResourceState{
+			managedResource("aws_vpc", "main", map[string]any{"id": "vpc-1"}),
+			west,
+			random,
+			custom,
+		},
+	}
+}
+
+func findWarning(warnings []models.Warning, warningType, address string) (models.Warning, bool) {
+	for _, warning := range warnings {
+		if warning.Type == warningType && warning.Address == address {
+			return warning, true
+		}
+	}
+
+	return models.Warning{}, false
+}
+
+// lockVersions returns a dependency lock selecting the given versions.
+func lockVersions(versions map[string]string) *models.DependencyLock {
+	lock := &models.DependencyLock{Providers: make(map[string]models.LockedProvider)}
+	for address, version := range versions {
+		lock.Providers[address] = models.LockedProvider{Version: version}
+	}
+
+	return lock
+}
+
+func TestProviders(t *testing.T) {
+	const (
+		aws    = "registry.terraform.io/hashicorp/aws"
+		random = "registry.terraform.io/hashicorp/random"
+		acme   = "terraform.example.com/acme/acme"
+	)
+
+	t.Run("lists providers used by the state", func(t *testing.T) {
+		report := Providers(context.Background(), providerState(), nil, nil, nil)
+
+		require.Len(t, report.Providers, 3)
+		assert.Equal(t, aws, report.Providers[0].Address)
+		assert.Equal(t, 2, report.Providers[0].Resources)
+		assert.Equal(t, random, report.Providers[1].Address)
+		assert.Equal(t, acme, report.Providers[2].Address)
+		assert.Empty(t, report.Warnings)
+	})
+
+	t.Run("reports constraint mismatches and missing constraints", func(t *testing.T) {
+		required := map[string]models.ProviderRequirement{
+			"aws":  {Source: "hashicorp/aws", VersionConstraints: []string{"~> 4.0"}},
+			"acme": {Source: "terraform.example.com/acme/acme", VersionConstraints: []string{">= 1.0"}},
+		}
+		versions := map[string]string{
+			aws:    "5.31.0",
+			random: "3.6.0",
+			acme:   "1.2.0",
+		}
+
+		report := Providers(context.Background(), providerState(), required, lockVersions(versions), nil)
+
+		assert.Equal(t, "aws", report.Providers[0].LocalName)
+		assert.Equal(t, []string{"~> 4.0"}, report.Providers[0].Constraints)
+		assert.Equal(t, "5.31.0", report.Providers[0].Version)
+
+		mismatch, ok := findWarning(report.Warnings, "constraint_mismatch", aws)
+		require.True(t, ok)
+		assert.Contains(t, mismatch.Message, "~> 4.0")
+
+		_, ok = findWarning(report.Warnings, "missing_constraint", random)
+		assert.True(t, ok)
+		_, ok = findWarning(report.Warnings, "constraint_mismatch", acme)
+		assert.False(t, ok)
+	})
+
+	t.Run("infers the source from the local name", func(t *testing.T) {
+		required := map[string]models.ProviderRequirement{"random": {VersionConstraints: []string{">= 3.0"}}}
+
+		report := Providers(context.Background(), providerState(), required, nil, nil)
+
+		_, ok := findWarning(report.Warnings, "missing_constraint", random)
+		assert.False(t, ok)
+		_, ok = findWarning(report.Warnings, "missing_constraint", aws)
+		assert.True(t, ok)
+	})
+
+	t.Run("warns about providers without a selected version", func(t *testing.T) {
+		report := Providers(context.Background(), providerState(), nil, lockVersions(map[string]string{aws: "5.31.0"}), nil)
+
+		_, ok := findWarning(report.Warnings, "unlocked_provider", random)
+		assert.True(t, ok)
+		_, ok = findWarning(report.Warnings, "unlocked_provider", aws)
+		assert.False(t, ok)
+	})
+
+	t.Run("reports the lock file hashes and constraints", func(t *testing.T) {
+		lock := &models.DependencyLock{Providers: map[string]models.LockedProvider{
+			aws:    {Version: "5.31.0", Constraints: ">= 4.0.0, < 6.0.0", Hashes: []string{"h1:abc="}},
+			random: {Version: "3.6.0", Constraints: "~> 2.0"},
+		}}
+		required := map[string]models.ProviderRequirement{"random": {VersionConstraints: []string{">= 3.0"}}}
+
+		report := Providers(context.Background(), providerState(), required, lock, nil)
+
+		assert.Equal(t, []string{">= 4.0.0, < 6.0.0"}, report.Providers[0].Constraints)
+		assert.Equal(t, []string{"h1:abc="}, report.Providers[0].Hashes)
+		assert.Equal(t, []string{">= 3.0"}, report.Providers[1].Constraints, "required_providers take precedence")
+
+		_, ok := findWarning(report.Warnings, "constraint_mismatch", aws)
+		assert.False(t, ok)
+		_, ok = findWarning(report.Warnings, "unlocked_provider", acme)
+		assert.True(t, ok)
+	})
+
+	t.Run("warns about locked providers the state does not use", func(t *testing.T) {
+		const google = "registry.terraform.io/hashicorp/google"
+
+		report := Providers(context.Background(), providerState(), nil, lockVersions(map[string]string{aws: "5.31.0", google: "5.10.0"}), nil)
+
+		require.Len(t, report.Providers, 4)
+		warning, ok := findWarning(report.Warnings, "unused_locked_provider", google)
+		require.True(t, ok)
+		assert.Equal(t, google+" is selected in the lock file but not used by the state", warning.Message)
+		_, ok = findWarning(report.Warnings, "unused_locked_provider", aws)
+		assert.False(t, ok)
+	})
+
+	t.Run("reports invalid constraints", func(t *testing.T) {
+		required := map[string]models.ProviderRequirement{"aws": {VersionConstraints: []string{">= latest"}}}
+
+		report := Providers(context.Background(), providerState(), required, lockVersions(map[string]string{aws: "5.31.0"}), nil)
+
+		_, ok := findWarning(report.Warnings, "invalid_constraint", aws)
+		assert.True(t, ok)
+	})
+
+	t.Run("flags providers a major version behind", func(t *testing.T) {
+		versions := map[string]string{aws: "4.67.0", random: "3.5.0", acme: "1.2.0"}
+		reg := fakeProviderRegistry{aws: "5.31.0"}
+
+		report := Providers(context.Background(), providerState(), nil, lockVersions(versions), reg)
+
+		assert.Equal(t, "5.31.0", report.Providers[0].LatestVersion)
+		_, ok := findWarning(report.Warnings, "outdated_provider", aws)
+		assert.True(t, ok)
+
+		_, ok = findWarning(report.Warnings, "registry_error", random)
+		assert.True(t, ok)
+
+		_, ok = findWarning(report.Warnings, "registry_error", acme)
+		assert.False(t, ok)
+	})
+
+	t.Run("minor releases behind are not outdated", func(t *testing.T) {
+		reg := fakeProviderRegistry{aws: "5.31.0"}
+
+		report := Providers(context.Background(), providerState(), nil, lockVersions(map[string]string{aws: "5.1.0"}), reg)
+
+		_, ok := findWarning(report.Warnings, "outdated_provider", aws)
+		assert.False(t, ok)
+	})
+}
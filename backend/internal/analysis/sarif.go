@@ -0,0 +1,99 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"github.com/terrascope/core/internal/models"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// sarifFingerprint keys the finding ID among the partial fingerprints of a
+// result, by which code scanning tells new alerts from known ones.
+const sarifFingerprint = "terrascope/v1"
+
+// SARIF reports findings as a SARIF 2.1.0 log with a single run, located in
+// the state file at uri. Each check is a rule, identified as
+// <analyzer>/<check>, whose default level is the level of its first finding.
+func SARIF(findings []models.Finding, uri string) *models.SarifLog {
+	run := models.SarifRun{
+		Tool:    models.SarifTool{Driver: models.SarifDriver{Name: "terrascope", Rules: []models.SarifRule{}}},
+		Results: []models.SarifResult{},
+	}
+
+	rules := make(map[string]int)
+
+	for _, finding := range findings {
+		ruleID := finding.Analyzer + "/" + finding.Check
+		level := sarifLevel(finding.Severity)
+
+		index, ok := rules[ruleID]
+		if !ok {
+			rule := models.SarifRule{
+				ID:                   ruleID,
+				Name:                 finding.Check,
+				ShortDescription:     models.SarifMessage{Text: finding.Check},
+				DefaultConfiguration: models.SarifConfiguration{Level: level},
+			}
+			if finding.Remediation != "" {
+				rule.Help = &models.SarifMessage{Text: finding.Remediation}
+			}
+
+			index = len(run.Tool.Driver.Rules)
+			rules[ruleID] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+
+		address := finding.Address
+		if address == "" {
+			address = finding.NodeID
+		}
+
+		message := finding.Message
+		if finding.Remediation != "" {
+			message += ": " + finding.Remediation
+		}
+
+		run.Results = append(run.Results, models.SarifResult{
+			RuleID:    ruleID,
+			RuleIndex: index,
+			Level:     level,
+			Message:   models.SarifMessage{Text: address + ": " + message},
+			Locations: []models.SarifLocation{{
+				PhysicalLocation: models.SarifPhysicalLocation{
+					ArtifactLocation: models.SarifArtifactLocation{URI: uri},
+				},
+				LogicalLocations: []models.SarifLogicalLocation{{
+					Name:               address,
+					FullyQualifiedName: finding.NodeID,
+					Kind:               "resource",
+				}},
+			}},
+			PartialFingerprints: map[string]string{sarifFingerprint: finding.ID},
+			Properties:          map[string]any{"severity": finding.Severity},
+		})
+	}
+
+	return &models.SarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []models.SarifRun{run},
+	}
+}
+
+// sarifLevel maps a finding severity to a SARIF level, which has no
+// critical level above error.
+func sarifLevel(severity string) string {
+	switch severity {
+	case models.SeverityCritical, models.SeverityError:
+		return "error"
+	case models.SeverityWarning:
+		return "warning"
+	}
+
+	return "note"
+}
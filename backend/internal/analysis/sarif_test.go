@@ -0,0 +1,93 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestSARIF(t *testing.T) {
+	findings := []models.Finding{
+		{
+			ID: "0123456789abcdef", Analyzer: "security", Check: "open_ingress", Severity: models.SeverityCritical,
+			NodeID: "aws_security_group.web", Address: "aws_security_group.web",
+			Message: "ingress on port 22 is open to the internet", Remediation: "restrict the CIDR blocks",
+		},
+		{
+			ID: "fedcba9876543210", Analyzer: "security", Check: "open_ingress", Severity: models.SeverityWarning,
+			NodeID: "aws_security_group.api", Address: "aws_security_group.api",
+			Message: "ingress on port 8080 is open to the internet",
+		},
+		{
+			ID: "00112233aabbccdd", Analyzer: "tags", Check: "untagged", Severity: models.SeverityInfo,
+			NodeID:  "aws_s3_bucket.logs",
+			Message: "resource has no tags",
+		},
+	}
+
+	log := SARIF(findings, "terraform.tfstate")
+
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+
+	run := log.Runs[0]
+	assert.Equal(t, "terrascope", run.Tool.Driver.Name)
+
+	t.Run("describes each check once", func(t *testing.T) {
+		require.Len(t, run.Tool.Driver.Rules, 2)
+
+		assert.Equal(t, "security/open_ingress", run.Tool.Driver.Rules[0].ID)
+		assert.Equal(t, "error", run.Tool.Driver.Rules[0].DefaultConfiguration.Level)
+		assert.Equal(t, &models.SarifMessage{Text: "restrict the CIDR blocks"}, run.Tool.Driver.Rules[0].Help)
+
+		assert.Equal(t, "tags/untagged", run.Tool.Driver.Rules[1].ID)
+		assert.Nil(t, run.Tool.Driver.Rules[1].Help)
+	})
+
+	t.Run("reports each finding", func(t *testing.T) {
+		require.Len(t, run.Results, 3)
+
+		type result struct {
+			rule  string
+			index int
+			level string
+		}
+
+		var results []result
+		for _, r := range run.Results {
+			results = append(results, result{r.RuleID, r.RuleIndex, r.Level})
+		}
+
+		assert.Equal(t, []result{
+			{"security/open_ingress", 0, "error"},
+			{"security/open_ingress", 0, "warning"},
+			{"tags/untagged", 1, "note"},
+		}, results)
+
+		first := run.Results[0]
+		assert.Equal(t, "aws_security_group.web: ingress on port 22 is open to the internet: restrict the CIDR blocks", first.Message.Text)
+		assert.Equal(t, map[string]string{"terrascope/v1": "0123456789abcdef"}, first.PartialFingerprints)
+		assert.Equal(t, "terraform.tfstate", first.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+		assert.Equal(t, "aws_security_group.web", first.Locations[0].LogicalLocations[0].FullyQualifiedName)
+
+		assert.Equal(t, "aws_s3_bucket.logs", run.Results[2].Locations[0].LogicalLocations[0].Name, "falls back to the node ID")
+	})
+
+	t.Run("encodes as a SARIF log", func(t *testing.T) {
+		data, err := json.Marshal(SARIF(nil, "terraform.tfstate"))
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{
+			"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+			"version": "2.1.0",
+			"runs": [{"tool": {"driver": {"name": "terrascope", "rules": []}}, "results": []}]
+		}`, string(data))
+	})
+}
@@ -0,0 +1,165 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"context"
+	"math"
+	"regexp"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+func init() {
+	Register(secretsAnalyzer{})
+}
+
+// secretPattern is a kind of secret recognized by its format, wherever it is
+// stored. The preview of a match shows the group of the pattern masked, or,
+// for patterns without one, the match masked unless it is public, like the
+// header of a private key.
+type secretPattern struct {
+	check    string
+	severity string
+	pattern  *regexp.Regexp
+	message  string
+	public   bool
+}
+
+var secretPatterns = []secretPattern{
+	{
+		check:    "aws_access_key",
+		severity: models.SeverityError,
+		pattern:  regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+		message:  "holds an AWS access key ID",
+	},
+	{
+		check:    "private_key",
+		severity: models.SeverityCritical,
+		pattern:  regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )*PRIVATE KEY-----`),
+		message:  "holds a private key",
+		public:   true,
+	},
+	{
+		check:    "token",
+		severity: models.SeverityCritical,
+		pattern:  regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36}|xox[abprs]-[A-Za-z0-9-]{10,}|sk_live_[A-Za-z0-9]{24,})\b`),
+		message:  "holds an API token",
+	},
+	{
+		check:    "connection_string",
+		severity: models.SeverityError,
+		pattern:  regexp.MustCompile(`\b[a-z][a-z0-9+.-]*://[^:@/\s]+:([^@/\s]+)@`),
+		message:  "holds a connection string with a password",
+	},
+}
+
+// secretAttribute matches the names of attributes meant to hold secrets,
+// whose values are checked for entropy instead of format.
+var secretAttribute = regexp.MustCompile(`(?i)(password|passwd|secret|token|api_?key|private_key|credentials)`)
+
+// Values of secret attributes are reported when they are at least
+// minSecretLength long and random enough, in bits per character, to be
+// generated rather than placeholders such as "changeme".
+const (
+	minSecretLength  = 16
+	minSecretEntropy = 3.5
+)
+
+// secretsAnalyzer reports the attribute values of the instances that look
+// like plaintext secrets, whether by their format, such as AWS access keys,
+// private key blocks and connection strings with a password, or by their
+// entropy in attributes named like secrets. Findings only show a redacted
+// preview of the value.
+type secretsAnalyzer struct{}
+
+func (secretsAnalyzer) Name() string { return "secrets" }
+
+func (secretsAnalyzer) Description() string {
+	return "Attribute values that look like plaintext secrets"
+}
+
+func (secretsAnalyzer) Analyze(ctx context.Context, _ *models.Graph, state *models.TerraformState) []models.Finding {
+	findings := []models.Finding{}
+
+	for _, ref := range parser.Instances(state) {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		address := parser.InstanceAddress(ref.Resource, ref.Instance)
+
+		walkStringPaths(ref.Instance.Attributes, "", func(path, value string) {
+			finding := models.Finding{NodeID: ref.NodeID, Address: address}
+
+			if check, ok := matchSecret(value); ok {
+				finding.Check = check.check
+				finding.Severity = check.severity
+				finding.Message = path + " " + check.message + ": " + redactSecret(check, value)
+			} else if secretAttribute.MatchString(path) && len(value) >= minSecretLength && entropy(value) >= minSecretEntropy {
+				finding.Check = "high_entropy"
+				finding.Severity = models.SeverityError
+				finding.Message = path + " holds a value that looks like a secret: " + redact(value)
+			} else {
+				return
+			}
+
+			finding.Remediation = "move the secret to a secret manager and rotate it, since anyone who can read the state can read it"
+			findings = append(findings, finding)
+		})
+	}
+
+	return findings
+}
+
+func matchSecret(value string) (secretPattern, bool) {
+	for _, check := range secretPatterns {
+		if check.pattern.MatchString(value) {
+			return check, true
+		}
+	}
+
+	return secretPattern{}, false
+}
+
+// redactSecret previews the secret a pattern found in value.
+func redactSecret(check secretPattern, value string) string {
+	match := check.pattern.FindStringSubmatchIndex(value)
+
+	switch {
+	case len(match) >= 4 && match[2] >= 0:
+		return value[match[0]:match[2]] + "****" + value[match[3]:match[1]]
+	case check.public:
+		return value[match[0]:match[1]]
+	}
+
+	return redact(value[match[0]:match[1]])
+}
+
+// redact keeps the first characters of a secret, a quarter of it at most and
+// never more than four, and masks the rest.
+func redact(secret string) string {
+	keep := min(len(secret)/4, 4)
+
+	return secret[:keep] + "****"
+}
+
+// entropy returns the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+
+	var bits float64
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		bits -= p * math.Log2(p)
+	}
+
+	return bits
+}
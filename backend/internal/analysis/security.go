@@ -0,0 +1,188 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+func init() {
+	Register(securityAnalyzer{})
+}
+
+// securityAnalyzer reports common AWS misconfigurations: ingress open to the
+// internet, public buckets and databases, and unencrypted storage.
+type securityAnalyzer struct{}
+
+func (securityAnalyzer) Name() string { return "security" }
+
+func (securityAnalyzer) Description() string {
+	return "Ingress open to the internet, public buckets and databases, and unencrypted storage"
+}
+
+// adminPorts are the remote administration ports that should never be open
+// to the internet.
+var adminPorts = []int{22, 3389}
+
+var publicACLs = map[string]bool{
+	"public-read":       true,
+	"public-read-write": true,
+}
+
+var encryptionAttributes = map[string]string{
+	"aws_db_instance": "storage_encrypted",
+	"aws_rds_cluster": "storage_encrypted",
+	"aws_ebs_volume":  "encrypted",
+}
+
+func (securityAnalyzer) Analyze(ctx context.Context, _ *models.Graph, state *models.TerraformState) []models.Finding {
+	findings := []models.Finding{}
+
+	for _, ref := range managedInstances(state) {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		attrs := ref.Instance.Attributes
+
+		finding := func(check, severity, message, remediation string) {
+			findings = append(findings, models.Finding{
+				Check:       check,
+				Severity:    severity,
+				NodeID:      ref.NodeID,
+				Address:     parser.InstanceAddress(ref.Resource, ref.Instance),
+				Message:     message,
+				Remediation: remediation,
+			})
+		}
+
+		for _, rule := range ingressRules(ref.Resource.Type, attrs) {
+			if !openToInternet(rule) {
+				continue
+			}
+
+			severity := models.SeverityWarning
+			if rule.allPorts() || slices.ContainsFunc(adminPorts, rule.covers) {
+				severity = models.SeverityCritical
+			}
+
+			finding("open_ingress", severity,
+				fmt.Sprintf("ingress on %s is open to the internet", rule.ports()),
+				"restrict the rule to known CIDR blocks or security groups")
+		}
+
+		switch ref.Resource.Type {
+		case "aws_s3_bucket", "aws_s3_bucket_acl":
+			if acl := stringAttr(attrs, "acl"); publicACLs[acl] {
+				finding("public_bucket", models.SeverityError,
+					fmt.Sprintf("the bucket ACL %s grants public access", acl),
+					"use a private ACL and a bucket policy for the principals that need access")
+			}
+		case "aws_db_instance", "aws_rds_cluster_instance":
+			if boolAttr(attrs, "publicly_accessible") {
+				finding("public_database", models.SeverityError,
+					"the database is publicly accessible",
+					"set publicly_accessible to false and reach it from within the VPC")
+			}
+		}
+
+		if attribute, ok := encryptionAttributes[ref.Resource.Type]; ok {
+			if encrypted, known := attrs[attribute].(bool); known && !encrypted {
+				finding("unencrypted_storage", models.SeverityWarning,
+					"the storage is not encrypted at rest",
+					fmt.Sprintf("set %s to true; existing storage must be recreated", attribute))
+			}
+		}
+	}
+
+	return findings
+}
+
+// ingressRule is an ingress rule of a security group, whichever resource
+// declares it.
+type ingressRule struct {
+	protocol string
+	fromPort int
+	toPort   int
+	cidrs    []string
+}
+
+// ingressRules returns the ingress rules declared by security groups, their
+// standalone rules and the VPC ingress rules.
+func ingressRules(resourceType string, attrs map[string]any) []ingressRule {
+	switch resourceType {
+	case "aws_security_group":
+		var rules []ingressRule
+		for _, block := range objectList(attrs["ingress"]) {
+			rules = append(rules, ingressRule{
+				protocol: stringAttr(block, "protocol"),
+				fromPort: portAttr(block, "from_port"),
+				toPort:   portAttr(block, "to_port"),
+				cidrs:    append(stringList(block["cidr_blocks"]), stringList(block["ipv6_cidr_blocks"])...),
+			})
+		}
+		return rules
+	case "aws_security_group_rule":
+		if stringAttr(attrs, "type") != "ingress" {
+			return nil
+		}
+		return []ingressRule{{
+			protocol: stringAttr(attrs, "protocol"),
+			fromPort: portAttr(attrs, "from_port"),
+			toPort:   portAttr(attrs, "to_port"),
+			cidrs:    append(stringList(attrs["cidr_blocks"]), stringList(attrs["ipv6_cidr_blocks"])...),
+		}}
+	case "aws_vpc_security_group_ingress_rule":
+		var cidrs []string
+		for _, key := range []string{"cidr_ipv4", "cidr_ipv6"} {
+			if cidr := stringAttr(attrs, key); cidr != "" {
+				cidrs = append(cidrs, cidr)
+			}
+		}
+		return []ingressRule{{
+			protocol: stringAttr(attrs, "ip_protocol"),
+			fromPort: portAttr(attrs, "from_port"),
+			toPort:   portAttr(attrs, "to_port"),
+			cidrs:    cidrs,
+		}}
+	}
+
+	return nil
+}
+
+func portAttr(attrs map[string]any, key string) int {
+	port, _ := attrs[key].(float64)
+
+	return int(port)
+}
+
+func openToInternet(rule ingressRule) bool {
+	return slices.Contains(rule.cidrs, "0.0.0.0/0") || slices.Contains(rule.cidrs, "::/0")
+}
+
+// allPorts reports whether the rule allows all traffic, as protocol -1 does
+// whatever its ports.
+func (rule ingressRule) allPorts() bool {
+	return rule.protocol == "-1" || rule.protocol == "all" || rule.fromPort == 0 && rule.toPort == 65535
+}
+
+func (rule ingressRule) covers(port int) bool {
+	return rule.fromPort <= port && port <= rule.toPort
+}
+
+func (rule ingressRule) ports() string {
+	switch {
+	case rule.allPorts():
+		return "all ports"
+	case rule.fromPort == rule.toPort:
+		return fmt.Sprintf("port %d", rule.fromPort)
+	}
+
+	return fmt.Sprintf("ports %d-%d", rule.fromPort, rule.toPort)
+}
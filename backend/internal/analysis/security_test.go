@@ -0,0 +1,60 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestSecurityAnalyzer(t *testing.T) {
+	resource := func(resourceType, name string, attrs map[string]any) models.ResourceState {
+		return models.ResourceState{
+			Mode: "managed", Type: resourceType, Name: name,
+			Provider:  `provider["registry.terraform.io/hashicorp/aws"]`,
+			Instances: []models.ResourceInstance{{Attributes: attrs}},
+		}
+	}
+
+	state := &models.TerraformState{
+		Resources: []models.ResourceState{
+			resource("aws_security_group", "web", map[string]any{"id": "sg-1", "ingress": []any{
+				map[string]any{"protocol": "tcp", "from_port": 443.0, "to_port": 443.0, "cidr_blocks": []any{"0.0.0.0/0"}},
+				map[string]any{"protocol": "tcp", "from_port": 22.0, "to_port": 22.0, "cidr_blocks": []any{"10.0.0.0/8"}},
+			}}),
+			resource("aws_security_group_rule", "ssh", map[string]any{
+				"id": "sgr-1", "type": "ingress", "protocol": "tcp", "from_port": 20.0, "to_port": 25.0, "ipv6_cidr_blocks": []any{"::/0"},
+			}),
+			resource("aws_security_group_rule", "egress", map[string]any{
+				"id": "sgr-2", "type": "egress", "protocol": "-1", "cidr_blocks": []any{"0.0.0.0/0"},
+			}),
+			resource("aws_vpc_security_group_ingress_rule", "all", map[string]any{
+				"id": "sgr-3", "ip_protocol": "-1", "cidr_ipv4": "0.0.0.0/0",
+			}),
+			resource("aws_s3_bucket", "site", map[string]any{"id": "site", "acl": "public-read"}),
+			resource("aws_s3_bucket", "logs", map[string]any{"id": "logs", "acl": "private"}),
+			resource("aws_db_instance", "main", map[string]any{"id": "db", "publicly_accessible": true, "storage_encrypted": false}),
+			resource("aws_ebs_volume", "data", map[string]any{"id": "vol", "encrypted": true}),
+		},
+	}
+
+	type result struct{ check, severity, address, message string }
+
+	var results []result
+	for _, f := range (securityAnalyzer{}).Analyze(context.Background(), nil, state) {
+		results = append(results, result{f.Check, f.Severity, f.Address, f.Message})
+	}
+
+	assert.Equal(t, []result{
+		{"open_ingress", models.SeverityWarning, "aws_security_group.web", "ingress on port 443 is open to the internet"},
+		{"open_ingress", models.SeverityCritical, "aws_security_group_rule.ssh", "ingress on ports 20-25 is open to the internet"},
+		{"open_ingress", models.SeverityCritical, "aws_vpc_security_group_ingress_rule.all", "ingress on all ports is open to the internet"},
+		{"public_bucket", models.SeverityError, "aws_s3_bucket.site", "the bucket ACL public-read grants public access"},
+		{"public_database", models.SeverityError, "aws_db_instance.main", "the database is publicly accessible"},
+		{"unencrypted_storage", models.SeverityWarning, "aws_db_instance.main", "the storage is not encrypted at rest"},
+	}, results)
+}
@@ -0,0 +1,53 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// severityRanks orders the finding severities from the least to the most
+// severe.
+var severityRanks = map[string]int{
+	models.SeverityInfo:     0,
+	models.SeverityWarning:  1,
+	models.SeverityError:    2,
+	models.SeverityCritical: 3,
+}
+
+// severityAliases are the names CI tools commonly give the same levels.
+var severityAliases = map[string]string{
+	"low":    models.SeverityInfo,
+	"medium": models.SeverityWarning,
+	"high":   models.SeverityError,
+}
+
+// ParseSeverity returns the severity named by name, case-insensitively,
+// which can also be one of low, medium and high.
+func ParseSeverity(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := severityAliases[name]; ok {
+		return alias, nil
+	}
+
+	if _, ok := severityRanks[name]; !ok {
+		return "", fmt.Errorf("unknown severity %q (supported: info, warning, error, critical)", name)
+	}
+
+	return name, nil
+}
+
+// AtLeast reports whether severity is at or above threshold. Unknown
+// severities rank below every known one.
+func AtLeast(severity, threshold string) bool {
+	rank, ok := severityRanks[severity]
+	if !ok {
+		return false
+	}
+
+	return rank >= severityRanks[threshold]
+}
@@ -0,0 +1,40 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestParseSeverity(t *testing.T) {
+	for name, expected := range map[string]string{
+		"info":     models.SeverityInfo,
+		"Warning":  models.SeverityWarning,
+		" error ":  models.SeverityError,
+		"CRITICAL": models.SeverityCritical,
+		"low":      models.SeverityInfo,
+		"medium":   models.SeverityWarning,
+		"high":     models.SeverityError,
+	} {
+		severity, err := ParseSeverity(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, severity, name)
+	}
+
+	_, err := ParseSeverity("severe")
+	assert.EqualError(t, err, `unknown severity "severe" (supported: info, warning, error, critical)`)
+}
+
+func TestAtLeast(t *testing.T) {
+	assert.True(t, AtLeast(models.SeverityCritical, models.SeverityError))
+	assert.True(t, AtLeast(models.SeverityError, models.SeverityError))
+	assert.False(t, AtLeast(models.SeverityWarning, models.SeverityError))
+	assert.True(t, AtLeast(models.SeverityInfo, models.SeverityInfo))
+	assert.False(t, AtLeast("unknown", models.SeverityInfo))
+}
@@ -0,0 +1,272 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+// SplitSuggestions suggests how a monolithic state could be split into
+// independent states. Weakly connected components of the dependency graph
+// share no dependency and are split apart first. Components of at least
+// twice minSize nodes are then partitioned at the dependencies that alone
+// connect two parts of them, keeping parts of at least minSize nodes.
+// Components smaller than minSize are grouped into a single split.
+func SplitSuggestions(state *models.TerraformState, minSize int) *models.SplitReport {
+	graph := parser.BuildGraphWithOptions(state, parser.GraphOptions{InstanceEdges: true})
+
+	nodes := make(map[string]models.Node, len(graph.Nodes))
+	ids := make([]string, 0, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		nodes[node.ID] = node
+		ids = append(ids, node.ID)
+	}
+	sort.Strings(ids)
+
+	neighbours := make(map[string]map[string]int)
+	for _, edge := range graph.Edges {
+		if _, ok := nodes[edge.Target]; !ok || edge.Source == edge.Target {
+			continue
+		}
+		link(neighbours, edge.Source, edge.Target)
+		link(neighbours, edge.Target, edge.Source)
+	}
+
+	components := connectedComponents(ids, neighbours)
+	report := &models.SplitReport{
+		Components:        len(components),
+		Splits:            []models.StateSplit{},
+		CrossDependencies: []models.CrossDependency{},
+	}
+
+	var groups, small [][]string
+	for _, component := range components {
+		switch {
+		case len(component) < minSize:
+			small = append(small, component)
+		case len(component) < 2*minSize:
+			groups = append(groups, component)
+		default:
+			groups = append(groups, partition(component, neighbours, minSize)...)
+		}
+	}
+
+	if len(small) > 0 {
+		var rest []string
+		for _, component := range small {
+			rest = append(rest, component...)
+		}
+		sort.Strings(rest)
+		groups = append(groups, rest)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if len(groups[i]) != len(groups[j]) {
+			return len(groups[i]) > len(groups[j])
+		}
+		return groups[i][0] < groups[j][0]
+	})
+
+	splits := make(map[string]string, len(ids))
+	for i, group := range groups {
+		for _, id := range group {
+			splits[id] = fmt.Sprintf("split-%d", i+1)
+		}
+	}
+
+	dependsOn := make(map[string]map[string]bool)
+	for _, edge := range graph.Edges {
+		from, to := splits[edge.Source], splits[edge.Target]
+		if to == "" || from == to {
+			continue
+		}
+
+		report.CrossDependencies = append(report.CrossDependencies, models.CrossDependency{
+			Source: edge.Source, Target: edge.Target, From: from, To: to,
+		})
+
+		if dependsOn[from] == nil {
+			dependsOn[from] = make(map[string]bool)
+		}
+		dependsOn[from][to] = true
+	}
+
+	sort.Slice(report.CrossDependencies, func(i, j int) bool {
+		a, b := report.CrossDependencies[i], report.CrossDependencies[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		return a.Target < b.Target
+	})
+
+	for i, group := range groups {
+		split := models.StateSplit{Cluster: describeCluster(group, nodes, neighbours, splits), DependsOn: []string{}}
+		split.ID = fmt.Sprintf("split-%d", i+1)
+
+		for to := range dependsOn[split.ID] {
+			split.DependsOn = append(split.DependsOn, to)
+		}
+		sort.Strings(split.DependsOn)
+
+		report.Splits = append(report.Splits, split)
+	}
+
+	return report
+}
+
+// connectedComponents returns the connected components of the undirected
+// graph, each sorted, in the order of their smallest node.
+func connectedComponents(ids []string, neighbours map[string]map[string]int) [][]string {
+	seen := make(map[string]bool, len(ids))
+	var components [][]string
+
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+
+		seen[id] = true
+		component := []string{}
+		queue := []string{id}
+
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			component = append(component, current)
+
+			for neighbour := range neighbours[current] {
+				if !seen[neighbour] {
+					seen[neighbour] = true
+					queue = append(queue, neighbour)
+				}
+			}
+		}
+
+		sort.Strings(component)
+		components = append(components, component)
+	}
+
+	return components
+}
+
+// partition splits a connected component at its bridges, the single edges
+// whose removal disconnects it, which leaves the groups of resources that
+// are connected by two edges or more. Groups smaller than minSize are merged,
+// smallest first, into the neighbouring group they share the most edges
+// with.
+func partition(component []string, neighbours map[string]map[string]int, minSize int) [][]string {
+	cut := bridges(component, neighbours)
+
+	kept := make(map[string]map[string]int, len(component))
+	for _, id := range component {
+		for neighbour, count := range neighbours[id] {
+			if cut[[2]string{id, neighbour}] {
+				continue
+			}
+			if kept[id] == nil {
+				kept[id] = make(map[string]int)
+			}
+			kept[id][neighbour] = count
+		}
+	}
+
+	labels := make(map[string]string, len(component))
+	members := make(map[string][]string)
+	for _, group := range connectedComponents(component, kept) {
+		for _, id := range group {
+			labels[id] = group[0]
+		}
+		members[group[0]] = group
+	}
+
+	for len(members) > 1 {
+		smallest := ""
+		for label, group := range members {
+			if len(group) < minSize && (smallest == "" || len(group) < len(members[smallest]) ||
+				len(group) == len(members[smallest]) && label < smallest) {
+				smallest = label
+			}
+		}
+
+		if smallest == "" {
+			break
+		}
+
+		weights := make(map[string]int)
+		for _, id := range members[smallest] {
+			for neighbour, count := range neighbours[id] {
+				if label := labels[neighbour]; label != smallest {
+					weights[label] += count
+				}
+			}
+		}
+
+		target, _ := dominant(weights)
+		for _, id := range members[smallest] {
+			labels[id] = target
+		}
+		members[target] = append(members[target], members[smallest]...)
+		delete(members, smallest)
+	}
+
+	groups := make([][]string, 0, len(members))
+	for _, group := range members {
+		sort.Strings(group)
+		groups = append(groups, group)
+	}
+
+	return groups
+}
+
+// bridges returns the bridges of a connected component, in both directions,
+// by Tarjan's algorithm. Edges repeated between two nodes are never bridges.
+func bridges(component []string, neighbours map[string]map[string]int) map[[2]string]bool {
+	cut := make(map[[2]string]bool)
+	discovered := make(map[string]int, len(component))
+	low := make(map[string]int, len(component))
+	timer := 0
+
+	var visit func(id, parent string)
+	visit = func(id, parent string) {
+		timer++
+		discovered[id], low[id] = timer, timer
+
+		for _, neighbour := range sortedNeighbours(neighbours[id]) {
+			switch {
+			case neighbour == parent:
+				if neighbours[id][neighbour] > 1 {
+					low[id] = min(low[id], discovered[neighbour])
+				}
+			case discovered[neighbour] == 0:
+				visit(neighbour, id)
+				low[id] = min(low[id], low[neighbour])
+
+				if low[neighbour] > discovered[id] {
+					cut[[2]string{id, neighbour}] = true
+					cut[[2]string{neighbour, id}] = true
+				}
+			default:
+				low[id] = min(low[id], discovered[neighbour])
+			}
+		}
+	}
+
+	visit(component[0], "")
+
+	return cut
+}
+
+func sortedNeighbours(neighbours map[string]int) []string {
+	ids := make([]string, 0, len(neighbours))
+	for id := range neighbours {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
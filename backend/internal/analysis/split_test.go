@@ -0,0 +1,91 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestSplitSuggestions(t *testing.T) {
+	t.Run("splits large components along low cuts", func(t *testing.T) {
+		report := SplitSuggestions(clusterState(), 2)
+
+		assert.Equal(t, 2, report.Components)
+		require.Len(t, report.Splits, 3)
+
+		network, app, rest := report.Splits[0], report.Splits[1], report.Splits[2]
+		assert.Equal(t, "split-1", network.ID)
+		assert.Equal(t, []string{"aws_route_table.main", "aws_subnet.a", "aws_subnet.b", "aws_vpc.main"}, network.Nodes)
+		assert.Empty(t, network.DependsOn)
+
+		assert.Equal(t, "module.app", app.Label)
+		assert.Equal(t, []string{"split-1"}, app.DependsOn, "the application reads the network")
+		assert.Equal(t, 1, app.ExternalEdges)
+
+		assert.Equal(t, []string{"aws_s3_bucket.logs"}, rest.Nodes)
+
+		assert.Equal(t, []models.CrossDependency{
+			{Source: "module.app.aws_instance.web", Target: "aws_subnet.a", From: "split-2", To: "split-1"},
+		}, report.CrossDependencies)
+	})
+
+	t.Run("keeps components below twice the minimum size whole", func(t *testing.T) {
+		report := SplitSuggestions(clusterState(), 5)
+
+		require.Len(t, report.Splits, 2)
+		assert.Equal(t, 8, report.Splits[0].Size)
+		assert.Empty(t, report.CrossDependencies)
+		assert.Equal(t, []string{"aws_s3_bucket.logs"}, report.Splits[1].Nodes)
+	})
+
+	t.Run("merges small clusters into their neighbours", func(t *testing.T) {
+		report := SplitSuggestions(clusterState(), 4)
+
+		require.Len(t, report.Splits, 3)
+		assert.Equal(t, 4, report.Splits[0].Size)
+		assert.Equal(t, 4, report.Splits[1].Size)
+	})
+
+	t.Run("reports no splits for empty states", func(t *testing.T) {
+		report := SplitSuggestions(&models.TerraformState{}, 2)
+
+		assert.Zero(t, report.Components)
+		assert.Empty(t, report.Splits)
+	})
+}
+
+func TestPartition(t *testing.T) {
+	neighbours := make(map[string]map[string]int)
+	connect := func(ids ...string) {
+		for i, a := range ids {
+			for _, b := range ids[i+1:] {
+				link(neighbours, a, b)
+				link(neighbours, b, a)
+			}
+		}
+	}
+	connect("a", "b", "c", "d")
+	connect("e", "f", "g", "h")
+	connect("d", "e")
+
+	component := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+
+	assert.ElementsMatch(t, [][]string{{"a", "b", "c", "d"}, {"e", "f", "g", "h"}}, partition(component, neighbours, 4))
+	assert.Equal(t, [][]string{component}, partition(component, neighbours, 5))
+}
+
+func TestBridges(t *testing.T) {
+	neighbours := make(map[string]map[string]int)
+	for _, edge := range [][2]string{{"a", "b"}, {"b", "c"}, {"c", "a"}, {"c", "d"}, {"d", "e"}, {"d", "e"}} {
+		link(neighbours, edge[0], edge[1])
+		link(neighbours, edge[1], edge[0])
+	}
+
+	assert.Equal(t, map[[2]string]bool{{"c", "d"}: true, {"d", "c"}: true}, bridges([]string{"a", "b", "c", "d", "e"}, neighbours),
+		"cycles and repeated edges are not bridges")
+}
@@ -0,0 +1,67 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"context"
+	"github.com/terrascope/core/internal/models"
+)
+
+func init() {
+	Register(staleAnalyzer{})
+}
+
+// staleAnalyzer reports the instances Terraform will replace or destroy on
+// the next apply: tainted instances, and deposed objects a
+// create_before_destroy replacement left behind.
+type staleAnalyzer struct{}
+
+func (staleAnalyzer) Name() string { return "stale" }
+
+func (staleAnalyzer) Description() string {
+	return "Tainted instances and deposed objects awaiting replacement or destruction"
+}
+
+func (staleAnalyzer) Analyze(ctx context.Context, graph *models.Graph, _ *models.TerraformState) []models.Finding {
+	findings := []models.Finding{}
+
+	for _, node := range graph.Nodes {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		finding := models.Finding{
+			Check:   node.Status,
+			NodeID:  node.ID,
+			Address: nodeAddress(graph, node),
+		}
+
+		switch node.Status {
+		case models.NodeStatusTainted:
+			finding.Severity = models.SeverityError
+			finding.Message = "the instance is tainted and will be replaced on the next apply"
+			finding.Remediation = "apply to replace it, or run terraform untaint if it is healthy"
+		case models.NodeStatusDeposed:
+			finding.Severity = models.SeverityWarning
+			finding.Message = "the object was deposed by a create_before_destroy replacement and was not destroyed"
+			finding.Remediation = "apply to destroy it"
+		default:
+			continue
+		}
+
+		findings = append(findings, finding)
+	}
+
+	return findings
+}
+
+// nodeAddress returns the Terraform address of a node, or its ID when the
+// graph does not record one.
+func nodeAddress(graph *models.Graph, node models.Node) string {
+	if address, ok := graph.Addresses[node.ID]; ok {
+		return address.Terraform
+	}
+
+	return node.ID
+}
@@ -0,0 +1,34 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestStaleAnalyzer(t *testing.T) {
+	graph := &models.Graph{
+		Nodes: []models.Node{
+			{ID: "aws_instance.web", Status: models.NodeStatusTainted},
+			{ID: "aws_instance.api"},
+			{ID: "aws_instance.db/deposed/abc", Status: models.NodeStatusDeposed},
+		},
+		Addresses: map[string]models.NodeAddress{"aws_instance.web": {Terraform: "aws_instance.web[0]"}},
+	}
+
+	findings := staleAnalyzer{}.Analyze(context.Background(), graph, nil)
+
+	require.Len(t, findings, 2)
+	assert.Equal(t, "tainted", findings[0].Check)
+	assert.Equal(t, models.SeverityError, findings[0].Severity)
+	assert.Equal(t, "aws_instance.web[0]", findings[0].Address)
+	assert.Equal(t, "deposed", findings[1].Check)
+	assert.Equal(t, models.SeverityWarning, findings[1].Severity)
+	assert.Equal(t, "aws_instance.db/deposed/abc", findings[1].Address, "nodes without an address are reported by ID")
+}
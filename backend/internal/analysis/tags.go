@@ -0,0 +1,58 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"context"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+func init() {
+	Register(tagsAnalyzer{})
+}
+
+// tagsAnalyzer reports the managed instances that support tags but have
+// none, neither their own nor the default tags of their provider.
+type tagsAnalyzer struct{}
+
+func (tagsAnalyzer) Name() string { return "tags" }
+
+func (tagsAnalyzer) Description() string {
+	return "Taggable resources without any tags"
+}
+
+func (tagsAnalyzer) Analyze(ctx context.Context, _ *models.Graph, state *models.TerraformState) []models.Finding {
+	findings := []models.Finding{}
+
+	for _, ref := range managedInstances(state) {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		attrs := ref.Instance.Attributes
+
+		tags, taggable := attrs["tags"]
+		if !taggable || !emptyTags(tags) || !emptyTags(attrs["tags_all"]) {
+			continue
+		}
+
+		findings = append(findings, models.Finding{
+			Check:       "untagged",
+			Severity:    models.SeverityWarning,
+			NodeID:      ref.NodeID,
+			Address:     parser.InstanceAddress(ref.Resource, ref.Instance),
+			Message:     "the resource has no tags",
+			Remediation: "add tags, or default_tags to its provider",
+		})
+	}
+
+	return findings
+}
+
+func emptyTags(value any) bool {
+	tags, _ := value.(map[string]any)
+
+	return len(tags) == 0
+}
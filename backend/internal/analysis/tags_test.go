@@ -0,0 +1,41 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestTagsAnalyzer(t *testing.T) {
+	instance := func(name string, attrs map[string]any) models.ResourceState {
+		return models.ResourceState{
+			Mode: "managed", Type: "aws_instance", Name: name,
+			Provider:  `provider["registry.terraform.io/hashicorp/aws"]`,
+			Instances: []models.ResourceInstance{{Attributes: attrs}},
+		}
+	}
+
+	state := &models.TerraformState{
+		Resources: []models.ResourceState{
+			instance("tagged", map[string]any{"id": "i-1", "tags": map[string]any{"Team": "a"}}),
+			instance("defaults", map[string]any{"id": "i-2", "tags": map[string]any{}, "tags_all": map[string]any{"Team": "a"}}),
+			instance("empty", map[string]any{"id": "i-3", "tags": map[string]any{}}),
+			instance("null", map[string]any{"id": "i-4", "tags": nil}),
+			instance("untaggable", map[string]any{"id": "i-5"}),
+		},
+	}
+
+	findings := tagsAnalyzer{}.Analyze(context.Background(), nil, state)
+
+	require.Len(t, findings, 2)
+	assert.Equal(t, "aws_instance.empty", findings[0].Address)
+	assert.Equal(t, "aws_instance.null", findings[1].Address)
+	assert.Equal(t, "untagged", findings[0].Check)
+	assert.Equal(t, models.SeverityWarning, findings[0].Severity)
+}
@@ -0,0 +1,70 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// Unmanaged compares a cloud inventory with the managed resources of the
+// states, by lineage, and reports the inventory resources that none of them
+// manages. A resource is managed when its ID or ARN is the id, arn or
+// self_link of a managed instance, compared regardless of case. Resources
+// listed more than once, as in configuration histories, count once.
+func Unmanaged(inventory *models.Inventory, states map[string]*models.TerraformState) *models.UnmanagedReport {
+	managed := make(map[string]bool)
+	for _, state := range states {
+		for _, ref := range managedInstances(state) {
+			for _, key := range []string{"id", "arn", "self_link"} {
+				if value := stringAttr(ref.Instance.Attributes, key); value != "" {
+					managed[strings.ToLower(value)] = true
+				}
+			}
+		}
+	}
+
+	report := &models.UnmanagedReport{
+		Unmanaged: []models.InventoryResource{},
+		ByType:    make(map[string]int),
+	}
+
+	seen := make(map[string]bool, len(inventory.Resources))
+	for _, resource := range inventory.Resources {
+		key := strings.ToLower(resource.ARN)
+		if key == "" {
+			key = resource.Type + "\x00" + strings.ToLower(resource.ID)
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		report.Total++
+
+		if resource.ID != "" && managed[strings.ToLower(resource.ID)] || resource.ARN != "" && managed[strings.ToLower(resource.ARN)] {
+			report.Managed++
+			continue
+		}
+
+		report.Unmanaged = append(report.Unmanaged, resource)
+		if resource.Type != "" {
+			report.ByType[resource.Type]++
+		}
+	}
+
+	sort.Slice(report.Unmanaged, func(i, j int) bool {
+		a, b := report.Unmanaged[i], report.Unmanaged[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return a.ARN < b.ARN
+	})
+
+	return report
+}
@@ -0,0 +1,47 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestUnmanaged(t *testing.T) {
+	states := map[string]*models.TerraformState{
+		"network": {Resources: []models.ResourceState{
+			{Mode: "managed", Type: "aws_vpc", Name: "main", Instances: []models.ResourceInstance{
+				{Attributes: map[string]any{"id": "vpc-1"}},
+			}},
+			{Mode: "data", Type: "aws_vpc", Name: "shared", Instances: []models.ResourceInstance{
+				{Attributes: map[string]any{"id": "vpc-2"}},
+			}},
+		}},
+		"app": {Resources: []models.ResourceState{
+			{Mode: "managed", Type: "aws_iam_role", Name: "app", Instances: []models.ResourceInstance{
+				{Attributes: map[string]any{"id": "app", "arn": "arn:aws:iam::123456789012:role/app"}},
+			}},
+		}},
+	}
+
+	inventory := &models.Inventory{Resources: []models.InventoryResource{
+		{ID: "VPC-1", Type: "AWS::EC2::VPC"},
+		{ID: "vpc-2", Type: "AWS::EC2::VPC"},
+		{ID: "AROAEXAMPLE", ARN: "arn:aws:iam::123456789012:role/app", Type: "AWS::IAM::Role"},
+		{ID: "AROAOTHER", ARN: "arn:aws:iam::123456789012:role/admin", Type: "AWS::IAM::Role"},
+		{ID: "AROAOTHER", ARN: "arn:aws:iam::123456789012:role/admin", Type: "AWS::IAM::Role"},
+	}}
+
+	report := Unmanaged(inventory, states)
+
+	assert.Equal(t, 4, report.Total)
+	assert.Equal(t, 2, report.Managed)
+	assert.Equal(t, []models.InventoryResource{
+		{ID: "vpc-2", Type: "AWS::EC2::VPC"},
+		{ID: "AROAOTHER", ARN: "arn:aws:iam::123456789012:role/admin", Type: "AWS::IAM::Role"},
+	}, report.Unmanaged)
+	assert.Equal(t, map[string]int{"AWS::EC2::VPC": 1, "AWS::IAM::Role": 1}, report.ByType)
+}
@@ -0,0 +1,140 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+func init() {
+	Register(unusedAnalyzer{})
+}
+
+// unusedAnalyzer reports the data sources nothing uses, as candidates for
+// cleanup: no managed resource depends on them, directly or through other
+// data sources, and no output holds one of their values.
+type unusedAnalyzer struct{}
+
+func (unusedAnalyzer) Name() string { return "unused" }
+
+func (unusedAnalyzer) Description() string {
+	return "Data sources that no managed resource or output uses"
+}
+
+func (unusedAnalyzer) Analyze(ctx context.Context, graph *models.Graph, state *models.TerraformState) []models.Finding {
+	// Edges name their target as recorded in the state, which for data
+	// sources is their address rather than their node ID, and for resources
+	// with several instances can be the resource rather than an instance.
+	nodes := make(map[string][]string, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		names := []string{node.ID}
+		if address, ok := graph.Addresses[node.ID]; ok {
+			names = append(names, address.Terraform, trimInstanceKey(address.Terraform))
+		}
+
+		for _, name := range slices.Compact(names) {
+			nodes[name] = append(nodes[name], node.ID)
+		}
+	}
+
+	dependencies := make(map[string][]string)
+	for _, edge := range graph.Edges {
+		dependencies[edge.Source] = append(dependencies[edge.Source], nodes[edge.Target]...)
+	}
+
+	used := make(map[string]bool)
+
+	var use func(id string)
+	use = func(id string) {
+		if used[id] {
+			return
+		}
+		used[id] = true
+
+		for _, target := range dependencies[id] {
+			use(target)
+		}
+	}
+
+	for _, node := range graph.Nodes {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if node.Mode == "managed" {
+			use(node.ID)
+		}
+	}
+
+	for _, id := range outputReferences(state) {
+		use(id)
+	}
+
+	findings := []models.Finding{}
+
+	for _, node := range graph.Nodes {
+		if node.Mode != "data" || used[node.ID] {
+			continue
+		}
+
+		findings = append(findings, models.Finding{
+			Check:       "unused_data_source",
+			Severity:    models.SeverityInfo,
+			NodeID:      node.ID,
+			Address:     nodeAddress(graph, node),
+			Message:     "no managed resource or output uses the data source",
+			Remediation: "remove the data block unless the configuration reads it elsewhere, such as in locals or provider settings",
+		})
+	}
+
+	return findings
+}
+
+// outputReferences returns the data source instances with an attribute equal
+// to the value of an output, or to one of the strings it holds.
+func outputReferences(state *models.TerraformState) []string {
+	if state == nil || len(state.Outputs) == 0 {
+		return nil
+	}
+
+	values := make(map[string]bool)
+	for _, output := range state.Outputs {
+		for _, value := range outputStrings(output.Value) {
+			values[value] = true
+		}
+	}
+
+	var ids []string
+
+	for _, ref := range parser.Instances(state) {
+		if ref.Resource.Mode != "data" {
+			continue
+		}
+
+		referenced := false
+		walkStringPaths(ref.Instance.Attributes, "", func(_, value string) {
+			referenced = referenced || values[value]
+		})
+
+		if referenced {
+			ids = append(ids, ref.NodeID)
+		}
+	}
+
+	return ids
+}
+
+// trimInstanceKey returns the address of the resource of an instance address.
+func trimInstanceKey(address string) string {
+	if i := strings.LastIndex(address, "["); i > 0 && strings.HasSuffix(address, "]") {
+		return address[:i]
+	}
+
+	return address
+}
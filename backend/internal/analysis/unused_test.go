@@ -0,0 +1,65 @@
+// Package analysis provides read-only analyses over parsed Terraform states.
+// Each analysis correlates resource attributes into a report that complements
+// the dependency graph.
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+func TestUnusedAnalyzer(t *testing.T) {
+	aws := `provider["registry.terraform.io/hashicorp/aws"]`
+
+	data := func(resType, name string, attrs map[string]any, dependencies ...string) models.ResourceState {
+		return models.ResourceState{
+			Mode: "data", Type: resType, Name: name, Provider: aws,
+			Instances: []models.ResourceInstance{{Attributes: attrs, Dependencies: dependencies}},
+		}
+	}
+
+	state := &models.TerraformState{
+		Outputs: map[string]models.Output{"account": {Value: "123456789012"}},
+		Resources: []models.ResourceState{
+			{
+				Mode: "managed", Type: "aws_instance", Name: "web", Provider: aws,
+				Instances: []models.ResourceInstance{{
+					Attributes:   map[string]any{"id": "i-1", "ami": "ami-1"},
+					Dependencies: []string{"data.aws_ami.ubuntu"},
+				}},
+			},
+			{
+				Mode: "managed", Type: "aws_subnet", Name: "private", Provider: aws,
+				Instances: []models.ResourceInstance{
+					{IndexKey: float64(0), Attributes: map[string]any{"id": "subnet-1"}, Dependencies: []string{"data.aws_availability_zones.all"}},
+					{IndexKey: float64(1), Attributes: map[string]any{"id": "subnet-2"}, Dependencies: []string{"data.aws_availability_zones.all"}},
+				},
+			},
+			data("aws_availability_zones", "all", map[string]any{"id": "eu-west-1"}),
+			data("aws_ami", "ubuntu", map[string]any{"id": "ami-1"}, "data.aws_region.current"),
+			data("aws_region", "current", map[string]any{"id": "eu-west-1"}),
+			data("aws_caller_identity", "current", map[string]any{"id": "123456789012", "account_id": "123456789012"}),
+			data("aws_iam_policy_document", "unused", map[string]any{"id": "1234", "json": "{}"}, "data.aws_vpc.legacy"),
+			data("aws_vpc", "legacy", map[string]any{"id": "vpc-1"}),
+		},
+	}
+
+	graph := parser.BuildGraph(state)
+
+	var unused []string
+	for _, finding := range (unusedAnalyzer{}).Analyze(context.Background(), graph, state) {
+		assert.Equal(t, "unused_data_source", finding.Check)
+		assert.Equal(t, models.SeverityInfo, finding.Severity)
+		unused = append(unused, finding.Address)
+	}
+
+	assert.Equal(t, []string{"data.aws_iam_policy_document.unused", "data.aws_vpc.legacy"}, unused,
+		"data sources used by managed resources, through other data sources or by outputs are not reported")
+
+	assert.Empty(t, (unusedAnalyzer{}).Analyze(context.Background(), parser.BuildGraph(&models.TerraformState{}), nil))
+}
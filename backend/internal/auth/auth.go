@@ -0,0 +1,138 @@
+// Package auth checks the passwords of the users allowed to sign in to a
+// self-hosted deployment.
+package auth
+
+import (
+	"bufio"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Password hashes are PBKDF2-HMAC-SHA256 digests, encoded as
+// pbkdf2-sha256$<iterations>$<salt>$<key> with unpadded base64 salt and key.
+const (
+	hashScheme     = "pbkdf2-sha256"
+	hashIterations = 600000
+	saltLength     = 16
+	keyLength      = 32
+)
+
+// dummyHash is checked by Verify for users without a password, so that a
+// failed login takes as long whether or not the user exists.
+var dummyHash = sync.OnceValue(func() string {
+	hash, _ := HashPassword("")
+	return hash
+})
+
+// HashPassword returns the hash of a password to put in a users file.
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, hashIterations, keyLength)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join([]string{
+		hashScheme,
+		strconv.Itoa(hashIterations),
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	}, "$"), nil
+}
+
+// CheckPassword reports whether the password matches the hash.
+func CheckPassword(hash, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != hashScheme {
+		return false
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations < 1 {
+		return false
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+
+	want, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil || len(want) == 0 {
+		return false
+	}
+
+	got, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(want))
+	if err != nil {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// Verify reports whether the password matches the hash of a user. An empty
+// hash, for a user that does not exist or has no password, never matches but
+// takes as long to check as any other.
+func Verify(hash, password string) bool {
+	if hash == "" {
+		CheckPassword(dummyHash(), password)
+		return false
+	}
+
+	return CheckPassword(hash, password)
+}
+
+// Users maps user names to password hashes.
+type Users map[string]string
+
+// ParseUsers reads a users file: one user:hash line per user, as printed by
+// HashPassword. Blank lines and lines starting with # are ignored.
+func ParseUsers(r io.Reader) (Users, error) {
+	users := Users{}
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		name, hash, ok := strings.Cut(text, ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("line %d: expected user:hash", line)
+		}
+
+		if !strings.HasPrefix(hash, hashScheme+"$") {
+			return nil, fmt.Errorf("line %d: password of %s is not a %s hash", line, name, hashScheme)
+		}
+
+		if _, exists := users[name]; exists {
+			return nil, fmt.Errorf("line %d: duplicate user %s", line, name)
+		}
+
+		users[name] = hash
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// Authenticate reports whether the user exists and the password is theirs.
+func (u Users) Authenticate(name, password string) bool {
+	return Verify(u[name], password)
+}
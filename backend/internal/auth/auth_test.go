@@ -0,0 +1,78 @@
+// Package auth checks the passwords of the users allowed to sign in to a
+// self-hosted deployment.
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(hash, "pbkdf2-sha256$600000$"))
+	assert.True(t, CheckPassword(hash, "correct horse"))
+	assert.False(t, CheckPassword(hash, "wrong horse"))
+
+	other, err := HashPassword("correct horse")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other, "hashes are salted")
+}
+
+func TestCheckPassword(t *testing.T) {
+	// The hash of "secret" with a single iteration and the salt "salt".
+	const hash = "pbkdf2-sha256$1$c2FsdA$ON9CizCTCOSMNofn+QvaDpzyU1aMIex1Sg4HarSrZCM"
+
+	assert.True(t, CheckPassword(hash, "secret"))
+	assert.False(t, CheckPassword(hash, "Secret"))
+
+	for _, malformed := range []string{
+		"",
+		"secret",
+		"bcrypt$1$c2FsdA$ON9CizCTCOSMNofn+QvaDpzyU1aMIex1Sg4HarSrZCM",
+		"pbkdf2-sha256$0$c2FsdA$ON9CizCTCOSMNofn+QvaDpzyU1aMIex1Sg4HarSrZCM",
+		"pbkdf2-sha256$x$c2FsdA$ON9CizCTCOSMNofn+QvaDpzyU1aMIex1Sg4HarSrZCM",
+		"pbkdf2-sha256$1$!!$ON9CizCTCOSMNofn+QvaDpzyU1aMIex1Sg4HarSrZCM",
+		"pbkdf2-sha256$1$c2FsdA$",
+	} {
+		assert.False(t, CheckPassword(malformed, "secret"), malformed)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	const hash = "pbkdf2-sha256$1$c2FsdA$ON9CizCTCOSMNofn+QvaDpzyU1aMIex1Sg4HarSrZCM"
+
+	assert.True(t, Verify(hash, "secret"))
+	assert.False(t, Verify(hash, "wrong"))
+	assert.False(t, Verify("", ""))
+	assert.False(t, Verify("", "secret"))
+}
+
+func TestParseUsers(t *testing.T) {
+	const hash = "pbkdf2-sha256$1$c2FsdA$ON9CizCTCOSMNofn+QvaDpzyU1aMIex1Sg4HarSrZCM"
+
+	t.Run("reads one user per line", func(t *testing.T) {
+		users, err := ParseUsers(strings.NewReader("# team\nalice:" + hash + "\n\n  bob:" + hash + "  \n"))
+		require.NoError(t, err)
+
+		assert.Equal(t, Users{"alice": hash, "bob": hash}, users)
+		assert.True(t, users.Authenticate("alice", "secret"))
+		assert.False(t, users.Authenticate("alice", "wrong"))
+		assert.False(t, users.Authenticate("carol", "secret"))
+	})
+
+	for name, file := range map[string]string{
+		"line 1: expected user:hash":                            "alice",
+		"line 1: password of alice is not a pbkdf2-sha256 hash": "alice:secret",
+		"line 2: duplicate user alice":                          "alice:" + hash + "\nalice:" + hash,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseUsers(strings.NewReader(file))
+			assert.EqualError(t, err, name)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Package cache keeps computed results, keyed by the hash of their inputs, so
+// that replicas sharing a Redis server compute each of them once.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// defaultTTL is how long results are kept unless TERRASCOPE_CACHE_TTL says
+// otherwise.
+const defaultTTL = time.Hour
+
+// defaultMemoryEntries bounds the results kept by the memory cache.
+const defaultMemoryEntries = 256
+
+// Cache stores results for a while. Implementations are safe for concurrent
+// use; a failing cache only costs a recomputation, so callers log its errors
+// and carry on.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte) error
+}
+
+// Shared is a Cache that the replicas of a deployment coordinate through: it
+// also keeps state that must not be evicted, counters and locks.
+type Shared interface {
+	Cache
+	// Put stores the value for ttl, or until it is deleted when ttl is 0.
+	Put(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes the key and reports whether it existed.
+	Delete(ctx context.Context, key string) (bool, error)
+	// Keys returns the keys that start with the prefix, in no order.
+	Keys(ctx context.Context, prefix string) ([]string, error)
+	// Incr adds delta to the counter at key and returns its value. A new
+	// counter starts at 0 and expires at expiresAt.
+	Incr(ctx context.Context, key string, delta int64, expiresAt time.Time) (int64, error)
+	// Lock takes the named lock for ttl unless someone else holds it, and
+	// reports whether it did. Unlock releases it before ttl, unless it has
+	// expired and been taken again since.
+	Lock(ctx context.Context, name string, ttl time.Duration) (unlock func(), ok bool, err error)
+}
+
+// PubSub relays messages between the replicas of a deployment.
+type PubSub interface {
+	// Publish sends the message to the subscribers of the channel on every
+	// replica.
+	Publish(ctx context.Context, channel string, message []byte) error
+	// Subscribe calls receive with the messages of the channel until ctx is
+	// done or the connection fails, and returns why it stopped. Messages
+	// published while no one is subscribed are lost.
+	Subscribe(ctx context.Context, channel string, receive func(message []byte)) error
+}
+
+// Key returns the key of a result of the kind computed from the parts, e.g.
+// the query and body of a request.
+func Key(kind string, parts ...[]byte) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		binary.Write(hash, binary.BigEndian, uint64(len(part)))
+		hash.Write(part)
+	}
+
+	return "terrascope:" + kind + ":" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// FromEnv returns the cache selected by TERRASCOPE_CACHE: memory, for a
+// single server, or redis, at TERRASCOPE_REDIS_URL, to share results between
+// replicas. It returns nil when the variable is unset, which disables
+// caching. An invalid configuration stops the server.
+func FromEnv() Cache {
+	ttl := defaultTTL
+	if value := os.Getenv("TERRASCOPE_CACHE_TTL"); value != "" {
+		// Redis expires keys by the millisecond, and rejects an expiry of
+		// 0 ms.
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < time.Millisecond {
+			log.Fatalf("Reading TERRASCOPE_CACHE_TTL: invalid value %q", value)
+		}
+		ttl = parsed
+	}
+
+	switch name := os.Getenv("TERRASCOPE_CACHE"); name {
+	case "":
+		return nil
+	case "memory":
+		return NewMemory(defaultMemoryEntries, ttl)
+	case "redis":
+		url := os.Getenv("TERRASCOPE_REDIS_URL")
+		if url == "" {
+			url = "redis://localhost:6379"
+		}
+
+		redis, err := NewRedis(url, ttl)
+		if err != nil {
+			log.Fatalf("Reading TERRASCOPE_REDIS_URL: %v", err)
+		}
+
+		return redis
+	default:
+		log.Fatalf("Reading TERRASCOPE_CACHE: unknown cache %q", name)
+		return nil
+	}
+}
+
+// Memory is a Cache local to the server, which evicts the least recently used
+// results beyond its size.
+type Memory struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	order      *list.List
+	entries    map[string]*list.Element
+
+	now func() time.Time
+}
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemory returns a cache of up to maxEntries results, each kept for ttl.
+func NewMemory(maxEntries int, ttl time.Duration) *Memory {
+	return &Memory{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+func (m *Memory) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := element.Value.(*memoryEntry)
+	if !m.now().Before(entry.expiresAt) {
+		m.order.Remove(element)
+		delete(m.entries, key)
+		return nil, false, nil
+	}
+
+	m.order.MoveToFront(element)
+
+	return entry.value, true, nil
+}
+
+func (m *Memory) Set(_ context.Context, key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := &memoryEntry{key: key, value: value, expiresAt: m.now().Add(m.ttl)}
+
+	if element, ok := m.entries[key]; ok {
+		element.Value = entry
+		m.order.MoveToFront(element)
+		return nil
+	}
+
+	m.entries[key] = m.order.PushFront(entry)
+
+	for m.order.Len() > m.maxEntries {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).key)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+// Package cache keeps computed results, keyed by the hash of their inputs, so
+// that replicas sharing a Redis server compute each of them once.
+package cache
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKey(t *testing.T) {
+	key := Key("graph", []byte("view=network"), []byte("{}"))
+	assert.True(t, strings.HasPrefix(key, "terrascope:graph:"))
+	assert.Len(t, strings.TrimPrefix(key, "terrascope:graph:"), 64)
+
+	assert.Equal(t, key, Key("graph", []byte("view=network"), []byte("{}")))
+	assert.NotEqual(t, key, Key("result", []byte("view=network"), []byte("{}")))
+	// Parts are length-prefixed, so moving bytes between them changes the key.
+	assert.NotEqual(t, Key("graph", []byte("ab"), []byte("c")), Key("graph", []byte("a"), []byte("bc")))
+}
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	memory := NewMemory(2, time.Minute)
+	memory.now = func() time.Time { return now }
+
+	_, ok, err := memory.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, memory.Set(ctx, "a", []byte("1")))
+	require.NoError(t, memory.Set(ctx, "b", []byte("2")))
+
+	value, ok, _ := memory.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+
+	t.Run("evicts the least recently used", func(t *testing.T) {
+		require.NoError(t, memory.Set(ctx, "c", []byte("3")))
+
+		_, ok, _ := memory.Get(ctx, "b")
+		assert.False(t, ok)
+		_, ok, _ = memory.Get(ctx, "a")
+		assert.True(t, ok)
+	})
+
+	t.Run("expires results", func(t *testing.T) {
+		now = now.Add(time.Minute)
+
+		_, ok, _ := memory.Get(ctx, "a")
+		assert.False(t, ok)
+		assert.Equal(t, 1, memory.order.Len())
+	})
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("TERRASCOPE_CACHE", "")
+	assert.Nil(t, FromEnv())
+
+	t.Setenv("TERRASCOPE_CACHE", "memory")
+	t.Setenv("TERRASCOPE_CACHE_TTL", "5m")
+	memory, ok := FromEnv().(*Memory)
+	require.True(t, ok)
+	assert.Equal(t, 5*time.Minute, memory.ttl)
+
+	t.Setenv("TERRASCOPE_CACHE", "redis")
+	t.Setenv("TERRASCOPE_REDIS_URL", "")
+	redis, ok := FromEnv().(*Redis)
+	require.True(t, ok)
+	assert.Equal(t, "localhost:6379", redis.client.Options().Addr)
+}
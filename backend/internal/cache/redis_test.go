@@ -0,0 +1,168 @@
+// Package cache keeps computed results, keyed by the hash of their inputs, so
+// that replicas sharing a Redis server compute each of them once.
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedis(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	server.RequireUserAuth("app", "s3cret")
+
+	redis, err := NewRedis("redis://app:s3cret@"+server.Addr()+"/2", 90*time.Second)
+	require.NoError(t, err)
+
+	_, ok, err := redis.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, redis.Set(ctx, "key", []byte("line\r\nbreak")))
+
+	value, ok, err := redis.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("line\r\nbreak"), value)
+
+	// The value is kept for the ttl in the database of the URL.
+	stored, err := server.DB(2).Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, "line\r\nbreak", stored)
+	assert.Equal(t, 90*time.Second, server.DB(2).TTL("key"))
+
+	t.Run("reports error replies", func(t *testing.T) {
+		redis, err := NewRedis("redis://wrong@"+server.Addr(), time.Minute)
+		require.NoError(t, err)
+
+		_, _, err = redis.Get(ctx, "key")
+		assert.ErrorContains(t, err, "WRONGPASS")
+	})
+}
+
+func TestRedisShared(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+
+	redis, err := NewRedis("redis://"+server.Addr(), time.Minute)
+	require.NoError(t, err)
+
+	t.Run("stores and deletes values", func(t *testing.T) {
+		require.NoError(t, redis.Put(ctx, "terrascope:a*:1", []byte("1"), 0))
+		require.NoError(t, redis.Put(ctx, "terrascope:a*:2", []byte("2"), time.Hour))
+		require.NoError(t, redis.Put(ctx, "terrascope:ab:1", []byte("3"), 0))
+
+		assert.Zero(t, server.TTL("terrascope:a*:1"))
+		assert.Equal(t, time.Hour, server.TTL("terrascope:a*:2"))
+
+		keys, err := redis.Keys(ctx, "terrascope:a*:")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"terrascope:a*:1", "terrascope:a*:2"}, keys)
+
+		deleted, err := redis.Delete(ctx, "terrascope:a*:1")
+		require.NoError(t, err)
+		assert.True(t, deleted)
+
+		deleted, err = redis.Delete(ctx, "terrascope:a*:1")
+		require.NoError(t, err)
+		assert.False(t, deleted)
+	})
+
+	t.Run("counts", func(t *testing.T) {
+		expires := time.Now().Add(time.Hour)
+
+		value, err := redis.Incr(ctx, "counter", 3, expires)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), value)
+
+		value, err = redis.Incr(ctx, "counter", 0, expires)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), value)
+
+		assert.InDelta(t, time.Hour, server.TTL("counter"), float64(time.Minute))
+	})
+
+	t.Run("locks", func(t *testing.T) {
+		unlock, ok, err := redis.Lock(ctx, "purge", time.Minute)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		_, ok, err = redis.Lock(ctx, "purge", time.Minute)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		unlock()
+
+		unlock, ok, err = redis.Lock(ctx, "purge", time.Minute)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		// A lock that expired and was taken again is not released.
+		server.FastForward(2 * time.Minute)
+		_, ok, err = redis.Lock(ctx, "purge", time.Minute)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		unlock()
+		assert.True(t, server.Exists("terrascope:lock:purge"))
+	})
+}
+
+func TestRedisPubSub(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	redis, err := NewRedis("redis://"+server.Addr(), time.Minute)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	messages := make(chan string, 1)
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- redis.Subscribe(ctx, "events", func(message []byte) {
+			select {
+			case messages <- string(message):
+			default:
+			}
+		})
+	}()
+
+	// Publish until the subscription is in place.
+	require.Eventually(t, func() bool {
+		require.NoError(t, redis.Publish(context.Background(), "events", []byte("stored")))
+		return len(messages) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "stored", <-messages)
+
+	cancel()
+	assert.ErrorIs(t, <-stopped, context.Canceled)
+}
+
+func TestNewRedis(t *testing.T) {
+	redis, err := NewRedis("rediss://:pw@cache.internal", time.Minute)
+	require.NoError(t, err)
+
+	options := redis.client.Options()
+	assert.Equal(t, "cache.internal:6379", options.Addr)
+	assert.Equal(t, "pw", options.Password)
+	assert.Empty(t, options.Username)
+	assert.NotNil(t, options.TLSConfig)
+
+	redis, err = NewRedis("redis://secret@localhost/3", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", redis.client.Options().Password, "a lone user is the password")
+	assert.Equal(t, 3, redis.client.Options().DB)
+
+	for _, url := range []string{"http://localhost", "redis://localhost/db"} {
+		_, err := NewRedis(url, time.Minute)
+		assert.Error(t, err, url)
+	}
+
+	_, err = NewRedis("redis://localhost", 500*time.Microsecond)
+	assert.EqualError(t, err, "ttl 500µs is under a millisecond")
+}
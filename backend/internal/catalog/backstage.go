@@ -0,0 +1,192 @@
+// Package catalog maps the resources of Terraform states to the entities of
+// external catalogs, such as the Backstage software catalog and the
+// ServiceNow CMDB, so that they can be surfaced next to the services that use
+// them.
+package catalog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+// Annotations of the Backstage entities, which Backstage plugins use to find
+// a resource in Terrascope. A component annotated with the lineage, and
+// optionally the module, of its infrastructure lists the matching resources.
+const (
+	AnnotationLineage = "terrascope.io/lineage"
+	AnnotationSerial  = "terrascope.io/serial"
+	AnnotationAddress = "terrascope.io/address"
+	AnnotationModule  = "terrascope.io/module"
+	AnnotationCloudID = "terrascope.io/cloud-id"
+)
+
+// maxEntityName is the longest name Backstage accepts for entities and
+// namespaces.
+const maxEntityName = 63
+
+// ownerTags are the resource tags that name the owner of a resource, in
+// order of preference.
+var ownerTags = []string{"owner", "Owner", "team", "Team"}
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// BackstageOptions completes the entities Backstage builds. Owner owns the
+// resources without an owner tag, and the system. BaseURL and APIPrefix
+// locate Terrascope for the links of the entities, and Module keeps only the
+// resources of a module and its children.
+type BackstageOptions struct {
+	Owner     string
+	BaseURL   string
+	APIPrefix string
+	Module    string
+}
+
+// Backstage returns the Backstage entities of a state: a System for its
+// lineage, in a namespace named after it, and a Resource for each managed
+// resource instance, with the resources it depends on and annotations that
+// point back to it in Terrascope.
+func Backstage(state *models.TerraformState, opts BackstageOptions) []models.BackstageEntity {
+	namespace := entityName(strings.ToLower(state.Lineage))
+	system := namespace
+	versionURL := opts.BaseURL + opts.APIPrefix + "/states/" + state.Lineage + "/" + strconv.Itoa(state.Serial)
+
+	entities := []models.BackstageEntity{{
+		APIVersion: models.BackstageAPIVersion,
+		Kind:       models.BackstageKindSystem,
+		Metadata: models.BackstageMetadata{
+			Name:        system,
+			Namespace:   namespace,
+			Title:       state.Lineage,
+			Description: "Resources of the Terraform state " + state.Lineage,
+			Annotations: map[string]string{
+				AnnotationLineage: state.Lineage,
+				AnnotationSerial:  strconv.Itoa(state.Serial),
+			},
+			Links: []models.BackstageLink{{URL: opts.BaseURL + "/", Title: "Terrascope"}},
+		},
+		Spec: models.BackstageSpec{Owner: ownerRef(opts.Owner)},
+	}}
+
+	graph := parser.BuildGraphWithOptions(state, parser.GraphOptions{InstanceEdges: true})
+
+	names := make(map[string]string)
+	refs := make(map[string]parser.InstanceRef)
+	for _, ref := range parser.Instances(state) {
+		if ref.Resource.Mode != "managed" || ref.Instance.Deposed != "" || !inModule(ref.Resource.Module, opts.Module) {
+			continue
+		}
+
+		refs[ref.NodeID] = ref
+		names[ref.NodeID] = entityName(parser.InstanceAddress(ref.Resource, ref.Instance))
+	}
+
+	dependsOn := make(map[string][]string)
+	for _, edge := range graph.Edges {
+		if name, ok := names[edge.Target]; ok && edge.Source != edge.Target {
+			dependsOn[edge.Source] = append(dependsOn[edge.Source], "resource:"+namespace+"/"+name)
+		}
+	}
+
+	ids := make([]string, 0, len(refs))
+	for id := range refs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		ref := refs[id]
+		address := parser.InstanceAddress(ref.Resource, ref.Instance)
+
+		annotations := map[string]string{
+			AnnotationLineage: state.Lineage,
+			AnnotationSerial:  strconv.Itoa(state.Serial),
+			AnnotationAddress: address,
+		}
+		if ref.Resource.Module != "" {
+			annotations[AnnotationModule] = ref.Resource.Module
+		}
+		if cloudID, _ := ref.Instance.Attributes["id"].(string); cloudID != "" {
+			annotations[AnnotationCloudID] = cloudID
+		}
+
+		owner := opts.Owner
+		if tags, ok := ref.Instance.Attributes["tags"].(map[string]any); ok {
+			for _, key := range ownerTags {
+				if value, _ := tags[key].(string); value != "" {
+					owner = value
+					break
+				}
+			}
+		}
+
+		deps := dependsOn[id]
+		sort.Strings(deps)
+
+		entities = append(entities, models.BackstageEntity{
+			APIVersion: models.BackstageAPIVersion,
+			Kind:       models.BackstageKindResource,
+			Metadata: models.BackstageMetadata{
+				Name:        names[id],
+				Namespace:   namespace,
+				Title:       address,
+				Annotations: annotations,
+				Tags:        []string{"terraform", path.Base(parser.ProviderSource(ref.Resource.Provider))},
+				Links: []models.BackstageLink{
+					{URL: opts.BaseURL + "/", Title: "Terrascope"},
+					{URL: versionURL, Title: "Terraform state"},
+				},
+			},
+			Spec: models.BackstageSpec{
+				Type:      ref.Resource.Type,
+				Owner:     ownerRef(owner),
+				System:    system,
+				DependsOn: deps,
+			},
+		})
+	}
+
+	return entities
+}
+
+// entityName turns an address into a valid Backstage name: runs of other
+// characters than letters and digits become dashes, and names that are too
+// long are cut and end with a hash of the address so they stay unique.
+func entityName(address string) string {
+	name := strings.Trim(invalidNameChars.ReplaceAllString(address, "-"), "-")
+	if name != "" && len(name) <= maxEntityName {
+		return name
+	}
+
+	sum := sha256.Sum256([]byte(address))
+	suffix := hex.EncodeToString(sum[:4])
+	prefix := strings.Trim(name[:min(len(name), maxEntityName-len(suffix)-1)], "-")
+	if prefix == "" {
+		return suffix
+	}
+
+	return prefix + "-" + suffix
+}
+
+// ownerRef references an owner by entity reference, as a group of the
+// default namespace unless the owner is a reference already.
+func ownerRef(owner string) string {
+	if strings.ContainsAny(owner, ":/") {
+		return owner
+	}
+
+	return "group:default/" + owner
+}
+
+// inModule reports whether a resource of module belongs to the selected
+// module or to one of its children. Every module is selected by "".
+func inModule(module, selected string) bool {
+	return selected == "" || module == selected || strings.HasPrefix(module, selected+".")
+}
@@ -0,0 +1,125 @@
+// Package catalog maps the resources of Terraform states to the entities of
+// external catalogs, such as the Backstage software catalog and the
+// ServiceNow CMDB, so that they can be surfaced next to the services that use
+// them.
+package catalog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+const awsProvider = `provider["registry.terraform.io/hashicorp/aws"]`
+
+func catalogState() *models.TerraformState {
+	return &models.TerraformState{
+		Lineage: "Prod-1",
+		Serial:  7,
+		Resources: []models.ResourceState{
+			{
+				Mode:      "managed",
+				Type:      "aws_vpc",
+				Name:      "main",
+				Provider:  awsProvider,
+				Instances: []models.ResourceInstance{{Attributes: map[string]any{"id": "vpc-1"}}},
+			},
+			{
+				Mode:     "managed",
+				Type:     "aws_subnet",
+				Name:     "private",
+				Module:   "module.network",
+				Provider: awsProvider,
+				Instances: []models.ResourceInstance{{
+					IndexKey:     "a",
+					Attributes:   map[string]any{"id": "subnet-1", "tags": map[string]any{"Team": "network"}},
+					Dependencies: []string{"aws_vpc.main"},
+				}},
+			},
+			{
+				Mode:      "data",
+				Type:      "aws_region",
+				Name:      "current",
+				Provider:  awsProvider,
+				Instances: []models.ResourceInstance{{Attributes: map[string]any{"name": "eu-west-1"}}},
+			},
+		},
+	}
+}
+
+func TestBackstage(t *testing.T) {
+	opts := BackstageOptions{Owner: "platform", BaseURL: "https://terrascope.example.com", APIPrefix: "/api/v1"}
+
+	t.Run("maps the lineage to a system", func(t *testing.T) {
+		entities := Backstage(catalogState(), opts)
+
+		require.Len(t, entities, 3, "data sources are left out")
+		system := entities[0]
+
+		assert.Equal(t, models.BackstageKindSystem, system.Kind)
+		assert.Equal(t, models.BackstageAPIVersion, system.APIVersion)
+		assert.Equal(t, "prod-1", system.Metadata.Name)
+		assert.Equal(t, "prod-1", system.Metadata.Namespace)
+		assert.Equal(t, "group:default/platform", system.Spec.Owner)
+		assert.Equal(t, "Prod-1", system.Metadata.Annotations[AnnotationLineage])
+	})
+
+	t.Run("maps resources with their dependencies", func(t *testing.T) {
+		entities := Backstage(catalogState(), opts)
+		vpc, subnet := entities[1], entities[2]
+
+		assert.Equal(t, models.BackstageKindResource, vpc.Kind)
+		assert.Equal(t, "aws-vpc-main", vpc.Metadata.Name)
+		assert.Equal(t, "aws_vpc", vpc.Spec.Type)
+		assert.Equal(t, "prod-1", vpc.Spec.System)
+		assert.Equal(t, "group:default/platform", vpc.Spec.Owner)
+		assert.Empty(t, vpc.Spec.DependsOn)
+		assert.Equal(t, []string{"terraform", "aws"}, vpc.Metadata.Tags)
+		assert.Equal(t, map[string]string{
+			AnnotationLineage: "Prod-1",
+			AnnotationSerial:  "7",
+			AnnotationAddress: "aws_vpc.main",
+			AnnotationCloudID: "vpc-1",
+		}, vpc.Metadata.Annotations)
+		assert.Contains(t, vpc.Metadata.Links, models.BackstageLink{
+			URL:   "https://terrascope.example.com/api/v1/states/Prod-1/7",
+			Title: "Terraform state",
+		})
+
+		assert.Equal(t, `module-network-aws-subnet-private-a`, subnet.Metadata.Name)
+		assert.Equal(t, "module.network", subnet.Metadata.Annotations[AnnotationModule])
+		assert.Equal(t, "group:default/network", subnet.Spec.Owner, "owner tags take precedence")
+		assert.Equal(t, []string{"resource:prod-1/aws-vpc-main"}, subnet.Spec.DependsOn)
+	})
+
+	t.Run("keeps the resources of a module", func(t *testing.T) {
+		moduleOpts := opts
+		moduleOpts.Module = "module.network"
+
+		entities := Backstage(catalogState(), moduleOpts)
+
+		require.Len(t, entities, 2)
+		assert.Equal(t, "module-network-aws-subnet-private-a", entities[1].Metadata.Name)
+		assert.Empty(t, entities[1].Spec.DependsOn, "dependencies outside the module are left out")
+	})
+}
+
+func TestEntityName(t *testing.T) {
+	assert.Equal(t, "aws-instance-web-0", entityName("aws_instance.web[0]"))
+	assert.Equal(t, "module-a-aws-s3-bucket-b-x", entityName(`module.a.aws_s3_bucket.b["x"]`))
+
+	long := "module." + strings.Repeat("a", 80) + ".aws_instance.web"
+	name := entityName(long)
+	assert.Len(t, name, maxEntityName)
+	assert.NotEqual(t, name, entityName(long+"2"), "cut names stay unique")
+
+	assert.Len(t, entityName("__"), 8)
+}
+
+func TestOwnerRef(t *testing.T) {
+	assert.Equal(t, "group:default/platform", ownerRef("platform"))
+	assert.Equal(t, "user:default/jane", ownerRef("user:default/jane"))
+}
@@ -0,0 +1,247 @@
+// Package catalog maps the resources of Terraform states to the entities of
+// external catalogs, such as the Backstage software catalog and the
+// ServiceNow CMDB, so that they can be surfaced next to the services that use
+// them.
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+const (
+	defaultCMDBClass       = "cmdb_ci"
+	defaultServiceNowTable = "u_terrascope_ci_import"
+	pushTimeout            = 60 * time.Second
+)
+
+// cmdbClasses maps resource types to the CMDB classes of their configuration
+// items. Other types become items of the base cmdb_ci class.
+var cmdbClasses = map[string]string{
+	"aws_instance":                    "cmdb_ci_vm_instance",
+	"google_compute_instance":         "cmdb_ci_vm_instance",
+	"azurerm_linux_virtual_machine":   "cmdb_ci_vm_instance",
+	"azurerm_windows_virtual_machine": "cmdb_ci_vm_instance",
+	"aws_db_instance":                 "cmdb_ci_cloud_database",
+	"aws_rds_cluster":                 "cmdb_ci_cloud_database",
+	"aws_dynamodb_table":              "cmdb_ci_cloud_database",
+	"google_sql_database_instance":    "cmdb_ci_cloud_database",
+	"azurerm_mssql_database":          "cmdb_ci_cloud_database",
+	"aws_s3_bucket":                   "cmdb_ci_cloud_object_storage",
+	"google_storage_bucket":           "cmdb_ci_cloud_object_storage",
+	"azurerm_storage_account":         "cmdb_ci_cloud_object_storage",
+	"aws_ebs_volume":                  "cmdb_ci_storage_volume",
+	"aws_vpc":                         "cmdb_ci_network",
+	"google_compute_network":          "cmdb_ci_network",
+	"azurerm_virtual_network":         "cmdb_ci_network",
+	"aws_subnet":                      "cmdb_ci_cloud_subnet",
+	"google_compute_subnetwork":       "cmdb_ci_cloud_subnet",
+	"azurerm_subnet":                  "cmdb_ci_cloud_subnet",
+	"aws_security_group":              "cmdb_ci_compute_security_group",
+	"azurerm_network_security_group":  "cmdb_ci_compute_security_group",
+	"aws_lb":                          "cmdb_ci_cloud_load_balancer",
+	"aws_alb":                         "cmdb_ci_cloud_load_balancer",
+	"aws_elb":                         "cmdb_ci_cloud_load_balancer",
+	"azurerm_lb":                      "cmdb_ci_cloud_load_balancer",
+	"aws_lambda_function":             "cmdb_ci_cloud_function",
+	"google_cloudfunctions_function":  "cmdb_ci_cloud_function",
+	"azurerm_function_app":            "cmdb_ci_cloud_function",
+	"aws_eks_cluster":                 "cmdb_ci_kubernetes_cluster",
+	"google_container_cluster":        "cmdb_ci_kubernetes_cluster",
+	"azurerm_kubernetes_cluster":      "cmdb_ci_kubernetes_cluster",
+}
+
+// CMDBRecords maps the managed resource instances of a state to the records
+// of a CMDB import set. Records are named after their Name tag, or their
+// address, and correlated by their lineage and address, which stay the same
+// across versions of the state even when the resource is replaced.
+func CMDBRecords(state *models.TerraformState) []models.CMDBRecord {
+	graph := parser.BuildGraphWithOptions(state, parser.GraphOptions{InstanceEdges: true})
+
+	refs := make(map[string]parser.InstanceRef)
+	correlations := make(map[string]string)
+	for _, ref := range parser.Instances(state) {
+		if ref.Resource.Mode != "managed" || ref.Instance.Deposed != "" {
+			continue
+		}
+
+		refs[ref.NodeID] = ref
+		correlations[ref.NodeID] = CorrelationID(state.Lineage, parser.InstanceAddress(ref.Resource, ref.Instance))
+	}
+
+	dependsOn := make(map[string][]string)
+	for _, edge := range graph.Edges {
+		if correlation, ok := correlations[edge.Target]; ok && edge.Source != edge.Target {
+			dependsOn[edge.Source] = append(dependsOn[edge.Source], correlation)
+		}
+	}
+
+	ids := make([]string, 0, len(refs))
+	for id := range refs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	records := make([]models.CMDBRecord, 0, len(ids))
+	for _, id := range ids {
+		ref := refs[id]
+		attrs := ref.Instance.Attributes
+		address := parser.InstanceAddress(ref.Resource, ref.Instance)
+
+		class := cmdbClasses[ref.Resource.Type]
+		if class == "" {
+			class = defaultCMDBClass
+		}
+
+		name := address
+		if tags, ok := attrs["tags"].(map[string]any); ok {
+			if value, _ := tags["Name"].(string); value != "" {
+				name = value
+			}
+		}
+
+		deps := dependsOn[id]
+		sort.Strings(deps)
+
+		objectID, _ := attrs["id"].(string)
+
+		records = append(records, models.CMDBRecord{
+			CorrelationID: correlations[id],
+			Class:         class,
+			Name:          name,
+			ObjectID:      objectID,
+			Address:       address,
+			ResourceType:  ref.Resource.Type,
+			Lineage:       state.Lineage,
+			Serial:        state.Serial,
+			Provider:      parser.ProviderSource(ref.Resource.Provider),
+			Region:        resourceRegion(attrs),
+			Module:        ref.Resource.Module,
+			DependsOn:     strings.Join(deps, ","),
+		})
+	}
+
+	return records
+}
+
+// CorrelationID returns the correlation ID of the configuration item of a
+// resource instance, by lineage and address.
+func CorrelationID(lineage, address string) string {
+	sum := sha256.Sum256([]byte(lineage + "\x00" + address))
+
+	return "terrascope-" + hex.EncodeToString(sum[:16])
+}
+
+// resourceRegion returns the region of a resource from its region attribute,
+// its ARN or its availability zone, or "" when none tells.
+func resourceRegion(attrs map[string]any) string {
+	if region, _ := attrs["region"].(string); region != "" {
+		return region
+	}
+
+	if arn, _ := attrs["arn"].(string); strings.HasPrefix(arn, "arn:") {
+		if parts := strings.SplitN(arn, ":", 5); len(parts) == 5 && parts[3] != "" {
+			return parts[3]
+		}
+	}
+
+	if zone, _ := attrs["availability_zone"].(string); len(zone) > 1 {
+		return zone[:len(zone)-1]
+	}
+
+	return ""
+}
+
+// ServiceNow pushes import sets to the staging table of a ServiceNow
+// instance, authenticating with basic auth.
+type ServiceNow struct {
+	InstanceURL string
+	Table       string
+	Username    string
+	Password    string
+	HTTPClient  *http.Client
+}
+
+// NewServiceNowFromEnv configures a client from TERRASCOPE_SERVICENOW_URL,
+// _TABLE, _USERNAME and _PASSWORD, and returns nil when no instance is
+// configured. The table defaults to u_terrascope_ci_import.
+func NewServiceNowFromEnv() *ServiceNow {
+	instance := os.Getenv("TERRASCOPE_SERVICENOW_URL")
+	if instance == "" {
+		return nil
+	}
+
+	table := os.Getenv("TERRASCOPE_SERVICENOW_TABLE")
+	if table == "" {
+		table = defaultServiceNowTable
+	}
+
+	return &ServiceNow{
+		InstanceURL: strings.TrimSuffix(instance, "/"),
+		Table:       table,
+		Username:    os.Getenv("TERRASCOPE_SERVICENOW_USERNAME"),
+		Password:    os.Getenv("TERRASCOPE_SERVICENOW_PASSWORD"),
+		HTTPClient:  &http.Client{Timeout: pushTimeout},
+	}
+}
+
+// Push inserts the records of an import set into the staging table, whose
+// transform map then creates or updates the configuration items.
+func (s *ServiceNow) Push(ctx context.Context, set *models.CMDBImportSet) (*models.CMDBPushResult, error) {
+	body, err := json.Marshal(set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode import set: %w", err)
+	}
+
+	endpoint := s.InstanceURL + "/api/now/import/" + url.PathEscape(s.Table) + "/insertMultiple"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build ServiceNow request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(s.Username, s.Password)
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ServiceNow request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("failed to close ServiceNow response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("ServiceNow returned %s", resp.Status)
+	}
+
+	var created struct {
+		ImportSetID  string `json:"import_set_id"`
+		StagingTable string `json:"staging_table"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return nil, fmt.Errorf("failed to decode ServiceNow response: %w", err)
+	}
+
+	table := created.StagingTable
+	if table == "" {
+		table = s.Table
+	}
+
+	return &models.CMDBPushResult{Table: table, ImportSetID: created.ImportSetID, Records: len(set.Records)}, nil
+}
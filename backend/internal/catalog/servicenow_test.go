@@ -0,0 +1,141 @@
+// Package catalog maps the resources of Terraform states to the entities of
+// external catalogs, such as the Backstage software catalog and the
+// ServiceNow CMDB, so that they can be surfaced next to the services that use
+// them.
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestCMDBRecords(t *testing.T) {
+	t.Run("maps resources to configuration items", func(t *testing.T) {
+		records := CMDBRecords(catalogState())
+
+		require.Len(t, records, 2, "data sources are left out")
+		vpc, subnet := records[0], records[1]
+
+		assert.Equal(t, models.CMDBRecord{
+			CorrelationID: CorrelationID("Prod-1", "aws_vpc.main"),
+			Class:         "cmdb_ci_network",
+			Name:          "aws_vpc.main",
+			ObjectID:      "vpc-1",
+			Address:       "aws_vpc.main",
+			ResourceType:  "aws_vpc",
+			Lineage:       "Prod-1",
+			Serial:        7,
+			Provider:      "registry.terraform.io/hashicorp/aws",
+		}, vpc)
+
+		assert.Equal(t, "cmdb_ci_cloud_subnet", subnet.Class)
+		assert.Equal(t, `module.network.aws_subnet.private["a"]`, subnet.Address)
+		assert.Equal(t, "module.network", subnet.Module)
+		assert.Equal(t, vpc.CorrelationID, subnet.DependsOn)
+	})
+
+	t.Run("keeps correlation IDs across versions", func(t *testing.T) {
+		state := catalogState()
+		first := CMDBRecords(state)
+
+		state.Serial++
+		state.Resources[0].Instances[0].Attributes["id"] = "vpc-2"
+		second := CMDBRecords(state)
+
+		assert.Equal(t, first[0].CorrelationID, second[0].CorrelationID, "replaced resources keep their item")
+		assert.Equal(t, "vpc-2", second[0].ObjectID)
+
+		state.Lineage = "Prod-2"
+		assert.NotEqual(t, first[0].CorrelationID, CMDBRecords(state)[0].CorrelationID)
+	})
+
+	t.Run("names items after their Name tag", func(t *testing.T) {
+		state := &models.TerraformState{Lineage: "prod", Resources: []models.ResourceState{{
+			Mode:     "managed",
+			Type:     "aws_route53_zone",
+			Name:     "main",
+			Provider: awsProvider,
+			Instances: []models.ResourceInstance{{Attributes: map[string]any{
+				"arn":  "arn:aws:route53:::hostedzone/Z1",
+				"tags": map[string]any{"Name": "example.com"},
+			}}},
+		}}}
+
+		records := CMDBRecords(state)
+
+		require.Len(t, records, 1)
+		assert.Equal(t, "example.com", records[0].Name)
+		assert.Equal(t, "cmdb_ci", records[0].Class, "unmapped types fall back to the base class")
+		assert.Empty(t, records[0].Region, "global resources have no region")
+	})
+}
+
+func TestResourceRegion(t *testing.T) {
+	assert.Equal(t, "us-east-1", resourceRegion(map[string]any{"region": "us-east-1"}))
+	assert.Equal(t, "eu-west-1", resourceRegion(map[string]any{"arn": "arn:aws:lambda:eu-west-1:123456789012:function:api"}))
+	assert.Equal(t, "eu-west-3", resourceRegion(map[string]any{"availability_zone": "eu-west-3a"}))
+	assert.Empty(t, resourceRegion(map[string]any{}))
+}
+
+func TestServiceNowPush(t *testing.T) {
+	set := &models.CMDBImportSet{Records: CMDBRecords(catalogState())}
+
+	t.Run("inserts the records into the staging table", func(t *testing.T) {
+		var received models.CMDBImportSet
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/api/now/import/u_terrascope_ci_import/insertMultiple", r.URL.Path)
+
+			username, password, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "terrascope", username)
+			assert.Equal(t, "secret", password)
+
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"import_set_id": "ISET0010001", "staging_table": "u_terrascope_ci_import"}`))
+		}))
+		defer server.Close()
+
+		client := &ServiceNow{InstanceURL: server.URL, Table: defaultServiceNowTable, Username: "terrascope", Password: "secret", HTTPClient: server.Client()}
+
+		result, err := client.Push(context.Background(), set)
+
+		require.NoError(t, err)
+		assert.Equal(t, &models.CMDBPushResult{Table: "u_terrascope_ci_import", ImportSetID: "ISET0010001", Records: 2}, result)
+		assert.Equal(t, set.Records, received.Records)
+	})
+
+	t.Run("fails on error responses", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, `{"error": {"message": "User Not Authenticated"}}`, http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		client := &ServiceNow{InstanceURL: server.URL, Table: defaultServiceNowTable, HTTPClient: server.Client()}
+
+		_, err := client.Push(context.Background(), set)
+
+		assert.ErrorContains(t, err, "401")
+	})
+}
+
+func TestNewServiceNowFromEnv(t *testing.T) {
+	t.Setenv("TERRASCOPE_SERVICENOW_URL", "")
+	assert.Nil(t, NewServiceNowFromEnv())
+
+	t.Setenv("TERRASCOPE_SERVICENOW_URL", "https://example.service-now.com/")
+	t.Setenv("TERRASCOPE_SERVICENOW_TABLE", "u_cloud_import")
+	client := NewServiceNowFromEnv()
+
+	require.NotNil(t, client)
+	assert.Equal(t, "https://example.service-now.com", client.InstanceURL)
+	assert.Equal(t, "u_cloud_import", client.Table)
+}
@@ -0,0 +1,208 @@
+// Package codes catalogs the stable codes carried by the warnings, findings
+// and validation issues Terrascope reports, so that automation can match them and clients
+// can translate them without parsing messages.
+package codes
+
+import (
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// catalog lists the codes in code order. Codes are never reused: a retired
+// warning or check keeps its code out of the catalog.
+var catalog = []models.CodeInfo{
+	{Code: "TS101", Kind: models.CodeKindWarning, Source: "check",
+		Description: "A precondition, postcondition or check block failed or errored on the last apply",
+		Remediation: "fix the resource or the condition, then apply again"},
+	{Code: "TS102", Kind: models.CodeKindWarning, Source: "plan_unmatched",
+		Description: "The plan changes a resource that is not in the state",
+		Remediation: "plan against the state the graph was built from"},
+
+	{Code: "TS201", Kind: models.CodeKindWarning, Source: "missing_constraint",
+		Description: "A provider used by the state has no required_providers entry",
+		Remediation: "add a version constraint for it to required_providers"},
+	{Code: "TS202", Kind: models.CodeKindWarning, Source: "invalid_constraint",
+		Description: "A required_providers version constraint cannot be parsed",
+		Remediation: "correct the constraint syntax"},
+	{Code: "TS203", Kind: models.CodeKindWarning, Source: "constraint_mismatch",
+		Description: "The provider version selected in the lock file does not satisfy its constraint",
+		Remediation: "run terraform init -upgrade, or relax the constraint"},
+	{Code: "TS204", Kind: models.CodeKindWarning, Source: "unlocked_provider",
+		Description: "A provider used by the state has no version selected in the lock file",
+		Remediation: "run terraform init and commit .terraform.lock.hcl"},
+	{Code: "TS205", Kind: models.CodeKindWarning, Source: "outdated_provider",
+		Description: "The selected provider version is a major version or more behind the latest release",
+		Remediation: "plan an upgrade to the latest major version"},
+	{Code: "TS206", Kind: models.CodeKindWarning, Source: "registry_error",
+		Description: "The latest provider version could not be fetched from the registry",
+		Remediation: "retry later, or pass ?registry=false to skip the comparison"},
+	{Code: "TS207", Kind: models.CodeKindWarning, Source: "unused_locked_provider",
+		Description: "The lock file selects a provider the state does not use",
+		Remediation: "remove it from the configuration and run terraform init"},
+
+	{Code: "TS301", Kind: models.CodeKindWarning, Source: "at_limit",
+		Description: "A resource count has reached the default quota of its provider",
+		Remediation: "request a quota increase before adding resources"},
+	{Code: "TS302", Kind: models.CodeKindWarning, Source: "approaching_limit",
+		Description: "A resource count is close to the default quota of its provider",
+		Remediation: "request a quota increase or clean up unused resources"},
+	{Code: "TS303", Kind: models.CodeKindWarning, Source: "limit_forecast",
+		Description: "A resource count is projected to reach the default quota of its provider within the horizon",
+		Remediation: "request a quota increase ahead of time"},
+
+	{Code: "TS401", Kind: models.CodeKindWarning, Source: "protected_resource",
+		Description: "A resource that would be destroyed usually holds data that cannot be recovered",
+		Remediation: "back it up, or set prevent_destroy on it"},
+	{Code: "TS402", Kind: models.CodeKindWarning, Source: "unknown_target",
+		Description: "A destroy target is not a managed resource in the state",
+		Remediation: "check the resource address"},
+	{Code: "TS403", Kind: models.CodeKindWarning, Source: "dependency_cycle",
+		Description: "A resource is part of a dependency cycle, so its destroy order is undefined",
+		Remediation: "break the cycle in the configuration"},
+
+	{Code: "TS501", Kind: models.CodeKindFinding, Source: "security/open_ingress",
+		Description: "Ingress is open to the internet",
+		Remediation: "restrict the rule to known CIDR blocks or security groups"},
+	{Code: "TS502", Kind: models.CodeKindFinding, Source: "security/public_bucket",
+		Description: "The bucket ACL grants public access",
+		Remediation: "use a private ACL and a bucket policy for the principals that need access"},
+	{Code: "TS503", Kind: models.CodeKindFinding, Source: "security/public_database",
+		Description: "The database is publicly accessible",
+		Remediation: "set publicly_accessible to false and reach it from within the VPC"},
+	{Code: "TS504", Kind: models.CodeKindFinding, Source: "security/unencrypted_storage",
+		Description: "The storage is not encrypted at rest",
+		Remediation: "enable encryption; existing storage must be recreated"},
+
+	{Code: "TS511", Kind: models.CodeKindFinding, Source: "secrets/aws_access_key",
+		Description: "An attribute holds an AWS access key ID",
+		Remediation: "move the secret to a secret manager and rotate it"},
+	{Code: "TS512", Kind: models.CodeKindFinding, Source: "secrets/private_key",
+		Description: "An attribute holds a private key",
+		Remediation: "move the secret to a secret manager and rotate it"},
+	{Code: "TS513", Kind: models.CodeKindFinding, Source: "secrets/token",
+		Description: "An attribute holds an API token",
+		Remediation: "move the secret to a secret manager and rotate it"},
+	{Code: "TS514", Kind: models.CodeKindFinding, Source: "secrets/connection_string",
+		Description: "An attribute holds a connection string with a password",
+		Remediation: "move the secret to a secret manager and rotate it"},
+	{Code: "TS515", Kind: models.CodeKindFinding, Source: "secrets/high_entropy",
+		Description: "An attribute named like a secret holds a random-looking value",
+		Remediation: "move the secret to a secret manager and rotate it"},
+
+	{Code: "TS521", Kind: models.CodeKindFinding, Source: "stale/tainted",
+		Description: "The instance is tainted and will be replaced on the next apply",
+		Remediation: "apply to replace it, or run terraform untaint if it is healthy"},
+	{Code: "TS522", Kind: models.CodeKindFinding, Source: "stale/deposed",
+		Description: "A create_before_destroy replacement left a deposed object behind",
+		Remediation: "apply to destroy it"},
+
+	{Code: "TS531", Kind: models.CodeKindFinding, Source: "tags/untagged",
+		Description: "The resource has no tags",
+		Remediation: "add tags, or default_tags to its provider"},
+
+	{Code: "TS541", Kind: models.CodeKindFinding, Source: "unused/unused_data_source",
+		Description: "No managed resource or output uses the data source",
+		Remediation: "remove the data block unless the configuration reads it elsewhere"},
+
+	{Code: "TS551", Kind: models.CodeKindFinding, Source: "naming/*",
+		Description: "A resource name does not match a rule of the naming policy",
+		Remediation: "rename the resource to match the rule"},
+
+	{Code: "TS601", Kind: models.CodeKindValidation, Source: "empty_document",
+		Description: "The tfstate document is empty",
+		Remediation: "send the content of the state file"},
+	{Code: "TS602", Kind: models.CodeKindValidation, Source: "invalid_json",
+		Description: "The tfstate document is not valid JSON or does not match the state schema",
+		Remediation: "send the state file as written by Terraform or terraform state pull"},
+	{Code: "TS603", Kind: models.CodeKindValidation, Source: "missing_version",
+		Description: "The state has no format version",
+		Remediation: "send a state written by Terraform 0.12 or later"},
+	{Code: "TS604", Kind: models.CodeKindValidation, Source: "unsupported_version",
+		Description: "The state format version is not supported",
+		Remediation: "upgrade the state with Terraform 0.12 or later"},
+	{Code: "TS605", Kind: models.CodeKindValidation, Source: "missing_terraform_version",
+		Description: "The state does not record the Terraform version that wrote it",
+		Remediation: "send the state file as written by Terraform"},
+	{Code: "TS606", Kind: models.CodeKindValidation, Source: "missing_lineage",
+		Description: "The state has no lineage, so its versions cannot be correlated",
+		Remediation: "send the state file as written by Terraform"},
+
+	{Code: "TS611", Kind: models.CodeKindValidation, Source: "invalid_mode",
+		Description: "A resource mode is neither managed nor data",
+		Remediation: "correct the mode of the resource"},
+	{Code: "TS612", Kind: models.CodeKindValidation, Source: "missing_field",
+		Description: "A resource is missing its type or name",
+		Remediation: "restore the resource from an earlier version of the state"},
+	{Code: "TS613", Kind: models.CodeKindValidation, Source: "missing_provider",
+		Description: "A resource is missing its provider configuration",
+		Remediation: "restore the resource from an earlier version of the state"},
+	{Code: "TS614", Kind: models.CodeKindValidation, Source: "duplicate_address",
+		Description: "A resource or instance address appears more than once",
+		Remediation: "remove the duplicate, keeping the entry Terraform last wrote"},
+	{Code: "TS615", Kind: models.CodeKindValidation, Source: "no_instances",
+		Description: "A resource has no instances",
+		Remediation: "run terraform apply, or remove the resource with terraform state rm"},
+	{Code: "TS616", Kind: models.CodeKindValidation, Source: "dangling_reference",
+		Description: "A resource depends on a resource that is not in the state",
+		Remediation: "apply again so that Terraform records the current dependencies"},
+
+	{Code: "TS621", Kind: models.CodeKindValidation, Source: "missing_index_key",
+		Description: "A resource has several instances but one of them has no index key",
+		Remediation: "restore the resource from an earlier version of the state"},
+	{Code: "TS622", Kind: models.CodeKindValidation, Source: "missing_attributes",
+		Description: "An instance has no attributes",
+		Remediation: "run terraform apply -refresh-only to record them"},
+}
+
+var (
+	byCode    = make(map[string]models.CodeInfo, len(catalog))
+	bySource  = make(map[string]string, len(catalog))
+	wildcards = make(map[string]string)
+)
+
+func init() {
+	for _, info := range catalog {
+		byCode[info.Code] = info
+
+		if analyzer, ok := strings.CutSuffix(info.Source, "/*"); ok && info.Kind == models.CodeKindFinding {
+			wildcards[analyzer] = info.Code
+			continue
+		}
+		bySource[info.Kind+" "+info.Source] = info.Code
+	}
+}
+
+// Catalog returns every code, in code order.
+func Catalog() []models.CodeInfo {
+	return append([]models.CodeInfo(nil), catalog...)
+}
+
+// Lookup returns the description of a code.
+func Lookup(code string) (models.CodeInfo, bool) {
+	info, ok := byCode[code]
+	return info, ok
+}
+
+// ForWarning returns the code of the warnings of a type, or "" when the
+// catalog has none.
+func ForWarning(warningType string) string {
+	return bySource[models.CodeKindWarning+" "+warningType]
+}
+
+// ForValidation returns the code of the validation issues of a type, or ""
+// when the catalog has none.
+func ForValidation(issueType string) string {
+	return bySource[models.CodeKindValidation+" "+issueType]
+}
+
+// ForFinding returns the code of the findings of an analyzer's check, or ""
+// for the checks of analyzers the catalog does not know, such as analyzers
+// registered by embedders.
+func ForFinding(analyzer, check string) string {
+	if code, ok := bySource[models.CodeKindFinding+" "+analyzer+"/"+check]; ok {
+		return code
+	}
+
+	return wildcards[analyzer]
+}
@@ -0,0 +1,68 @@
+// Package codes catalogs the stable codes carried by the warnings and
+// findings Terrascope reports, so that automation can match them and clients
+// can translate them without parsing messages.
+package codes
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestCatalog(t *testing.T) {
+	format := regexp.MustCompile(`^TS[1-9][0-9]{2}$`)
+
+	catalog := Catalog()
+	seen := make(map[string]bool)
+	for _, info := range catalog {
+		assert.Regexp(t, format, info.Code)
+		assert.False(t, seen[info.Code], "%s is listed once", info.Code)
+		seen[info.Code] = true
+
+		assert.Contains(t, []string{models.CodeKindWarning, models.CodeKindFinding, models.CodeKindValidation}, info.Kind, info.Code)
+		assert.NotEmpty(t, info.Source, info.Code)
+		assert.NotEmpty(t, info.Description, info.Code)
+	}
+
+	assert.True(t, slices.IsSortedFunc(catalog, func(a, b models.CodeInfo) int {
+		return strings.Compare(a.Code, b.Code)
+	}), "the catalog is in code order")
+
+	catalog[0].Code = "changed"
+	assert.NotEqual(t, "changed", Catalog()[0].Code, "the catalog is a copy")
+}
+
+func TestLookup(t *testing.T) {
+	info, ok := Lookup("TS102")
+	assert.True(t, ok)
+	assert.Equal(t, "plan_unmatched", info.Source)
+
+	_, ok = Lookup("TS999")
+	assert.False(t, ok)
+}
+
+func TestForWarning(t *testing.T) {
+	assert.Equal(t, "TS101", ForWarning("check"))
+	assert.Equal(t, "TS403", ForWarning("dependency_cycle"))
+	assert.Empty(t, ForWarning("security/open_ingress"), "finding sources are not warning types")
+	assert.Empty(t, ForWarning("unknown"))
+}
+
+func TestForValidation(t *testing.T) {
+	assert.Equal(t, "TS603", ForValidation("missing_version"))
+	assert.Equal(t, "TS616", ForValidation("dangling_reference"))
+	assert.Empty(t, ForValidation("missing_constraint"), "warning types are not issue types")
+	assert.Empty(t, ForValidation("unknown"))
+}
+
+func TestForFinding(t *testing.T) {
+	assert.Equal(t, "TS501", ForFinding("security", "open_ingress"))
+	assert.Equal(t, "TS522", ForFinding("stale", "deposed"))
+	assert.Equal(t, "TS551", ForFinding("naming", "any rule"), "wildcards match every check")
+	assert.Empty(t, ForFinding("security", "unknown"))
+	assert.Empty(t, ForFinding("custom", "check"))
+}
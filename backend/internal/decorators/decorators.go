@@ -0,0 +1,478 @@
+// Package decorators runs user-supplied scripts against every node of a graph
+// to add metadata, drop nodes or add edges. Scripts are written in Starlark,
+// the Python dialect used by Bazel, and run sandboxed: they can only reach the
+// node they are given, and every run is bounded in steps and time.
+package decorators
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/terrascope/core/internal/models"
+	"go.starlark.net/resolve"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+)
+
+// maxSteps bounds the interpreter steps of the top level of a script and of
+// each call to its decorate function.
+const maxSteps = 1_000_000
+
+// maxDepth bounds how deeply the values stored in metadata can nest.
+const maxDepth = 64
+
+// DefaultEdgeType is the type of the edges added without one.
+const DefaultEdgeType = "decorator"
+
+var (
+	// ErrTimeout is returned when a script runs past its deadline.
+	ErrTimeout = errors.New("script timed out")
+	// ErrTooManySteps is returned when a script runs more steps than it is
+	// allowed.
+	ErrTooManySteps = errors.New("script exceeded its step limit")
+)
+
+// fileOptions are the Starlark dialect of scripts: the standard language,
+// without top-level loops, recursion or sets.
+var fileOptions = &syntax.FileOptions{}
+
+// Decorator is a compiled script whose decorate(node) function is called for
+// every node of a graph.
+type Decorator struct {
+	Name     string
+	decorate *starlark.Function
+}
+
+// Compile parses a script and runs its top level, which must define a
+// decorate function taking the node. The globals of the script are frozen
+// once it has run, so that no call to decorate sees the changes of another.
+// The top level is bounded in steps, and by the deadline of ctx.
+func Compile(ctx context.Context, name, script string) (*Decorator, error) {
+	th, done := newThread(ctx, name)
+	defer done()
+
+	globals, err := starlark.ExecFileOptions(fileOptions, th, name, script, nodeBuiltins)
+	if err != nil {
+		return nil, scriptError(ctx, th, err)
+	}
+
+	decorate, ok := globals["decorate"].(*starlark.Function)
+	if !ok {
+		return nil, errors.New("script must define a decorate(node) function")
+	}
+	if decorate.NumParams() != 1 || decorate.HasVarargs() || decorate.HasKwargs() {
+		return nil, fmt.Errorf("decorate must take 1 argument, not %d", decorate.NumParams())
+	}
+
+	return &Decorator{Name: name, decorate: decorate}, nil
+}
+
+// Apply calls decorate on every node of the graph, in order, and returns a
+// copy of the graph with the changes it asked for; the graph itself is left
+// untouched. The node has the fields id, type, mode, provider, module, status
+// and address, and a metadata dict. Within decorate, the script can call:
+//
+//   - set_metadata(key, value) to set a metadata entry of the node,
+//   - drop() to remove the node and its edges from the graph,
+//   - add_edge(target, type) to add an edge from the node to the node with
+//     ID target, of DefaultEdgeType unless typed; edges to nodes not in the
+//     decorated graph are left out.
+//
+// Each call is bounded in steps, and the whole run by the deadline of ctx.
+func (d *Decorator) Apply(ctx context.Context, graph *models.Graph) (*models.Graph, error) {
+	dropped := make(map[string]bool)
+	metadata := make(map[string]map[string]any)
+	var added []models.Edge
+
+	for _, node := range graph.Nodes {
+		changes := &nodeChanges{id: node.ID, set: make(map[string]any)}
+
+		if err := d.call(ctx, graph, node, changes); err != nil {
+			return nil, fmt.Errorf("node %s: %w", node.ID, err)
+		}
+
+		if changes.dropped {
+			dropped[node.ID] = true
+		}
+		if len(changes.set) > 0 {
+			metadata[node.ID] = changes.set
+		}
+		added = append(added, changes.edges...)
+	}
+
+	decorated := *graph
+	decorated.Stats = nil
+	decorated.Nodes = make([]models.Node, 0, len(graph.Nodes))
+	decorated.Edges = make([]models.Edge, 0, len(graph.Edges)+len(added))
+
+	for _, node := range graph.Nodes {
+		if dropped[node.ID] {
+			continue
+		}
+
+		if set, ok := metadata[node.ID]; ok {
+			merged := make(map[string]any, len(node.Metadata)+len(set))
+			for key, v := range node.Metadata {
+				merged[key] = v
+			}
+			for key, v := range set {
+				merged[key] = v
+			}
+			node.Metadata = merged
+		}
+
+		decorated.Nodes = append(decorated.Nodes, node)
+	}
+
+	kept := make(map[string]bool, len(decorated.Nodes))
+	for _, node := range decorated.Nodes {
+		kept[node.ID] = true
+	}
+
+	type edgeKey struct{ source, target, typ string }
+	seen := make(map[edgeKey]bool, len(graph.Edges))
+
+	for _, edge := range graph.Edges {
+		if kept[edge.Source] && kept[edge.Target] {
+			decorated.Edges = append(decorated.Edges, edge)
+			seen[edgeKey{edge.Source, edge.Target, edge.Type}] = true
+		}
+	}
+
+	for _, edge := range added {
+		key := edgeKey{edge.Source, edge.Target, edge.Type}
+		if kept[edge.Source] && kept[edge.Target] && !seen[key] {
+			edge.Metadata = map[string]any{"decorator": d.Name}
+			decorated.Edges = append(decorated.Edges, edge)
+			seen[key] = true
+		}
+	}
+
+	if graph.Addresses != nil && len(dropped) > 0 {
+		decorated.Addresses = make(map[string]models.NodeAddress, len(graph.Addresses))
+		for id, address := range graph.Addresses {
+			if !dropped[id] {
+				decorated.Addresses[id] = address
+			}
+		}
+	}
+
+	return &decorated, nil
+}
+
+// call calls decorate on a node on a thread of its own, recording the
+// changes it asks for.
+func (d *Decorator) call(ctx context.Context, graph *models.Graph, node models.Node, changes *nodeChanges) error {
+	th, done := newThread(ctx, d.Name)
+	defer done()
+
+	th.SetLocal(changesKey, changes)
+
+	_, err := starlark.Call(th, d.decorate, starlark.Tuple{nodeValue(graph, node)}, nil)
+
+	return scriptError(ctx, th, err)
+}
+
+// newThread returns a thread bounded in steps and cancelled when ctx is done.
+// Scripts cannot load modules, and what they print is discarded. done stops
+// watching ctx.
+func newThread(ctx context.Context, name string) (th *starlark.Thread, done func()) {
+	th = &starlark.Thread{Name: name, Print: func(*starlark.Thread, string) {}}
+	th.SetMaxExecutionSteps(maxSteps)
+
+	if ctx.Err() != nil {
+		th.Cancel(ErrTimeout.Error())
+	}
+	stop := context.AfterFunc(ctx, func() { th.Cancel(ErrTimeout.Error()) })
+
+	return th, func() { stop() }
+}
+
+// scriptError reports where a script failed, with the position of the
+// innermost call that has one, and which limit stopped it.
+func scriptError(ctx context.Context, th *starlark.Thread, err error) error {
+	var (
+		syntaxErr  syntax.Error
+		resolveErr resolve.ErrorList
+		evalErr    *starlark.EvalError
+	)
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("%d:%d: %s", syntaxErr.Pos.Line, syntaxErr.Pos.Col, syntaxErr.Msg)
+	case errors.As(err, &resolveErr) && len(resolveErr) > 0:
+		return fmt.Errorf("%d:%d: %s", resolveErr[0].Pos.Line, resolveErr[0].Pos.Col, resolveErr[0].Msg)
+	case !errors.As(err, &evalErr):
+		return err
+	}
+
+	var cause error = errors.New(evalErr.Msg)
+	switch {
+	case th.ExecutionSteps() >= maxSteps:
+		cause = ErrTooManySteps
+	case ctx.Err() != nil:
+		cause = ErrTimeout
+	}
+
+	for i := range evalErr.CallStack {
+		if pos := evalErr.CallStack.At(i).Pos; pos.Line > 0 {
+			return fmt.Errorf("%d:%d: %w", pos.Line, pos.Col, cause)
+		}
+	}
+
+	return cause
+}
+
+// changesKey is the thread-local key of the changes a call to decorate asks
+// for.
+const changesKey = "changes"
+
+// nodeChanges are the changes a call to decorate asks for.
+type nodeChanges struct {
+	id      string
+	set     map[string]any
+	dropped bool
+	edges   []models.Edge
+}
+
+// nodeBuiltins are the functions through which a script changes the node
+// given to decorate.
+var nodeBuiltins = starlark.StringDict{
+	"set_metadata": starlark.NewBuiltin("set_metadata", setMetadata),
+	"drop":         starlark.NewBuiltin("drop", drop),
+	"add_edge":     starlark.NewBuiltin("add_edge", addEdge),
+}
+
+// changesOf returns the changes of the call to decorate running on a thread.
+func changesOf(th *starlark.Thread, b *starlark.Builtin) (*nodeChanges, error) {
+	changes, ok := th.Local(changesKey).(*nodeChanges)
+	if !ok {
+		return nil, fmt.Errorf("%s: can only be called within decorate", b.Name())
+	}
+
+	return changes, nil
+}
+
+func setMetadata(th *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		key   string
+		value starlark.Value
+	)
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "key", &key, "value", &value); err != nil {
+		return nil, err
+	}
+
+	changes, err := changesOf(th, b)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := toGo(value, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+
+	changes.set[key] = v
+
+	return starlark.None, nil
+}
+
+func drop(th *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs); err != nil {
+		return nil, err
+	}
+
+	changes, err := changesOf(th, b)
+	if err != nil {
+		return nil, err
+	}
+
+	changes.dropped = true
+
+	return starlark.None, nil
+}
+
+func addEdge(th *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	target, edgeType := "", DefaultEdgeType
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "target", &target, "type?", &edgeType); err != nil {
+		return nil, err
+	}
+
+	changes, err := changesOf(th, b)
+	if err != nil {
+		return nil, err
+	}
+
+	changes.edges = append(changes.edges, models.Edge{Source: changes.id, Target: target, Type: edgeType})
+
+	return starlark.None, nil
+}
+
+// nodeValue returns the node as given to decorate.
+func nodeValue(graph *models.Graph, node models.Node) *starlarkstruct.Struct {
+	metadata := starlark.NewDict(len(node.Metadata))
+
+	keys := make([]string, 0, len(node.Metadata))
+	for key := range node.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		_ = metadata.SetKey(starlark.String(key), fromGo(node.Metadata[key]))
+	}
+
+	var address starlark.Value = starlark.None
+	if graph.Addresses != nil {
+		if a, ok := graph.Addresses[node.ID]; ok {
+			address = starlark.String(a.Terraform)
+		}
+	}
+
+	return starlarkstruct.FromStringDict(starlark.String("node"), starlark.StringDict{
+		"id":       starlark.String(node.ID),
+		"type":     starlark.String(node.Type),
+		"mode":     starlark.String(node.Mode),
+		"provider": starlark.String(node.Provider),
+		"module":   starlark.String(node.Module),
+		"status":   starlark.String(node.Status),
+		"address":  address,
+		"metadata": metadata,
+	})
+}
+
+// fromGo converts metadata to script values. Whole numbers become ints, as
+// numbers decoded from JSON are all floats.
+func fromGo(v any) starlark.Value {
+	switch v := v.(type) {
+	case nil:
+		return starlark.None
+	case bool:
+		return starlark.Bool(v)
+	case string:
+		return starlark.String(v)
+	case int:
+		return starlark.MakeInt(v)
+	case int64:
+		return starlark.MakeInt64(v)
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return starlark.MakeInt64(int64(v))
+		}
+		return starlark.Float(v)
+	case []any:
+		elems := make([]starlark.Value, len(v))
+		for i, elem := range v {
+			elems[i] = fromGo(elem)
+		}
+		return starlark.NewList(elems)
+	case []string:
+		elems := make([]starlark.Value, len(v))
+		for i, elem := range v {
+			elems[i] = starlark.String(elem)
+		}
+		return starlark.NewList(elems)
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		d := starlark.NewDict(len(v))
+		for _, key := range keys {
+			_ = d.SetKey(starlark.String(key), fromGo(v[key]))
+		}
+		return d
+	}
+
+	// Other metadata, such as structs, is seen as its JSON form.
+	data, err := json.Marshal(v)
+	if err != nil {
+		return starlark.None
+	}
+
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return starlark.None
+	}
+
+	return fromGo(decoded)
+}
+
+// toGo converts a script value to metadata, which can only hold JSON values:
+// None, bools, numbers, strings, lists, tuples and dicts keyed by strings.
+// Ints must fit in 64 bits.
+func toGo(v starlark.Value, depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, errors.New("value too deeply nested")
+	}
+
+	convert := func(iterable starlark.Iterable) ([]any, error) {
+		var converted []any
+
+		iter := iterable.Iterate()
+		defer iter.Done()
+
+		var elem starlark.Value
+		for iter.Next(&elem) {
+			c, err := toGo(elem, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			converted = append(converted, c)
+		}
+
+		if converted == nil {
+			converted = []any{}
+		}
+
+		return converted, nil
+	}
+
+	switch v := v.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.Bool:
+		return bool(v), nil
+	case starlark.Int:
+		i, ok := v.Int64()
+		if !ok {
+			return nil, fmt.Errorf("cannot store %s in metadata: int too large", v)
+		}
+		return i, nil
+	case starlark.Float:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return nil, fmt.Errorf("cannot store %s in metadata", v)
+		}
+		return float64(v), nil
+	case starlark.String:
+		return string(v), nil
+	case *starlark.List:
+		return convert(v)
+	case starlark.Tuple:
+		return convert(v)
+	case *starlark.Dict:
+		converted := make(map[string]any, v.Len())
+		for _, item := range v.Items() {
+			k, ok := item[0].(starlark.String)
+			if !ok {
+				return nil, fmt.Errorf("metadata keys must be strings, not %s", item[0].Type())
+			}
+			var err error
+			if converted[string(k)], err = toGo(item[1], depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return converted, nil
+	}
+
+	return nil, fmt.Errorf("cannot store %s in metadata", v.Type())
+}
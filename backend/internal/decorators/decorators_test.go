@@ -0,0 +1,216 @@
+// Package decorators runs user-supplied scripts against every node of a graph
+// to add metadata, drop nodes or add edges. Scripts are written in Starlark,
+// the Python dialect used by Bazel, and run sandboxed: they can only reach the
+// node they are given, and every run is bounded in steps and time.
+package decorators
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func testGraph() *models.Graph {
+	return &models.Graph{
+		Nodes: []models.Node{
+			{ID: "aws_instance.web", Type: "aws_instance", Mode: "managed", Metadata: map[string]any{
+				"tags": map[string]any{"Team": "payments"}, "index_key": 0.0,
+			}},
+			{ID: "aws_security_group.web", Type: "aws_security_group", Mode: "managed"},
+			{ID: "null_resource.hook", Type: "null_resource", Mode: "managed"},
+		},
+		Edges: []models.Edge{
+			{Source: "aws_instance.web", Target: "aws_security_group.web", Type: "implicit"},
+			{Source: "null_resource.hook", Target: "aws_instance.web", Type: "explicit"},
+		},
+		Addresses: map[string]models.NodeAddress{
+			"aws_instance.web":   {Terraform: "aws_instance.web[0]"},
+			"null_resource.hook": {Terraform: "null_resource.hook"},
+		},
+		Stats: &models.Stats{TotalNodes: 3},
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		err    string
+	}{
+		{"valid", "def decorate(node):\n    pass\n", ""},
+		{"syntax error", "def decorate(node)\n", "2:1: got newline, want ':'"},
+		{"no decorate", "x = 1\n", "script must define a decorate(node) function"},
+		{"decorate not a function", "decorate = 1\n", "script must define a decorate(node) function"},
+		{"wrong arity", "def decorate(a, b):\n    pass\n", "decorate must take 1 argument, not 2"},
+		{"top-level failure", "x = fail(\"no\")\ndef decorate(node):\n    pass\n", "1:9: fail: no"},
+		{"top-level loop", "for x in []:\n    pass\n", "1:1: for loop not within a function"},
+		{"load", "load(\"lib.star\", \"x\")\n", "1:1: load not implemented by this application"},
+		{"node builtin at top level", "x = drop()\n", "1:9: drop: can only be called within decorate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := Compile(context.Background(), "test", tt.script)
+
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "test", d.Name)
+		})
+	}
+
+	t.Run("deadline", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := Compile(ctx, "test", "def decorate(node):\n    pass\n")
+		assert.ErrorIs(t, err, ErrTimeout)
+	})
+}
+
+func TestApply(t *testing.T) {
+	d, err := Compile(context.Background(), "ownership", `
+OWNERS = {"aws_security_group": "network"}
+
+def decorate(node):
+    if node.type.startswith("null_"):
+        drop()
+        return
+    team = node.metadata.get("tags", {}).get("Team") or OWNERS.get(node.type, "unknown")
+    set_metadata("team", team)
+    set_metadata("labels", {"address": node.address, "index": node.metadata.get("index_key")})
+    if node.type == "aws_security_group":
+        add_edge("aws_instance.web", "protects")
+        add_edge("missing.node")
+`)
+	require.NoError(t, err)
+
+	graph := testGraph()
+	decorated, err := d.Apply(context.Background(), graph)
+	require.NoError(t, err)
+
+	require.Len(t, decorated.Nodes, 2)
+	assert.Equal(t, "payments", decorated.Nodes[0].Metadata["team"])
+	assert.Equal(t, map[string]any{"address": "aws_instance.web[0]", "index": int64(0)}, decorated.Nodes[0].Metadata["labels"])
+	assert.Equal(t, map[string]any{"Team": "payments"}, decorated.Nodes[0].Metadata["tags"], "existing metadata is kept")
+	assert.Equal(t, "network", decorated.Nodes[1].Metadata["team"])
+
+	assert.Equal(t, []models.Edge{
+		{Source: "aws_instance.web", Target: "aws_security_group.web", Type: "implicit"},
+		{Source: "aws_security_group.web", Target: "aws_instance.web", Type: "protects", Metadata: map[string]any{"decorator": "ownership"}},
+	}, decorated.Edges)
+	assert.NotContains(t, decorated.Addresses, "null_resource.hook")
+	assert.Nil(t, decorated.Stats)
+
+	assert.Len(t, graph.Nodes, 3, "the graph is left untouched")
+	assert.NotContains(t, graph.Nodes[0].Metadata, "team")
+	assert.Len(t, graph.Edges, 2)
+	assert.Contains(t, graph.Addresses, "null_resource.hook")
+}
+
+func TestApplyIsolatesNodes(t *testing.T) {
+	d, err := Compile(context.Background(), "leak", `
+SEEN = []
+
+def decorate(node):
+    SEEN.append(node.id)
+`)
+	require.NoError(t, err)
+
+	_, err = d.Apply(context.Background(), testGraph())
+	assert.EqualError(t, err, "node aws_instance.web: 5:16: append: cannot append to frozen list")
+}
+
+func TestApplyErrors(t *testing.T) {
+	t.Run("script failure", func(t *testing.T) {
+		d, err := Compile(context.Background(), "strict", `
+def decorate(node):
+    if not node.metadata.get("tags"):
+        fail("untagged", node.id)
+`)
+		require.NoError(t, err)
+
+		_, err = d.Apply(context.Background(), testGraph())
+		assert.EqualError(t, err, "node aws_security_group.web: 4:13: fail: untagged aws_security_group.web")
+	})
+
+	t.Run("unsupported metadata", func(t *testing.T) {
+		d, err := Compile(context.Background(), "bad", "def decorate(node):\n    set_metadata(\"fn\", decorate)\n")
+		require.NoError(t, err)
+
+		_, err = d.Apply(context.Background(), testGraph())
+		assert.ErrorContains(t, err, "cannot store function in metadata")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		d, err := Compile(context.Background(), "slow", "def decorate(node):\n    pass\n")
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err = d.Apply(ctx, testGraph())
+		assert.ErrorIs(t, err, ErrTimeout)
+	})
+
+	t.Run("ints that do not fit metadata", func(t *testing.T) {
+		d, err := Compile(context.Background(), "big", "def decorate(node):\n    set_metadata(\"n\", 9223372036854775807 + 1)\n")
+		require.NoError(t, err)
+
+		_, err = d.Apply(context.Background(), testGraph())
+		assert.ErrorContains(t, err, "cannot store 9223372036854775808 in metadata: int too large")
+
+		d, err = Compile(context.Background(), "exact", "def decorate(node):\n    set_metadata(\"n\", 9223372036854775807 + 1 - 1)\n")
+		require.NoError(t, err)
+
+		decorated, err := d.Apply(context.Background(), testGraph())
+		require.NoError(t, err)
+		assert.Equal(t, int64(math.MaxInt64), decorated.Nodes[0].Metadata["n"], "ints do not wrap")
+	})
+
+	t.Run("mutation during iteration", func(t *testing.T) {
+		d, err := Compile(context.Background(), "mutate", `
+def decorate(node):
+    for key in node.metadata:
+        node.metadata[key + "_copy"] = 1
+`)
+		require.NoError(t, err)
+
+		_, err = d.Apply(context.Background(), testGraph())
+		assert.EqualError(t, err, "node aws_instance.web: 4:22: cannot insert into hash table during iteration")
+	})
+
+	t.Run("recursion", func(t *testing.T) {
+		d, err := Compile(context.Background(), "recursive", `
+def visit(n):
+    return visit(n - 1)
+
+def decorate(node):
+    visit(3)
+`)
+		require.NoError(t, err)
+
+		_, err = d.Apply(context.Background(), testGraph())
+		assert.ErrorContains(t, err, "called recursively")
+	})
+
+	t.Run("step budget", func(t *testing.T) {
+		d, err := Compile(context.Background(), "busy", `
+def decorate(node):
+    for i in range(1000):
+        for j in range(1000):
+            pass
+`)
+		require.NoError(t, err)
+
+		_, err = d.Apply(context.Background(), testGraph())
+		assert.ErrorIs(t, err, ErrTooManySteps)
+	})
+}
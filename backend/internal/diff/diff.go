@@ -0,0 +1,356 @@
+// Package diff compares Terraform states and reports how resources changed
+// between two versions.
+package diff
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+const (
+	matchedByID         = "id"
+	matchedByAttributes = "attributes"
+)
+
+// CheckBackup checks that backup can be the state Terraform saved as
+// terraform.tfstate.backup before writing state: both share a lineage and the
+// backup is not newer than the state.
+func CheckBackup(backup, state *models.TerraformState) error {
+	if backup.Lineage != state.Lineage {
+		return fmt.Errorf("backup lineage %q differs from state lineage %q", backup.Lineage, state.Lineage)
+	}
+
+	if backup.Serial > state.Serial {
+		return fmt.Errorf("backup serial %d is newer than state serial %d", backup.Serial, state.Serial)
+	}
+
+	return nil
+}
+
+// States compares two states instance by instance. Instances present only in
+// one state are matched against each other to detect `terraform state mv`
+// operations, which are reported as renames instead of a delete and create.
+func States(before, after *models.TerraformState) *models.StateDiff {
+	result := &models.StateDiff{
+		Added:    []string{},
+		Removed:  []string{},
+		Modified: []models.ResourceChange{},
+		Renamed:  []models.Rename{},
+	}
+
+	old := indexInstances(before)
+	current := indexInstances(after)
+
+	var removed, added []parser.InstanceRef
+
+	for _, id := range sortedKeys(old) {
+		prev := old[id]
+		next, ok := current[id]
+		if !ok {
+			removed = append(removed, prev)
+			continue
+		}
+
+		if changed := changedAttributes(prev.Instance.Attributes, next.Instance.Attributes); len(changed) > 0 {
+			result.Modified = append(result.Modified, models.ResourceChange{ID: id, ChangedAttributes: changed})
+		}
+	}
+
+	for _, id := range sortedKeys(current) {
+		if _, ok := old[id]; !ok {
+			added = append(added, current[id])
+		}
+	}
+
+	result.Renamed = detectRenames(removed, added)
+
+	movedFrom := make(map[string]bool, len(result.Renamed))
+	movedTo := make(map[string]bool, len(result.Renamed))
+	for _, rename := range result.Renamed {
+		movedFrom[rename.From] = true
+		movedTo[rename.To] = true
+	}
+
+	for _, ref := range removed {
+		if !movedFrom[ref.NodeID] {
+			result.Removed = append(result.Removed, ref.NodeID)
+		}
+	}
+
+	for _, ref := range added {
+		if !movedTo[ref.NodeID] {
+			result.Added = append(result.Added, ref.NodeID)
+		}
+	}
+
+	return result
+}
+
+// Graph merges the dependency graphs of two states. Nodes and edges of the
+// later state come first, followed by those only the earlier one has. Renamed
+// nodes keep their later ID, and the edges of the earlier state are moved to
+// it, so that a rename alone changes no edge. Every node records the state it
+// comes from.
+func Graph(before, after *models.TerraformState) *models.DiffGraph {
+	return MergeGraphs(before, after, parser.BuildGraph(before), parser.BuildGraph(after))
+}
+
+// MergeGraphs merges the dependency graphs already built from two states,
+// like Graph. The graphs are not modified.
+func MergeGraphs(before, after *models.TerraformState, old, current *models.Graph) *models.DiffGraph {
+	changes := States(before, after)
+
+	modified := make(map[string][]string, len(changes.Modified))
+	for _, change := range changes.Modified {
+		modified[change.ID] = change.ChangedAttributes
+	}
+
+	renamedTo := make(map[string]string, len(changes.Renamed))
+	renamedFrom := make(map[string]models.Rename, len(changes.Renamed))
+	for _, rename := range changes.Renamed {
+		renamedTo[rename.From] = rename.To
+		renamedFrom[rename.To] = rename
+	}
+
+	moved := func(id string) string {
+		if to, ok := renamedTo[id]; ok {
+			return to
+		}
+		return id
+	}
+
+	oldNodes := make(map[string]bool, len(old.Nodes))
+	for _, node := range old.Nodes {
+		oldNodes[moved(node.ID)] = true
+	}
+
+	result := &models.DiffGraph{
+		Nodes: make([]models.DiffNode, 0, len(current.Nodes)),
+		Edges: make([]models.DiffEdge, 0, len(current.Edges)),
+	}
+
+	beforeSource := models.NodeSource{Lineage: before.Lineage, Serial: before.Serial}
+	afterSource := models.NodeSource{Lineage: after.Lineage, Serial: after.Serial}
+
+	currentNodes := make(map[string]bool, len(current.Nodes))
+	for _, node := range current.Nodes {
+		currentNodes[node.ID] = true
+		merged := models.DiffNode{Node: node, Change: models.ChangeUnchanged, Source: afterSource}
+
+		if rename, ok := renamedFrom[node.ID]; ok {
+			merged.Change = models.ChangeModified
+			merged.RenamedFrom = rename.From
+			merged.ChangedAttributes = rename.ChangedAttributes
+		} else if !oldNodes[node.ID] {
+			merged.Change = models.ChangeAdded
+		} else if changed, ok := modified[node.ID]; ok {
+			merged.Change = models.ChangeModified
+			merged.ChangedAttributes = changed
+		}
+
+		result.Nodes = append(result.Nodes, merged)
+	}
+
+	for _, node := range old.Nodes {
+		if !currentNodes[moved(node.ID)] {
+			result.Nodes = append(result.Nodes, models.DiffNode{Node: node, Change: models.ChangeRemoved, Source: beforeSource})
+		}
+	}
+
+	oldEdges := make(map[edgeKey]models.Edge, len(old.Edges))
+	for _, edge := range old.Edges {
+		edge.Source = moved(edge.Source)
+		edge.Target = moved(edge.Target)
+		if _, exists := oldEdges[keyOf(edge)]; !exists {
+			oldEdges[keyOf(edge)] = edge
+		}
+	}
+
+	currentEdges := make(map[edgeKey]bool, len(current.Edges))
+	for _, edge := range current.Edges {
+		currentEdges[keyOf(edge)] = true
+		merged := models.DiffEdge{Edge: edge, Change: models.ChangeAdded}
+
+		if prev, ok := oldEdges[keyOf(edge)]; ok {
+			merged.Change = models.ChangeUnchanged
+			if !reflect.DeepEqual(prev.Metadata, edge.Metadata) {
+				merged.Change = models.ChangeModified
+			}
+		}
+
+		result.Edges = append(result.Edges, merged)
+	}
+
+	for _, edge := range old.Edges {
+		edge.Source = moved(edge.Source)
+		edge.Target = moved(edge.Target)
+		if !currentEdges[keyOf(edge)] {
+			currentEdges[keyOf(edge)] = true
+			result.Edges = append(result.Edges, models.DiffEdge{Edge: edge, Change: models.ChangeRemoved})
+		}
+	}
+
+	return result
+}
+
+// Label labels the states the nodes of a merged graph come from, such as with
+// the names of their files.
+func Label(graph *models.DiffGraph, before, after string) {
+	for i := range graph.Nodes {
+		if graph.Nodes[i].Change == models.ChangeRemoved {
+			graph.Nodes[i].Source.Label = before
+		} else {
+			graph.Nodes[i].Source.Label = after
+		}
+	}
+}
+
+// edgeKey identifies an edge across the graphs of two states.
+type edgeKey struct {
+	source, target, kind string
+}
+
+func keyOf(edge models.Edge) edgeKey {
+	return edgeKey{source: edge.Source, target: edge.Target, kind: edge.Type}
+}
+
+// detectRenames pairs removed and added instances of the same type and mode,
+// first by their cloud ID and then by identical attributes. Added instances
+// are indexed by what they are matched on, so pairing takes linear time.
+func detectRenames(removed, added []parser.InstanceRef) []models.Rename {
+	renames := []models.Rename{}
+	used := make(map[string]bool)
+
+	match := func(matchedBy string, key func(ref parser.InstanceRef) (string, bool), same func(prev, next parser.InstanceRef) bool) {
+		candidates := make(map[renameKey][]parser.InstanceRef)
+		for _, next := range added {
+			if match, ok := key(next); ok && !used["to:"+next.NodeID] {
+				k := renameKeyOf(next, match)
+				candidates[k] = append(candidates[k], next)
+			}
+		}
+
+		for _, prev := range removed {
+			match, ok := key(prev)
+			if !ok || used["from:"+prev.NodeID] {
+				continue
+			}
+
+			// Matched candidates are dropped from the front of their queue,
+			// so each is skipped at most once.
+			k := renameKeyOf(prev, match)
+			queue := candidates[k]
+			for len(queue) > 0 && used["to:"+queue[0].NodeID] {
+				queue = queue[1:]
+			}
+			candidates[k] = queue
+
+			for _, next := range queue {
+				if used["to:"+next.NodeID] || !same(prev, next) {
+					continue
+				}
+
+				used["from:"+prev.NodeID] = true
+				used["to:"+next.NodeID] = true
+				renames = append(renames, models.Rename{
+					From:              prev.NodeID,
+					To:                next.NodeID,
+					Type:              next.Resource.Type,
+					MatchedBy:         matchedBy,
+					ChangedAttributes: changedAttributes(prev.Instance.Attributes, next.Instance.Attributes),
+				})
+
+				break
+			}
+		}
+	}
+
+	match(matchedByID, func(ref parser.InstanceRef) (string, bool) {
+		id, ok := ref.Instance.Attributes["id"].(string)
+		return id, ok && id != ""
+	}, func(prev, next parser.InstanceRef) bool {
+		return prev.Instance.Attributes["id"] == next.Instance.Attributes["id"]
+	})
+
+	match(matchedByAttributes, attributesHash, func(prev, next parser.InstanceRef) bool {
+		return reflect.DeepEqual(prev.Instance.Attributes, next.Instance.Attributes)
+	})
+
+	return renames
+}
+
+// renameKey groups the instances that can be renames of each other.
+type renameKey struct {
+	kind, mode, match string
+}
+
+func renameKeyOf(ref parser.InstanceRef, match string) renameKey {
+	return renameKey{kind: ref.Resource.Type, mode: ref.Resource.Mode, match: match}
+}
+
+// attributesHash hashes the attributes of an instance. Maps encode with sorted
+// keys, so identical attributes hash the same.
+func attributesHash(ref parser.InstanceRef) (string, bool) {
+	if len(ref.Instance.Attributes) == 0 {
+		return "", false
+	}
+
+	data, err := json.Marshal(ref.Instance.Attributes)
+	if err != nil {
+		return "", false
+	}
+
+	sum := sha256.Sum256(data)
+
+	return string(sum[:]), true
+}
+
+func indexInstances(state *models.TerraformState) map[string]parser.InstanceRef {
+	index := make(map[string]parser.InstanceRef)
+
+	for _, ref := range parser.Instances(state) {
+		if _, exists := index[ref.NodeID]; !exists {
+			index[ref.NodeID] = ref
+		}
+	}
+
+	return index
+}
+
+// changedAttributes lists the top-level attribute keys whose values differ.
+func changedAttributes(before, after map[string]any) []string {
+	changed := []string{}
+
+	for key, value := range before {
+		if next, ok := after[key]; !ok || !reflect.DeepEqual(value, next) {
+			changed = append(changed, key)
+		}
+	}
+
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+
+	sort.Strings(changed)
+
+	return changed
+}
+
+func sortedKeys(index map[string]parser.InstanceRef) []string {
+	keys := make([]string, 0, len(index))
+	for key := range index {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
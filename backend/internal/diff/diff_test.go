@@ -0,0 +1,228 @@
+// Package diff compares Terraform states and reports how resources changed
+// between two versions.
+package diff
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func resource(resType, name string, attrs map[string]any) models.ResourceState {
+	return models.ResourceState{
+		Mode:      "managed",
+		Type:      resType,
+		Name:      name,
+		Provider:  "provider[\"registry.terraform.io/hashicorp/aws\"]",
+		Instances: []models.ResourceInstance{{Attributes: attrs}},
+	}
+}
+
+func state(resources ...models.ResourceState) *models.TerraformState {
+	return &models.TerraformState{Version: 4, TerraformVersion: "1.5.0", Resources: resources}
+}
+
+func TestCheckBackup(t *testing.T) {
+	state := &models.TerraformState{Lineage: "abc-123", Serial: 5}
+
+	assert.NoError(t, CheckBackup(&models.TerraformState{Lineage: "abc-123", Serial: 4}, state))
+	assert.NoError(t, CheckBackup(&models.TerraformState{Lineage: "abc-123", Serial: 5}, state), "refreshes keep the serial")
+
+	assert.EqualError(t, CheckBackup(&models.TerraformState{Lineage: "def-456", Serial: 4}, state),
+		`backup lineage "def-456" differs from state lineage "abc-123"`)
+	assert.EqualError(t, CheckBackup(&models.TerraformState{Lineage: "abc-123", Serial: 6}, state),
+		"backup serial 6 is newer than state serial 5")
+}
+
+func TestStates(t *testing.T) {
+	t.Run("identical states have no changes", func(t *testing.T) {
+		s := state(resource("aws_vpc", "main", map[string]any{"id": "vpc-1"}))
+
+		result := States(s, s)
+
+		assert.Empty(t, result.Added)
+		assert.Empty(t, result.Removed)
+		assert.Empty(t, result.Modified)
+		assert.Empty(t, result.Renamed)
+	})
+
+	t.Run("detects added and removed resources", func(t *testing.T) {
+		before := state(resource("aws_vpc", "main", map[string]any{"id": "vpc-1"}))
+		after := state(resource("aws_s3_bucket", "logs", map[string]any{"id": "logs"}))
+
+		result := States(before, after)
+
+		assert.Equal(t, []string{"aws_s3_bucket.logs"}, result.Added)
+		assert.Equal(t, []string{"aws_vpc.main"}, result.Removed)
+		assert.Empty(t, result.Renamed)
+	})
+
+	t.Run("detects modified attributes", func(t *testing.T) {
+		before := state(resource("aws_instance", "web", map[string]any{"id": "i-1", "instance_type": "t3.micro", "ami": "ami-1"}))
+		after := state(resource("aws_instance", "web", map[string]any{"id": "i-1", "instance_type": "t3.large", "monitoring": true}))
+
+		result := States(before, after)
+
+		require.Len(t, result.Modified, 1)
+		assert.Equal(t, "aws_instance.web", result.Modified[0].ID)
+		assert.Equal(t, []string{"ami", "instance_type", "monitoring"}, result.Modified[0].ChangedAttributes)
+	})
+
+	t.Run("same cloud id under new address is a rename", func(t *testing.T) {
+		before := state(resource("aws_instance", "web", map[string]any{"id": "i-1", "tags": map[string]any{"Name": "a"}}))
+		moved := resource("aws_instance", "web", map[string]any{"id": "i-1", "tags": map[string]any{"Name": "b"}})
+		moved.Module = "module.app"
+		after := state(moved)
+
+		result := States(before, after)
+
+		assert.Empty(t, result.Added)
+		assert.Empty(t, result.Removed)
+		require.Len(t, result.Renamed, 1)
+		assert.Equal(t, "aws_instance.web", result.Renamed[0].From)
+		assert.Equal(t, "module.app.aws_instance.web", result.Renamed[0].To)
+		assert.Equal(t, "id", result.Renamed[0].MatchedBy)
+		assert.Equal(t, []string{"tags"}, result.Renamed[0].ChangedAttributes)
+	})
+
+	t.Run("identical attributes without id is a rename", func(t *testing.T) {
+		before := state(resource("random_pet", "name", map[string]any{"length": float64(2), "separator": "-"}))
+		after := state(resource("random_pet", "label", map[string]any{"length": float64(2), "separator": "-"}))
+
+		result := States(before, after)
+
+		require.Len(t, result.Renamed, 1)
+		assert.Equal(t, "attributes", result.Renamed[0].MatchedBy)
+		assert.Empty(t, result.Renamed[0].ChangedAttributes)
+	})
+
+	t.Run("different types are never renames", func(t *testing.T) {
+		before := state(resource("aws_instance", "web", map[string]any{"id": "shared"}))
+		after := state(resource("aws_s3_bucket", "web", map[string]any{"id": "shared"}))
+
+		result := States(before, after)
+
+		assert.Empty(t, result.Renamed)
+		assert.Equal(t, []string{"aws_s3_bucket.web"}, result.Added)
+		assert.Equal(t, []string{"aws_instance.web"}, result.Removed)
+	})
+
+	t.Run("each instance is matched at most once", func(t *testing.T) {
+		before := state(resource("aws_instance", "a", map[string]any{"id": "i-1"}))
+		after := state(
+			resource("aws_instance", "b", map[string]any{"id": "i-1"}),
+			resource("aws_instance", "c", map[string]any{"id": "i-1"}),
+		)
+
+		result := States(before, after)
+
+		require.Len(t, result.Renamed, 1)
+		assert.Equal(t, "aws_instance.b", result.Renamed[0].To)
+		assert.Equal(t, []string{"aws_instance.c"}, result.Added)
+	})
+
+	t.Run("pairs many moves by id and attributes", func(t *testing.T) {
+		var before, after []models.ResourceState
+		for i := range 4000 {
+			name := fmt.Sprintf("r%d", i)
+			attrs := map[string]any{"id": name}
+			if i%2 == 1 {
+				attrs = map[string]any{"name": name}
+			}
+
+			before = append(before, resource("aws_instance", name, attrs))
+			moved := resource("aws_instance", name, attrs)
+			moved.Module = "module.app"
+			after = append(after, moved)
+		}
+
+		result := States(state(before...), state(after...))
+
+		require.Len(t, result.Renamed, 4000)
+		assert.Empty(t, result.Added)
+		assert.Empty(t, result.Removed)
+		for _, rename := range result.Renamed {
+			assert.Equal(t, "module.app."+rename.From, rename.To)
+		}
+	})
+}
+
+func TestGraph(t *testing.T) {
+	dependent := func(resType, name string, attrs map[string]any, dependencies ...string) models.ResourceState {
+		res := resource(resType, name, attrs)
+		res.Instances[0].Dependencies = dependencies
+		return res
+	}
+
+	before := state(
+		resource("aws_vpc", "main", map[string]any{"id": "vpc-1", "cidr_block": "10.0.0.0/16"}),
+		dependent("aws_subnet", "a", map[string]any{"id": "subnet-1"}, "aws_vpc.main"),
+		dependent("aws_instance", "old", map[string]any{"id": "i-1"}, "aws_subnet.a"),
+		resource("aws_s3_bucket", "logs", map[string]any{"id": "logs"}),
+	)
+	after := state(
+		resource("aws_vpc", "main", map[string]any{"id": "vpc-1", "cidr_block": "10.1.0.0/16"}),
+		dependent("aws_subnet", "primary", map[string]any{"id": "subnet-1"}, "aws_vpc.main"),
+		dependent("aws_instance", "new", map[string]any{"id": "i-2"}, "aws_subnet.primary"),
+		dependent("aws_s3_bucket", "logs", map[string]any{"id": "logs"}, "aws_vpc.main"),
+	)
+
+	before.Lineage, before.Serial = "abc-123", 4
+	after.Lineage, after.Serial = "abc-123", 5
+
+	result := Graph(before, after)
+
+	changes := map[string]string{}
+	for _, node := range result.Nodes {
+		changes[node.ID] = node.Change
+	}
+	assert.Equal(t, map[string]string{
+		"aws_vpc.main":       models.ChangeModified,
+		"aws_subnet.primary": models.ChangeModified,
+		"aws_instance.new":   models.ChangeAdded,
+		"aws_s3_bucket.logs": models.ChangeUnchanged,
+		"aws_instance.old":   models.ChangeRemoved,
+	}, changes)
+
+	require.Len(t, result.Nodes, 5)
+	assert.Equal(t, []string{"cidr_block"}, result.Nodes[0].ChangedAttributes)
+	assert.Equal(t, "aws_subnet.a", result.Nodes[1].RenamedFrom)
+	assert.Equal(t, "aws_instance.old", result.Nodes[4].ID)
+
+	for _, node := range result.Nodes[:4] {
+		assert.Equal(t, models.NodeSource{Lineage: "abc-123", Serial: 5}, node.Source, node.ID)
+	}
+	assert.Equal(t, models.NodeSource{Lineage: "abc-123", Serial: 4}, result.Nodes[4].Source, "removed nodes come from the earlier state")
+
+	Label(result, "terraform.tfstate.backup", "terraform.tfstate")
+	assert.Equal(t, "terraform.tfstate", result.Nodes[0].Source.Label)
+	assert.Equal(t, "terraform.tfstate.backup", result.Nodes[4].Source.Label)
+
+	edges := map[string]string{}
+	for _, edge := range result.Edges {
+		edges[edge.Source+" -> "+edge.Target] = edge.Change
+	}
+	assert.Equal(t, map[string]string{
+		"aws_subnet.primary -> aws_vpc.main":     models.ChangeUnchanged,
+		"aws_instance.new -> aws_subnet.primary": models.ChangeAdded,
+		"aws_s3_bucket.logs -> aws_vpc.main":     models.ChangeAdded,
+		"aws_instance.old -> aws_subnet.primary": models.ChangeRemoved,
+	}, edges)
+	assert.Len(t, result.Edges, 4)
+}
+
+func TestChangedAttributes(t *testing.T) {
+	t.Run("nested values are compared deeply", func(t *testing.T) {
+		before := map[string]any{"tags": map[string]any{"a": "1"}}
+		after := map[string]any{"tags": map[string]any{"a": "1"}}
+
+		assert.Empty(t, changedAttributes(before, after))
+	})
+
+	t.Run("nil maps have no changes", func(t *testing.T) {
+		assert.Empty(t, changedAttributes(nil, nil))
+	})
+}
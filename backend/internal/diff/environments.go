@@ -0,0 +1,67 @@
+// Package diff compares Terraform states and reports how resources changed
+// between two versions.
+package diff
+
+import (
+	"github.com/terrascope/core/internal/models"
+)
+
+// environmentAttributes are the attributes that identify a resource in its
+// environment and differ between environments by construction, so they are
+// never reported as divergences.
+var environmentAttributes = []string{"id", "arn", "tags_all"}
+
+// Environments compares two states of different lineages, such as the states
+// of the prod and staging deployments of a configuration. Instances are
+// aligned by address rather than matched by identity, and the attributes
+// named by ignored are left out of the comparison along with
+// environmentAttributes.
+func Environments(left, right *models.TerraformState, ignored []string) *models.EnvironmentDiff {
+	result := &models.EnvironmentDiff{
+		Left:      models.EnvironmentSide{Lineage: left.Lineage, Serial: left.Serial},
+		Right:     models.EnvironmentSide{Lineage: right.Lineage, Serial: right.Serial},
+		OnlyLeft:  []string{},
+		OnlyRight: []string{},
+		Diverged:  []models.ResourceChange{},
+	}
+
+	skipped := make(map[string]bool, len(environmentAttributes)+len(ignored))
+	for _, key := range environmentAttributes {
+		skipped[key] = true
+	}
+	for _, key := range ignored {
+		skipped[key] = true
+	}
+
+	leftIndex := indexInstances(left)
+	rightIndex := indexInstances(right)
+
+	for _, id := range sortedKeys(leftIndex) {
+		other, ok := rightIndex[id]
+		if !ok {
+			result.OnlyLeft = append(result.OnlyLeft, id)
+			continue
+		}
+
+		result.Shared++
+
+		changed := []string{}
+		for _, key := range changedAttributes(leftIndex[id].Instance.Attributes, other.Instance.Attributes) {
+			if !skipped[key] {
+				changed = append(changed, key)
+			}
+		}
+
+		if len(changed) > 0 {
+			result.Diverged = append(result.Diverged, models.ResourceChange{ID: id, ChangedAttributes: changed})
+		}
+	}
+
+	for _, id := range sortedKeys(rightIndex) {
+		if _, ok := leftIndex[id]; !ok {
+			result.OnlyRight = append(result.OnlyRight, id)
+		}
+	}
+
+	return result
+}
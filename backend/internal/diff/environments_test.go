@@ -0,0 +1,59 @@
+// Package diff compares Terraform states and reports how resources changed
+// between two versions.
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestEnvironments(t *testing.T) {
+	prod := state(
+		resource("aws_vpc", "main", map[string]any{"id": "vpc-1", "arn": "arn:aws:ec2:vpc/vpc-1", "cidr_block": "10.0.0.0/16"}),
+		resource("aws_db_instance", "main", map[string]any{"id": "db-1", "instance_class": "db.r6g.large", "multi_az": true}),
+		resource("aws_cloudfront_distribution", "cdn", map[string]any{"id": "E1"}),
+	)
+	prod.Lineage, prod.Serial = "prod", 12
+
+	staging := state(
+		resource("aws_vpc", "main", map[string]any{"id": "vpc-2", "arn": "arn:aws:ec2:vpc/vpc-2", "cidr_block": "10.0.0.0/16"}),
+		resource("aws_db_instance", "main", map[string]any{"id": "db-2", "instance_class": "db.t4g.small", "multi_az": false}),
+		resource("aws_instance", "bastion", map[string]any{"id": "i-1"}),
+	)
+	staging.Lineage, staging.Serial = "staging", 3
+
+	t.Run("aligns resources by address", func(t *testing.T) {
+		result := Environments(prod, staging, nil)
+
+		assert.Equal(t, models.EnvironmentSide{Lineage: "prod", Serial: 12}, result.Left)
+		assert.Equal(t, models.EnvironmentSide{Lineage: "staging", Serial: 3}, result.Right)
+		assert.Equal(t, 2, result.Shared)
+		assert.Equal(t, []string{"aws_cloudfront_distribution.cdn"}, result.OnlyLeft)
+		assert.Equal(t, []string{"aws_instance.bastion"}, result.OnlyRight)
+	})
+
+	t.Run("reports attribute divergences without identities", func(t *testing.T) {
+		result := Environments(prod, staging, nil)
+
+		assert.Equal(t, []models.ResourceChange{
+			{ID: "aws_db_instance.main", ChangedAttributes: []string{"instance_class", "multi_az"}},
+		}, result.Diverged)
+	})
+
+	t.Run("ignores the given attributes", func(t *testing.T) {
+		result := Environments(prod, staging, []string{"instance_class", "multi_az"})
+
+		assert.Empty(t, result.Diverged)
+	})
+
+	t.Run("identical environments are in sync", func(t *testing.T) {
+		result := Environments(prod, prod, nil)
+
+		assert.Equal(t, 3, result.Shared)
+		assert.Empty(t, result.OnlyLeft)
+		assert.Empty(t, result.OnlyRight)
+		assert.Empty(t, result.Diverged)
+	})
+}
@@ -0,0 +1,137 @@
+// Package events delivers the events of state lineages to the clients that
+// follow them.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/terrascope/core/internal/cache"
+	"github.com/terrascope/core/internal/models"
+)
+
+// channel is the pub/sub channel events are relayed on between replicas.
+const channel = "terrascope:events"
+
+// subscriberBuffer is how many events a subscriber may fall behind by before
+// further events are dropped for it.
+const subscriberBuffer = 16
+
+// maxRelayBackoff bounds the wait before subscribing again after the relay
+// failed.
+const maxRelayBackoff = 30 * time.Second
+
+// Broker delivers published events to the subscribers of their lineage. With
+// a PubSub, events go through it so that subscribers on every replica receive
+// them, as long as Relay runs.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan models.StateEvent]struct{}
+	pubsub      cache.PubSub
+}
+
+// NewBroker returns a broker relaying events through the pubsub, or
+// delivering them in this server when it is nil.
+func NewBroker(pubsub cache.PubSub) *Broker {
+	return &Broker{
+		subscribers: make(map[string]map[chan models.StateEvent]struct{}),
+		pubsub:      pubsub,
+	}
+}
+
+// Subscribe returns the events of the lineage until cancel is called. Events
+// are dropped for subscribers that do not keep up.
+func (b *Broker) Subscribe(lineage string) (events <-chan models.StateEvent, cancel func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan models.StateEvent, subscriberBuffer)
+	if b.subscribers[lineage] == nil {
+		b.subscribers[lineage] = make(map[chan models.StateEvent]struct{})
+	}
+	b.subscribers[lineage][ch] = struct{}{}
+
+	var once sync.Once
+	cancel = func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			delete(b.subscribers[lineage], ch)
+			if len(b.subscribers[lineage]) == 0 {
+				delete(b.subscribers, lineage)
+			}
+		})
+	}
+
+	return ch, cancel
+}
+
+// Publish sends the event to the subscribers of its lineage.
+func (b *Broker) Publish(event models.StateEvent) {
+	if b.pubsub == nil {
+		b.deliver(event)
+		return
+	}
+
+	message, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Publishing event: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := b.pubsub.Publish(ctx, channel, message); err != nil {
+		log.Printf("Publishing event: %v", err)
+	}
+}
+
+// Relay delivers the events published by every replica to the subscribers of
+// this server until ctx is done, subscribing again when the connection fails.
+// It returns at once without a PubSub.
+func (b *Broker) Relay(ctx context.Context) {
+	if b.pubsub == nil {
+		return
+	}
+
+	backoff := time.Second
+	for ctx.Err() == nil {
+		err := b.pubsub.Subscribe(ctx, channel, func(message []byte) {
+			backoff = time.Second
+
+			var event models.StateEvent
+			if err := json.Unmarshal(message, &event); err != nil {
+				log.Printf("Ignoring relayed event: %v", err)
+				return
+			}
+			b.deliver(event)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Printf("Relaying events: %v; retrying in %s", err, backoff)
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxRelayBackoff)
+	}
+}
+
+func (b *Broker) deliver(event models.StateEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[event.Lineage] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Package events delivers the events of state lineages to the clients that
+// follow them.
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestBroker(t *testing.T) {
+	broker := NewBroker(nil)
+
+	prod, cancelProd := broker.Subscribe("prod")
+	defer cancelProd()
+	dev, cancelDev := broker.Subscribe("dev")
+
+	broker.Publish(models.StateEvent{Type: models.StateEventVersion, Lineage: "prod", Serial: 2})
+
+	select {
+	case event := <-prod:
+		assert.Equal(t, 2, event.Serial)
+	default:
+		t.Fatal("no event for prod")
+	}
+	assert.Empty(t, dev)
+
+	t.Run("stops delivering once cancelled", func(t *testing.T) {
+		cancelDev()
+		cancelDev()
+
+		broker.Publish(models.StateEvent{Lineage: "dev"})
+		assert.Empty(t, dev)
+		assert.NotContains(t, broker.subscribers, "dev")
+	})
+
+	t.Run("drops events for slow subscribers", func(t *testing.T) {
+		for serial := range subscriberBuffer + 5 {
+			broker.Publish(models.StateEvent{Lineage: "prod", Serial: serial})
+		}
+
+		assert.Len(t, prod, subscriberBuffer)
+	})
+}
+
+// fakePubSub relays published messages to the subscription of the test, and
+// fails the first subscription.
+type fakePubSub struct {
+	mu         sync.Mutex
+	receive    func([]byte)
+	subscribed chan struct{}
+	attempts   int
+}
+
+func (f *fakePubSub) Publish(_ context.Context, _ string, message []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.receive != nil {
+		f.receive(message)
+	}
+	return nil
+}
+
+func (f *fakePubSub) Subscribe(ctx context.Context, _ string, receive func([]byte)) error {
+	f.mu.Lock()
+	f.attempts++
+	if f.attempts == 1 {
+		f.mu.Unlock()
+		return errors.New("connection refused")
+	}
+	f.receive = receive
+	f.mu.Unlock()
+
+	close(f.subscribed)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestBrokerRelay(t *testing.T) {
+	pubsub := &fakePubSub{subscribed: make(chan struct{})}
+	broker := NewBroker(pubsub)
+
+	events, cancel := broker.Subscribe("prod")
+	defer cancel()
+
+	// Without the relay, published events do not come back.
+	broker.Publish(models.StateEvent{Lineage: "prod"})
+	assert.Empty(t, events)
+
+	ctx, stop := context.WithCancel(context.Background())
+	relayed := make(chan struct{})
+	go func() {
+		broker.Relay(ctx)
+		close(relayed)
+	}()
+
+	select {
+	case <-pubsub.subscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("relay did not subscribe again")
+	}
+
+	broker.Publish(models.StateEvent{Type: models.StateEventAnalysis, Lineage: "prod", Analysis: "clusters"})
+	require.Len(t, events, 1)
+	assert.Equal(t, "clusters", (<-events).Analysis)
+
+	stop()
+	<-relayed
+}
@@ -0,0 +1,93 @@
+// Package export writes rendered graph artifacts to object storage so they can
+// be linked to without calling the API at view time.
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/render"
+)
+
+// Store persists rendered artifacts. Put returns the final object key and URL
+// returns where the object can be fetched from.
+type Store interface {
+	Put(ctx context.Context, key string, body []byte, contentType string) (string, error)
+	URL(key string) string
+}
+
+// ErrInvalidLineage reports a state lineage that is not a UUID, the shape
+// Terraform writes. Lineages become part of object keys, so anything else is
+// refused rather than escaped.
+var ErrInvalidLineage = errors.New("lineage is not a UUID")
+
+var lineagePattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// ValidLineage reports whether a lineage may be used in artifact keys: it is
+// empty or a lowercase UUID.
+func ValidLineage(lineage string) bool {
+	return lineage == "" || lineagePattern.MatchString(lineage)
+}
+
+// ArtifactKey returns the deterministic object key for a rendered format of a
+// state version: <lineage>/<serial>/graph.<ext>. States without a lineage
+// are keyed by the graph digest instead.
+func ArtifactKey(state *models.TerraformState, digest string, format render.Format) string {
+	version := state.Lineage + "/" + fmt.Sprint(state.Serial)
+	if state.Lineage == "" {
+		version = "digest/" + strings.TrimPrefix(digest, "sha256:")
+	}
+
+	return version + "/graph." + format.Extension
+}
+
+// Export renders the graph in each of the requested formats and uploads the
+// results. Invalid lineages and unknown formats are rejected before anything
+// is uploaded; upload failures stop the export.
+func Export(ctx context.Context, store Store, state *models.TerraformState, graph *models.Graph, formats []string) (*models.ExportResult, error) {
+	if !ValidLineage(state.Lineage) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidLineage, state.Lineage)
+	}
+
+	digest := ""
+	if graph.Stats != nil {
+		digest = graph.Stats.Digest
+	}
+
+	selected := make([]render.Format, 0, len(formats))
+	for _, name := range formats {
+		format, err := render.Lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, format)
+	}
+
+	result := &models.ExportResult{Artifacts: []models.Artifact{}}
+
+	for _, format := range selected {
+		body, err := format.Render(graph)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", format.Name, err)
+		}
+
+		key, err := store.Put(ctx, ArtifactKey(state, digest, format), body, format.ContentType)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Artifacts = append(result.Artifacts, models.Artifact{
+			Format:      format.Name,
+			Key:         key,
+			URL:         store.URL(key),
+			ContentType: format.ContentType,
+			Size:        len(body),
+		})
+	}
+
+	return result, nil
+}
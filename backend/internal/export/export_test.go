@@ -0,0 +1,109 @@
+// Package export writes rendered graph artifacts to object storage so they can
+// be linked to without calling the API at view time.
+package export
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/render"
+)
+
+type memoryStore struct {
+	objects map[string][]byte
+	err     error
+}
+
+func (m *memoryStore) Put(_ context.Context, key string, body []byte, _ string) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+
+	m.objects[key] = body
+
+	return key, nil
+}
+
+func (m *memoryStore) URL(key string) string {
+	return "mem://" + key
+}
+
+func exportGraph() *models.Graph {
+	return &models.Graph{
+		Nodes: []models.Node{{ID: "aws_vpc.main", Type: "aws_vpc", Mode: "managed", Provider: "aws"}},
+		Edges: []models.Edge{},
+		Stats: &models.Stats{Digest: "sha256:abc"},
+	}
+}
+
+func TestValidLineage(t *testing.T) {
+	assert.True(t, ValidLineage("0b6e9e2c-5b1a-4c3e-9f7d-2a1b3c4d5e6f"))
+	assert.True(t, ValidLineage(""))
+	assert.False(t, ValidLineage("../../ci/0b6e9e2c-5b1a-4c3e-9f7d-2a1b3c4d5e6f"))
+	assert.False(t, ValidLineage("0B6E9E2C-5B1A-4C3E-9F7D-2A1B3C4D5E6F"))
+	assert.False(t, ValidLineage("0b6e9e2c-5b1a-4c3e-9f7d-2a1b3c4d5e6f/1"))
+	assert.False(t, ValidLineage("abc-123"))
+}
+
+func TestArtifactKey(t *testing.T) {
+	dot := render.Formats["dot"]
+
+	t.Run("keys by lineage and serial", func(t *testing.T) {
+		state := &models.TerraformState{Lineage: "0b6e9e2c-5b1a-4c3e-9f7d-2a1b3c4d5e6f", Serial: 12}
+
+		assert.Equal(t, "0b6e9e2c-5b1a-4c3e-9f7d-2a1b3c4d5e6f/12/graph.dot", ArtifactKey(state, "sha256:abc", dot))
+	})
+
+	t.Run("falls back to the digest without a lineage", func(t *testing.T) {
+		assert.Equal(t, "digest/abc/graph.dot", ArtifactKey(&models.TerraformState{}, "sha256:abc", dot))
+	})
+}
+
+func TestExport(t *testing.T) {
+	state := &models.TerraformState{Lineage: "0b6e9e2c-5b1a-4c3e-9f7d-2a1b3c4d5e6f", Serial: 12}
+
+	t.Run("uploads every requested format", func(t *testing.T) {
+		store := &memoryStore{objects: map[string][]byte{}}
+
+		result, err := Export(context.Background(), store, state, exportGraph(), []string{"json", "dot", "html"})
+
+		require.NoError(t, err)
+		require.Len(t, result.Artifacts, 3)
+		assert.Equal(t, "0b6e9e2c-5b1a-4c3e-9f7d-2a1b3c4d5e6f/12/graph.json", result.Artifacts[0].Key)
+		assert.Equal(t, "mem://0b6e9e2c-5b1a-4c3e-9f7d-2a1b3c4d5e6f/12/graph.dot", result.Artifacts[1].URL)
+		assert.Equal(t, "text/html; charset=utf-8", result.Artifacts[2].ContentType)
+		assert.Equal(t, len(store.objects["0b6e9e2c-5b1a-4c3e-9f7d-2a1b3c4d5e6f/12/graph.html"]), result.Artifacts[2].Size)
+		assert.Len(t, store.objects, 3)
+	})
+
+	t.Run("rejects unknown formats before uploading", func(t *testing.T) {
+		store := &memoryStore{objects: map[string][]byte{}}
+
+		_, err := Export(context.Background(), store, state, exportGraph(), []string{"json", "pdf"})
+
+		assert.ErrorContains(t, err, `unknown format "pdf"`)
+		assert.Empty(t, store.objects)
+	})
+
+	t.Run("rejects lineages that are not UUIDs before uploading", func(t *testing.T) {
+		store := &memoryStore{objects: map[string][]byte{}}
+		state := &models.TerraformState{Lineage: "../ci/other", Serial: 12}
+
+		_, err := Export(context.Background(), store, state, exportGraph(), []string{"json"})
+
+		assert.ErrorIs(t, err, ErrInvalidLineage)
+		assert.Empty(t, store.objects)
+	})
+
+	t.Run("returns upload errors", func(t *testing.T) {
+		store := &memoryStore{err: errors.New("bucket unavailable")}
+
+		_, err := Export(context.Background(), store, state, exportGraph(), []string{"json"})
+
+		assert.EqualError(t, err, "bucket unavailable")
+	})
+}
@@ -0,0 +1,335 @@
+// Package export writes rendered graph artifacts to object storage so they can
+// be linked to without calling the API at view time.
+package export
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	amzDateFormat  = "20060102T150405Z"
+	amzDayFormat   = "20060102"
+	signingService = "s3"
+	uploadTimeout  = 30 * time.Second
+	// restoreTier is the retrieval tier of Restore: hours for Glacier
+	// Flexible Retrieval and half a day for Deep Archive.
+	restoreTier = "Standard"
+)
+
+// S3Store uploads objects to an S3-compatible bucket (AWS S3, MinIO, R2, or
+// GCS through its XML API with HMAC keys) using Signature Version 4. Objects
+// are written in StorageClass, the bucket's default when empty.
+type S3Store struct {
+	Endpoint     string
+	Region       string
+	Bucket       string
+	Prefix       string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	StorageClass string
+	HTTPClient   *http.Client
+
+	now func() time.Time
+}
+
+// NewS3StoreFromEnv configures a store from TERRASCOPE_EXPORT_* variables and
+// returns nil when no bucket is configured. Without an endpoint the store
+// talks to AWS S3 in the configured region.
+func NewS3StoreFromEnv() *S3Store {
+	return S3StoreFromEnv("TERRASCOPE_EXPORT_")
+}
+
+// S3StoreFromEnv configures a store from the BUCKET, REGION, ENDPOINT,
+// PREFIX, ACCESS_KEY_ID, SECRET_ACCESS_KEY, SESSION_TOKEN and STORAGE_CLASS
+// variables with the given prefix, like NewS3StoreFromEnv.
+func S3StoreFromEnv(prefix string) *S3Store {
+	bucket := os.Getenv(prefix + "BUCKET")
+	if bucket == "" {
+		return nil
+	}
+
+	region := os.Getenv(prefix + "REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	endpoint := os.Getenv(prefix + "ENDPOINT")
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+
+	return &S3Store{
+		Endpoint:     strings.TrimSuffix(endpoint, "/"),
+		Region:       region,
+		Bucket:       bucket,
+		Prefix:       strings.Trim(os.Getenv(prefix+"PREFIX"), "/"),
+		AccessKey:    os.Getenv(prefix + "ACCESS_KEY_ID"),
+		SecretKey:    os.Getenv(prefix + "SECRET_ACCESS_KEY"),
+		SessionToken: os.Getenv(prefix + "SESSION_TOKEN"),
+		StorageClass: os.Getenv(prefix + "STORAGE_CLASS"),
+		HTTPClient:   &http.Client{Timeout: uploadTimeout},
+	}
+}
+
+// Put uploads body under key, prefixed with the store prefix, and returns the
+// full object key.
+func (s *S3Store) Put(ctx context.Context, key string, body []byte, contentType string) (string, error) {
+	key = s.fullKey(key)
+
+	header := http.Header{"Content-Type": {contentType}}
+	if s.StorageClass != "" {
+		header.Set("X-Amz-Storage-Class", s.StorageClass)
+	}
+
+	if _, err := s.do(ctx, http.MethodPut, key, body, header); err != nil {
+		return "", fmt.Errorf("upload of %s failed: %w", key, err)
+	}
+
+	return key, nil
+}
+
+// Get downloads the object under key, prefixed with the store prefix.
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	key = s.fullKey(key)
+
+	body, err := s.do(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("download of %s failed: %w", key, err)
+	}
+
+	return body, nil
+}
+
+// Delete removes the object under key, prefixed with the store prefix.
+// Deleting a missing object succeeds.
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	key = s.fullKey(key)
+
+	if _, err := s.do(ctx, http.MethodDelete, key, nil, nil); err != nil {
+		return fmt.Errorf("deletion of %s failed: %w", key, err)
+	}
+
+	return nil
+}
+
+// Restore requests a temporary copy of an archived object under key,
+// prefixed with the store prefix, readable for the given number of days, and
+// reports whether the copy is readable yet. Objects outside the archival
+// storage classes are always readable.
+func (s *S3Store) Restore(ctx context.Context, key string, days int) (bool, error) {
+	key = s.fullKey(key)
+
+	request := fmt.Sprintf("<RestoreRequest><Days>%d</Days><GlacierJobParameters><Tier>%s</Tier></GlacierJobParameters></RestoreRequest>", days, restoreTier)
+
+	status, detail, err := s.send(ctx, http.MethodPost, s.URL(key)+"?restore", []byte(request), http.Header{"Content-Type": {"application/xml"}})
+	if err != nil {
+		return false, fmt.Errorf("restore of %s failed: %w", key, err)
+	}
+
+	switch {
+	case status == http.StatusOK:
+		return true, nil
+	case status == http.StatusAccepted:
+		return false, nil
+	case status == http.StatusConflict && strings.Contains(string(detail), "<Code>RestoreAlreadyInProgress</Code>"):
+		return false, nil
+	case status == http.StatusForbidden && strings.Contains(string(detail), "<Code>InvalidObjectState</Code>"):
+		return true, nil
+	}
+
+	return false, fmt.Errorf("restore of %s failed: %d %s: %s", key, status, http.StatusText(status), strings.TrimSpace(string(detail)))
+}
+
+func (s *S3Store) fullKey(key string) string {
+	if s.Prefix != "" {
+		return s.Prefix + "/" + key
+	}
+
+	return key
+}
+
+// do sends a signed request for the object and returns the response body.
+func (s *S3Store) do(ctx context.Context, method, key string, body []byte, header http.Header) ([]byte, error) {
+	status, resp, err := s.send(ctx, method, s.URL(key), body, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if status/100 != 2 {
+		if len(resp) > 1024 {
+			resp = resp[:1024]
+		}
+		return nil, fmt.Errorf("%d %s: %s", status, http.StatusText(status), strings.TrimSpace(string(resp)))
+	}
+
+	return resp, nil
+}
+
+// send sends a signed request with the non-empty headers and returns the
+// response status and body.
+func (s *S3Store) send(ctx context.Context, method, target string, body []byte, header http.Header) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	for name, values := range header {
+		if len(values) > 0 && values[0] != "" {
+			req.Header[name] = values
+		}
+	}
+	s.sign(req, body)
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("failed to close S3 response body: %v", err)
+		}
+	}()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return resp.StatusCode, data, nil
+}
+
+// URL returns the object URL of a key returned by Put.
+func (s *S3Store) URL(key string) string {
+	return s.Endpoint + "/" + s.Bucket + "/" + encodePath(key)
+}
+
+// sign adds AWS Signature Version 4 headers covering every header already set
+// on the request plus the host.
+func (s *S3Store) sign(req *http.Request, body []byte) {
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	t := now().UTC()
+
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", t.Format(amzDateFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{t.Format(amzDayFormat), s.Region, signingService, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		t.Format(amzDateFormat),
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), t.Format(amzDayFormat))
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, signingService)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKey, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := []string{}
+	for _, key := range keys {
+		vals := append([]string(nil), values[key]...)
+		sort.Strings(vals)
+		for _, value := range vals {
+			parts = append(parts, encodeComponent(key)+"="+encodeComponent(value))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// encodePath percent-encodes every byte of an object key except unreserved
+// characters and slashes, as SigV4 expects for S3.
+func encodePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = encodeComponent(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func encodeComponent(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,117 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+// adminToken authorizes the /admin endpoints as a bearer token. Without it,
+// only admin API keys and signed-in admins may use them.
+var adminToken = os.Getenv("TERRASCOPE_ADMIN_TOKEN")
+
+// requireAdmin checks that an admin request comes with an admin API key, from
+// a signed-in admin, or with the admin token as its bearer token. On failure
+// it writes a 503, 401, 403 or 500 response and returns false.
+func requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if key, ok := requestAPIKey(r); ok {
+		if key.Role != models.RoleAdmin {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return false
+		}
+		return true
+	}
+
+	user, ok, err := currentUser(r)
+	if err != nil {
+		storageFailed(w, err)
+		return false
+	}
+	if ok && user.Role == models.RoleAdmin {
+		return true
+	}
+
+	if adminToken == "" {
+		http.Error(w, "Admin endpoints are not configured", http.StatusServiceUnavailable)
+		return false
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+// AdminExportHandler downloads every stored state version with its document,
+// and every stored graph and lock, as a gzip compressed JSON archive that
+// AdminImportHandler can load into another instance.
+func AdminExportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", `attachment; filename="terrascope-archive.json.gz"`)
+
+	if err := store.WriteArchive(w, storedStates, storedGraphs, stateLocks); err != nil {
+		log.Printf("Error writing archive: %v", err)
+	}
+}
+
+// AdminImportHandler loads an archive produced by AdminExportHandler,
+// gzip-compressed or not, replacing stored entries with the same key.
+func AdminImportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	archive, err := store.ReadArchive(body)
+	if err != nil {
+		http.Error(w, "Invalid archive: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := store.Restore(archive, storedStates, storedGraphs, stateLocks)
+	if errors.Is(err, store.ErrTrashed) {
+		http.Error(w, "Invalid archive: "+err.Error(), http.StatusConflict)
+		return
+	} else if err != nil {
+		storageFailed(w, err)
+		return
+	}
+
+	writeJSON(w, r, result)
+}
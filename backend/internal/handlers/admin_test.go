@@ -0,0 +1,127 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+func TestAdminHandlers(t *testing.T) {
+	previousToken, previousStates, previousGraphs, previousLocks := adminToken, storedStates, storedGraphs, stateLocks
+	adminToken = "s3cret"
+	storedStates, storedGraphs, stateLocks = store.NewStateStore(time.Hour), store.NewGraphStore(4), store.NewLockStore()
+	t.Cleanup(func() {
+		adminToken, storedStates, storedGraphs, stateLocks = previousToken, previousStates, previousGraphs, previousLocks
+	})
+
+	tfstate := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"lineage": "admin-test",
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_vpc",
+				"name": "main",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "vpc-1"}}]
+			}
+		]
+	}`
+
+	parsed := httptest.NewRecorder()
+	ParseHandler(parsed, httptest.NewRequest(http.MethodPost, "/parse", strings.NewReader(tfstate)))
+	require.Equal(t, http.StatusOK, parsed.Code)
+	digest := parsed.Header().Get("X-Graph-Digest")
+	stored := httptest.NewRecorder()
+	StatesHandler(stored, httptest.NewRequest(http.MethodPost, "/states", strings.NewReader(tfstate)))
+	require.Equal(t, http.StatusCreated, stored.Code)
+	stateLocks.Put("admin-test", models.LockInfo{ID: "lock-1", Who: "ci-runner-42"})
+
+	authorized := func(req *http.Request) *http.Request {
+		req.Header.Set("Authorization", "Bearer s3cret")
+		return req
+	}
+
+	t.Run("export and import into another instance", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		AdminExportHandler(w, authorized(httptest.NewRequest(http.MethodGet, "/admin/export", nil)))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/gzip", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "terrascope-archive.json.gz")
+		archive := w.Body.Bytes()
+
+		storedStates, storedGraphs, stateLocks = store.NewStateStore(time.Hour), store.NewGraphStore(4), store.NewLockStore()
+
+		w = httptest.NewRecorder()
+		AdminImportHandler(w, authorized(httptest.NewRequest(http.MethodPost, "/admin/import", bytes.NewReader(archive))))
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var result models.ImportResult
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+		assert.Equal(t, models.ImportResult{States: 1, Graphs: 1, Locks: 1}, result)
+
+		_, document, err := storedStates.Get("admin-test", 0)
+		require.NoError(t, err)
+		assert.JSONEq(t, tfstate, string(document))
+		_, ok := storedGraphs.Get(digest)
+		assert.True(t, ok)
+		lock, ok := stateLocks.Get("admin-test")
+		require.True(t, ok)
+		assert.Equal(t, "ci-runner-42", lock.Who)
+	})
+
+	t.Run("invalid archive", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		AdminImportHandler(w, authorized(httptest.NewRequest(http.MethodPost, "/admin/import", strings.NewReader(`{"version": 9}`))))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid archive: unsupported archive version 9")
+	})
+
+	t.Run("authorization", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		AdminExportHandler(w, httptest.NewRequest(http.MethodGet, "/admin/export", nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/export", nil)
+		req.Header.Set("Authorization", "Bearer wrong")
+		w = httptest.NewRecorder()
+		AdminExportHandler(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("disabled without a token", func(t *testing.T) {
+		adminToken = ""
+		t.Cleanup(func() { adminToken = "s3cret" })
+
+		w := httptest.NewRecorder()
+		AdminImportHandler(w, authorized(httptest.NewRequest(http.MethodPost, "/admin/import", nil)))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		AdminExportHandler(w, httptest.NewRequest(http.MethodPost, "/admin/export", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+		w = httptest.NewRecorder()
+		AdminImportHandler(w, httptest.NewRequest(http.MethodGet, "/admin/import", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
@@ -0,0 +1,311 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/terrascope/core/internal/alerts"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/notifications"
+	"github.com/terrascope/core/internal/parser"
+	"github.com/terrascope/core/internal/store"
+)
+
+// alertHistoryLimit bounds how many triggered alerts AlertsHandler can list.
+const alertHistoryLimit = 500
+
+// alertStore keeps the alert rules and recent alerts. notificationChannels
+// holds the channels configured by TERRASCOPE_NOTIFICATIONS_CONFIG, through
+// which alertNotifier delivers the alerts.
+var (
+	alertStore                           = store.NewAlertStore(alertHistoryLimit)
+	notificationChannels                 = notifications.FromEnv()
+	alertNotifier        alerts.Notifier = alerts.NewChannelNotifier(notificationChannels)
+)
+
+// anomalyThresholds tune the anomalies flagged on every stored version, from
+// TERRASCOPE_ANOMALY_DELETION_PERCENT, TERRASCOPE_ANOMALY_SERIAL_JUMP and
+// TERRASCOPE_ANOMALY_LINEAGE_OVERLAP_PERCENT; 0 disables a check.
+var anomalyThresholds = anomalyThresholdsFromEnv()
+
+func anomalyThresholdsFromEnv() alerts.AnomalyThresholds {
+	thresholds := alerts.DefaultAnomalyThresholds
+
+	for name, threshold := range map[string]*int{
+		"TERRASCOPE_ANOMALY_DELETION_PERCENT":        &thresholds.DeletionPercent,
+		"TERRASCOPE_ANOMALY_SERIAL_JUMP":             &thresholds.SerialJump,
+		"TERRASCOPE_ANOMALY_LINEAGE_OVERLAP_PERCENT": &thresholds.LineageOverlapPercent,
+	} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			log.Printf("Ignoring %s: invalid value %q", name, value)
+			continue
+		}
+
+		*threshold = n
+	}
+
+	return thresholds
+}
+
+// AlertRulesHandler creates an alert rule (POST) or lists the rules (GET).
+func AlertRulesHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		createAlertRule(w, r)
+	case http.MethodGet:
+		writeJSON(w, r, alertStore.Rules())
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func createAlertRule(w http.ResponseWriter, r *http.Request) {
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	var rule models.AlertRule
+	if err := json.Unmarshal(body.Bytes(), &rule); err != nil {
+		http.Error(w, "Invalid alert rule: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := alerts.ValidateRule(rule); err != nil {
+		http.Error(w, "Invalid alert rule: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := notificationChannels.Resolve(rule.Lineage, rule.Channels); err != nil {
+		http.Error(w, "Invalid alert rule: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rule.ID = newID()
+	rule.CreatedAt = time.Now().UTC()
+	alertStore.PutRule(rule)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, r, rule)
+}
+
+// newID returns a random identifier of 16 hexadecimal digits.
+func newID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(id)
+}
+
+// AlertRuleHandler returns (GET) or deletes (DELETE) an alert rule.
+func AlertRuleHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	switch r.Method {
+	case http.MethodGet:
+		rule, ok := alertStore.Rule(id)
+		if !ok {
+			http.Error(w, "Alert rule not found: "+id, http.StatusNotFound)
+			return
+		}
+
+		writeJSON(w, r, rule)
+	case http.MethodDelete:
+		if !alertStore.DeleteRule(id) {
+			http.Error(w, "Alert rule not found: "+id, http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// AlertsHandler lists the most recent alerts, newest first, optionally only
+// those of the lineage named by ?lineage=.
+func AlertsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	triggered := alertStore.Alerts()
+
+	if lineage := r.URL.Query().Get("lineage"); lineage != "" {
+		filtered := []models.Alert{}
+		for _, alert := range triggered {
+			if alert.Lineage == lineage {
+				filtered = append(filtered, alert)
+			}
+		}
+		triggered = filtered
+	}
+
+	writeJSON(w, r, triggered)
+}
+
+// evaluateAlerts compares a newly stored state with the previous stored
+// version of its lineage, records the alerts it triggers and delivers them in
+// the background. The first version of a lineage triggers nothing.
+func evaluateAlerts(state *models.TerraformState) {
+	rules := alertStore.Rules()
+	if len(rules) == 0 {
+		return
+	}
+
+	previous, ok := previousState(state.Lineage, state.Serial)
+	if !ok {
+		return
+	}
+
+	triggered := alerts.Evaluate(rules, previous, state)
+	if len(triggered) == 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+	for i := range triggered {
+		triggered[i].TriggeredAt = now
+	}
+
+	alertStore.Record(triggered...)
+
+	for _, alert := range triggered {
+		rule, ok := alertStore.Rule(alert.RuleID)
+		if !ok {
+			continue
+		}
+
+		go deliverAlert(rule, alert)
+	}
+}
+
+// detectAnomalies compares a state about to be stored with the previous
+// stored version of its lineage or, for the first version of a lineage, with
+// the latest version of another lineage of the project.
+func detectAnomalies(state *models.TerraformState, project string) []models.Alert {
+	previous, ok := previousState(state.Lineage, state.Serial)
+	if !ok {
+		if versions, _ := storedStates.Versions(state.Lineage); len(versions) > 0 {
+			return nil
+		}
+
+		if previous, ok = latestOtherLineage(state.Lineage, project); !ok {
+			return nil
+		}
+	}
+
+	anomalies := alerts.DetectAnomalies(anomalyThresholds, previous, state)
+	if len(anomalies) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	for i := range anomalies {
+		anomalies[i].TriggeredAt = now
+	}
+
+	return anomalies
+}
+
+// recordAnomalies records the anomalies of a stored version with the alerts
+// and delivers them in the background to the channels of the lineage.
+func recordAnomalies(anomalies []models.Alert) {
+	alertStore.Record(anomalies...)
+
+	for _, anomaly := range anomalies {
+		go deliverAlert(models.AlertRule{Name: anomaly.RuleName}, anomaly)
+	}
+}
+
+// latestOtherLineage parses the most recently stored version among the other
+// lineages of the project.
+func latestOtherLineage(lineage, project string) (*models.TerraformState, bool) {
+	histories, err := storedStates.Lineages()
+	if err != nil {
+		log.Printf("Skipping anomalies for %s: %v", lineage, err)
+		return nil, false
+	}
+
+	var latest *models.StateVersion
+	for i, history := range histories {
+		if history.Lineage == lineage || history.Latest.Project != project {
+			continue
+		}
+		if latest == nil || history.Latest.StoredAt.After(latest.StoredAt) {
+			latest = &histories[i].Latest
+		}
+	}
+
+	if latest == nil {
+		return nil, false
+	}
+
+	_, data, err := storedStates.Get(latest.Lineage, latest.Serial)
+	if err != nil {
+		log.Printf("Skipping anomalies for %s: %v", lineage, err)
+		return nil, false
+	}
+
+	state, err := parser.ParseTfstate(data)
+	if err != nil {
+		log.Printf("Skipping anomalies for %s: %v", lineage, err)
+		return nil, false
+	}
+
+	return state, true
+}
+
+// previousState parses the stored version of the lineage with the highest
+// serial below the given one.
+func previousState(lineage string, serial int) (*models.TerraformState, bool) {
+	versions, _ := storedStates.Versions(lineage)
+
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].Serial >= serial {
+			continue
+		}
+
+		_, data, err := storedStates.Get(lineage, versions[i].Serial)
+		if err != nil {
+			log.Printf("Skipping alerts for %s/%d: %v", lineage, serial, err)
+			return nil, false
+		}
+
+		state, err := parser.ParseTfstate(data)
+		if err != nil {
+			log.Printf("Skipping alerts for %s/%d: %v", lineage, serial, err)
+			return nil, false
+		}
+
+		return state, true
+	}
+
+	return nil, false
+}
+
+func deliverAlert(rule models.AlertRule, alert models.Alert) {
+	if err := alertNotifier.Notify(context.Background(), rule, alert); err != nil {
+		log.Printf("Failed to deliver alert %q for %s/%d: %v", rule.Name, alert.Lineage, alert.Serial, err)
+	}
+}
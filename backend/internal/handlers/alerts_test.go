@@ -0,0 +1,185 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/alerts"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+type fakeNotifier chan models.Alert
+
+func (n fakeNotifier) Notify(_ context.Context, _ models.AlertRule, alert models.Alert) error {
+	n <- alert
+	return nil
+}
+
+func TestAlertHandlers(t *testing.T) {
+	previousStates, previousAlerts, previousNotifier := storedStates, alertStore, alertNotifier
+	storedStates = store.NewStateStore(time.Hour)
+	alertStore = store.NewAlertStore(alertHistoryLimit)
+	delivered := make(fakeNotifier, 10)
+	alertNotifier = delivered
+	t.Cleanup(func() { storedStates, alertStore, alertNotifier = previousStates, previousAlerts, previousNotifier })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/states", StatesHandler)
+	mux.HandleFunc("/alerts", AlertsHandler)
+	mux.HandleFunc("/alerts/rules", AlertRulesHandler)
+	mux.HandleFunc("/alerts/rules/{id}", AlertRuleHandler)
+
+	request := func(method, url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, url, strings.NewReader(body)))
+
+		return w
+	}
+
+	tfstate := func(serial string, users ...string) string {
+		resources := []string{}
+		for _, user := range users {
+			resources = append(resources, `{"mode": "managed", "type": "aws_iam_user", "name": "`+user+`",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "`+user+`"}}]}`)
+		}
+
+		return `{"version": 4, "terraform_version": "1.5.0", "serial": ` + serial + `, "lineage": "prod",
+			"resources": [` + strings.Join(resources, ",") + `]}`
+	}
+
+	var rule models.AlertRule
+
+	t.Run("creates rules", func(t *testing.T) {
+		w := request(http.MethodPost, "/alerts/rules", `{"name": "iam users", "condition": "added", "resource_type": "aws_iam_user", "webhooks": ["https://hooks.example.com/x"]}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&rule))
+		assert.Len(t, rule.ID, 16)
+
+		w = request(http.MethodGet, "/alerts/rules/"+rule.ID, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("rejects invalid rules", func(t *testing.T) {
+		w := request(http.MethodPost, "/alerts/rules", `{"name": "bad", "condition": "exploded"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "unknown condition")
+
+		w = request(http.MethodPost, "/alerts/rules", `{"name": "bad", "condition": "added", "channels": ["nobody"]}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "unknown channel")
+	})
+
+	t.Run("new state versions trigger alerts", func(t *testing.T) {
+		require.Equal(t, http.StatusCreated, request(http.MethodPost, "/states", tfstate("1", "ci")).Code)
+		require.Equal(t, http.StatusCreated, request(http.MethodPost, "/states", tfstate("2", "ci", "intruder")).Code)
+
+		select {
+		case alert := <-delivered:
+			assert.Equal(t, rule.ID, alert.RuleID)
+			assert.Equal(t, []string{"aws_iam_user.intruder"}, alert.Resources)
+		case <-time.After(time.Second):
+			t.Fatal("alert was not delivered")
+		}
+
+		w := request(http.MethodGet, "/alerts?lineage=prod", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var alerts []models.Alert
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&alerts))
+		require.Len(t, alerts, 1)
+		assert.Equal(t, 1, alerts[0].PreviousSerial)
+		assert.Equal(t, 2, alerts[0].Serial)
+
+		w = request(http.MethodGet, "/alerts?lineage=staging", "")
+		assert.JSONEq(t, `[]`, w.Body.String())
+	})
+
+	t.Run("deletes rules", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, request(http.MethodDelete, "/alerts/rules/"+rule.ID, "").Code)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodDelete, "/alerts/rules/"+rule.ID, "").Code)
+	})
+}
+
+func TestStateAnomalies(t *testing.T) {
+	previousStates, previousAlerts, previousNotifier := storedStates, alertStore, alertNotifier
+	storedStates = store.NewStateStore(time.Hour)
+	alertStore = store.NewAlertStore(alertHistoryLimit)
+	delivered := make(fakeNotifier, 10)
+	alertNotifier = delivered
+	t.Cleanup(func() { storedStates, alertStore, alertNotifier = previousStates, previousAlerts, previousNotifier })
+
+	tfstate := func(lineage, serial string, users ...string) string {
+		resources := []string{}
+		for _, user := range users {
+			resources = append(resources, `{"mode": "managed", "type": "aws_iam_user", "name": "`+user+`",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "`+user+`"}}]}`)
+		}
+
+		return `{"version": 4, "terraform_version": "1.5.0", "serial": ` + serial + `, "lineage": "` + lineage + `",
+			"resources": [` + strings.Join(resources, ",") + `]}`
+	}
+
+	post := func(t *testing.T, body string) models.StateVersion {
+		t.Helper()
+
+		w := httptest.NewRecorder()
+		StatesHandler(w, httptest.NewRequest(http.MethodPost, "/states", strings.NewReader(body)))
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var version models.StateVersion
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&version))
+
+		return version
+	}
+
+	t.Run("first version of a lineage", func(t *testing.T) {
+		assert.Empty(t, post(t, tfstate("prod", "1", "alice", "bob", "carol")).Anomalies)
+	})
+
+	t.Run("mass deletion is attached to the version", func(t *testing.T) {
+		version := post(t, tfstate("prod", "2", "alice"))
+
+		require.Len(t, version.Anomalies, 1)
+		assert.Equal(t, models.AnomalyMassDeletion, version.Anomalies[0].Condition)
+		assert.False(t, version.Anomalies[0].TriggeredAt.IsZero())
+
+		versions, err := storedStates.Versions("prod")
+		require.NoError(t, err)
+		assert.Equal(t, version.Anomalies, versions[1].Anomalies)
+
+		assert.Equal(t, models.AnomalyMassDeletion, alertStore.Alerts()[0].Condition)
+		assert.Equal(t, models.AnomalyMassDeletion, (<-delivered).Condition)
+	})
+
+	t.Run("lineage change", func(t *testing.T) {
+		version := post(t, tfstate("prod-copy", "1", "alice"))
+
+		require.Len(t, version.Anomalies, 1)
+		assert.Equal(t, models.AnomalyLineageChange, version.Anomalies[0].Condition)
+		assert.Equal(t, models.AnomalyLineageChange, (<-delivered).Condition)
+	})
+}
+
+func TestAnomalyThresholdsFromEnv(t *testing.T) {
+	t.Setenv("TERRASCOPE_ANOMALY_DELETION_PERCENT", "80")
+	t.Setenv("TERRASCOPE_ANOMALY_SERIAL_JUMP", "0")
+	t.Setenv("TERRASCOPE_ANOMALY_LINEAGE_OVERLAP_PERCENT", "lots")
+
+	assert.Equal(t, alerts.AnomalyThresholds{
+		DeletionPercent:       80,
+		SerialJump:            0,
+		LineageOverlapPercent: alerts.DefaultAnomalyThresholds.LineageOverlapPercent,
+	}, anomalyThresholdsFromEnv())
+}
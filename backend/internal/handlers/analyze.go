@@ -0,0 +1,732 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/terrascope/core/internal/analysis"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+	"github.com/terrascope/core/internal/registry"
+	"github.com/terrascope/core/internal/store"
+)
+
+func EndpointsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	state, ok := readState(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, r, analysis.Endpoints(state))
+}
+
+func ImportSuggestionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	state, ok := readState(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, r, analysis.ImportSuggestions(state))
+}
+
+// defaultClusterSize is the smallest cluster ClustersHandler reports unless
+// ?min_size= says otherwise.
+const defaultClusterSize = 2
+
+// ClustersHandler groups tightly connected resources of the posted state into
+// labelled clusters, for organizing large states that don't use modules.
+func ClustersHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	minSize := defaultClusterSize
+	if value := r.URL.Query().Get("min_size"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			http.Error(w, "Invalid min_size: "+value, http.StatusBadRequest)
+			return
+		}
+		minSize = n
+	}
+
+	state, ok := readState(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, r, analysis.Clusters(state, minSize))
+}
+
+// defaultSplitSize is the smallest state SplitSuggestionsHandler suggests
+// unless ?min_size= says otherwise.
+const defaultSplitSize = 5
+
+// SplitSuggestionsHandler suggests how the posted state could be split into
+// independent states with few dependencies between them.
+func SplitSuggestionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	minSize := defaultSplitSize
+	if value := r.URL.Query().Get("min_size"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			http.Error(w, "Invalid min_size: "+value, http.StatusBadRequest)
+			return
+		}
+		minSize = n
+	}
+
+	state, ok := readState(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, r, analysis.SplitSuggestions(state, minSize))
+}
+
+// defaultExtractionSize is the smallest group of resources
+// ModuleExtractionsHandler suggests extracting unless ?min_size= says
+// otherwise.
+const defaultExtractionSize = 2
+
+// ModuleExtractionsHandler suggests refactoring the groups of resources
+// repeated across the posted state into modules.
+func ModuleExtractionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	minSize := defaultExtractionSize
+	if value := r.URL.Query().Get("min_size"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			http.Error(w, "Invalid min_size: "+value, http.StatusBadRequest)
+			return
+		}
+		minSize = n
+	}
+
+	state, ok := readState(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, r, analysis.ModuleExtractions(state, minSize))
+}
+
+// OutputsHandler links the outputs of the posted state to the resources they
+// expose and to the remote state data sources, in the latest stored version
+// of every other lineage, that read them.
+func OutputsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	state, ok := readState(w, r)
+	if !ok {
+		return
+	}
+
+	consumers, err := latestStates()
+	if err != nil {
+		storageFailed(w, err)
+		return
+	}
+
+	writeJSON(w, r, analysis.Outputs(state, consumers))
+}
+
+// UnmanagedHandler compares the posted cloud inventory, an AWS Config export
+// or a CSV resource list, with the resources managed by the latest stored
+// version of every lineage, and reports the cloud resources that no stored
+// state manages.
+func UnmanagedHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	inventory, err := parser.ParseInventory(body.Bytes())
+	if err != nil {
+		http.Error(w, "Invalid inventory: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	states, err := latestStates()
+	if err != nil {
+		storageFailed(w, err)
+		return
+	}
+
+	writeJSON(w, r, analysis.Unmanaged(inventory, states))
+}
+
+// latestStates parses the latest stored version of every lineage, by
+// lineage. Versions that no longer parse are skipped.
+func latestStates() (map[string]*models.TerraformState, error) {
+	histories, err := storedStates.Lineages()
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]*models.TerraformState, len(histories))
+	for _, history := range histories {
+		_, data, err := storedStates.Get(history.Lineage, history.Latest.Serial)
+		if err != nil {
+			return nil, err
+		}
+
+		state, err := parser.ParseTfstate(data)
+		if err != nil {
+			log.Printf("Skipping %s/%d: %v", history.Lineage, history.Latest.Serial, err)
+			continue
+		}
+
+		states[history.Lineage] = state
+	}
+
+	return states, nil
+}
+
+// defaultLimitsHorizon is how many days ahead LimitsHandler projects resource
+// counts unless ?horizon_days= says otherwise.
+const defaultLimitsHorizon = 90
+
+// LimitsHandler compares the resource counts of the posted state with the
+// default quotas of their provider, and projects their growth over the
+// stored versions of its lineage, warning about the quotas reached or about
+// to be.
+func LimitsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	horizon := defaultLimitsHorizon
+	if value := r.URL.Query().Get("horizon_days"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			http.Error(w, "Invalid horizon_days: "+value, http.StatusBadRequest)
+			return
+		}
+		horizon = n
+	}
+
+	state, ok := readState(w, r)
+	if !ok {
+		return
+	}
+
+	samples, err := limitSamples(state.Lineage, state.Serial)
+	if err != nil {
+		storageFailed(w, err)
+		return
+	}
+	samples = append(samples, analysis.LimitSample{At: time.Now().UTC(), State: state})
+
+	writeJSON(w, r, analysis.Limits(samples, horizon))
+}
+
+// limitSamples parses the stored versions of a lineage older than serial, in
+// order. Archived versions and versions that no longer parse are skipped.
+func limitSamples(lineage string, serial int) ([]analysis.LimitSample, error) {
+	versions, err := storedStates.Versions(lineage)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var samples []analysis.LimitSample
+	for _, version := range versions {
+		if version.Serial >= serial {
+			break
+		}
+		if version.Archived {
+			continue
+		}
+
+		_, data, err := storedStates.Get(lineage, version.Serial)
+		if err != nil {
+			return nil, err
+		}
+
+		state, err := parser.ParseTfstate(data)
+		if err != nil {
+			log.Printf("Skipping %s/%d: %v", lineage, version.Serial, err)
+			continue
+		}
+
+		samples = append(samples, analysis.LimitSample{At: version.StoredAt, State: state})
+	}
+
+	return samples, nil
+}
+
+type ModulesRequest struct {
+	State    json.RawMessage `json:"state"`
+	Manifest json.RawMessage `json:"modules_manifest,omitempty"`
+}
+
+// moduleRegistry and providerRegistry resolve the latest published versions
+// for ModulesHandler and ProvidersHandler.
+var (
+	moduleRegistry   analysis.ModuleRegistry   = registry.NewClient()
+	providerRegistry analysis.ProviderRegistry = registry.NewClient()
+)
+
+// ModulesHandler reports the modules in a state. The optional module manifest
+// (.terraform/modules/modules.json) adds sources and versions, which are
+// compared against the registry unless ?registry=false.
+func ModulesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	var req ModulesRequest
+	if err := json.Unmarshal(body.Bytes(), &req); err != nil {
+		http.Error(w, "Invalid modules request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	state, err := parser.ParseTfstate(req.State)
+	if err != nil {
+		http.Error(w, "Invalid tfstate: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var manifest *models.ModuleManifest
+	if len(req.Manifest) > 0 {
+		if manifest, err = parser.ParseModuleManifest(req.Manifest); err != nil {
+			http.Error(w, "Invalid modules manifest: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	reg := moduleRegistry
+	if r.URL.Query().Get("registry") == "false" {
+		reg = nil
+	}
+
+	writeJSON(w, r, analysis.Modules(r.Context(), state, manifest, reg))
+}
+
+// ProvidersRequest carries the state and, optionally, the required_providers
+// constraints and the dependency lock file, either as the text of
+// .terraform.lock.hcl or as the provider versions it selects.
+type ProvidersRequest struct {
+	State             json.RawMessage                       `json:"state"`
+	RequiredProviders map[string]models.ProviderRequirement `json:"required_providers,omitempty"`
+	LockFile          string                                `json:"lock_file,omitempty"`
+	ProviderVersions  map[string]string                     `json:"provider_versions,omitempty"`
+}
+
+// ProvidersHandler audits the providers in a state against the optional
+// required_providers constraints and the provider versions selected in the
+// lock file, comparing them with the registry unless ?registry=false.
+func ProvidersHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	var req ProvidersRequest
+	if err := json.Unmarshal(body.Bytes(), &req); err != nil {
+		http.Error(w, "Invalid providers request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	state, err := parser.ParseTfstate(req.State)
+	if err != nil {
+		http.Error(w, "Invalid tfstate: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var lock *models.DependencyLock
+	if req.LockFile != "" {
+		if lock, err = parser.ParseDependencyLock([]byte(req.LockFile)); err != nil {
+			http.Error(w, "Invalid lock file: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else if req.ProviderVersions != nil {
+		lock = &models.DependencyLock{Providers: make(map[string]models.LockedProvider, len(req.ProviderVersions))}
+		for address, version := range req.ProviderVersions {
+			lock.Providers[address] = models.LockedProvider{Version: version}
+		}
+	}
+
+	reg := providerRegistry
+	if r.URL.Query().Get("registry") == "false" {
+		reg = nil
+	}
+
+	writeJSON(w, r, analysis.Providers(r.Context(), state, req.RequiredProviders, lock, reg))
+}
+
+// namingPolicy is the naming standard NamingHandler checks states against,
+// loaded from the JSON file named by TERRASCOPE_NAMING_POLICY. Without one,
+// no resource is reported.
+var namingPolicy = namingPolicyFromEnv()
+
+func namingPolicyFromEnv() *analysis.NamingPolicy {
+	file := os.Getenv("TERRASCOPE_NAMING_POLICY")
+	if file == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		log.Printf("Ignoring TERRASCOPE_NAMING_POLICY: %v", err)
+		return nil
+	}
+
+	policy, err := analysis.ParseNamingPolicy(data)
+	if err != nil {
+		log.Printf("Ignoring TERRASCOPE_NAMING_POLICY: %v", err)
+		return nil
+	}
+
+	return policy
+}
+
+// NamingHandler reports the resources of the posted state whose names break
+// the naming policy.
+func NamingHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	state, ok := readState(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, r, analysis.CheckNaming(state, namingPolicy))
+}
+
+func init() {
+	analysis.Register(analysis.NamingAnalyzer{Policy: namingPolicy})
+}
+
+// AnalyzersHandler lists the analyzers AnalyzeHandler can run.
+func AnalyzersHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeJSON(w, r, analysis.Manifest())
+}
+
+// defaultAnalysisTimeout bounds how long the analyzers of a request can run
+// unless TERRASCOPE_ANALYSIS_TIMEOUT says otherwise.
+const defaultAnalysisTimeout = time.Minute
+
+// analysisTimeout bounds how long AnalyzeHandler and ComplianceHandler run
+// analyzers. The findings of the analyzers that completed in time are still
+// returned, marked partial.
+var analysisTimeout = analysisTimeoutFromEnv()
+
+func analysisTimeoutFromEnv() time.Duration {
+	value := os.Getenv("TERRASCOPE_ANALYSIS_TIMEOUT")
+	if value == "" {
+		return defaultAnalysisTimeout
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		log.Printf("Ignoring TERRASCOPE_ANALYSIS_TIMEOUT: invalid value %q", value)
+		return defaultAnalysisTimeout
+	}
+
+	return timeout
+}
+
+// PartialHeader lists the analyzers that ran out of time, separated by
+// commas, when a response only holds the findings of the others. Unlike the
+// partial flag of JSON reports, it also marks SARIF and JUnit responses.
+const PartialHeader = "X-Partial-Results"
+
+// markPartial marks the response as partial when analyzers are incomplete,
+// keeping it out of the results cache.
+func markPartial(w http.ResponseWriter, incomplete []string) {
+	if len(incomplete) == 0 {
+		return
+	}
+
+	w.Header().Set(PartialHeader, strings.Join(incomplete, ","))
+	w.Header().Set("Cache-Control", "no-store")
+}
+
+// withoutAnalyzers drops the findings of the named analyzers.
+func withoutAnalyzers(findings []models.Finding, names []string) []models.Finding {
+	kept := findings[:0:0]
+	for _, finding := range findings {
+		if !slices.Contains(names, finding.Analyzer) {
+			kept = append(kept, finding)
+		}
+	}
+
+	return kept
+}
+
+// sarifContentType is the media type of SARIF logs.
+const sarifContentType = "application/sarif+json"
+
+// defaultSarifArtifact is the state file SARIF results are located in unless
+// ?artifact= names the path of the state in the repository.
+const defaultSarifArtifact = "terraform.tfstate"
+
+// AnalyzeHandler runs the analyzers selected by ?analyzers=, every analyzer
+// by default, over the posted state and returns their findings with the
+// dependency graph they refer to. With ?format=sarif it returns only the
+// findings, as a SARIF 2.1.0 log for code scanning, and with ?format=junit as
+// a JUnit XML report for CI, where the findings at or above the ?fail_on=
+// severity fail, every finding by default.
+//
+// With ?baseline=previous it only returns the findings the previous stored
+// version of the state's lineage did not have, or every finding when none is
+// stored, and with ?baseline=<serial> those a given stored version did not
+// have.
+func AnalyzeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "sarif" && format != "junit" {
+		http.Error(w, "Unknown format: "+format, http.StatusBadRequest)
+		return
+	}
+
+	failOn := models.SeverityInfo
+	if value := r.URL.Query().Get("fail_on"); value != "" {
+		var err error
+		if failOn, err = analysis.ParseSeverity(value); err != nil {
+			http.Error(w, "Invalid fail_on: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	baseline := r.URL.Query().Get("baseline")
+	if baseline != "" && baseline != baselinePrevious {
+		if _, err := strconv.Atoi(baseline); err != nil {
+			http.Error(w, "Invalid baseline: "+baseline, http.StatusBadRequest)
+			return
+		}
+	}
+
+	selected, ok := selectedAnalyzers(w, r)
+	if !ok {
+		return
+	}
+
+	state, graph, ok := readStateGraph(w, r)
+	if !ok {
+		return
+	}
+
+	graph.Stats = parser.ComputeStats(graph)
+
+	ctx, cancel := context.WithTimeout(r.Context(), analysisTimeout)
+	defer cancel()
+
+	findings, incomplete := analysis.RunAnalyzersContext(ctx, selected, graph, state)
+
+	var summary *models.BaselineSummary
+	if baseline != "" {
+		w.Header().Set("Cache-Control", "no-store")
+
+		version, data, err := baselineVersion(state, baseline)
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			http.Error(w, "State not found: "+state.Lineage+"/"+baseline, http.StatusNotFound)
+			return
+		case errors.Is(err, store.ErrArchived):
+			http.Error(w, "Baseline is archived to cold storage: "+state.Lineage+"/"+strconv.Itoa(version.Serial), http.StatusConflict)
+			return
+		case err != nil:
+			storageFailed(w, err)
+			return
+		}
+
+		if data != nil {
+			previous, previousGraph, err := parseGraph(data)
+			if err != nil {
+				http.Error(w, "Invalid baseline tfstate: "+err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+
+			// Without the findings of the baseline, those of an analyzer
+			// cannot be told new.
+			known, missed := analysis.RunAnalyzersContext(ctx, selected, previousGraph, previous)
+			for _, name := range missed {
+				if !slices.Contains(incomplete, name) {
+					incomplete = append(incomplete, name)
+				}
+			}
+
+			summary = &models.BaselineSummary{Lineage: version.Lineage, Serial: version.Serial}
+			findings, summary.Known = analysis.NewFindings(withoutAnalyzers(findings, missed), known)
+		}
+	}
+
+	markPartial(w, incomplete)
+
+	switch format {
+	case "sarif":
+		artifact := r.URL.Query().Get("artifact")
+		if artifact == "" {
+			artifact = defaultSarifArtifact
+		}
+
+		w.Header().Set("Content-Type", sarifContentType)
+
+		if err := json.NewEncoder(w).Encode(analysis.SARIF(findings, artifact)); err != nil {
+			log.Printf("Error encoding response: %v", err)
+		}
+	case "junit":
+		names := make([]string, len(selected))
+		for i, analyzer := range selected {
+			names[i] = analyzer.Name()
+		}
+
+		writeXML(w, analysis.JUnit(names, findings, failOn))
+	default:
+		writeJSON(w, r, models.AnalysisReport{
+			Findings:   findings,
+			Baseline:   summary,
+			Graph:      graph,
+			Partial:    len(incomplete) > 0,
+			Incomplete: incomplete,
+		})
+	}
+}
+
+// ComplianceHandler runs the analyzers selected by ?analyzers=, every analyzer
+// by default, over the posted state and reports the coverage of the
+// compliance framework controls their checks map to, as audit evidence.
+func ComplianceHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	selected, ok := selectedAnalyzers(w, r)
+	if !ok {
+		return
+	}
+
+	state, graph, ok := readStateGraph(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), analysisTimeout)
+	defer cancel()
+
+	findings, incomplete := analysis.RunAnalyzersContext(ctx, selected, graph, state)
+
+	// Controls are only assessed by the analyzers that completed.
+	var names []string
+	for _, analyzer := range selected {
+		if !slices.Contains(incomplete, analyzer.Name()) {
+			names = append(names, analyzer.Name())
+		}
+	}
+
+	report := analysis.Compliance(names, findings)
+	report.Partial, report.Incomplete = len(incomplete) > 0, incomplete
+
+	markPartial(w, incomplete)
+	writeJSON(w, r, report)
+}
+
+// baselinePrevious selects the previous stored version of the state's
+// lineage as the baseline of AnalyzeHandler.
+const baselinePrevious = "previous"
+
+// baselineVersion returns the stored version of the state's lineage that
+// ?baseline= selects: the given serial, or with baselinePrevious the latest
+// version older than the state, if any. Without one, it returns no data.
+func baselineVersion(state *models.TerraformState, baseline string) (models.StateVersion, []byte, error) {
+	if baseline != baselinePrevious {
+		serial, _ := strconv.Atoi(baseline)
+		return storedStates.Get(state.Lineage, serial)
+	}
+
+	versions, err := storedStates.Versions(state.Lineage)
+	if errors.Is(err, store.ErrNotFound) {
+		return models.StateVersion{}, nil, nil
+	} else if err != nil {
+		return models.StateVersion{}, nil, err
+	}
+
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].Serial < state.Serial {
+			return storedStates.Get(state.Lineage, versions[i].Serial)
+		}
+	}
+
+	return models.StateVersion{}, nil, nil
+}
+
+// selectedAnalyzers reads ?analyzers=. On failure it writes a 400 response
+// and returns false.
+func selectedAnalyzers(w http.ResponseWriter, r *http.Request) ([]analysis.Analyzer, bool) {
+	selected, err := analysis.SelectAnalyzers(r.URL.Query().Get("analyzers"))
+	if err != nil {
+		http.Error(w, "Invalid analyzers: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	return selected, true
+}
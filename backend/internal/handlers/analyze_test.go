@@ -0,0 +1,1242 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/analysis"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/registry"
+	"github.com/terrascope/core/internal/store"
+)
+
+func TestEndpointsHandler(t *testing.T) {
+	endpointTfstate := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"serial": 1,
+		"lineage": "abc-123",
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_lb",
+				"name": "web",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "arn:lb/web", "arn": "arn:lb/web", "dns_name": "web.elb.amazonaws.com", "internal": false}}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_route53_record",
+				"name": "www",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "Z1_www", "name": "www.example.com", "type": "CNAME", "records": ["web.elb.amazonaws.com"]}}]
+			}
+		]
+	}`
+
+	t.Run("returns exposure map", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/analyze/endpoints", strings.NewReader(endpointTfstate))
+		w := httptest.NewRecorder()
+
+		EndpointsHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var report models.EndpointReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+
+		require.Len(t, report.Endpoints, 1)
+		assert.Equal(t, "www.example.com", report.Endpoints[0].Hostname)
+		assert.Equal(t, "aws_lb.web", report.Endpoints[0].LoadBalancer)
+		assert.True(t, report.Endpoints[0].InternetFacing)
+		require.Len(t, report.LoadBalancers, 1)
+	})
+
+	t.Run("returns 405 for GET request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/analyze/endpoints", nil)
+		w := httptest.NewRecorder()
+
+		EndpointsHandler(w, req)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+
+	t.Run("returns 400 for invalid tfstate", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/analyze/endpoints", strings.NewReader(`{invalid`))
+		w := httptest.NewRecorder()
+
+		EndpointsHandler(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid tfstate")
+	})
+}
+
+func TestImportSuggestionsHandler(t *testing.T) {
+	t.Run("returns suggestions for unmanaged ids", func(t *testing.T) {
+		tfstate := `{
+			"version": 4,
+			"terraform_version": "1.5.0",
+			"serial": 1,
+			"lineage": "abc-123",
+			"resources": [
+				{
+					"mode": "managed",
+					"type": "aws_instance",
+					"name": "web",
+					"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+					"instances": [{"attributes": {"id": "i-0123456789abcdef0", "subnet_id": "subnet-0abc12345"}}]
+				}
+			]
+		}`
+
+		req := httptest.NewRequest(http.MethodPost, "/analyze/import-suggestions", strings.NewReader(tfstate))
+		w := httptest.NewRecorder()
+
+		ImportSuggestionsHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var report models.ImportReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+
+		require.Len(t, report.Suggestions, 1)
+		assert.Equal(t, "subnet-0abc12345", report.Suggestions[0].ID)
+		assert.Equal(t, []string{"aws_instance.web"}, report.Suggestions[0].ReferencedBy)
+	})
+
+	t.Run("returns 405 for GET request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/analyze/import-suggestions", nil)
+		w := httptest.NewRecorder()
+
+		ImportSuggestionsHandler(w, req)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
+
+func TestModulesHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"modules": [{"versions": [{"version": "5.1.0"}, {"version": "5.8.1"}]}]}`))
+	}))
+	defer server.Close()
+
+	original := moduleRegistry
+	moduleRegistry = &registry.Client{BaseURL: server.URL, HTTPClient: server.Client()}
+	defer func() { moduleRegistry = original }()
+
+	modulesBody := `{
+		"state": {
+			"version": 4,
+			"terraform_version": "1.5.0",
+			"serial": 1,
+			"lineage": "abc-123",
+			"resources": [
+				{
+					"mode": "managed",
+					"type": "aws_vpc",
+					"name": "this",
+					"module": "module.vpc",
+					"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+					"instances": [{"attributes": {"id": "vpc-1"}}]
+				}
+			]
+		},
+		"modules_manifest": {
+			"Modules": [
+				{"Key": "", "Source": "", "Dir": "."},
+				{"Key": "vpc", "Source": "registry.terraform.io/terraform-aws-modules/vpc/aws", "Version": "5.1.0", "Dir": ".terraform/modules/vpc"}
+			]
+		}
+	}`
+
+	t.Run("reports outdated registry modules", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/analyze/modules", strings.NewReader(modulesBody))
+		w := httptest.NewRecorder()
+
+		ModulesHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var report models.ModuleReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+
+		require.Len(t, report.Modules, 1)
+		assert.Equal(t, "vpc", report.Modules[0].Key)
+		assert.Equal(t, "5.1.0", report.Modules[0].Version)
+		assert.Equal(t, "5.8.1", report.Modules[0].LatestVersion)
+		assert.True(t, report.Modules[0].Outdated)
+	})
+
+	t.Run("skips the registry when disabled", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/analyze/modules?registry=false", strings.NewReader(modulesBody))
+		w := httptest.NewRecorder()
+
+		ModulesHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var report models.ModuleReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+
+		require.Len(t, report.Modules, 1)
+		assert.Empty(t, report.Modules[0].LatestVersion)
+		assert.False(t, report.Modules[0].Outdated)
+	})
+
+	t.Run("returns 405 for GET request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/analyze/modules", nil)
+		w := httptest.NewRecorder()
+
+		ModulesHandler(w, req)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+
+	t.Run("returns 400 for invalid request body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/analyze/modules", strings.NewReader(`{invalid`))
+		w := httptest.NewRecorder()
+
+		ModulesHandler(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid modules request")
+	})
+
+	t.Run("returns 400 for invalid manifest", func(t *testing.T) {
+		body := `{"state": {"version": 4, "terraform_version": "1.5.0"}, "modules_manifest": "not a manifest"}`
+		req := httptest.NewRequest(http.MethodPost, "/analyze/modules", strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		ModulesHandler(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid modules manifest")
+	})
+}
+
+func TestProvidersHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"versions": [{"version": "4.67.0"}, {"version": "5.31.0"}]}`))
+	}))
+	defer server.Close()
+
+	original := providerRegistry
+	providerRegistry = &registry.Client{BaseURL: server.URL, HTTPClient: server.Client()}
+	defer func() { providerRegistry = original }()
+
+	providersBody := `{
+		"state": {
+			"version": 4,
+			"terraform_version": "1.5.0",
+			"serial": 1,
+			"lineage": "abc-123",
+			"resources": [
+				{
+					"mode": "managed",
+					"type": "aws_vpc",
+					"name": "main",
+					"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+					"instances": [{"attributes": {"id": "vpc-1"}}]
+				}
+			]
+		},
+		"required_providers": {
+			"aws": {"source": "hashicorp/aws", "version_constraints": ["~> 3.0"]}
+		},
+		"provider_versions": {
+			"registry.terraform.io/hashicorp/aws": "4.67.0"
+		}
+	}`
+
+	decode := func(t *testing.T, w *httptest.ResponseRecorder) models.ProviderReport {
+		t.Helper()
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var report models.ProviderReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+
+		return report
+	}
+
+	warningTypes := func(report models.ProviderReport) []string {
+		types := []string{}
+		for _, warning := range report.Warnings {
+			types = append(types, warning.Type)
+		}
+		return types
+	}
+
+	t.Run("reports mismatches and outdated providers", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/analyze/providers", strings.NewReader(providersBody))
+		w := httptest.NewRecorder()
+
+		ProvidersHandler(w, req)
+
+		report := decode(t, w)
+		require.Len(t, report.Providers, 1)
+		assert.Equal(t, "5.31.0", report.Providers[0].LatestVersion)
+		assert.ElementsMatch(t, []string{"constraint_mismatch", "outdated_provider"}, warningTypes(report))
+	})
+
+	t.Run("skips the registry when disabled", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/analyze/providers?registry=false", strings.NewReader(providersBody))
+		w := httptest.NewRecorder()
+
+		ProvidersHandler(w, req)
+
+		report := decode(t, w)
+		assert.Empty(t, report.Providers[0].LatestVersion)
+		assert.Equal(t, []string{"constraint_mismatch"}, warningTypes(report))
+	})
+
+	t.Run("reads the lock file", func(t *testing.T) {
+		body := `{
+			"state": {
+				"version": 4,
+				"terraform_version": "1.5.0",
+				"resources": [
+					{
+						"mode": "managed",
+						"type": "aws_vpc",
+						"name": "main",
+						"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+						"instances": [{"attributes": {"id": "vpc-1"}}]
+					}
+				]
+			},
+			"lock_file": "provider \"registry.terraform.io/hashicorp/aws\" {\n  version = \"5.31.0\"\n  hashes = [\n    \"h1:abc=\",\n  ]\n}\n\nprovider \"registry.terraform.io/hashicorp/null\" {\n  version = \"3.2.2\"\n}\n"
+		}`
+		req := httptest.NewRequest(http.MethodPost, "/analyze/providers?registry=false", strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		ProvidersHandler(w, req)
+
+		report := decode(t, w)
+		require.Len(t, report.Providers, 2)
+		assert.Equal(t, "5.31.0", report.Providers[0].Version)
+		assert.Equal(t, []string{"h1:abc="}, report.Providers[0].Hashes)
+		assert.Equal(t, []string{"unused_locked_provider"}, warningTypes(report))
+	})
+
+	t.Run("returns 400 for an invalid lock file", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/analyze/providers", strings.NewReader(`{"state": {"version": 4, "terraform_version": "1.5.0", "resources": []}, "lock_file": "provider {"}`))
+		w := httptest.NewRecorder()
+
+		ProvidersHandler(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid lock file")
+	})
+
+	t.Run("returns 405 for GET request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/analyze/providers", nil)
+		w := httptest.NewRecorder()
+
+		ProvidersHandler(w, req)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+
+	t.Run("returns 400 for invalid request body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/analyze/providers", strings.NewReader(`{invalid`))
+		w := httptest.NewRecorder()
+
+		ProvidersHandler(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid providers request")
+	})
+
+	t.Run("returns 400 when the state is missing", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/analyze/providers", strings.NewReader(`{"required_providers": {}}`))
+		w := httptest.NewRecorder()
+
+		ProvidersHandler(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid tfstate")
+	})
+}
+
+func TestOutputsHandler(t *testing.T) {
+	previous := storedStates
+	storedStates = store.NewStateStore(time.Hour)
+	t.Cleanup(func() { storedStates = previous })
+
+	consumer := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"serial": 3,
+		"lineage": "app",
+		"resources": [
+			{
+				"mode": "data",
+				"type": "terraform_remote_state",
+				"name": "network",
+				"provider": "provider[\"terraform.io/builtin/terraform\"]",
+				"instances": [{"attributes": {"outputs": {"value": {"vpc_id": "vpc-0a1b2c"}, "type": ["object", {"vpc_id": "string"}]}}}]
+			}
+		]
+	}`
+	require.NoError(t, storedStates.Put(models.StateVersion{Lineage: "app", Serial: 3}, []byte(consumer)))
+
+	tfstate := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"lineage": "network",
+		"outputs": {"vpc_id": {"value": "vpc-0a1b2c", "type": "string"}},
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_vpc",
+				"name": "main",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "vpc-0a1b2c"}}]
+			}
+		]
+	}`
+
+	t.Run("links outputs to resources and consumers", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		OutputsHandler(w, httptest.NewRequest(http.MethodPost, "/analyze/outputs", strings.NewReader(tfstate)))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var graph models.OutputGraph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+
+		assert.Equal(t, []models.OutputEdge{
+			{Source: "output.vpc_id", Target: "aws_vpc.main", Type: "value", Attribute: "id"},
+			{Source: "app:terraform_remote_state.network", Target: "output.vpc_id", Type: "consumer"},
+		}, graph.Edges)
+	})
+
+	t.Run("returns 405 for GET request", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		OutputsHandler(w, httptest.NewRequest(http.MethodGet, "/analyze/outputs", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
+
+func TestUnmanagedHandler(t *testing.T) {
+	previous := storedStates
+	storedStates = store.NewStateStore(time.Hour)
+	t.Cleanup(func() { storedStates = previous })
+
+	tfstate := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"serial": 1,
+		"lineage": "network",
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_vpc",
+				"name": "main",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "vpc-0a1b2c"}}]
+			}
+		]
+	}`
+	require.NoError(t, storedStates.Put(models.StateVersion{Lineage: "network", Serial: 1}, []byte(tfstate)))
+
+	t.Run("reports the resources no stored state manages", func(t *testing.T) {
+		inventory := "Resource ID,Resource Type,Region\nvpc-0a1b2c,AWS::EC2::VPC,eu-west-1\nvpc-9f8e7d,AWS::EC2::VPC,eu-west-1\n"
+
+		w := httptest.NewRecorder()
+		UnmanagedHandler(w, httptest.NewRequest(http.MethodPost, "/analyze/unmanaged", strings.NewReader(inventory)))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		assert.JSONEq(t, `{"total": 2, "managed": 1, "by_type": {"AWS::EC2::VPC": 1},
+			"unmanaged": [{"id": "vpc-9f8e7d", "type": "AWS::EC2::VPC", "region": "eu-west-1"}]}`, w.Body.String())
+	})
+
+	t.Run("rejects invalid inventories", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		UnmanagedHandler(w, httptest.NewRequest(http.MethodPost, "/analyze/unmanaged", strings.NewReader("name\nweb\n")))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "Invalid inventory: invalid inventory CSV: no id or arn column\n", w.Body.String())
+	})
+
+	t.Run("returns 405 for GET request", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		UnmanagedHandler(w, httptest.NewRequest(http.MethodGet, "/analyze/unmanaged", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
+
+func TestClustersHandler(t *testing.T) {
+	tfstate := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_vpc",
+				"name": "main",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "vpc-1"}}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_subnet",
+				"name": "a",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "subnet-1"}, "dependencies": ["aws_vpc.main"]}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_s3_bucket",
+				"name": "logs",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "logs"}}]
+			}
+		]
+	}`
+
+	t.Run("returns clusters", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ClustersHandler(w, httptest.NewRequest(http.MethodPost, "/analyze/clusters", strings.NewReader(tfstate)))
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var report models.ClusterReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+
+		require.Len(t, report.Clusters, 1)
+		assert.Equal(t, []string{"aws_subnet.a", "aws_vpc.main"}, report.Clusters[0].Nodes)
+		assert.Equal(t, 1, report.Unclustered)
+	})
+
+	t.Run("min_size", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ClustersHandler(w, httptest.NewRequest(http.MethodPost, "/analyze/clusters?min_size=1", strings.NewReader(tfstate)))
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var report models.ClusterReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+
+		assert.Len(t, report.Clusters, 2)
+	})
+
+	t.Run("invalid min_size", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ClustersHandler(w, httptest.NewRequest(http.MethodPost, "/analyze/clusters?min_size=0", strings.NewReader(tfstate)))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid min_size: 0")
+	})
+
+	t.Run("invalid tfstate", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ClustersHandler(w, httptest.NewRequest(http.MethodPost, "/analyze/clusters", strings.NewReader("{")))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ClustersHandler(w, httptest.NewRequest(http.MethodGet, "/analyze/clusters", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
+
+func TestSplitSuggestionsHandler(t *testing.T) {
+	tfstate := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_vpc",
+				"name": "main",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "vpc-1"}}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_subnet",
+				"name": "a",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "subnet-1"}, "dependencies": ["aws_vpc.main"]}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_s3_bucket",
+				"name": "logs",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "logs"}}]
+			}
+		]
+	}`
+
+	t.Run("returns splits", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		SplitSuggestionsHandler(w, httptest.NewRequest(http.MethodPost, "/analyze/split-suggestions?min_size=2", strings.NewReader(tfstate)))
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var report models.SplitReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+
+		assert.Equal(t, 2, report.Components)
+		require.Len(t, report.Splits, 2)
+		assert.Equal(t, []string{"aws_subnet.a", "aws_vpc.main"}, report.Splits[0].Nodes)
+		assert.Empty(t, report.CrossDependencies)
+	})
+
+	t.Run("default min_size", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		SplitSuggestionsHandler(w, httptest.NewRequest(http.MethodPost, "/analyze/split-suggestions", strings.NewReader(tfstate)))
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var report models.SplitReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+
+		assert.Len(t, report.Splits, 1, "components smaller than min_size are kept together")
+	})
+
+	t.Run("invalid min_size", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		SplitSuggestionsHandler(w, httptest.NewRequest(http.MethodPost, "/analyze/split-suggestions?min_size=x", strings.NewReader(tfstate)))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid min_size: x")
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		SplitSuggestionsHandler(w, httptest.NewRequest(http.MethodGet, "/analyze/split-suggestions", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
+
+func TestModuleExtractionsHandler(t *testing.T) {
+	tfstate := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_s3_bucket",
+				"name": "a",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "a", "bucket": "a"}}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_s3_bucket_versioning",
+				"name": "a",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "a", "bucket": "a"}, "dependencies": ["aws_s3_bucket.a"]}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_s3_bucket",
+				"name": "b",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "b", "bucket": "b"}}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_s3_bucket_versioning",
+				"name": "b",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "b", "bucket": "b"}, "dependencies": ["aws_s3_bucket.b"]}]
+			}
+		]
+	}`
+
+	t.Run("returns suggestions", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ModuleExtractionsHandler(w, httptest.NewRequest(http.MethodPost, "/analyze/module-suggestions", strings.NewReader(tfstate)))
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var report models.ExtractionReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+
+		require.Len(t, report.Suggestions, 1)
+		assert.Equal(t, [][]string{
+			{"aws_s3_bucket.a", "aws_s3_bucket_versioning.a"},
+			{"aws_s3_bucket.b", "aws_s3_bucket_versioning.b"},
+		}, report.Suggestions[0].Occurrences)
+		assert.Equal(t, []models.ModuleVariable{{
+			Name:       "bucket",
+			Attributes: []string{"aws_s3_bucket.a.bucket", "aws_s3_bucket_versioning.a.bucket"},
+			Values:     []any{"a", "b"},
+		}}, report.Suggestions[0].Variables)
+	})
+
+	t.Run("min_size", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ModuleExtractionsHandler(w, httptest.NewRequest(http.MethodPost, "/analyze/module-suggestions?min_size=3", strings.NewReader(tfstate)))
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var report models.ExtractionReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+
+		assert.Empty(t, report.Suggestions)
+	})
+
+	t.Run("invalid min_size", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ModuleExtractionsHandler(w, httptest.NewRequest(http.MethodPost, "/analyze/module-suggestions?min_size=-1", strings.NewReader(tfstate)))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid min_size: -1")
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ModuleExtractionsHandler(w, httptest.NewRequest(http.MethodGet, "/analyze/module-suggestions", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
+
+func TestNamingHandler(t *testing.T) {
+	tfstate := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_s3_bucket",
+				"name": "logs",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "Logs_Bucket", "bucket": "Logs_Bucket"}}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_s3_bucket",
+				"name": "assets",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "acme-assets", "bucket": "acme-assets"}}]
+			}
+		]
+	}`
+
+	analyze := func(t *testing.T) models.FindingsReport {
+		t.Helper()
+
+		w := httptest.NewRecorder()
+		NamingHandler(w, httptest.NewRequest(http.MethodPost, "/analyze/naming", strings.NewReader(tfstate)))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var report models.FindingsReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+
+		return report
+	}
+
+	t.Run("without a policy", func(t *testing.T) {
+		assert.Empty(t, analyze(t).Findings)
+	})
+
+	t.Run("reports names breaking the policy", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "naming.json")
+		require.NoError(t, os.WriteFile(file, []byte(`{"rules": [
+			{"name": "bucket prefix", "resource_type": "aws_s3_bucket", "attribute": "bucket", "pattern": "^acme-[a-z0-9-]+$"}
+		]}`), 0o600))
+		t.Setenv("TERRASCOPE_NAMING_POLICY", file)
+
+		previous := namingPolicy
+		namingPolicy = namingPolicyFromEnv()
+		t.Cleanup(func() { namingPolicy = previous })
+		require.NotNil(t, namingPolicy)
+
+		report := analyze(t)
+
+		require.Len(t, report.Findings, 1)
+		assert.Equal(t, "aws_s3_bucket.logs", report.Findings[0].Address)
+		assert.Equal(t, "bucket prefix", report.Findings[0].Check)
+	})
+
+	t.Run("invalid policy files are ignored", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "naming.json")
+		require.NoError(t, os.WriteFile(file, []byte(`{"rules": [{"name": "bad", "pattern": "("}]}`), 0o600))
+		t.Setenv("TERRASCOPE_NAMING_POLICY", file)
+
+		assert.Nil(t, namingPolicyFromEnv())
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		NamingHandler(w, httptest.NewRequest(http.MethodGet, "/analyze/naming", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
+
+func TestAnalyzersHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	AnalyzersHandler(w, httptest.NewRequest(http.MethodGet, "/analyzers", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var manifest models.AnalyzerManifest
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&manifest))
+
+	var names []string
+	for _, analyzer := range manifest.Analyzers {
+		names = append(names, analyzer.Name)
+	}
+	assert.Equal(t, []string{"naming", "secrets", "security", "stale", "tags", "unused"}, names)
+}
+
+func TestAnalyzeHandler(t *testing.T) {
+	tfstate := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_s3_bucket",
+				"name": "site",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"status": "tainted", "attributes": {"id": "site", "acl": "public-read", "tags": {}}}]
+			}
+		]
+	}`
+
+	analyze := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		AnalyzeHandler(w, httptest.NewRequest(http.MethodPost, "/analyze"+query, strings.NewReader(tfstate)))
+
+		return w
+	}
+
+	checks := func(t *testing.T, w *httptest.ResponseRecorder) []string {
+		t.Helper()
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var report models.AnalysisReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+
+		require.NotNil(t, report.Graph)
+		require.Len(t, report.Graph.Nodes, 1)
+		assert.Equal(t, 1, report.Graph.Stats.TotalNodes)
+
+		checks := []string{}
+		for _, finding := range report.Findings {
+			assert.Equal(t, report.Graph.Nodes[0].ID, finding.NodeID, "findings refer to the nodes of the graph")
+			assert.NotEmpty(t, finding.ID)
+			checks = append(checks, finding.Analyzer+"/"+finding.Check)
+		}
+		return checks
+	}
+
+	t.Run("runs every analyzer by default", func(t *testing.T) {
+		assert.Equal(t, []string{"security/public_bucket", "stale/tainted", "tags/untagged"}, checks(t, analyze("")))
+	})
+
+	t.Run("runs the selected analyzers", func(t *testing.T) {
+		assert.Equal(t, []string{"tags/untagged", "stale/tainted"}, checks(t, analyze("?analyzers=tags,stale")))
+		assert.Equal(t, []string{"security/public_bucket", "tags/untagged"}, checks(t, analyze("?analyzers=-stale")))
+	})
+
+	t.Run("rejects unknown analyzers", func(t *testing.T) {
+		w := analyze("?analyzers=lint")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `unknown analyzer "lint"`)
+	})
+
+	t.Run("reports SARIF", func(t *testing.T) {
+		w := analyze("?format=sarif&analyzers=security&artifact=envs/prod/terraform.tfstate")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/sarif+json", w.Header().Get("Content-Type"))
+
+		var log models.SarifLog
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&log))
+
+		require.Len(t, log.Runs, 1)
+		require.Len(t, log.Runs[0].Results, 1)
+
+		result := log.Runs[0].Results[0]
+		assert.Equal(t, "security/public_bucket", result.RuleID)
+		assert.Equal(t, "envs/prod/terraform.tfstate", result.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	})
+
+	t.Run("reports JUnit XML", func(t *testing.T) {
+		junit := func(query string) models.JUnitTestSuites {
+			t.Helper()
+
+			w := analyze("?format=junit&analyzers=security,stale,naming" + query)
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "application/xml", w.Header().Get("Content-Type"))
+			assert.True(t, strings.HasPrefix(w.Body.String(), xml.Header))
+
+			var report models.JUnitTestSuites
+			require.NoError(t, xml.NewDecoder(w.Body).Decode(&report))
+			return report
+		}
+
+		report := junit("")
+		assert.Equal(t, 3, report.Tests)
+		assert.Equal(t, 2, report.Failures)
+		require.Len(t, report.Suites, 3)
+		assert.Equal(t, "naming", report.Suites[2].Name)
+
+		assert.Equal(t, 0, junit("&fail_on=critical").Failures)
+		assert.Equal(t, 2, junit("&fail_on=high").Failures, "tainted and public buckets are errors")
+
+		w := analyze("?format=junit&fail_on=severe")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid fail_on")
+	})
+
+	t.Run("rejects unknown formats", func(t *testing.T) {
+		w := analyze("?format=csv")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Unknown format: csv")
+	})
+}
+
+func TestAnalyzeHandlerBaseline(t *testing.T) {
+	previous := storedStates
+	storedStates = store.NewStateStore(time.Hour)
+	t.Cleanup(func() { storedStates = previous })
+
+	tfstate := func(serial int, buckets ...string) string {
+		resources := make([]string, len(buckets))
+		for i, bucket := range buckets {
+			resources[i] = `{
+				"mode": "managed",
+				"type": "aws_s3_bucket",
+				"name": "` + bucket + `",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "` + bucket + `", "acl": "public-read"}}]
+			}`
+		}
+
+		return `{"version": 4, "terraform_version": "1.5.0", "lineage": "web", "serial": ` + strconv.Itoa(serial) +
+			`, "resources": [` + strings.Join(resources, ",") + `]}`
+	}
+
+	require.NoError(t, storedStates.Put(models.StateVersion{Lineage: "web", Serial: 1}, []byte(tfstate(1))))
+	require.NoError(t, storedStates.Put(models.StateVersion{Lineage: "web", Serial: 2}, []byte(tfstate(2, "site"))))
+	require.NoError(t, storedStates.Put(models.StateVersion{Lineage: "web", Serial: 3}, []byte(tfstate(3, "site", "logs"))))
+
+	analyze := func(query, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		AnalyzeHandler(w, httptest.NewRequest(http.MethodPost, "/analyze?analyzers=security"+query, strings.NewReader(body)))
+
+		return w
+	}
+
+	report := func(t *testing.T, w *httptest.ResponseRecorder) models.AnalysisReport {
+		t.Helper()
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+		var report models.AnalysisReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+		return report
+	}
+
+	t.Run("reports the findings new since the previous version", func(t *testing.T) {
+		got := report(t, analyze("&baseline=previous", tfstate(4, "site", "logs", "assets")))
+
+		require.Len(t, got.Findings, 1)
+		assert.Equal(t, "aws_s3_bucket.assets", got.Findings[0].Address)
+		assert.Equal(t, &models.BaselineSummary{Lineage: "web", Serial: 3, Known: 2}, got.Baseline)
+	})
+
+	t.Run("compares a stored version with the one before it", func(t *testing.T) {
+		got := report(t, analyze("&baseline=previous", tfstate(3, "site", "logs")))
+
+		require.Len(t, got.Findings, 1)
+		assert.Equal(t, 2, got.Baseline.Serial)
+	})
+
+	t.Run("reports the findings new since a given version", func(t *testing.T) {
+		got := report(t, analyze("&baseline=1", tfstate(4, "site", "logs")))
+
+		assert.Len(t, got.Findings, 2)
+		assert.Equal(t, &models.BaselineSummary{Lineage: "web", Serial: 1}, got.Baseline)
+	})
+
+	t.Run("reports every finding without a previous version", func(t *testing.T) {
+		got := report(t, analyze("&baseline=previous", tfstate(1, "site")))
+
+		assert.Len(t, got.Findings, 1)
+		assert.Nil(t, got.Baseline)
+	})
+
+	t.Run("fails the JUnit report only on new findings", func(t *testing.T) {
+		w := analyze("&baseline=previous&format=junit", tfstate(4, "site", "logs"))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var junit models.JUnitTestSuites
+		require.NoError(t, xml.NewDecoder(w.Body).Decode(&junit))
+		assert.Zero(t, junit.Failures)
+	})
+
+	t.Run("rejects unknown versions", func(t *testing.T) {
+		w := analyze("&baseline=9", tfstate(4, "site"))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "State not found: web/9")
+	})
+
+	t.Run("rejects invalid baselines", func(t *testing.T) {
+		w := analyze("&baseline=last", tfstate(4, "site"))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid baseline: last")
+	})
+}
+
+func TestComplianceHandler(t *testing.T) {
+	tfstate := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_s3_bucket",
+				"name": "site",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "site", "acl": "public-read", "tags": {"team": "web"}}}]
+			}
+		]
+	}`
+
+	compliance := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		ComplianceHandler(w, httptest.NewRequest(http.MethodPost, "/analyze/compliance"+query, strings.NewReader(tfstate)))
+
+		return w
+	}
+
+	statuses := func(t *testing.T, w *httptest.ResponseRecorder) map[string]string {
+		t.Helper()
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var report models.ComplianceReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+		require.Len(t, report.Frameworks, 3)
+
+		statuses := make(map[string]string)
+		for _, framework := range report.Frameworks {
+			for _, control := range framework.Controls {
+				statuses[framework.Framework+" "+control.ID] = control.Status
+			}
+		}
+		return statuses
+	}
+
+	t.Run("maps the findings to controls", func(t *testing.T) {
+		got := statuses(t, compliance(""))
+		assert.Equal(t, models.ControlFail, got[analysis.FrameworkCIS+" 2.1.4"])
+		assert.Equal(t, models.ControlPass, got[analysis.FrameworkCIS+" 5.2"])
+		assert.Equal(t, models.ControlPass, got[analysis.FrameworkNIST+" CM-8"])
+	})
+
+	t.Run("does not assess the controls of the analyzers left out", func(t *testing.T) {
+		got := statuses(t, compliance("?analyzers=security"))
+		assert.Equal(t, models.ControlFail, got[analysis.FrameworkSOC2+" CC6.1"])
+		assert.Equal(t, models.ControlNotAssessed, got[analysis.FrameworkNIST+" CM-8"])
+	})
+
+	t.Run("rejects unknown analyzers", func(t *testing.T) {
+		w := compliance("?analyzers=lint")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("rejects other methods", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ComplianceHandler(w, httptest.NewRequest(http.MethodGet, "/analyze/compliance", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
+
+func TestLimitsHandler(t *testing.T) {
+	previous := storedStates
+	storedStates = store.NewStateStore(time.Hour)
+	t.Cleanup(func() { storedStates = previous })
+
+	tfstate := func(serial, vpcs int) string {
+		resources := make([]string, vpcs)
+		for i := range resources {
+			resources[i] = `{
+				"mode": "managed",
+				"type": "aws_vpc",
+				"name": "vpc` + strconv.Itoa(i) + `",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "vpc-` + strconv.Itoa(i) + `", "region": "eu-west-1"}}]
+			}`
+		}
+
+		return `{"version": 4, "terraform_version": "1.5.0", "lineage": "network", "serial": ` + strconv.Itoa(serial) +
+			`, "resources": [` + strings.Join(resources, ",") + `]}`
+	}
+
+	require.NoError(t, storedStates.Put(models.StateVersion{Lineage: "network", Serial: 1, StoredAt: time.Now().AddDate(0, 0, -20)}, []byte(tfstate(1, 1))))
+	require.NoError(t, storedStates.Put(models.StateVersion{Lineage: "network", Serial: 2, StoredAt: time.Now().AddDate(0, 0, -10)}, []byte(tfstate(2, 2))))
+	require.NoError(t, storedStates.Put(models.StateVersion{Lineage: "network", Serial: 5, StoredAt: time.Now()}, []byte(tfstate(5, 4))))
+
+	limits := func(query, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		LimitsHandler(w, httptest.NewRequest(http.MethodPost, "/analyze/limits"+query, strings.NewReader(body)))
+
+		return w
+	}
+
+	t.Run("projects the counts over the stored versions", func(t *testing.T) {
+		w := limits("", tfstate(3, 3))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var report models.LimitsReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+
+		assert.Equal(t, 3, report.Samples, "versions from the posted serial on are left out")
+		assert.Equal(t, 90, report.HorizonDays)
+		require.Len(t, report.Usage, 1)
+		assert.Equal(t, 3, report.Usage[0].Current)
+		assert.InDelta(t, 0.1, report.Usage[0].GrowthPerDay, 0.01)
+		require.Len(t, report.Warnings, 1)
+		assert.Equal(t, "limit_forecast", report.Warnings[0].Type)
+	})
+
+	t.Run("reads the horizon", func(t *testing.T) {
+		w := limits("?horizon_days=7", tfstate(3, 3))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var report models.LimitsReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+		assert.Equal(t, 7, report.HorizonDays)
+		assert.Empty(t, report.Warnings)
+	})
+
+	t.Run("works without history", func(t *testing.T) {
+		w := limits("", strings.Replace(tfstate(1, 1), `"network"`, `"other"`, 1))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var report models.LimitsReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+		assert.Equal(t, 1, report.Samples)
+	})
+
+	t.Run("rejects invalid horizons", func(t *testing.T) {
+		w := limits("?horizon_days=0", tfstate(3, 3))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid horizon_days: 0")
+	})
+}
+
+// stalledAnalyzer runs the analyzer it wraps once released, and gives up
+// when the analysis times out first.
+type stalledAnalyzer struct {
+	analysis.Analyzer
+	release chan struct{}
+}
+
+func (a stalledAnalyzer) Analyze(ctx context.Context, graph *models.Graph, state *models.TerraformState) []models.Finding {
+	select {
+	case <-a.release:
+		return a.Analyzer.Analyze(ctx, graph, state)
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+// stallAnalyzer replaces the named analyzer with one that does not complete
+// before the end of the test, and shortens the analysis timeout.
+func stallAnalyzer(t *testing.T, name string) {
+	selected, err := analysis.SelectAnalyzers(name)
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	analysis.Register(stalledAnalyzer{Analyzer: selected[0], release: release})
+
+	previous := analysisTimeout
+	analysisTimeout = 20 * time.Millisecond
+
+	t.Cleanup(func() {
+		close(release)
+		analysis.Register(selected[0])
+		analysisTimeout = previous
+	})
+}
+
+func TestAnalysisTimeout(t *testing.T) {
+	tfstate := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_s3_bucket",
+				"name": "site",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "site", "acl": "public-read", "tags": {}}}]
+			}
+		]
+	}`
+
+	stallAnalyzer(t, "tags")
+
+	t.Run("analyze returns partial findings", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		AnalyzeHandler(w, httptest.NewRequest(http.MethodPost, "/analyze?analyzers=security,tags", strings.NewReader(tfstate)))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "tags", w.Header().Get(PartialHeader))
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+		var report models.AnalysisReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+		assert.True(t, report.Partial)
+		assert.Equal(t, []string{"tags"}, report.Incomplete)
+		require.Len(t, report.Findings, 1)
+		assert.Equal(t, "security", report.Findings[0].Analyzer)
+	})
+
+	t.Run("SARIF is marked partial", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		AnalyzeHandler(w, httptest.NewRequest(http.MethodPost, "/analyze?analyzers=security,tags&format=sarif", strings.NewReader(tfstate)))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "tags", w.Header().Get(PartialHeader))
+	})
+
+	t.Run("compliance leaves the controls of incomplete analyzers unassessed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ComplianceHandler(w, httptest.NewRequest(http.MethodPost, "/analyze/compliance?analyzers=security,tags", strings.NewReader(tfstate)))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var report models.ComplianceReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+		assert.True(t, report.Partial)
+		assert.Equal(t, []string{"tags"}, report.Incomplete)
+
+		statuses := make(map[string]string)
+		for _, framework := range report.Frameworks {
+			for _, control := range framework.Controls {
+				statuses[framework.Framework+" "+control.ID] = control.Status
+			}
+		}
+		assert.Equal(t, models.ControlFail, statuses[analysis.FrameworkSOC2+" CC6.1"])
+		assert.Equal(t, models.ControlNotAssessed, statuses[analysis.FrameworkNIST+" CM-8"])
+	})
+}
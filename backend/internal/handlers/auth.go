@@ -0,0 +1,322 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/terrascope/core/internal/auth"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+	"github.com/terrascope/core/internal/web"
+)
+
+// SessionCookieName holds the token of a login session.
+const SessionCookieName = "terrascope_session"
+
+// sessionTTL is how long a login session lasts.
+const sessionTTL = 12 * time.Hour
+
+// accounts may sign in to the UI: the users of TERRASCOPE_USERS_FILE and
+// those invited through the admin API. Login is disabled, and the UI and
+// stored states open to everyone, as long as there are none. OpenStorage
+// moves them to the database of the storage backend.
+var accounts store.UserBackend = accountsFromEnv()
+
+func accountsFromEnv() store.UserBackend {
+	accounts := store.NewUserStore()
+	if err := seedAccounts(accounts); err != nil {
+		log.Fatalf("Reading TERRASCOPE_USERS_FILE: %v", err)
+	}
+
+	return accounts
+}
+
+// seedAccounts stores the users of TERRASCOPE_USERS_FILE with their password
+// hashes, except those removed through the admin API.
+func seedAccounts(accounts store.UserBackend) error {
+	path := os.Getenv("TERRASCOPE_USERS_FILE")
+	if path == "" {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	users, err := auth.ParseUsers(file)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for username, hash := range users {
+		if err := accounts.Seed(models.User{Username: username, Role: models.RoleMember, CreatedAt: now}, hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loginEnabled reports whether there are accounts to sign in with. On
+// failure it writes a 500 response.
+func loginEnabled(w http.ResponseWriter) (enabled, ok bool) {
+	n, err := accounts.Len()
+	if err != nil {
+		storageFailed(w, err)
+		return false, false
+	}
+
+	return n > 0, true
+}
+
+// sessions holds the login sessions of the UI.
+var sessions = sessionsFromEnv()
+
+func sessionsFromEnv() store.SessionBackend {
+	if shared != nil {
+		return store.NewSharedSessions(shared, sessionTTL)
+	}
+
+	return store.NewSessionStore(sessionTTL)
+}
+
+// LoginHandler checks a user's password and starts a session, whose token is
+// set as an HTTP-only cookie.
+func LoginHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	enabled, ok := loginEnabled(w)
+	if !ok {
+		return
+	}
+	if !enabled {
+		http.Error(w, "Login is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	var req models.LoginRequest
+	if err := json.Unmarshal(body.Bytes(), &req); err != nil {
+		http.Error(w, "Invalid login: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hash, err := accounts.Password(req.Username)
+	if err != nil {
+		storageFailed(w, err)
+		return
+	}
+
+	if !auth.Verify(hash, req.Password) {
+		http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+		return
+	}
+
+	token := randomToken()
+	session := sessions.Create(token, req.Username, time.Now().UTC())
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Secure:   secureCookies(r),
+	})
+
+	writeJSON(w, r, session)
+}
+
+// LogoutHandler ends the session of the request and clears its cookie.
+func LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if cookie, err := r.Cookie(SessionCookieName); err == nil {
+		sessions.Delete(cookie.Value)
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Secure:   secureCookies(r),
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SessionHandler returns the session of the request, so that the UI can show
+// who is signed in.
+func SessionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session, ok, err := currentSession(r)
+	if err != nil {
+		storageFailed(w, err)
+		return
+	}
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	writeJSON(w, r, session)
+}
+
+// LoginPageHandler serves the sign-in form of the UI.
+func LoginPageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(web.LoginPage())
+}
+
+// RequireSession lets only signed-in users and API clients through once login
+// is enabled. Browsers navigating to a page are sent to the sign-in form, and
+// other requests are unauthorized.
+func RequireSession(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enabled, ok := loginEnabled(w)
+		if !ok {
+			return
+		}
+		if !enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if _, ok := requestAPIKey(r); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		_, ok, err := currentUser(r)
+		if err != nil {
+			storageFailed(w, err)
+			return
+		}
+		if ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), "text/html") {
+			http.Redirect(w, r, "/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+			return
+		}
+
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	}
+}
+
+// currentSession returns the session of the request, unless its user has
+// been removed, or removed and invited again, since it started.
+func currentSession(r *http.Request) (models.Session, bool, error) {
+	cookie, err := r.Cookie(SessionCookieName)
+	if err != nil {
+		return models.Session{}, false, nil
+	}
+
+	session, ok := sessions.Get(cookie.Value, time.Now().UTC())
+	if !ok {
+		return models.Session{}, false, nil
+	}
+
+	user, ok, err := accounts.Get(session.Username)
+	if err != nil || !ok || session.CreatedAt.Before(user.CreatedAt) {
+		return models.Session{}, false, err
+	}
+
+	return session, true, nil
+}
+
+// currentUser returns the signed-in user of the request, with their current
+// role.
+func currentUser(r *http.Request) (models.User, bool, error) {
+	session, ok, err := currentSession(r)
+	if err != nil || !ok {
+		return models.User{}, false, err
+	}
+
+	return accounts.Get(session.Username)
+}
+
+// apiKeyPrefix starts the secrets of API keys, which tells them apart from
+// the admin token.
+const apiKeyPrefix = "tsk_"
+
+// apiKeys authenticate API clients. OpenStorage moves them to the database
+// of the storage backend, so that they work on every replica.
+var apiKeys store.KeyBackend = store.NewKeyStore()
+
+type apiKeyContextKey struct{}
+
+// TrackAPIKeys authenticates the requests that present an API key as their
+// bearer token and records them in the key's usage. Requests with a revoked
+// or unknown key are unauthorized; others pass through unchanged.
+func TrackAPIKeys(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || !strings.HasPrefix(secret, apiKeyPrefix) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key, ok, err := apiKeys.Use(secret, time.Now().UTC())
+		if err != nil {
+			storageFailed(w, err)
+			return
+		}
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Invalid API key", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKeyContextKey{}, key)))
+	})
+}
+
+// requestAPIKey returns the API key the request was authenticated with.
+func requestAPIKey(r *http.Request) (models.APIKey, bool) {
+	key, ok := r.Context().Value(apiKeyContextKey{}).(models.APIKey)
+	return key, ok
+}
+
+// secureCookies reports whether session cookies are only sent over HTTPS:
+// when the request came over TLS or through a proxy that terminated it.
+func secureCookies(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
@@ -0,0 +1,279 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+// testPasswordHash is the hash of "secret" with a single iteration.
+const testPasswordHash = "pbkdf2-sha256$1$c2FsdA$ON9CizCTCOSMNofn+QvaDpzyU1aMIex1Sg4HarSrZCM"
+
+// withUsers enables login for the test with a user alice whose password is
+// "secret", hashed with a single iteration to keep the tests fast.
+func withUsers(t *testing.T) {
+	t.Helper()
+
+	previousAccounts, previousSessions := accounts, sessions
+	accounts = store.NewUserStore()
+	accounts.Add(models.User{Username: "alice", Role: models.RoleMember}, testPasswordHash)
+	sessions = store.NewSessionStore(time.Hour)
+	t.Cleanup(func() { accounts, sessions = previousAccounts, previousSessions })
+}
+
+func login(t *testing.T, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	LoginHandler(w, httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(body)))
+
+	return w
+}
+
+func TestLoginHandler(t *testing.T) {
+	withUsers(t)
+
+	t.Run("starts a session", func(t *testing.T) {
+		w := login(t, `{"username": "alice", "password": "secret"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var session models.Session
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&session))
+		assert.Equal(t, "alice", session.Username)
+
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, SessionCookieName, cookies[0].Name)
+		assert.True(t, cookies[0].HttpOnly)
+		assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+		assert.False(t, cookies[0].Secure)
+
+		req := httptest.NewRequest(http.MethodGet, "/auth/session", nil)
+		req.AddCookie(cookies[0])
+		current := httptest.NewRecorder()
+		SessionHandler(current, req)
+
+		require.Equal(t, http.StatusOK, current.Code)
+		assert.Contains(t, current.Body.String(), `"username":"alice"`)
+	})
+
+	t.Run("cookies are secure behind a TLS proxy", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"username": "alice", "password": "secret"}`))
+		req.Header.Set("X-Forwarded-Proto", "https")
+		w := httptest.NewRecorder()
+
+		LoginHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, w.Result().Cookies()[0].Secure)
+	})
+
+	t.Run("rejects wrong passwords and unknown users", func(t *testing.T) {
+		for _, body := range []string{
+			`{"username": "alice", "password": "wrong"}`,
+			`{"username": "bob", "password": "secret"}`,
+			`{}`,
+		} {
+			w := login(t, body)
+			assert.Equal(t, http.StatusUnauthorized, w.Code, body)
+			assert.Empty(t, w.Result().Cookies(), body)
+		}
+	})
+
+	t.Run("returns 400 for invalid request body", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, login(t, `{invalid`).Code)
+	})
+
+	t.Run("returns 405 for GET request", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		LoginHandler(w, httptest.NewRequest(http.MethodGet, "/auth/login", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
+
+func TestLoginHandlerNotConfigured(t *testing.T) {
+	previous := accounts
+	accounts = store.NewUserStore()
+	t.Cleanup(func() { accounts = previous })
+
+	w := login(t, `{"username": "alice", "password": "secret"}`)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestLogoutHandler(t *testing.T) {
+	withUsers(t)
+
+	cookie := login(t, `{"username": "alice", "password": "secret"}`).Result().Cookies()[0]
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+	req.AddCookie(cookie)
+	w := httptest.NewRecorder()
+	LogoutHandler(w, req)
+
+	require.Equal(t, http.StatusNoContent, w.Code)
+	cleared := w.Result().Cookies()
+	require.Len(t, cleared, 1)
+	assert.Equal(t, -1, cleared[0].MaxAge)
+
+	req = httptest.NewRequest(http.MethodGet, "/auth/session", nil)
+	req.AddCookie(cookie)
+	w = httptest.NewRecorder()
+	SessionHandler(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRequireSession(t *testing.T) {
+	protected := RequireSession(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	t.Run("open when login is disabled", func(t *testing.T) {
+		previous := accounts
+		accounts = store.NewUserStore()
+		t.Cleanup(func() { accounts = previous })
+
+		w := httptest.NewRecorder()
+		protected(w, httptest.NewRequest(http.MethodGet, "/states", nil))
+
+		assert.Equal(t, http.StatusTeapot, w.Code)
+	})
+
+	withUsers(t)
+
+	t.Run("passes signed-in requests", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/states", nil)
+		req.AddCookie(login(t, `{"username": "alice", "password": "secret"}`).Result().Cookies()[0])
+		w := httptest.NewRecorder()
+
+		protected(w, req)
+
+		assert.Equal(t, http.StatusTeapot, w.Code)
+	})
+
+	t.Run("unauthorized without a session", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/states", nil)
+		req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: "forged"})
+		w := httptest.NewRecorder()
+
+		protected(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("browsers are sent to the sign-in form", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/graphs?view=orbit", nil)
+		req.Header.Set("Accept", "text/html,application/xhtml+xml")
+		w := httptest.NewRecorder()
+
+		protected(w, req)
+
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/login?next=%2Fgraphs%3Fview%3Dorbit", w.Header().Get("Location"))
+	})
+
+	t.Run("removed users are signed out", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/states", nil)
+		req.AddCookie(login(t, `{"username": "alice", "password": "secret"}`).Result().Cookies()[0])
+		accounts.Add(models.User{Username: "bob", Role: models.RoleMember}, testPasswordHash)
+		accounts.Delete("alice")
+		t.Cleanup(func() {
+			accounts.Delete("bob")
+			accounts.Add(models.User{Username: "alice", Role: models.RoleMember}, testPasswordHash)
+		})
+		w := httptest.NewRecorder()
+
+		protected(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("passes API clients", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/states", nil)
+		req = req.WithContext(context.WithValue(req.Context(), apiKeyContextKey{}, models.APIKey{ID: "k1"}))
+		w := httptest.NewRecorder()
+
+		protected(w, req)
+
+		assert.Equal(t, http.StatusTeapot, w.Code)
+	})
+
+	t.Run("stored states uploaded in parts", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		uploadTargets["states"](w, httptest.NewRequest(http.MethodPost, "/states", strings.NewReader(`{}`)))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestLoginPageHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	LoginPageHandler(w, httptest.NewRequest(http.MethodGet, "/login", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "/api/v1/auth/login")
+}
+
+func TestTrackAPIKeys(t *testing.T) {
+	previous := apiKeys
+	apiKeys = store.NewKeyStore()
+	apiKeys.Create(models.APIKey{ID: "k1", Name: "ci", Role: models.RoleMember}, "tsk_live")
+	apiKeys.Create(models.APIKey{ID: "k2", Name: "old", Role: models.RoleMember}, "tsk_revoked")
+	apiKeys.Revoke("k2", time.Now())
+	t.Cleanup(func() { apiKeys = previous })
+
+	var seen models.APIKey
+	tracked := TrackAPIKeys(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = requestAPIKey(r)
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	request := func(authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/states", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		tracked.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("records the usage of valid keys", func(t *testing.T) {
+		require.Equal(t, http.StatusTeapot, request("Bearer tsk_live").Code)
+		assert.Equal(t, "k1", seen.ID)
+
+		key, _, _ := apiKeys.Get("k1")
+		assert.EqualValues(t, 1, key.Requests)
+		assert.NotNil(t, key.LastUsedAt)
+	})
+
+	t.Run("rejects revoked and unknown keys", func(t *testing.T) {
+		for _, authorization := range []string{"Bearer tsk_revoked", "Bearer tsk_unknown"} {
+			w := request(authorization)
+			assert.Equal(t, http.StatusUnauthorized, w.Code, authorization)
+			assert.Contains(t, w.Body.String(), "Invalid API key")
+		}
+	})
+
+	t.Run("passes other requests through", func(t *testing.T) {
+		for _, authorization := range []string{"", "Bearer s3cret"} {
+			seen = models.APIKey{}
+			assert.Equal(t, http.StatusTeapot, request(authorization).Code, authorization)
+			assert.Empty(t, seen.ID)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/terrascope/core/internal/catalog"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+	"github.com/terrascope/core/internal/store"
+)
+
+// defaultBackstageOwner owns the Backstage entities of the resources without
+// an owner tag unless ?owner= says otherwise.
+const defaultBackstageOwner = "infrastructure"
+
+// backstageEntitiesPath is the path of BackstageEntitiesHandler relative to
+// the API prefix, which it recovers from the request path for its links.
+const backstageEntitiesPath = "/backstage/entities"
+
+// BackstageEntitiesHandler lists the Backstage catalog entities of the latest
+// stored version of the lineage in ?lineage=, or of every stored lineage, for
+// a Backstage entity provider to ingest: a System per lineage and a Resource
+// per managed resource instance, linked back to Terrascope. ?module= keeps
+// the resources of a module and its children, and ?owner= owns the resources
+// without an owner tag.
+func BackstageEntitiesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+
+	opts := catalog.BackstageOptions{
+		Owner:     query.Get("owner"),
+		BaseURL:   requestBaseURL(r),
+		APIPrefix: strings.TrimSuffix(r.URL.Path, backstageEntitiesPath),
+		Module:    query.Get("module"),
+	}
+	if opts.Owner == "" {
+		opts.Owner = defaultBackstageOwner
+	}
+
+	states, ok := selectedStates(w, query.Get("lineage"))
+	if !ok {
+		return
+	}
+
+	entities := []models.BackstageEntity{}
+	for _, state := range states {
+		entities = append(entities, catalog.Backstage(state, opts)...)
+	}
+
+	writeJSON(w, r, entities)
+}
+
+// selectedStates returns the latest stored version of lineage, or of every
+// stored lineage when lineage is "", ordered by lineage. It writes the error
+// response and reports false when the lineage is unknown or unreadable.
+func selectedStates(w http.ResponseWriter, lineage string) ([]*models.TerraformState, bool) {
+	if lineage == "" {
+		latest, err := latestStates()
+		if err != nil {
+			storageFailed(w, err)
+			return nil, false
+		}
+
+		states := make([]*models.TerraformState, 0, len(latest))
+		for _, state := range latest {
+			states = append(states, state)
+		}
+		sort.Slice(states, func(i, j int) bool { return states[i].Lineage < states[j].Lineage })
+
+		return states, true
+	}
+
+	data, err := latestVersion(lineage)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Lineage not found: "+lineage, http.StatusNotFound)
+		return nil, false
+	} else if err != nil {
+		storageFailed(w, err)
+		return nil, false
+	}
+
+	state, err := parser.ParseTfstate(data)
+	if err != nil {
+		http.Error(w, "Invalid tfstate of "+lineage+": "+err.Error(), http.StatusUnprocessableEntity)
+		return nil, false
+	}
+
+	return []*models.TerraformState{state}, true
+}
+
+// requestBaseURL returns the scheme and host the request reached the server
+// at, through the proxy that terminated TLS if any.
+func requestBaseURL(r *http.Request) string {
+	if secureCookies(r) {
+		return "https://" + r.Host
+	}
+
+	return "http://" + r.Host
+}
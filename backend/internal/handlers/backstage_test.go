@@ -0,0 +1,100 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+func TestBackstageEntitiesHandler(t *testing.T) {
+	previous := storedStates
+	storedStates = store.NewStateStore(time.Hour)
+	t.Cleanup(func() { storedStates = previous })
+
+	tfstate := func(lineage string) []byte {
+		return []byte(`{"version": 4, "terraform_version": "1.5.0", "serial": 3, "lineage": "` + lineage + `", "resources": [{
+			"mode": "managed",
+			"type": "aws_s3_bucket",
+			"name": "logs",
+			"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+			"instances": [{"attributes": {"id": "` + lineage + `-logs", "tags": {"owner": "data"}}}]
+		}]}`)
+	}
+
+	require.NoError(t, storedStates.Put(models.StateVersion{Lineage: "prod", Serial: 3}, tfstate("prod")))
+	require.NoError(t, storedStates.Put(models.StateVersion{Lineage: "staging", Serial: 3}, tfstate("staging")))
+	require.NoError(t, storedStates.Put(models.StateVersion{Lineage: "broken", Serial: 1}, []byte(`{}`)))
+
+	entities := func(target string) ([]models.BackstageEntity, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		r.Header.Set("X-Forwarded-Proto", "https")
+		BackstageEntitiesHandler(w, r)
+
+		var result []models.BackstageEntity
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+		}
+
+		return result, w
+	}
+
+	t.Run("lists the entities of a lineage", func(t *testing.T) {
+		result, w := entities("/api/v1/backstage/entities?lineage=prod&owner=platform")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		require.Len(t, result, 2)
+		assert.Equal(t, models.BackstageKindSystem, result[0].Kind)
+		assert.Equal(t, "group:default/platform", result[0].Spec.Owner)
+
+		bucket := result[1]
+		assert.Equal(t, "aws-s3-bucket-logs", bucket.Metadata.Name)
+		assert.Equal(t, "group:default/data", bucket.Spec.Owner)
+		assert.Contains(t, bucket.Metadata.Links, models.BackstageLink{
+			URL:   "https://example.com/api/v1/states/prod/3",
+			Title: "Terraform state",
+		})
+	})
+
+	t.Run("lists every lineage", func(t *testing.T) {
+		result, w := entities("/backstage/entities")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		require.Len(t, result, 4, "unparsable states are skipped")
+		assert.Equal(t, "prod", result[0].Metadata.Namespace)
+		assert.Equal(t, "staging", result[2].Metadata.Namespace)
+		assert.Equal(t, "group:default/"+defaultBackstageOwner, result[0].Spec.Owner)
+		assert.Contains(t, result[1].Metadata.Links, models.BackstageLink{
+			URL:   "https://example.com/states/prod/3",
+			Title: "Terraform state",
+		}, "links follow the path the API was reached at")
+	})
+
+	t.Run("unknown lineage", func(t *testing.T) {
+		_, w := entities("/backstage/entities?lineage=unknown")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid stored state", func(t *testing.T) {
+		_, w := entities("/backstage/entities?lineage=broken")
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		BackstageEntitiesHandler(w, httptest.NewRequest(http.MethodPost, "/backstage/entities", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
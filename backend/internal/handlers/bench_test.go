@@ -0,0 +1,60 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// benchmarkTfstate generates a state without a lineage, so the delta cache
+// never kicks in and every iteration measures a full parse.
+func benchmarkTfstate(resources int) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString(`{"version":4,"terraform_version":"1.5.0","serial":1,"lineage":"","resources":[`)
+	for i := 0; i < resources; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, `{"mode":"managed","type":"aws_instance","name":"r%d",`+
+			`"provider":"provider[\"registry.terraform.io/hashicorp/aws\"]",`+
+			`"instances":[{"schema_version":1,"attributes":{"id":"i-%08x","tags":{"Index":"%d"}},"dependencies":["aws_instance.r0"]}]}`, i, i, i)
+	}
+	buf.WriteString(`]}`)
+
+	return buf.Bytes()
+}
+
+func BenchmarkParseHandler(b *testing.B) {
+	for _, size := range []int{100, 1000, 10000} {
+		data := benchmarkTfstate(size)
+
+		b.Run(fmt.Sprintf("resources=%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+
+			for b.Loop() {
+				req := httptest.NewRequest(http.MethodPost, "/parse", bytes.NewReader(data))
+				w := httptest.NewRecorder()
+				w.Body = nil
+
+				ParseHandler(discardRecorder{w}, req)
+			}
+		})
+	}
+}
+
+// discardRecorder drops the response body so the benchmark measures request
+// handling rather than response buffering.
+type discardRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (d discardRecorder) Write(p []byte) (int, error) {
+	return io.Discard.Write(p)
+}
@@ -0,0 +1,117 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+
+	"github.com/terrascope/core/internal/cache"
+)
+
+// CacheHeader tells whether a response was served from the results cache, or
+// for ParseHandler from the graph cache: hit or miss. Responses are not marked
+// when caching is disabled.
+const CacheHeader = "X-Cache"
+
+// results keeps parsed graphs, diffs and analysis results, keyed by the hash
+// of their request, when TERRASCOPE_CACHE enables it.
+var results = cache.FromEnv()
+
+// shared is the results cache when the replicas of a deployment share it, with
+// TERRASCOPE_CACHE=redis. Login sessions, state locks, the graphs and schemas
+// stored by digest and the usage of quotas are then kept there rather than in
+// each server, so that requests may reach any replica. Chunked uploads, and
+// the users, API keys and alert rules of the admin API, stay with the server
+// that received them.
+var shared, _ = results.(cache.Shared)
+
+// cachedResult returns the result stored under the key, if any.
+func cachedResult(r *http.Request, key string) ([]byte, bool) {
+	if results == nil {
+		return nil, false
+	}
+
+	value, ok, err := results.Get(r.Context(), key)
+	if err != nil {
+		log.Printf("Reading cached result: %v", err)
+		return nil, false
+	}
+
+	return value, ok
+}
+
+// cacheResult stores the result under the key.
+func cacheResult(r *http.Request, key string, value []byte) {
+	if results == nil {
+		return
+	}
+
+	if err := results.Set(r.Context(), key, value); err != nil {
+		log.Printf("Caching result: %v", err)
+	}
+}
+
+// CacheResults serves the JSON responses of next, whose result must only
+// depend on the path, query and body of the request, from the results cache.
+// Successful responses are cached unless next marks them Cache-Control:
+// no-store because they also depend on stored data; other responses pass
+// through unchanged.
+func CacheResults(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if results == nil {
+			next(w, r)
+			return
+		}
+
+		body, ok := readRequestBody(w, r)
+		if !ok {
+			return
+		}
+		data := bytes.Clone(body.Bytes())
+		releaseBody(body)
+
+		key := cache.Key("result", []byte(r.URL.Path), []byte(r.URL.RawQuery), data)
+
+		if value, ok := cachedResult(r, key); ok {
+			w.Header().Set(CacheHeader, "hit")
+			w.Header().Set("Content-Type", "application/json")
+			if _, err := w.Write(value); err != nil {
+				log.Printf("Error writing response: %v", err)
+			}
+			return
+		}
+
+		w.Header().Set(CacheHeader, "miss")
+
+		recorder := &recordedResponse{ResponseWriter: w, status: http.StatusOK}
+		r.Body = io.NopCloser(bytes.NewReader(data))
+		r.ContentLength = int64(len(data))
+		next(recorder, r)
+
+		mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+		if recorder.status == http.StatusOK && mediaType == "application/json" && w.Header().Get("Cache-Control") != "no-store" {
+			cacheResult(r, key, recorder.body.Bytes())
+		}
+	}
+}
+
+// recordedResponse writes a response through while keeping a copy of its body.
+type recordedResponse struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rr *recordedResponse) WriteHeader(status int) {
+	rr.status = status
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *recordedResponse) Write(data []byte) (int, error) {
+	rr.body.Write(data)
+	return rr.ResponseWriter.Write(data)
+}
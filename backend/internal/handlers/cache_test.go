@@ -0,0 +1,168 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/cache"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+// useResultsCache enables the results cache for the test, in front of an
+// empty graph cache.
+func useResultsCache(t *testing.T) {
+	useGraphCache(t, defaultGraphCacheBytes)
+
+	original := results
+	results = cache.NewMemory(16, time.Minute)
+	t.Cleanup(func() { results = original })
+}
+
+func TestCacheResults(t *testing.T) {
+	useResultsCache(t)
+
+	calls := 0
+	handler := CacheResults(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, ok := readRequestBody(w, r)
+		if !ok {
+			return
+		}
+		defer releaseBody(body)
+
+		if body.String() == "bad" {
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+		if body.String() == "stored" {
+			w.Header().Set("Cache-Control", "no-store")
+		}
+		writeJSON(w, r, map[string]string{"echo": body.String()})
+	})
+
+	serve := func(target, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler(rr, httptest.NewRequest(http.MethodPost, target, strings.NewReader(body)))
+		return rr
+	}
+
+	first := serve("/analyze/endpoints", "a")
+	assert.Equal(t, "miss", first.Header().Get(CacheHeader))
+
+	second := serve("/analyze/endpoints", "a")
+	assert.Equal(t, "hit", second.Header().Get(CacheHeader))
+	assert.Equal(t, "application/json", second.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"echo": "a"}`, second.Body.String())
+	assert.Equal(t, 1, calls)
+
+	t.Run("keys results by path, query and body", func(t *testing.T) {
+		serve("/analyze/endpoints", "b")
+		serve("/analyze/endpoints?min_size=3", "a")
+		serve("/analyze/clusters", "a")
+		assert.Equal(t, 4, calls)
+	})
+
+	t.Run("does not cache failures", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve("/analyze/endpoints", "bad").Code)
+		assert.Equal(t, http.StatusBadRequest, serve("/analyze/endpoints", "bad").Code)
+		assert.Equal(t, 6, calls)
+	})
+
+	t.Run("does not cache results marked no-store", func(t *testing.T) {
+		serve("/analyze/endpoints", "stored")
+		assert.Equal(t, "miss", serve("/analyze/endpoints", "stored").Header().Get(CacheHeader))
+		assert.Equal(t, 8, calls)
+	})
+
+	t.Run("passes through when caching is disabled", func(t *testing.T) {
+		results = nil
+
+		rr := serve("/analyze/endpoints", "a")
+		assert.Empty(t, rr.Header().Get(CacheHeader))
+		assert.Equal(t, 9, calls)
+	})
+}
+
+func TestParseHandlerCachesGraphs(t *testing.T) {
+	useResultsCache(t)
+
+	state := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"serial": 1,
+		"lineage": "cache-handler-test",
+		"resources": [{
+			"mode": "managed",
+			"type": "aws_vpc",
+			"name": "main",
+			"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+			"instances": [{"attributes": {"id": "vpc-1"}}]
+		}]
+	}`
+
+	parse := func(target string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		ParseHandler(rr, httptest.NewRequest(http.MethodPost, target, strings.NewReader(state)))
+		require.Equal(t, http.StatusOK, rr.Code)
+		return rr
+	}
+
+	first := parse("/parse")
+	assert.Equal(t, "miss", first.Header().Get(CacheHeader))
+
+	second := parse("/parse?pretty=true")
+	assert.Equal(t, "hit", second.Header().Get(CacheHeader))
+	assert.Empty(t, second.Header().Get("X-Delta-Reused"))
+	assert.Equal(t, first.Header().Get("X-Graph-Digest"), second.Header().Get("X-Graph-Digest"))
+
+	var graph models.Graph
+	require.NoError(t, json.NewDecoder(second.Body).Decode(&graph))
+	require.Len(t, graph.Nodes, 1)
+	assert.Equal(t, "vpc-1", graph.Nodes[0].Metadata["id"])
+
+	// Options that change the graph are part of its key.
+	assert.Equal(t, "miss", parse("/parse?view=network").Header().Get(CacheHeader))
+	assert.Equal(t, "miss", parse("/parse?id_format=terraform").Header().Get(CacheHeader))
+
+	t.Run("adds the current lock", func(t *testing.T) {
+		stateLocks.Put("cache-handler-test", models.LockInfo{ID: "lock-1", Who: "ci"})
+		t.Cleanup(func() { stateLocks.Delete("cache-handler-test") })
+
+		rr := parse("/parse")
+		assert.Equal(t, "hit", rr.Header().Get(CacheHeader))
+
+		var graph models.Graph
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&graph))
+		require.NotNil(t, graph.Lock)
+		assert.Equal(t, "lock-1", graph.Lock.ID)
+	})
+}
+
+func TestSharedState(t *testing.T) {
+	original := shared
+	t.Cleanup(func() { shared = original })
+
+	shared = nil
+	assert.IsType(t, &store.SessionStore{}, sessionsFromEnv())
+	assert.IsType(t, &store.LockStore{}, stateLocksFromEnv())
+	assert.IsType(t, &store.GraphStore{}, storedGraphsFromEnv())
+	assert.IsType(t, &store.SchemaStore{}, storedSchemasFromEnv())
+
+	redis, err := cache.NewRedis("redis://localhost:6379", time.Minute)
+	require.NoError(t, err)
+
+	shared = redis
+	assert.IsType(t, &store.SharedSessions{}, sessionsFromEnv())
+	assert.IsType(t, &store.SharedLocks{}, stateLocksFromEnv())
+	assert.IsType(t, &store.SharedGraphs{}, storedGraphsFromEnv())
+	assert.IsType(t, &store.SharedSchemas{}, storedSchemasFromEnv())
+}
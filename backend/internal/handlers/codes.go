@@ -0,0 +1,49 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/terrascope/core/internal/codes"
+	"github.com/terrascope/core/internal/models"
+)
+
+// CodesHandler lists the stable codes of the warnings, findings and
+// validation issues, with their description and remediation, for automation
+// to match and clients to translate. ?kind= limits the list to warning,
+// finding or validation codes, and ?code= describes a single code.
+func CodesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if code := r.URL.Query().Get("code"); code != "" {
+		info, ok := codes.Lookup(code)
+		if !ok {
+			http.Error(w, "Unknown code: "+code, http.StatusNotFound)
+			return
+		}
+
+		writeJSON(w, r, info)
+		return
+	}
+
+	kind := r.URL.Query().Get("kind")
+	switch kind {
+	case "", models.CodeKindWarning, models.CodeKindFinding, models.CodeKindValidation:
+	default:
+		http.Error(w, "Unknown kind: "+kind, http.StatusBadRequest)
+		return
+	}
+
+	catalog := []models.CodeInfo{}
+	for _, info := range codes.Catalog() {
+		if kind == "" || info.Kind == kind {
+			catalog = append(catalog, info)
+		}
+	}
+
+	writeJSON(w, r, catalog)
+}
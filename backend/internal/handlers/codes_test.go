@@ -0,0 +1,93 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestCodesHandler(t *testing.T) {
+	request := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		CodesHandler(w, httptest.NewRequest(http.MethodGet, url, nil))
+
+		return w
+	}
+
+	t.Run("lists every code", func(t *testing.T) {
+		w := request("/codes")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var catalog []models.CodeInfo
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&catalog))
+
+		kinds := make(map[string]bool)
+		for _, info := range catalog {
+			kinds[info.Kind] = true
+		}
+		assert.Equal(t, map[string]bool{models.CodeKindWarning: true, models.CodeKindFinding: true, models.CodeKindValidation: true}, kinds)
+	})
+
+	t.Run("filters by kind", func(t *testing.T) {
+		w := request("/codes?kind=finding")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var catalog []models.CodeInfo
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&catalog))
+		require.NotEmpty(t, catalog)
+		for _, info := range catalog {
+			assert.Equal(t, models.CodeKindFinding, info.Kind)
+		}
+
+		assert.Equal(t, http.StatusBadRequest, request("/codes?kind=error").Code)
+	})
+
+	t.Run("describes a code", func(t *testing.T) {
+		w := request("/codes?code=TS502")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var info models.CodeInfo
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&info))
+		assert.Equal(t, "security/public_bucket", info.Source)
+		assert.NotEmpty(t, info.Remediation)
+
+		assert.Equal(t, http.StatusNotFound, request("/codes?code=TS999").Code)
+	})
+
+	t.Run("findings carry their code", func(t *testing.T) {
+		tfstate := `{
+			"version": 4,
+			"terraform_version": "1.5.0",
+			"resources": [{
+				"mode": "managed",
+				"type": "aws_s3_bucket",
+				"name": "site",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "site", "acl": "public-read", "tags": {"team": "web"}}}]
+			}]
+		}`
+
+		w := httptest.NewRecorder()
+		AnalyzeHandler(w, httptest.NewRequest(http.MethodPost, "/analyze?analyzers=security", strings.NewReader(tfstate)))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var report models.AnalysisReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+		require.Len(t, report.Findings, 1)
+		assert.Equal(t, "TS502", report.Findings[0].Code)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		CodesHandler(w, httptest.NewRequest(http.MethodPost, "/codes", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
@@ -0,0 +1,164 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/terrascope/core/internal/export"
+	"github.com/terrascope/core/internal/store"
+)
+
+// Defaults of TERRASCOPE_COLD_AFTER_DAYS and TERRASCOPE_COLD_RESTORE_DAYS.
+const (
+	defaultColdAfterDays   = 90
+	defaultColdRestoreDays = 7
+)
+
+// defaultColdStorageClass is the storage class of archived documents when
+// TERRASCOPE_COLD_STORAGE_CLASS is not set.
+const defaultColdStorageClass = "GLACIER"
+
+// archivalInterval is how often ArchiveStates looks for versions due for cold
+// storage.
+var archivalInterval = time.Hour
+
+// coldStore archives the documents of old state versions to the S3 bucket of
+// the TERRASCOPE_COLD_BUCKET, _REGION, _ENDPOINT, _PREFIX, _ACCESS_KEY_ID,
+// _SECRET_ACCESS_KEY, _SESSION_TOKEN and _STORAGE_CLASS variables. It is nil,
+// and versions stay in the storage backend, when no bucket is set.
+var coldStore = coldStoreFromEnv()
+
+// coldAfterDays is the age of the versions archived, and coldRestoreDays how
+// long rehydrated copies stay readable.
+var (
+	coldAfterDays   = daysFromEnv("TERRASCOPE_COLD_AFTER_DAYS", defaultColdAfterDays)
+	coldRestoreDays = daysFromEnv("TERRASCOPE_COLD_RESTORE_DAYS", defaultColdRestoreDays)
+)
+
+func coldStoreFromEnv() store.ColdStore {
+	bucket := export.S3StoreFromEnv("TERRASCOPE_COLD_")
+	if bucket == nil {
+		return nil
+	}
+
+	if bucket.StorageClass == "" {
+		bucket.StorageClass = defaultColdStorageClass
+	}
+
+	return bucket
+}
+
+func daysFromEnv(name string, fallback int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil || days <= 0 {
+		log.Printf("Ignoring %s: invalid value %q", name, value)
+		return fallback
+	}
+
+	return days
+}
+
+// coldArchiver archives the stored states to coldStore, or is nil when cold
+// storage is not configured.
+func coldArchiver() *store.Archiver {
+	if coldStore == nil {
+		return nil
+	}
+
+	return &store.Archiver{
+		States:      storedStates,
+		Cold:        coldStore,
+		After:       time.Duration(coldAfterDays) * 24 * time.Hour,
+		RestoreDays: coldRestoreDays,
+	}
+}
+
+// ArchiveStates moves the state versions older than TERRASCOPE_COLD_AFTER_DAYS
+// to cold storage now and every archivalInterval until ctx is done. It
+// returns at once when cold storage is not configured. Every replica runs it,
+// so when they share a cache only the one that takes the archive lock
+// archives each time.
+func ArchiveStates(ctx context.Context) {
+	archiver := coldArchiver()
+	if archiver == nil {
+		return
+	}
+
+	ticker := time.NewTicker(archivalInterval)
+	defer ticker.Stop()
+
+	for {
+		archiveOnce(ctx, archiver)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// archiveOnce archives the versions due for cold storage, unless another
+// replica is archiving them. The lock expires by the next run should the
+// replica holding it stop.
+func archiveOnce(ctx context.Context, archiver *store.Archiver) {
+	if shared != nil {
+		unlock, ok, err := shared.Lock(ctx, "archive", archivalInterval)
+		if err != nil {
+			log.Printf("Archival to cold storage failed: %v", err)
+			return
+		}
+		if !ok {
+			return
+		}
+		defer unlock()
+	}
+
+	archived, err := archiver.Archive(ctx, time.Now().UTC())
+	if len(archived) > 0 {
+		log.Printf("Archived %d state versions to cold storage", len(archived))
+	}
+	if err != nil {
+		log.Printf("Archival to cold storage failed: %v", err)
+	}
+}
+
+// rehydrateRetryAfter is how long clients are asked to wait for a document to
+// be restored from cold storage, which takes hours.
+const rehydrateRetryAfter = time.Hour
+
+// rehydrate reads the document of an archived version from cold storage. On
+// failure, or while the document is being restored, it writes the response
+// and returns false.
+func rehydrate(w http.ResponseWriter, r *http.Request, lineage string, serial int) ([]byte, bool) {
+	archiver := coldArchiver()
+	if archiver == nil {
+		http.Error(w, "Cold storage is not configured", http.StatusServiceUnavailable)
+		return nil, false
+	}
+
+	data, err := archiver.Rehydrate(r.Context(), lineage, serial)
+	if errors.Is(err, store.ErrRehydrating) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(rehydrateRetryAfter.Seconds())))
+		http.Error(w, "State version is being rehydrated from cold storage: retry later", http.StatusAccepted)
+		return nil, false
+	} else if err != nil {
+		log.Printf("Rehydration of %s/%d failed: %v", lineage, serial, err)
+		http.Error(w, "Rehydration failed", http.StatusBadGateway)
+		return nil, false
+	}
+
+	return data, true
+}
@@ -0,0 +1,187 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/cache"
+	"github.com/terrascope/core/internal/export"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+// fakeCold is a cold store whose objects are readable once restored is set.
+type fakeCold struct {
+	objects  map[string][]byte
+	restored bool
+	err      error
+}
+
+func (c *fakeCold) Put(_ context.Context, key string, body []byte, _ string) (string, error) {
+	c.objects[key] = body
+	return key, nil
+}
+
+func (c *fakeCold) Get(_ context.Context, key string) ([]byte, error) {
+	return c.objects[key], nil
+}
+
+func (c *fakeCold) Restore(context.Context, string, int) (bool, error) {
+	return c.restored, c.err
+}
+
+// lockedShared is a shared cache whose locks are held by another replica
+// unless free is set.
+type lockedShared struct {
+	cache.Shared
+	free   bool
+	locked []string
+}
+
+func (s *lockedShared) Lock(_ context.Context, name string, _ time.Duration) (func(), bool, error) {
+	if !s.free {
+		return nil, false, nil
+	}
+
+	s.locked = append(s.locked, name)
+	return func() { s.locked = s.locked[:len(s.locked)-1] }, true, nil
+}
+
+func TestArchiveStatesLock(t *testing.T) {
+	previousStates, previousCold, previousShared := storedStates, coldStore, shared
+	t.Cleanup(func() { storedStates, coldStore, shared = previousStates, previousCold, previousShared })
+
+	storedStates = store.NewStateStore(time.Hour)
+	storedStates.Put(models.StateVersion{Lineage: "prod", Serial: 1, StoredAt: time.Now().AddDate(-1, 0, 0)}, []byte(`{"serial": 1}`))
+	storedStates.Put(models.StateVersion{Lineage: "prod", Serial: 2, StoredAt: time.Now()}, []byte(`{"serial": 2}`))
+
+	cold := &fakeCold{objects: make(map[string][]byte)}
+	coldStore = cold
+
+	locks := &lockedShared{}
+	shared = locks
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ArchiveStates(ctx)
+	assert.Empty(t, cold.objects, "another replica is archiving")
+
+	locks.free = true
+	ArchiveStates(ctx)
+	assert.Len(t, cold.objects, 1)
+	assert.Empty(t, locks.locked, "the lock is released after the run")
+}
+
+func TestColdStorage(t *testing.T) {
+	previousStates, previousCold := storedStates, coldStore
+	t.Cleanup(func() { storedStates, coldStore = previousStates, previousCold })
+
+	now := time.Now().UTC()
+	storedStates = store.NewStateStore(time.Hour)
+	storedStates.Put(models.StateVersion{Lineage: "prod", Serial: 1, StoredAt: now.AddDate(-1, 0, 0)}, []byte(`{"serial": 1}`))
+	storedStates.Put(models.StateVersion{Lineage: "prod", Serial: 2, StoredAt: now}, []byte(`{"serial": 2}`))
+
+	cold := &fakeCold{objects: make(map[string][]byte)}
+	coldStore = cold
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ArchiveStates(ctx)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/states/{lineage}/{serial}", StateVersionHandler)
+
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/states/prod/1", nil))
+
+		return w
+	}
+
+	t.Run("archives old versions", func(t *testing.T) {
+		versions, err := storedStates.Versions("prod")
+		require.NoError(t, err)
+		assert.True(t, versions[0].Archived)
+		assert.False(t, versions[1].Archived)
+		assert.Len(t, cold.objects, 1)
+	})
+
+	t.Run("asks to retry while rehydrating", func(t *testing.T) {
+		w := get()
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Equal(t, "3600", w.Header().Get("Retry-After"))
+	})
+
+	t.Run("serves rehydrated versions", func(t *testing.T) {
+		cold.restored = true
+		t.Cleanup(func() { cold.restored = false })
+
+		w := get()
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"serial": 1}`, w.Body.String())
+	})
+
+	t.Run("reports failed restores", func(t *testing.T) {
+		cold.err = errors.New("access denied")
+		t.Cleanup(func() { cold.err = nil })
+
+		assert.Equal(t, http.StatusBadGateway, get().Code)
+	})
+
+	t.Run("requires cold storage", func(t *testing.T) {
+		coldStore = nil
+		t.Cleanup(func() { coldStore = cold })
+
+		w := get()
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "Cold storage is not configured\n", w.Body.String())
+	})
+}
+
+func TestColdStoreFromEnv(t *testing.T) {
+	t.Run("is not configured without a bucket", func(t *testing.T) {
+		t.Setenv("TERRASCOPE_COLD_BUCKET", "")
+
+		assert.Nil(t, coldStoreFromEnv())
+	})
+
+	t.Run("defaults to Glacier", func(t *testing.T) {
+		t.Setenv("TERRASCOPE_COLD_BUCKET", "archive")
+		t.Setenv("TERRASCOPE_COLD_STORAGE_CLASS", "")
+
+		bucket, ok := coldStoreFromEnv().(*export.S3Store)
+		require.True(t, ok)
+		assert.Equal(t, "archive", bucket.Bucket)
+		assert.Equal(t, "GLACIER", bucket.StorageClass)
+	})
+
+	t.Run("reads the storage class", func(t *testing.T) {
+		t.Setenv("TERRASCOPE_COLD_BUCKET", "archive")
+		t.Setenv("TERRASCOPE_COLD_STORAGE_CLASS", "DEEP_ARCHIVE")
+
+		assert.Equal(t, "DEEP_ARCHIVE", coldStoreFromEnv().(*export.S3Store).StorageClass)
+	})
+}
+
+func TestDaysFromEnv(t *testing.T) {
+	t.Setenv("TERRASCOPE_COLD_AFTER_DAYS", "30")
+	assert.Equal(t, 30, daysFromEnv("TERRASCOPE_COLD_AFTER_DAYS", 90))
+
+	t.Setenv("TERRASCOPE_COLD_AFTER_DAYS", "-1")
+	assert.Equal(t, 90, daysFromEnv("TERRASCOPE_COLD_AFTER_DAYS", 90))
+
+	t.Setenv("TERRASCOPE_COLD_AFTER_DAYS", "")
+	assert.Equal(t, 90, daysFromEnv("TERRASCOPE_COLD_AFTER_DAYS", 90))
+}
@@ -0,0 +1,196 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/terrascope/core/internal/decorators"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+// defaultDecoratorTimeout bounds how long the decorators of a graph request
+// can run unless TERRASCOPE_DECORATOR_TIMEOUT says otherwise.
+const defaultDecoratorTimeout = 5 * time.Second
+
+// maxDecoratorScript bounds the size of an uploaded decorator script.
+const maxDecoratorScript = 64 << 10
+
+// decoratorStore keeps the uploaded decorator scripts, which graph requests
+// name in ?decorators=.
+var (
+	decoratorStore   = store.NewDecoratorStore()
+	decoratorTimeout = decoratorTimeoutFromEnv()
+)
+
+func decoratorTimeoutFromEnv() time.Duration {
+	value := os.Getenv("TERRASCOPE_DECORATOR_TIMEOUT")
+	if value == "" {
+		return defaultDecoratorTimeout
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		log.Printf("Ignoring TERRASCOPE_DECORATOR_TIMEOUT: invalid value %q", value)
+		return defaultDecoratorTimeout
+	}
+
+	return timeout
+}
+
+// decoratorName keeps decorator names usable in a comma-separated list.
+var decoratorName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// DecoratorsHandler uploads a decorator script (POST), replacing the script
+// of the same name, or lists the scripts (GET).
+func DecoratorsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		createDecorator(w, r)
+	case http.MethodGet:
+		writeJSON(w, r, decoratorStore.List())
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func createDecorator(w http.ResponseWriter, r *http.Request) {
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	var decorator models.Decorator
+	if err := json.Unmarshal(body.Bytes(), &decorator); err != nil {
+		http.Error(w, "Invalid decorator: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !decoratorName.MatchString(decorator.Name) {
+		http.Error(w, "Invalid decorator: name must be letters, digits, dashes and underscores", http.StatusBadRequest)
+		return
+	}
+
+	if len(decorator.Script) > maxDecoratorScript {
+		http.Error(w, "Invalid decorator: script too large", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), decoratorTimeout)
+	defer cancel()
+
+	if _, err := decorators.Compile(ctx, decorator.Name, decorator.Script); err != nil {
+		http.Error(w, "Invalid decorator: "+decoratorError(err), http.StatusBadRequest)
+		return
+	}
+
+	decorator.CreatedAt = time.Now().UTC()
+	decoratorStore.Put(decorator)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, r, decorator)
+}
+
+// DecoratorHandler returns (GET) or deletes (DELETE) a decorator script.
+func DecoratorHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	switch r.Method {
+	case http.MethodGet:
+		decorator, ok := decoratorStore.Get(name)
+		if !ok {
+			http.Error(w, "Decorator not found: "+name, http.StatusNotFound)
+			return
+		}
+
+		writeJSON(w, r, decorator)
+	case http.MethodDelete:
+		if !decoratorStore.Delete(name) {
+			http.Error(w, "Decorator not found: "+name, http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// graphDecorators compiles the decorators named by ?decorators=, in order, all
+// within decoratorTimeout. On failure it writes a 404 response for unknown
+// decorators and a 422 response for scripts that fail, and returns false.
+func graphDecorators(w http.ResponseWriter, r *http.Request) ([]*decorators.Decorator, bool) {
+	value := r.URL.Query().Get("decorators")
+	if value == "" {
+		return nil, true
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), decoratorTimeout)
+	defer cancel()
+
+	var compiled []*decorators.Decorator
+
+	for name := range strings.SplitSeq(value, ",") {
+		name = strings.TrimSpace(name)
+
+		decorator, ok := decoratorStore.Get(name)
+		if !ok {
+			http.Error(w, "Decorator not found: "+name, http.StatusNotFound)
+			return nil, false
+		}
+
+		d, err := decorators.Compile(ctx, decorator.Name, decorator.Script)
+		if err != nil {
+			http.Error(w, "Invalid decorator "+name+": "+decoratorError(err), http.StatusUnprocessableEntity)
+			return nil, false
+		}
+
+		compiled = append(compiled, d)
+	}
+
+	return compiled, true
+}
+
+// applyDecorators runs the decorators over the graph in turn, all within
+// decoratorTimeout. On failure it writes a 422 response and returns false.
+func applyDecorators(w http.ResponseWriter, r *http.Request, graph *models.Graph, compiled []*decorators.Decorator) (*models.Graph, bool) {
+	if len(compiled) == 0 {
+		return graph, true
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), decoratorTimeout)
+	defer cancel()
+
+	for _, d := range compiled {
+		decorated, err := d.Apply(ctx, graph)
+		if err != nil {
+			http.Error(w, "Decorator "+d.Name+" failed: "+decoratorError(err), http.StatusUnprocessableEntity)
+			return nil, false
+		}
+
+		graph = decorated
+	}
+
+	return graph, true
+}
+
+// decoratorError describes why a decorator failed, naming the timeout of
+// those that ran past it.
+func decoratorError(err error) string {
+	if errors.Is(err, decorators.ErrTimeout) {
+		return "decorators ran longer than " + decoratorTimeout.String()
+	}
+
+	return err.Error()
+}
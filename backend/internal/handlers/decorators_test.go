@@ -0,0 +1,160 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+func TestDecoratorHandlers(t *testing.T) {
+	previous := decoratorStore
+	decoratorStore = store.NewDecoratorStore()
+	t.Cleanup(func() { decoratorStore = previous })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/decorators", DecoratorsHandler)
+	mux.HandleFunc("/decorators/{name}", DecoratorHandler)
+	mux.HandleFunc("/parse", ParseHandler)
+
+	request := func(method, url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, url, strings.NewReader(body)))
+
+		return w
+	}
+
+	upload := func(name, script string) *httptest.ResponseRecorder {
+		body, err := json.Marshal(models.Decorator{Name: name, Script: script})
+		require.NoError(t, err)
+
+		return request(http.MethodPost, "/decorators", string(body))
+	}
+
+	tfstate := `{"version": 4, "terraform_version": "1.5.0", "serial": 1, "lineage": "decorated",
+		"resources": [
+			{"mode": "managed", "type": "aws_instance", "name": "web",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "i-1", "tags": {"Team": "payments"}}}]},
+			{"mode": "managed", "type": "null_resource", "name": "hook",
+				"provider": "provider[\"registry.terraform.io/hashicorp/null\"]",
+				"instances": [{"attributes": {"id": "1"}, "dependencies": ["aws_instance.web"]}]}
+		]}`
+
+	t.Run("uploads scripts", func(t *testing.T) {
+		w := upload("owners", "def decorate(node):\n    set_metadata(\"team\", node.metadata.get(\"tags\", {}).get(\"Team\", \"none\"))\n")
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var decorator models.Decorator
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&decorator))
+		assert.Equal(t, "owners", decorator.Name)
+		assert.WithinDuration(t, time.Now(), decorator.CreatedAt, time.Minute)
+
+		require.Equal(t, http.StatusCreated, upload("no-null", "def decorate(node):\n    if node.type == \"null_resource\":\n        drop()\n").Code)
+
+		w = request(http.MethodGet, "/decorators", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var decorators []models.Decorator
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&decorators))
+		require.Len(t, decorators, 2)
+		assert.Equal(t, "no-null", decorators[0].Name)
+
+		assert.Equal(t, http.StatusOK, request(http.MethodGet, "/decorators/owners", "").Code)
+	})
+
+	t.Run("rejects invalid scripts", func(t *testing.T) {
+		tests := []struct {
+			name, decorator, script, err string
+		}{
+			{"syntax error", "bad", "def decorate(node)\n", "2:1: got newline, want ':'"},
+			{"no decorate function", "bad", "x = 1\n", "decorate(node)"},
+			{"invalid name", "a,b", "def decorate(node):\n    pass\n", "name must be"},
+			{"oversized script", "big", "#" + strings.Repeat("x", maxDecoratorScript), "script too large"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := upload(tt.decorator, tt.script)
+
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				assert.Contains(t, w.Body.String(), tt.err)
+			})
+		}
+	})
+
+	t.Run("decorates parsed graphs", func(t *testing.T) {
+		w := request(http.MethodPost, "/parse?decorators=owners,no-null", tfstate)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var graph models.Graph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+		require.Len(t, graph.Nodes, 1)
+		assert.Equal(t, "aws_instance", graph.Nodes[0].Type)
+		assert.Equal(t, "payments", graph.Nodes[0].Metadata["team"])
+		assert.Empty(t, graph.Edges)
+		assert.Equal(t, 1, graph.Stats.TotalNodes)
+
+		w = request(http.MethodPost, "/parse", tfstate)
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+		assert.Len(t, graph.Nodes, 2, "graphs are only decorated on request")
+	})
+
+	t.Run("reports failing decorators", func(t *testing.T) {
+		require.Equal(t, http.StatusCreated, upload("strict", "def decorate(node):\n    if not node.metadata.get(\"tags\"):\n        fail(\"untagged\")\n").Code)
+
+		w := request(http.MethodPost, "/parse?decorators=strict", tfstate)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "Decorator strict failed")
+		assert.Contains(t, w.Body.String(), "fail: untagged")
+
+		w = request(http.MethodPost, "/parse?decorators=missing", tfstate)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		previousTimeout := decoratorTimeout
+		decoratorTimeout = time.Nanosecond
+		t.Cleanup(func() { decoratorTimeout = previousTimeout })
+
+		w := request(http.MethodPost, "/parse?decorators=owners", tfstate)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "decorators ran longer than 1ns")
+
+		w = upload("late", "def decorate(node):\n    pass\n")
+		assert.Equal(t, http.StatusBadRequest, w.Code, "scripts are compiled within the timeout")
+		assert.Contains(t, w.Body.String(), "decorators ran longer than 1ns")
+	})
+
+	t.Run("deletes scripts", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, request(http.MethodDelete, "/decorators/owners", "").Code)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodDelete, "/decorators/owners", "").Code)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodGet, "/decorators/owners", "").Code)
+	})
+}
+
+func TestDecoratorTimeoutFromEnv(t *testing.T) {
+	t.Run("defaults to five seconds", func(t *testing.T) {
+		t.Setenv("TERRASCOPE_DECORATOR_TIMEOUT", "")
+		assert.Equal(t, defaultDecoratorTimeout, decoratorTimeoutFromEnv())
+	})
+
+	t.Run("reads a duration", func(t *testing.T) {
+		t.Setenv("TERRASCOPE_DECORATOR_TIMEOUT", "500ms")
+		assert.Equal(t, 500*time.Millisecond, decoratorTimeoutFromEnv())
+	})
+
+	t.Run("ignores invalid values", func(t *testing.T) {
+		t.Setenv("TERRASCOPE_DECORATOR_TIMEOUT", "-1s")
+		assert.Equal(t, defaultDecoratorTimeout, decoratorTimeoutFromEnv())
+	})
+}
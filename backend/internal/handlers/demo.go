@@ -0,0 +1,66 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/terrascope/core/internal/parser/testgen"
+)
+
+// Defaults and bounds of the generated demo states.
+const (
+	defaultDemoResources = 100
+	defaultDemoModules   = 5
+	maxDemoResources     = 100000
+	maxDemoModules       = 1000
+)
+
+// DemoStateHandler returns a synthetic state sized by ?resources= and
+// ?modules=. The same ?seed= always returns the same state.
+func DemoStateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resources, ok := intParam(w, r, "resources", defaultDemoResources, 1, maxDemoResources)
+	if !ok {
+		return
+	}
+
+	modules, ok := intParam(w, r, "modules", defaultDemoModules, 0, maxDemoModules)
+	if !ok {
+		return
+	}
+
+	var seed uint64
+	if value := r.URL.Query().Get("seed"); value != "" {
+		var err error
+		if seed, err = strconv.ParseUint(value, 10, 64); err != nil {
+			http.Error(w, "Invalid seed: "+value, http.StatusBadRequest)
+			return
+		}
+	}
+
+	writeJSON(w, r, testgen.Generate(testgen.Options{Resources: resources, Modules: modules, Seed: seed}))
+}
+
+// intParam reads an integer query parameter between lowest and highest, or
+// returns the default when it is absent. On failure it writes a 400 response
+// and returns false.
+func intParam(w http.ResponseWriter, r *http.Request, name string, def, lowest, highest int) (int, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, true
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < lowest || n > highest {
+		http.Error(w, "Invalid "+name+": "+value, http.StatusBadRequest)
+		return 0, false
+	}
+
+	return n, true
+}
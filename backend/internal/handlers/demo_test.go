@@ -0,0 +1,53 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestDemoStateHandler(t *testing.T) {
+	request := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		DemoStateHandler(w, httptest.NewRequest(http.MethodGet, url, nil))
+
+		return w
+	}
+
+	t.Run("generates the requested size", func(t *testing.T) {
+		w := request("/demo/state?resources=40&modules=3&seed=9")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var state models.TerraformState
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&state))
+		assert.Len(t, state.Resources, 40)
+		assert.Equal(t, 4, state.Version)
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		w := request("/demo/state")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var state models.TerraformState
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&state))
+		assert.Len(t, state.Resources, defaultDemoResources)
+	})
+
+	for _, url := range []string{
+		"/demo/state?resources=0",
+		"/demo/state?resources=1000000",
+		"/demo/state?modules=-1",
+		"/demo/state?seed=abc",
+	} {
+		t.Run("rejects "+url, func(t *testing.T) {
+			assert.Equal(t, http.StatusBadRequest, request(url).Code)
+		})
+	}
+}
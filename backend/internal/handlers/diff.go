@@ -0,0 +1,179 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/terrascope/core/internal/diff"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+	"github.com/terrascope/core/internal/store"
+)
+
+type DiffRequest struct {
+	Before      json.RawMessage `json:"before"`
+	After       json.RawMessage `json:"after"`
+	BeforeLabel string          `json:"before_label,omitempty"`
+	AfterLabel  string          `json:"after_label,omitempty"`
+}
+
+// DiffHandler compares two states. By default it lists the added, removed,
+// modified and renamed resources; with ?view=graph it returns their merged
+// dependency graph instead, with the change of every node and edge, and the
+// state each node comes from, labelled as the request labels it.
+func DiffHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	var req DiffRequest
+	if err := json.Unmarshal(body.Bytes(), &req); err != nil {
+		http.Error(w, "Invalid diff request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	before, old, err := parseGraph(req.Before)
+	if err != nil {
+		http.Error(w, "Invalid before tfstate: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	after, current, err := parseGraph(req.After)
+	if err != nil {
+		http.Error(w, "Invalid after tfstate: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeDiff(w, r, before, after, old, current, req.BeforeLabel, req.AfterLabel)
+}
+
+type BackupDiffRequest struct {
+	State       json.RawMessage `json:"state"`
+	Backup      json.RawMessage `json:"backup"`
+	StateLabel  string          `json:"state_label,omitempty"`
+	BackupLabel string          `json:"backup_label,omitempty"`
+}
+
+// BackupDiffHandler compares a state with the terraform.tfstate.backup that
+// Terraform saved before writing it, which tells what the last apply did. The
+// backup must share the lineage of the state and not be newer. It answers
+// like DiffHandler.
+func BackupDiffHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	var req BackupDiffRequest
+	if err := json.Unmarshal(body.Bytes(), &req); err != nil {
+		http.Error(w, "Invalid diff request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	state, current, err := parseGraph(req.State)
+	if err != nil {
+		http.Error(w, "Invalid state tfstate: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	backup, old, err := parseGraph(req.Backup)
+	if err != nil {
+		http.Error(w, "Invalid backup tfstate: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := diff.CheckBackup(backup, state); err != nil {
+		http.Error(w, "Backup does not match the state: "+err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	writeDiff(w, r, backup, state, old, current, req.BackupLabel, req.StateLabel)
+}
+
+// CompareEnvironmentsHandler compares the latest stored versions of the
+// lineages named by ?left= and ?right=, typically two environments of the
+// same configuration, and reports the resources only one of them has and the
+// attributes that diverge between the others. ?ignore= lists more attributes,
+// separated by commas, that are expected to differ.
+func CompareEnvironmentsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	if query.Get("left") == "" || query.Get("right") == "" {
+		http.Error(w, "Both left and right lineages are required", http.StatusBadRequest)
+		return
+	}
+
+	var states [2]*models.TerraformState
+	for i, lineage := range []string{query.Get("left"), query.Get("right")} {
+		data, err := latestVersion(lineage)
+		if errors.Is(err, store.ErrNotFound) {
+			http.Error(w, "Lineage not found: "+lineage, http.StatusNotFound)
+			return
+		} else if err != nil {
+			storageFailed(w, err)
+			return
+		}
+
+		if states[i], err = parser.ParseTfstate(data); err != nil {
+			http.Error(w, "Invalid tfstate of "+lineage+": "+err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	var ignored []string
+	if value := query.Get("ignore"); value != "" {
+		ignored = strings.Split(value, ",")
+	}
+
+	writeJSON(w, r, diff.Environments(states[0], states[1], ignored))
+}
+
+// latestVersion returns the raw document of the latest stored version of a
+// lineage.
+func latestVersion(lineage string) ([]byte, error) {
+	versions, err := storedStates.Versions(lineage)
+	if err != nil {
+		return nil, err
+	}
+
+	_, data, err := storedStates.Get(lineage, versions[len(versions)-1].Serial)
+
+	return data, err
+}
+
+// writeDiff writes the changes between two states in the view named by
+// ?view=: the changed resources, or with view=graph the merge of their
+// graphs, whose nodes carry the labels of their states.
+func writeDiff(w http.ResponseWriter, r *http.Request, before, after *models.TerraformState, old, current *models.Graph, beforeLabel, afterLabel string) {
+	switch view := r.URL.Query().Get("view"); view {
+	case "", "changes":
+		writeJSON(w, r, diff.States(before, after))
+	case "graph":
+		graph := diff.MergeGraphs(before, after, old, current)
+		diff.Label(graph, beforeLabel, afterLabel)
+		writeJSON(w, r, graph)
+	default:
+		http.Error(w, "Unknown view: "+view, http.StatusBadRequest)
+	}
+}
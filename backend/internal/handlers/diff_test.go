@@ -0,0 +1,293 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+func TestDiffHandler(t *testing.T) {
+	diffBody := `{
+		"before": {
+			"version": 4,
+			"terraform_version": "1.5.0",
+			"serial": 1,
+			"lineage": "abc-123",
+			"resources": [
+				{
+					"mode": "managed",
+					"type": "aws_s3_bucket",
+					"name": "assets",
+					"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+					"instances": [{"attributes": {"id": "assets-bucket"}}]
+				}
+			]
+		},
+		"after": {
+			"version": 4,
+			"terraform_version": "1.5.0",
+			"serial": 2,
+			"lineage": "abc-123",
+			"resources": [
+				{
+					"mode": "managed",
+					"type": "aws_s3_bucket",
+					"name": "static",
+					"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+					"instances": [{"attributes": {"id": "assets-bucket"}}]
+				}
+			]
+		}
+	}`
+
+	t.Run("reports moved resources as renames", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/diff", strings.NewReader(diffBody))
+		w := httptest.NewRecorder()
+
+		DiffHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var result models.StateDiff
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+
+		assert.Empty(t, result.Added)
+		assert.Empty(t, result.Removed)
+		require.Len(t, result.Renamed, 1)
+		assert.Equal(t, "aws_s3_bucket.assets", result.Renamed[0].From)
+		assert.Equal(t, "aws_s3_bucket.static", result.Renamed[0].To)
+	})
+
+	t.Run("merges both graphs with view=graph", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/diff?view=graph", strings.NewReader(diffBody))
+		w := httptest.NewRecorder()
+
+		DiffHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var result models.DiffGraph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+
+		require.Len(t, result.Nodes, 1)
+		assert.Equal(t, "aws_s3_bucket.static", result.Nodes[0].ID)
+		assert.Equal(t, models.ChangeModified, result.Nodes[0].Change)
+		assert.Equal(t, "aws_s3_bucket.assets", result.Nodes[0].RenamedFrom)
+		assert.Empty(t, result.Edges)
+	})
+
+	t.Run("returns 400 for unknown view", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/diff?view=tree", strings.NewReader(diffBody))
+		w := httptest.NewRecorder()
+
+		DiffHandler(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Unknown view: tree")
+	})
+
+	t.Run("returns 405 for GET request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/diff", nil)
+		w := httptest.NewRecorder()
+
+		DiffHandler(w, req)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+
+	t.Run("returns 400 for invalid request body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/diff", strings.NewReader(`{invalid`))
+		w := httptest.NewRecorder()
+
+		DiffHandler(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid diff request")
+	})
+
+	t.Run("returns 400 when a state is missing", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/diff", strings.NewReader(`{"before": {"version": 4, "terraform_version": "1.5.0"}}`))
+		w := httptest.NewRecorder()
+
+		DiffHandler(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid after tfstate")
+	})
+}
+
+func TestBackupDiffHandler(t *testing.T) {
+	tfstate := func(serial int, lineage, bucket string) string {
+		return `{
+			"version": 4,
+			"terraform_version": "1.5.0",
+			"serial": ` + strconv.Itoa(serial) + `,
+			"lineage": "` + lineage + `",
+			"resources": [
+				{
+					"mode": "managed",
+					"type": "aws_s3_bucket",
+					"name": "` + bucket + `",
+					"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+					"instances": [{"attributes": {"id": "` + bucket + `"}}]
+				}
+			]
+		}`
+	}
+
+	diffBackup := func(query, state, backup string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		body := `{"state": ` + state + `, "backup": ` + backup + `}`
+		BackupDiffHandler(w, httptest.NewRequest(http.MethodPost, "/diff/backup"+query, strings.NewReader(body)))
+
+		return w
+	}
+
+	t.Run("reports what the last apply changed", func(t *testing.T) {
+		w := diffBackup("", tfstate(2, "abc-123", "logs"), tfstate(1, "abc-123", "assets"))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var result models.StateDiff
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+		assert.Equal(t, []string{"aws_s3_bucket.logs"}, result.Added)
+		assert.Equal(t, []string{"aws_s3_bucket.assets"}, result.Removed)
+	})
+
+	t.Run("merges both graphs with view=graph", func(t *testing.T) {
+		w := diffBackup("?view=graph", tfstate(2, "abc-123", "logs"), tfstate(1, "abc-123", "assets"))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var graph models.DiffGraph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+		require.Len(t, graph.Nodes, 2)
+		assert.Equal(t, models.NodeSource{Lineage: "abc-123", Serial: 2}, graph.Nodes[0].Source)
+		assert.Equal(t, models.NodeSource{Lineage: "abc-123", Serial: 1}, graph.Nodes[1].Source, "removed nodes come from the backup")
+	})
+
+	t.Run("labels the states of the nodes", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		body := `{"state": ` + tfstate(2, "abc-123", "logs") + `, "backup": ` + tfstate(1, "abc-123", "assets") + `, "state_label": "prod.tfstate", "backup_label": "prod.tfstate.backup"}`
+		BackupDiffHandler(w, httptest.NewRequest(http.MethodPost, "/diff/backup?view=graph", strings.NewReader(body)))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var graph models.DiffGraph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+		require.Len(t, graph.Nodes, 2)
+		assert.Equal(t, "prod.tfstate", graph.Nodes[0].Source.Label)
+		assert.Equal(t, "prod.tfstate.backup", graph.Nodes[1].Source.Label)
+	})
+
+	t.Run("returns 422 when the backup is of another state", func(t *testing.T) {
+		w := diffBackup("", tfstate(2, "abc-123", "logs"), tfstate(1, "def-456", "assets"))
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "Backup does not match the state")
+
+		w = diffBackup("", tfstate(2, "abc-123", "logs"), tfstate(3, "abc-123", "assets"))
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "backup serial 3 is newer than state serial 2")
+	})
+
+	t.Run("returns 400 when the backup is missing", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		BackupDiffHandler(w, httptest.NewRequest(http.MethodPost, "/diff/backup", strings.NewReader(`{"state": `+tfstate(2, "abc-123", "logs")+`}`)))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid backup tfstate")
+	})
+
+	t.Run("returns 405 for GET request", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		BackupDiffHandler(w, httptest.NewRequest(http.MethodGet, "/diff/backup", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
+
+func TestCompareEnvironmentsHandler(t *testing.T) {
+	previous := storedStates
+	storedStates = store.NewStateStore(time.Hour)
+	t.Cleanup(func() { storedStates = previous })
+
+	tfstate := func(lineage string, serial int, class string, names ...string) []byte {
+		resources := make([]string, len(names))
+		for i, name := range names {
+			resources[i] = `{
+				"mode": "managed",
+				"type": "aws_db_instance",
+				"name": "` + name + `",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "` + lineage + `-` + name + `", "instance_class": "` + class + `"}}]
+			}`
+		}
+
+		return []byte(`{"version": 4, "terraform_version": "1.5.0", "serial": ` + strconv.Itoa(serial) + `, "lineage": "` + lineage +
+			`", "resources": [` + strings.Join(resources, ",") + `]}`)
+	}
+
+	require.NoError(t, storedStates.Put(models.StateVersion{Lineage: "prod", Serial: 1}, tfstate("prod", 1, "db.r6g.large", "main")))
+	require.NoError(t, storedStates.Put(models.StateVersion{Lineage: "prod", Serial: 2}, tfstate("prod", 2, "db.r6g.large", "main", "replica")))
+	require.NoError(t, storedStates.Put(models.StateVersion{Lineage: "staging", Serial: 1}, tfstate("staging", 1, "db.t4g.small", "main")))
+	require.NoError(t, storedStates.Put(models.StateVersion{Lineage: "broken", Serial: 1}, []byte(`{}`)))
+
+	compare := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		CompareEnvironmentsHandler(w, httptest.NewRequest(http.MethodGet, "/compare/environments"+query, nil))
+
+		return w
+	}
+
+	t.Run("compares the latest versions", func(t *testing.T) {
+		w := compare("?left=prod&right=staging")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var result models.EnvironmentDiff
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+
+		assert.Equal(t, models.EnvironmentSide{Lineage: "prod", Serial: 2}, result.Left)
+		assert.Equal(t, 1, result.Shared)
+		assert.Equal(t, []string{"aws_db_instance.replica"}, result.OnlyLeft)
+		assert.Empty(t, result.OnlyRight)
+		assert.Equal(t, []models.ResourceChange{{ID: "aws_db_instance.main", ChangedAttributes: []string{"instance_class"}}}, result.Diverged)
+	})
+
+	t.Run("ignores the given attributes", func(t *testing.T) {
+		w := compare("?left=prod&right=staging&ignore=instance_class")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var result models.EnvironmentDiff
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+		assert.Empty(t, result.Diverged)
+	})
+
+	t.Run("requires both lineages", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, compare("?left=prod").Code)
+	})
+
+	t.Run("returns 404 for unknown lineages", func(t *testing.T) {
+		w := compare("?left=prod&right=qa")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "Lineage not found: qa")
+	})
+
+	t.Run("returns 422 for states that no longer parse", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnprocessableEntity, compare("?left=prod&right=broken").Code)
+	})
+
+	t.Run("returns 405 for POST request", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		CompareEnvironmentsHandler(w, httptest.NewRequest(http.MethodPost, "/compare/environments", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
@@ -0,0 +1,172 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/terrascope/core/internal/cache"
+	"github.com/terrascope/core/internal/events"
+	"github.com/terrascope/core/internal/models"
+)
+
+// eventsHeartbeat is how often StateEventsHandler writes a comment to an idle
+// stream, so that proxies do not close it.
+var eventsHeartbeat = 25 * time.Second
+
+// stateEvents delivers the events of each lineage to StateEventsHandler,
+// through the results cache when it relays messages between replicas.
+var stateEvents = stateEventsFromEnv()
+
+func stateEventsFromEnv() *events.Broker {
+	pubsub, _ := results.(cache.PubSub)
+	return events.NewBroker(pubsub)
+}
+
+// RelayEvents delivers the events published by other replicas to the streams
+// of this server until ctx is done.
+func RelayEvents(ctx context.Context) {
+	stateEvents.Relay(ctx)
+}
+
+// StateEventsHandler streams the events of a lineage as server-sent events
+// until the client goes away: a version event when a version is stored, and
+// an analysis event when an analysis of one of its states completes. The
+// lineage need not have stored versions yet.
+func StateEventsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	updates, cancel := stateEvents.Subscribe(r.PathValue("lineage"))
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	stream := http.NewResponseController(w)
+	if err := stream.Flush(); err != nil {
+		log.Printf("Error streaming events: %v", err)
+		return
+	}
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-updates:
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("Error encoding event: %v", err)
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+
+		if err := stream.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// PublishAnalysis tells the clients following a lineage that the named
+// analysis of one of its stored versions completed. It wraps analysis handlers
+// outside CacheResults, so that cached results are announced too. Only the
+// analyses of authenticated requests, by API key or session, are announced,
+// and only for the lineage and serial of a stored version, as read from the
+// posted state or from the state field of the posted request.
+func PublishAnalysis(analysis string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, ok := readRequestBody(w, r)
+		if !ok {
+			return
+		}
+		data := bytes.Clone(body.Bytes())
+		releaseBody(body)
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		r.Body = io.NopCloser(bytes.NewReader(data))
+		r.ContentLength = int64(len(data))
+		next(recorder, r)
+
+		if recorder.status != http.StatusOK || !authenticated(r) {
+			return
+		}
+
+		lineage, serial, ok := analyzedVersion(data)
+		if !ok {
+			return
+		}
+
+		versions, err := storedStates.Versions(lineage)
+		if err != nil || !slices.ContainsFunc(versions, func(v models.StateVersion) bool { return v.Serial == serial }) {
+			return
+		}
+
+		stateEvents.Publish(models.StateEvent{
+			Type:     models.StateEventAnalysis,
+			Lineage:  lineage,
+			Serial:   serial,
+			Analysis: analysis,
+			At:       time.Now().UTC(),
+		})
+	}
+}
+
+// authenticated reports whether a request comes with an API key or from a
+// signed-in user.
+func authenticated(r *http.Request) bool {
+	if _, ok := requestAPIKey(r); ok {
+		return true
+	}
+
+	_, ok, _ := currentUser(r)
+
+	return ok
+}
+
+// analyzedVersion returns the lineage and serial of the state an analysis
+// request posted, as the whole body or as its state field.
+func analyzedVersion(data []byte) (lineage string, serial int, ok bool) {
+	var version struct {
+		Lineage string          `json:"lineage"`
+		Serial  int             `json:"serial"`
+		State   json.RawMessage `json:"state"`
+	}
+	if json.Unmarshal(data, &version) != nil {
+		return "", 0, false
+	}
+
+	if version.Lineage == "" && len(version.State) > 0 {
+		return analyzedVersion(version.State)
+	}
+
+	return version.Lineage, version.Serial, version.Lineage != ""
+}
+
+// statusRecorder writes a response through while keeping its status.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	sr.status = status
+	sr.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,125 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/cache"
+	"github.com/terrascope/core/internal/events"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+func TestStateEventsHandler(t *testing.T) {
+	previousEvents, previousStates, previousHeartbeat, previousResults := stateEvents, storedStates, eventsHeartbeat, results
+	stateEvents, storedStates, eventsHeartbeat = events.NewBroker(nil), store.NewStateStore(time.Hour), 50*time.Millisecond
+	results = cache.NewMemory(16, time.Minute)
+	t.Cleanup(func() {
+		stateEvents, storedStates, eventsHeartbeat, results = previousEvents, previousStates, previousHeartbeat, previousResults
+	})
+
+	// withKey authenticates requests as a client with an API key would be.
+	withKey := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			next(w, withProjectKey(r, "web"))
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/states", StatesHandler)
+	mux.HandleFunc("/states/{lineage}/events", StateEventsHandler)
+	mux.HandleFunc("/analyze/endpoints", withKey(PublishAnalysis("endpoints", CacheResults(EndpointsHandler))))
+	mux.HandleFunc("/anonymous/endpoints", PublishAnalysis("anonymous", CacheResults(EndpointsHandler)))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/states/events-test/events")
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	stream := bufio.NewReader(response.Body)
+
+	// next returns the type and data of the next event, skipping comments.
+	next := func() (string, models.StateEvent) {
+		var kind string
+		var event models.StateEvent
+		for {
+			line, err := stream.ReadString('\n')
+			require.NoError(t, err)
+
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				kind = strings.TrimSpace(strings.TrimPrefix(line, "event: "))
+			case strings.HasPrefix(line, "data: "):
+				require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+			case line == "\n" && kind != "":
+				return kind, event
+			}
+		}
+	}
+
+	state := `{"version": 4, "terraform_version": "1.5.0", "serial": 3, "lineage": "events-test", "resources": []}`
+
+	// The subscription is in place once the headers are sent.
+	post, err := http.Post(server.URL+"/states", "application/json", strings.NewReader(state))
+	require.NoError(t, err)
+	post.Body.Close()
+	require.Equal(t, http.StatusCreated, post.StatusCode)
+
+	kind, event := next()
+	assert.Equal(t, models.StateEventVersion, kind)
+	assert.Equal(t, "events-test", event.Lineage)
+	assert.Equal(t, 3, event.Serial)
+
+	analyze := func(path, state string) *http.Response {
+		post, err := http.Post(server.URL+path, "application/json", strings.NewReader(state))
+		require.NoError(t, err)
+		post.Body.Close()
+		require.Equal(t, http.StatusOK, post.StatusCode)
+
+		return post
+	}
+
+	// Neither anonymous analyses nor those of versions that are not stored
+	// are announced, so the next event is the one of the stored version.
+	analyze("/anonymous/endpoints", state)
+	analyze("/analyze/endpoints", strings.Replace(state, `"serial": 3`, `"serial": 4`, 1))
+	analyze("/analyze/endpoints", state)
+
+	kind, event = next()
+	assert.Equal(t, models.StateEventAnalysis, kind)
+	assert.Equal(t, "endpoints", event.Analysis)
+	assert.Equal(t, 3, event.Serial)
+
+	t.Run("announces cached results", func(t *testing.T) {
+		assert.Equal(t, "hit", analyze("/analyze/endpoints", state).Header.Get(CacheHeader))
+
+		kind, event := next()
+		assert.Equal(t, models.StateEventAnalysis, kind)
+		assert.Equal(t, "endpoints", event.Analysis)
+	})
+
+	t.Run("keeps idle streams alive", func(t *testing.T) {
+		line, err := stream.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, ": keep-alive\n", line)
+	})
+}
+
+func TestStateEventsHandlerMethods(t *testing.T) {
+	rr := httptest.NewRecorder()
+	StateEventsHandler(rr, httptest.NewRequest(http.MethodPost, "/states/x/events", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
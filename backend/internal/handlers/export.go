@@ -0,0 +1,81 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/terrascope/core/internal/export"
+	"github.com/terrascope/core/internal/parser"
+	"github.com/terrascope/core/internal/render"
+)
+
+// artifactStore receives the artifacts written by ExportHandler. It is nil
+// when no export bucket is configured.
+var artifactStore = newArtifactStore()
+
+func newArtifactStore() export.Store {
+	if store := export.NewS3StoreFromEnv(); store != nil {
+		return store
+	}
+
+	return nil
+}
+
+// ExportHandler renders the dependency graph of the posted state in the
+// formats listed in ?formats= (all formats by default) and uploads them to the
+// configured bucket under keys derived from the state lineage and serial.
+// States whose lineage is not a UUID are rejected.
+func ExportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if artifactStore == nil {
+		http.Error(w, "Export is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	formats := render.FormatNames()
+	if param := r.URL.Query().Get("formats"); param != "" {
+		formats = strings.Split(param, ",")
+	}
+
+	for _, name := range formats {
+		if _, err := render.Lookup(name); err != nil {
+			http.Error(w, "Unknown format: "+name, http.StatusBadRequest)
+			return
+		}
+	}
+
+	opts, ok := graphOptions(w, r)
+	if !ok {
+		return
+	}
+
+	state, ok := readState(w, r)
+	if !ok {
+		return
+	}
+
+	if !export.ValidLineage(state.Lineage) {
+		http.Error(w, "Invalid lineage: "+export.ErrInvalidLineage.Error(), http.StatusBadRequest)
+		return
+	}
+
+	graph := parser.BuildGraphWithOptions(state, opts)
+	parser.SortGraph(graph)
+	graph.Stats = parser.ComputeStats(graph)
+
+	result, err := export.Export(r.Context(), artifactStore, state, graph, formats)
+	if err != nil {
+		log.Printf("Export failed: %v", err)
+		http.Error(w, "Export failed", http.StatusBadGateway)
+		return
+	}
+
+	writeJSON(w, r, result)
+}
@@ -0,0 +1,154 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+type recordingStore struct {
+	keys []string
+	err  error
+}
+
+func (s *recordingStore) Put(_ context.Context, key string, _ []byte, _ string) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+
+	s.keys = append(s.keys, key)
+
+	return key, nil
+}
+
+func (s *recordingStore) URL(key string) string {
+	return "https://bucket.example.com/" + key
+}
+
+func TestExportHandler(t *testing.T) {
+	tfstate := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"serial": 7,
+		"lineage": "5d1c7f0e-8a2b-4e6d-b3c9-1f0a2e4d6c8b",
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_vpc",
+				"name": "main",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "vpc-1"}}]
+			}
+		]
+	}`
+
+	withStore := func(t *testing.T, store *recordingStore) {
+		t.Helper()
+
+		original := artifactStore
+		artifactStore = store
+		t.Cleanup(func() { artifactStore = original })
+	}
+
+	t.Run("uploads all formats by default", func(t *testing.T) {
+		store := &recordingStore{}
+		withStore(t, store)
+
+		req := httptest.NewRequest(http.MethodPost, "/export", strings.NewReader(tfstate))
+		w := httptest.NewRecorder()
+
+		ExportHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var result models.ExportResult
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+
+		assert.Equal(t, []string{"5d1c7f0e-8a2b-4e6d-b3c9-1f0a2e4d6c8b/7/graph.dot", "5d1c7f0e-8a2b-4e6d-b3c9-1f0a2e4d6c8b/7/graph.html", "5d1c7f0e-8a2b-4e6d-b3c9-1f0a2e4d6c8b/7/graph.json", "5d1c7f0e-8a2b-4e6d-b3c9-1f0a2e4d6c8b/7/graph.sqlite", "5d1c7f0e-8a2b-4e6d-b3c9-1f0a2e4d6c8b/7/graph.svg"}, store.keys)
+		require.Len(t, result.Artifacts, 5)
+		assert.Equal(t, "https://bucket.example.com/5d1c7f0e-8a2b-4e6d-b3c9-1f0a2e4d6c8b/7/graph.dot", result.Artifacts[0].URL)
+	})
+
+	t.Run("uploads selected formats", func(t *testing.T) {
+		store := &recordingStore{}
+		withStore(t, store)
+
+		req := httptest.NewRequest(http.MethodPost, "/export?formats=json", strings.NewReader(tfstate))
+		w := httptest.NewRecorder()
+
+		ExportHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"5d1c7f0e-8a2b-4e6d-b3c9-1f0a2e4d6c8b/7/graph.json"}, store.keys)
+	})
+
+	t.Run("returns 400 for unknown format", func(t *testing.T) {
+		withStore(t, &recordingStore{})
+
+		req := httptest.NewRequest(http.MethodPost, "/export?formats=json,pdf", strings.NewReader(tfstate))
+		w := httptest.NewRecorder()
+
+		ExportHandler(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Unknown format: pdf")
+	})
+
+	t.Run("returns 400 for a lineage that is not a UUID", func(t *testing.T) {
+		store := &recordingStore{}
+		withStore(t, store)
+
+		body := strings.Replace(tfstate, "5d1c7f0e-8a2b-4e6d-b3c9-1f0a2e4d6c8b", "../../releases/latest", 1)
+		req := httptest.NewRequest(http.MethodPost, "/export", strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		ExportHandler(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid lineage")
+		assert.Empty(t, store.keys)
+	})
+
+	t.Run("returns 502 when the upload fails", func(t *testing.T) {
+		withStore(t, &recordingStore{err: errors.New("access denied")})
+
+		req := httptest.NewRequest(http.MethodPost, "/export", strings.NewReader(tfstate))
+		w := httptest.NewRecorder()
+
+		ExportHandler(w, req)
+
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+	})
+
+	t.Run("returns 503 when export is not configured", func(t *testing.T) {
+		original := artifactStore
+		artifactStore = nil
+		defer func() { artifactStore = original }()
+
+		req := httptest.NewRequest(http.MethodPost, "/export", strings.NewReader(tfstate))
+		w := httptest.NewRecorder()
+
+		ExportHandler(w, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("returns 405 for GET request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/export", nil)
+		w := httptest.NewRecorder()
+
+		ExportHandler(w, req)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
@@ -0,0 +1,147 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/terrascope/core/internal/analysis"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+	"github.com/terrascope/core/internal/store"
+)
+
+// goldenModules keeps the registered golden modules, which stored states are
+// checked against.
+var goldenModules = store.NewGoldenModuleStore()
+
+// goldenModuleName keeps golden module names usable in a path.
+var goldenModuleName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// GoldenModuleRequest registers a golden module. Its resources are either
+// listed, or taken from the module instance at Instance of State, a reference
+// deployment of the module.
+type GoldenModuleRequest struct {
+	models.GoldenModule
+	State    json.RawMessage `json:"state,omitempty"`
+	Instance string          `json:"instance,omitempty"`
+}
+
+// GoldenModulesHandler registers a golden module (POST), replacing the golden
+// module of the same name, or lists them (GET).
+func GoldenModulesHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		createGoldenModule(w, r)
+	case http.MethodGet:
+		writeJSON(w, r, goldenModules.List())
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func createGoldenModule(w http.ResponseWriter, r *http.Request) {
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	var req GoldenModuleRequest
+	if err := json.Unmarshal(body.Bytes(), &req); err != nil {
+		http.Error(w, "Invalid golden module: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	golden := req.GoldenModule
+
+	if !goldenModuleName.MatchString(golden.Name) {
+		http.Error(w, "Invalid golden module: name must be letters, digits, dashes and underscores", http.StatusBadRequest)
+		return
+	}
+
+	if golden.Module == "" {
+		http.Error(w, "Invalid golden module: module is required", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.State) > 0 {
+		state, err := parser.ParseTfstate(req.State)
+		if err != nil {
+			http.Error(w, "Invalid tfstate: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		golden.Resources = analysis.ModuleSignature(state, req.Instance)
+	}
+
+	if len(golden.Resources) == 0 {
+		http.Error(w, "Invalid golden module: no resources", http.StatusBadRequest)
+		return
+	}
+
+	sort.Strings(golden.Resources)
+	golden.Resources = slices.Compact(golden.Resources)
+	golden.CreatedAt = time.Now().UTC()
+	goldenModules.Put(golden)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, r, golden)
+}
+
+// GoldenModuleHandler returns (GET) or deletes (DELETE) a golden module.
+func GoldenModuleHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	switch r.Method {
+	case http.MethodGet:
+		golden, ok := goldenModules.Get(name)
+		if !ok {
+			http.Error(w, "Golden module not found: "+name, http.StatusNotFound)
+			return
+		}
+
+		writeJSON(w, r, golden)
+	case http.MethodDelete:
+		if !goldenModules.Delete(name) {
+			http.Error(w, "Golden module not found: "+name, http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GoldenConformanceHandler checks every instance of a golden module in the
+// latest stored version of each lineage against its signature, reporting the
+// resources each instance misses or adds.
+func GoldenConformanceHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := r.PathValue("name")
+
+	golden, ok := goldenModules.Get(name)
+	if !ok {
+		http.Error(w, "Golden module not found: "+name, http.StatusNotFound)
+		return
+	}
+
+	states, err := latestStates()
+	if err != nil {
+		storageFailed(w, err)
+		return
+	}
+
+	writeJSON(w, r, analysis.Conformance(golden, states))
+}
@@ -0,0 +1,109 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+func TestGoldenModuleHandlers(t *testing.T) {
+	previousModules, previousStates := goldenModules, storedStates
+	goldenModules = store.NewGoldenModuleStore()
+	storedStates = store.NewStateStore(time.Hour)
+	t.Cleanup(func() { goldenModules, storedStates = previousModules, previousStates })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/golden-modules", GoldenModulesHandler)
+	mux.HandleFunc("/golden-modules/{name}", GoldenModuleHandler)
+	mux.HandleFunc("/golden-modules/{name}/conformance", GoldenConformanceHandler)
+
+	request := func(method, url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, url, strings.NewReader(body)))
+
+		return w
+	}
+
+	resource := func(module, resType string) string {
+		return `{"mode": "managed", "type": "` + resType + `", "name": "this", "module": "` + module + `",
+			"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+			"instances": [{"attributes": {"id": "x"}}]}`
+	}
+
+	reference := `{"version": 4, "terraform_version": "1.5.0", "serial": 1, "lineage": "reference", "resources": [` +
+		resource("module.bucket", "aws_s3_bucket") + `,` + resource("module.bucket", "aws_s3_bucket_versioning") + `]}`
+
+	t.Run("registers golden modules from a reference state", func(t *testing.T) {
+		w := request(http.MethodPost, "/golden-modules", `{"name": "s3", "module": "bucket", "state": `+reference+`, "instance": "module.bucket"}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var golden models.GoldenModule
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&golden))
+		assert.Equal(t, []string{"aws_s3_bucket.this", "aws_s3_bucket_versioning.this"}, golden.Resources)
+		assert.WithinDuration(t, time.Now(), golden.CreatedAt, time.Minute)
+
+		assert.Equal(t, http.StatusOK, request(http.MethodGet, "/golden-modules/s3", "").Code)
+	})
+
+	t.Run("registers listed resources", func(t *testing.T) {
+		w := request(http.MethodPost, "/golden-modules", `{"name": "rds", "module": "db", "resources": ["aws_db_instance.this", "aws_db_instance.this"]}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		w = request(http.MethodGet, "/golden-modules", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var modules []models.GoldenModule
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&modules))
+		require.Len(t, modules, 2)
+		assert.Equal(t, "rds", modules[0].Name)
+		assert.Equal(t, []string{"aws_db_instance.this"}, modules[0].Resources)
+	})
+
+	t.Run("rejects invalid golden modules", func(t *testing.T) {
+		for _, body := range []string{
+			`{invalid`,
+			`{"name": "a b", "module": "bucket", "resources": ["aws_s3_bucket.this"]}`,
+			`{"name": "s3", "resources": ["aws_s3_bucket.this"]}`,
+			`{"name": "s3", "module": "bucket"}`,
+			`{"name": "s3", "module": "bucket", "state": ` + reference + `, "instance": "module.other"}`,
+			`{"name": "s3", "module": "bucket", "state": {}}`,
+		} {
+			assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, "/golden-modules", body).Code, body)
+		}
+	})
+
+	t.Run("checks the stored states", func(t *testing.T) {
+		deviating := `{"version": 4, "terraform_version": "1.5.0", "serial": 4, "lineage": "prod", "resources": [` +
+			resource(`module.bucket[\"logs\"]`, "aws_s3_bucket") + `]}`
+		require.NoError(t, storedStates.Put(models.StateVersion{Lineage: "prod", Serial: 4}, []byte(deviating)))
+		require.NoError(t, storedStates.Put(models.StateVersion{Lineage: "reference", Serial: 1}, []byte(reference)))
+
+		w := request(http.MethodGet, "/golden-modules/s3/conformance", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var report models.ConformanceReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+		require.Len(t, report.Instances, 2)
+		assert.Equal(t, 1, report.Deviating)
+		assert.Equal(t, `module.bucket["logs"]`, report.Instances[0].Instance)
+		assert.Equal(t, []string{"aws_s3_bucket_versioning.this"}, report.Instances[0].Missing)
+		assert.True(t, report.Instances[1].Conforms)
+	})
+
+	t.Run("deletes golden modules", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, request(http.MethodDelete, "/golden-modules/rds", "").Code)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodDelete, "/golden-modules/rds", "").Code)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodGet, "/golden-modules/rds", "").Code)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodGet, "/golden-modules/rds/conformance", "").Code)
+	})
+}
@@ -0,0 +1,113 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/terrascope/core/internal/cache"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+	"github.com/terrascope/core/internal/store"
+)
+
+// defaultGraphCacheBytes bounds the documents whose states and graphs are kept
+// in process unless TERRASCOPE_GRAPH_CACHE_BYTES says otherwise.
+const defaultGraphCacheBytes = 64 << 20
+
+// graphCache keeps the states and graphs recently built by ParseHandler and
+// the diff and analyze handlers in process, in front of the results cache,
+// so that a single server without Redis does not parse the same document
+// twice. TERRASCOPE_GRAPH_CACHE_BYTES=0 disables it.
+var graphCache = store.NewGraphCache(graphCacheBytesFromEnv())
+
+func graphCacheBytesFromEnv() int64 {
+	value := os.Getenv("TERRASCOPE_GRAPH_CACHE_BYTES")
+	if value == "" {
+		return defaultGraphCacheBytes
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		log.Printf("Ignoring TERRASCOPE_GRAPH_CACHE_BYTES: invalid value %q", value)
+		return defaultGraphCacheBytes
+	}
+
+	return n
+}
+
+// parseGraph parses a tfstate document and builds its dependency graph with
+// the default options, or returns them from the graph cache. The graph is a
+// copy that callers may set the fields of, but its nodes and edges, like the
+// state, are shared and must not be modified.
+func parseGraph(data []byte) (*models.TerraformState, *models.Graph, error) {
+	key := cache.Key("parsed", data)
+
+	parsed, ok := graphCache.Get(key)
+	if !ok {
+		state, err := parser.ParseTfstate(data)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		parsed = store.ParsedGraph{State: state, Graph: parser.BuildGraph(state)}
+		graphCache.Put(key, parsed, int64(len(data)))
+	}
+
+	graph := *parsed.Graph
+
+	return parsed.State, &graph, nil
+}
+
+// GraphCacheMetricsHandler reports the size and hit rate of the in-process
+// graph cache. With ?format=prometheus it exposes them for scraping.
+func GraphCacheMetricsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	stats := graphCache.Stats()
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		writeJSON(w, r, stats)
+	case "prometheus":
+		w.Header().Set("Content-Type", prometheusContentType)
+
+		if _, err := w.Write(graphCacheMetrics(stats)); err != nil {
+			log.Printf("Error writing response: %v", err)
+		}
+	default:
+		http.Error(w, "Unknown format: "+format, http.StatusBadRequest)
+	}
+}
+
+// graphCacheMetrics renders the graph cache stats in the Prometheus text
+// exposition format. The hit rate is left for queries to compute from the
+// counters.
+func graphCacheMetrics(stats models.GraphCacheStats) []byte {
+	var b bytes.Buffer
+
+	for _, metric := range []struct {
+		name, kind, help string
+		value            float64
+	}{
+		{"terrascope_graph_cache_hits_total", "counter", "Graph cache lookups that found an entry.", float64(stats.Hits)},
+		{"terrascope_graph_cache_misses_total", "counter", "Graph cache lookups that found no entry.", float64(stats.Misses)},
+		{"terrascope_graph_cache_evictions_total", "counter", "Graph cache entries evicted to make room.", float64(stats.Evictions)},
+		{"terrascope_graph_cache_entries", "gauge", "Entries in the graph cache.", float64(stats.Entries)},
+		{"terrascope_graph_cache_bytes", "gauge", "Bytes of the documents the graph cache entries were built from.", float64(stats.Bytes)},
+		{"terrascope_graph_cache_max_bytes", "gauge", "Bound of the graph cache, in bytes of documents.", float64(stats.MaxBytes)},
+	} {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", metric.name, metric.help, metric.name, metric.kind)
+		writeSample(&b, metric.name, metric.value)
+	}
+
+	return b.Bytes()
+}
@@ -0,0 +1,156 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+// useGraphCache replaces the graph cache with an empty one of maxBytes for the
+// test.
+func useGraphCache(t *testing.T, maxBytes int64) {
+	original := graphCache
+	graphCache = store.NewGraphCache(maxBytes)
+	t.Cleanup(func() { graphCache = original })
+}
+
+const graphCacheState = `{
+	"version": 4,
+	"terraform_version": "1.5.0",
+	"serial": 1,
+	"lineage": "graph-cache-test",
+	"resources": [{
+		"mode": "managed",
+		"type": "aws_vpc",
+		"name": "main",
+		"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+		"instances": [{"attributes": {"id": "vpc-1"}}]
+	}]
+}`
+
+func TestGraphCacheHandlers(t *testing.T) {
+	t.Run("parse reuses the graph without the results cache", func(t *testing.T) {
+		useGraphCache(t, defaultGraphCacheBytes)
+
+		parse := func() *httptest.ResponseRecorder {
+			rr := httptest.NewRecorder()
+			ParseHandler(rr, httptest.NewRequest(http.MethodPost, "/parse", strings.NewReader(graphCacheState)))
+			require.Equal(t, http.StatusOK, rr.Code)
+			return rr
+		}
+
+		assert.Equal(t, "miss", parse().Header().Get(CacheHeader))
+
+		stateLocks.Put("graph-cache-test", models.LockInfo{ID: "lock-1"})
+		second := parse()
+		stateLocks.Delete("graph-cache-test")
+		assert.Equal(t, "hit", second.Header().Get(CacheHeader))
+
+		// The lock of the second response is not left on the cached graph.
+		third := parse()
+		var graph models.Graph
+		require.NoError(t, json.NewDecoder(third.Body).Decode(&graph))
+		assert.Nil(t, graph.Lock)
+
+		stats := graphCache.Stats()
+		assert.Equal(t, int64(2), stats.Hits)
+		assert.Equal(t, int64(1), stats.Misses)
+	})
+
+	t.Run("diff and analyze share the parsed states", func(t *testing.T) {
+		useGraphCache(t, defaultGraphCacheBytes)
+
+		rr := httptest.NewRecorder()
+		AnalyzeHandler(rr, httptest.NewRequest(http.MethodPost, "/analyze", strings.NewReader(graphCacheState)))
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		body := `{"before": ` + graphCacheState + `, "after": ` + graphCacheState + `}`
+		rr = httptest.NewRecorder()
+		DiffHandler(rr, httptest.NewRequest(http.MethodPost, "/diff?view=graph", strings.NewReader(body)))
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var merged models.DiffGraph
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&merged))
+		require.Len(t, merged.Nodes, 1)
+		assert.Equal(t, models.ChangeUnchanged, merged.Nodes[0].Change)
+
+		stats := graphCache.Stats()
+		assert.Equal(t, 1, stats.Entries)
+		assert.Equal(t, int64(2), stats.Hits)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		useGraphCache(t, 0)
+
+		for range 2 {
+			rr := httptest.NewRecorder()
+			ParseHandler(rr, httptest.NewRequest(http.MethodPost, "/parse", strings.NewReader(graphCacheState)))
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Empty(t, rr.Header().Get(CacheHeader))
+		}
+
+		assert.Zero(t, graphCache.Stats().Hits)
+	})
+}
+
+func TestGraphCacheMetricsHandler(t *testing.T) {
+	useGraphCache(t, 1024)
+
+	graphCache.Put("a", store.ParsedGraph{State: &models.TerraformState{}, Graph: &models.Graph{}}, 100)
+	graphCache.Get("a")
+	graphCache.Get("b")
+
+	request := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		GraphCacheMetricsHandler(w, httptest.NewRequest(http.MethodGet, url, nil))
+
+		return w
+	}
+
+	t.Run("JSON stats", func(t *testing.T) {
+		w := request("/metrics/cache")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var stats models.GraphCacheStats
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&stats))
+		assert.Equal(t, models.GraphCacheStats{
+			Entries:  1,
+			Bytes:    100,
+			MaxBytes: 1024,
+			Hits:     1,
+			Misses:   1,
+			HitRate:  0.5,
+		}, stats)
+	})
+
+	t.Run("Prometheus metrics", func(t *testing.T) {
+		w := request("/metrics/cache?format=prometheus")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, prometheusContentType, w.Header().Get("Content-Type"))
+
+		body := w.Body.String()
+		assert.Contains(t, body, "# TYPE terrascope_graph_cache_hits_total counter\n")
+		assert.Contains(t, body, "terrascope_graph_cache_hits_total{} 1\n")
+		assert.Contains(t, body, "terrascope_graph_cache_misses_total{} 1\n")
+		assert.Contains(t, body, "terrascope_graph_cache_bytes{} 100\n")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, request("/metrics/cache?format=xml").Code)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		GraphCacheMetricsHandler(w, httptest.NewRequest(http.MethodPost, "/metrics/cache", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
@@ -3,7 +3,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
 	"runtime"
 	"time"
@@ -36,10 +35,5 @@ func HealthHandler(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-	}
+	writeJSON(w, r, response)
 }
@@ -0,0 +1,53 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/terrascope/core/internal/jsonschema"
+	"github.com/terrascope/core/internal/models"
+)
+
+// responseSchemas are the response types JSONSchemaHandler publishes, by
+// name.
+var responseSchemas = map[string]any{
+	"graph":      models.Graph{},
+	"node":       models.Node{},
+	"edge":       models.Edge{},
+	"stats":      models.Stats{},
+	"diff":       models.StateDiff{},
+	"diff-graph": models.DiffGraph{},
+	"findings":   models.AnalysisReport{},
+}
+
+// JSONSchemaHandler serves the JSON Schema of the response type named in the
+// path, generated from the types the server encodes. Its $id is its URL under
+// the API version prefix, so that each version of the API publishes its own
+// schemas.
+func JSONSchemaHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := r.PathValue("name")
+	v, ok := responseSchemas[name]
+	if !ok {
+		names := make([]string, 0, len(responseSchemas))
+		for name := range responseSchemas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		http.Error(w, "Unknown schema: "+name+" (available: "+strings.Join(names, ", ")+")", http.StatusNotFound)
+		return
+	}
+
+	schema := jsonschema.Reflect(v)
+	schema.ID = requestBaseURL(r) + r.URL.Path
+
+	writeJSON(w, r, schema)
+}
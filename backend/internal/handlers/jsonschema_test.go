@@ -0,0 +1,57 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/jsonschema"
+)
+
+func TestJSONSchemaHandler(t *testing.T) {
+	serve := func(method, name string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/api/v1/schemas/"+name, nil)
+		r.SetPathValue("name", name)
+		w := httptest.NewRecorder()
+
+		JSONSchemaHandler(w, r)
+
+		return w
+	}
+
+	t.Run("serves the schema of a response type", func(t *testing.T) {
+		w := serve(http.MethodGet, "graph")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var schema map[string]any
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&schema))
+
+		assert.Equal(t, jsonschema.Draft, schema["$schema"])
+		assert.Equal(t, "http://example.com/api/v1/schemas/graph", schema["$id"])
+		assert.Equal(t, "Graph", schema["title"])
+		assert.Contains(t, schema["$defs"], "Node")
+		assert.Contains(t, schema["properties"], "nodes")
+	})
+
+	t.Run("publishes every response type", func(t *testing.T) {
+		for name := range responseSchemas {
+			assert.Equal(t, http.StatusOK, serve(http.MethodGet, name).Code, name)
+		}
+	})
+
+	t.Run("unknown schema", func(t *testing.T) {
+		w := serve(http.MethodGet, "plan")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "available: diff, diff-graph, edge, findings, graph, node, stats")
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodPost, "graph").Code)
+	})
+}
@@ -0,0 +1,113 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// AdminKeysHandler lists the API keys with their usage, or creates one. The
+// secret of a new key is returned once and cannot be recovered.
+func AdminKeysHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		keys, err := apiKeys.List()
+		if err != nil {
+			storageFailed(w, err)
+			return
+		}
+		writeJSON(w, r, keys)
+		return
+	}
+
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	var req models.APIKeyRequest
+	if err := json.Unmarshal(body.Bytes(), &req); err != nil {
+		http.Error(w, "Invalid API key: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Name == "" {
+		http.Error(w, "API key name is required", http.StatusBadRequest)
+		return
+	}
+
+	if req.Role == "" {
+		req.Role = models.RoleMember
+	}
+	if !validRole(req.Role) {
+		http.Error(w, "Unknown role: "+req.Role, http.StatusBadRequest)
+		return
+	}
+
+	created := models.CreatedAPIKey{
+		APIKey: models.APIKey{
+			ID:        newID(),
+			Name:      req.Name,
+			Role:      req.Role,
+			Project:   req.Project,
+			CreatedAt: time.Now().UTC(),
+		},
+		Secret: apiKeyPrefix + randomToken(),
+	}
+	if err := apiKeys.Create(created.APIKey, created.Secret); err != nil {
+		storageFailed(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, r, created)
+}
+
+// AdminKeyHandler returns an API key with its usage, or revokes it. Revoked
+// keys stay listed so that their usage can still be reviewed.
+func AdminKeyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	id := r.PathValue("id")
+
+	var key models.APIKey
+	var ok bool
+	var err error
+	if r.Method == http.MethodDelete {
+		key, ok, err = apiKeys.Revoke(id, time.Now().UTC())
+	} else {
+		key, ok, err = apiKeys.Get(id)
+	}
+
+	if err != nil {
+		storageFailed(w, err)
+		return
+	}
+	if !ok {
+		http.Error(w, "API key not found: "+id, http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, r, key)
+}
@@ -0,0 +1,100 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+func TestAdminKeysHandlers(t *testing.T) {
+	previous := apiKeys
+	apiKeys = store.NewKeyStore()
+	t.Cleanup(func() { apiKeys = previous })
+
+	mux := adminMux(t)
+	tracked := TrackAPIKeys(mux)
+
+	create := func(body string) models.CreatedAPIKey {
+		t.Helper()
+
+		w := adminRequest(mux, http.MethodPost, "/admin/keys", body)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var created models.CreatedAPIKey
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+		return created
+	}
+
+	withKey := func(method, target, secret string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Set("Authorization", "Bearer "+secret)
+		w := httptest.NewRecorder()
+		tracked.ServeHTTP(w, req)
+		return w
+	}
+
+	member := create(`{"name": "ci", "project": "web"}`)
+	admin := create(`{"name": "ops", "role": "admin"}`)
+
+	t.Run("creates keys with a secret", func(t *testing.T) {
+		assert.Equal(t, "ci", member.Name)
+		assert.Equal(t, models.RoleMember, member.Role)
+		assert.Equal(t, "web", member.Project)
+		assert.True(t, strings.HasPrefix(member.Secret, apiKeyPrefix))
+		assert.Equal(t, models.RoleAdmin, admin.Role)
+	})
+
+	t.Run("rejects invalid keys", func(t *testing.T) {
+		for _, body := range []string{`{}`, `{"name": "x", "role": "owner"}`, `{invalid`} {
+			w := adminRequest(mux, http.MethodPost, "/admin/keys", body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+
+	t.Run("admin keys have the admin scope", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, withKey(http.MethodGet, "/admin/keys", admin.Secret).Code)
+		assert.Equal(t, http.StatusForbidden, withKey(http.MethodGet, "/admin/keys", member.Secret).Code)
+	})
+
+	t.Run("shows usage per key without secrets", func(t *testing.T) {
+		w := adminRequest(mux, http.MethodGet, "/admin/keys/"+member.ID, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), member.Secret)
+
+		var key models.APIKey
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&key))
+		assert.EqualValues(t, 1, key.Requests)
+		assert.NotNil(t, key.LastUsedAt)
+
+		w = adminRequest(mux, http.MethodGet, "/admin/keys", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "secret")
+
+		var keys []models.APIKey
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&keys))
+		assert.Len(t, keys, 2)
+	})
+
+	t.Run("revokes keys", func(t *testing.T) {
+		w := adminRequest(mux, http.MethodDelete, "/admin/keys/"+admin.ID, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"revoked_at"`)
+
+		assert.Equal(t, http.StatusUnauthorized, withKey(http.MethodGet, "/admin/keys", admin.Secret).Code)
+		assert.Equal(t, http.StatusNotFound, adminRequest(mux, http.MethodDelete, "/admin/keys/missing", "").Code)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		assert.Equal(t, http.StatusMethodNotAllowed, adminRequest(mux, http.MethodDelete, "/admin/keys", "").Code)
+		assert.Equal(t, http.StatusMethodNotAllowed, adminRequest(mux, http.MethodPut, "/admin/keys/"+member.ID, "").Code)
+	})
+}
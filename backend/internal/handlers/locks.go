@@ -0,0 +1,93 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/terrascope/core/internal/parser"
+	"github.com/terrascope/core/internal/store"
+)
+
+type LockRequest struct {
+	Lineage  string          `json:"lineage"`
+	LockInfo json.RawMessage `json:"lock_info"`
+}
+
+// stateLocks holds the current lock of each state lineage. ParseHandler
+// attaches it to the graphs of that lineage.
+var stateLocks = stateLocksFromEnv()
+
+func stateLocksFromEnv() store.LockBackend {
+	if shared != nil {
+		return store.NewSharedLocks(shared)
+	}
+
+	return store.NewLockStore()
+}
+
+// LocksHandler records (POST), returns (GET) and releases (DELETE) the lock
+// held on a state lineage. POST takes the lock info Terraform writes next to
+// a locked state or the DynamoDB lock item of the S3 backend.
+func LocksHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		recordLock(w, r)
+	case http.MethodGet, http.MethodDelete:
+		lineage := r.URL.Query().Get("lineage")
+		if lineage == "" {
+			http.Error(w, "Missing lineage", http.StatusBadRequest)
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			if !stateLocks.Delete(lineage) {
+				http.Error(w, "No lock for lineage: "+lineage, http.StatusNotFound)
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		lock, ok := stateLocks.Get(lineage)
+		if !ok {
+			http.Error(w, "No lock for lineage: "+lineage, http.StatusNotFound)
+			return
+		}
+
+		writeJSON(w, r, lock)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func recordLock(w http.ResponseWriter, r *http.Request) {
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	var req LockRequest
+	if err := json.Unmarshal(body.Bytes(), &req); err != nil {
+		http.Error(w, "Invalid lock request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Lineage == "" {
+		http.Error(w, "Invalid lock request: missing lineage", http.StatusBadRequest)
+		return
+	}
+
+	lock, err := parser.ParseLockInfo(req.LockInfo)
+	if err != nil {
+		http.Error(w, "Invalid lock info: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stateLocks.Put(req.Lineage, *lock)
+
+	writeJSON(w, r, lock)
+}
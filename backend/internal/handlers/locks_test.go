@@ -0,0 +1,101 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+func TestLocksHandler(t *testing.T) {
+	previous := stateLocks
+	stateLocks = store.NewLockStore()
+	t.Cleanup(func() { stateLocks = previous })
+
+	lockRequest := `{
+		"lineage": "locked-lineage",
+		"lock_info": {
+			"ID": "4f1c0a0e",
+			"Operation": "OperationTypeApply",
+			"Who": "runner@ci-runner-42",
+			"Version": "1.5.7",
+			"Created": "2024-03-05T10:15:30Z",
+			"Path": "bucket/prod/terraform.tfstate"
+		}
+	}`
+
+	request := func(method, url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		LocksHandler(w, httptest.NewRequest(method, url, strings.NewReader(body)))
+
+		return w
+	}
+
+	t.Run("records and returns a lock", func(t *testing.T) {
+		w := request(http.MethodPost, "/locks", lockRequest)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = request(http.MethodGet, "/locks?lineage=locked-lineage", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var lock models.LockInfo
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&lock))
+		assert.Equal(t, "runner@ci-runner-42", lock.Who)
+		assert.Equal(t, "OperationTypeApply", lock.Operation)
+	})
+
+	t.Run("parse attaches the lock to graphs of the lineage", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, request(http.MethodPost, "/locks", lockRequest).Code)
+
+		tfstate := `{"version": 4, "terraform_version": "1.5.0", "serial": 3, "lineage": "locked-lineage", "resources": []}`
+		w := httptest.NewRecorder()
+		ParseHandler(w, httptest.NewRequest(http.MethodPost, "/parse", strings.NewReader(tfstate)))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var graph models.Graph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+		require.NotNil(t, graph.Lock)
+		assert.Equal(t, "runner@ci-runner-42", graph.Lock.Who)
+	})
+
+	t.Run("delete releases the lock", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, request(http.MethodPost, "/locks", lockRequest).Code)
+
+		assert.Equal(t, http.StatusNoContent, request(http.MethodDelete, "/locks?lineage=locked-lineage", "").Code)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodGet, "/locks?lineage=locked-lineage", "").Code)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodDelete, "/locks?lineage=locked-lineage", "").Code)
+	})
+
+	tests := []struct {
+		name     string
+		method   string
+		url      string
+		body     string
+		status   int
+		contains string
+	}{
+		{"invalid request", http.MethodPost, "/locks", "{", http.StatusBadRequest, "Invalid lock request"},
+		{"missing lineage", http.MethodPost, "/locks", `{"lock_info": {"ID": "a"}}`, http.StatusBadRequest, "Invalid lock request: missing lineage"},
+		{"invalid lock info", http.MethodPost, "/locks", `{"lineage": "l", "lock_info": {"Who": "me"}}`, http.StatusBadRequest, "Invalid lock info: missing lock ID"},
+		{"get without lineage", http.MethodGet, "/locks", "", http.StatusBadRequest, "Missing lineage"},
+		{"unknown lineage", http.MethodGet, "/locks?lineage=other", "", http.StatusNotFound, "No lock for lineage: other"},
+		{"method not allowed", http.MethodPut, "/locks", "", http.StatusMethodNotAllowed, "Method not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := request(tt.method, tt.url, tt.body)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.contains)
+		})
+	}
+}
@@ -0,0 +1,222 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/terrascope/core/internal/analysis"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+	"github.com/terrascope/core/internal/store"
+)
+
+// prometheusContentType is the content type of the Prometheus text exposition
+// format.
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// InfrastructureMetricsHandler reports how the stored lineages grew over their
+// versions, or only the lineage named by ?lineage=. With ?format=prometheus it
+// exposes the latest version of each lineage as gauges for scraping.
+func InfrastructureMetricsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "prometheus" {
+		http.Error(w, "Unknown format: "+format, http.StatusBadRequest)
+		return
+	}
+
+	var lineages []string
+	if lineage := r.URL.Query().Get("lineage"); lineage != "" {
+		if _, err := storedStates.Versions(lineage); errors.Is(err, store.ErrNotFound) {
+			http.Error(w, "Lineage not found: "+lineage, http.StatusNotFound)
+			return
+		} else if err != nil {
+			storageFailed(w, err)
+			return
+		}
+		lineages = []string{lineage}
+	} else {
+		histories, err := storedStates.Lineages()
+		if err != nil {
+			storageFailed(w, err)
+			return
+		}
+		for _, history := range histories {
+			lineages = append(lineages, history.Lineage)
+		}
+	}
+
+	latestOnly := format == "prometheus"
+
+	series := make([]models.InfrastructureSeries, 0, len(lineages))
+	for _, lineage := range lineages {
+		series = append(series, infrastructureSeries(lineage, latestOnly))
+	}
+
+	if !latestOnly {
+		writeJSON(w, r, series)
+		return
+	}
+
+	w.Header().Set("Content-Type", prometheusContentType)
+
+	if _, err := w.Write(prometheusMetrics(series)); err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
+
+// infrastructureSeries measures the stored versions of a lineage that are not
+// archived, or only its latest version when latestOnly is set.
+func infrastructureSeries(lineage string, latestOnly bool) models.InfrastructureSeries {
+	series := models.InfrastructureSeries{Lineage: lineage, Points: []models.InfrastructurePoint{}}
+
+	versions, _ := storedStates.Versions(lineage)
+	if latestOnly && len(versions) > 0 {
+		versions = versions[len(versions)-1:]
+	}
+
+	for _, version := range versions {
+		if version.Archived {
+			continue
+		}
+
+		_, data, err := storedStates.Get(lineage, version.Serial)
+		if err != nil {
+			log.Printf("Skipping stored state %s/%d: %v", lineage, version.Serial, err)
+			continue
+		}
+
+		state, err := parser.ParseTfstate(data)
+		if err != nil {
+			log.Printf("Skipping stored state %s/%d: %v", lineage, version.Serial, err)
+			continue
+		}
+
+		point := analysis.Measure(state)
+		point.StoredAt = version.StoredAt
+		series.Points = append(series.Points, point)
+	}
+
+	return series
+}
+
+// prometheusGauge is a metric exposed by InfrastructureMetricsHandler; sample
+// writes its samples for the latest point of a lineage.
+type prometheusGauge struct {
+	name   string
+	help   string
+	sample func(b *bytes.Buffer, name, lineage string, point models.InfrastructurePoint)
+}
+
+var infrastructureGauges = []prometheusGauge{
+	{
+		name: "terrascope_state_serial",
+		help: "Serial of the latest stored state version.",
+		sample: func(b *bytes.Buffer, name, lineage string, point models.InfrastructurePoint) {
+			writeSample(b, name, float64(point.Serial), "lineage", lineage)
+		},
+	},
+	{
+		name: "terrascope_resources",
+		help: "Managed resource instances in the latest stored state version.",
+		sample: func(b *bytes.Buffer, name, lineage string, point models.InfrastructurePoint) {
+			writeSample(b, name, float64(point.Resources), "lineage", lineage)
+		},
+	},
+	{
+		name: "terrascope_resources_by_type",
+		help: "Managed resource instances by resource type.",
+		sample: func(b *bytes.Buffer, name, lineage string, point models.InfrastructurePoint) {
+			for _, resType := range sortedCountKeys(point.ByType) {
+				writeSample(b, name, float64(point.ByType[resType]), "lineage", lineage, "type", resType)
+			}
+		},
+	},
+	{
+		name: "terrascope_resources_by_provider",
+		help: "Managed resource instances by provider.",
+		sample: func(b *bytes.Buffer, name, lineage string, point models.InfrastructurePoint) {
+			for _, provider := range sortedCountKeys(point.ByProvider) {
+				writeSample(b, name, float64(point.ByProvider[provider]), "lineage", lineage, "provider", provider)
+			}
+		},
+	},
+	{
+		name: "terrascope_monthly_cost_estimate_usd",
+		help: "Rough monthly on-demand cost of the priced resources, in USD.",
+		sample: func(b *bytes.Buffer, name, lineage string, point models.InfrastructurePoint) {
+			writeSample(b, name, point.Cost.MonthlyUSD, "lineage", lineage)
+		},
+	},
+	{
+		name: "terrascope_unpriced_resources",
+		help: "Managed resource instances without a known price.",
+		sample: func(b *bytes.Buffer, name, lineage string, point models.InfrastructurePoint) {
+			writeSample(b, name, float64(point.Cost.Unpriced), "lineage", lineage)
+		},
+	},
+}
+
+// prometheusMetrics renders the last point of each series as gauges in the
+// Prometheus text exposition format.
+func prometheusMetrics(series []models.InfrastructureSeries) []byte {
+	var b bytes.Buffer
+
+	for _, gauge := range infrastructureGauges {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", gauge.name, gauge.help, gauge.name)
+
+		for _, s := range series {
+			if len(s.Points) == 0 {
+				continue
+			}
+			gauge.sample(&b, gauge.name, s.Lineage, s.Points[len(s.Points)-1])
+		}
+	}
+
+	return b.Bytes()
+}
+
+// writeSample writes one sample line with the given label name/value pairs.
+func writeSample(b *bytes.Buffer, name string, value float64, labels ...string) {
+	b.WriteString(name)
+	b.WriteByte('{')
+
+	for i := 0; i+1 < len(labels); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(labels[i])
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(labels[i+1]))
+		b.WriteByte('"')
+	}
+
+	b.WriteString("} ")
+	b.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	b.WriteByte('\n')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func sortedCountKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
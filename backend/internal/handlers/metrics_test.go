@@ -0,0 +1,96 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+func TestInfrastructureMetricsHandler(t *testing.T) {
+	previous := storedStates
+	storedStates = store.NewStateStore(time.Hour)
+	t.Cleanup(func() { storedStates = previous })
+
+	tfstate := func(serial int, instanceTypes ...string) []byte {
+		resources := []map[string]any{}
+		for i, instanceType := range instanceTypes {
+			resources = append(resources, map[string]any{
+				"mode":     "managed",
+				"type":     "aws_instance",
+				"name":     "web" + string(rune('a'+i)),
+				"provider": `provider["registry.terraform.io/hashicorp/aws"]`,
+				"instances": []map[string]any{
+					{"attributes": map[string]any{"instance_type": instanceType}},
+				},
+			})
+		}
+
+		data, err := json.Marshal(map[string]any{
+			"version":           4,
+			"terraform_version": "1.5.0",
+			"serial":            serial,
+			"lineage":           `prod "eu"`,
+			"resources":         resources,
+		})
+		require.NoError(t, err)
+
+		return data
+	}
+
+	for serial, data := range map[int][]byte{1: tfstate(1, "t3.micro"), 2: tfstate(2, "t3.micro", "m5.large")} {
+		storedStates.Put(models.StateVersion{Lineage: `prod "eu"`, Serial: serial}, data)
+	}
+
+	request := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		InfrastructureMetricsHandler(w, httptest.NewRequest(http.MethodGet, url, nil))
+
+		return w
+	}
+
+	t.Run("JSON series", func(t *testing.T) {
+		w := request("/metrics/infrastructure")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var series []models.InfrastructureSeries
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&series))
+		require.Len(t, series, 1)
+		require.Len(t, series[0].Points, 2)
+
+		assert.Equal(t, 1, series[0].Points[0].Resources)
+		assert.Equal(t, 2, series[0].Points[1].Resources)
+		assert.Equal(t, map[string]int{"aws": 2}, series[0].Points[1].ByProvider)
+		assert.InDelta(t, 77.67, series[0].Points[1].Cost.MonthlyUSD, 0.001)
+	})
+
+	t.Run("Prometheus gauges", func(t *testing.T) {
+		w := request("/metrics/infrastructure?format=prometheus")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, prometheusContentType, w.Header().Get("Content-Type"))
+
+		body := w.Body.String()
+		assert.Contains(t, body, "# TYPE terrascope_resources gauge\n")
+		assert.Contains(t, body, `terrascope_resources{lineage="prod \"eu\""} 2`+"\n")
+		assert.Contains(t, body, `terrascope_resources_by_type{lineage="prod \"eu\"",type="aws_instance"} 2`+"\n")
+		assert.Contains(t, body, `terrascope_state_serial{lineage="prod \"eu\""} 2`+"\n")
+		assert.Equal(t, 1, strings.Count(body, "terrascope_resources{"))
+	})
+
+	t.Run("unknown lineage", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, request("/metrics/infrastructure?lineage=staging").Code)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, request("/metrics/infrastructure?format=xml").Code)
+	})
+}
@@ -4,47 +4,335 @@ package handlers
 
 import (
 	"encoding/json"
-	"io"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"os"
+	"strconv"
 
+	"github.com/terrascope/core/internal/cache"
+	"github.com/terrascope/core/internal/models"
 	"github.com/terrascope/core/internal/parser"
+	"github.com/terrascope/core/internal/store"
 )
 
+// defaultMaxNodes is the graph size above which ParseHandler returns a
+// summarized graph unless TERRASCOPE_MAX_NODES or ?max_nodes= say otherwise.
+const defaultMaxNodes = 20000
+
+// maxGraphNodes is the server-wide summarization threshold; 0 disables it.
+var maxGraphNodes = maxNodesFromEnv()
+
+func maxNodesFromEnv() int {
+	value := os.Getenv("TERRASCOPE_MAX_NODES")
+	if value == "" {
+		return defaultMaxNodes
+	}
+
+	maxNodes, err := strconv.Atoi(value)
+	if err != nil || maxNodes < 0 {
+		log.Printf("Ignoring TERRASCOPE_MAX_NODES: invalid value %q", value)
+		return defaultMaxNodes
+	}
+
+	return maxNodes
+}
+
+// defaultReduceDepth is the longest path ?reduce=transitive looks for when
+// there is no ?reduce_depth=, which keeps the reduction fast on large graphs.
+const defaultReduceDepth = 8
+
+// deltaCacheLineages bounds how many state lineages ParseHandler keeps a
+// previous parse for.
+const deltaCacheLineages = 32
+
+// deltaCache lets ParseHandler reparse only the resources that changed when
+// CI uploads successive serials of the same state.
+var deltaCache = parser.NewDeltaCache(deltaCacheLineages)
+
+// defaultIDFormat addresses graph nodes when a request has no ?id_format=.
+// TERRASCOPE_ID_FORMAT=terraform switches the default to Terraform addresses.
+var defaultIDFormat = idFormatFromEnv()
+
+func idFormatFromEnv() parser.IDFormat {
+	name := os.Getenv("TERRASCOPE_ID_FORMAT")
+	if name == "" {
+		return parser.IDFormatLegacy
+	}
+
+	format, err := parser.ParseIDFormat(name)
+	if err != nil {
+		log.Printf("Ignoring TERRASCOPE_ID_FORMAT: %v", err)
+		return parser.IDFormatLegacy
+	}
+
+	return format
+}
+
+// graphOptions reads the graph building options from the query string. On
+// failure it writes a 400 response, or a 404 response for unknown ?schemas=,
+// and returns false.
+func graphOptions(w http.ResponseWriter, r *http.Request) (parser.GraphOptions, bool) {
+	opts := parser.GraphOptions{IDFormat: defaultIDFormat}
+
+	if name := r.URL.Query().Get("id_format"); name != "" {
+		format, err := parser.ParseIDFormat(name)
+		if err != nil {
+			http.Error(w, "Unknown id_format: "+name, http.StatusBadRequest)
+			return opts, false
+		}
+		opts.IDFormat = format
+	}
+
+	opts.InstanceEdges = r.URL.Query().Get("instance_edges") == "true"
+	opts.FoldInstances = r.URL.Query().Get("expand_instances") == "false"
+	opts.InferReferences = r.URL.Query().Get("references") == "true"
+
+	if digest := r.URL.Query().Get("schemas"); digest != "" {
+		schemas, ok := storedSchemas.Get(digest)
+		if !ok {
+			http.Error(w, "Provider schemas not found: "+digest, http.StatusNotFound)
+			return opts, false
+		}
+		opts.Schemas = schemas
+	}
+
+	return opts, true
+}
+
 func ParseHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		http.Error(w, "Failed to read body", http.StatusBadRequest)
+	opts, ok := graphOptions(w, r)
+	if !ok {
 		return
 	}
 
-	defer func() {
-		if err := r.Body.Close(); err != nil {
-			log.Printf("failed to close request body: %v", err)
+	compiled, ok := graphDecorators(w, r)
+	if !ok {
+		return
+	}
+
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	entry, ok := cachedGraph(r, body.Bytes(), opts)
+	if ok {
+		w.Header().Set(CacheHeader, "hit")
+	} else if entry, ok = buildGraph(w, r, body.Bytes(), opts); !ok {
+		return
+	}
+
+	// Decorators run on every request, after the cache, as their scripts can
+	// be replaced at any time.
+	graph, ok := applyDecorators(w, r, entry.Graph, compiled)
+	if !ok {
+		return
+	}
+
+	maxNodes := maxGraphNodes
+	if value := r.URL.Query().Get("max_nodes"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			http.Error(w, "Invalid max_nodes: "+value, http.StatusBadRequest)
+			return
 		}
-	}()
+		maxNodes = n
+	}
+
+	summarizeBy := r.URL.Query().Get("summarize_by")
+	switch summarizeBy {
+	case "":
+		summarizeBy = parser.SummarizeByModule
+	case parser.SummarizeByModule, parser.SummarizeByType:
+	default:
+		http.Error(w, "Unknown summarize_by: "+summarizeBy, http.StatusBadRequest)
+		return
+	}
+
+	reduceDepth, reduce, ok := graphReduction(w, r)
+	if !ok {
+		return
+	}
 
-	state, err := parser.ParseTfstate(body)
+	if entry.Lineage != "" {
+		graph.Lock, _ = stateLocks.Get(entry.Lineage)
+	}
+
+	graph.Stats = parser.ComputeStats(graph)
+	storedGraphs.Put(graph.Stats.Digest, graph)
+	digest, lock := graph.Stats.Digest, graph.Lock
+
+	// Degrees are counted on the full graph, before it is reduced or
+	// summarized, so summary nodes carry none.
+	var degrees map[string]models.NodeDegrees
+	if r.URL.Query().Get("with_degrees") == "true" {
+		degrees = parser.ComputeDegrees(graph)
+	}
+
+	if reduce {
+		graph = parser.ReduceTransitive(graph, reduceDepth)
+		graph.Reduction.Digest = digest
+		graph.Stats = parser.ComputeStats(graph)
+	}
+
+	if maxNodes > 0 && len(graph.Nodes) > maxNodes {
+		graph = parser.Summarize(graph, summarizeBy, maxNodes)
+		graph.Summary.Digest = digest
+		graph.Lock = lock
+		graph.Stats = parser.ComputeStats(graph)
+	}
+
+	if degrees != nil {
+		graph = parser.WithDegrees(graph, degrees)
+	}
+
+	writeGraph(w, r, graph)
+}
+
+// graphReduction reads ?reduce= and ?reduce_depth=, returning the longest
+// path to look for and whether the graph is to be reduced. On failure it
+// writes a 400 response and returns false.
+func graphReduction(w http.ResponseWriter, r *http.Request) (depth int, reduce, ok bool) {
+	switch name := r.URL.Query().Get("reduce"); name {
+	case "":
+		return 0, false, true
+	case parser.ReduceByTransitive:
+	default:
+		http.Error(w, "Unknown reduce: "+name, http.StatusBadRequest)
+		return 0, false, false
+	}
+
+	value := r.URL.Query().Get("reduce_depth")
+	if value == "" {
+		return defaultReduceDepth, true, true
+	}
+
+	depth, err := strconv.Atoi(value)
+	if err != nil || depth < 0 {
+		http.Error(w, "Invalid reduce_depth: "+value, http.StatusBadRequest)
+		return 0, false, false
+	}
+
+	return depth, true, true
+}
+
+// graphEntry is a graph built by ParseHandler, before the lock of its lineage
+// is added and it is summarized, as kept in the results cache.
+type graphEntry struct {
+	Lineage string        `json:"lineage"`
+	Graph   *models.Graph `json:"graph"`
+}
+
+// graphKey is the results cache key of the graph of the state for the
+// request: its view, module and sort, and the graph building options.
+func graphKey(r *http.Request, data []byte, opts parser.GraphOptions) string {
+	query := url.Values{"id_format": {string(opts.IDFormat)}}
+	for _, name := range []string{"instance_edges", "expand_instances", "references", "schemas", "view", "module", "sort"} {
+		if value := r.URL.Query().Get(name); value != "" {
+			query.Set(name, value)
+		}
+	}
+
+	return cache.Key("graph", []byte(query.Encode()), data)
+}
+
+// cachedGraph returns the graph of the state for the request from the graph
+// cache or else the results cache.
+func cachedGraph(r *http.Request, data []byte, opts parser.GraphOptions) (*graphEntry, bool) {
+	key := graphKey(r, data, opts)
+
+	if parsed, ok := graphCache.Get(key); ok {
+		// The graph is shared with the cache: ParseHandler sets its lock and
+		// stats on a copy.
+		graph := *parsed.Graph
+		return &graphEntry{Lineage: parsed.State.Lineage, Graph: &graph}, true
+	}
+
+	if results == nil {
+		return nil, false
+	}
+
+	value, ok := cachedResult(r, key)
+	if !ok {
+		return nil, false
+	}
+
+	var entry graphEntry
+	if err := json.Unmarshal(value, &entry); err != nil || entry.Graph == nil {
+		log.Printf("Ignoring cached graph: %v", err)
+		return nil, false
+	}
+
+	return &entry, true
+}
+
+// buildGraph parses the state and builds its graph for the request, which it
+// caches. On failure it writes a 400 response and returns false.
+func buildGraph(w http.ResponseWriter, r *http.Request, data []byte, opts parser.GraphOptions) (*graphEntry, bool) {
+	parsed, err := deltaCache.Parse(data, opts)
 	if err != nil {
 		http.Error(w, "Invalid tfstate: "+err.Error(), http.StatusBadRequest)
-		return
+		return nil, false
+	}
+
+	state := parsed.State
+	w.Header().Set("X-Delta-Reused", fmt.Sprintf("%d/%d", parsed.Reused, parsed.Reused+parsed.Parsed))
+
+	var graph *models.Graph
+
+	switch view := r.URL.Query().Get("view"); view {
+	case "", "dependencies":
+		graph = parsed.Graph
+	case "network":
+		graph = parser.BuildNetworkGraphWithOptions(state, opts)
+	case "security_groups":
+		graph = parser.BuildSecurityGroupGraphWithOptions(state, opts)
+	default:
+		http.Error(w, "Unknown view: "+view, http.StatusBadRequest)
+		return nil, false
 	}
 
-	graph := parser.BuildGraph(state)
+	if module := r.URL.Query().Get("module"); module != "" {
+		graph = parser.ModuleSubgraph(graph, module)
+	}
+
+	switch order := r.URL.Query().Get("sort"); order {
+	case "":
+	case "id":
+		parser.SortGraph(graph)
+	default:
+		http.Error(w, "Unknown sort: "+order, http.StatusBadRequest)
+		return nil, false
+	}
 
-	w.Header().Set("Content-Type", "application/json")
+	key := graphKey(r, data, opts)
+	graphCache.Put(key, store.ParsedGraph{State: state, Graph: graph}, int64(len(data)))
 
-	encoder := json.NewEncoder(w)
-	if r.URL.Query().Get("pretty") == "true" {
-		encoder.SetIndent("", "  ")
+	// ParseHandler sets the lock and stats of the graph, which the graph
+	// cache now shares, on a copy.
+	copied := *graph
+	entry := &graphEntry{Lineage: state.Lineage, Graph: &copied}
+
+	if results != nil || graphCache.Enabled() {
+		w.Header().Set(CacheHeader, "miss")
 	}
 
-	if err := encoder.Encode(graph); err != nil {
-		log.Printf("Error encoding response: %v", err)
+	if results != nil {
+		value, err := json.Marshal(entry)
+		if err != nil {
+			log.Printf("Caching graph: %v", err)
+		} else {
+			cacheResult(r, key, value)
+		}
 	}
+
+	return entry, true
 }
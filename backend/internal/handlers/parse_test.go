@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
 )
 
 func TestParseHandler(t *testing.T) {
@@ -452,3 +453,772 @@ func TestParseHandler(t *testing.T) {
 		assert.Equal(t, "data", graph.Nodes[0].Mode)
 	})
 }
+
+func TestParseHandlerViews(t *testing.T) {
+	networkTfstate := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"serial": 1,
+		"lineage": "abc-123",
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_vpc",
+				"name": "main",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "vpc-1", "cidr_block": "10.0.0.0/16"}}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_subnet",
+				"name": "public",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "subnet-1", "vpc_id": "vpc-1"}}]
+			}
+		]
+	}`
+
+	t.Run("network view returns topology edges", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/parse?view=network", strings.NewReader(networkTfstate))
+		w := httptest.NewRecorder()
+
+		ParseHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var graph models.Graph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+
+		assert.Len(t, graph.Nodes, 2)
+		require.Len(t, graph.Edges, 1)
+		assert.Equal(t, "aws_vpc.main", graph.Edges[0].Source)
+		assert.Equal(t, "aws_subnet.public", graph.Edges[0].Target)
+		assert.Equal(t, "contains", graph.Edges[0].Type)
+	})
+
+	t.Run("dependencies view is the default", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/parse?view=dependencies", strings.NewReader(networkTfstate))
+		w := httptest.NewRecorder()
+
+		ParseHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var graph models.Graph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+
+		assert.Len(t, graph.Nodes, 2)
+		assert.Empty(t, graph.Edges)
+	})
+
+	t.Run("security groups view returns rule edges", func(t *testing.T) {
+		sgTfstate := `{
+			"version": 4,
+			"terraform_version": "1.5.0",
+			"serial": 1,
+			"lineage": "abc-123",
+			"resources": [
+				{
+					"mode": "managed",
+					"type": "aws_security_group",
+					"name": "web",
+					"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+					"instances": [{"attributes": {
+						"id": "sg-1",
+						"ingress": [{"protocol": "tcp", "from_port": 443, "to_port": 443, "cidr_blocks": ["0.0.0.0/0"]}]
+					}}]
+				}
+			]
+		}`
+
+		req := httptest.NewRequest(http.MethodPost, "/parse?view=security_groups", strings.NewReader(sgTfstate))
+		w := httptest.NewRecorder()
+
+		ParseHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var graph models.Graph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+
+		assert.Len(t, graph.Nodes, 2)
+		require.Len(t, graph.Edges, 1)
+		assert.Equal(t, "0.0.0.0/0", graph.Edges[0].Source)
+		assert.Equal(t, "aws_security_group.web", graph.Edges[0].Target)
+		assert.Equal(t, "443", graph.Edges[0].Metadata["ports"])
+	})
+
+	t.Run("returns 400 for unknown view", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/parse?view=galaxy", strings.NewReader(networkTfstate))
+		w := httptest.NewRecorder()
+
+		ParseHandler(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Unknown view")
+	})
+}
+
+func TestParseHandlerSort(t *testing.T) {
+	tfstate := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"serial": 1,
+		"lineage": "abc-123",
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_subnet",
+				"name": "b",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"depends_on": ["aws_vpc.main", "aws_iam_role.flow"],
+				"instances": [{"attributes": {"id": "subnet-b"}, "dependencies": ["aws_route_table.main"]}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_subnet",
+				"name": "a",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "subnet-a"}}]
+			}
+		]
+	}`
+
+	t.Run("sort=id orders nodes and edges", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/parse?sort=id", strings.NewReader(tfstate))
+		w := httptest.NewRecorder()
+
+		ParseHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var graph models.Graph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+
+		require.Len(t, graph.Nodes, 2)
+		assert.Equal(t, "aws_subnet.a", graph.Nodes[0].ID)
+		assert.Equal(t, "aws_subnet.b", graph.Nodes[1].ID)
+
+		require.Len(t, graph.Edges, 3)
+		assert.Equal(t, "aws_iam_role.flow", graph.Edges[0].Target)
+		assert.Equal(t, "aws_route_table.main", graph.Edges[1].Target)
+		assert.Equal(t, "aws_vpc.main", graph.Edges[2].Target)
+	})
+
+	t.Run("sorted output is byte-identical across requests", func(t *testing.T) {
+		var bodies []string
+		for i := 0; i < 10; i++ {
+			req := httptest.NewRequest(http.MethodPost, "/parse?sort=id", strings.NewReader(tfstate))
+			w := httptest.NewRecorder()
+
+			ParseHandler(w, req)
+
+			bodies = append(bodies, w.Body.String())
+		}
+
+		for _, body := range bodies[1:] {
+			assert.Equal(t, bodies[0], body)
+		}
+	})
+
+	t.Run("returns 400 for unknown sort", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/parse?sort=size", strings.NewReader(tfstate))
+		w := httptest.NewRecorder()
+
+		ParseHandler(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Unknown sort")
+	})
+}
+
+func TestParseHandlerDigest(t *testing.T) {
+	tfstate := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"serial": 1,
+		"lineage": "abc-123",
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_s3_bucket",
+				"name": "assets",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "assets"}}]
+			}
+		]
+	}`
+
+	t.Run("returns stats with digest and header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/parse", strings.NewReader(tfstate))
+		w := httptest.NewRecorder()
+
+		ParseHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var graph models.Graph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+
+		require.NotNil(t, graph.Stats)
+		assert.Equal(t, 1, graph.Stats.TotalNodes)
+		assert.Equal(t, 1, graph.Stats.ResourcesByType["aws_s3_bucket"])
+		assert.True(t, strings.HasPrefix(graph.Stats.Digest, "sha256:"))
+		assert.Equal(t, graph.Stats.Digest, w.Header().Get("X-Graph-Digest"))
+	})
+
+	t.Run("digest does not depend on sort order", func(t *testing.T) {
+		unsorted := httptest.NewRecorder()
+		ParseHandler(unsorted, httptest.NewRequest(http.MethodPost, "/parse", strings.NewReader(tfstate)))
+
+		sorted := httptest.NewRecorder()
+		ParseHandler(sorted, httptest.NewRequest(http.MethodPost, "/parse?sort=id", strings.NewReader(tfstate)))
+
+		assert.Equal(t, unsorted.Header().Get("X-Graph-Digest"), sorted.Header().Get("X-Graph-Digest"))
+	})
+}
+
+func TestParseHandlerFormats(t *testing.T) {
+	tfstate := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"serial": 1,
+		"lineage": "abc-123",
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_vpc",
+				"name": "main",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "vpc-1"}}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_subnet",
+				"name": "a",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "subnet-1"}, "dependencies": ["aws_vpc.main"]}]
+			}
+		]
+	}`
+
+	t.Run("renders svg", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/parse?format=svg", strings.NewReader(tfstate))
+		w := httptest.NewRecorder()
+
+		ParseHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/svg+xml", w.Header().Get("Content-Type"))
+		assert.NotEmpty(t, w.Header().Get("X-Graph-Digest"))
+		assert.True(t, strings.HasPrefix(w.Body.String(), "<svg "))
+		assert.Contains(t, w.Body.String(), "<title>aws_subnet.a</title>")
+	})
+
+	t.Run("renders dot", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/parse?format=dot", strings.NewReader(tfstate))
+		w := httptest.NewRecorder()
+
+		ParseHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"aws_subnet.a" -> "aws_vpc.main"`)
+	})
+
+	t.Run("renders sqlite for download", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/parse?format=sqlite", strings.NewReader(tfstate))
+		w := httptest.NewRecorder()
+
+		ParseHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/vnd.sqlite3", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="graph.sqlite"`, w.Header().Get("Content-Disposition"))
+		assert.True(t, strings.HasPrefix(w.Body.String(), "SQLite format 3\x00"))
+	})
+
+	t.Run("json is the default", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/parse?format=json", strings.NewReader(tfstate))
+		w := httptest.NewRecorder()
+
+		ParseHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("presentation", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/parse?presentation=true&sort=id", strings.NewReader(tfstate))
+		w := httptest.NewRecorder()
+
+		ParseHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var graph models.Graph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+
+		require.Len(t, graph.Nodes, 2)
+		assert.Equal(t, &models.NodePresentation{Label: "a", SecondaryLabel: "subnet-1", Badges: []string{}}, graph.Nodes[0].Presentation)
+	})
+
+	t.Run("no presentation by default", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/parse", strings.NewReader(tfstate))
+		w := httptest.NewRecorder()
+
+		ParseHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "presentation")
+	})
+
+	t.Run("returns 400 for unknown format", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/parse?format=png", strings.NewReader(tfstate))
+		w := httptest.NewRecorder()
+
+		ParseHandler(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Unknown format: png")
+	})
+}
+
+func TestParseHandlerDelta(t *testing.T) {
+	state := func(serial int, bucket string) string {
+		return fmt.Sprintf(`{
+			"version": 4,
+			"terraform_version": "1.5.0",
+			"serial": %d,
+			"lineage": "delta-handler-test",
+			"resources": [
+				{
+					"mode": "managed",
+					"type": "aws_vpc",
+					"name": "main",
+					"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+					"instances": [{"attributes": {"id": "vpc-1"}}]
+				},
+				{
+					"mode": "managed",
+					"type": "aws_s3_bucket",
+					"name": "logs",
+					"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+					"instances": [{"attributes": {"id": %q}}]
+				}
+			]
+		}`, serial, bucket)
+	}
+
+	first := httptest.NewRecorder()
+	ParseHandler(first, httptest.NewRequest(http.MethodPost, "/parse", strings.NewReader(state(1, "logs"))))
+	require.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "0/2", first.Header().Get("X-Delta-Reused"))
+
+	second := httptest.NewRecorder()
+	ParseHandler(second, httptest.NewRequest(http.MethodPost, "/parse", strings.NewReader(state(2, "logs-v2"))))
+	require.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, "1/2", second.Header().Get("X-Delta-Reused"))
+
+	var graph models.Graph
+	require.NoError(t, json.NewDecoder(second.Body).Decode(&graph))
+	require.Len(t, graph.Nodes, 2)
+	assert.Equal(t, "logs-v2", graph.Nodes[1].Metadata["id"])
+}
+
+func TestParseHandlerIDFormat(t *testing.T) {
+	tfstate := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"resources": [
+			{
+				"mode": "data",
+				"type": "aws_ami",
+				"name": "ubuntu",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "ami-1"}}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_subnet",
+				"name": "private",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [
+					{"index_key": "a", "attributes": {"id": "subnet-a"}, "dependencies": ["data.aws_ami.ubuntu"]},
+					{"index_key": "b", "attributes": {"id": "subnet-b"}}
+				]
+			}
+		]
+	}`
+
+	parse := func(t *testing.T, url string) *models.Graph {
+		t.Helper()
+
+		w := httptest.NewRecorder()
+		ParseHandler(w, httptest.NewRequest(http.MethodPost, url, strings.NewReader(tfstate)))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var graph models.Graph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+
+		return &graph
+	}
+
+	ids := func(graph *models.Graph) []string {
+		result := []string{}
+		for _, node := range graph.Nodes {
+			result = append(result, node.ID)
+		}
+
+		return result
+	}
+
+	t.Run("defaults to legacy IDs", func(t *testing.T) {
+		assert.Equal(t, []string{"aws_ami.ubuntu", "aws_subnet.private[a]", "aws_subnet.private[b]"}, ids(parse(t, "/parse")))
+	})
+
+	t.Run("terraform IDs", func(t *testing.T) {
+		graph := parse(t, "/parse?id_format=terraform")
+
+		assert.Equal(t, []string{"data.aws_ami.ubuntu", `aws_subnet.private["a"]`, `aws_subnet.private["b"]`}, ids(graph))
+		require.Len(t, graph.Edges, 1)
+		assert.Equal(t, "data.aws_ami.ubuntu", graph.Edges[0].Target)
+	})
+
+	t.Run("maps legacy IDs to terraform addresses", func(t *testing.T) {
+		graph := parse(t, "/parse")
+
+		assert.Equal(t, models.NodeAddress{Terraform: `aws_subnet.private["b"]`, StatePath: ".resources[1].instances[1]"}, graph.Addresses["aws_subnet.private[b]"])
+		assert.Equal(t, "data.aws_ami.ubuntu", graph.Addresses["aws_ami.ubuntu"].Terraform)
+	})
+
+	t.Run("default can be switched", func(t *testing.T) {
+		previous := defaultIDFormat
+		defaultIDFormat = parser.IDFormatTerraform
+		t.Cleanup(func() { defaultIDFormat = previous })
+
+		assert.Equal(t, "data.aws_ami.ubuntu", ids(parse(t, "/parse"))[0])
+		assert.Equal(t, "aws_ami.ubuntu", ids(parse(t, "/parse?id_format=legacy"))[0])
+	})
+
+	t.Run("applies to other views", func(t *testing.T) {
+		graph := parse(t, "/parse?view=network&id_format=terraform")
+
+		assert.Equal(t, []string{`aws_subnet.private["a"]`, `aws_subnet.private["b"]`}, ids(graph))
+	})
+
+	t.Run("instance edges", func(t *testing.T) {
+		graph := parse(t, "/parse?id_format=terraform&instance_edges=true")
+
+		require.Len(t, graph.Edges, 1)
+		assert.Equal(t, `aws_subnet.private["a"]`, graph.Edges[0].Source)
+		assert.Equal(t, "data.aws_ami.ubuntu", graph.Edges[0].Target)
+		assert.Equal(t, map[string]any{"source_instance": `["a"]`}, graph.Edges[0].Metadata)
+	})
+
+	t.Run("folded instances", func(t *testing.T) {
+		graph := parse(t, "/parse?expand_instances=false")
+
+		assert.Equal(t, []string{"aws_ami.ubuntu", "aws_subnet.private"}, ids(graph))
+		assert.Len(t, graph.Nodes[1].Metadata["instances"], 2)
+		require.Len(t, graph.Edges, 1)
+		assert.Equal(t, "aws_subnet.private", graph.Edges[0].Source)
+	})
+
+	t.Run("unknown id format", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ParseHandler(w, httptest.NewRequest(http.MethodPost, "/parse?id_format=canonical", strings.NewReader(tfstate)))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Unknown id_format: canonical")
+	})
+}
+
+func TestIDFormatFromEnv(t *testing.T) {
+	t.Run("defaults to legacy", func(t *testing.T) {
+		t.Setenv("TERRASCOPE_ID_FORMAT", "")
+		assert.Equal(t, parser.IDFormatLegacy, idFormatFromEnv())
+	})
+
+	t.Run("terraform", func(t *testing.T) {
+		t.Setenv("TERRASCOPE_ID_FORMAT", "terraform")
+		assert.Equal(t, parser.IDFormatTerraform, idFormatFromEnv())
+	})
+
+	t.Run("invalid value falls back to legacy", func(t *testing.T) {
+		t.Setenv("TERRASCOPE_ID_FORMAT", "bogus")
+		assert.Equal(t, parser.IDFormatLegacy, idFormatFromEnv())
+	})
+}
+
+func TestParseHandlerGuardrails(t *testing.T) {
+	tfstate := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_vpc",
+				"name": "main",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "vpc-1"}}]
+			},
+			{
+				"module": "module.app",
+				"mode": "managed",
+				"type": "aws_instance",
+				"name": "web",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [
+					{"index_key": 0, "attributes": {"id": "i-1"}, "dependencies": ["aws_vpc.main"]},
+					{"index_key": 1, "attributes": {"id": "i-2"}, "dependencies": ["aws_vpc.main"]}
+				]
+			}
+		]
+	}`
+
+	parse := func(t *testing.T, url string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		w := httptest.NewRecorder()
+		ParseHandler(w, httptest.NewRequest(http.MethodPost, url, strings.NewReader(tfstate)))
+
+		return w
+	}
+
+	decode := func(t *testing.T, w *httptest.ResponseRecorder) *models.Graph {
+		t.Helper()
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var graph models.Graph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+
+		return &graph
+	}
+
+	t.Run("small graphs are not summarized", func(t *testing.T) {
+		graph := decode(t, parse(t, "/parse"))
+
+		assert.False(t, graph.Summarized)
+		assert.Nil(t, graph.Summary)
+		assert.Len(t, graph.Nodes, 3)
+	})
+
+	t.Run("graphs above max_nodes are summarized", func(t *testing.T) {
+		graph := decode(t, parse(t, "/parse?max_nodes=2"))
+
+		assert.True(t, graph.Summarized)
+		require.NotNil(t, graph.Summary)
+		assert.Equal(t, 3, graph.Summary.TotalNodes)
+		require.Len(t, graph.Nodes, 2)
+		assert.Equal(t, "module.app", graph.Nodes[0].ID)
+		assert.Equal(t, 2, graph.Stats.TotalNodes)
+	})
+
+	t.Run("server threshold applies by default", func(t *testing.T) {
+		previous := maxGraphNodes
+		maxGraphNodes = 1
+		t.Cleanup(func() { maxGraphNodes = previous })
+
+		assert.True(t, decode(t, parse(t, "/parse")).Summarized)
+		assert.False(t, decode(t, parse(t, "/parse?max_nodes=0")).Summarized)
+	})
+
+	t.Run("summarize by type", func(t *testing.T) {
+		graph := decode(t, parse(t, "/parse?max_nodes=1&summarize_by=type"))
+
+		require.Len(t, graph.Nodes, 2)
+		assert.Equal(t, "aws_instance", graph.Nodes[0].ID)
+	})
+
+	t.Run("module subgraph", func(t *testing.T) {
+		graph := decode(t, parse(t, "/parse?module=module.app&sort=id"))
+
+		require.Len(t, graph.Nodes, 2)
+		assert.Equal(t, "module.app.aws_instance.web[0]", graph.Nodes[0].ID)
+		assert.Empty(t, graph.Edges)
+	})
+
+	t.Run("invalid max_nodes", func(t *testing.T) {
+		w := parse(t, "/parse?max_nodes=lots")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid max_nodes: lots")
+	})
+
+	t.Run("unknown summarize_by", func(t *testing.T) {
+		w := parse(t, "/parse?summarize_by=provider")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Unknown summarize_by: provider")
+	})
+}
+
+func TestParseHandlerReduce(t *testing.T) {
+	tfstate := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_vpc",
+				"name": "main",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "vpc-1"}}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_subnet",
+				"name": "a",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "subnet-1"}, "dependencies": ["aws_vpc.main"]}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_instance",
+				"name": "web",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "i-1"}, "dependencies": ["aws_subnet.a", "aws_vpc.main"]}]
+			}
+		]
+	}`
+
+	parse := func(t *testing.T, url string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		w := httptest.NewRecorder()
+		ParseHandler(w, httptest.NewRequest(http.MethodPost, url, strings.NewReader(tfstate)))
+
+		return w
+	}
+
+	full := parse(t, "/parse")
+	require.Equal(t, http.StatusOK, full.Code)
+
+	t.Run("drops implied edges", func(t *testing.T) {
+		w := parse(t, "/parse?reduce=transitive&sort=id")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var graph models.Graph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+
+		assert.Len(t, graph.Edges, 2)
+		assert.Equal(t, 2, graph.Stats.TotalEdges)
+		require.NotNil(t, graph.Reduction)
+		assert.Equal(t, &models.Reduction{
+			By:           "transitive",
+			Depth:        defaultReduceDepth,
+			RemovedEdges: 1,
+			Digest:       full.Header().Get("X-Graph-Digest"),
+		}, graph.Reduction)
+	})
+
+	t.Run("full graph is kept for subgraphs", func(t *testing.T) {
+		graph, ok := storedGraphs.Get(full.Header().Get("X-Graph-Digest"))
+		require.True(t, ok)
+
+		assert.Len(t, graph.Edges, 3)
+		assert.Nil(t, graph.Reduction)
+	})
+
+	t.Run("depth too short for the longer path", func(t *testing.T) {
+		w := parse(t, "/parse?reduce=transitive&reduce_depth=1")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var graph models.Graph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+
+		assert.Len(t, graph.Edges, 3)
+		assert.Equal(t, 0, graph.Reduction.RemovedEdges)
+	})
+
+	t.Run("unknown reduce", func(t *testing.T) {
+		w := parse(t, "/parse?reduce=all")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Unknown reduce: all")
+	})
+
+	t.Run("invalid reduce_depth", func(t *testing.T) {
+		w := parse(t, "/parse?reduce=transitive&reduce_depth=deep")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid reduce_depth: deep")
+	})
+}
+
+func TestParseHandlerDegrees(t *testing.T) {
+	tfstate := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_vpc",
+				"name": "main",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "vpc-1"}}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_subnet",
+				"name": "a",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "subnet-1"}, "dependencies": ["aws_vpc.main"]}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_instance",
+				"name": "web",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "i-1"}, "dependencies": ["aws_subnet.a", "aws_vpc.main"]}]
+			}
+		]
+	}`
+
+	parse := func(t *testing.T, url string) map[string]*models.NodeDegrees {
+		t.Helper()
+
+		w := httptest.NewRecorder()
+		ParseHandler(w, httptest.NewRequest(http.MethodPost, url, strings.NewReader(tfstate)))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var graph models.Graph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+
+		degrees := make(map[string]*models.NodeDegrees)
+		for _, node := range graph.Nodes {
+			degrees[node.ID] = node.Degrees
+		}
+
+		return degrees
+	}
+
+	t.Run("absent by default", func(t *testing.T) {
+		assert.Nil(t, parse(t, "/parse")["aws_vpc.main"])
+	})
+
+	t.Run("counted on the full graph", func(t *testing.T) {
+		degrees := parse(t, "/parse?with_degrees=true&reduce=transitive")
+
+		assert.Equal(t, &models.NodeDegrees{InDegree: 2, TransitiveDependentsCount: 2}, degrees["aws_vpc.main"])
+		assert.Equal(t, &models.NodeDegrees{InDegree: 1, OutDegree: 1, TransitiveDependentsCount: 1}, degrees["aws_subnet.a"])
+		assert.Equal(t, &models.NodeDegrees{OutDegree: 2}, degrees["aws_instance.web"])
+	})
+}
+
+func TestMaxNodesFromEnv(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		t.Setenv("TERRASCOPE_MAX_NODES", "")
+		assert.Equal(t, defaultMaxNodes, maxNodesFromEnv())
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		t.Setenv("TERRASCOPE_MAX_NODES", "500")
+		assert.Equal(t, 500, maxNodesFromEnv())
+	})
+
+	t.Run("invalid value falls back to the default", func(t *testing.T) {
+		t.Setenv("TERRASCOPE_MAX_NODES", "-1")
+		assert.Equal(t, defaultMaxNodes, maxNodesFromEnv())
+	})
+}
@@ -0,0 +1,60 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/terrascope/core/internal/parser"
+)
+
+type PlanOverlayRequest struct {
+	State json.RawMessage `json:"state"`
+	Plan  json.RawMessage `json:"plan"`
+}
+
+// PlanOverlayHandler returns the dependency graph of the state with each node
+// annotated by its pending change in the plan, which must be the
+// `terraform show -json` output of a plan made against that state.
+func PlanOverlayHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	opts, ok := graphOptions(w, r)
+	if !ok {
+		return
+	}
+
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	var req PlanOverlayRequest
+	if err := json.Unmarshal(body.Bytes(), &req); err != nil {
+		http.Error(w, "Invalid plan overlay request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	state, err := parser.ParseTfstate(req.State)
+	if err != nil {
+		http.Error(w, "Invalid tfstate: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	plan, err := parser.ParsePlan(req.Plan)
+	if err != nil {
+		http.Error(w, "Invalid plan: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	graph := parser.BuildGraphWithOptions(state, opts)
+	parser.OverlayPlan(graph, plan, opts)
+	graph.Stats = parser.ComputeStats(graph)
+
+	writeGraph(w, r, graph)
+}
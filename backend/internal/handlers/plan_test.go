@@ -0,0 +1,76 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestPlanOverlayHandler(t *testing.T) {
+	tfstate := `{
+		"version": 4, "terraform_version": "1.9.0", "serial": 1, "lineage": "plan",
+		"resources": [{
+			"mode": "managed", "type": "aws_instance", "name": "web",
+			"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+			"instances": [{"attributes": {"id": "i-1", "instance_type": "t3.micro"}}]
+		}]
+	}`
+	plan := `{
+		"format_version": "1.2",
+		"resource_changes": [{
+			"address": "aws_instance.web", "mode": "managed", "type": "aws_instance", "name": "web",
+			"provider_name": "registry.terraform.io/hashicorp/aws",
+			"change": {
+				"actions": ["update"],
+				"before": {"id": "i-1", "instance_type": "t3.micro"},
+				"after": {"id": "i-1", "instance_type": "t3.large"}
+			}
+		}]
+	}`
+
+	t.Run("annotates the state graph", func(t *testing.T) {
+		body := `{"state": ` + tfstate + `, "plan": ` + plan + `}`
+		w := httptest.NewRecorder()
+		PlanOverlayHandler(w, httptest.NewRequest(http.MethodPost, "/graph/plan?id_format=terraform", strings.NewReader(body)))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var graph models.Graph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+		require.Len(t, graph.Nodes, 1)
+		require.NotNil(t, graph.Nodes[0].Change)
+		assert.Equal(t, "update", graph.Nodes[0].Change.Action)
+		assert.Equal(t, []string{"instance_type"}, graph.Nodes[0].Change.ChangedPaths)
+		assert.NotEmpty(t, w.Header().Get("X-Graph-Digest"))
+	})
+
+	tests := []struct {
+		name    string
+		method  string
+		body    string
+		code    int
+		message string
+	}{
+		{"wrong method", http.MethodGet, "", http.StatusMethodNotAllowed, "Method not allowed"},
+		{"invalid request", http.MethodPost, "not json", http.StatusBadRequest, "Invalid plan overlay request"},
+		{"invalid state", http.MethodPost, `{"state": {}, "plan": ` + plan + `}`, http.StatusBadRequest, "Invalid tfstate"},
+		{"missing plan", http.MethodPost, `{"state": ` + tfstate + `}`, http.StatusBadRequest, "Invalid plan"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			PlanOverlayHandler(w, httptest.NewRequest(tt.method, "/graph/plan", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.code, w.Code)
+			assert.Contains(t, w.Body.String(), tt.message)
+		})
+	}
+}
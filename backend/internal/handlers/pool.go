@@ -0,0 +1,47 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// maxPooledBufferSize keeps a single very large upload from pinning its
+// buffer in the pool for the lifetime of the process.
+const maxPooledBufferSize = 32 << 20
+
+var bodyBufferPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+// readBody reads r into a pooled buffer, growing it once up front when the
+// request announces its size. The returned buffer must be handed back with
+// releaseBody once nothing references its bytes anymore.
+func readBody(r io.Reader, sizeHint int64) (*bytes.Buffer, error) {
+	buf := bodyBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	if sizeHint > 0 && sizeHint <= maxPooledBufferSize {
+		buf.Grow(int(sizeHint) + bytes.MinRead)
+	}
+
+	if _, err := buf.ReadFrom(r); err != nil {
+		releaseBody(buf)
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+func releaseBody(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+
+	buf.Reset()
+	bodyBufferPool.Put(buf)
+}
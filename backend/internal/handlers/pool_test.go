@@ -0,0 +1,66 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestReadBody(t *testing.T) {
+	t.Run("reads the full body", func(t *testing.T) {
+		buf, err := readBody(strings.NewReader("hello"), 5)
+		require.NoError(t, err)
+		defer releaseBody(buf)
+
+		assert.Equal(t, "hello", buf.String())
+	})
+
+	t.Run("works without a size hint", func(t *testing.T) {
+		buf, err := readBody(strings.NewReader("hello"), -1)
+		require.NoError(t, err)
+		defer releaseBody(buf)
+
+		assert.Equal(t, "hello", buf.String())
+	})
+
+	t.Run("returns read errors", func(t *testing.T) {
+		_, err := readBody(failingReader{}, 0)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("reused buffers start empty", func(t *testing.T) {
+		first, err := readBody(strings.NewReader("first body"), 0)
+		require.NoError(t, err)
+		releaseBody(first)
+
+		second, err := readBody(strings.NewReader("second"), 0)
+		require.NoError(t, err)
+		defer releaseBody(second)
+
+		assert.Equal(t, "second", second.String())
+	})
+}
+
+func TestReleaseBody(t *testing.T) {
+	t.Run("oversized buffers are dropped", func(t *testing.T) {
+		buf := bytes.NewBuffer(make([]byte, 0, maxPooledBufferSize+1))
+		buf.WriteString("data")
+
+		releaseBody(buf)
+
+		assert.Equal(t, "data", buf.String())
+	})
+}
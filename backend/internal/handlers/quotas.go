@@ -0,0 +1,214 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/quotas"
+)
+
+// ProjectHeader names the project that an authenticated request's usage
+// counts against, unless it comes with an API key bound to a project.
+const ProjectHeader = "X-Terrascope-Project"
+
+// defaultProject is the project of requests that name none, or one they may
+// not use.
+const defaultProject = "default"
+
+// meter accounts for the usage of each project against the quotas of
+// TERRASCOPE_QUOTAS_CONFIG.
+var meter = quotas.FromEnv(shared)
+
+// requestProject returns the project whose quota the request uses: that of
+// its API key, or else the one its header names if the request comes from an
+// API key or a signed-in user and the project has a quota of its own. Other
+// requests count against the default project, so that clients cannot escape
+// their quota by naming a new project on each request. A user who cannot be
+// read from storage counts as anonymous.
+func requestProject(r *http.Request) string {
+	key, hasKey := requestAPIKey(r)
+	if hasKey && key.Project != "" {
+		return key.Project
+	}
+
+	project := strings.TrimSpace(r.Header.Get(ProjectHeader))
+	if project == "" || !meter.Known(project) {
+		return defaultProject
+	}
+
+	if hasKey {
+		return project
+	}
+	if _, ok, _ := currentUser(r); ok {
+		return project
+	}
+
+	return defaultProject
+}
+
+// checkQuota checks that the project may use what the request needs. On
+// failure it writes a 429 response, telling clients when monthly usage resets,
+// or a 500 response when stored bytes cannot be measured, and returns false.
+func checkQuota(w http.ResponseWriter, project string, need models.Usage) bool {
+	return enforceQuota(w, project, need, meter.Check)
+}
+
+// reserveQuota checks the quota as checkQuota does, and records the parses
+// the request needs along, so that concurrent requests cannot overshoot it.
+func reserveQuota(w http.ResponseWriter, project string, need models.Usage) bool {
+	return enforceQuota(w, project, need, meter.Reserve)
+}
+
+func enforceQuota(w http.ResponseWriter, project string, need models.Usage, check func(string, models.Usage, int64, time.Time) error) bool {
+	now := time.Now().UTC()
+
+	// Stored bytes are only measured when the request stores some against
+	// a limit, since measuring them reads every version of the project.
+	var stored int64
+	if need.StoredBytes > 0 && meter.Quota(project).StoredBytes > 0 {
+		var err error
+		if stored, err = storedStates.ProjectStoredBytes(project, now); err != nil {
+			storageFailed(w, err)
+			return false
+		}
+	}
+
+	err := check(project, need, stored, now)
+	if err == nil {
+		return true
+	}
+
+	var exceeded *quotas.ExceededError
+	if errors.As(err, &exceeded) && !exceeded.ResetsAt.IsZero() {
+		w.Header().Set("Retry-After", strconv.Itoa(int(exceeded.ResetsAt.Sub(now).Seconds())+1))
+	}
+
+	http.Error(w, "Quota exceeded: "+err.Error(), http.StatusTooManyRequests)
+	return false
+}
+
+// storing serializes storing the versions of each project with a stored
+// bytes quota, so that concurrent requests on this replica cannot all fit in
+// the room left by the quota.
+var storing = &projectLocks{held: make(map[string]chan struct{})}
+
+// projectLocks are locks by project.
+type projectLocks struct {
+	mu   sync.Mutex
+	held map[string]chan struct{}
+}
+
+// lock waits for the project's lock, takes it and returns its unlock.
+func (l *projectLocks) lock(project string) func() {
+	for {
+		l.mu.Lock()
+		released, busy := l.held[project]
+		if !busy {
+			released = make(chan struct{})
+			l.held[project] = released
+			l.mu.Unlock()
+
+			return func() {
+				l.mu.Lock()
+				delete(l.held, project)
+				l.mu.Unlock()
+				close(released)
+			}
+		}
+		l.mu.Unlock()
+
+		<-released
+	}
+}
+
+// MeterParses counts each request as a parse of its project, and rejects it
+// once the project has used up its parses this month.
+func MeterParses(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !reserveQuota(w, requestProject(r), models.Usage{Parses: 1}) {
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// MeterJobs counts the time each request takes as analysis job minutes of its
+// project, and rejects it once the project has used up its minutes this
+// month.
+func MeterJobs(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		project := requestProject(r)
+		if !checkQuota(w, project, models.Usage{JobMinutes: 1}) {
+			return
+		}
+
+		start := time.Now()
+		defer func() {
+			meter.Record(project, models.Usage{JobMinutes: time.Since(start).Minutes()}, time.Now().UTC())
+		}()
+
+		next(w, r)
+	}
+}
+
+// UsageHandler returns the usage of the request's project this month against
+// its quota.
+func UsageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	now := time.Now().UTC()
+	project := requestProject(r)
+
+	stored, err := storedStates.ProjectStoredBytes(project, now)
+	if err != nil {
+		storageFailed(w, err)
+		return
+	}
+
+	writeJSON(w, r, meter.Usage(project, stored, now))
+}
+
+// AdminUsageHandler returns the usage of every project this month: those with
+// a quota of their own, usage or stored states.
+func AdminUsageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	now := time.Now().UTC()
+
+	stored, err := storedStates.StoredBytes(now)
+	if err != nil {
+		storageFailed(w, err)
+		return
+	}
+
+	storing := make([]string, 0, len(stored))
+	for project := range stored {
+		storing = append(storing, project)
+	}
+
+	projects := meter.Projects(now, storing...)
+	usage := make([]models.ProjectUsage, 0, len(projects))
+	for _, project := range projects {
+		usage = append(usage, meter.Usage(project, stored[project], now))
+	}
+
+	writeJSON(w, r, usage)
+}
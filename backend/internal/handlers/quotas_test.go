@@ -0,0 +1,218 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/quotas"
+	"github.com/terrascope/core/internal/store"
+)
+
+// withQuotas enforces the config for the test, with empty usage and stored
+// states.
+func withQuotas(t *testing.T, config models.QuotaConfig) {
+	t.Helper()
+
+	previousMeter, previousStates := meter, storedStates
+	meter = quotas.New(config)
+	storedStates = store.NewStateStore(time.Hour)
+	t.Cleanup(func() { meter, storedStates = previousMeter, previousStates })
+}
+
+// withProjectKey authenticates the request with an API key bound to the
+// project.
+func withProjectKey(req *http.Request, project string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), apiKeyContextKey{}, models.APIKey{Project: project}))
+}
+
+func TestRequestProject(t *testing.T) {
+	withQuotas(t, models.QuotaConfig{Projects: map[string]models.Usage{"web": {}}})
+	withUsers(t)
+
+	request := func(project string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/parse", nil)
+		req.Header.Set(ProjectHeader, project)
+		return req
+	}
+
+	assert.Equal(t, defaultProject, requestProject(httptest.NewRequest(http.MethodPost, "/parse", nil)))
+	assert.Equal(t, defaultProject, requestProject(request("web")), "anonymous requests cannot name a project")
+
+	t.Run("signed-in users name projects with a quota of their own", func(t *testing.T) {
+		cookie := login(t, `{"username": "alice", "password": "secret"}`).Result().Cookies()[0]
+
+		req := request("web")
+		req.AddCookie(cookie)
+		assert.Equal(t, "web", requestProject(req))
+
+		req = request("unknown")
+		req.AddCookie(cookie)
+		assert.Equal(t, defaultProject, requestProject(req))
+	})
+
+	t.Run("API keys", func(t *testing.T) {
+		assert.Equal(t, "web", requestProject(withProjectKey(request("web"), "")))
+		assert.Equal(t, defaultProject, requestProject(withProjectKey(request("unknown"), "")))
+		assert.Equal(t, "ci", requestProject(withProjectKey(request("web"), "ci")), "keys bound to a project override the header")
+	})
+}
+
+func TestMeterParses(t *testing.T) {
+	withQuotas(t, models.QuotaConfig{Default: models.Usage{Parses: 1}})
+
+	parse := MeterParses(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	request := func(project string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		parse(w, withProjectKey(httptest.NewRequest(http.MethodPost, "/parse", nil), project))
+		return w
+	}
+
+	assert.Equal(t, http.StatusTeapot, request("web").Code)
+
+	w := request("web")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "Quota exceeded: quota exceeded for project web: 1 of 1 parses used this month")
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusTeapot, request("api").Code)
+
+	t.Run("does not measure stored bytes", func(t *testing.T) {
+		previous := storedStates
+		storedStates = failingStates{}
+		t.Cleanup(func() { storedStates = previous })
+
+		assert.Equal(t, http.StatusTeapot, request("ops").Code)
+	})
+}
+
+func TestMeterJobs(t *testing.T) {
+	withQuotas(t, models.QuotaConfig{Default: models.Usage{JobMinutes: 0.0001}})
+
+	job := MeterJobs(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	w := httptest.NewRecorder()
+	job(w, httptest.NewRequest(http.MethodPost, "/analyze/modules", nil))
+	require.Equal(t, http.StatusTeapot, w.Code)
+
+	usage := meter.Usage(defaultProject, 0, time.Now())
+	assert.Greater(t, usage.Usage.JobMinutes, 0.0001)
+
+	w = httptest.NewRecorder()
+	job(w, httptest.NewRequest(http.MethodPost, "/analyze/modules", nil))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestStoredBytesQuota(t *testing.T) {
+	withQuotas(t, models.QuotaConfig{Default: models.Usage{StoredBytes: 200}})
+
+	put := func(serial string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/states", strings.NewReader(`{"version": 4, "terraform_version": "1.5.0", "lineage": "quota", "serial": `+serial+`, "resources": []}`))
+		w := httptest.NewRecorder()
+		StatesHandler(w, withProjectKey(req, "web"))
+		return w
+	}
+
+	w := put("1")
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"project":"web"`)
+
+	require.Equal(t, http.StatusCreated, put("2").Code)
+
+	w = put("3")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "stored bytes used")
+	assert.Empty(t, w.Header().Get("Retry-After"))
+
+	t.Run("concurrent requests do not overshoot the quota", func(t *testing.T) {
+		storedStates = slowStates{storedStates}
+
+		var wg sync.WaitGroup
+		codes := make([]int, 6)
+		for i := range codes {
+			wg.Go(func() {
+				req := httptest.NewRequest(http.MethodPost, "/states", strings.NewReader(`{"version": 4, "terraform_version": "1.5.0", "lineage": "racing", "serial": `+strconv.Itoa(i+1)+`, "resources": []}`))
+				w := httptest.NewRecorder()
+				StatesHandler(w, withProjectKey(req, "api"))
+				codes[i] = w.Code
+			})
+		}
+		wg.Wait()
+
+		created := 0
+		for _, code := range codes {
+			if code == http.StatusCreated {
+				created++
+			}
+		}
+		assert.Equal(t, 2, created)
+	})
+}
+
+// slowStates store versions slowly, so that concurrent requests overlap.
+type slowStates struct {
+	store.StateBackend
+}
+
+func (s slowStates) Put(version models.StateVersion, data []byte) error {
+	time.Sleep(10 * time.Millisecond)
+	return s.StateBackend.Put(version, data)
+}
+
+func TestUsageHandlers(t *testing.T) {
+	withQuotas(t, models.QuotaConfig{
+		Default:  models.Usage{Parses: 10},
+		Projects: map[string]models.Usage{"platform": {}},
+	})
+	meter.Record("web", models.Usage{Parses: 3}, time.Now())
+
+	t.Run("usage of the request's project", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		UsageHandler(w, withProjectKey(httptest.NewRequest(http.MethodGet, "/usage", nil), "web"))
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var usage models.ProjectUsage
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&usage))
+		assert.Equal(t, "web", usage.Project)
+		assert.EqualValues(t, 3, usage.Usage.Parses)
+		assert.EqualValues(t, 10, usage.Quota.Parses)
+	})
+
+	t.Run("usage of every project for admins", func(t *testing.T) {
+		mux := adminMux(t)
+		mux.HandleFunc("/admin/usage", AdminUsageHandler)
+
+		w := adminRequest(mux, http.MethodGet, "/admin/usage", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var usage []models.ProjectUsage
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&usage))
+		require.Len(t, usage, 2)
+		assert.Equal(t, "platform", usage[0].Project)
+		assert.Equal(t, "web", usage[1].Project)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		UsageHandler(w, httptest.NewRequest(http.MethodPost, "/usage", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
@@ -0,0 +1,179 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"log"
+	"net/http"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+	"github.com/terrascope/core/internal/render"
+)
+
+// readState reads the request body and parses it as a tfstate document. On
+// failure it writes a 400 response and returns false.
+func readState(w http.ResponseWriter, r *http.Request) (*models.TerraformState, bool) {
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return nil, false
+	}
+	defer releaseBody(body)
+
+	state, err := parser.ParseTfstate(body.Bytes())
+	if err != nil {
+		http.Error(w, "Invalid tfstate: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	return state, true
+}
+
+// readStateGraph reads the request body, parses it as a tfstate document and
+// builds its dependency graph, through the graph cache. On failure it writes
+// a 400 response and returns false.
+func readStateGraph(w http.ResponseWriter, r *http.Request) (*models.TerraformState, *models.Graph, bool) {
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+	defer releaseBody(body)
+
+	state, graph, err := parseGraph(body.Bytes())
+	if err != nil {
+		http.Error(w, "Invalid tfstate: "+err.Error(), http.StatusBadRequest)
+		return nil, nil, false
+	}
+
+	return state, graph, true
+}
+
+// readRequestBody reads the full request body into a pooled buffer. On failure
+// it writes a 400 response and returns false; on success the caller must
+// release the buffer.
+func readRequestBody(w http.ResponseWriter, r *http.Request) (*bytes.Buffer, bool) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			log.Printf("failed to close request body: %v", err)
+		}
+	}()
+
+	body, err := readBody(r.Body, r.ContentLength)
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return nil, false
+	}
+
+	if !verifyChecksum(w, r, body.Bytes()) {
+		releaseBody(body)
+		return nil, false
+	}
+
+	return body, true
+}
+
+// contentChecksumHeader optionally carries the hex-encoded SHA-256 of the
+// request body, so that truncated or corrupted uploads are rejected before
+// they reach a parser.
+const contentChecksumHeader = "X-Content-SHA256"
+
+// verifyChecksum checks the body against the X-Content-SHA256 header when the
+// request has one. On failure it writes a 400 response and returns false.
+func verifyChecksum(w http.ResponseWriter, r *http.Request, body []byte) bool {
+	value := r.Header.Get(contentChecksumHeader)
+	if value == "" {
+		return true
+	}
+
+	expected, err := hex.DecodeString(value)
+	if err != nil || len(expected) != sha256.Size {
+		http.Error(w, "Invalid "+contentChecksumHeader+" header", http.StatusBadRequest)
+		return false
+	}
+
+	if sum := sha256.Sum256(body); !bytes.Equal(sum[:], expected) {
+		http.Error(w, "Body checksum mismatch", http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+// writeJSON encodes v as the compact JSON response body. The router's
+// encoding middleware indents it or splits lists into JSON Lines on request.
+func writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// writeXML encodes v as the indented XML response body.
+func writeXML(w http.ResponseWriter, v any) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		log.Printf("Error encoding response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+
+	if _, err := w.Write(append([]byte(xml.Header), append(body, '\n')...)); err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
+
+// writeGraph writes the graph, whose stats must be computed, as JSON or in the
+// format named by ?format=, with its digest in the X-Graph-Digest header. With
+// ?presentation=true every node carries its label and badges.
+func writeGraph(w http.ResponseWriter, r *http.Request, graph *models.Graph) {
+	name := r.URL.Query().Get("format")
+
+	var format render.Format
+	if name != "" && name != "json" {
+		var err error
+		if format, err = render.Lookup(name); err != nil {
+			http.Error(w, "Unknown format: "+name, http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("X-Graph-Digest", graph.Stats.Digest)
+
+	if r.URL.Query().Get("presentation") == "true" {
+		graph = parser.Present(graph)
+	}
+
+	if format.Render == nil {
+		writeJSON(w, r, graph)
+		return
+	}
+
+	writeRendered(w, graph, format)
+}
+
+// writeRendered renders the graph in the given format as the response body.
+func writeRendered(w http.ResponseWriter, graph *models.Graph, format render.Format) {
+	body, err := format.Render(graph)
+	if err != nil {
+		log.Printf("Error rendering %s: %v", format.Name, err)
+		http.Error(w, "Failed to render graph", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType)
+	if format.Attachment {
+		w.Header().Set("Content-Disposition", `attachment; filename="graph.`+format.Extension+`"`)
+	}
+
+	if _, err := w.Write(body); err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadRequestBodyChecksum(t *testing.T) {
+	tfstate := `{"version": 4, "terraform_version": "1.5.0", "serial": 1, "lineage": "abc", "resources": []}`
+	sum := sha256.Sum256([]byte(tfstate))
+	checksum := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name     string
+		checksum string
+		body     string
+		expected int
+		message  string
+	}{
+		{"no checksum", "", tfstate, http.StatusOK, ""},
+		{"matching checksum", checksum, tfstate, http.StatusOK, ""},
+		{"uppercase checksum", strings.ToUpper(checksum), tfstate, http.StatusOK, ""},
+		{"truncated body", checksum, tfstate[:40], http.StatusBadRequest, "Body checksum mismatch"},
+		{"malformed checksum", "not-a-checksum", tfstate, http.StatusBadRequest, "Invalid X-Content-SHA256 header"},
+		{"short checksum", checksum[:32], tfstate, http.StatusBadRequest, "Invalid X-Content-SHA256 header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/parse", strings.NewReader(tt.body))
+			if tt.checksum != "" {
+				req.Header.Set("X-Content-SHA256", tt.checksum)
+			}
+			w := httptest.NewRecorder()
+
+			ParseHandler(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+			assert.Contains(t, w.Body.String(), tt.message)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/terrascope/core/internal/parser"
+)
+
+// SanitizeHandler returns a copy of the posted state with its values redacted
+// and nothing stored, so that it can be attached to bug reports or used in
+// demos. The number of redacted values is sent in X-Redacted-Values.
+func SanitizeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	state, ok := readState(w, r)
+	if !ok {
+		return
+	}
+
+	sanitized, redacted := parser.Sanitize(state)
+
+	w.Header().Set("X-Redacted-Values", strconv.Itoa(redacted))
+	writeJSON(w, r, sanitized)
+}
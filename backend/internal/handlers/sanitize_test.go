@@ -0,0 +1,50 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestSanitizeHandler(t *testing.T) {
+	t.Run("redacts the posted state", func(t *testing.T) {
+		body := `{"version": 4, "terraform_version": "1.5.0", "serial": 1, "lineage": "prod", "resources": [{
+			"mode": "managed", "type": "aws_instance", "name": "web",
+			"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+			"instances": [{"attributes": {"id": "i-1", "user_data": "export TOKEN=abc"}}]
+		}]}`
+
+		w := httptest.NewRecorder()
+		SanitizeHandler(w, httptest.NewRequest(http.MethodPost, "/sanitize", strings.NewReader(body)))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "1", w.Header().Get("X-Redacted-Values"))
+		assert.NotContains(t, w.Body.String(), "TOKEN")
+
+		var state models.TerraformState
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&state))
+		assert.Equal(t, "i-1", state.Resources[0].Instances[0].Attributes["id"])
+	})
+
+	t.Run("rejects invalid states", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		SanitizeHandler(w, httptest.NewRequest(http.MethodPost, "/sanitize", strings.NewReader(`{}`)))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("rejects GET", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		SanitizeHandler(w, httptest.NewRequest(http.MethodGet, "/sanitize", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
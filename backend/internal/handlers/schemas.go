@@ -0,0 +1,60 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/terrascope/core/internal/parser"
+	"github.com/terrascope/core/internal/store"
+)
+
+// storedSchemasLimit bounds how many provider schemas documents are kept for
+// graph requests. Each is large but rarely changes.
+const storedSchemasLimit = 8
+
+// storedSchemas holds the uploaded provider schemas, keyed by digest.
+var storedSchemas = storedSchemasFromEnv()
+
+func storedSchemasFromEnv() store.SchemaBackend {
+	if shared != nil {
+		return store.NewSharedSchemas(shared)
+	}
+
+	return store.NewSchemaStore(storedSchemasLimit)
+}
+
+// SchemasHandler stores the posted `terraform providers schema -json` output
+// and returns its digest. Graph requests that pass the digest as ?schemas=
+// type resource attributes by these schemas.
+func SchemasHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	schemas, err := parser.ParseProviderSchemas(body.Bytes())
+	if err != nil {
+		http.Error(w, "Invalid provider schemas: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sum := sha256.Sum256(body.Bytes())
+	digest := hex.EncodeToString(sum[:])
+	storedSchemas.Put(digest, schemas)
+
+	summary := parser.SummarizeSchemas(schemas)
+	summary.Digest = digest
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, r, summary)
+}
@@ -0,0 +1,69 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+func TestSchemasHandler(t *testing.T) {
+	previous := storedSchemas
+	storedSchemas = store.NewSchemaStore(2)
+	t.Cleanup(func() { storedSchemas = previous })
+
+	schemas := `{"format_version": "1.0", "provider_schemas": {"registry.terraform.io/hashicorp/aws": {"resource_schemas": {
+		"aws_vpc": {"block": {"attributes": {"id": {"type": "string", "computed": true}}}},
+		"aws_security_group": {"block": {"attributes": {"vpc_id": {"type": "string", "optional": true}}}}
+	}}}}`
+
+	w := httptest.NewRecorder()
+	SchemasHandler(w, httptest.NewRequest(http.MethodPost, "/schemas", strings.NewReader(schemas)))
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var summary models.SchemaSummary
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&summary))
+	assert.Len(t, summary.Digest, 64)
+	assert.Equal(t, 2, summary.ResourceTypes)
+
+	t.Run("graphs reference the schemas by digest", func(t *testing.T) {
+		state := `{"version": 4, "terraform_version": "1.5.0", "resources": [
+			{"mode": "managed", "type": "aws_vpc", "name": "main", "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+			 "instances": [{"attributes": {"id": "vpc-1"}}]},
+			{"mode": "managed", "type": "aws_security_group", "name": "web", "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+			 "instances": [{"attributes": {"id": "sg-1", "vpc_id": "vpc-1"}}]}
+		]}`
+
+		w := httptest.NewRecorder()
+		ParseHandler(w, httptest.NewRequest(http.MethodPost, "/parse?schemas="+summary.Digest, strings.NewReader(state)))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var graph models.Graph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+		require.Len(t, graph.Edges, 1)
+		assert.Equal(t, "reference", graph.Edges[0].Type)
+		assert.Equal(t, "aws_vpc.main", graph.Edges[0].Target)
+	})
+
+	t.Run("rejects invalid schemas", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		SchemasHandler(w, httptest.NewRequest(http.MethodPost, "/schemas", strings.NewReader(`{"format_version": "1.0"}`)))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("rejects unknown digests", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ParseHandler(w, httptest.NewRequest(http.MethodPost, "/parse?schemas=unknown", strings.NewReader(`{}`)))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
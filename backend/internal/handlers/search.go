@@ -0,0 +1,65 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// maxSearchHits bounds how many hits SearchHandler returns.
+const maxSearchHits = 100
+
+// SearchHandler returns the attributes of the stored state versions matching
+// ?q=, such as a CIDR block or an instance ID, with the resources and versions
+// that have them. ?lineage= limits the search to a lineage.
+func SearchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		http.Error(w, "Missing query", http.StatusBadRequest)
+		return
+	}
+
+	results, err := storedStates.Search(query, r.URL.Query().Get("lineage"), maxSearchHits)
+	if err != nil {
+		storageFailed(w, err)
+		return
+	}
+
+	writeJSON(w, r, results)
+}
+
+// LookupHandler returns the stored states that manage the cloud resource
+// whose id, arn or self_link is ?id=, with the address and module of the
+// resource in them, answering which Terraform configuration manages it.
+func LookupHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "Missing id", http.StatusBadRequest)
+		return
+	}
+
+	matches, err := storedStates.Lookup(id)
+	if err != nil {
+		storageFailed(w, err)
+		return
+	}
+
+	if len(matches) == 0 {
+		http.Error(w, "No stored state manages "+id, http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, r, models.LookupResult{ID: id, Matches: matches})
+}
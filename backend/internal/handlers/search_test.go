@@ -0,0 +1,117 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+func TestSearchHandler(t *testing.T) {
+	previous := storedStates
+	storedStates = store.NewStateStore(time.Hour)
+	t.Cleanup(func() { storedStates = previous })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/states", StatesHandler)
+	mux.HandleFunc("/graph/search", SearchHandler)
+
+	request := func(method, url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, url, strings.NewReader(body)))
+
+		return w
+	}
+
+	tfstate := `{"version": 4, "terraform_version": "1.5.0", "serial": 1, "lineage": "network", "resources": [
+		{"mode": "managed", "type": "aws_vpc", "name": "main", "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+		 "instances": [{"attributes": {"id": "vpc-1", "cidr_block": "10.42.0.0/16"}}]}
+	]}`
+	require.Equal(t, http.StatusCreated, request(http.MethodPost, "/states", tfstate).Code)
+
+	t.Run("returns the resources and versions that have a value", func(t *testing.T) {
+		w := request(http.MethodGet, "/graph/search?q=10.42.0.0/16", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var results models.SearchResults
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&results))
+		assert.Equal(t, "10.42.0.0/16", results.Query)
+		assert.Equal(t, []models.SearchHit{{
+			Lineage:   "network",
+			Address:   "aws_vpc.main",
+			Attribute: "cidr_block",
+			Value:     "10.42.0.0/16",
+			Serials:   []int{1},
+		}}, results.Hits)
+	})
+
+	t.Run("searches the backend OpenStorage opened", func(t *testing.T) {
+		storedStates = store.NewStateStore(time.Hour)
+		t.Cleanup(func() { storedStates = previous })
+
+		w := request(http.MethodGet, "/graph/search?q=vpc-1", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"query": "vpc-1", "hits": []}`, w.Body.String())
+	})
+
+	t.Run("requires a query", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, request(http.MethodGet, "/graph/search", "").Code)
+	})
+
+	t.Run("rejects other methods", func(t *testing.T) {
+		assert.Equal(t, http.StatusMethodNotAllowed, request(http.MethodPost, "/graph/search?q=vpc-1", "").Code)
+	})
+}
+
+func TestLookupHandler(t *testing.T) {
+	previous := storedStates
+	storedStates = store.NewStateStore(time.Hour)
+	t.Cleanup(func() { storedStates = previous })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/states", StatesHandler)
+	mux.HandleFunc("/lookup", LookupHandler)
+
+	request := func(method, url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, url, strings.NewReader(body)))
+
+		return w
+	}
+
+	tfstate := `{"version": 4, "terraform_version": "1.5.0", "serial": 4, "lineage": "compute", "resources": [
+		{"mode": "managed", "type": "aws_instance", "name": "web", "module": "module.app",
+		 "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+		 "instances": [{"attributes": {"id": "i-0abc123"}}]}
+	]}`
+	require.Equal(t, http.StatusCreated, request(http.MethodPost, "/states", tfstate).Code)
+
+	t.Run("returns the state that manages an ID", func(t *testing.T) {
+		w := request(http.MethodGet, "/lookup?id=i-0abc123", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		assert.JSONEq(t, `{"id": "i-0abc123", "matches": [{"lineage": "compute", "serial": 4, "project": "default",
+			"address": "module.app.aws_instance.web", "module": "module.app", "type": "aws_instance",
+			"attribute": "id"}]}`, w.Body.String())
+	})
+
+	t.Run("reports unmanaged IDs", func(t *testing.T) {
+		w := request(http.MethodGet, "/lookup?id=i-0def456", "")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "No stored state manages i-0def456\n", w.Body.String())
+	})
+
+	t.Run("requires an id", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, request(http.MethodGet, "/lookup", "").Code)
+	})
+}
@@ -0,0 +1,56 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/terrascope/core/internal/catalog"
+	"github.com/terrascope/core/internal/models"
+)
+
+// serviceNow receives the import sets pushed by ServiceNowExportHandler. It
+// is nil when no ServiceNow instance is configured.
+var serviceNow = catalog.NewServiceNowFromEnv()
+
+// ServiceNowExportHandler maps the resources of the latest stored version of
+// the lineage in ?lineage=, or of every stored lineage, to CMDB configuration
+// items in the import set format of ServiceNow. GET serves the import set for
+// ServiceNow to pull, and POST pushes it to the staging table of the
+// configured instance.
+func ServiceNowExportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.Method == http.MethodPost && serviceNow == nil {
+		http.Error(w, "ServiceNow is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	states, ok := selectedStates(w, r.URL.Query().Get("lineage"))
+	if !ok {
+		return
+	}
+
+	set := &models.CMDBImportSet{Records: []models.CMDBRecord{}}
+	for _, state := range states {
+		set.Records = append(set.Records, catalog.CMDBRecords(state)...)
+	}
+
+	if r.Method == http.MethodGet {
+		writeJSON(w, r, set)
+		return
+	}
+
+	result, err := serviceNow.Push(r.Context(), set)
+	if err != nil {
+		log.Printf("ServiceNow export failed: %v", err)
+		http.Error(w, "Export failed", http.StatusBadGateway)
+		return
+	}
+
+	writeJSON(w, r, result)
+}
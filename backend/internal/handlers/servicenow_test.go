@@ -0,0 +1,128 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/catalog"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+func TestServiceNowExportHandler(t *testing.T) {
+	previous := storedStates
+	storedStates = store.NewStateStore(time.Hour)
+	t.Cleanup(func() { storedStates = previous })
+
+	tfstate := func(lineage string) []byte {
+		return []byte(`{"version": 4, "terraform_version": "1.5.0", "serial": 3, "lineage": "` + lineage + `", "resources": [{
+			"mode": "managed",
+			"type": "aws_s3_bucket",
+			"name": "logs",
+			"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+			"instances": [{"attributes": {"id": "` + lineage + `-logs", "arn": "arn:aws:s3:::` + lineage + `-logs"}}]
+		}]}`)
+	}
+
+	require.NoError(t, storedStates.Put(models.StateVersion{Lineage: "prod", Serial: 3}, tfstate("prod")))
+	require.NoError(t, storedStates.Put(models.StateVersion{Lineage: "staging", Serial: 3}, tfstate("staging")))
+
+	withServiceNow := func(t *testing.T, client *catalog.ServiceNow) {
+		t.Helper()
+
+		original := serviceNow
+		serviceNow = client
+		t.Cleanup(func() { serviceNow = original })
+	}
+
+	export := func(method, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		ServiceNowExportHandler(w, httptest.NewRequest(method, target, nil))
+
+		return w
+	}
+
+	t.Run("serves the import set of a lineage", func(t *testing.T) {
+		w := export(http.MethodGet, "/api/v1/export/servicenow?lineage=prod")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var set models.CMDBImportSet
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&set))
+
+		require.Len(t, set.Records, 1)
+		assert.Equal(t, catalog.CorrelationID("prod", "aws_s3_bucket.logs"), set.Records[0].CorrelationID)
+		assert.Equal(t, "cmdb_ci_cloud_object_storage", set.Records[0].Class)
+		assert.Equal(t, "prod-logs", set.Records[0].ObjectID)
+	})
+
+	t.Run("serves the import set of every lineage", func(t *testing.T) {
+		w := export(http.MethodGet, "/api/v1/export/servicenow")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var set models.CMDBImportSet
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&set))
+
+		require.Len(t, set.Records, 2)
+		assert.Equal(t, "prod", set.Records[0].Lineage)
+		assert.Equal(t, "staging", set.Records[1].Lineage)
+	})
+
+	t.Run("unknown lineage", func(t *testing.T) {
+		w := export(http.MethodGet, "/api/v1/export/servicenow?lineage=unknown")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("pushes to the configured instance", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var set models.CMDBImportSet
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&set))
+			assert.Len(t, set.Records, 1)
+
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"import_set_id": "ISET0010001", "staging_table": "u_terrascope_ci_import"}`))
+		}))
+		defer server.Close()
+		withServiceNow(t, &catalog.ServiceNow{InstanceURL: server.URL, Table: "u_terrascope_ci_import", HTTPClient: server.Client()})
+
+		w := export(http.MethodPost, "/api/v1/export/servicenow?lineage=staging")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var result models.CMDBPushResult
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+		assert.Equal(t, models.CMDBPushResult{Table: "u_terrascope_ci_import", ImportSetID: "ISET0010001", Records: 1}, result)
+	})
+
+	t.Run("push failure", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+		withServiceNow(t, &catalog.ServiceNow{InstanceURL: server.URL, Table: "u_terrascope_ci_import", HTTPClient: server.Client()})
+
+		w := export(http.MethodPost, "/api/v1/export/servicenow")
+
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		withServiceNow(t, nil)
+
+		w := export(http.MethodPost, "/api/v1/export/servicenow")
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		w := export(http.MethodDelete, "/api/v1/export/servicenow")
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
@@ -0,0 +1,196 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+// Lifetimes of share links when none is requested, and at most.
+const (
+	defaultShareTTL = 24 * time.Hour
+	maxShareTTL     = 30 * 24 * time.Hour
+)
+
+// shareKey signs share links. Without TERRASCOPE_SHARE_KEY a random key is
+// used, and links stop working when the server restarts.
+var shareKey = shareKeyFromEnv()
+
+func shareKeyFromEnv() []byte {
+	if key := os.Getenv("TERRASCOPE_SHARE_KEY"); key != "" {
+		return []byte(key)
+	}
+
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+
+	return key
+}
+
+// shareClaims is what a share token grants.
+type shareClaims struct {
+	Lineage   string `json:"lineage"`
+	Serial    int    `json:"serial"`
+	Module    string `json:"module,omitempty"`
+	Redact    bool   `json:"redact,omitempty"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// SharesHandler mints a signed, expiring link granting read-only access to the
+// graph of a stored state version, the latest of the lineage unless a serial
+// is given, so that it can be shared without an account.
+func SharesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	var req models.ShareRequest
+	if err := json.Unmarshal(body.Bytes(), &req); err != nil {
+		http.Error(w, "Invalid share: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Lineage == "" {
+		http.Error(w, "Invalid share: missing lineage", http.StatusBadRequest)
+		return
+	}
+
+	ttl := defaultShareTTL
+	if req.ExpiresIn != "" {
+		var err error
+		if ttl, err = time.ParseDuration(req.ExpiresIn); err != nil || ttl <= 0 || ttl > maxShareTTL {
+			http.Error(w, "Invalid expires_in: "+req.ExpiresIn, http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Links are pinned to a version, so that they keep showing the graph that
+	// was shared when the lineage moves on.
+	serial := req.Serial
+	if serial == 0 {
+		if serial, ok = latestSerial(w, req.Lineage); !ok {
+			return
+		}
+	}
+
+	if _, ok := indexedGraph(w, req.Lineage, strconv.Itoa(serial)); !ok {
+		return
+	}
+
+	expiresAt := time.Now().UTC().Add(ttl).Truncate(time.Second)
+	token := signShare(shareClaims{
+		Lineage:   req.Lineage,
+		Serial:    serial,
+		Module:    req.Module,
+		Redact:    req.Redact,
+		ExpiresAt: expiresAt.Unix(),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, r, models.Share{
+		Token:     token,
+		URL:       strings.TrimSuffix(r.URL.Path, "/") + "/" + token,
+		Lineage:   req.Lineage,
+		Serial:    serial,
+		Module:    req.Module,
+		Redact:    req.Redact,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// ShareHandler returns the graph a share link grants access to, filtered and
+// redacted as the link says. Links stop working when they expire or when the
+// version is deleted or archived to cold storage.
+func ShareHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, ok := verifyShare(r.PathValue("token"))
+	if !ok {
+		http.Error(w, "Share not found", http.StatusNotFound)
+		return
+	}
+
+	if time.Now().Unix() >= claims.ExpiresAt {
+		http.Error(w, "Share expired", http.StatusGone)
+		return
+	}
+
+	graph, ok := indexedGraph(w, claims.Lineage, strconv.Itoa(claims.Serial))
+	if !ok {
+		return
+	}
+
+	if claims.Module != "" {
+		graph = parser.ModuleSubgraph(graph, claims.Module)
+	}
+	graph.Stats = parser.ComputeStats(graph)
+
+	if claims.Redact {
+		graph, _ = parser.SanitizeGraph(graph)
+	}
+
+	w.Header().Set("Cache-Control", "private, no-store")
+	writeGraph(w, r, graph)
+}
+
+// signShare encodes the claims and their HMAC as a URL-safe token.
+func signShare(claims shareClaims) string {
+	payload, _ := json.Marshal(claims)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(shareSignature(encoded))
+}
+
+// verifyShare returns the claims of a token signed with shareKey.
+func verifyShare(token string) (shareClaims, bool) {
+	var claims shareClaims
+
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return claims, false
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, shareSignature(encoded)) {
+		return claims, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || json.Unmarshal(payload, &claims) != nil || claims.Lineage == "" {
+		return claims, false
+	}
+
+	return claims, true
+}
+
+func shareSignature(encoded string) []byte {
+	mac := hmac.New(sha256.New, shareKey)
+	mac.Write([]byte(encoded))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,188 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+func TestShares(t *testing.T) {
+	previousStates, previousGraphs := storedStates, storedGraphs
+	storedStates = store.NewStateStore(time.Hour)
+	storedGraphs = store.NewGraphStore(storedGraphsLimit)
+	t.Cleanup(func() { storedStates, storedGraphs = previousStates, previousGraphs })
+
+	tfstate := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"serial": 3,
+		"lineage": "shared",
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_vpc",
+				"name": "main",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "vpc-1", "tags": {"Owner": "alice"}}}]
+			},
+			{
+				"module": "module.app",
+				"mode": "managed",
+				"type": "aws_instance",
+				"name": "web",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "i-1", "name": "web-prod"}, "dependencies": ["aws_vpc.main"]}]
+			}
+		]
+	}`
+
+	stored := httptest.NewRecorder()
+	StatesHandler(stored, httptest.NewRequest(http.MethodPost, "/states", strings.NewReader(tfstate)))
+	require.Equal(t, http.StatusCreated, stored.Code)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/shares", RequireSession(SharesHandler))
+	mux.HandleFunc("/api/v1/shares/{token}", ShareHandler)
+
+	share := func(body string) (models.Share, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/shares", strings.NewReader(body)))
+
+		var share models.Share
+		if w.Code == http.StatusCreated {
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&share))
+		}
+		return share, w
+	}
+
+	open := func(url string) (*models.Graph, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+
+		var graph models.Graph
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+		}
+		return &graph, w
+	}
+
+	t.Run("link grants the latest stored version", func(t *testing.T) {
+		created, w := share(`{"lineage": "shared"}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		assert.Equal(t, "/api/v1/shares/"+created.Token, created.URL)
+		assert.Equal(t, "shared", created.Lineage)
+		assert.Equal(t, 3, created.Serial)
+		assert.WithinDuration(t, time.Now().Add(defaultShareTTL), created.ExpiresAt, time.Minute)
+
+		graph, w := open(created.URL)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "private, no-store", w.Header().Get("Cache-Control"))
+		assert.Len(t, graph.Nodes, 2)
+		require.NotNil(t, graph.Stats)
+		assert.Equal(t, 2, graph.Stats.TotalNodes)
+	})
+
+	t.Run("link outlives the graphs of later parses", func(t *testing.T) {
+		created, w := share(`{"lineage": "shared", "serial": 3}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		for i := range storedGraphsLimit + 1 {
+			parsed := httptest.NewRecorder()
+			body := strings.Replace(tfstate, "web-prod", "web-"+strconv.Itoa(i), 1)
+			ParseHandler(parsed, httptest.NewRequest(http.MethodPost, "/parse", strings.NewReader(body)))
+			require.Equal(t, http.StatusOK, parsed.Code)
+		}
+
+		graph, w := open(created.URL)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Len(t, graph.Nodes, 2)
+	})
+
+	t.Run("link can be filtered and redacted", func(t *testing.T) {
+		created, w := share(`{"lineage": "shared", "module": "module.app", "redact": true, "expires_in": "1h"}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), created.ExpiresAt, time.Minute)
+
+		graph, w := open(created.URL)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, graph.Nodes, 1)
+		assert.Equal(t, "i-1", graph.Nodes[0].Metadata["id"])
+		assert.Equal(t, "(redacted)", graph.Nodes[0].Metadata["name"])
+		assert.NotContains(t, w.Body.String(), "web-prod")
+	})
+
+	t.Run("tampered links are not found", func(t *testing.T) {
+		created, _ := share(`{"lineage": "shared", "redact": true}`)
+
+		claims, ok := verifyShare(created.Token)
+		require.True(t, ok)
+		claims.Redact = false
+		payload, _, _ := strings.Cut(signShare(claims), ".")
+		_, signature, _ := strings.Cut(created.Token, ".")
+
+		_, w := open("/api/v1/shares/" + payload + "." + signature)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		_, w = open("/api/v1/shares/not-a-token")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("expired links are gone", func(t *testing.T) {
+		token := signShare(shareClaims{Lineage: "shared", Serial: 3, ExpiresAt: time.Now().Add(-time.Minute).Unix()})
+
+		_, w := open("/api/v1/shares/" + token)
+		assert.Equal(t, http.StatusGone, w.Code)
+	})
+
+	t.Run("links to missing versions are not found", func(t *testing.T) {
+		token := signShare(shareClaims{Lineage: "shared", Serial: 9, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+
+		_, w := open("/api/v1/shares/" + token)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "State not found: shared/9")
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		for body, code := range map[string]int{
+			`{invalid`:                               http.StatusBadRequest,
+			`{}`:                                     http.StatusBadRequest,
+			`{"lineage": "unknown"}`:                 http.StatusNotFound,
+			`{"lineage": "shared", "serial": 9}`:     http.StatusNotFound,
+			`{"lineage": "x", "expires_in": "1"}`:    http.StatusBadRequest,
+			`{"lineage": "x", "expires_in": "-1h"}`:  http.StatusBadRequest,
+			`{"lineage": "x", "expires_in": "800h"}`: http.StatusBadRequest,
+		} {
+			_, w := share(body)
+			assert.Equal(t, code, w.Code, body)
+		}
+	})
+
+	t.Run("minting links requires a session", func(t *testing.T) {
+		withUsers(t)
+
+		_, w := share(`{"lineage": "shared"}`)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("returns 405 for wrong methods", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/shares", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/v1/shares/token", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
@@ -0,0 +1,48 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/terrascope/core/internal/analysis"
+	"github.com/terrascope/core/internal/parser"
+)
+
+type DestroyRequest struct {
+	State   json.RawMessage `json:"state"`
+	Targets []string        `json:"targets"`
+}
+
+func SimulateDestroyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	var req DestroyRequest
+	if err := json.Unmarshal(body.Bytes(), &req); err != nil {
+		http.Error(w, "Invalid destroy request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Targets) == 0 {
+		http.Error(w, "Invalid destroy request: no targets", http.StatusBadRequest)
+		return
+	}
+
+	state, err := parser.ParseTfstate(req.State)
+	if err != nil {
+		http.Error(w, "Invalid tfstate: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, r, analysis.SimulateDestroy(state, req.Targets))
+}
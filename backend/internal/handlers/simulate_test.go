@@ -0,0 +1,109 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestSimulateDestroyHandler(t *testing.T) {
+	destroyBody := `{
+		"state": {
+			"version": 4,
+			"terraform_version": "1.5.0",
+			"serial": 1,
+			"lineage": "abc-123",
+			"resources": [
+				{
+					"mode": "managed",
+					"type": "aws_vpc",
+					"name": "main",
+					"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+					"instances": [{"attributes": {"id": "vpc-1"}}]
+				},
+				{
+					"mode": "managed",
+					"type": "aws_db_subnet_group",
+					"name": "main",
+					"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+					"instances": [{"attributes": {"id": "main"}, "dependencies": ["aws_vpc.main"]}]
+				},
+				{
+					"mode": "managed",
+					"type": "aws_db_instance",
+					"name": "main",
+					"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+					"instances": [{"attributes": {"id": "db-1"}, "dependencies": ["aws_db_subnet_group.main"]}]
+				}
+			]
+		},
+		"targets": ["aws_vpc.main"]
+	}`
+
+	t.Run("returns the ordered destroy cascade", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/simulate/destroy", strings.NewReader(destroyBody))
+		w := httptest.NewRecorder()
+
+		SimulateDestroyHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var result models.DestroySimulation
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+
+		require.Len(t, result.Cascade, 3)
+		assert.Equal(t, "aws_db_instance.main", result.Cascade[0].ID)
+		assert.Equal(t, "aws_db_subnet_group.main", result.Cascade[1].ID)
+		assert.Equal(t, "aws_vpc.main", result.Cascade[2].ID)
+		require.Len(t, result.Warnings, 1)
+		assert.Equal(t, "protected_resource", result.Warnings[0].Type)
+	})
+
+	t.Run("returns 405 for GET request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/simulate/destroy", nil)
+		w := httptest.NewRecorder()
+
+		SimulateDestroyHandler(w, req)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+
+	t.Run("returns 400 for invalid request body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/simulate/destroy", strings.NewReader(`{invalid`))
+		w := httptest.NewRecorder()
+
+		SimulateDestroyHandler(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid destroy request")
+	})
+
+	t.Run("returns 400 without targets", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/simulate/destroy", strings.NewReader(`{"state": {"version": 4, "terraform_version": "1.5.0"}}`))
+		w := httptest.NewRecorder()
+
+		SimulateDestroyHandler(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "no targets")
+	})
+
+	t.Run("returns 400 when the state is missing", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/simulate/destroy", strings.NewReader(`{"targets": ["aws_vpc.main"]}`))
+		w := httptest.NewRecorder()
+
+		SimulateDestroyHandler(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid tfstate")
+	})
+}
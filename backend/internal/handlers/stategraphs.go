@@ -0,0 +1,119 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+	"github.com/terrascope/core/internal/store"
+)
+
+// graphSnapshotEvery is how often the graph of a stored version is kept in
+// full rather than as a patch of the graph of the previous version.
+const graphSnapshotEvery = 16
+
+// stateGraphs indexes the graphs of the stored states, built with the default
+// options of ParseHandler.
+func stateGraphs() *store.GraphIndex {
+	return &store.GraphIndex{
+		States:        storedStates,
+		SnapshotEvery: graphSnapshotEvery,
+		Options:       parser.GraphOptions{IDFormat: defaultIDFormat},
+	}
+}
+
+// StateGraphHandler returns the dependency graph of a stored state version,
+// the latest of the lineage without a serial, from the graph index. With
+// ?root= it returns the neighborhood of a node in it, as SubgraphHandler does
+// for the graphs ParseHandler built.
+func StateGraphHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	neighborhood, ok := subgraphRequest(w, r)
+	if !ok {
+		return
+	}
+
+	graph, ok := indexedGraph(w, r.PathValue("lineage"), r.PathValue("serial"))
+	if !ok {
+		return
+	}
+
+	if neighborhood.root != "" {
+		writeSubgraph(w, r, graph, neighborhood)
+		return
+	}
+
+	if neighborhood.reduce {
+		graph = parser.ReduceTransitive(graph, neighborhood.reduceDepth)
+	}
+	graph.Stats = parser.ComputeStats(graph)
+
+	writeGraph(w, r, graph)
+}
+
+// indexedGraph returns the graph of a stored version, the latest version of
+// the lineage when value is empty. On failure it writes the response and
+// returns false.
+func indexedGraph(w http.ResponseWriter, lineage, value string) (*models.Graph, bool) {
+	var serial int
+	if value == "" {
+		var ok bool
+		if serial, ok = latestSerial(w, lineage); !ok {
+			return nil, false
+		}
+		value = strconv.Itoa(serial)
+	} else {
+		var err error
+		if serial, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "Invalid serial: "+value, http.StatusBadRequest)
+			return nil, false
+		}
+	}
+
+	graph, err := stateGraphs().Graph(lineage, serial)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		http.Error(w, "State not found: "+lineage+"/"+value, http.StatusNotFound)
+		return nil, false
+	case errors.Is(err, store.ErrArchived):
+		http.Error(w, "State is archived to cold storage: "+lineage+"/"+value, http.StatusConflict)
+		return nil, false
+	case err != nil:
+		storageFailed(w, err)
+		return nil, false
+	}
+
+	return graph, true
+}
+
+// latestSerial returns the serial of the latest stored version of a lineage.
+// On failure it writes the response and returns false.
+func latestSerial(w http.ResponseWriter, lineage string) (int, bool) {
+	versions, err := storedStates.Versions(lineage)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Lineage not found: "+lineage, http.StatusNotFound)
+		return 0, false
+	} else if err != nil {
+		storageFailed(w, err)
+		return 0, false
+	}
+
+	return versions[len(versions)-1].Serial, true
+}
+
+// indexGraph stores the graph of a version just stored. Failures are only
+// logged, as the index builds missing graphs when they are queried.
+func indexGraph(version models.StateVersion, graph *models.Graph) {
+	if err := stateGraphs().Update(version, graph); err != nil {
+		log.Printf("Failed to index the graph of %s/%d: %v", version.Lineage, version.Serial, err)
+	}
+}
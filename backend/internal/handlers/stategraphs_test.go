@@ -0,0 +1,116 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+func TestStateGraphHandler(t *testing.T) {
+	previous := storedStates
+	storedStates = store.NewStateStore(time.Hour)
+	t.Cleanup(func() { storedStates = previous })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/states", StatesHandler)
+	mux.HandleFunc("/states/{lineage}/graph", StateGraphHandler)
+	mux.HandleFunc("/states/{lineage}/{serial}/graph", StateGraphHandler)
+
+	request := func(method, url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, url, strings.NewReader(body)))
+
+		return w
+	}
+
+	tfstate := func(serial, bucket string) string {
+		return `{"version": 4, "terraform_version": "1.5.0", "serial": ` + serial + `, "lineage": "graphs", "resources": [
+			{"mode": "managed", "type": "aws_vpc", "name": "main", "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+			 "instances": [{"attributes": {"id": "vpc-1"}}]},
+			{"mode": "managed", "type": "aws_subnet", "name": "a", "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+			 "instances": [{"attributes": {"id": "subnet-1"}, "dependencies": ["aws_vpc.main"]}]},
+			{"mode": "managed", "type": "aws_s3_bucket", "name": "logs", "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+			 "instances": [{"attributes": {"id": "` + bucket + `"}}]}
+		]}`
+	}
+
+	require.Equal(t, http.StatusCreated, request(http.MethodPost, "/states", tfstate("1", "logs-1")).Code)
+	require.Equal(t, http.StatusCreated, request(http.MethodPost, "/states", tfstate("2", "logs-2")).Code)
+
+	decode := func(w *httptest.ResponseRecorder) models.Graph {
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var graph models.Graph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+
+		return graph
+	}
+
+	t.Run("indexes stored versions", func(t *testing.T) {
+		stored, err := storedStates.Graph("graphs", 2)
+		require.NoError(t, err)
+		assert.Equal(t, 1, stored.Depth)
+		assert.Len(t, stored.Patch.Nodes, 1)
+	})
+
+	t.Run("returns the graph of a version", func(t *testing.T) {
+		graph := decode(request(http.MethodGet, "/states/graphs/1/graph", ""))
+
+		assert.Len(t, graph.Nodes, 3)
+		assert.Len(t, graph.Edges, 1)
+		require.NotNil(t, graph.Stats)
+		assert.Equal(t, 3, graph.Stats.TotalNodes)
+	})
+
+	t.Run("returns the latest version without a serial", func(t *testing.T) {
+		graph := decode(request(http.MethodGet, "/states/graphs/graph", ""))
+
+		var ids []any
+		for _, node := range graph.Nodes {
+			ids = append(ids, node.Metadata["id"])
+		}
+		assert.Contains(t, ids, "logs-2")
+	})
+
+	t.Run("returns the neighborhood of a node", func(t *testing.T) {
+		graph := decode(request(http.MethodGet, "/states/graphs/2/graph?root=aws_subnet.a&direction=dependencies", ""))
+
+		assert.Len(t, graph.Nodes, 2)
+		assert.Len(t, graph.Edges, 1)
+	})
+
+	t.Run("answers archived versions with a conflict", func(t *testing.T) {
+		require.NoError(t, storedStates.Put(models.StateVersion{Lineage: "cold", Serial: 1}, []byte(tfstate("1", "logs-1"))))
+		require.NoError(t, storedStates.Archive("cold", 1))
+
+		assert.Equal(t, http.StatusConflict, request(http.MethodGet, "/states/cold/1/graph", "").Code)
+	})
+
+	tests := []struct {
+		name     string
+		url      string
+		expected int
+	}{
+		{"unknown lineage", "/states/unknown/graph", http.StatusNotFound},
+		{"unknown version", "/states/graphs/9/graph", http.StatusNotFound},
+		{"invalid serial", "/states/graphs/latest/graph", http.StatusBadRequest},
+		{"unknown root", "/states/graphs/1/graph?root=aws_instance.web", http.StatusNotFound},
+		{"invalid depth", "/states/graphs/1/graph?root=aws_subnet.a&depth=-1", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, request(http.MethodGet, tt.url, "").Code)
+		})
+	}
+}
@@ -0,0 +1,241 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+// defaultTrashRetention is how long a deleted lineage stays restorable unless
+// TERRASCOPE_TRASH_RETENTION says otherwise.
+const defaultTrashRetention = 7 * 24 * time.Hour
+
+// storedStates keeps the uploaded history of each state lineage, in memory
+// until OpenStorage opens the backend selected by TERRASCOPE_STORAGE.
+var storedStates store.StateBackend = store.NewStateStore(trashRetentionFromEnv())
+
+func trashRetentionFromEnv() time.Duration {
+	value := os.Getenv("TERRASCOPE_TRASH_RETENTION")
+	if value == "" {
+		return defaultTrashRetention
+	}
+
+	retention, err := time.ParseDuration(value)
+	if err != nil || retention <= 0 {
+		log.Printf("Ignoring TERRASCOPE_TRASH_RETENTION: invalid value %q", value)
+		return defaultTrashRetention
+	}
+
+	return retention
+}
+
+// StatesHandler stores a version of the posted state (POST) or lists the
+// stored lineages (GET).
+func StatesHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		storeState(w, r)
+	case http.MethodGet:
+		lineages, err := storedStates.Lineages()
+		if err != nil {
+			storageFailed(w, err)
+			return
+		}
+
+		writeJSON(w, r, lineages)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func storeState(w http.ResponseWriter, r *http.Request) {
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	// The graph is built along, reusing the resources of the previous
+	// version, for the graph index.
+	parsed, err := deltaCache.Parse(body.Bytes(), stateGraphs().Options)
+	if err != nil {
+		http.Error(w, "Invalid tfstate: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	state := parsed.State
+
+	if state.Lineage == "" {
+		http.Error(w, "Invalid tfstate: missing lineage", http.StatusBadRequest)
+		return
+	}
+
+	project := requestProject(r)
+
+	unlock := func() {}
+	if meter.Quota(project).StoredBytes > 0 {
+		unlock = storing.lock(project)
+	}
+
+	if !checkQuota(w, project, models.Usage{StoredBytes: int64(body.Len())}) {
+		unlock()
+		return
+	}
+
+	data := bytes.Clone(body.Bytes())
+	sum := sha256.Sum256(data)
+
+	version := models.StateVersion{
+		Lineage:          state.Lineage,
+		Serial:           state.Serial,
+		TerraformVersion: state.TerraformVersion,
+		Resources:        len(state.Resources),
+		Digest:           hex.EncodeToString(sum[:]),
+		Size:             len(data),
+		StoredAt:         time.Now().UTC(),
+		Project:          project,
+		Anomalies:        detectAnomalies(state, project),
+	}
+
+	err = storedStates.Put(version, data)
+	unlock()
+
+	if errors.Is(err, store.ErrTrashed) {
+		http.Error(w, "Lineage is in the trash: "+state.Lineage, http.StatusConflict)
+		return
+	} else if err != nil {
+		storageFailed(w, err)
+		return
+	}
+
+	indexGraph(version, parsed.Graph)
+	evaluateAlerts(state)
+	recordAnomalies(version.Anomalies)
+	stateEvents.Publish(models.StateEvent{
+		Type:    models.StateEventVersion,
+		Lineage: version.Lineage,
+		Serial:  version.Serial,
+		At:      version.StoredAt,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, r, version)
+}
+
+// StateHistoryHandler lists the stored versions of a lineage (GET) or moves
+// the lineage to the trash (DELETE).
+func StateHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	lineage := r.PathValue("lineage")
+
+	switch r.Method {
+	case http.MethodGet:
+		versions, err := storedStates.Versions(lineage)
+		if errors.Is(err, store.ErrNotFound) {
+			http.Error(w, "Lineage not found: "+lineage, http.StatusNotFound)
+			return
+		} else if err != nil {
+			storageFailed(w, err)
+			return
+		}
+
+		writeJSON(w, r, versions)
+	case http.MethodDelete:
+		entry, err := storedStates.Delete(lineage, time.Now().UTC())
+		if errors.Is(err, store.ErrNotFound) {
+			http.Error(w, "Lineage not found: "+lineage, http.StatusNotFound)
+			return
+		} else if err != nil {
+			storageFailed(w, err)
+			return
+		}
+
+		writeJSON(w, r, entry)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// StateVersionHandler returns the raw document of a stored state version,
+// rehydrating archived versions from cold storage.
+func StateVersionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	lineage, value := r.PathValue("lineage"), r.PathValue("serial")
+
+	serial, err := strconv.Atoi(value)
+	if err != nil {
+		http.Error(w, "Invalid serial: "+value, http.StatusBadRequest)
+		return
+	}
+
+	_, data, err := storedStates.Get(lineage, serial)
+	if errors.Is(err, store.ErrArchived) {
+		var ok bool
+		if data, ok = rehydrate(w, r, lineage, serial); !ok {
+			return
+		}
+	} else if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "State not found: "+lineage+"/"+value, http.StatusNotFound)
+		return
+	} else if err != nil {
+		storageFailed(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, err := w.Write(data); err != nil {
+		log.Printf("Error writing response: %v", err)
+	}
+}
+
+// TrashHandler lists the deleted lineages that can still be restored.
+func TrashHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	trash, err := storedStates.Trash(time.Now().UTC())
+	if err != nil {
+		storageFailed(w, err)
+		return
+	}
+
+	writeJSON(w, r, trash)
+}
+
+// RestoreHandler moves a deleted lineage back from the trash.
+func RestoreHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	lineage := r.PathValue("lineage")
+
+	history, err := storedStates.Restore(lineage, time.Now().UTC())
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Lineage not in trash: "+lineage, http.StatusNotFound)
+		return
+	} else if err != nil {
+		storageFailed(w, err)
+		return
+	}
+
+	writeJSON(w, r, history)
+}
@@ -0,0 +1,192 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+func TestStateHandlers(t *testing.T) {
+	previous := storedStates
+	storedStates = store.NewStateStore(time.Hour)
+	t.Cleanup(func() { storedStates = previous })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/states", StatesHandler)
+	mux.HandleFunc("/states/{lineage}", StateHistoryHandler)
+	mux.HandleFunc("/states/{lineage}/{serial}", StateVersionHandler)
+	mux.HandleFunc("/trash", TrashHandler)
+	mux.HandleFunc("/trash/{lineage}/restore", RestoreHandler)
+
+	request := func(method, url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, url, strings.NewReader(body)))
+
+		return w
+	}
+
+	tfstate := func(serial string) string {
+		return `{"version": 4, "terraform_version": "1.5.0", "serial": ` + serial + `, "lineage": "prod", "resources": []}`
+	}
+
+	t.Run("stores and lists versions", func(t *testing.T) {
+		w := request(http.MethodPost, "/states", tfstate("1"))
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var version models.StateVersion
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&version))
+		assert.Equal(t, "prod", version.Lineage)
+		assert.Len(t, version.Digest, 64)
+
+		require.Equal(t, http.StatusCreated, request(http.MethodPost, "/states", tfstate("2")).Code)
+
+		w = request(http.MethodGet, "/states/prod", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var versions []models.StateVersion
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&versions))
+		require.Len(t, versions, 2)
+		assert.Equal(t, 2, versions[1].Serial)
+
+		w = request(http.MethodGet, "/states/prod/1", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, tfstate("1"), w.Body.String())
+	})
+
+	t.Run("delete and restore a lineage", func(t *testing.T) {
+		require.Equal(t, http.StatusCreated, request(http.MethodPost, "/states", tfstate("3")).Code)
+
+		w := request(http.MethodDelete, "/states/prod", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		assert.Equal(t, http.StatusNotFound, request(http.MethodGet, "/states/prod", "").Code)
+		assert.Equal(t, http.StatusConflict, request(http.MethodPost, "/states", tfstate("4")).Code)
+
+		w = request(http.MethodGet, "/trash", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var trash []models.TrashEntry
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&trash))
+		require.Len(t, trash, 1)
+		assert.Equal(t, "prod", trash[0].Lineage)
+
+		w = request(http.MethodPost, "/trash/prod/restore", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var history models.StateHistory
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&history))
+		assert.Equal(t, 3, history.Latest.Serial)
+
+		assert.Equal(t, http.StatusOK, request(http.MethodGet, "/states/prod", "").Code)
+	})
+
+	tests := []struct {
+		name     string
+		method   string
+		url      string
+		body     string
+		expected int
+	}{
+		{"invalid tfstate", http.MethodPost, "/states", "not json", http.StatusBadRequest},
+		{"missing lineage", http.MethodPost, "/states", `{"version": 4, "serial": 1, "resources": []}`, http.StatusBadRequest},
+		{"unknown version", http.MethodGet, "/states/prod/99", "", http.StatusNotFound},
+		{"invalid serial", http.MethodGet, "/states/prod/latest", "", http.StatusBadRequest},
+		{"delete unknown lineage", http.MethodDelete, "/states/unknown", "", http.StatusNotFound},
+		{"restore unknown lineage", http.MethodPost, "/trash/unknown/restore", "", http.StatusNotFound},
+		{"states with PUT", http.MethodPut, "/states", "", http.StatusMethodNotAllowed},
+		{"trash with POST", http.MethodPost, "/trash", "", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, request(tt.method, tt.url, tt.body).Code)
+		})
+	}
+}
+
+func TestTrashRetentionFromEnv(t *testing.T) {
+	t.Run("defaults to a week", func(t *testing.T) {
+		t.Setenv("TERRASCOPE_TRASH_RETENTION", "")
+		assert.Equal(t, defaultTrashRetention, trashRetentionFromEnv())
+	})
+
+	t.Run("reads a duration", func(t *testing.T) {
+		t.Setenv("TERRASCOPE_TRASH_RETENTION", "48h")
+		assert.Equal(t, 48*time.Hour, trashRetentionFromEnv())
+	})
+
+	t.Run("ignores invalid values", func(t *testing.T) {
+		t.Setenv("TERRASCOPE_TRASH_RETENTION", "soon")
+		assert.Equal(t, defaultTrashRetention, trashRetentionFromEnv())
+	})
+}
+
+// failingStates is a storage backend whose every call fails.
+type failingStates struct{}
+
+var errStorage = errors.New("connection refused")
+
+func (failingStates) Put(models.StateVersion, []byte) error          { return errStorage }
+func (failingStates) Lineages() ([]models.StateHistory, error)       { return nil, errStorage }
+func (failingStates) Versions(string) ([]models.StateVersion, error) { return nil, errStorage }
+func (failingStates) Get(string, int) (models.StateVersion, []byte, error) {
+	return models.StateVersion{}, nil, errStorage
+}
+func (failingStates) Delete(string, time.Time) (models.TrashEntry, error) {
+	return models.TrashEntry{}, errStorage
+}
+func (failingStates) Trash(time.Time) ([]models.TrashEntry, error) { return nil, errStorage }
+func (failingStates) Restore(string, time.Time) (models.StateHistory, error) {
+	return models.StateHistory{}, errStorage
+}
+func (failingStates) StoredBytes(time.Time) (map[string]int64, error)     { return nil, errStorage }
+func (failingStates) ProjectStoredBytes(string, time.Time) (int64, error) { return 0, errStorage }
+func (failingStates) Archive(string, int) error                           { return errStorage }
+func (failingStates) PutGraph(string, int, models.StoredGraph) error      { return errStorage }
+func (failingStates) Graph(string, int) (models.StoredGraph, error) {
+	return models.StoredGraph{}, errStorage
+}
+func (failingStates) Search(string, string, int) (models.SearchResults, error) {
+	return models.SearchResults{}, errStorage
+}
+func (failingStates) Lookup(string) ([]models.LookupMatch, error) { return nil, errStorage }
+
+func TestStateHandlersStorageErrors(t *testing.T) {
+	previous := storedStates
+	storedStates = failingStates{}
+	t.Cleanup(func() { storedStates = previous })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/states", StatesHandler)
+	mux.HandleFunc("/states/{lineage}", StateHistoryHandler)
+	mux.HandleFunc("/states/{lineage}/{serial}", StateVersionHandler)
+	mux.HandleFunc("/trash", TrashHandler)
+	mux.HandleFunc("/trash/{lineage}/restore", RestoreHandler)
+
+	for _, req := range []struct{ method, url, body string }{
+		{http.MethodGet, "/states", ""},
+		{http.MethodPost, "/states", `{"version": 4, "terraform_version": "1.5.0", "serial": 1, "lineage": "prod", "resources": []}`},
+		{http.MethodGet, "/states/prod", ""},
+		{http.MethodDelete, "/states/prod", ""},
+		{http.MethodGet, "/states/prod/1", ""},
+		{http.MethodGet, "/trash", ""},
+		{http.MethodPost, "/trash/prod/restore", ""},
+	} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(req.method, req.url, strings.NewReader(req.body)))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code, req.method+" "+req.url)
+		assert.Equal(t, "Storage error\n", w.Body.String(), "errors of the backend are not leaked")
+	}
+}
@@ -0,0 +1,122 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/terrascope/core/internal/export"
+	"github.com/terrascope/core/internal/store"
+	_ "modernc.org/sqlite"
+)
+
+// sqlDrivers names the database/sql driver of each SQL dialect. The SQLite
+// driver is always linked; the Postgres driver is linked into the server with
+// the postgres build tag.
+var sqlDrivers = map[store.Dialect]string{
+	store.DialectSQLite:   "sqlite",
+	store.DialectPostgres: "pgx",
+}
+
+// StorageDatabase opens the database of the backend selected by
+// TERRASCOPE_STORAGE, sqlite (the default) or postgres, at
+// TERRASCOPE_STORAGE_DSN, terrascope.db for SQLite by default. It returns a
+// nil database for the memory backend.
+func StorageDatabase() (*sql.DB, store.Dialect, error) {
+	name := os.Getenv("TERRASCOPE_STORAGE")
+	if name == "memory" {
+		return nil, "", nil
+	}
+	if name == "" {
+		name = string(store.DialectSQLite)
+	}
+
+	dialect, err := store.ParseDialect(name)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid TERRASCOPE_STORAGE: %w", err)
+	}
+
+	dsn := os.Getenv("TERRASCOPE_STORAGE_DSN")
+	if dialect == store.DialectSQLite {
+		if dsn == "" {
+			dsn = "terrascope.db"
+		}
+		dsn = store.SQLiteDSN(dsn)
+	}
+
+	db, err := sql.Open(sqlDrivers[dialect], dsn)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w (is the server built with -tags %s?)", err, dialect)
+	}
+
+	return db, dialect, nil
+}
+
+// OpenStorage switches the stored states, users and API keys to the backend
+// selected by TERRASCOPE_STORAGE. The users of TERRASCOPE_USERS_FILE are
+// added to the database, unless they were removed through the admin API.
+// The SQL backends keep documents in the S3 bucket of the
+// TERRASCOPE_STORAGE_BUCKET, _REGION, _ENDPOINT, _PREFIX, _ACCESS_KEY_ID,
+// _SECRET_ACCESS_KEY and _SESSION_TOKEN variables when one is set.
+//
+// Their schema is migrated to the latest version first, unless
+// TERRASCOPE_STORAGE_MIGRATE=manual leaves that to the migrate subcommand;
+// the schema must then already be at the latest version.
+func OpenStorage(ctx context.Context) error {
+	db, dialect, err := StorageDatabase()
+	if err != nil || db == nil {
+		return err
+	}
+
+	migrations, err := store.Migrations(dialect)
+	if err != nil {
+		return err
+	}
+	latest := len(migrations)
+
+	if os.Getenv("TERRASCOPE_STORAGE_MIGRATE") == "manual" {
+		version, err := store.SchemaVersion(ctx, db)
+		if err != nil {
+			return err
+		}
+		if version != latest {
+			return fmt.Errorf("schema version %d is not the latest, %d: run the migrate subcommand", version, latest)
+		}
+	} else {
+		ran, err := store.Migrate(ctx, db, dialect, latest)
+		if err != nil {
+			return err
+		}
+		for _, migration := range ran {
+			log.Printf("Migrated %s storage to schema version %d (%s)", dialect, migration.Version, migration.Name)
+		}
+	}
+
+	var blobs store.BlobStore
+	if bucket := export.S3StoreFromEnv("TERRASCOPE_STORAGE_"); bucket != nil {
+		blobs = bucket
+	}
+
+	users := store.NewSQLUsers(db, dialect)
+	if err := seedAccounts(users); err != nil {
+		return fmt.Errorf("reading TERRASCOPE_USERS_FILE: %w", err)
+	}
+
+	storedStates = store.NewSQLStateStore(db, dialect, blobs, trashRetentionFromEnv())
+	accounts = users
+	apiKeys = store.NewSQLKeys(db, dialect)
+
+	return nil
+}
+
+// storageFailed logs an error of the storage backend and writes a 500
+// response.
+func storageFailed(w http.ResponseWriter, err error) {
+	log.Printf("Storage error: %v", err)
+	http.Error(w, "Storage error", http.StatusInternalServerError)
+}
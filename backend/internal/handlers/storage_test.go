@@ -0,0 +1,84 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+func TestOpenStorage(t *testing.T) {
+	original, originalAccounts, originalKeys := storedStates, accounts, apiKeys
+	t.Cleanup(func() { storedStates, accounts, apiKeys = original, originalAccounts, originalKeys })
+
+	t.Run("keeps states in memory on request", func(t *testing.T) {
+		t.Setenv("TERRASCOPE_STORAGE", "memory")
+		require.NoError(t, OpenStorage(context.Background()))
+		assert.Same(t, original, storedStates)
+	})
+
+	t.Run("keeps states in SQLite by default", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		t.Setenv("TERRASCOPE_STORAGE", "")
+		t.Setenv("TERRASCOPE_STORAGE_DSN", "")
+		t.Cleanup(func() { storedStates = original })
+
+		require.NoError(t, OpenStorage(context.Background()))
+		require.IsType(t, &store.SQLStateStore{}, storedStates)
+		assert.FileExists(t, "terrascope.db")
+
+		require.NoError(t, storedStates.Put(models.StateVersion{Lineage: "network", Serial: 1}, []byte(`{"resources": []}`)))
+		_, data, err := storedStates.Get("network", 1)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"resources": []}`, string(data))
+	})
+
+	t.Run("keeps users and API keys in the database", func(t *testing.T) {
+		dir := t.TempDir()
+		usersFile := filepath.Join(dir, "users")
+		require.NoError(t, os.WriteFile(usersFile, []byte("alice:"+testPasswordHash+"\nbob:"+testPasswordHash+"\n"), 0o600))
+		t.Setenv("TERRASCOPE_STORAGE", "sqlite")
+		t.Setenv("TERRASCOPE_STORAGE_DSN", filepath.Join(dir, "terrascope.db"))
+		t.Setenv("TERRASCOPE_USERS_FILE", usersFile)
+		t.Cleanup(func() { storedStates, accounts, apiKeys = original, originalAccounts, originalKeys })
+
+		require.NoError(t, OpenStorage(context.Background()))
+		require.IsType(t, &store.SQLUsers{}, accounts)
+		require.IsType(t, &store.SQLKeys{}, apiKeys)
+
+		removed, err := accounts.Delete("bob")
+		require.NoError(t, err)
+		require.True(t, removed)
+		require.NoError(t, apiKeys.Create(models.APIKey{ID: "k1", Name: "ci", Role: models.RoleMember, CreatedAt: time.Now().UTC()}, "tsk_live"))
+
+		require.NoError(t, OpenStorage(context.Background()), "reopening the database seeds the users again")
+
+		users, err := accounts.List()
+		require.NoError(t, err)
+		require.Len(t, users, 1, "users removed through the admin API stay removed")
+		assert.Equal(t, "alice", users[0].Username)
+
+		_, ok, err := apiKeys.Use("tsk_live", time.Now().UTC())
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("rejects unknown backends", func(t *testing.T) {
+		t.Setenv("TERRASCOPE_STORAGE", "mysql")
+		assert.ErrorContains(t, OpenStorage(context.Background()), "invalid TERRASCOPE_STORAGE")
+	})
+
+	t.Run("needs the driver to be linked", func(t *testing.T) {
+		t.Setenv("TERRASCOPE_STORAGE", "postgres")
+		assert.ErrorContains(t, OpenStorage(context.Background()), "-tags postgres")
+		assert.Same(t, original, storedStates)
+	})
+}
@@ -0,0 +1,128 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+	"github.com/terrascope/core/internal/store"
+)
+
+// storedGraphsLimit bounds how many graphs ParseHandler keeps for
+// SubgraphHandler.
+const storedGraphsLimit = 32
+
+// defaultSubgraphDepth is the neighborhood depth when ?depth= is not given.
+const defaultSubgraphDepth = 1
+
+// storedGraphs holds the graphs recently returned by ParseHandler, keyed by
+// digest.
+var storedGraphs = storedGraphsFromEnv()
+
+func storedGraphsFromEnv() store.GraphBackend {
+	if shared != nil {
+		return store.NewSharedGraphs(shared)
+	}
+
+	return store.NewGraphStore(storedGraphsLimit)
+}
+
+// SubgraphHandler returns the neighborhood of ?root= in a graph recently
+// built by ParseHandler, identified by its digest in ?graph=, following edges
+// up to ?depth= hops in ?direction= (dependencies, dependents or both). The
+// root may be a node ID or a Terraform resource or instance address. With
+// ?reduce=transitive the edges implied by longer paths are left out, and with
+// ?with_degrees=true every node carries its degrees in the full graph.
+func SubgraphHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	digest, root := r.URL.Query().Get("graph"), r.URL.Query().Get("root")
+	if digest == "" || root == "" {
+		http.Error(w, "Missing graph or root", http.StatusBadRequest)
+		return
+	}
+
+	neighborhood, ok := subgraphRequest(w, r)
+	if !ok {
+		return
+	}
+
+	graph, ok := storedGraphs.Get(digest)
+	if !ok {
+		http.Error(w, "Graph not found: "+digest, http.StatusNotFound)
+		return
+	}
+
+	writeSubgraph(w, r, graph, neighborhood)
+}
+
+// subgraph is the neighborhood a request selects with ?root=, ?depth=,
+// ?direction= and ?reduce=.
+type subgraph struct {
+	root        string
+	depth       int
+	direction   string
+	reduce      bool
+	reduceDepth int
+}
+
+// subgraphRequest reads the neighborhood a request selects. On failure it
+// writes a 400 response and returns false.
+func subgraphRequest(w http.ResponseWriter, r *http.Request) (subgraph, bool) {
+	query := r.URL.Query()
+
+	depth := defaultSubgraphDepth
+	if value := query.Get("depth"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			http.Error(w, "Invalid depth: "+value, http.StatusBadRequest)
+			return subgraph{}, false
+		}
+		depth = n
+	}
+
+	direction := query.Get("direction")
+	switch direction {
+	case "":
+		direction = parser.DirectionBoth
+	case parser.DirectionDependencies, parser.DirectionDependents, parser.DirectionBoth:
+	default:
+		http.Error(w, "Unknown direction: "+direction, http.StatusBadRequest)
+		return subgraph{}, false
+	}
+
+	reduceDepth, reduce, ok := graphReduction(w, r)
+	if !ok {
+		return subgraph{}, false
+	}
+
+	return subgraph{root: query.Get("root"), depth: depth, direction: direction, reduce: reduce, reduceDepth: reduceDepth}, true
+}
+
+// writeSubgraph writes the neighborhood of a graph. With ?with_degrees=true
+// every node carries its degrees in the full graph.
+func writeSubgraph(w http.ResponseWriter, r *http.Request, graph *models.Graph, neighborhood subgraph) {
+	roots := parser.ResolveNodes(graph, neighborhood.root)
+	if len(roots) == 0 {
+		http.Error(w, "Node not found: "+neighborhood.root, http.StatusNotFound)
+		return
+	}
+
+	result := parser.Neighborhood(graph, roots, neighborhood.depth, neighborhood.direction)
+	if neighborhood.reduce {
+		result = parser.ReduceTransitive(result, neighborhood.reduceDepth)
+	}
+	result.Stats = parser.ComputeStats(result)
+
+	if r.URL.Query().Get("with_degrees") == "true" {
+		result = parser.WithDegrees(result, parser.ComputeDegrees(graph))
+	}
+
+	writeGraph(w, r, result)
+}
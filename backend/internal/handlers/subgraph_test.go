@@ -0,0 +1,160 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+func TestSubgraphHandler(t *testing.T) {
+	previous := storedGraphs
+	storedGraphs = store.NewGraphStore(4)
+	t.Cleanup(func() { storedGraphs = previous })
+
+	tfstate := `{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_vpc",
+				"name": "main",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "vpc-1"}}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_subnet",
+				"name": "a",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "subnet-1"}, "dependencies": ["aws_vpc.main"]}]
+			},
+			{
+				"module": "module.app",
+				"mode": "managed",
+				"type": "aws_instance",
+				"name": "web",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "i-1"}, "dependencies": ["aws_subnet.a"]}]
+			}
+		]
+	}`
+
+	parsed := httptest.NewRecorder()
+	ParseHandler(parsed, httptest.NewRequest(http.MethodPost, "/parse", strings.NewReader(tfstate)))
+	require.Equal(t, http.StatusOK, parsed.Code)
+	digest := parsed.Header().Get("X-Graph-Digest")
+
+	subgraph := func(query url.Values) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		SubgraphHandler(w, httptest.NewRequest(http.MethodGet, "/graph/subgraph?"+query.Encode(), nil))
+
+		return w
+	}
+
+	decode := func(t *testing.T, w *httptest.ResponseRecorder) []string {
+		t.Helper()
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var graph models.Graph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+
+		ids := []string{}
+		for _, node := range graph.Nodes {
+			ids = append(ids, node.ID)
+		}
+
+		return ids
+	}
+
+	t.Run("neighborhood of a node", func(t *testing.T) {
+		w := subgraph(url.Values{"graph": {digest}, "root": {"aws_subnet.a"}})
+
+		assert.NotEmpty(t, w.Header().Get("X-Graph-Digest"))
+		assert.Equal(t, []string{"aws_vpc.main", "aws_subnet.a", "module.app.aws_instance.web"}, decode(t, w))
+	})
+
+	t.Run("depth and direction", func(t *testing.T) {
+		w := subgraph(url.Values{"graph": {digest}, "root": {"module.app.aws_instance.web"}, "depth": {"3"}, "direction": {"dependencies"}})
+
+		assert.Equal(t, []string{"aws_vpc.main", "aws_subnet.a", "module.app.aws_instance.web"}, decode(t, w))
+
+		w = subgraph(url.Values{"graph": {digest}, "root": {"aws_vpc.main"}, "depth": {"1"}, "direction": {"dependents"}})
+
+		assert.Equal(t, []string{"aws_vpc.main", "aws_subnet.a"}, decode(t, w))
+	})
+
+	t.Run("degrees in the full graph", func(t *testing.T) {
+		w := subgraph(url.Values{"graph": {digest}, "root": {"aws_vpc.main"}, "depth": {"0"}, "with_degrees": {"true"}})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var graph models.Graph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+
+		require.Len(t, graph.Nodes, 1)
+		assert.Equal(t, &models.NodeDegrees{InDegree: 1, TransitiveDependentsCount: 2}, graph.Nodes[0].Degrees)
+	})
+
+	t.Run("summarized graphs link to the full graph", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ParseHandler(w, httptest.NewRequest(http.MethodPost, "/parse?max_nodes=1", strings.NewReader(tfstate)))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var summary models.Graph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&summary))
+		require.True(t, summary.Summarized)
+
+		assert.Equal(t, digest, summary.Summary.Digest)
+		assert.NotEqual(t, digest, w.Header().Get("X-Graph-Digest"))
+		assert.Len(t, decode(t, subgraph(url.Values{"graph": {summary.Summary.Digest}, "root": {"aws_vpc.main"}})), 2)
+	})
+
+	t.Run("renders formats", func(t *testing.T) {
+		w := subgraph(url.Values{"graph": {digest}, "root": {"aws_vpc.main"}, "format": {"dot"}})
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "digraph")
+	})
+
+	tests := []struct {
+		name     string
+		query    url.Values
+		status   int
+		contains string
+	}{
+		{"missing root", url.Values{"graph": {digest}}, http.StatusBadRequest, "Missing graph or root"},
+		{"missing graph", url.Values{"root": {"aws_vpc.main"}}, http.StatusBadRequest, "Missing graph or root"},
+		{"invalid depth", url.Values{"graph": {digest}, "root": {"aws_vpc.main"}, "depth": {"-1"}}, http.StatusBadRequest, "Invalid depth: -1"},
+		{"unknown direction", url.Values{"graph": {digest}, "root": {"aws_vpc.main"}, "direction": {"up"}}, http.StatusBadRequest, "Unknown direction: up"},
+		{"unknown graph", url.Values{"graph": {"sha256:missing"}, "root": {"aws_vpc.main"}}, http.StatusNotFound, "Graph not found"},
+		{"unknown node", url.Values{"graph": {digest}, "root": {"aws_vpc.other"}}, http.StatusNotFound, "Node not found: aws_vpc.other"},
+		{"unknown format", url.Values{"graph": {digest}, "root": {"aws_vpc.main"}, "format": {"gif"}}, http.StatusBadRequest, "Unknown format: gif"},
+		{"unknown reduce", url.Values{"graph": {digest}, "root": {"aws_vpc.main"}, "reduce": {"all"}}, http.StatusBadRequest, "Unknown reduce: all"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := subgraph(tt.query)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.contains)
+		})
+	}
+
+	t.Run("method not allowed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		SubgraphHandler(w, httptest.NewRequest(http.MethodPost, "/graph/subgraph", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
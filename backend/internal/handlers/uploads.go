@@ -0,0 +1,302 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+// Limits of chunked uploads. Parts stay small enough to pass proxies that cut
+// long requests, and the number of uploads spooled at once is bounded, also
+// for each client so that one client cannot hold every slot.
+const (
+	maxUploadPartSize   = 8 << 20
+	maxUploadSize       = 1 << 30
+	maxUploadParts      = 10000
+	maxActiveUploads    = 8
+	maxUploadsPerClient = 2
+	uploadIdleTimeout   = 30 * time.Minute
+)
+
+// pendingUploads spools the parts of chunked uploads to
+// TERRASCOPE_UPLOADS_DIR, by default a directory of the system's temporary
+// directory, until they are completed.
+var pendingUploads = store.NewUploadStore(uploadsDir(), maxActiveUploads, maxUploadsPerClient, uploadIdleTimeout)
+
+func uploadsDir() string {
+	if dir := os.Getenv("TERRASCOPE_UPLOADS_DIR"); dir != "" {
+		return dir
+	}
+
+	return filepath.Join(os.TempDir(), "terrascope-uploads")
+}
+
+// uploadTargets are the endpoints a completed upload can be handed to, by
+// the name given when the upload is started. Only parses are open to
+// anonymous clients once login is enabled.
+var uploadTargets = map[string]http.HandlerFunc{
+	"parse":    MeterParses(ParseHandler),
+	"states":   RequireSession(storeState),
+	"validate": RequireSession(ValidateHandler),
+	"sanitize": RequireSession(SanitizeHandler),
+}
+
+// UploadsHandler starts a chunked upload. The client then PUTs the document
+// in numbered parts to /uploads/{id}/parts/{part} and completes the upload,
+// which hands the assembled document to the target endpoint. Uploads count
+// against the request's project: a project may have only a few in progress,
+// and none to parse once it has used up its parses. Anonymous clients share
+// the default project, so their uploads in progress are limited by address
+// instead. Uploads to targets other than parse require a login session once
+// login is enabled.
+func UploadsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	var req models.UploadRequest
+	if err := json.Unmarshal(body.Bytes(), &req); err != nil {
+		http.Error(w, "Invalid upload: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Target == "" {
+		req.Target = "parse"
+	}
+
+	if _, ok := uploadTargets[req.Target]; !ok {
+		http.Error(w, "Unknown target: "+req.Target, http.StatusBadRequest)
+		return
+	}
+
+	if req.Size < 0 {
+		http.Error(w, "Invalid upload: negative size", http.StatusBadRequest)
+		return
+	}
+
+	if req.Size > maxUploadSize {
+		http.Error(w, "Upload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if req.SHA256 != "" {
+		req.SHA256 = strings.ToLower(req.SHA256)
+		if sum, err := hex.DecodeString(req.SHA256); err != nil || len(sum) != sha256.Size {
+			http.Error(w, "Invalid upload: sha256 must be 64 hexadecimal digits", http.StatusBadRequest)
+			return
+		}
+	}
+
+	start := func(w http.ResponseWriter, r *http.Request) {
+		project := requestProject(r)
+		if req.Target == "parse" && !checkQuota(w, project, models.Usage{Parses: 1}) {
+			return
+		}
+
+		upload, ok := pendingUploads.Create(models.Upload{
+			ID:          newID(),
+			Target:      req.Target,
+			Project:     project,
+			Size:        req.Size,
+			SHA256:      req.SHA256,
+			MaxPartSize: maxUploadPartSize,
+		}, uploadClient(r, project), time.Now().UTC())
+		if !ok {
+			http.Error(w, "Too many uploads in progress", http.StatusTooManyRequests)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, r, upload)
+	}
+
+	if req.Target != "parse" {
+		start = RequireSession(start)
+	}
+
+	start(w, r)
+}
+
+// uploadClient returns who an upload counts against for the limit of uploads
+// in progress: the request's project, or the client's address for anonymous
+// requests, which all share the default project. The address is that of the
+// connection, since forwarding headers are set by the clients themselves.
+func uploadClient(r *http.Request, project string) string {
+	if project != defaultProject {
+		return project
+	}
+	if _, ok := requestAPIKey(r); ok {
+		return project
+	}
+	if _, ok, _ := currentUser(r); ok {
+		return project
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return "address " + host
+}
+
+// UploadHandler returns an upload with the parts received so far, so that an
+// interrupted client can resume it (GET), or abandons it (DELETE).
+func UploadHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	switch r.Method {
+	case http.MethodGet:
+		upload, ok := pendingUploads.Get(id, time.Now().UTC())
+		if !ok {
+			http.Error(w, "Upload not found: "+id, http.StatusNotFound)
+			return
+		}
+
+		writeJSON(w, r, upload)
+	case http.MethodDelete:
+		if !pendingUploads.Delete(id) {
+			http.Error(w, "Upload not found: "+id, http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// UploadPartHandler stores a part of an upload. Parts are numbered from 1 and
+// may arrive in any order; sending a part again replaces it. The part may
+// carry its checksum in the X-Content-SHA256 header.
+func UploadPartHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, value := r.PathValue("id"), r.PathValue("part")
+
+	part, err := strconv.Atoi(value)
+	if err != nil || part < 1 || part > maxUploadParts {
+		http.Error(w, "Invalid part: "+value, http.StatusBadRequest)
+		return
+	}
+
+	if r.ContentLength > maxUploadPartSize {
+		http.Error(w, "Part too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadPartSize)
+
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	upload, err := pendingUploads.PutPart(id, part, bytes.Clone(body.Bytes()), maxUploadSize, time.Now().UTC())
+	switch {
+	case errors.Is(err, store.ErrUploadNotFound):
+		http.Error(w, "Upload not found: "+id, http.StatusNotFound)
+		return
+	case errors.Is(err, store.ErrUploadTooLarge):
+		http.Error(w, "Upload too large", http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
+		storageFailed(w, err)
+		return
+	}
+
+	writeJSON(w, r, upload)
+}
+
+// UploadCompleteHandler assembles the parts of an upload, checks the result
+// against the size and checksum announced when the upload started, and
+// serves the request as the upload's target endpoint would have served the
+// whole document, streamed from the spooled parts. Query parameters are
+// passed on to the target.
+func UploadCompleteHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.PathValue("id")
+
+	document, upload, err := pendingUploads.Assemble(id, time.Now().UTC())
+	if errors.Is(err, store.ErrUploadNotFound) {
+		http.Error(w, "Upload not found: "+id, http.StatusNotFound)
+		return
+	}
+	var spoolErr *fs.PathError
+	if errors.As(err, &spoolErr) {
+		storageFailed(w, err)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Incomplete upload: "+err.Error(), http.StatusConflict)
+		return
+	}
+
+	defer document.Close()
+
+	if upload.Size > 0 && upload.Received != upload.Size {
+		http.Error(w, "Incomplete upload: received "+strconv.FormatInt(upload.Received, 10)+" of "+strconv.FormatInt(upload.Size, 10)+" bytes", http.StatusConflict)
+		return
+	}
+
+	if upload.SHA256 != "" {
+		hash := sha256.New()
+		if _, err := io.Copy(hash, document); err != nil {
+			storageFailed(w, err)
+			return
+		}
+
+		if hex.EncodeToString(hash.Sum(nil)) != upload.SHA256 {
+			http.Error(w, "Upload checksum mismatch", http.StatusBadRequest)
+			return
+		}
+
+		if err := document.Rewind(); err != nil {
+			storageFailed(w, err)
+			return
+		}
+	}
+
+	// The parts stay readable through the open files once the upload is
+	// deleted.
+	pendingUploads.Delete(id)
+
+	target := r.Clone(r.Context())
+	target.Body = io.NopCloser(document)
+	target.ContentLength = upload.Received
+	target.Header.Del(contentChecksumHeader)
+
+	uploadTargets[upload.Target](w, target)
+}
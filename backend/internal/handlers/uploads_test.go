@@ -0,0 +1,204 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/store"
+)
+
+func TestUploadHandlers(t *testing.T) {
+	previousUploads, previousStates := pendingUploads, storedStates
+	pendingUploads = store.NewUploadStore(t.TempDir(), 2, 2, time.Hour)
+	storedStates = store.NewStateStore(time.Hour)
+	t.Cleanup(func() { pendingUploads, storedStates = previousUploads, previousStates })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/uploads", UploadsHandler)
+	mux.HandleFunc("/uploads/{id}", UploadHandler)
+	mux.HandleFunc("/uploads/{id}/parts/{part}", UploadPartHandler)
+	mux.HandleFunc("/uploads/{id}/complete", UploadCompleteHandler)
+
+	request := func(method, url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, url, strings.NewReader(body)))
+
+		return w
+	}
+
+	start := func(t *testing.T, req models.UploadRequest) models.Upload {
+		body, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		w := request(http.MethodPost, "/uploads", string(body))
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var upload models.Upload
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&upload))
+
+		return upload
+	}
+
+	tfstate := `{"version": 4, "terraform_version": "1.5.0", "serial": 3, "lineage": "prod", "resources": [
+		{"mode": "managed", "type": "aws_vpc", "name": "main", "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+		 "instances": [{"attributes": {"id": "vpc-1"}}]}
+	]}`
+	sum := sha256.Sum256([]byte(tfstate))
+
+	t.Run("parses the assembled document", func(t *testing.T) {
+		upload := start(t, models.UploadRequest{Size: int64(len(tfstate)), SHA256: hex.EncodeToString(sum[:])})
+		assert.Equal(t, "parse", upload.Target)
+		assert.Equal(t, int64(maxUploadPartSize), upload.MaxPartSize)
+
+		prefix := "/uploads/" + upload.ID
+		require.Equal(t, http.StatusOK, request(http.MethodPut, prefix+"/parts/2", tfstate[100:]).Code)
+		require.Equal(t, http.StatusOK, request(http.MethodPut, prefix+"/parts/1", tfstate[:100]).Code)
+
+		w := request(http.MethodGet, prefix, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&upload))
+		assert.Equal(t, []int{1, 2}, upload.Parts)
+
+		w = request(http.MethodPost, prefix+"/complete", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var graph models.Graph
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&graph))
+		assert.Len(t, graph.Nodes, 1)
+
+		assert.Equal(t, http.StatusNotFound, request(http.MethodGet, prefix, "").Code)
+	})
+
+	t.Run("stores the assembled document", func(t *testing.T) {
+		upload := start(t, models.UploadRequest{Target: "states"})
+
+		prefix := "/uploads/" + upload.ID
+		require.Equal(t, http.StatusOK, request(http.MethodPut, prefix+"/parts/1", tfstate).Code)
+
+		w := request(http.MethodPost, prefix+"/complete", "")
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		_, _, err := storedStates.Get("prod", 3)
+		assert.NoError(t, err)
+	})
+
+	t.Run("keeps incomplete uploads for resuming", func(t *testing.T) {
+		upload := start(t, models.UploadRequest{Size: int64(len(tfstate)), SHA256: hex.EncodeToString(sum[:])})
+		t.Cleanup(func() { pendingUploads.Delete(upload.ID) })
+
+		prefix := "/uploads/" + upload.ID
+		request(http.MethodPut, prefix+"/parts/1", tfstate[:100])
+		request(http.MethodPut, prefix+"/parts/3", tfstate[200:])
+
+		w := request(http.MethodPost, prefix+"/complete", "")
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "missing part 2")
+
+		request(http.MethodPut, prefix+"/parts/2", strings.ToUpper(tfstate[100:200]))
+
+		w = request(http.MethodPost, prefix+"/complete", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "checksum mismatch")
+
+		request(http.MethodPut, prefix+"/parts/2", tfstate[100:200])
+		assert.Equal(t, http.StatusOK, request(http.MethodPost, prefix+"/complete", "").Code)
+	})
+
+	t.Run("limits uploads in progress", func(t *testing.T) {
+		first := start(t, models.UploadRequest{})
+		second := start(t, models.UploadRequest{})
+
+		assert.Equal(t, http.StatusTooManyRequests, request(http.MethodPost, "/uploads", `{}`).Code)
+
+		assert.Equal(t, http.StatusNoContent, request(http.MethodDelete, "/uploads/"+first.ID, "").Code)
+		assert.Equal(t, http.StatusNoContent, request(http.MethodDelete, "/uploads/"+second.ID, "").Code)
+	})
+
+	t.Run("limits uploads in progress per project", func(t *testing.T) {
+		withQuotas(t, models.QuotaConfig{Projects: map[string]models.Usage{"web": {}}})
+		previousUploads := pendingUploads
+		pendingUploads = store.NewUploadStore(t.TempDir(), 2, 1, time.Hour)
+		t.Cleanup(func() { pendingUploads = previousUploads })
+
+		startAs := func(project string) int {
+			w := httptest.NewRecorder()
+			UploadsHandler(w, withProjectKey(httptest.NewRequest(http.MethodPost, "/uploads", strings.NewReader(`{}`)), project))
+			return w.Code
+		}
+
+		assert.Equal(t, http.StatusCreated, startAs("web"))
+		assert.Equal(t, http.StatusTooManyRequests, startAs("web"))
+		assert.Equal(t, http.StatusCreated, startAs("ci"))
+	})
+
+	t.Run("limits anonymous uploads in progress per address", func(t *testing.T) {
+		previousUploads := pendingUploads
+		pendingUploads = store.NewUploadStore(t.TempDir(), 3, 1, time.Hour)
+		t.Cleanup(func() { pendingUploads = previousUploads })
+
+		startFrom := func(address string) int {
+			req := httptest.NewRequest(http.MethodPost, "/uploads", strings.NewReader(`{}`))
+			req.RemoteAddr = address
+			w := httptest.NewRecorder()
+			UploadsHandler(w, req)
+			return w.Code
+		}
+
+		assert.Equal(t, http.StatusCreated, startFrom("192.0.2.1:4000"))
+		assert.Equal(t, http.StatusTooManyRequests, startFrom("192.0.2.1:4001"))
+		assert.Equal(t, http.StatusCreated, startFrom("192.0.2.2:4000"), "other anonymous clients are not blocked")
+	})
+
+	t.Run("refuses parses once the project used them up", func(t *testing.T) {
+		withQuotas(t, models.QuotaConfig{Default: models.Usage{Parses: 1}})
+		meter.Record(defaultProject, models.Usage{Parses: 1}, time.Now().UTC())
+
+		w := request(http.MethodPost, "/uploads", `{}`)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Contains(t, w.Body.String(), "Quota exceeded")
+	})
+
+	t.Run("requires a session for targets other than parse", func(t *testing.T) {
+		withUsers(t)
+
+		assert.Equal(t, http.StatusUnauthorized, request(http.MethodPost, "/uploads", `{"target": "validate"}`).Code)
+
+		upload := start(t, models.UploadRequest{})
+		assert.True(t, pendingUploads.Delete(upload.ID), "parses stay open to anonymous clients")
+	})
+
+	t.Run("rejects oversized parts", func(t *testing.T) {
+		upload := start(t, models.UploadRequest{})
+		t.Cleanup(func() { pendingUploads.Delete(upload.ID) })
+
+		w := request(http.MethodPut, "/uploads/"+upload.ID+"/parts/1", strings.Repeat(" ", maxUploadPartSize+1))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	for _, tc := range []struct {
+		name, method, url, body string
+		status                  int
+	}{
+		{"unknown target", http.MethodPost, "/uploads", `{"target": "export"}`, http.StatusBadRequest},
+		{"oversized upload", http.MethodPost, "/uploads", `{"size": 2147483648}`, http.StatusRequestEntityTooLarge},
+		{"invalid checksum", http.MethodPost, "/uploads", `{"sha256": "abc"}`, http.StatusBadRequest},
+		{"invalid part number", http.MethodPut, "/uploads/unknown/parts/0", "", http.StatusBadRequest},
+		{"unknown upload", http.MethodPut, "/uploads/unknown/parts/1", "{}", http.StatusNotFound},
+		{"completion of unknown upload", http.MethodPost, "/uploads/unknown/complete", "", http.StatusNotFound},
+	} {
+		t.Run("rejects "+tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.status, request(tc.method, tc.url, tc.body).Code)
+		})
+	}
+}
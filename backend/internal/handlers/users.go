@@ -0,0 +1,287 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/terrascope/core/internal/auth"
+	"github.com/terrascope/core/internal/models"
+)
+
+// invitationTTL is how long an invited user has to choose a password.
+const invitationTTL = 7 * 24 * time.Hour
+
+// minPasswordLength is the shortest password an invited user may choose.
+const minPasswordLength = 8
+
+// lastAdmin reports whether the user is the only admin who can sign in, whom
+// removing or demoting would leave the users without an admin.
+func lastAdmin(user models.User) (bool, error) {
+	if user.Role != models.RoleAdmin || user.Invited {
+		return false, nil
+	}
+
+	n, err := accounts.Admins()
+	return n == 1, err
+}
+
+// validRole reports whether the role can be assigned to users and API keys.
+func validRole(role string) bool {
+	return role == models.RoleMember || role == models.RoleAdmin
+}
+
+// AdminUsersHandler lists the users allowed to sign in, or invites a new one.
+// The invitation token is returned once, for the admin to pass on.
+func AdminUsersHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		users, err := accounts.List()
+		if err != nil {
+			storageFailed(w, err)
+			return
+		}
+		writeJSON(w, r, users)
+		return
+	}
+
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	var req models.InviteRequest
+	if err := json.Unmarshal(body.Bytes(), &req); err != nil {
+		http.Error(w, "Invalid invitation: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Username == "" || strings.ContainsAny(req.Username, ": \t\r\n") {
+		http.Error(w, "Invalid username: "+req.Username, http.StatusBadRequest)
+		return
+	}
+
+	if req.Role == "" {
+		req.Role = models.RoleMember
+	}
+	if !validRole(req.Role) {
+		http.Error(w, "Unknown role: "+req.Role, http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now().UTC()
+	invitation := models.Invitation{
+		User:      models.User{Username: req.Username, Role: req.Role, Invited: true, CreatedAt: now},
+		Token:     randomToken(),
+		ExpiresAt: now.Add(invitationTTL),
+	}
+
+	invited, err := accounts.Invite(invitation.User, invitation.Token, invitation.ExpiresAt)
+	if err != nil {
+		storageFailed(w, err)
+		return
+	}
+	if !invited {
+		http.Error(w, "User already exists: "+req.Username, http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, r, invitation)
+}
+
+// AdminUserHandler returns or removes a user. Removed users are signed out of
+// their sessions. The last user cannot be removed, since that would disable
+// login and open the stored states to everyone, and neither can the last
+// admin.
+func AdminUserHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	username := r.PathValue("username")
+
+	user, ok, err := accounts.Get(username)
+	if err != nil {
+		storageFailed(w, err)
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		if ok {
+			n, err := accounts.Len()
+			if err != nil {
+				storageFailed(w, err)
+				return
+			}
+			if n == 1 {
+				http.Error(w, "Cannot remove the last user", http.StatusConflict)
+				return
+			}
+
+			last, err := lastAdmin(user)
+			if err != nil {
+				storageFailed(w, err)
+				return
+			}
+			if last {
+				http.Error(w, "Cannot remove the last admin", http.StatusConflict)
+				return
+			}
+		}
+		removed, err := accounts.Delete(username)
+		if err != nil {
+			storageFailed(w, err)
+			return
+		}
+		if !removed {
+			http.Error(w, "User not found: "+username, http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !ok {
+		http.Error(w, "User not found: "+username, http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, r, user)
+}
+
+// AdminUserRoleHandler assigns a role to a user. It applies to the user's
+// current sessions. The last admin cannot be made a member.
+func AdminUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	var req models.RoleRequest
+	if err := json.Unmarshal(body.Bytes(), &req); err != nil {
+		http.Error(w, "Invalid role: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !validRole(req.Role) {
+		http.Error(w, "Unknown role: "+req.Role, http.StatusBadRequest)
+		return
+	}
+
+	username := r.PathValue("username")
+
+	if req.Role != models.RoleAdmin {
+		user, _, err := accounts.Get(username)
+		if err != nil {
+			storageFailed(w, err)
+			return
+		}
+
+		last, err := lastAdmin(user)
+		if err != nil {
+			storageFailed(w, err)
+			return
+		}
+		if last {
+			http.Error(w, "Cannot demote the last admin", http.StatusConflict)
+			return
+		}
+	}
+
+	user, ok, err := accounts.SetRole(username, req.Role)
+	if err != nil {
+		storageFailed(w, err)
+		return
+	}
+	if !ok {
+		http.Error(w, "User not found: "+username, http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, r, user)
+}
+
+// AcceptInvitationHandler lets an invited user choose their password, after
+// which they can sign in.
+func AcceptInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	var req models.AcceptInvitationRequest
+	if err := json.Unmarshal(body.Bytes(), &req); err != nil {
+		http.Error(w, "Invalid invitation: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Password) < minPasswordLength {
+		http.Error(w, "Password must be at least 8 characters", http.StatusBadRequest)
+		return
+	}
+
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		http.Error(w, "Failed to hash password", http.StatusInternalServerError)
+		return
+	}
+
+	user, ok, err := accounts.Accept(req.Token, hash, time.Now().UTC())
+	if err != nil {
+		storageFailed(w, err)
+		return
+	}
+	if !ok {
+		http.Error(w, "Invitation not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, r, user)
+}
+
+// randomToken returns a random hex token for invitations and API keys.
+func randomToken() string {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(random)
+}
@@ -0,0 +1,160 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+// adminMux routes the admin endpoints for a test, authorized by the admin
+// token s3cret.
+func adminMux(t *testing.T) *http.ServeMux {
+	t.Helper()
+
+	previous := adminToken
+	adminToken = "s3cret"
+	t.Cleanup(func() { adminToken = previous })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin/users", AdminUsersHandler)
+	mux.HandleFunc("/admin/users/{username}", AdminUserHandler)
+	mux.HandleFunc("/admin/users/{username}/role", AdminUserRoleHandler)
+	mux.HandleFunc("/admin/keys", AdminKeysHandler)
+	mux.HandleFunc("/admin/keys/{id}", AdminKeyHandler)
+
+	return mux
+}
+
+func adminRequest(mux http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer s3cret")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestAdminUsersHandlers(t *testing.T) {
+	withUsers(t)
+	mux := adminMux(t)
+
+	var invitation models.Invitation
+
+	t.Run("invites users", func(t *testing.T) {
+		w := adminRequest(mux, http.MethodPost, "/admin/users", `{"username": "bob"}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&invitation))
+		assert.Equal(t, "bob", invitation.User.Username)
+		assert.Equal(t, models.RoleMember, invitation.User.Role)
+		assert.True(t, invitation.User.Invited)
+		assert.NotEmpty(t, invitation.Token)
+
+		w = adminRequest(mux, http.MethodPost, "/admin/users", `{"username": "bob"}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("rejects invalid invitations", func(t *testing.T) {
+		for _, body := range []string{`{}`, `{"username": "a:b"}`, `{"username": "carol", "role": "owner"}`, `{invalid`} {
+			w := adminRequest(mux, http.MethodPost, "/admin/users", body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+
+	t.Run("invited users choose a password and sign in", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, login(t, `{"username": "bob", "password": ""}`).Code)
+
+		accept := func(body string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			AcceptInvitationHandler(w, httptest.NewRequest(http.MethodPost, "/auth/accept", strings.NewReader(body)))
+			return w
+		}
+
+		assert.Equal(t, http.StatusBadRequest, accept(`{"token": "`+invitation.Token+`", "password": "short"}`).Code)
+		assert.Equal(t, http.StatusNotFound, accept(`{"token": "wrong", "password": "correct horse"}`).Code)
+
+		w := accept(`{"token": "` + invitation.Token + `", "password": "correct horse"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "invited")
+
+		assert.Equal(t, http.StatusOK, login(t, `{"username": "bob", "password": "correct horse"}`).Code)
+	})
+
+	t.Run("lists users", func(t *testing.T) {
+		w := adminRequest(mux, http.MethodGet, "/admin/users", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var users []models.User
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&users))
+		require.Len(t, users, 2)
+		assert.Equal(t, "alice", users[0].Username)
+		assert.Equal(t, "bob", users[1].Username)
+	})
+
+	t.Run("assigns roles", func(t *testing.T) {
+		w := adminRequest(mux, http.MethodPut, "/admin/users/bob/role", `{"role": "admin"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"role":"admin"`)
+
+		w = adminRequest(mux, http.MethodPut, "/admin/users/bob/role", `{"role": "owner"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = adminRequest(mux, http.MethodPut, "/admin/users/nobody/role", `{"role": "admin"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("signed-in admins use the admin endpoints", func(t *testing.T) {
+		cookie := login(t, `{"username": "bob", "password": "correct horse"}`).Result().Cookies()[0]
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/users/alice", nil)
+		req.AddCookie(cookie)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		cookie = login(t, `{"username": "alice", "password": "secret"}`).Result().Cookies()[0]
+
+		req = httptest.NewRequest(http.MethodGet, "/admin/users/alice", nil)
+		req.AddCookie(cookie)
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "members need the admin token")
+	})
+
+	t.Run("keeps the last admin", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, adminRequest(mux, http.MethodPut, "/admin/users/bob/role", `{"role": "member"}`).Code)
+		assert.Equal(t, http.StatusConflict, adminRequest(mux, http.MethodDelete, "/admin/users/bob", "").Code)
+		assert.Equal(t, http.StatusOK, adminRequest(mux, http.MethodPut, "/admin/users/bob/role", `{"role": "admin"}`).Code)
+
+		require.Equal(t, http.StatusOK, adminRequest(mux, http.MethodPut, "/admin/users/alice/role", `{"role": "admin"}`).Code)
+		assert.Equal(t, http.StatusOK, adminRequest(mux, http.MethodPut, "/admin/users/bob/role", `{"role": "member"}`).Code)
+		assert.Equal(t, http.StatusConflict, adminRequest(mux, http.MethodPut, "/admin/users/alice/role", `{"role": "member"}`).Code)
+	})
+
+	t.Run("removes users but not the last one", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, adminRequest(mux, http.MethodDelete, "/admin/users/bob", "").Code)
+		assert.Equal(t, http.StatusNotFound, adminRequest(mux, http.MethodGet, "/admin/users/bob", "").Code)
+		assert.Equal(t, http.StatusNotFound, adminRequest(mux, http.MethodDelete, "/admin/users/bob", "").Code)
+		assert.Equal(t, http.StatusConflict, adminRequest(mux, http.MethodDelete, "/admin/users/alice", "").Code)
+	})
+
+	t.Run("requires the admin scope", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/users", nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		assert.Equal(t, http.StatusMethodNotAllowed, adminRequest(mux, http.MethodPut, "/admin/users", "").Code)
+		assert.Equal(t, http.StatusMethodNotAllowed, adminRequest(mux, http.MethodPost, "/admin/users/alice", "").Code)
+		assert.Equal(t, http.StatusMethodNotAllowed, adminRequest(mux, http.MethodGet, "/admin/users/alice/role", "").Code)
+	})
+}
@@ -0,0 +1,33 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/terrascope/core/internal/parser"
+)
+
+// ValidateHandler checks the structure of a tfstate without building a graph.
+// Invalid states are answered with 422 so CI can gate on the status code; the
+// body carries the report either way.
+func ValidateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, ok := readRequestBody(w, r)
+	if !ok {
+		return
+	}
+	defer releaseBody(body)
+
+	report := parser.Validate(body.Bytes())
+	if !report.Valid {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+
+	writeJSON(w, r, report)
+}
@@ -0,0 +1,67 @@
+// Package handlers provides HTTP request handlers for the API endpoints.
+// It defines the routing logic, response formatting, and error handling mechanisms.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestValidateHandler(t *testing.T) {
+	t.Run("returns 200 for a valid state", func(t *testing.T) {
+		body := `{"version": 4, "terraform_version": "1.5.0", "lineage": "abc", "resources": []}`
+		req := httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		ValidateHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var report models.ValidationReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+		assert.True(t, report.Valid)
+		assert.Empty(t, report.Issues)
+	})
+
+	t.Run("returns 422 with the report for an invalid state", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(`{"version": 3}`))
+		w := httptest.NewRecorder()
+
+		ValidateHandler(w, req)
+
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var report models.ValidationReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+		assert.False(t, report.Valid)
+		assert.NotEmpty(t, report.Issues)
+	})
+
+	t.Run("returns 422 for malformed json", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(`{invalid`))
+		w := httptest.NewRecorder()
+
+		ValidateHandler(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid_json")
+	})
+
+	t.Run("returns 405 for GET request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/validate", nil)
+		w := httptest.NewRecorder()
+
+		ValidateHandler(w, req)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
@@ -0,0 +1,233 @@
+// Package jsonschema generates JSON Schemas from the Go types of the API
+// responses, so that clients can validate responses and generate code against
+// the same definitions the server encodes.
+package jsonschema
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema dialect of the generated schemas.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema, limited to the keywords Reflect generates.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// Reflect returns the schema of the JSON encoding of v's type, which must be
+// a struct. Nested structs are defined once under $defs and referenced.
+// Fields without omitempty are required, and those whose zero value encodes
+// as null, such as nil slices, also accept null.
+func Reflect(v any) *Schema {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	g := &generator{root: t, defs: make(map[string]*Schema)}
+	schema := g.object(t)
+	schema.Schema = Draft
+	schema.Title = t.Name()
+	if len(g.defs) > 0 {
+		schema.Defs = g.defs
+	}
+
+	return schema
+}
+
+type generator struct {
+	root reflect.Type
+	defs map[string]*Schema
+}
+
+// schema returns the schema of values of type t.
+func (g *generator) schema(t reflect.Type) *Schema {
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schema(t.Elem())
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		return g.ref(t)
+	default:
+		// Interfaces hold any value.
+		return &Schema{}
+	}
+}
+
+// ref references the definition of a struct type, adding it to $defs the
+// first time.
+func (g *generator) ref(t reflect.Type) *Schema {
+	if t == g.root {
+		return &Schema{Ref: "#"}
+	}
+
+	if t.Name() == "" {
+		return g.object(t)
+	}
+
+	if _, ok := g.defs[t.Name()]; !ok {
+		// Reserve the name first, for types that refer to themselves.
+		g.defs[t.Name()] = nil
+		g.defs[t.Name()] = g.object(t)
+	}
+
+	return &Schema{Ref: "#/$defs/" + t.Name()}
+}
+
+// object returns the schema of the fields of a struct type.
+func (g *generator) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for _, f := range fields(t) {
+		property := g.schema(f.typ)
+
+		if !f.omitEmpty {
+			schema.Required = append(schema.Required, f.name)
+
+			if nullable(f.typ) {
+				property = orNull(property)
+			}
+		}
+
+		schema.Properties[f.name] = property
+	}
+
+	sort.Strings(schema.Required)
+
+	return schema
+}
+
+// nullable reports whether the zero value of a type encodes as null.
+func nullable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Uint8
+	}
+
+	return false
+}
+
+// orNull extends a schema to accept null.
+func orNull(schema *Schema) *Schema {
+	switch typ := schema.Type.(type) {
+	case string:
+		schema.Type = []string{typ, "null"}
+		return schema
+	case nil:
+		if schema.Ref == "" {
+			// The empty schema accepts null already.
+			return schema
+		}
+	}
+
+	return &Schema{AnyOf: []*Schema{schema, {Type: "null"}}}
+}
+
+type field struct {
+	name      string
+	typ       reflect.Type
+	omitEmpty bool
+}
+
+// fields lists the fields encoding/json encodes for a struct type, with the
+// fields of embedded structs promoted unless a shallower field shadows them.
+func fields(t reflect.Type) []field {
+	var result []field
+	seen := make(map[string]bool)
+
+	level := []reflect.Type{t}
+	for len(level) > 0 {
+		var next []reflect.Type
+		var found []field
+
+		for _, typ := range level {
+			for i := range typ.NumField() {
+				f := typ.Field(i)
+				tag := f.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+
+				name, options, _ := strings.Cut(tag, ",")
+
+				embedded := f.Type
+				if embedded.Kind() == reflect.Pointer {
+					embedded = embedded.Elem()
+				}
+				if f.Anonymous && name == "" && embedded.Kind() == reflect.Struct {
+					next = append(next, embedded)
+					continue
+				}
+
+				if !f.IsExported() {
+					continue
+				}
+
+				if name == "" {
+					name = f.Name
+				}
+				if seen[name] {
+					continue
+				}
+
+				found = append(found, field{name: name, typ: f.Type, omitEmpty: hasOption(options, "omitempty") || hasOption(options, "omitzero")})
+			}
+		}
+
+		for _, f := range found {
+			seen[f.name] = true
+		}
+		result = append(result, found...)
+		level = next
+	}
+
+	return result
+}
+
+func hasOption(options, option string) bool {
+	for _, o := range strings.Split(options, ",") {
+		if o == option {
+			return true
+		}
+	}
+
+	return false
+}
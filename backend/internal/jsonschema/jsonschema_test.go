@@ -0,0 +1,102 @@
+// Package jsonschema generates JSON Schemas from the Go types of the API
+// responses, so that clients can validate responses and generate code against
+// the same definitions the server encodes.
+package jsonschema
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+type tree struct {
+	Name     string    `json:"name"`
+	Children []tree    `json:"children,omitempty"`
+	Parent   *tree     `json:"parent"`
+	Leaf     *leaf     `json:"leaf,omitempty"`
+	Created  time.Time `json:"created"`
+	Labels   []string  `json:"labels"`
+	Extra    any       `json:"extra"`
+	internal string
+	Ignored  string `json:"-"`
+}
+
+type leaf struct {
+	Weight float64          `json:"weight"`
+	Counts map[string]int   `json:"counts,omitempty"`
+	Data   []byte           `json:"data,omitempty"`
+	Flags  map[string]bool  `json:"flags"`
+	Nested struct{ A bool } `json:"nested"`
+}
+
+type base struct {
+	ID     string `json:"id"`
+	Change string `json:"change,omitempty"`
+}
+
+type derived struct {
+	base
+	Change string `json:"change"`
+}
+
+func TestReflect(t *testing.T) {
+	t.Run("describes fields", func(t *testing.T) {
+		schema := Reflect(tree{})
+
+		assert.Equal(t, Draft, schema.Schema)
+		assert.Equal(t, "tree", schema.Title)
+		assert.Equal(t, []string{"created", "extra", "labels", "name", "parent"}, schema.Required)
+		assert.Equal(t, &Schema{Type: "string"}, schema.Properties["name"])
+		assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, schema.Properties["created"])
+		assert.Equal(t, &Schema{Type: []string{"array", "null"}, Items: &Schema{Type: "string"}}, schema.Properties["labels"])
+		assert.Equal(t, &Schema{}, schema.Properties["extra"])
+		assert.NotContains(t, schema.Properties, "internal")
+		assert.NotContains(t, schema.Properties, "Ignored")
+	})
+
+	t.Run("references nested structs", func(t *testing.T) {
+		schema := Reflect(&tree{})
+
+		assert.Equal(t, &Schema{Type: "array", Items: &Schema{Ref: "#"}}, schema.Properties["children"], "the root refers to itself")
+		assert.Equal(t, &Schema{AnyOf: []*Schema{{Ref: "#"}, {Type: "null"}}}, schema.Properties["parent"])
+		assert.Equal(t, &Schema{Ref: "#/$defs/leaf"}, schema.Properties["leaf"])
+
+		leaf := schema.Defs["leaf"]
+		require.NotNil(t, leaf)
+		assert.Equal(t, &Schema{Type: "number"}, leaf.Properties["weight"])
+		assert.Equal(t, &Schema{Type: "object", AdditionalProperties: &Schema{Type: "integer"}}, leaf.Properties["counts"])
+		assert.Equal(t, &Schema{Type: []string{"object", "null"}, AdditionalProperties: &Schema{Type: "boolean"}}, leaf.Properties["flags"])
+		assert.Equal(t, &Schema{Type: "string", Format: "byte"}, leaf.Properties["data"])
+		assert.Equal(t, "object", leaf.Properties["nested"].Type, "anonymous structs are inlined")
+	})
+
+	t.Run("promotes embedded fields", func(t *testing.T) {
+		schema := Reflect(derived{})
+
+		assert.Equal(t, []string{"change", "id"}, schema.Required, "outer fields shadow embedded ones")
+		assert.Len(t, schema.Properties, 2)
+	})
+
+	t.Run("requires the fields of encoded zero values", func(t *testing.T) {
+		for _, v := range []any{models.Graph{}, models.Node{}, models.Edge{}, models.Stats{}, models.StateDiff{}, models.DiffNode{}, models.AnalysisReport{}} {
+			encoded, err := json.Marshal(v)
+			require.NoError(t, err)
+
+			var fields map[string]any
+			require.NoError(t, json.Unmarshal(encoded, &fields))
+
+			keys := make([]string, 0, len(fields))
+			for key := range fields {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			assert.Equal(t, keys, Reflect(v).Required, "%T", v)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import "time"
+
+// Conditions an alert rule can watch for between two versions of a state.
+const (
+	AlertOnAdded        = "added"
+	AlertOnRemoved      = "removed"
+	AlertOnModified     = "modified"
+	AlertOnCountChanged = "count_changed"
+)
+
+// Anomalies flagged between consecutive versions of a state, whatever the
+// alert rules: most of the resources destroyed, resources moved to another
+// provider, a serial far ahead of the previous one, or the resources of
+// another lineage showing up under a new one.
+const (
+	AnomalyMassDeletion  = "mass_deletion"
+	AnomalyProviderSwap  = "provider_swap"
+	AnomalySerialJump    = "serial_jump"
+	AnomalyLineageChange = "lineage_change"
+)
+
+// AlertRule is a condition evaluated whenever a new version of a matching
+// lineage is stored. Lineage is a glob pattern; an empty pattern matches every
+// lineage, and an empty ResourceType matches every managed resource. Alerts go
+// to the named notification channels, or to the channels configured for the
+// lineage when none are named, and to any ad-hoc webhooks.
+type AlertRule struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Lineage      string    `json:"lineage,omitempty"`
+	Condition    string    `json:"condition"`
+	ResourceType string    `json:"resource_type,omitempty"`
+	Severity     string    `json:"severity,omitempty"`
+	Channels     []string  `json:"channels,omitempty"`
+	Webhooks     []string  `json:"webhooks,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Alert records a rule that matched a new state version, or an anomaly, which
+// has no RuleID and the anomaly as its Condition.
+type Alert struct {
+	RuleID         string    `json:"rule_id"`
+	RuleName       string    `json:"rule_name"`
+	Lineage        string    `json:"lineage"`
+	Serial         int       `json:"serial"`
+	PreviousSerial int       `json:"previous_serial"`
+	Condition      string    `json:"condition"`
+	Severity       string    `json:"severity"`
+	Message        string    `json:"message"`
+	Resources      []string  `json:"resources,omitempty"`
+	Before         *int      `json:"before,omitempty"`
+	After          *int      `json:"after,omitempty"`
+	TriggeredAt    time.Time `json:"triggered_at"`
+}
@@ -0,0 +1,56 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+type EndpointReport struct {
+	Endpoints     []Endpoint     `json:"endpoints"`
+	LoadBalancers []LoadBalancer `json:"load_balancers"`
+}
+
+type Endpoint struct {
+	Hostname       string   `json:"hostname"`
+	RecordID       string   `json:"record_id"`
+	RecordType     string   `json:"record_type,omitempty"`
+	Values         []string `json:"values,omitempty"`
+	LoadBalancer   string   `json:"load_balancer,omitempty"`
+	InternetFacing bool     `json:"internet_facing"`
+	Targets        []string `json:"targets,omitempty"`
+}
+
+type LoadBalancer struct {
+	ID             string   `json:"id"`
+	Type           string   `json:"type"`
+	DNSName        string   `json:"dns_name"`
+	InternetFacing bool     `json:"internet_facing"`
+	Hostnames      []string `json:"hostnames,omitempty"`
+	Targets        []string `json:"targets,omitempty"`
+}
+
+type ImportReport struct {
+	Suggestions []ImportSuggestion `json:"suggestions"`
+}
+
+type ImportSuggestion struct {
+	ID           string   `json:"id"`
+	ResourceType string   `json:"resource_type"`
+	Address      string   `json:"address"`
+	Command      string   `json:"command"`
+	ReferencedBy []string `json:"referenced_by"`
+}
+
+type DestroySimulation struct {
+	Targets  []string      `json:"targets"`
+	Cascade  []DestroyStep `json:"cascade"`
+	Warnings []Warning     `json:"warnings,omitempty"`
+}
+
+type DestroyStep struct {
+	ID         string   `json:"id"`
+	Type       string   `json:"type"`
+	Target     bool     `json:"target"`
+	RequiredBy []string `json:"required_by,omitempty"`
+	Protected  bool     `json:"protected"`
+	// CreateBeforeDestroy is set when replacements of the resource create
+	// the new object before destroying the old one.
+	CreateBeforeDestroy bool `json:"create_before_destroy,omitempty"`
+}
@@ -0,0 +1,65 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointReportMarshal(t *testing.T) {
+	t.Run("marshal report with endpoint and load balancer", func(t *testing.T) {
+		report := EndpointReport{
+			Endpoints: []Endpoint{
+				{
+					Hostname:       "www.example.com",
+					RecordID:       "aws_route53_record.www",
+					LoadBalancer:   "aws_lb.web",
+					InternetFacing: true,
+					Targets:        []string{"aws_instance.web"},
+				},
+			},
+			LoadBalancers: []LoadBalancer{
+				{ID: "aws_lb.web", Type: "aws_lb", DNSName: "web.elb.amazonaws.com", InternetFacing: true},
+			},
+		}
+
+		data, err := json.Marshal(report)
+		require.NoError(t, err)
+
+		var decoded map[string]any
+		require.NoError(t, json.Unmarshal(data, &decoded))
+
+		endpoints := decoded["endpoints"].([]any)
+		endpoint := endpoints[0].(map[string]any)
+		assert.Equal(t, "www.example.com", endpoint["hostname"])
+		assert.Equal(t, true, endpoint["internet_facing"])
+		assert.NotContains(t, endpoint, "values")
+
+		balancers := decoded["load_balancers"].([]any)
+		assert.Equal(t, "web.elb.amazonaws.com", balancers[0].(map[string]any)["dns_name"])
+	})
+}
+
+func TestImportReportMarshal(t *testing.T) {
+	t.Run("marshal suggestion", func(t *testing.T) {
+		report := ImportReport{Suggestions: []ImportSuggestion{{
+			ID:           "subnet-0abc12345",
+			ResourceType: "aws_subnet",
+			Address:      "aws_subnet.subnet_0abc12345",
+			Command:      "terraform import aws_subnet.subnet_0abc12345 subnet-0abc12345",
+			ReferencedBy: []string{"aws_instance.web"},
+		}}}
+
+		data, err := json.Marshal(report)
+		require.NoError(t, err)
+
+		var decoded ImportReport
+		require.NoError(t, json.Unmarshal(data, &decoded))
+
+		assert.Equal(t, report, decoded)
+	})
+}
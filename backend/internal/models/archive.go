@@ -0,0 +1,37 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import "time"
+
+// Archive is a portable snapshot of an instance's stored data.
+type Archive struct {
+	Version   int             `json:"version"`
+	CreatedAt time.Time       `json:"created_at"`
+	Graphs    []ArchivedGraph `json:"graphs"`
+	Locks     []ArchivedLock  `json:"locks"`
+	States    []ArchivedState `json:"states,omitempty"`
+}
+
+type ArchivedGraph struct {
+	Digest string `json:"digest"`
+	Graph  *Graph `json:"graph"`
+}
+
+type ArchivedLock struct {
+	Lineage string   `json:"lineage"`
+	Lock    LockInfo `json:"lock"`
+}
+
+// ArchivedState is a stored state version and its document, which is empty
+// when the version is archived to cold storage.
+type ArchivedState struct {
+	Version  StateVersion `json:"version"`
+	Document []byte       `json:"document,omitempty"`
+}
+
+type ImportResult struct {
+	States int `json:"states"`
+	Graphs int `json:"graphs"`
+	Locks  int `json:"locks"`
+}
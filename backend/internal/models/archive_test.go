@@ -0,0 +1,43 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveMarshal(t *testing.T) {
+	t.Run("empty archive keeps empty lists", func(t *testing.T) {
+		archive := Archive{
+			Version:   1,
+			CreatedAt: time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC),
+			Graphs:    []ArchivedGraph{},
+			Locks:     []ArchivedLock{},
+		}
+
+		data, err := json.Marshal(archive)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"version":1,"created_at":"2024-03-05T10:00:00Z","graphs":[],"locks":[]}`, string(data))
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		archive := Archive{
+			Version: 1,
+			Graphs:  []ArchivedGraph{{Digest: "sha256:ab", Graph: &Graph{Nodes: []Node{{ID: "aws_vpc.main"}}, Edges: []Edge{}}}},
+			Locks:   []ArchivedLock{{Lineage: "l", Lock: LockInfo{ID: "a", Who: "me"}}},
+		}
+
+		data, err := json.Marshal(archive)
+		require.NoError(t, err)
+
+		var decoded Archive
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, archive, decoded)
+	})
+}
@@ -0,0 +1,82 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import "time"
+
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Session is the login session of a user of the embedded UI.
+type Session struct {
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Roles of users and API keys. Members may use the stored-state routes once
+// login is enabled; admins may also use the /admin routes.
+const (
+	RoleMember = "member"
+	RoleAdmin  = "admin"
+)
+
+// User may sign in to the UI. Invited users have not chosen a password yet.
+type User struct {
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	Invited   bool      `json:"invited,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type InviteRequest struct {
+	Username string `json:"username"`
+	Role     string `json:"role,omitempty"`
+}
+
+// Invitation lets the invited user choose a password until it expires. Its
+// token is only returned when the invitation is created.
+type Invitation struct {
+	User      User      `json:"user"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AcceptInvitationRequest sets the password of an invited user.
+type AcceptInvitationRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type RoleRequest struct {
+	Role string `json:"role"`
+}
+
+type APIKeyRequest struct {
+	Name    string `json:"name"`
+	Role    string `json:"role,omitempty"`
+	Project string `json:"project,omitempty"`
+}
+
+// APIKey authenticates API clients as a bearer token, with the usage
+// recorded since it was created. The usage of keys bound to a project counts
+// against that project's quota.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Role       string     `json:"role"`
+	Project    string     `json:"project,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	Requests   int64      `json:"requests"`
+}
+
+// CreatedAPIKey is a new API key with its secret, which is only returned
+// when the key is created.
+type CreatedAPIKey struct {
+	APIKey
+	Secret string `json:"secret"`
+}
@@ -0,0 +1,45 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+// BackstageAPIVersion is the apiVersion of the Backstage catalog entities.
+const BackstageAPIVersion = "backstage.io/v1alpha1"
+
+// Backstage entity kinds.
+const (
+	BackstageKindSystem   = "System"
+	BackstageKindResource = "Resource"
+)
+
+// BackstageEntity is an entity of the Backstage software catalog, in the
+// shape of its catalog-info.yaml descriptors.
+type BackstageEntity struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   BackstageMetadata `json:"metadata"`
+	Spec       BackstageSpec     `json:"spec"`
+}
+
+type BackstageMetadata struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	Title       string            `json:"title,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Annotations map[string]string `json:"annotations"`
+	Tags        []string          `json:"tags,omitempty"`
+	Links       []BackstageLink   `json:"links,omitempty"`
+}
+
+type BackstageLink struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+}
+
+// BackstageSpec holds the spec fields of the System and Resource kinds.
+// DependsOn and System are only set on resources.
+type BackstageSpec struct {
+	Type      string   `json:"type,omitempty"`
+	Owner     string   `json:"owner"`
+	System    string   `json:"system,omitempty"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
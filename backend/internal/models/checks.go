@@ -0,0 +1,13 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import "github.com/terrascope/core/pkg/terrascope/model"
+
+// Check results and warnings are part of the public API, see model.
+
+type (
+	CheckResult = model.CheckResult
+	CheckObject = model.CheckObject
+	Warning     = model.Warning
+)
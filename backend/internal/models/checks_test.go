@@ -0,0 +1,71 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckResultUnmarshal(t *testing.T) {
+	t.Run("state with check results", func(t *testing.T) {
+		jsonData := `{
+			"version": 4,
+			"terraform_version": "1.5.0",
+			"serial": 1,
+			"lineage": "abc-123",
+			"resources": [],
+			"check_results": [
+				{
+					"object_kind": "resource",
+					"config_addr": "aws_instance.web",
+					"status": "fail",
+					"objects": [
+						{
+							"object_addr": "aws_instance.web",
+							"status": "fail",
+							"failure_messages": ["AMI must be encrypted"]
+						}
+					]
+				}
+			]
+		}`
+
+		var state TerraformState
+		require.NoError(t, json.Unmarshal([]byte(jsonData), &state))
+
+		require.Len(t, state.CheckResults, 1)
+		result := state.CheckResults[0]
+		assert.Equal(t, "resource", result.ObjectKind)
+		assert.Equal(t, "aws_instance.web", result.ConfigAddr)
+		assert.Equal(t, "fail", result.Status)
+		require.Len(t, result.Objects, 1)
+		assert.Equal(t, []string{"AMI must be encrypted"}, result.Objects[0].FailureMessages)
+	})
+
+	t.Run("check results are omitted when empty", func(t *testing.T) {
+		data, err := json.Marshal(TerraformState{Version: 4})
+		require.NoError(t, err)
+
+		assert.NotContains(t, string(data), "check_results")
+	})
+}
+
+func TestWarningMarshal(t *testing.T) {
+	t.Run("node warnings are omitted when empty", func(t *testing.T) {
+		data, err := json.Marshal(Node{ID: "aws_vpc.main"})
+		require.NoError(t, err)
+
+		assert.NotContains(t, string(data), "warnings")
+	})
+
+	t.Run("warning fields", func(t *testing.T) {
+		data, err := json.Marshal(Warning{Type: "check", Address: "check.health", Status: "fail", Message: "down"})
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"type":"check","address":"check.health","status":"fail","message":"down"}`, string(data))
+	})
+}
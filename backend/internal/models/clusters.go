@@ -0,0 +1,46 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+type ClusterReport struct {
+	Clusters    []Cluster `json:"clusters"`
+	Unclustered int       `json:"unclustered"`
+}
+
+type Cluster struct {
+	ID             string   `json:"id"`
+	Label          string   `json:"label"`
+	Size           int      `json:"size"`
+	DominantModule string   `json:"dominant_module,omitempty"`
+	DominantType   string   `json:"dominant_type"`
+	InternalEdges  int      `json:"internal_edges"`
+	ExternalEdges  int      `json:"external_edges"`
+	Nodes          []string `json:"nodes"`
+}
+
+// SplitReport suggests how to split a state into states with few
+// dependencies between them. Components counts the weakly connected
+// components of the dependency graph, which can be split apart without any,
+// and CrossDependencies lists the dependencies the suggested splits cut.
+type SplitReport struct {
+	Components        int               `json:"components"`
+	Splits            []StateSplit      `json:"splits"`
+	CrossDependencies []CrossDependency `json:"cross_dependencies"`
+}
+
+// StateSplit is a group of resources suggested to move to a state of its own.
+// DependsOn lists the splits it reads from, which must be applied first.
+type StateSplit struct {
+	Cluster
+	DependsOn []string `json:"depends_on"`
+}
+
+// CrossDependency is a dependency of a node of one split on a node of
+// another, which becomes a remote state lookup or data source after the
+// split.
+type CrossDependency struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
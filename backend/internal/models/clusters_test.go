@@ -0,0 +1,27 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterReportMarshal(t *testing.T) {
+	t.Run("empty report keeps an empty cluster list", func(t *testing.T) {
+		data, err := json.Marshal(ClusterReport{Clusters: []Cluster{}})
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"clusters":[],"unclustered":0}`, string(data))
+	})
+
+	t.Run("dominant module is omitted when empty", func(t *testing.T) {
+		data, err := json.Marshal(Cluster{ID: "cluster-1", Label: "aws_subnet", Size: 1, DominantType: "aws_subnet", Nodes: []string{"aws_subnet.a"}})
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"id":"cluster-1","label":"aws_subnet","size":1,"dominant_type":"aws_subnet","internal_edges":0,"external_edges":0,"nodes":["aws_subnet.a"]}`, string(data))
+	})
+}
@@ -0,0 +1,22 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+// Kinds of codes.
+const (
+	CodeKindWarning    = "warning"
+	CodeKindFinding    = "finding"
+	CodeKindValidation = "validation"
+)
+
+// CodeInfo describes the stable code that the warnings, findings or
+// validation issues of one kind carry. Source is the warning or issue type,
+// or the <analyzer>/<check> of the findings, where a check of * stands for
+// every check of its analyzer.
+type CodeInfo struct {
+	Code        string `json:"code"`
+	Kind        string `json:"kind"`
+	Source      string `json:"source"`
+	Description string `json:"description"`
+	Remediation string `json:"remediation,omitempty"`
+}
@@ -0,0 +1,43 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+// Statuses of a compliance control.
+const (
+	ControlPass        = "pass"
+	ControlFail        = "fail"
+	ControlNotAssessed = "not_assessed"
+)
+
+// ComplianceReport summarizes, per framework, the controls that the checks
+// of the analyzers a request ran give evidence for. Partial is set when the
+// analyzers named by Incomplete ran out of time, and the controls only they
+// check are not assessed.
+type ComplianceReport struct {
+	Frameworks []FrameworkCoverage `json:"frameworks"`
+	Partial    bool                `json:"partial,omitempty"`
+	Incomplete []string            `json:"incomplete,omitempty"`
+}
+
+// FrameworkCoverage counts the controls of a framework that passed among
+// those assessed. Coverage is the percentage of the assessed controls that
+// passed, 0 when none was.
+type FrameworkCoverage struct {
+	Framework string          `json:"framework"`
+	Assessed  int             `json:"assessed"`
+	Passed    int             `json:"passed"`
+	Failed    int             `json:"failed"`
+	Coverage  float64         `json:"coverage"`
+	Controls  []ControlStatus `json:"controls"`
+}
+
+// ControlStatus is the result of the checks mapped to a control, as
+// <analyzer>/<check>. A control fails with the IDs of the findings of its
+// checks, and is not assessed when none of its analyzers ran.
+type ControlStatus struct {
+	ID       string   `json:"id"`
+	Title    string   `json:"title"`
+	Checks   []string `json:"checks"`
+	Status   string   `json:"status"`
+	Findings []string `json:"findings,omitempty"`
+}
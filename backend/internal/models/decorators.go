@@ -0,0 +1,13 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import "time"
+
+// Decorator is an uploaded script run against every node of the graphs of
+// the requests that name it, to add metadata, drop nodes or add edges.
+type Decorator struct {
+	Name      string    `json:"name"`
+	Script    string    `json:"script"`
+	CreatedAt time.Time `json:"created_at"`
+}
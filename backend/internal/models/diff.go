@@ -0,0 +1,44 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import "github.com/terrascope/core/pkg/terrascope/model"
+
+// Diffs are part of the public API, see model.
+
+type (
+	StateDiff      = model.StateDiff
+	ResourceChange = model.ResourceChange
+	Rename         = model.Rename
+	DiffGraph      = model.DiffGraph
+	DiffNode       = model.DiffNode
+	NodeSource     = model.NodeSource
+	DiffEdge       = model.DiffEdge
+)
+
+// Changes of the nodes and edges of a DiffGraph.
+const (
+	ChangeAdded     = model.ChangeAdded
+	ChangeRemoved   = model.ChangeRemoved
+	ChangeModified  = model.ChangeModified
+	ChangeUnchanged = model.ChangeUnchanged
+)
+
+// EnvironmentDiff compares the latest versions of two lineages deploying the
+// same configuration, such as prod and staging. Resources are aligned by
+// address: Only lists the addresses present in one environment, and Diverged
+// the shared addresses whose attributes differ.
+type EnvironmentDiff struct {
+	Left      EnvironmentSide  `json:"left"`
+	Right     EnvironmentSide  `json:"right"`
+	Shared    int              `json:"shared"`
+	OnlyLeft  []string         `json:"only_left"`
+	OnlyRight []string         `json:"only_right"`
+	Diverged  []ResourceChange `json:"diverged"`
+}
+
+// EnvironmentSide names the state version compared on one side.
+type EnvironmentSide struct {
+	Lineage string `json:"lineage"`
+	Serial  int    `json:"serial"`
+}
@@ -0,0 +1,47 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateDiffMarshal(t *testing.T) {
+	t.Run("empty diff keeps empty lists", func(t *testing.T) {
+		diff := StateDiff{
+			Added:    []string{},
+			Removed:  []string{},
+			Modified: []ResourceChange{},
+			Renamed:  []Rename{},
+		}
+
+		data, err := json.Marshal(diff)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"added":[],"removed":[],"modified":[],"renamed":[]}`, string(data))
+	})
+
+	t.Run("rename omits unchanged attributes", func(t *testing.T) {
+		data, err := json.Marshal(Rename{From: "a.b", To: "a.c", Type: "a", MatchedBy: "id"})
+		require.NoError(t, err)
+
+		assert.NotContains(t, string(data), "changed_attributes")
+	})
+
+	t.Run("diff node change is a string", func(t *testing.T) {
+		node := DiffNode{
+			Node:   Node{ID: "aws_vpc.main", Type: "aws_vpc", Mode: "managed", Provider: "aws", Change: &NodeChange{Action: ActionUpdate}},
+			Change: ChangeModified,
+			Source: NodeSource{Lineage: "abc-123", Serial: 2},
+		}
+
+		data, err := json.Marshal(node)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"id":"aws_vpc.main","type":"aws_vpc","mode":"managed","provider":"aws","change":"modified","source":{"lineage":"abc-123","serial":2}}`, string(data))
+	})
+}
@@ -0,0 +1,25 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import "time"
+
+// Types of the events of a state lineage.
+const (
+	// StateEventVersion is sent when a version of the lineage is stored.
+	StateEventVersion = "version"
+	// StateEventAnalysis is sent when an analysis of a state of the lineage
+	// completes.
+	StateEventAnalysis = "analysis"
+)
+
+// StateEvent tells the clients following a lineage that something happened to
+// it, so that they can refresh what they show.
+type StateEvent struct {
+	Type    string `json:"type"`
+	Lineage string `json:"lineage"`
+	Serial  int    `json:"serial"`
+	// Analysis names the analysis that completed, e.g. endpoints.
+	Analysis string    `json:"analysis,omitempty"`
+	At       time.Time `json:"at"`
+}
@@ -0,0 +1,48 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+type ExportResult struct {
+	Artifacts []Artifact `json:"artifacts"`
+}
+
+type Artifact struct {
+	Format      string `json:"format"`
+	Key         string `json:"key"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+}
+
+// CMDBImportSet is a batch of configuration items in the shape of the body
+// of the insertMultiple endpoint of the ServiceNow Import Set API.
+type CMDBImportSet struct {
+	Records []CMDBRecord `json:"records"`
+}
+
+// CMDBRecord is a row of the import set table Terrascope fills, which a
+// transform map turns into a configuration item of Class. CorrelationID is
+// the same for a resource instance across versions of its state, and
+// DependsOn lists the correlation IDs of its dependencies, comma-separated.
+type CMDBRecord struct {
+	CorrelationID string `json:"u_correlation_id"`
+	Class         string `json:"u_class"`
+	Name          string `json:"u_name"`
+	ObjectID      string `json:"u_object_id,omitempty"`
+	Address       string `json:"u_terraform_address"`
+	ResourceType  string `json:"u_resource_type"`
+	Lineage       string `json:"u_lineage"`
+	Serial        int    `json:"u_serial"`
+	Provider      string `json:"u_provider"`
+	Region        string `json:"u_region,omitempty"`
+	Module        string `json:"u_module,omitempty"`
+	DependsOn     string `json:"u_depends_on,omitempty"`
+}
+
+// CMDBPushResult tells where an import set was pushed: the staging table and
+// the import set ServiceNow created for the records.
+type CMDBPushResult struct {
+	Table       string `json:"table"`
+	ImportSetID string `json:"import_set_id"`
+	Records     int    `json:"records"`
+}
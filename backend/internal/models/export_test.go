@@ -0,0 +1,27 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportResultMarshal(t *testing.T) {
+	t.Run("empty result keeps an empty artifact list", func(t *testing.T) {
+		data, err := json.Marshal(ExportResult{Artifacts: []Artifact{}})
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"artifacts":[]}`, string(data))
+	})
+
+	t.Run("artifact fields", func(t *testing.T) {
+		data, err := json.Marshal(Artifact{Format: "dot", Key: "l/1/graph.dot", URL: "https://b/l/1/graph.dot", ContentType: "text/vnd.graphviz", Size: 42})
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"format":"dot","key":"l/1/graph.dot","url":"https://b/l/1/graph.dot","content_type":"text/vnd.graphviz","size":42}`, string(data))
+	})
+}
@@ -0,0 +1,51 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import "github.com/terrascope/core/pkg/terrascope/model"
+
+// Finding severities, from the least to the most severe.
+const (
+	SeverityInfo     = model.SeverityInfo
+	SeverityWarning  = model.SeverityWarning
+	SeverityError    = model.SeverityError
+	SeverityCritical = model.SeverityCritical
+)
+
+// Finding is part of the public API, see model.
+type Finding = model.Finding
+
+type FindingsReport struct {
+	Findings []Finding `json:"findings"`
+}
+
+// AnalysisReport holds the findings of the analyzers a request ran and the
+// graph their node IDs refer to. With a baseline, Findings only holds the
+// findings the baseline version did not have. Partial is set when the
+// analyzers named by Incomplete ran out of time, and their findings are
+// missing.
+type AnalysisReport struct {
+	Findings   []Finding        `json:"findings"`
+	Baseline   *BaselineSummary `json:"baseline,omitempty"`
+	Graph      *Graph           `json:"graph"`
+	Partial    bool             `json:"partial,omitempty"`
+	Incomplete []string         `json:"incomplete,omitempty"`
+}
+
+// BaselineSummary names the stored state version findings were compared
+// with and counts the findings it already had, which were left out.
+type BaselineSummary struct {
+	Lineage string `json:"lineage"`
+	Serial  int    `json:"serial"`
+	Known   int    `json:"known"`
+}
+
+// AnalyzerManifest lists the analyzers a request can select.
+type AnalyzerManifest struct {
+	Analyzers []AnalyzerInfo `json:"analyzers"`
+}
+
+type AnalyzerInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
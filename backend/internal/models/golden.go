@@ -0,0 +1,37 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import "time"
+
+// GoldenModule is the reference signature of a module: the resources every
+// instance of the module calls named Module should hold, by address relative
+// to the module instance and without instance keys, such as aws_s3_bucket.this
+// or module.logs.aws_s3_bucket.this.
+type GoldenModule struct {
+	Name      string    `json:"name"`
+	Module    string    `json:"module"`
+	Resources []string  `json:"resources"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ModuleConformance compares a module instance of a stored state with a
+// golden module: Missing lists the golden resources the instance lacks and
+// Extra the resources it has beyond them.
+type ModuleConformance struct {
+	Lineage   string   `json:"lineage"`
+	Serial    int      `json:"serial"`
+	Instance  string   `json:"instance"`
+	Conforms  bool     `json:"conforms"`
+	Missing   []string `json:"missing"`
+	Extra     []string `json:"extra"`
+	Resources int      `json:"resources"`
+}
+
+// ConformanceReport holds the conformance of every instance of a golden
+// module found in the stored states.
+type ConformanceReport struct {
+	Golden    string              `json:"golden"`
+	Instances []ModuleConformance `json:"instances"`
+	Deviating int                 `json:"deviating"`
+}
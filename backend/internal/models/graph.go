@@ -2,30 +2,48 @@
 // It includes entity definitions and methods for persistence and validation.
 package models
 
-type Graph struct {
-	Nodes []Node `json:"nodes"`
-	Edges []Edge `json:"edges"`
-	Stats *Stats `json:"stats,omitempty"`
-}
+import "github.com/terrascope/core/pkg/terrascope/model"
 
-type Node struct {
-	ID       string         `json:"id"`
-	Type     string         `json:"type"`
-	Mode     string         `json:"mode"`
-	Provider string         `json:"provider"`
-	Module   string         `json:"module,omitempty"`
-	Metadata map[string]any `json:"metadata,omitempty"`
-}
+// Graphs are part of the public API, see model.
 
-type Edge struct {
-	Source string `json:"source"`
-	Target string `json:"target"`
-	Type   string `json:"type"`
-}
+type (
+	Graph            = model.Graph
+	Node             = model.Node
+	NodePresentation = model.NodePresentation
+	NodeDegrees      = model.NodeDegrees
+	NodeAddress      = model.NodeAddress
+	Edge             = model.Edge
+	Stats            = model.Stats
+	ProviderStats    = model.ProviderStats
+	TypeCount        = model.TypeCount
+	ModuleCount      = model.ModuleCount
+	Reduction        = model.Reduction
+	Summary          = model.Summary
+)
 
-type Stats struct {
-	TotalNodes      int            `json:"total_nodes"`
-	TotalEdges      int            `json:"total_edges"`
-	ResourcesByType map[string]int `json:"resources_by_type,omitempty"`
-	ResourcesByMode map[string]int `json:"resources_by_mode,omitempty"`
+// GraphPatch turns a graph into another: it removes the nodes of
+// RemovedNodes and the edges of RemovedEdges, adds or replaces Nodes with
+// their Addresses, and adds Edges. Warnings replace those of the graph.
+type GraphPatch struct {
+	RemovedNodes []string               `json:"removed_nodes,omitempty"`
+	Nodes        []Node                 `json:"nodes,omitempty"`
+	RemovedEdges []Edge                 `json:"removed_edges,omitempty"`
+	Edges        []Edge                 `json:"edges,omitempty"`
+	Addresses    map[string]NodeAddress `json:"addresses,omitempty"`
+	Warnings     []Warning              `json:"warnings,omitempty"`
 }
+
+// Node statuses mark instances Terraform will replace: tainted instances and
+// deposed objects left behind by a create_before_destroy replacement.
+const (
+	NodeStatusTainted = model.NodeStatusTainted
+	NodeStatusDeposed = model.NodeStatusDeposed
+)
+
+// Node badges, in the order they are listed.
+const (
+	BadgeDrifted   = model.BadgeDrifted
+	BadgeRisky     = model.BadgeRisky
+	BadgeUntagged  = model.BadgeUntagged
+	BadgeSensitive = model.BadgeSensitive
+)
@@ -153,6 +153,23 @@ func TestGraphMarshal(t *testing.T) {
 
 		jsonString := string(data)
 		assert.NotContains(t, jsonString, "stats")
+		assert.NotContains(t, jsonString, "addresses")
+		assert.NotContains(t, jsonString, "summar")
+	})
+
+	t.Run("marshal graph with addresses", func(t *testing.T) {
+		graph := Graph{
+			Nodes: []Node{{ID: "aws_subnet.private[a]", Type: "aws_subnet", Mode: "managed", Provider: "aws"}},
+			Edges: []Edge{},
+			Addresses: map[string]NodeAddress{
+				"aws_subnet.private[a]": {Terraform: `aws_subnet.private["a"]`, StatePath: ".resources[0].instances[0]"},
+			},
+		}
+
+		data, err := json.Marshal(graph)
+		require.NoError(t, err)
+
+		assert.Contains(t, string(data), `"addresses":{"aws_subnet.private[a]":{"terraform":"aws_subnet.private[\"a\"]","state_path":".resources[0].instances[0]"}}`)
 	})
 }
 
@@ -329,6 +346,32 @@ func TestEdgeMarshal(t *testing.T) {
 		assert.Equal(t, edge.Target, decoded.Target)
 		assert.Equal(t, edge.Type, decoded.Type)
 	})
+
+	t.Run("omitempty metadata when not set", func(t *testing.T) {
+		edge := Edge{Source: "a", Target: "b", Type: "implicit"}
+
+		data, err := json.Marshal(edge)
+		require.NoError(t, err)
+
+		assert.NotContains(t, string(data), "metadata")
+	})
+
+	t.Run("marshal edge with metadata", func(t *testing.T) {
+		edge := Edge{
+			Source:   "0.0.0.0/0",
+			Target:   "aws_security_group.web",
+			Type:     "ingress",
+			Metadata: map[string]any{"ports": "443"},
+		}
+
+		data, err := json.Marshal(edge)
+		require.NoError(t, err)
+
+		var decoded Edge
+		require.NoError(t, json.Unmarshal(data, &decoded))
+
+		assert.Equal(t, "443", decoded.Metadata["ports"])
+	})
 }
 
 func TestStatsUnmarshal(t *testing.T) {
@@ -373,6 +416,21 @@ func TestStatsUnmarshal(t *testing.T) {
 		assert.Equal(t, 3, stats.TotalEdges)
 		assert.Nil(t, stats.ResourcesByType)
 		assert.Nil(t, stats.ResourcesByMode)
+		assert.Empty(t, stats.Digest)
+	})
+
+	t.Run("stats with digest", func(t *testing.T) {
+		jsonData := `{
+			"total_nodes": 1,
+			"total_edges": 0,
+			"digest": "sha256:abc123"
+		}`
+
+		var stats Stats
+		err := json.Unmarshal([]byte(jsonData), &stats)
+
+		require.NoError(t, err)
+		assert.Equal(t, "sha256:abc123", stats.Digest)
 	})
 }
 
@@ -0,0 +1,27 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+// Inventory lists the resources of a cloud account, as exported by the
+// cloud provider rather than recorded by Terraform.
+type Inventory struct {
+	Resources []InventoryResource `json:"resources"`
+}
+
+type InventoryResource struct {
+	ID      string `json:"id,omitempty"`
+	ARN     string `json:"arn,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Region  string `json:"region,omitempty"`
+	Account string `json:"account,omitempty"`
+}
+
+// UnmanagedReport lists the resources of an inventory that no stored state
+// manages, and counts them by type.
+type UnmanagedReport struct {
+	Total     int                 `json:"total"`
+	Managed   int                 `json:"managed"`
+	Unmanaged []InventoryResource `json:"unmanaged"`
+	ByType    map[string]int      `json:"by_type"`
+}
@@ -0,0 +1,31 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+// LimitsReport compares the resource counts of a state with the default
+// quotas of their provider, and projects their growth over the history of
+// the lineage HorizonDays ahead. Samples counts the state versions the growth
+// was fitted to, including the analyzed one.
+type LimitsReport struct {
+	Lineage     string       `json:"lineage"`
+	Samples     int          `json:"samples"`
+	HorizonDays int          `json:"horizon_days"`
+	Usage       []LimitUsage `json:"usage"`
+	Warnings    []Warning    `json:"warnings,omitempty"`
+}
+
+// LimitUsage is the usage of a quota within a group of its scope: a region,
+// the account, a VPC, or a single resource for per-resource quotas such as
+// the security groups of a network interface. DaysToLimit is set when the
+// count grows toward the quota.
+type LimitUsage struct {
+	Limit        string  `json:"limit"`
+	Type         string  `json:"type"`
+	Scope        string  `json:"scope"`
+	Group        string  `json:"group,omitempty"`
+	Quota        int     `json:"quota"`
+	Current      int     `json:"current"`
+	GrowthPerDay float64 `json:"growth_per_day"`
+	Projected    int     `json:"projected"`
+	DaysToLimit  *int    `json:"days_to_limit,omitempty"`
+}
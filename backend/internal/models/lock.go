@@ -0,0 +1,8 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import "github.com/terrascope/core/pkg/terrascope/model"
+
+// LockInfo is part of the public API, see model.
+type LockInfo = model.LockInfo
@@ -0,0 +1,23 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockInfoMarshal(t *testing.T) {
+	t.Run("optional fields are omitted", func(t *testing.T) {
+		lock := LockInfo{ID: "a", Who: "ci-runner-42", Created: time.Date(2024, 3, 5, 10, 15, 30, 0, time.UTC)}
+
+		data, err := json.Marshal(lock)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"id":"a","who":"ci-runner-42","created":"2024-03-05T10:15:30Z"}`, string(data))
+	})
+}
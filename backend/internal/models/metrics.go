@@ -0,0 +1,43 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import "time"
+
+// InfrastructureSeries is the growth of a state lineage over its stored
+// versions, oldest first.
+type InfrastructureSeries struct {
+	Lineage string                `json:"lineage"`
+	Points  []InfrastructurePoint `json:"points"`
+}
+
+// InfrastructurePoint measures the managed resources of one state version.
+type InfrastructurePoint struct {
+	Serial     int            `json:"serial"`
+	StoredAt   time.Time      `json:"stored_at"`
+	Resources  int            `json:"resources"`
+	ByType     map[string]int `json:"by_type"`
+	ByProvider map[string]int `json:"by_provider"`
+	Cost       CostEstimate   `json:"cost"`
+}
+
+// CostEstimate is a rough monthly on-demand cost of the resources in a state.
+// Resources whose type has no known price count as unpriced.
+type CostEstimate struct {
+	MonthlyUSD float64 `json:"monthly_usd"`
+	Priced     int     `json:"priced"`
+	Unpriced   int     `json:"unpriced"`
+}
+
+// GraphCacheStats describes the in-process graph cache: its entries, the
+// bytes of documents they were built from against its bound, and how often
+// lookups found an entry since the server started.
+type GraphCacheStats struct {
+	Entries   int     `json:"entries"`
+	Bytes     int64   `json:"bytes"`
+	MaxBytes  int64   `json:"max_bytes"`
+	Hits      int64   `json:"hits"`
+	Misses    int64   `json:"misses"`
+	Evictions int64   `json:"evictions"`
+	HitRate   float64 `json:"hit_rate"`
+}
@@ -0,0 +1,58 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+// ModuleManifest mirrors .terraform/modules/modules.json, which terraform init
+// writes with the resolved source and version of every module call.
+type ModuleManifest struct {
+	Modules []ModuleManifestEntry `json:"Modules"`
+}
+
+type ModuleManifestEntry struct {
+	Key     string `json:"Key"`
+	Source  string `json:"Source"`
+	Version string `json:"Version,omitempty"`
+	Dir     string `json:"Dir"`
+}
+
+type ModuleReport struct {
+	Modules []ModuleInfo `json:"modules"`
+}
+
+type ModuleInfo struct {
+	Key           string   `json:"key"`
+	Instances     []string `json:"instances,omitempty"`
+	Resources     int      `json:"resources"`
+	Source        string   `json:"source,omitempty"`
+	Version       string   `json:"version,omitempty"`
+	LatestVersion string   `json:"latest_version,omitempty"`
+	Outdated      bool     `json:"outdated"`
+	RegistryError string   `json:"registry_error,omitempty"`
+}
+
+// ExtractionReport lists the groups of resources repeated across a state that
+// could be refactored into a module.
+type ExtractionReport struct {
+	Suggestions []ModuleExtraction `json:"suggestions"`
+}
+
+// ModuleExtraction is a group of connected resources repeated with the same
+// types and the same dependencies between them. Every occurrence lists the
+// addresses of its resources in the order of Types, and Variables are the
+// attributes whose values differ between occurrences.
+type ModuleExtraction struct {
+	ID          string           `json:"id"`
+	Types       []string         `json:"types"`
+	Edges       int              `json:"edges"`
+	Occurrences [][]string       `json:"occurrences"`
+	Variables   []ModuleVariable `json:"variables"`
+}
+
+// ModuleVariable is a suggested input of an extracted module: the attributes
+// that take its value, addressed within the first occurrence, and its value
+// in every occurrence.
+type ModuleVariable struct {
+	Name       string   `json:"name"`
+	Attributes []string `json:"attributes"`
+	Values     []any    `json:"values"`
+}
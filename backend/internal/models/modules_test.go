@@ -0,0 +1,37 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleManifestUnmarshal(t *testing.T) {
+	t.Run("reads terraform's manifest field names", func(t *testing.T) {
+		data := `{"Modules": [{"Key": "vpc", "Source": "registry.terraform.io/terraform-aws-modules/vpc/aws", "Version": "5.1.0", "Dir": ".terraform/modules/vpc"}]}`
+
+		var manifest ModuleManifest
+		require.NoError(t, json.Unmarshal([]byte(data), &manifest))
+
+		require.Len(t, manifest.Modules, 1)
+		assert.Equal(t, ModuleManifestEntry{
+			Key:     "vpc",
+			Source:  "registry.terraform.io/terraform-aws-modules/vpc/aws",
+			Version: "5.1.0",
+			Dir:     ".terraform/modules/vpc",
+		}, manifest.Modules[0])
+	})
+}
+
+func TestModuleInfoMarshal(t *testing.T) {
+	t.Run("omits unknown source and registry data", func(t *testing.T) {
+		data, err := json.Marshal(ModuleInfo{Key: "vpc", Instances: []string{"module.vpc"}, Resources: 2})
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"key":"vpc","instances":["module.vpc"],"resources":2,"outdated":false}`, string(data))
+	})
+}
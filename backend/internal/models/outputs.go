@@ -0,0 +1,43 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+// Kinds of the nodes of an OutputGraph.
+const (
+	OutputNodeOutput      = "output"
+	OutputNodeResource    = "resource"
+	OutputNodeRemoteState = "remote_state"
+)
+
+// Types of the edges of an OutputGraph.
+const (
+	OutputEdgeValue    = "value"
+	OutputEdgeConsumer = "consumer"
+)
+
+// OutputGraph links the outputs of a state to the resources they expose and
+// to the terraform_remote_state data sources of other states that read them.
+// Edges point from the output to the resource whose attribute holds its
+// value, and from the consumer to the output.
+type OutputGraph struct {
+	Nodes []OutputNode `json:"nodes"`
+	Edges []OutputEdge `json:"edges"`
+}
+
+// OutputNode is an output (output.<name>), a resource instance of the state,
+// or a remote state data source of another lineage, whose ID is prefixed
+// with that lineage.
+type OutputNode struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Lineage   string `json:"lineage,omitempty"`
+	Sensitive bool   `json:"sensitive,omitempty"`
+}
+
+type OutputEdge struct {
+	Source    string `json:"source"`
+	Target    string `json:"target"`
+	Type      string `json:"type"`
+	Attribute string `json:"attribute,omitempty"`
+}
@@ -0,0 +1,47 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import "github.com/terrascope/core/pkg/terrascope/model"
+
+// Plan is the subset of the `terraform show -json` plan representation that
+// describes pending resource changes.
+type Plan struct {
+	FormatVersion    string               `json:"format_version"`
+	TerraformVersion string               `json:"terraform_version"`
+	ResourceChanges  []PlanResourceChange `json:"resource_changes,omitempty"`
+}
+
+type PlanResourceChange struct {
+	Address       string     `json:"address"`
+	ModuleAddress string     `json:"module_address,omitempty"`
+	Mode          string     `json:"mode"`
+	Type          string     `json:"type"`
+	Name          string     `json:"name"`
+	Index         any        `json:"index,omitempty"`
+	Deposed       string     `json:"deposed,omitempty"`
+	ProviderName  string     `json:"provider_name"`
+	Change        PlanChange `json:"change"`
+	ActionReason  string     `json:"action_reason,omitempty"`
+}
+
+type PlanChange struct {
+	Actions      []string `json:"actions"`
+	Before       any      `json:"before"`
+	After        any      `json:"after"`
+	AfterUnknown any      `json:"after_unknown,omitempty"`
+	ReplacePaths [][]any  `json:"replace_paths,omitempty"`
+}
+
+// Pending actions of a node, as summarized from a plan's change actions.
+const (
+	ActionNoOp    = model.ActionNoOp
+	ActionCreate  = model.ActionCreate
+	ActionRead    = model.ActionRead
+	ActionUpdate  = model.ActionUpdate
+	ActionDelete  = model.ActionDelete
+	ActionReplace = model.ActionReplace
+)
+
+// NodeChange is part of the public API, see model.
+type NodeChange = model.NodeChange
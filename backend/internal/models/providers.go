@@ -0,0 +1,40 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+// ProviderRequirement is one entry of a required_providers block, in the shape
+// terraform-config-inspect --json emits.
+type ProviderRequirement struct {
+	Source             string   `json:"source,omitempty"`
+	VersionConstraints []string `json:"version_constraints,omitempty"`
+}
+
+type ProviderReport struct {
+	Providers []ProviderInfo `json:"providers"`
+	Warnings  []Warning      `json:"warnings,omitempty"`
+}
+
+type ProviderInfo struct {
+	Address       string   `json:"address"`
+	LocalName     string   `json:"local_name,omitempty"`
+	Constraints   []string `json:"constraints,omitempty"`
+	Version       string   `json:"version,omitempty"`
+	Hashes        []string `json:"hashes,omitempty"`
+	LatestVersion string   `json:"latest_version,omitempty"`
+	Resources     int      `json:"resources"`
+}
+
+// DependencyLock holds the provider selections of a dependency lock file
+// (.terraform.lock.hcl), by provider address.
+type DependencyLock struct {
+	Providers map[string]LockedProvider `json:"providers"`
+}
+
+// LockedProvider is a provider block of the dependency lock file: the version
+// terraform init selected, the constraints it was selected against and the
+// checksums of its packages.
+type LockedProvider struct {
+	Version     string   `json:"version"`
+	Constraints string   `json:"constraints,omitempty"`
+	Hashes      []string `json:"hashes,omitempty"`
+}
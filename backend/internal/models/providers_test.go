@@ -0,0 +1,35 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderRequirementUnmarshal(t *testing.T) {
+	t.Run("reads config-inspect required_providers entries", func(t *testing.T) {
+		data := `{"aws": {"source": "hashicorp/aws", "version_constraints": ["~> 5.0", "!= 5.1.0"]}, "random": {}}`
+
+		var required map[string]ProviderRequirement
+		require.NoError(t, json.Unmarshal([]byte(data), &required))
+
+		assert.Equal(t, "hashicorp/aws", required["aws"].Source)
+		assert.Equal(t, []string{"~> 5.0", "!= 5.1.0"}, required["aws"].VersionConstraints)
+		assert.Empty(t, required["random"].Source)
+	})
+}
+
+func TestProviderReportMarshal(t *testing.T) {
+	t.Run("omits empty warnings and optional fields", func(t *testing.T) {
+		report := ProviderReport{Providers: []ProviderInfo{{Address: "registry.terraform.io/hashicorp/aws", Resources: 3}}}
+
+		data, err := json.Marshal(report)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"providers":[{"address":"registry.terraform.io/hashicorp/aws","resources":3}]}`, string(data))
+	})
+}
@@ -0,0 +1,30 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import "time"
+
+// Usage counts what a project uses: the states it parsed and the minutes its
+// analysis jobs ran in a month, and the bytes of state it stores.
+type Usage struct {
+	Parses      int64   `json:"parses"`
+	StoredBytes int64   `json:"stored_bytes"`
+	JobMinutes  float64 `json:"job_minutes"`
+}
+
+// QuotaConfig bounds the usage of every project, with overrides for some
+// projects. A zero limit is unlimited.
+type QuotaConfig struct {
+	Default  Usage            `json:"default"`
+	Projects map[string]Usage `json:"projects,omitempty"`
+}
+
+// ProjectUsage is the usage of a project in the current month against its
+// quota.
+type ProjectUsage struct {
+	Project  string    `json:"project"`
+	Period   string    `json:"period"`
+	Usage    Usage     `json:"usage"`
+	Quota    Usage     `json:"quota"`
+	ResetsAt time.Time `json:"resets_at"`
+}
@@ -0,0 +1,25 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import "github.com/terrascope/core/pkg/terrascope/model"
+
+// Provider schemas are part of the public API, see model.
+
+type (
+	ProviderSchemas = model.ProviderSchemas
+	ProviderSchema  = model.ProviderSchema
+	Schema          = model.Schema
+	SchemaBlock     = model.SchemaBlock
+	SchemaAttribute = model.SchemaAttribute
+	SchemaBlockType = model.SchemaBlockType
+)
+
+// SchemaSummary describes a stored provider schemas document, which graph
+// requests refer to by digest.
+type SchemaSummary struct {
+	Digest        string   `json:"digest"`
+	FormatVersion string   `json:"format_version"`
+	Providers     []string `json:"providers"`
+	ResourceTypes int      `json:"resource_types"`
+}
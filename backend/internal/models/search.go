@@ -0,0 +1,42 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+// SearchHit is an attribute of a resource instance whose key or value matches
+// a search, with the serials of the versions of the lineage in which the
+// instance has that value.
+type SearchHit struct {
+	Lineage   string `json:"lineage"`
+	Address   string `json:"address"`
+	Attribute string `json:"attribute"`
+	Value     string `json:"value"`
+	Serials   []int  `json:"serials"`
+}
+
+// SearchResults lists the hits of a search over the attributes of the stored
+// states, ordered by lineage, address and attribute. Truncated is set when
+// more hits matched than were returned.
+type SearchResults struct {
+	Query     string      `json:"query"`
+	Hits      []SearchHit `json:"hits"`
+	Truncated bool        `json:"truncated,omitempty"`
+}
+
+// LookupMatch is a resource instance that the latest version of a stored
+// state manages under a cloud ID, the Attribute of the instance that holds
+// it.
+type LookupMatch struct {
+	Lineage   string `json:"lineage"`
+	Serial    int    `json:"serial"`
+	Project   string `json:"project,omitempty"`
+	Address   string `json:"address"`
+	Module    string `json:"module,omitempty"`
+	Type      string `json:"type"`
+	Attribute string `json:"attribute"`
+}
+
+// LookupResult lists the resource instances managed under a cloud ID.
+type LookupResult struct {
+	ID      string        `json:"id"`
+	Matches []LookupMatch `json:"matches"`
+}
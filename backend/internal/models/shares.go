@@ -0,0 +1,30 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import "time"
+
+// ShareRequest asks for a read-only link to the graph of a stored state
+// version, the latest of the lineage when Serial is zero. Module limits the
+// link to a module subtree, Redact sanitizes node metadata, and ExpiresIn is a
+// duration such as 72h.
+type ShareRequest struct {
+	Lineage   string `json:"lineage"`
+	Serial    int    `json:"serial,omitempty"`
+	Module    string `json:"module,omitempty"`
+	Redact    bool   `json:"redact,omitempty"`
+	ExpiresIn string `json:"expires_in,omitempty"`
+}
+
+// Share is a signed link to the graph of a stored state version. The token
+// carries the version, filter and expiry it grants, so it needs no
+// server-side record.
+type Share struct {
+	Token     string    `json:"token"`
+	URL       string    `json:"url"`
+	Lineage   string    `json:"lineage"`
+	Serial    int       `json:"serial"`
+	Module    string    `json:"module,omitempty"`
+	Redact    bool      `json:"redact,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
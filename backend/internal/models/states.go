@@ -0,0 +1,50 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import "time"
+
+// StateVersion describes one stored version of a state lineage. Anomalies are
+// the suspicious changes found against the previous version when it was
+// stored. The document of an Archived version is in cold storage and must be
+// rehydrated before it can be read.
+type StateVersion struct {
+	Lineage          string    `json:"lineage"`
+	Serial           int       `json:"serial"`
+	TerraformVersion string    `json:"terraform_version"`
+	Resources        int       `json:"resources"`
+	Digest           string    `json:"digest"`
+	Size             int       `json:"size"`
+	StoredAt         time.Time `json:"stored_at"`
+	Project          string    `json:"project,omitempty"`
+	Anomalies        []Alert   `json:"anomalies,omitempty"`
+	Archived         bool      `json:"archived,omitempty"`
+}
+
+// StoredGraph is the dependency graph of a stored state version: in full, or
+// as a patch of the graph of the Base serial of the same lineage, Depth
+// patches away from a full graph. Digest is the digest of the graph, and
+// BaseDigest the digest of the graph the patch applies to.
+type StoredGraph struct {
+	Base       int         `json:"base,omitempty"`
+	BaseDigest string      `json:"base_digest,omitempty"`
+	Depth      int         `json:"depth,omitempty"`
+	Digest     string      `json:"digest"`
+	Graph      *Graph      `json:"graph,omitempty"`
+	Patch      *GraphPatch `json:"patch,omitempty"`
+}
+
+// StateHistory summarizes the stored versions of a lineage.
+type StateHistory struct {
+	Lineage  string       `json:"lineage"`
+	Versions int          `json:"versions"`
+	Latest   StateVersion `json:"latest"`
+}
+
+// TrashEntry is a soft-deleted lineage, restorable until ExpiresAt.
+type TrashEntry struct {
+	Lineage   string    `json:"lineage"`
+	Versions  int       `json:"versions"`
+	DeletedAt time.Time `json:"deleted_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
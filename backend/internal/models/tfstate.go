@@ -2,36 +2,15 @@
 // It includes entity definitions and methods for persistence and validation.
 package models
 
-type TerraformState struct {
-	Version          int               `json:"version"`
-	TerraformVersion string            `json:"terraform_version"`
-	Serial           int               `json:"serial"`
-	Lineage          string            `json:"lineage"`
-	Outputs          map[string]Output `json:"outputs,omitempty"`
-	Resources        []ResourceState   `json:"resources"`
-}
+import "github.com/terrascope/core/pkg/terrascope/model"
 
-type Output struct {
-	Value     any  `json:"value"`
-	Type      any  `json:"type"`
-	Sensitive bool `json:"sensitive,omitempty"`
-}
+// The parsed state documents are part of the public API, see model.
 
-type ResourceState struct {
-	Mode      string             `json:"mode"`
-	Type      string             `json:"type"`
-	Name      string             `json:"name"`
-	Provider  string             `json:"provider"`
-	Module    string             `json:"module,omitempty"`
-	Instances []ResourceInstance `json:"instances"`
-	DependsOn []string           `json:"depends_on,omitempty"`
-}
-
-type ResourceInstance struct {
-	SchemaVersion  int               `json:"schema_version"`
-	Attributes     map[string]any    `json:"attributes"`
-	AttributesFlat map[string]string `json:"attributes_flat,omitempty"`
-	Private        string            `json:"private,omitempty"`
-	Dependencies   []string          `json:"dependencies,omitempty"`
-	IndexKey       any               `json:"index_key,omitempty"`
-}
+type (
+	TerraformState   = model.TerraformState
+	Output           = model.Output
+	ResourceState    = model.ResourceState
+	ResourceInstance = model.ResourceInstance
+	AttributePath    = model.AttributePath
+	PathStep         = model.PathStep
+)
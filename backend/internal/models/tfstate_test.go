@@ -334,6 +334,43 @@ func TestTerraformStateUnmarshal(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, "eyJzY2hlbWFfdmVyc2lvbiI6IjEifQ==", state.Resources[0].Instances[0].Private)
 	})
+
+	t.Run("instance with sensitive attributes", func(t *testing.T) {
+		jsonData := `{
+			"version": 4,
+			"terraform_version": "1.5.0",
+			"serial": 1,
+			"lineage": "abc-123",
+			"resources": [
+				{
+					"mode": "managed",
+					"type": "aws_db_instance",
+					"name": "main",
+					"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+					"instances": [
+						{
+							"schema_version": 1,
+							"attributes": {"id": "db-1", "password": "hunter2"},
+							"sensitive_attributes": [
+								[{"type": "get_attr", "value": "password"}],
+								[{"type": "get_attr", "value": "tags"}, {"type": "index", "value": {"value": "secret", "type": "string"}}]
+							]
+						}
+					]
+				}
+			]
+		}`
+
+		var state TerraformState
+		err := json.Unmarshal([]byte(jsonData), &state)
+
+		require.NoError(t, err)
+		paths := state.Resources[0].Instances[0].SensitiveAttributes
+		require.Len(t, paths, 2)
+		assert.Equal(t, "get_attr", paths[0][0].Type)
+		assert.Equal(t, "password", paths[0][0].Key())
+		assert.Equal(t, "secret", paths[1][1].Key())
+	})
 }
 
 func TestTerraformStateMarshal(t *testing.T) {
@@ -500,3 +537,64 @@ func TestOutputFields(t *testing.T) {
 		assert.False(t, output.Sensitive)
 	})
 }
+
+func TestAttributePathString(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     AttributePath
+		expected string
+	}{
+		{
+			name:     "top-level attribute",
+			path:     AttributePath{{Type: "get_attr", Value: "password"}},
+			expected: "password",
+		},
+		{
+			name: "map key",
+			path: AttributePath{
+				{Type: "get_attr", Value: "tags"},
+				{Type: "index", Value: map[string]any{"value": "owner", "type": "string"}},
+			},
+			expected: `tags["owner"]`,
+		},
+		{
+			name: "list index then attribute",
+			path: AttributePath{
+				{Type: "get_attr", Value: "ingress"},
+				{Type: "index", Value: map[string]any{"value": float64(0), "type": "number"}},
+				{Type: "get_attr", Value: "cidr_blocks"},
+			},
+			expected: "ingress[0].cidr_blocks",
+		},
+		{
+			name:     "empty path",
+			path:     AttributePath{},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.path.String())
+		})
+	}
+}
+
+func TestResourceInstanceNodeStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		instance ResourceInstance
+		expected string
+	}{
+		{"healthy", ResourceInstance{}, ""},
+		{"tainted", ResourceInstance{Status: "tainted"}, NodeStatusTainted},
+		{"deposed", ResourceInstance{Deposed: "2f1c9a8e"}, NodeStatusDeposed},
+		{"deposed takes precedence", ResourceInstance{Status: "tainted", Deposed: "2f1c9a8e"}, NodeStatusDeposed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.instance.NodeStatus())
+		})
+	}
+}
@@ -0,0 +1,31 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import "time"
+
+// UploadRequest starts a chunked upload of a document too large for a single
+// request. Target names the endpoint the assembled document is handed to.
+// Size and SHA256, when given, are checked against the assembled document.
+type UploadRequest struct {
+	Target string `json:"target,omitempty"`
+	Size   int64  `json:"size,omitempty"`
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// Upload is a chunked upload in progress. Parts lists the numbers of the
+// parts received so far, so that an interrupted client can resume by sending
+// only the missing ones. Project is the project whose quota the upload
+// counts against.
+type Upload struct {
+	ID          string    `json:"id"`
+	Target      string    `json:"target"`
+	Project     string    `json:"project"`
+	Size        int64     `json:"size,omitempty"`
+	SHA256      string    `json:"sha256,omitempty"`
+	MaxPartSize int64     `json:"max_part_size"`
+	Parts       []int     `json:"parts"`
+	Received    int64     `json:"received"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
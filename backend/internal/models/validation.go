@@ -0,0 +1,24 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+type ValidationReport struct {
+	Valid            bool              `json:"valid"`
+	Version          int               `json:"version,omitempty"`
+	TerraformVersion string            `json:"terraform_version,omitempty"`
+	Serial           int               `json:"serial"`
+	Lineage          string            `json:"lineage,omitempty"`
+	Resources        int               `json:"resources"`
+	Instances        int               `json:"instances"`
+	Issues           []ValidationIssue `json:"issues"`
+}
+
+// ValidationIssue is a problem Validate found in a tfstate document. Code is
+// the stable code of its Type, listed by GET /codes.
+type ValidationIssue struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code,omitempty"`
+	Type     string `json:"type"`
+	Address  string `json:"address,omitempty"`
+	Message  string `json:"message"`
+}
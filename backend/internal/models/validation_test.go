@@ -0,0 +1,27 @@
+// Package models defines the core data structures and database interaction logic.
+// It includes entity definitions and methods for persistence and validation.
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationReportMarshal(t *testing.T) {
+	t.Run("empty report keeps an empty issue list", func(t *testing.T) {
+		data, err := json.Marshal(ValidationReport{Valid: true, Issues: []ValidationIssue{}})
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"valid":true,"serial":0,"resources":0,"instances":0,"issues":[]}`, string(data))
+	})
+
+	t.Run("issue omits empty address", func(t *testing.T) {
+		data, err := json.Marshal(ValidationIssue{Severity: "error", Code: "TS603", Type: "missing_version", Message: "missing version field"})
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"severity":"error","code":"TS603","type":"missing_version","message":"missing version field"}`, string(data))
+	})
+}
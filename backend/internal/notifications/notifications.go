@@ -0,0 +1,233 @@
+// Package notifications delivers messages about state changes to external
+// channels such as Slack, email, generic webhooks and PagerDuty.
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+)
+
+// Message severities, ordered from least to most urgent. They match the
+// severities of the PagerDuty Events API.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityError    = "error"
+	SeverityCritical = "critical"
+)
+
+// Message is what a channel delivers. Details carry the event that caused it,
+// such as a triggered alert, for channels that forward structured payloads.
+type Message struct {
+	Title    string `json:"title"`
+	Text     string `json:"text"`
+	Severity string `json:"severity"`
+	Lineage  string `json:"lineage,omitempty"`
+	Details  any    `json:"details,omitempty"`
+}
+
+// Channel delivers messages to one destination.
+type Channel interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Channel types accepted in a Config.
+const (
+	TypeSlack     = "slack"
+	TypeSMTP      = "smtp"
+	TypeWebhook   = "webhook"
+	TypePagerDuty = "pagerduty"
+)
+
+// ChannelConfig configures a named channel. Which fields apply depends on the
+// type: url for slack and webhook, routing_key (and optionally url) for
+// pagerduty, and the smtp_* fields for smtp.
+type ChannelConfig struct {
+	Type         string   `json:"type"`
+	URL          string   `json:"url,omitempty"`
+	RoutingKey   string   `json:"routing_key,omitempty"`
+	SMTPAddr     string   `json:"smtp_addr,omitempty"`
+	SMTPUsername string   `json:"smtp_username,omitempty"`
+	SMTPPassword string   `json:"smtp_password,omitempty"`
+	From         string   `json:"from,omitempty"`
+	To           []string `json:"to,omitempty"`
+}
+
+// Override replaces the default channels for lineages matching a glob
+// pattern, so that each project can route its notifications elsewhere.
+type Override struct {
+	Lineage  string   `json:"lineage"`
+	Channels []string `json:"channels"`
+}
+
+// Config names the available channels, the channels used when an event names
+// none, and per-lineage overrides of those defaults. The first matching
+// override wins.
+type Config struct {
+	Channels  map[string]ChannelConfig `json:"channels"`
+	Defaults  []string                 `json:"defaults,omitempty"`
+	Overrides []Override               `json:"overrides,omitempty"`
+}
+
+// Dispatcher routes messages to the configured channels.
+type Dispatcher struct {
+	channels  map[string]Channel
+	defaults  []string
+	overrides []Override
+}
+
+// New builds the channels of the config and checks that the defaults and
+// overrides only name configured channels.
+func New(cfg Config) (*Dispatcher, error) {
+	d := &Dispatcher{
+		channels:  make(map[string]Channel, len(cfg.Channels)),
+		defaults:  cfg.Defaults,
+		overrides: cfg.Overrides,
+	}
+
+	for name, channelConfig := range cfg.Channels {
+		channel, err := newChannel(channelConfig)
+		if err != nil {
+			return nil, fmt.Errorf("channel %s: %w", name, err)
+		}
+		d.channels[name] = channel
+	}
+
+	if err := d.checkNames(cfg.Defaults); err != nil {
+		return nil, fmt.Errorf("defaults: %w", err)
+	}
+
+	for _, override := range cfg.Overrides {
+		if _, err := path.Match(override.Lineage, ""); err != nil {
+			return nil, fmt.Errorf("invalid override pattern %q", override.Lineage)
+		}
+		if err := d.checkNames(override.Channels); err != nil {
+			return nil, fmt.Errorf("override %s: %w", override.Lineage, err)
+		}
+	}
+
+	return d, nil
+}
+
+// FromEnv loads the config file named by TERRASCOPE_NOTIFICATIONS_CONFIG. It
+// returns a dispatcher without channels when the variable is unset or the file
+// is invalid.
+func FromEnv() *Dispatcher {
+	empty := &Dispatcher{channels: map[string]Channel{}}
+
+	file := os.Getenv("TERRASCOPE_NOTIFICATIONS_CONFIG")
+	if file == "" {
+		return empty
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		log.Printf("Ignoring TERRASCOPE_NOTIFICATIONS_CONFIG: %v", err)
+		return empty
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		log.Printf("Ignoring TERRASCOPE_NOTIFICATIONS_CONFIG: %v", err)
+		return empty
+	}
+
+	d, err := New(cfg)
+	if err != nil {
+		log.Printf("Ignoring TERRASCOPE_NOTIFICATIONS_CONFIG: %v", err)
+		return empty
+	}
+
+	return d
+}
+
+func newChannel(cfg ChannelConfig) (Channel, error) {
+	switch cfg.Type {
+	case TypeSlack:
+		if cfg.URL == "" {
+			return nil, errors.New("missing url")
+		}
+		return NewSlackChannel(cfg.URL), nil
+	case TypeWebhook:
+		if cfg.URL == "" {
+			return nil, errors.New("missing url")
+		}
+		return NewWebhookChannel(cfg.URL), nil
+	case TypePagerDuty:
+		if cfg.RoutingKey == "" {
+			return nil, errors.New("missing routing_key")
+		}
+		channel := NewPagerDutyChannel(cfg.RoutingKey)
+		if cfg.URL != "" {
+			channel.URL = cfg.URL
+		}
+		return channel, nil
+	case TypeSMTP:
+		if cfg.SMTPAddr == "" || cfg.From == "" || len(cfg.To) == 0 {
+			return nil, errors.New("smtp_addr, from and to are required")
+		}
+		return &SMTPChannel{
+			Addr:     cfg.SMTPAddr,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.From,
+			To:       cfg.To,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown channel type %q", cfg.Type)
+	}
+}
+
+func (d *Dispatcher) checkNames(names []string) error {
+	for _, name := range names {
+		if _, ok := d.channels[name]; !ok {
+			return fmt.Errorf("unknown channel %q", name)
+		}
+	}
+
+	return nil
+}
+
+// Resolve returns the named channels, or when names is empty the channels
+// configured for the lineage: those of the first matching override, else the
+// defaults.
+func (d *Dispatcher) Resolve(lineage string, names []string) ([]Channel, error) {
+	if len(names) == 0 {
+		names = d.defaults
+		for _, override := range d.overrides {
+			if matched, _ := path.Match(override.Lineage, lineage); matched {
+				names = override.Channels
+				break
+			}
+		}
+	}
+
+	if err := d.checkNames(names); err != nil {
+		return nil, err
+	}
+
+	channels := make([]Channel, 0, len(names))
+	for _, name := range names {
+		channels = append(channels, d.channels[name])
+	}
+
+	return channels, nil
+}
+
+// Send delivers the message to every channel and returns the joined errors.
+func Send(ctx context.Context, channels []Channel, msg Message) error {
+	var errs []error
+
+	for _, channel := range channels {
+		if err := channel.Send(ctx, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,136 @@
+// Package notifications delivers messages about state changes to external
+// channels such as Slack, email, generic webhooks and PagerDuty.
+package notifications
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingChannel struct {
+	sent []Message
+	err  error
+}
+
+func (c *recordingChannel) Send(_ context.Context, msg Message) error {
+	c.sent = append(c.sent, msg)
+	return c.err
+}
+
+func testConfig() Config {
+	return Config{
+		Channels: map[string]ChannelConfig{
+			"ops":     {Type: TypeSlack, URL: "https://hooks.slack.com/services/x"},
+			"oncall":  {Type: TypePagerDuty, RoutingKey: "key"},
+			"audit":   {Type: TypeWebhook, URL: "https://audit.example.com/hook"},
+			"finance": {Type: TypeSMTP, SMTPAddr: "smtp.example.com:587", From: "terrascope@example.com", To: []string{"finance@example.com"}},
+		},
+		Defaults:  []string{"ops"},
+		Overrides: []Override{{Lineage: "prod-*", Channels: []string{"ops", "oncall"}}},
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Run("builds every channel type", func(t *testing.T) {
+		d, err := New(testConfig())
+		require.NoError(t, err)
+
+		assert.IsType(t, &SlackChannel{}, d.channels["ops"])
+		assert.IsType(t, &PagerDutyChannel{}, d.channels["oncall"])
+		assert.IsType(t, &WebhookChannel{}, d.channels["audit"])
+		assert.IsType(t, &SMTPChannel{}, d.channels["finance"])
+	})
+
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		err    string
+	}{
+		{"unknown type", func(c *Config) { c.Channels["ops"] = ChannelConfig{Type: "carrier-pigeon"} }, "unknown channel type"},
+		{"missing url", func(c *Config) { c.Channels["ops"] = ChannelConfig{Type: TypeSlack} }, "missing url"},
+		{"incomplete smtp", func(c *Config) { c.Channels["finance"] = ChannelConfig{Type: TypeSMTP, SMTPAddr: "smtp:25"} }, "required"},
+		{"unknown default", func(c *Config) { c.Defaults = []string{"nobody"} }, `unknown channel "nobody"`},
+		{"unknown override channel", func(c *Config) { c.Overrides[0].Channels = []string{"nobody"} }, `unknown channel "nobody"`},
+		{"bad override pattern", func(c *Config) { c.Overrides[0].Lineage = "prod-[" }, "invalid override pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			tt.modify(&cfg)
+
+			_, err := New(cfg)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	d, err := New(testConfig())
+	require.NoError(t, err)
+
+	channels, err := d.Resolve("staging", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []Channel{d.channels["ops"]}, channels)
+
+	channels, err = d.Resolve("prod-eu", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []Channel{d.channels["ops"], d.channels["oncall"]}, channels)
+
+	channels, err = d.Resolve("prod-eu", []string{"audit"})
+	require.NoError(t, err)
+	assert.Equal(t, []Channel{d.channels["audit"]}, channels)
+
+	_, err = d.Resolve("prod-eu", []string{"nobody"})
+	assert.ErrorContains(t, err, "unknown channel")
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Run("unset", func(t *testing.T) {
+		t.Setenv("TERRASCOPE_NOTIFICATIONS_CONFIG", "")
+
+		channels, err := FromEnv().Resolve("prod", nil)
+		require.NoError(t, err)
+		assert.Empty(t, channels)
+	})
+
+	t.Run("loads the config file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "notifications.json")
+		require.NoError(t, os.WriteFile(file, []byte(`{
+			"channels": {"ops": {"type": "webhook", "url": "https://hooks.example.com/ops"}},
+			"defaults": ["ops"]
+		}`), 0o600))
+		t.Setenv("TERRASCOPE_NOTIFICATIONS_CONFIG", file)
+
+		channels, err := FromEnv().Resolve("prod", nil)
+		require.NoError(t, err)
+		require.Len(t, channels, 1)
+		assert.Equal(t, "https://hooks.example.com/ops", channels[0].(*WebhookChannel).URL)
+	})
+
+	t.Run("ignores invalid files", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "notifications.json")
+		require.NoError(t, os.WriteFile(file, []byte(`{"defaults": ["nobody"]}`), 0o600))
+		t.Setenv("TERRASCOPE_NOTIFICATIONS_CONFIG", file)
+
+		channels, err := FromEnv().Resolve("prod", nil)
+		require.NoError(t, err)
+		assert.Empty(t, channels)
+	})
+}
+
+func TestSend(t *testing.T) {
+	ok, failing := &recordingChannel{}, &recordingChannel{err: errors.New("boom")}
+
+	err := Send(context.Background(), []Channel{failing, ok}, Message{Title: "hello"})
+
+	assert.ErrorContains(t, err, "boom")
+	assert.Len(t, ok.sent, 1)
+	assert.Len(t, failing.sent, 1)
+}
@@ -0,0 +1,64 @@
+// Package notifications delivers messages about state changes to external
+// channels such as Slack, email, generic webhooks and PagerDuty.
+package notifications
+
+import (
+	"context"
+	"net/http"
+)
+
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutyChannel triggers incidents through the PagerDuty Events API v2.
+type PagerDutyChannel struct {
+	RoutingKey string
+	URL        string
+	HTTPClient *http.Client
+}
+
+// NewPagerDutyChannel returns a channel for the integration routing key.
+func NewPagerDutyChannel(routingKey string) *PagerDutyChannel {
+	return &PagerDutyChannel{
+		RoutingKey: routingKey,
+		URL:        pagerDutyEventsURL,
+		HTTPClient: &http.Client{Timeout: deliveryTimeout},
+	}
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	Payload     pagerDutyPayload `json:"payload"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string `json:"summary"`
+	Source        string `json:"source"`
+	Severity      string `json:"severity"`
+	Component     string `json:"component,omitempty"`
+	CustomDetails any    `json:"custom_details,omitempty"`
+}
+
+func (c *PagerDutyChannel) Send(ctx context.Context, msg Message) error {
+	severity := msg.Severity
+	if severity == "" {
+		severity = SeverityWarning
+	}
+
+	summary := msg.Title
+	if msg.Text != "" {
+		summary += ": " + msg.Text
+	}
+
+	return postJSON(ctx, c.HTTPClient, c.URL, pagerDutyEvent{
+		RoutingKey:  c.RoutingKey,
+		EventAction: "trigger",
+		Payload: pagerDutyPayload{
+			Summary:       summary,
+			Source:        "terrascope",
+			Severity:      severity,
+			Component:     msg.Lineage,
+			CustomDetails: msg.Details,
+		},
+	})
+}
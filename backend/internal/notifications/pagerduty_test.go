@@ -0,0 +1,38 @@
+// Package notifications delivers messages about state changes to external
+// channels such as Slack, email, generic webhooks and PagerDuty.
+package notifications
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPagerDutyChannel(t *testing.T) {
+	server, bodies := captureServer(t, http.StatusAccepted)
+
+	channel := NewPagerDutyChannel("routing-key")
+	channel.URL = server.URL
+
+	err := channel.Send(context.Background(), Message{
+		Title:   "iam users in prod",
+		Text:    "1 aws_iam_user instances added",
+		Lineage: "prod",
+	})
+
+	require.NoError(t, err)
+	require.Len(t, *bodies, 1)
+
+	event := (*bodies)[0]
+	assert.Equal(t, "routing-key", event["routing_key"])
+	assert.Equal(t, "trigger", event["event_action"])
+	assert.Equal(t, map[string]any{
+		"summary":   "iam users in prod: 1 aws_iam_user instances added",
+		"source":    "terrascope",
+		"severity":  SeverityWarning,
+		"component": "prod",
+	}, event["payload"])
+}
@@ -0,0 +1,42 @@
+// Package notifications delivers messages about state changes to external
+// channels such as Slack, email, generic webhooks and PagerDuty.
+package notifications
+
+import (
+	"context"
+	"net/http"
+)
+
+var severityEmoji = map[string]string{
+	SeverityInfo:     ":information_source:",
+	SeverityWarning:  ":warning:",
+	SeverityError:    ":x:",
+	SeverityCritical: ":rotating_light:",
+}
+
+// SlackChannel posts messages to a Slack incoming webhook.
+type SlackChannel struct {
+	WebhookURL string
+	HTTPClient *http.Client
+}
+
+// NewSlackChannel returns a channel for the incoming webhook URL.
+func NewSlackChannel(webhookURL string) *SlackChannel {
+	return &SlackChannel{WebhookURL: webhookURL, HTTPClient: &http.Client{Timeout: deliveryTimeout}}
+}
+
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+func (c *SlackChannel) Send(ctx context.Context, msg Message) error {
+	text := "*" + msg.Title + "*"
+	if emoji, ok := severityEmoji[msg.Severity]; ok {
+		text = emoji + " " + text
+	}
+	if msg.Text != "" {
+		text += "\n" + msg.Text
+	}
+
+	return postJSON(ctx, c.HTTPClient, c.WebhookURL, slackPayload{Text: text})
+}
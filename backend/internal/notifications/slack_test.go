@@ -0,0 +1,26 @@
+// Package notifications delivers messages about state changes to external
+// channels such as Slack, email, generic webhooks and PagerDuty.
+package notifications
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlackChannel(t *testing.T) {
+	server, bodies := captureServer(t, http.StatusOK)
+
+	err := NewSlackChannel(server.URL).Send(context.Background(), Message{
+		Title:    "iam users in prod",
+		Text:     "1 aws_iam_user instances added",
+		Severity: SeverityWarning,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, *bodies, 1)
+	assert.Equal(t, map[string]any{"text": ":warning: *iam users in prod*\n1 aws_iam_user instances added"}, (*bodies)[0])
+}
@@ -0,0 +1,69 @@
+// Package notifications delivers messages about state changes to external
+// channels such as Slack, email, generic webhooks and PagerDuty.
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// SMTPChannel emails messages through an SMTP relay, authenticating with
+// PLAIN auth when a username is set.
+type SMTPChannel struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+	To       []string
+
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func (c *SMTPChannel) Send(_ context.Context, msg Message) error {
+	var auth smtp.Auth
+	if c.Username != "" {
+		host, _, err := net.SplitHostPort(c.Addr)
+		if err != nil {
+			return fmt.Errorf("invalid smtp address %q: %w", c.Addr, err)
+		}
+		auth = smtp.PlainAuth("", c.Username, c.Password, host)
+	}
+
+	send := c.sendMail
+	if send == nil {
+		send = smtp.SendMail
+	}
+
+	if err := send(c.Addr, auth, c.From, c.To, c.email(msg)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+// email formats the message as a plain text email.
+func (c *SMTPChannel) email(msg Message) []byte {
+	subject := "[terrascope] " + msg.Title
+	if msg.Severity != "" {
+		subject = "[terrascope " + msg.Severity + "] " + msg.Title
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", c.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(c.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", headerValue(subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Text, "\n", "\r\n"))
+	b.WriteString("\r\n")
+
+	return []byte(b.String())
+}
+
+// headerValue keeps user-controlled text from injecting extra headers.
+func headerValue(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
@@ -0,0 +1,68 @@
+// Package notifications delivers messages about state changes to external
+// channels such as Slack, email, generic webhooks and PagerDuty.
+package notifications
+
+import (
+	"context"
+	"errors"
+	"net/smtp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMTPChannel(t *testing.T) {
+	t.Run("sends a plain text email", func(t *testing.T) {
+		var gotAddr, gotFrom string
+		var gotTo []string
+		var gotAuth smtp.Auth
+		var gotMsg []byte
+
+		channel := &SMTPChannel{
+			Addr:     "smtp.example.com:587",
+			Username: "terrascope",
+			Password: "secret",
+			From:     "terrascope@example.com",
+			To:       []string{"ops@example.com", "sec@example.com"},
+			sendMail: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+				gotAddr, gotAuth, gotFrom, gotTo, gotMsg = addr, a, from, to, msg
+				return nil
+			},
+		}
+
+		err := channel.Send(context.Background(), Message{
+			Title:    "iam users in prod\r\nBcc: attacker@example.com",
+			Text:     "1 aws_iam_user instances added\naws_iam_user.intruder",
+			Severity: SeverityCritical,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "smtp.example.com:587", gotAddr)
+		assert.NotNil(t, gotAuth)
+		assert.Equal(t, "terrascope@example.com", gotFrom)
+		assert.Equal(t, []string{"ops@example.com", "sec@example.com"}, gotTo)
+		assert.Equal(t, "From: terrascope@example.com\r\n"+
+			"To: ops@example.com, sec@example.com\r\n"+
+			"Subject: [terrascope critical] iam users in prod  Bcc: attacker@example.com\r\n"+
+			"MIME-Version: 1.0\r\n"+
+			"Content-Type: text/plain; charset=utf-8\r\n\r\n"+
+			"1 aws_iam_user instances added\r\naws_iam_user.intruder\r\n", string(gotMsg))
+	})
+
+	t.Run("skips auth without a username", func(t *testing.T) {
+		channel := &SMTPChannel{
+			Addr: "relay:25",
+			From: "terrascope@example.com",
+			To:   []string{"ops@example.com"},
+			sendMail: func(_ string, a smtp.Auth, _ string, _ []string, _ []byte) error {
+				assert.Nil(t, a)
+				return errors.New("connection refused")
+			},
+		}
+
+		err := channel.Send(context.Background(), Message{Title: "x"})
+
+		assert.ErrorContains(t, err, "failed to send email")
+	})
+}
@@ -0,0 +1,61 @@
+// Package notifications delivers messages about state changes to external
+// channels such as Slack, email, generic webhooks and PagerDuty.
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+const deliveryTimeout = 10 * time.Second
+
+// WebhookChannel posts each message as JSON to a URL.
+type WebhookChannel struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+// NewWebhookChannel returns a channel that gives up on the webhook after ten
+// seconds.
+func NewWebhookChannel(url string) *WebhookChannel {
+	return &WebhookChannel{URL: url, HTTPClient: &http.Client{Timeout: deliveryTimeout}}
+}
+
+func (c *WebhookChannel) Send(ctx context.Context, msg Message) error {
+	return postJSON(ctx, c.HTTPClient, c.URL, msg)
+}
+
+// postJSON posts v as JSON and fails on any non-2xx response.
+func postJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("notification request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("failed to close notification response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s returned %s", req.URL.Host, resp.Status)
+	}
+
+	return nil
+}
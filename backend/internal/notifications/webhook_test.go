@@ -0,0 +1,64 @@
+// Package notifications delivers messages about state changes to external
+// channels such as Slack, email, generic webhooks and PagerDuty.
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureServer answers with the given status and records the JSON bodies it
+// receives.
+func captureServer(t *testing.T, status int) (*httptest.Server, *[]map[string]any) {
+	t.Helper()
+
+	var bodies []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		data, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		var body map[string]any
+		assert.NoError(t, json.Unmarshal(data, &body))
+		bodies = append(bodies, body)
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &bodies
+}
+
+func TestWebhookChannel(t *testing.T) {
+	t.Run("posts the message", func(t *testing.T) {
+		server, bodies := captureServer(t, http.StatusNoContent)
+
+		err := NewWebhookChannel(server.URL).Send(context.Background(), Message{
+			Title:    "iam users in prod",
+			Severity: SeverityCritical,
+			Details:  map[string]any{"serial": 2},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, *bodies, 1)
+		assert.Equal(t, "iam users in prod", (*bodies)[0]["title"])
+		assert.Equal(t, map[string]any{"serial": float64(2)}, (*bodies)[0]["details"])
+	})
+
+	t.Run("reports failed deliveries", func(t *testing.T) {
+		server, _ := captureServer(t, http.StatusBadGateway)
+
+		err := NewWebhookChannel(server.URL).Send(context.Background(), Message{Title: "x"})
+
+		assert.ErrorContains(t, err, "502")
+	})
+}
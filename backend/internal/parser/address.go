@@ -0,0 +1,121 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+var moduleInstanceKey = regexp.MustCompile(`\[[^\]]*\]`)
+
+// InstanceAddress returns the instance address exactly as Terraform prints it,
+// e.g. module.app.data.aws_ami.ubuntu or aws_subnet.private["a"].
+func InstanceAddress(res models.ResourceState, instance models.ResourceInstance) string {
+	return ResourceAddress(res) + instanceKey(instance)
+}
+
+// instanceKey returns the instance key suffix as Terraform prints it, e.g. [0]
+// or ["frontend"], or an empty string for instances without a key.
+func instanceKey(instance models.ResourceInstance) string {
+	key := indexKeyValue(instance.IndexKey)
+	if key == nil {
+		return ""
+	}
+
+	if s, ok := key.(string); ok {
+		return fmt.Sprintf("[%q]", s)
+	}
+
+	return fmt.Sprintf("[%v]", key)
+}
+
+// deposedSuffix distinguishes the node of a deposed object from the current
+// instance at the same address, as Terraform prints it in plans, e.g.
+// aws_instance.web (deposed object 2f1c9a8e).
+func deposedSuffix(instance models.ResourceInstance) string {
+	if instance.Deposed == "" {
+		return ""
+	}
+
+	return " (deposed object " + instance.Deposed + ")"
+}
+
+// attachAddresses maps every node built from a resource instance to the
+// instance's Terraform address and its path in the state document, e.g.
+// .resources[3].instances[1]. Nodes shared by several instances keep the
+// first, as graph building does; folded nodes map to the resource.
+func attachAddresses(graph *models.Graph, state *models.TerraformState, opts GraphOptions) {
+	nodes := make(map[string]bool, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		nodes[node.ID] = true
+	}
+
+	graph.Addresses = make(map[string]models.NodeAddress, len(graph.Nodes))
+
+	for r, res := range state.Resources {
+		for i, instance := range res.Instances {
+			nodeID := opts.nodeID(res, instance, i)
+			if _, exists := graph.Addresses[nodeID]; exists || !nodes[nodeID] {
+				continue
+			}
+
+			if opts.FoldInstances {
+				graph.Addresses[nodeID] = models.NodeAddress{
+					Terraform: ResourceAddress(res),
+					StatePath: fmt.Sprintf(".resources[%d]", r),
+				}
+				continue
+			}
+
+			graph.Addresses[nodeID] = models.NodeAddress{
+				Terraform: InstanceAddress(res, instance),
+				StatePath: fmt.Sprintf(".resources[%d].instances[%d]", r, i),
+			}
+		}
+	}
+}
+
+// ResourceAddress returns the resource address without an instance key,
+// including the module instance path and the data. prefix for data sources.
+func ResourceAddress(res models.ResourceState) string {
+	parts := []string{}
+
+	if res.Module != "" {
+		parts = append(parts, res.Module)
+	}
+
+	if res.Mode == "data" {
+		parts = append(parts, "data")
+	}
+
+	parts = append(parts, res.Type, res.Name)
+
+	return strings.Join(parts, ".")
+}
+
+// ConfigAddress returns the address of the resource in configuration, where
+// module calls carry no instance keys (module.app[0] becomes module.app).
+func ConfigAddress(res models.ResourceState) string {
+	return moduleInstanceKey.ReplaceAllString(ResourceAddress(res), "")
+}
+
+func indexKeyValue(key any) any {
+	if key == nil {
+		return nil
+	}
+
+	val := reflect.ValueOf(key)
+	if val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+
+	return val.Interface()
+}
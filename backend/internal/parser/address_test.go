@@ -0,0 +1,158 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestInstanceAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		res      models.ResourceState
+		instance models.ResourceInstance
+		expected string
+	}{
+		{
+			name:     "managed resource",
+			res:      models.ResourceState{Mode: "managed", Type: "aws_vpc", Name: "main"},
+			expected: "aws_vpc.main",
+		},
+		{
+			name:     "data source keeps data prefix",
+			res:      models.ResourceState{Mode: "data", Type: "aws_ami", Name: "ubuntu", Module: "module.app"},
+			expected: "module.app.data.aws_ami.ubuntu",
+		},
+		{
+			name:     "count index",
+			res:      models.ResourceState{Mode: "managed", Type: "aws_subnet", Name: "private"},
+			instance: models.ResourceInstance{IndexKey: float64(1)},
+			expected: "aws_subnet.private[1]",
+		},
+		{
+			name:     "for_each key is quoted",
+			res:      models.ResourceState{Mode: "managed", Type: "aws_subnet", Name: "private"},
+			instance: models.ResourceInstance{IndexKey: "a"},
+			expected: `aws_subnet.private["a"]`,
+		},
+		{
+			name:     "pointer index key",
+			res:      models.ResourceState{Mode: "managed", Type: "aws_subnet", Name: "private"},
+			instance: models.ResourceInstance{IndexKey: intPtr(2)},
+			expected: "aws_subnet.private[2]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, InstanceAddress(tt.res, tt.instance))
+		})
+	}
+}
+
+func TestConfigAddress(t *testing.T) {
+	t.Run("strips module instance keys", func(t *testing.T) {
+		res := models.ResourceState{Mode: "managed", Type: "aws_instance", Name: "web", Module: `module.app["blue"].module.compute[0]`}
+
+		assert.Equal(t, "module.app.module.compute.aws_instance.web", ConfigAddress(res))
+	})
+
+	t.Run("root module resource is unchanged", func(t *testing.T) {
+		res := models.ResourceState{Mode: "managed", Type: "aws_instance", Name: "web"}
+
+		assert.Equal(t, "aws_instance.web", ConfigAddress(res))
+	})
+}
+
+func TestResourceAddress(t *testing.T) {
+	t.Run("data source in module keeps module instance key", func(t *testing.T) {
+		res := models.ResourceState{Mode: "data", Type: "aws_ami", Name: "ubuntu", Module: "module.app[0]"}
+
+		assert.Equal(t, "module.app[0].data.aws_ami.ubuntu", ResourceAddress(res))
+	})
+}
+
+func TestIndexKeyValue(t *testing.T) {
+	var nilPtr *int
+
+	assert.Nil(t, indexKeyValue(nil))
+	assert.Nil(t, indexKeyValue(nilPtr))
+	assert.Equal(t, 3, indexKeyValue(intPtr(3)))
+	assert.Equal(t, "a", indexKeyValue("a"))
+}
+
+func TestAttachAddresses(t *testing.T) {
+	state := &models.TerraformState{
+		Resources: []models.ResourceState{
+			{
+				Mode:      "data",
+				Type:      "aws_ami",
+				Name:      "ubuntu",
+				Module:    "module.app",
+				Instances: []models.ResourceInstance{{}},
+			},
+			{
+				Mode: "managed",
+				Type: "aws_subnet",
+				Name: "private",
+				Instances: []models.ResourceInstance{
+					{IndexKey: float64(0)},
+				},
+			},
+			{
+				Mode: "managed",
+				Type: "aws_instance",
+				Name: "web",
+				Instances: []models.ResourceInstance{
+					{IndexKey: "blue"},
+					{IndexKey: "green"},
+				},
+			},
+		},
+	}
+
+	t.Run("legacy IDs", func(t *testing.T) {
+		graph := BuildGraph(state)
+
+		assert.Equal(t, map[string]models.NodeAddress{
+			"module.app.aws_ami.ubuntu": {Terraform: "module.app.data.aws_ami.ubuntu", StatePath: ".resources[0].instances[0]"},
+			"aws_subnet.private":        {Terraform: "aws_subnet.private[0]", StatePath: ".resources[1].instances[0]"},
+			"aws_instance.web[blue]":    {Terraform: `aws_instance.web["blue"]`, StatePath: ".resources[2].instances[0]"},
+			"aws_instance.web[green]":   {Terraform: `aws_instance.web["green"]`, StatePath: ".resources[2].instances[1]"},
+		}, graph.Addresses)
+	})
+
+	t.Run("terraform IDs map to themselves", func(t *testing.T) {
+		graph := BuildGraphWithOptions(state, GraphOptions{IDFormat: IDFormatTerraform})
+
+		require.Len(t, graph.Addresses, 4)
+		for id, address := range graph.Addresses {
+			assert.Equal(t, id, address.Terraform)
+		}
+	})
+
+	t.Run("only nodes in the graph are mapped", func(t *testing.T) {
+		graph := BuildNetworkGraph(state)
+
+		assert.Equal(t, map[string]models.NodeAddress{
+			"aws_subnet.private":      {Terraform: "aws_subnet.private[0]", StatePath: ".resources[1].instances[0]"},
+			"aws_instance.web[blue]":  {Terraform: `aws_instance.web["blue"]`, StatePath: ".resources[2].instances[0]"},
+			"aws_instance.web[green]": {Terraform: `aws_instance.web["green"]`, StatePath: ".resources[2].instances[1]"},
+		}, graph.Addresses)
+	})
+
+	t.Run("colliding legacy IDs keep the first instance", func(t *testing.T) {
+		graph := BuildGraph(&models.TerraformState{
+			Resources: []models.ResourceState{
+				{Mode: "managed", Type: "aws_vpc", Name: "main", Instances: []models.ResourceInstance{{}}},
+				{Mode: "data", Type: "aws_vpc", Name: "main", Instances: []models.ResourceInstance{{}}},
+			},
+		})
+
+		assert.Equal(t, models.NodeAddress{Terraform: "aws_vpc.main", StatePath: ".resources[0].instances[0]"}, graph.Addresses["aws_vpc.main"])
+	})
+}
@@ -0,0 +1,199 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"testing"
+)
+
+// Performance budgets per resource for parse + graph build, checked by
+// TestPerformanceBudget. They sit well above the numbers documented in
+// BENCHMARKS.md so that only real regressions fail the check.
+const (
+	budgetNsPerResource     = 40000
+	budgetAllocsPerResource = 100
+)
+
+var benchmarkSizes = []int{1000, 10000, 100000}
+
+// syntheticState generates a deterministic tfstate document with the given
+// number of resources spread over modules, with implicit and explicit
+// dependencies between neighbouring resources.
+func syntheticState(resources int) []byte {
+	types := []string{"aws_instance", "aws_subnet", "aws_security_group", "aws_s3_bucket", "aws_iam_role"}
+	items := make([]map[string]any, 0, resources)
+
+	for i := 0; i < resources; i++ {
+		resType := types[i%len(types)]
+		module := ""
+		if i%4 != 0 {
+			module = fmt.Sprintf("module.m%d", i%50)
+		}
+
+		var deps []string
+		if i > 0 {
+			deps = append(deps, fmt.Sprintf("%s.r%d", types[(i-1)%len(types)], i-1))
+		}
+
+		item := map[string]any{
+			"mode":     "managed",
+			"type":     resType,
+			"name":     fmt.Sprintf("r%d", i),
+			"provider": `provider["registry.terraform.io/hashicorp/aws"]`,
+			"instances": []map[string]any{
+				{
+					"schema_version": 1,
+					"attributes": map[string]any{
+						"id":   fmt.Sprintf("%s-%08x", resType, i),
+						"arn":  fmt.Sprintf("arn:aws:service:eu-west-1:123456789012:%s/%d", resType, i),
+						"name": fmt.Sprintf("resource-%d", i),
+						"tags": map[string]any{"Environment": "bench", "Index": fmt.Sprint(i)},
+					},
+					"dependencies": deps,
+				},
+			},
+		}
+		if module != "" {
+			item["module"] = module
+		}
+		if i%10 == 0 && i > 0 {
+			item["depends_on"] = []string{fmt.Sprintf("%s.r0", types[0])}
+		}
+
+		items = append(items, item)
+	}
+
+	data, err := json.Marshal(map[string]any{
+		"version":           4,
+		"terraform_version": "1.5.0",
+		"serial":            1,
+		"lineage":           "bench",
+		"resources":         items,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return data
+}
+
+func BenchmarkParseTfstate(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		data := syntheticState(size)
+
+		b.Run(fmt.Sprintf("resources=%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+
+			for b.Loop() {
+				if _, err := ParseTfstate(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkBuildGraph(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		state, err := ParseTfstate(syntheticState(size))
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(fmt.Sprintf("resources=%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for b.Loop() {
+				BuildGraph(state)
+			}
+		})
+	}
+}
+
+func BenchmarkParseAndBuild(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		data := syntheticState(size)
+
+		b.Run(fmt.Sprintf("resources=%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+
+			for b.Loop() {
+				state, err := ParseTfstate(data)
+				if err != nil {
+					b.Fatal(err)
+				}
+				BuildGraph(state)
+			}
+		})
+	}
+}
+
+// TestPerformanceBudget fails when parsing and building a 10k resource state
+// exceeds the per-resource time or allocation budget. It only runs when
+// TERRASCOPE_PERF=1 so regular test runs stay fast and deterministic. Set
+// TERRASCOPE_MEMPROFILE to a path to also write a heap profile of the run.
+func TestPerformanceBudget(t *testing.T) {
+	if os.Getenv("TERRASCOPE_PERF") != "1" {
+		t.Skip("set TERRASCOPE_PERF=1 to run the performance budget check")
+	}
+
+	const resources = 10000
+	data := syntheticState(resources)
+
+	result := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+
+		for b.Loop() {
+			state, err := ParseTfstate(data)
+			if err != nil {
+				b.Fatal(err)
+			}
+			BuildGraph(state)
+		}
+	})
+
+	nsPerResource := result.NsPerOp() / resources
+	allocsPerResource := result.AllocsPerOp() / resources
+
+	t.Logf("parse+build %d resources: %d ns/resource, %d allocs/resource, %d B/op",
+		resources, nsPerResource, allocsPerResource, result.AllocedBytesPerOp())
+
+	if nsPerResource > budgetNsPerResource {
+		t.Errorf("time budget exceeded: %d ns/resource > %d", nsPerResource, budgetNsPerResource)
+	}
+
+	if allocsPerResource > budgetAllocsPerResource {
+		t.Errorf("allocation budget exceeded: %d allocs/resource > %d", allocsPerResource, budgetAllocsPerResource)
+	}
+
+	if path := os.Getenv("TERRASCOPE_MEMPROFILE"); path != "" {
+		writeHeapProfile(t, path)
+	}
+}
+
+func writeHeapProfile(t *testing.T, path string) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create heap profile: %v", err)
+	}
+
+	defer func() {
+		if err := f.Close(); err != nil {
+			t.Errorf("failed to close heap profile: %v", err)
+		}
+	}()
+
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		t.Fatalf("failed to write heap profile: %v", err)
+	}
+}
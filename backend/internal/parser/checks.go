@@ -0,0 +1,104 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"fmt"
+
+	"github.com/terrascope/core/internal/codes"
+	"github.com/terrascope/core/internal/models"
+)
+
+const warningTypeCheck = "check"
+
+var failingCheckStatuses = map[string]bool{
+	"fail":  true,
+	"error": true,
+}
+
+// applyCheckResults attaches failing check results (preconditions,
+// postconditions and check blocks) as warnings. Results for resource objects
+// go on the matching nodes; everything else is reported on the graph.
+func applyCheckResults(graph *models.Graph, state *models.TerraformState, opts GraphOptions) {
+	if len(state.CheckResults) == 0 {
+		return
+	}
+
+	nodeIndex := make(map[string]int, len(graph.Nodes))
+	for i, node := range graph.Nodes {
+		nodeIndex[node.ID] = i
+	}
+
+	byObject := make(map[string]string)
+	byConfig := make(map[string][]string)
+	for _, ref := range instances(state, opts) {
+		byObject[InstanceAddress(ref.Resource, ref.Instance)] = ref.NodeID
+		config := ConfigAddress(ref.Resource)
+		byConfig[config] = append(byConfig[config], ref.NodeID)
+	}
+
+	attach := func(nodeIDs []string, warning models.Warning) {
+		attached := false
+		for _, nodeID := range nodeIDs {
+			if i, ok := nodeIndex[nodeID]; ok {
+				graph.Nodes[i].Warnings = append(graph.Nodes[i].Warnings, warning)
+				attached = true
+			}
+		}
+
+		if !attached {
+			graph.Warnings = append(graph.Warnings, warning)
+		}
+	}
+
+	for _, result := range state.CheckResults {
+		if len(result.Objects) == 0 {
+			if failingCheckStatuses[result.Status] {
+				attach(byConfig[result.ConfigAddr], checkWarning(result.ConfigAddr, result.Status, ""))
+			}
+			continue
+		}
+
+		for _, object := range result.Objects {
+			if !failingCheckStatuses[object.Status] {
+				continue
+			}
+
+			var targets []string
+			if nodeID, ok := byObject[object.ObjectAddr]; ok {
+				targets = []string{nodeID}
+			}
+
+			if len(object.FailureMessages) == 0 {
+				attach(targets, checkWarning(object.ObjectAddr, object.Status, ""))
+				continue
+			}
+
+			for _, message := range object.FailureMessages {
+				attach(targets, checkWarning(object.ObjectAddr, object.Status, message))
+			}
+		}
+	}
+}
+
+func checkWarning(address, status, message string) models.Warning {
+	if message == "" {
+		message = fmt.Sprintf("check %s for %s", statusVerb(status), address)
+	}
+
+	return models.Warning{
+		Code:    codes.ForWarning(warningTypeCheck),
+		Type:    warningTypeCheck,
+		Address: address,
+		Status:  status,
+		Message: message,
+	}
+}
+
+func statusVerb(status string) string {
+	if status == "error" {
+		return "errored"
+	}
+
+	return "failed"
+}
@@ -0,0 +1,105 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func checkState(results ...models.CheckResult) *models.TerraformState {
+	web := managedResource("aws_instance", "web", map[string]any{"id": "i-1"})
+	web.Instances = []models.ResourceInstance{
+		{Attributes: map[string]any{"id": "i-1"}, IndexKey: "blue"},
+		{Attributes: map[string]any{"id": "i-2"}, IndexKey: "green"},
+	}
+
+	return &models.TerraformState{
+		Resources: []models.ResourceState{
+			web,
+			managedResource("aws_s3_bucket", "logs", map[string]any{"id": "logs"}),
+		},
+		CheckResults: results,
+	}
+}
+
+func TestApplyCheckResults(t *testing.T) {
+	t.Run("no check results adds no warnings", func(t *testing.T) {
+		graph := BuildGraph(checkState())
+
+		assert.Empty(t, graph.Warnings)
+		for _, node := range graph.Nodes {
+			assert.Empty(t, node.Warnings)
+		}
+	})
+
+	t.Run("failing object attaches messages to its node", func(t *testing.T) {
+		graph := BuildGraph(checkState(models.CheckResult{
+			ObjectKind: "resource",
+			ConfigAddr: "aws_instance.web",
+			Status:     "fail",
+			Objects: []models.CheckObject{
+				{ObjectAddr: `aws_instance.web["blue"]`, Status: "fail", FailureMessages: []string{"AMI must be encrypted", "Instance must be tagged"}},
+				{ObjectAddr: `aws_instance.web["green"]`, Status: "pass"},
+			},
+		}))
+
+		blue, ok := findNode(graph, "aws_instance.web[blue]")
+		require.True(t, ok)
+		require.Len(t, blue.Warnings, 2)
+		assert.Equal(t, "TS101", blue.Warnings[0].Code)
+		assert.Equal(t, "check", blue.Warnings[0].Type)
+		assert.Equal(t, "fail", blue.Warnings[0].Status)
+		assert.Equal(t, "AMI must be encrypted", blue.Warnings[0].Message)
+
+		green, ok := findNode(graph, "aws_instance.web[green]")
+		require.True(t, ok)
+		assert.Empty(t, green.Warnings)
+		assert.Empty(t, graph.Warnings)
+	})
+
+	t.Run("failing config without objects marks every instance", func(t *testing.T) {
+		graph := BuildGraph(checkState(models.CheckResult{
+			ObjectKind: "resource",
+			ConfigAddr: "aws_instance.web",
+			Status:     "error",
+		}))
+
+		for _, id := range []string{"aws_instance.web[blue]", "aws_instance.web[green]"} {
+			node, ok := findNode(graph, id)
+			require.True(t, ok)
+			require.Len(t, node.Warnings, 1)
+			assert.Equal(t, "check errored for aws_instance.web", node.Warnings[0].Message)
+		}
+	})
+
+	t.Run("check blocks become graph warnings", func(t *testing.T) {
+		graph := BuildGraph(checkState(models.CheckResult{
+			ObjectKind: "check",
+			ConfigAddr: "check.health",
+			Status:     "fail",
+			Objects: []models.CheckObject{
+				{ObjectAddr: "check.health", Status: "fail", FailureMessages: []string{"endpoint returned 503"}},
+			},
+		}))
+
+		require.Len(t, graph.Warnings, 1)
+		assert.Equal(t, "check.health", graph.Warnings[0].Address)
+		assert.Equal(t, "endpoint returned 503", graph.Warnings[0].Message)
+	})
+
+	t.Run("passing and unknown checks are ignored", func(t *testing.T) {
+		graph := BuildGraph(checkState(
+			models.CheckResult{ObjectKind: "resource", ConfigAddr: "aws_s3_bucket.logs", Status: "pass"},
+			models.CheckResult{ObjectKind: "output_value", ConfigAddr: "output.url", Status: "unknown"},
+		))
+
+		assert.Empty(t, graph.Warnings)
+		for _, node := range graph.Nodes {
+			assert.Empty(t, node.Warnings)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import "github.com/terrascope/core/internal/models"
+
+// ComputeDegrees counts, for every node of the graph, the edges into and out
+// of it and the nodes with a path to it, which depend on it. Edges to nodes
+// outside the graph are ignored.
+func ComputeDegrees(graph *models.Graph) map[string]models.NodeDegrees {
+	index := make(map[string]int, len(graph.Nodes))
+	for i, node := range graph.Nodes {
+		index[node.ID] = i
+	}
+
+	degrees := make([]models.NodeDegrees, len(graph.Nodes))
+	dependents := make([][]int, len(graph.Nodes))
+
+	for _, edge := range graph.Edges {
+		source, ok := index[edge.Source]
+		if !ok {
+			continue
+		}
+		target, ok := index[edge.Target]
+		if !ok {
+			continue
+		}
+
+		degrees[source].OutDegree++
+		degrees[target].InDegree++
+		dependents[target] = append(dependents[target], source)
+	}
+
+	// seen[i] == i+1 marks the nodes reached from node i, so the slice need
+	// not be cleared between searches.
+	seen := make([]int, len(graph.Nodes))
+	var queue []int
+
+	for i := range graph.Nodes {
+		seen[i] = i + 1
+		queue = append(queue[:0], i)
+
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+
+			for _, dependent := range dependents[current] {
+				if seen[dependent] != i+1 {
+					seen[dependent] = i + 1
+					degrees[i].TransitiveDependentsCount++
+					queue = append(queue, dependent)
+				}
+			}
+		}
+	}
+
+	result := make(map[string]models.NodeDegrees, len(graph.Nodes))
+	for id, i := range index {
+		result[id] = degrees[i]
+	}
+
+	return result
+}
+
+// WithDegrees returns a copy of the graph in which the nodes found in degrees,
+// usually computed on the full graph, carry them. The graph itself is left
+// untouched, since it may be shared with the graph store.
+func WithDegrees(graph *models.Graph, degrees map[string]models.NodeDegrees) *models.Graph {
+	counted := *graph
+	counted.Nodes = make([]models.Node, len(graph.Nodes))
+
+	for i, node := range graph.Nodes {
+		if d, ok := degrees[node.ID]; ok {
+			node.Degrees = &d
+		}
+		counted.Nodes[i] = node
+	}
+
+	return &counted
+}
@@ -0,0 +1,50 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestComputeDegrees(t *testing.T) {
+	t.Run("counts edges and transitive dependents", func(t *testing.T) {
+		degrees := ComputeDegrees(modularGraph())
+
+		assert.Equal(t, models.NodeDegrees{InDegree: 3, TransitiveDependentsCount: 4}, degrees["aws_vpc.main"])
+		assert.Equal(t, models.NodeDegrees{InDegree: 1, OutDegree: 1, TransitiveDependentsCount: 1}, degrees["module.app.aws_instance.web[0]"])
+		assert.Equal(t, models.NodeDegrees{OutDegree: 1}, degrees["module.app.aws_eip.web"])
+		assert.Equal(t, models.NodeDegrees{OutDegree: 1}, degrees["module.db.aws_db_instance.main"], "edges to missing nodes are ignored")
+		assert.Len(t, degrees, 5)
+	})
+
+	t.Run("cycles count each dependent once", func(t *testing.T) {
+		graph := &models.Graph{
+			Nodes: []models.Node{{ID: "a"}, {ID: "b"}, {ID: "c"}},
+			Edges: []models.Edge{
+				{Source: "a", Target: "b"},
+				{Source: "b", Target: "a"},
+				{Source: "c", Target: "a"},
+				{Source: "c", Target: "c"},
+			},
+		}
+
+		degrees := ComputeDegrees(graph)
+
+		assert.Equal(t, 2, degrees["a"].TransitiveDependentsCount)
+		assert.Equal(t, 2, degrees["b"].TransitiveDependentsCount)
+		assert.Equal(t, 0, degrees["c"].TransitiveDependentsCount)
+	})
+}
+
+func TestWithDegrees(t *testing.T) {
+	graph := modularGraph()
+
+	counted := WithDegrees(graph, map[string]models.NodeDegrees{"aws_vpc.main": {InDegree: 3}})
+
+	assert.Equal(t, &models.NodeDegrees{InDegree: 3}, counted.Nodes[0].Degrees)
+	assert.Nil(t, counted.Nodes[1].Degrees)
+	assert.Nil(t, graph.Nodes[0].Degrees, "the graph is left untouched")
+}
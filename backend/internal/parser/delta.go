@@ -0,0 +1,187 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// DeltaCache remembers the last parse of each state lineage so that the next
+// upload of the same lineage (same serial or the one after) only unmarshals
+// and rebuilds the resources whose JSON changed. Resources are matched by a
+// hash of their raw JSON. Returned states and graphs share attribute and
+// metadata maps with the cache, so callers must not modify them.
+type DeltaCache struct {
+	mu         sync.Mutex
+	entries    map[string]*deltaEntry
+	order      []string
+	maxEntries int
+}
+
+type deltaEntry struct {
+	serial    int
+	opts      GraphOptions
+	resources map[resourceHash]*parsedResource
+}
+
+type resourceHash [sha256.Size]byte
+
+type parsedResource struct {
+	state models.ResourceState
+	graph resourceGraph
+}
+
+// DeltaResult is the outcome of DeltaCache.Parse: the full state, its
+// dependency graph, and how many resources were reused from the cache.
+type DeltaResult struct {
+	State  *models.TerraformState
+	Graph  *models.Graph
+	Reused int
+	Parsed int
+}
+
+// rawState is a tfstate document with its resources left undecoded.
+type rawState struct {
+	Version          int                      `json:"version"`
+	TerraformVersion string                   `json:"terraform_version"`
+	Serial           int                      `json:"serial"`
+	Lineage          string                   `json:"lineage"`
+	Outputs          map[string]models.Output `json:"outputs,omitempty"`
+	Resources        []json.RawMessage        `json:"resources"`
+	CheckResults     []models.CheckResult     `json:"check_results,omitempty"`
+}
+
+// NewDeltaCache returns a cache that keeps the last parse of up to
+// maxEntries lineages, evicting the least recently stored.
+func NewDeltaCache(maxEntries int) *DeltaCache {
+	return &DeltaCache{
+		entries:    make(map[string]*deltaEntry),
+		maxEntries: maxEntries,
+	}
+}
+
+// Parse parses a tfstate document and builds its dependency graph, reusing
+// the resources of the previous parse of the same lineage when the serial is
+// unchanged or one ahead. The result is identical to ParseTfstate followed by
+// BuildGraphWithOptions.
+func (c *DeltaCache) Parse(data []byte, opts GraphOptions) (*DeltaResult, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty tfstate data")
+	}
+
+	var raw rawState
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tfstate: %w", err)
+	}
+
+	if err := validateHeader(raw.Version, raw.TerraformVersion); err != nil {
+		return nil, err
+	}
+
+	previous := c.previous(raw.Lineage, raw.Serial, opts)
+
+	state := &models.TerraformState{
+		Version:          raw.Version,
+		TerraformVersion: raw.TerraformVersion,
+		Serial:           raw.Serial,
+		Lineage:          raw.Lineage,
+		Outputs:          raw.Outputs,
+		Resources:        make([]models.ResourceState, 0, len(raw.Resources)),
+		CheckResults:     raw.CheckResults,
+	}
+	graph := &models.Graph{
+		Nodes: []models.Node{},
+		Edges: []models.Edge{},
+	}
+	result := &DeltaResult{State: state, Graph: graph}
+
+	current := make(map[resourceHash]*parsedResource, len(raw.Resources))
+	nodeMap := make(map[string]bool)
+
+	for _, message := range raw.Resources {
+		hash := hashResource(message)
+
+		parsed, ok := previous[hash]
+		if ok {
+			result.Reused++
+		} else {
+			parsed = &parsedResource{}
+			if err := json.Unmarshal(message, &parsed.state); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal tfstate: %w", err)
+			}
+			parsed.graph = buildResource(parsed.state, opts)
+			result.Parsed++
+		}
+
+		current[hash] = parsed
+		state.Resources = append(state.Resources, parsed.state)
+		addResource(graph, nodeMap, parsed.graph)
+	}
+
+	if opts.InstanceEdges {
+		expandInstanceEdges(graph, state, opts)
+	}
+
+	if opts.Schemas != nil || opts.InferReferences {
+		addReferenceEdges(graph, state, opts)
+	}
+
+	applyCheckResults(graph, state, opts)
+	attachAddresses(graph, state, opts)
+
+	if raw.Lineage != "" {
+		c.store(raw.Lineage, &deltaEntry{serial: raw.Serial, opts: opts, resources: current})
+	}
+
+	return result, nil
+}
+
+// previous returns the cached resources for a lineage when the serial shows
+// the upload is a resubmission or the direct successor of the cached one, and
+// the cached graph was built with the same options.
+func (c *DeltaCache) previous(lineage string, serial int, opts GraphOptions) map[resourceHash]*parsedResource {
+	if lineage == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[lineage]
+	if !ok || entry.opts != opts || (serial != entry.serial && serial != entry.serial+1) {
+		return nil
+	}
+
+	return entry.resources
+}
+
+func (c *DeltaCache) store(lineage string, entry *deltaEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.entries[lineage]; exists {
+		for i, cached := range c.order {
+			if cached == lineage {
+				c.order = append(c.order[:i], c.order[i+1:]...)
+				break
+			}
+		}
+	}
+
+	c.entries[lineage] = entry
+	c.order = append(c.order, lineage)
+
+	for len(c.order) > c.maxEntries {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+func hashResource(message []byte) resourceHash {
+	return sha256.Sum256(message)
+}
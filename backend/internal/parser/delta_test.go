@@ -0,0 +1,204 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func deltaState(lineage string, serial int, bucketName string) []byte {
+	return []byte(fmt.Sprintf(`{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"serial": %d,
+		"lineage": %q,
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_vpc",
+				"name": "main",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "vpc-1"}}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_subnet",
+				"name": "a",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "subnet-1"}, "dependencies": ["aws_vpc.main"]}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_s3_bucket",
+				"name": "logs",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": %q}}]
+			}
+		]
+	}`, serial, lineage, bucketName))
+}
+
+func TestDeltaCacheParse(t *testing.T) {
+	t.Run("first upload parses every resource", func(t *testing.T) {
+		cache := NewDeltaCache(4)
+
+		result, err := cache.Parse(deltaState("l1", 1, "logs"), GraphOptions{})
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.Reused)
+		assert.Equal(t, 3, result.Parsed)
+		assert.Len(t, result.Graph.Nodes, 3)
+		assert.Len(t, result.Graph.Edges, 1)
+	})
+
+	t.Run("next serial only parses changed resources", func(t *testing.T) {
+		cache := NewDeltaCache(4)
+		_, err := cache.Parse(deltaState("l1", 1, "logs"), GraphOptions{})
+		require.NoError(t, err)
+
+		result, err := cache.Parse(deltaState("l1", 2, "logs-v2"), GraphOptions{})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Reused)
+		assert.Equal(t, 1, result.Parsed)
+		assert.Equal(t, "logs-v2", result.Graph.Nodes[2].Metadata["id"])
+	})
+
+	t.Run("resubmitting the same serial reuses everything", func(t *testing.T) {
+		cache := NewDeltaCache(4)
+		_, err := cache.Parse(deltaState("l1", 1, "logs"), GraphOptions{})
+		require.NoError(t, err)
+
+		result, err := cache.Parse(deltaState("l1", 1, "logs"), GraphOptions{})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Reused)
+		assert.Equal(t, 0, result.Parsed)
+	})
+
+	t.Run("skipped serials and other lineages parse from scratch", func(t *testing.T) {
+		cache := NewDeltaCache(4)
+		_, err := cache.Parse(deltaState("l1", 1, "logs"), GraphOptions{})
+		require.NoError(t, err)
+
+		skipped, err := cache.Parse(deltaState("l1", 5, "logs"), GraphOptions{})
+		require.NoError(t, err)
+		other, err := cache.Parse(deltaState("l2", 2, "logs"), GraphOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, skipped.Reused)
+		assert.Equal(t, 0, other.Reused)
+	})
+
+	t.Run("matches ParseTfstate and BuildGraph", func(t *testing.T) {
+		cache := NewDeltaCache(4)
+		_, err := cache.Parse(deltaState("l1", 1, "logs"), GraphOptions{})
+		require.NoError(t, err)
+
+		data := deltaState("l1", 2, "logs-v2")
+		result, err := cache.Parse(data, GraphOptions{})
+		require.NoError(t, err)
+
+		state, err := ParseTfstate(data)
+		require.NoError(t, err)
+		graph := BuildGraph(state)
+
+		assert.Equal(t, state, result.State)
+		assert.Equal(t, Digest(graph), Digest(result.Graph))
+	})
+
+	t.Run("graphs built with other options are not reused", func(t *testing.T) {
+		cache := NewDeltaCache(4)
+		_, err := cache.Parse(deltaState("l1", 1, "logs"), GraphOptions{})
+		require.NoError(t, err)
+
+		result, err := cache.Parse(deltaState("l1", 1, "logs"), GraphOptions{IDFormat: IDFormatTerraform})
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, result.Reused)
+		assert.Equal(t, 3, result.Parsed)
+	})
+
+	t.Run("states without a lineage are not cached", func(t *testing.T) {
+		cache := NewDeltaCache(4)
+		_, err := cache.Parse(deltaState("", 1, "logs"), GraphOptions{})
+		require.NoError(t, err)
+
+		result, err := cache.Parse(deltaState("", 2, "logs"), GraphOptions{})
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.Reused)
+	})
+
+	t.Run("evicts the oldest lineage", func(t *testing.T) {
+		cache := NewDeltaCache(2)
+		for _, lineage := range []string{"a", "b", "c"} {
+			_, err := cache.Parse(deltaState(lineage, 1, "logs"), GraphOptions{})
+			require.NoError(t, err)
+		}
+
+		evicted, err := cache.Parse(deltaState("a", 2, "logs"), GraphOptions{})
+		require.NoError(t, err)
+		kept, err := cache.Parse(deltaState("c", 2, "logs"), GraphOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, evicted.Reused)
+		assert.Equal(t, 3, kept.Reused)
+	})
+
+	t.Run("rejects invalid documents", func(t *testing.T) {
+		cache := NewDeltaCache(4)
+
+		_, err := cache.Parse(nil, GraphOptions{})
+		assert.EqualError(t, err, "empty tfstate data")
+
+		_, err = cache.Parse([]byte(`{"version": 4}`), GraphOptions{})
+		assert.EqualError(t, err, "invalid tfstate: missing terraform_version field")
+
+		_, err = cache.Parse([]byte(`{"version": 4, "terraform_version": "1.5.0", "resources": [{"mode": 1}]}`), GraphOptions{})
+		assert.ErrorContains(t, err, "failed to unmarshal tfstate")
+	})
+}
+
+func BenchmarkDeltaParse(b *testing.B) {
+	const resources = 10000
+
+	base := syntheticState(resources)
+	var doc map[string]any
+	if err := json.Unmarshal(base, &doc); err != nil {
+		b.Fatal(err)
+	}
+
+	// The next serial changes 1% of the resources.
+	doc["serial"] = 2
+	items := doc["resources"].([]any)
+	for i := 0; i < len(items); i += 100 {
+		instance := items[i].(map[string]any)["instances"].([]any)[0].(map[string]any)
+		instance["attributes"].(map[string]any)["name"] = fmt.Sprintf("renamed-%d", i)
+	}
+	next, err := json.Marshal(doc)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(next)))
+
+	for b.Loop() {
+		b.StopTimer()
+		cache := NewDeltaCache(1)
+		if _, err := cache.Parse(base, GraphOptions{}); err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+
+		if _, err := cache.Parse(next, GraphOptions{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
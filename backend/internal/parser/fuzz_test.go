@@ -0,0 +1,117 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// fuzzSeeds are malformed variations of real states: attributes of unexpected
+// types, index keys that are neither numbers nor strings, and null resources
+// and instances. The states under testdata/states are added as seeds too.
+var fuzzSeeds = []string{
+	`{"version": 4, "terraform_version": "1.5.0", "resources": null}`,
+	`{"version": 4, "terraform_version": "1.5.0", "resources": [null]}`,
+	`{"version": 4, "terraform_version": "1.5.0", "resources": [{"type": "aws_instance", "name": "a", "instances": [null, {}]}]}`,
+	`{"version": 4, "terraform_version": "1.5.0", "resources": [{"type": "aws_instance", "name": "a", "instances": [{"index_key": {"a": [1]}}, {"index_key": [true]}]}]}`,
+	`{"version": 4, "terraform_version": "1.5.0", "resources": [{"type": "aws_instance", "name": "a", "instances": [{"index_key": 1e300}, {"index_key": -0.5}]}]}`,
+	`{"version": 4, "terraform_version": "1.5.0", "resources": [{"type": "aws_subnet", "name": "a", "instances": [{"attributes": {"id": 7, "vpc_id": ["x"], "cidr_block": {"a": 1}}}]}]}`,
+	`{"version": 4, "terraform_version": "1.5.0", "resources": [{"type": "aws_security_group", "name": "a", "instances": [{"attributes": {"id": "sg-1", "ingress": [null, 3, {"from_port": "x", "protocol": 6, "cidr_blocks": "0.0.0.0/0", "security_groups": [null, 1]}], "egress": {"a": 1}}}]}]}`,
+	`{"version": 4, "terraform_version": "1.5.0", "resources": [{"type": "aws_instance", "name": "a", "instances": [{"attributes": null, "sensitive_attributes": [[{"type": "index", "value": {"value": null}}], [], [{"type": "get_attr", "value": 1}]]}]}]}`,
+	`{"version": 4, "terraform_version": "1.5.0", "resources": [{"type": "aws_instance", "name": "a", "instances": [{"private": "%%%", "dependencies": [null, ""]}]}], "check_results": [{"objects": null}, {"objects": [{"failure_messages": [null]}]}]}`,
+}
+
+func addFuzzSeeds(f *testing.F) {
+	f.Helper()
+
+	paths, err := filepath.Glob(filepath.Join("testdata", "states", "*.tfstate"))
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+
+	for _, seed := range fuzzSeeds {
+		f.Add([]byte(seed))
+	}
+}
+
+// FuzzParseTfstate checks that arbitrary uploads are rejected with an error
+// rather than a panic by every parser of uploaded documents.
+func FuzzParseTfstate(f *testing.F) {
+	addFuzzSeeds(f)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		state, err := ParseTfstate(data)
+		if err == nil && state == nil {
+			t.Fatal("ParseTfstate returned neither a state nor an error")
+		}
+		Validate(data)
+		ParsePlan(data)
+		ParseLockInfo(data)
+	})
+}
+
+// FuzzBuildGraph builds every kind of graph, with every option, from the
+// states that parse successfully.
+func FuzzBuildGraph(f *testing.F) {
+	addFuzzSeeds(f)
+
+	options := []GraphOptions{
+		{},
+		{IDFormat: IDFormatTerraform},
+		{InstanceEdges: true},
+		{IDFormat: IDFormatTerraform, InstanceEdges: true},
+		{FoldInstances: true},
+		{IDFormat: IDFormatTerraform, FoldInstances: true},
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		state, err := ParseTfstate(data)
+		if err != nil {
+			return
+		}
+
+		for _, opts := range options {
+			graph := BuildGraphWithOptions(state, opts)
+			checkEdges(t, graph)
+
+			SortGraph(graph)
+			ComputeStats(graph)
+			Digest(graph)
+			Summarize(graph, "module", 10)
+
+			BuildNetworkGraphWithOptions(state, opts)
+			BuildSecurityGroupGraphWithOptions(state, opts)
+		}
+
+		Instances(state)
+		Sanitize(state)
+	})
+}
+
+// checkEdges fails when an edge leaves a node that is not in the graph.
+func checkEdges(t *testing.T, graph *models.Graph) {
+	t.Helper()
+
+	nodes := make(map[string]bool, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		nodes[node.ID] = true
+	}
+
+	for _, edge := range graph.Edges {
+		if !nodes[edge.Source] {
+			t.Fatalf("edge from missing node %q", edge.Source)
+		}
+	}
+}
@@ -4,13 +4,20 @@ package parser
 
 import (
 	"fmt"
-	"reflect"
 	"strings"
 
 	"github.com/terrascope/core/internal/models"
 )
 
+const redactedValue = "(sensitive value)"
+
 func BuildGraph(state *models.TerraformState) *models.Graph {
+	return BuildGraphWithOptions(state, GraphOptions{})
+}
+
+// BuildGraphWithOptions builds the dependency graph of the state, addressing
+// nodes as selected by opts.
+func BuildGraphWithOptions(state *models.TerraformState, opts GraphOptions) *models.Graph {
 	graph := &models.Graph{
 		Nodes: []models.Node{},
 		Edges: []models.Edge{},
@@ -18,62 +25,186 @@ func BuildGraph(state *models.TerraformState) *models.Graph {
 	nodeMap := make(map[string]bool)
 
 	for _, res := range state.Resources {
-		for i, instance := range res.Instances {
-			nodeID := buildNodeID(res, instance, i)
+		addResource(graph, nodeMap, buildResource(res, opts))
+	}
 
-			if nodeMap[nodeID] {
-				continue
-			}
+	if opts.InstanceEdges {
+		expandInstanceEdges(graph, state, opts)
+	}
 
-			node := models.Node{
-				ID:       nodeID,
-				Type:     res.Type,
-				Mode:     res.Mode,
-				Provider: extractProviderName(res.Provider),
-				Module:   res.Module,
-				Metadata: buildMetadata(res, instance),
-			}
+	if opts.Schemas != nil || opts.InferReferences {
+		addReferenceEdges(graph, state, opts)
+	}
+
+	applyCheckResults(graph, state, opts)
+	attachAddresses(graph, state, opts)
+
+	return graph
+}
+
+// resourceGraph holds the nodes of one resource's instances, each with the
+// edges to its dependencies.
+type resourceGraph []instanceGraph
 
-			graph.Nodes = append(graph.Nodes, node)
-			nodeMap[nodeID] = true
-			deps := collectDependencies(res.DependsOn, instance.Dependencies)
+type instanceGraph struct {
+	node  models.Node
+	edges []models.Edge
+}
+
+func buildResource(res models.ResourceState, opts GraphOptions) resourceGraph {
+	res = opts.typedResource(res)
+
+	if opts.FoldInstances {
+		return foldResource(res, opts)
+	}
+
+	instances := make(resourceGraph, 0, len(res.Instances))
+
+	for i, instance := range res.Instances {
+		nodeID := opts.nodeID(res, instance, i)
+
+		node := models.Node{
+			ID:              nodeID,
+			Type:            res.Type,
+			Mode:            res.Mode,
+			Provider:        extractProviderName(res.Provider),
+			ProviderAddress: ProviderAddress(res.Provider),
+			Module:          res.Module,
+			Status:          instance.NodeStatus(),
+			Metadata:        buildMetadata(res, instance),
+		}
+
+		deps := collectDependencies(res.DependsOn, instance.Dependencies)
+		edges := make([]models.Edge, 0, len(deps))
 
-			for target, edgeType := range deps {
-				graph.Edges = append(graph.Edges, models.Edge{
-					Source: nodeID,
-					Target: target,
-					Type:   edgeType,
-				})
+		key := instanceKey(instance)
+
+		for target, edgeType := range deps {
+			edge := models.Edge{
+				Source: nodeID,
+				Target: target,
+				Type:   edgeType,
+			}
+			if key != "" {
+				edge.Metadata = map[string]any{"source_instance": key}
 			}
+
+			edges = append(edges, edge)
 		}
+
+		instances = append(instances, instanceGraph{node: node, edges: edges})
 	}
 
-	return graph
+	return instances
 }
 
-func buildNodeID(res models.ResourceState, instance models.ResourceInstance, instanceIndex int) string {
-	parts := []string{}
+// foldResource builds a single node for all instances of the resource, with
+// one edge per dependency of any instance.
+func foldResource(res models.ResourceState, opts GraphOptions) resourceGraph {
+	if len(res.Instances) == 0 {
+		return nil
+	}
 
-	if res.Module != "" {
-		parts = append(parts, res.Module)
+	nodeID := opts.nodeID(res, models.ResourceInstance{}, 0)
+
+	node := models.Node{
+		ID:              nodeID,
+		Type:            res.Type,
+		Mode:            res.Mode,
+		Provider:        extractProviderName(res.Provider),
+		ProviderAddress: ProviderAddress(res.Provider),
+		Module:          res.Module,
+		Status:          foldedStatus(res),
+		Metadata:        foldedMetadata(res),
+	}
+
+	var implicit []string
+	for _, instance := range res.Instances {
+		implicit = append(implicit, instance.Dependencies...)
 	}
 
-	name := res.Name
+	deps := collectDependencies(res.DependsOn, implicit)
+	edges := make([]models.Edge, 0, len(deps))
 
-	if len(res.Instances) > 1 {
-		key := instance.IndexKey
-		if key != nil {
-			val := reflect.ValueOf(key)
-			if val.Kind() == reflect.Pointer && !val.IsNil() {
-				val = val.Elem()
-			}
-			name = fmt.Sprintf("%s[%v]", name, val.Interface())
+	for target, edgeType := range deps {
+		edges = append(edges, models.Edge{
+			Source: nodeID,
+			Target: target,
+			Type:   edgeType,
+		})
+	}
+
+	return resourceGraph{{node: node, edges: edges}}
+}
+
+// foldedStatus returns the most severe status of the resource's instances:
+// tainted over deposed.
+func foldedStatus(res models.ResourceState) string {
+	status := ""
+
+	for _, instance := range res.Instances {
+		switch instance.NodeStatus() {
+		case models.NodeStatusTainted:
+			return models.NodeStatusTainted
+		case models.NodeStatusDeposed:
+			status = models.NodeStatusDeposed
+		}
+	}
+
+	return status
+}
+
+// addResource appends the resource's nodes and edges to the graph, skipping
+// instances whose node ID is already present.
+func addResource(graph *models.Graph, nodeMap map[string]bool, instances resourceGraph) {
+	for _, instance := range instances {
+		if nodeMap[instance.node.ID] {
+			continue
+		}
+
+		graph.Nodes = append(graph.Nodes, instance.node)
+		nodeMap[instance.node.ID] = true
+		graph.Edges = append(graph.Edges, instance.edges...)
+	}
+}
+
+func buildNodeID(res models.ResourceState, instance models.ResourceInstance, instanceIndex int) string {
+	id := legacyResourceID(res)
+
+	if currentInstances(res) > 1 {
+		if key := indexKeyValue(instance.IndexKey); key != nil {
+			id = fmt.Sprintf("%s[%v]", id, key)
 		} else {
-			name = fmt.Sprintf("%s[%d]", name, instanceIndex)
+			id = fmt.Sprintf("%s[%d]", id, instanceIndex)
 		}
 	}
 
-	parts = append(parts, res.Type, name)
+	return id + deposedSuffix(instance)
+}
+
+// currentInstances counts the instances of the resource that are not deposed.
+func currentInstances(res models.ResourceState) int {
+	count := 0
+
+	for _, instance := range res.Instances {
+		if instance.Deposed == "" {
+			count++
+		}
+	}
+
+	return count
+}
+
+// legacyResourceID returns the legacy node ID of a resource without any
+// instance key.
+func legacyResourceID(res models.ResourceState) string {
+	parts := []string{}
+
+	if res.Module != "" {
+		parts = append(parts, res.Module)
+	}
+
+	parts = append(parts, res.Type, res.Name)
 
 	return strings.Join(parts, ".")
 }
@@ -115,9 +246,86 @@ func buildMetadata(res models.ResourceState, instance models.ResourceInstance) m
 		metadata["index_key"] = instance.IndexKey
 	}
 
+	if instance.Status != "" {
+		metadata["status"] = instance.Status
+	}
+
+	if instance.Deposed != "" {
+		metadata["deposed"] = instance.Deposed
+	}
+
+	if lifecycle := lifecycleMetadata(instance); lifecycle != nil {
+		metadata["lifecycle"] = lifecycle
+	}
+
+	redactSensitive(metadata, instance.SensitiveAttributes)
+
 	return metadata
 }
 
+// foldedMetadata describes a resource folded into one node: its mode and the
+// metadata of every instance.
+func foldedMetadata(res models.ResourceState) map[string]any {
+	instances := make([]map[string]any, 0, len(res.Instances))
+
+	for _, instance := range res.Instances {
+		metadata := buildMetadata(res, instance)
+		delete(metadata, "mode")
+		instances = append(instances, metadata)
+	}
+
+	return map[string]any{
+		"mode":      res.Mode,
+		"instances": instances,
+	}
+}
+
+// redactSensitive masks metadata values that Terraform marked as sensitive and
+// lists every sensitive attribute path so clients can flag them.
+func redactSensitive(metadata map[string]any, paths []models.AttributePath) {
+	if len(paths) == 0 {
+		return
+	}
+
+	flagged := make([]string, 0, len(paths))
+
+	for _, path := range paths {
+		flagged = append(flagged, path.String())
+
+		if len(path) == 0 || path[0].Type != "get_attr" {
+			continue
+		}
+
+		name, _ := path[0].Key().(string)
+		value, ok := metadata[name]
+		if !ok || name == "mode" || name == "index_key" {
+			continue
+		}
+
+		if len(path) == 1 {
+			metadata[name] = redactedValue
+			continue
+		}
+
+		tags, ok := value.(map[string]any)
+		key, isString := path[1].Key().(string)
+		if !ok || !isString {
+			continue
+		}
+
+		if _, exists := tags[key]; exists {
+			masked := make(map[string]any, len(tags))
+			for k, v := range tags {
+				masked[k] = v
+			}
+			masked[key] = redactedValue
+			metadata[name] = masked
+		}
+	}
+
+	metadata["sensitive_attributes"] = flagged
+}
+
 func collectDependencies(explicit, implicit []string) map[string]string {
 	deps := make(map[string]string)
 
@@ -133,3 +341,32 @@ func collectDependencies(explicit, implicit []string) map[string]string {
 
 	return deps
 }
+
+// InstanceRef pairs a resource instance with the node ID it receives in the graph.
+type InstanceRef struct {
+	NodeID   string
+	Resource models.ResourceState
+	Instance models.ResourceInstance
+}
+
+// Instances flattens the state into its resource instances, addressed the same
+// way BuildGraph addresses nodes, so analyses can correlate attributes with nodes.
+func Instances(state *models.TerraformState) []InstanceRef {
+	return instances(state, GraphOptions{})
+}
+
+func instances(state *models.TerraformState, opts GraphOptions) []InstanceRef {
+	refs := []InstanceRef{}
+
+	for _, res := range state.Resources {
+		for i, instance := range res.Instances {
+			refs = append(refs, InstanceRef{
+				NodeID:   opts.nodeID(res, instance, i),
+				Resource: res,
+				Instance: instance,
+			})
+		}
+	}
+
+	return refs
+}
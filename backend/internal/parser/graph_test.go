@@ -3,9 +3,11 @@
 package parser
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/terrascope/core/internal/models"
 )
 
@@ -494,6 +496,183 @@ func TestBuildMetadata(t *testing.T) {
 	})
 }
 
+func TestRedactSensitive(t *testing.T) {
+	t.Run("redacts top-level sensitive metadata", func(t *testing.T) {
+		res := models.ResourceState{Mode: "managed"}
+		instance := models.ResourceInstance{
+			Attributes: map[string]any{"id": "db-1", "name": "secret-name"},
+			SensitiveAttributes: []models.AttributePath{
+				{{Type: "get_attr", Value: "name"}},
+			},
+		}
+
+		metadata := buildMetadata(res, instance)
+
+		assert.Equal(t, "db-1", metadata["id"])
+		assert.Equal(t, "(sensitive value)", metadata["name"])
+		assert.Equal(t, []string{"name"}, metadata["sensitive_attributes"])
+	})
+
+	t.Run("redacts individual tags without mutating state", func(t *testing.T) {
+		tags := map[string]any{"Owner": "platform", "Token": "abc"}
+		res := models.ResourceState{Mode: "managed"}
+		instance := models.ResourceInstance{
+			Attributes: map[string]any{"tags": tags},
+			SensitiveAttributes: []models.AttributePath{
+				{
+					{Type: "get_attr", Value: "tags"},
+					{Type: "index", Value: map[string]any{"value": "Token", "type": "string"}},
+				},
+			},
+		}
+
+		metadata := buildMetadata(res, instance)
+
+		redacted := metadata["tags"].(map[string]any)
+		assert.Equal(t, "platform", redacted["Owner"])
+		assert.Equal(t, "(sensitive value)", redacted["Token"])
+		assert.Equal(t, "abc", tags["Token"])
+	})
+
+	t.Run("flags paths outside metadata", func(t *testing.T) {
+		res := models.ResourceState{Mode: "managed"}
+		instance := models.ResourceInstance{
+			Attributes: map[string]any{"id": "db-1", "password": "hunter2"},
+			SensitiveAttributes: []models.AttributePath{
+				{{Type: "get_attr", Value: "password"}},
+			},
+		}
+
+		metadata := buildMetadata(res, instance)
+
+		assert.NotContains(t, metadata, "password")
+		assert.Equal(t, []string{"password"}, metadata["sensitive_attributes"])
+	})
+
+	t.Run("no sensitive attributes leaves metadata untouched", func(t *testing.T) {
+		res := models.ResourceState{Mode: "managed"}
+		instance := models.ResourceInstance{Attributes: map[string]any{"id": "db-1"}}
+
+		metadata := buildMetadata(res, instance)
+
+		assert.NotContains(t, metadata, "sensitive_attributes")
+	})
+}
+
 func intPtr(i int) *int {
 	return &i
 }
+
+func TestInstances(t *testing.T) {
+	t.Run("empty state returns no instances", func(t *testing.T) {
+		refs := Instances(&models.TerraformState{})
+
+		assert.Empty(t, refs)
+	})
+
+	t.Run("instances are addressed like graph nodes", func(t *testing.T) {
+		state := &models.TerraformState{
+			Resources: []models.ResourceState{
+				{
+					Type:   "aws_subnet",
+					Name:   "private",
+					Mode:   "managed",
+					Module: "module.net",
+					Instances: []models.ResourceInstance{
+						{Attributes: map[string]any{"id": "subnet-1"}, IndexKey: intPtr(0)},
+						{Attributes: map[string]any{"id": "subnet-2"}, IndexKey: intPtr(1)},
+					},
+				},
+			},
+		}
+
+		refs := Instances(state)
+		graph := BuildGraph(state)
+
+		assert.Len(t, refs, 2)
+		for i, ref := range refs {
+			assert.Equal(t, graph.Nodes[i].ID, ref.NodeID)
+			assert.Equal(t, "aws_subnet", ref.Resource.Type)
+		}
+		assert.Equal(t, "subnet-2", refs[1].Instance.Attributes["id"])
+	})
+}
+
+func TestInstanceStatus(t *testing.T) {
+	state := func() *models.TerraformState {
+		return &models.TerraformState{
+			Resources: []models.ResourceState{
+				{
+					Mode: "managed", Type: "aws_instance", Name: "web",
+					Provider: `provider["registry.terraform.io/hashicorp/aws"]`,
+					Instances: []models.ResourceInstance{
+						{Attributes: map[string]any{"id": "i-new"}, Status: "tainted"},
+						{Attributes: map[string]any{"id": "i-old"}, Deposed: "2f1c9a8e"},
+					},
+				},
+				{
+					Mode: "managed", Type: "aws_eip", Name: "web",
+					Provider: `provider["registry.terraform.io/hashicorp/aws"]`,
+					Instances: []models.ResourceInstance{
+						{Attributes: map[string]any{"id": "eip-1"}, Dependencies: []string{"aws_instance.web"}},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("marks tainted and deposed nodes", func(t *testing.T) {
+		graph := BuildGraph(state())
+
+		require.Len(t, graph.Nodes, 3)
+		assert.Equal(t, "aws_instance.web", graph.Nodes[0].ID)
+		assert.Equal(t, models.NodeStatusTainted, graph.Nodes[0].Status)
+		assert.Equal(t, "tainted", graph.Nodes[0].Metadata["status"])
+
+		assert.Equal(t, "aws_instance.web (deposed object 2f1c9a8e)", graph.Nodes[1].ID)
+		assert.Equal(t, models.NodeStatusDeposed, graph.Nodes[1].Status)
+		assert.Equal(t, "2f1c9a8e", graph.Nodes[1].Metadata["deposed"])
+		assert.Equal(t, "i-old", graph.Nodes[1].Metadata["id"])
+
+		assert.Empty(t, graph.Nodes[2].Status)
+	})
+
+	t.Run("terraform addresses keep the deposed suffix", func(t *testing.T) {
+		graph := BuildGraphWithOptions(state(), GraphOptions{IDFormat: IDFormatTerraform})
+
+		assert.Equal(t, "aws_instance.web (deposed object 2f1c9a8e)", graph.Nodes[1].ID)
+		assert.Equal(t, "aws_instance.web", graph.Addresses[graph.Nodes[1].ID].Terraform)
+	})
+
+	t.Run("instance edges do not target deposed objects", func(t *testing.T) {
+		graph := BuildGraphWithOptions(state(), GraphOptions{InstanceEdges: true})
+
+		require.Len(t, graph.Edges, 1)
+		assert.Equal(t, "aws_instance.web", graph.Edges[0].Target)
+	})
+
+	t.Run("folded nodes take the most severe status", func(t *testing.T) {
+		graph := BuildGraphWithOptions(state(), GraphOptions{FoldInstances: true})
+
+		require.Len(t, graph.Nodes, 2)
+		assert.Equal(t, models.NodeStatusTainted, graph.Nodes[0].Status)
+	})
+
+	t.Run("stats count the statuses", func(t *testing.T) {
+		stats := ComputeStats(BuildGraph(state()))
+
+		assert.Equal(t, 1, stats.TaintedInstances)
+		assert.Equal(t, 1, stats.DeposedInstances)
+	})
+
+	t.Run("deposed objects are valid instances", func(t *testing.T) {
+		data, err := json.Marshal(state())
+		require.NoError(t, err)
+
+		report := Validate(data)
+		for _, issue := range report.Issues {
+			assert.NotEqual(t, "duplicate_address", issue.Code)
+			assert.NotEqual(t, "missing_index_key", issue.Code)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import "regexp"
+
+// IDFormatPattern recognizes the provider IDs of a resource type. Patterns
+// with an empty ResourceType recognize identifiers shared by many types, such
+// as ARNs.
+type IDFormatPattern struct {
+	Pattern      *regexp.Regexp
+	ResourceType string
+}
+
+// IDFormats lists the ID formats recognized in resource attributes, most
+// specific first.
+var IDFormats = []IDFormatPattern{
+	{regexp.MustCompile(`^vpc-[0-9a-f]{8,17}$`), "aws_vpc"},
+	{regexp.MustCompile(`^subnet-[0-9a-f]{8,17}$`), "aws_subnet"},
+	{regexp.MustCompile(`^sg-[0-9a-f]{8,17}$`), "aws_security_group"},
+	{regexp.MustCompile(`^igw-[0-9a-f]{8,17}$`), "aws_internet_gateway"},
+	{regexp.MustCompile(`^eigw-[0-9a-f]{8,17}$`), "aws_egress_only_internet_gateway"},
+	{regexp.MustCompile(`^nat-[0-9a-f]{8,17}$`), "aws_nat_gateway"},
+	{regexp.MustCompile(`^rtb-[0-9a-f]{8,17}$`), "aws_route_table"},
+	{regexp.MustCompile(`^eni-[0-9a-f]{8,17}$`), "aws_network_interface"},
+	{regexp.MustCompile(`^acl-[0-9a-f]{8,17}$`), "aws_network_acl"},
+	{regexp.MustCompile(`^eipalloc-[0-9a-f]{8,17}$`), "aws_eip"},
+	{regexp.MustCompile(`^pcx-[0-9a-f]{8,17}$`), "aws_vpc_peering_connection"},
+	{regexp.MustCompile(`^tgw-attach-[0-9a-f]{8,17}$`), "aws_ec2_transit_gateway_vpc_attachment"},
+	{regexp.MustCompile(`^tgw-[0-9a-f]{8,17}$`), "aws_ec2_transit_gateway"},
+	{regexp.MustCompile(`^vpce-[0-9a-f]{8,17}$`), "aws_vpc_endpoint"},
+	{regexp.MustCompile(`^vgw-[0-9a-f]{8,17}$`), "aws_vpn_gateway"},
+	{regexp.MustCompile(`^lt-[0-9a-f]{8,17}$`), "aws_launch_template"},
+	{regexp.MustCompile(`^i-[0-9a-f]{8,17}$`), "aws_instance"},
+	{regexp.MustCompile(`^vol-[0-9a-f]{8,17}$`), "aws_ebs_volume"},
+	{regexp.MustCompile(`^snap-[0-9a-f]{8,17}$`), "aws_ebs_snapshot"},
+	{regexp.MustCompile(`^ami-[0-9a-f]{8,17}$`), "aws_ami"},
+	{regexp.MustCompile(`^arn:aws[a-z-]*:[a-z0-9-]+:[a-z0-9-]*:[0-9]*:.+`), ""},
+	{regexp.MustCompile(`(?i)^/subscriptions/[0-9a-f-]{36}/resourceGroups/[^/]+`), ""},
+	{regexp.MustCompile(`^(?:https://www\.googleapis\.com/[a-z]+/v[0-9a-z]+/)?projects/[a-z][a-z0-9-]{4,28}[a-z0-9]/.+`), ""},
+}
+
+// IDResourceType returns the resource type whose provider IDs have the format
+// of value, or an empty type for identifiers shared by many types. It returns
+// false when value has no known ID format.
+func IDResourceType(value string) (string, bool) {
+	for _, format := range IDFormats {
+		if format.Pattern.MatchString(value) {
+			return format.ResourceType, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,32 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIDResourceType(t *testing.T) {
+	for value, expected := range map[string]string{
+		"vpc-0a1b2c3d4e5f60718":              "aws_vpc",
+		"subnet-01234567":                    "aws_subnet",
+		"tgw-attach-0123456789abcdef0":       "aws_ec2_transit_gateway_vpc_attachment",
+		"tgw-0123456789abcdef0":              "aws_ec2_transit_gateway",
+		"arn:aws:iam::123456789012:role/app": "",
+		"arn:aws-us-gov:s3:::logs":           "",
+		"/subscriptions/0b1f6471-1bf0-4dda-aec3-cb9272f09590/resourceGroups/prod/providers/x/y": "",
+		"projects/my-project/zones/europe-west1-b/instances/web":                                "",
+		"https://www.googleapis.com/compute/v1/projects/my-project/global/networks/default":     "",
+	} {
+		resourceType, ok := IDResourceType(value)
+		assert.True(t, ok, value)
+		assert.Equal(t, expected, resourceType, value)
+	}
+
+	for _, value := range []string{"main", "vpc-xyz", "10.0.0.0/16", "sg-0123", "arn:lb/web", "projects"} {
+		_, ok := IDResourceType(value)
+		assert.False(t, ok, value)
+	}
+}
@@ -0,0 +1,70 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"maps"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+type instanceTarget struct {
+	nodeID string
+	key    string
+}
+
+// expandInstanceEdges replaces every edge to a resource address with one edge
+// per instance of that resource, recording the target instance key in the
+// edge metadata. Terraform records dependencies by resource, so an instance
+// depends on all current instances of its target, but not on its deposed
+// objects. Edges to addresses that match no resource are kept unchanged, as
+// are all edges when instances are folded.
+func expandInstanceEdges(graph *models.Graph, state *models.TerraformState, opts GraphOptions) {
+	if opts.FoldInstances {
+		return
+	}
+
+	targets := make(map[string][]instanceTarget)
+	seen := make(map[string]bool)
+
+	for _, ref := range instances(state, opts) {
+		if ref.Instance.Deposed != "" {
+			continue
+		}
+
+		target := instanceTarget{nodeID: ref.NodeID, key: instanceKey(ref.Instance)}
+
+		for _, address := range []string{ResourceAddress(ref.Resource), ConfigAddress(ref.Resource)} {
+			if seen[address+" "+ref.NodeID] {
+				continue
+			}
+			seen[address+" "+ref.NodeID] = true
+			targets[address] = append(targets[address], target)
+		}
+	}
+
+	edges := make([]models.Edge, 0, len(graph.Edges))
+
+	for _, edge := range graph.Edges {
+		resolved, ok := targets[edge.Target]
+		if !ok {
+			edges = append(edges, edge)
+			continue
+		}
+
+		for _, target := range resolved {
+			expanded := edge
+			expanded.Target = target.nodeID
+
+			if target.key != "" {
+				expanded.Metadata = make(map[string]any, len(edge.Metadata)+1)
+				maps.Copy(expanded.Metadata, edge.Metadata)
+				expanded.Metadata["target_instance"] = target.key
+			}
+
+			edges = append(edges, expanded)
+		}
+	}
+
+	graph.Edges = edges
+}
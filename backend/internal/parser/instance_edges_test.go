@@ -0,0 +1,147 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func instanceEdgesState() *models.TerraformState {
+	return &models.TerraformState{
+		Resources: []models.ResourceState{
+			{
+				Mode: "managed",
+				Type: "aws_subnet",
+				Name: "private",
+				Instances: []models.ResourceInstance{
+					{IndexKey: "a"},
+					{IndexKey: "b"},
+				},
+			},
+			{
+				Mode: "managed",
+				Type: "aws_instance",
+				Name: "web",
+				Instances: []models.ResourceInstance{
+					{IndexKey: float64(0), Dependencies: []string{"aws_subnet.private"}},
+					{IndexKey: float64(1), Dependencies: []string{"aws_subnet.private"}},
+				},
+			},
+			{
+				Mode:   "managed",
+				Type:   "aws_eip",
+				Name:   "web",
+				Module: "module.net[0]",
+				Instances: []models.ResourceInstance{
+					{Dependencies: []string{"aws_instance.web", "aws_vpc.external"}},
+				},
+			},
+		},
+	}
+}
+
+func edgesFrom(graph *models.Graph, source string) []models.Edge {
+	edges := []models.Edge{}
+	for _, edge := range graph.Edges {
+		if edge.Source == source {
+			edges = append(edges, edge)
+		}
+	}
+
+	SortGraph(&models.Graph{Edges: edges})
+
+	return edges
+}
+
+func TestSourceInstanceMetadata(t *testing.T) {
+	graph := BuildGraph(instanceEdgesState())
+
+	t.Run("keyed instances record their key", func(t *testing.T) {
+		edges := edgesFrom(graph, "aws_instance.web[1]")
+
+		require.Len(t, edges, 1)
+		assert.Equal(t, "aws_subnet.private", edges[0].Target)
+		assert.Equal(t, map[string]any{"source_instance": "[1]"}, edges[0].Metadata)
+	})
+
+	t.Run("unkeyed instances have no metadata", func(t *testing.T) {
+		for _, edge := range edgesFrom(graph, "module.net[0].aws_eip.web") {
+			assert.Nil(t, edge.Metadata)
+		}
+	})
+}
+
+func TestExpandInstanceEdges(t *testing.T) {
+	graph := BuildGraphWithOptions(instanceEdgesState(), GraphOptions{InstanceEdges: true})
+
+	t.Run("one edge per target instance", func(t *testing.T) {
+		edges := edgesFrom(graph, "aws_instance.web[0]")
+
+		require.Len(t, edges, 2)
+		assert.Equal(t, "aws_subnet.private[a]", edges[0].Target)
+		assert.Equal(t, map[string]any{"source_instance": "[0]", "target_instance": `["a"]`}, edges[0].Metadata)
+		assert.Equal(t, "aws_subnet.private[b]", edges[1].Target)
+		assert.Equal(t, map[string]any{"source_instance": "[0]", "target_instance": `["b"]`}, edges[1].Metadata)
+	})
+
+	t.Run("unresolved targets are kept", func(t *testing.T) {
+		edges := edgesFrom(graph, "module.net[0].aws_eip.web")
+
+		require.Len(t, edges, 3)
+		assert.Equal(t, "aws_instance.web[0]", edges[0].Target)
+		assert.Equal(t, map[string]any{"target_instance": "[0]"}, edges[0].Metadata)
+		assert.Equal(t, "aws_instance.web[1]", edges[1].Target)
+		assert.Equal(t, "aws_vpc.external", edges[2].Target)
+		assert.Nil(t, edges[2].Metadata)
+	})
+
+	t.Run("every resolved edge points at a node", func(t *testing.T) {
+		ids := map[string]bool{}
+		for _, node := range graph.Nodes {
+			ids[node.ID] = true
+		}
+
+		for _, edge := range graph.Edges {
+			if edge.Target != "aws_vpc.external" {
+				assert.True(t, ids[edge.Target], edge.Target)
+			}
+		}
+	})
+
+	t.Run("configuration addresses resolve to module instances", func(t *testing.T) {
+		state := instanceEdgesState()
+		state.Resources[1].Instances = []models.ResourceInstance{{Dependencies: []string{"module.net.aws_eip.web"}}}
+
+		edges := edgesFrom(BuildGraphWithOptions(state, GraphOptions{InstanceEdges: true}), "aws_instance.web")
+
+		require.Len(t, edges, 1)
+		assert.Equal(t, "module.net[0].aws_eip.web", edges[0].Target)
+	})
+
+	t.Run("terraform IDs", func(t *testing.T) {
+		graph := BuildGraphWithOptions(instanceEdgesState(), GraphOptions{IDFormat: IDFormatTerraform, InstanceEdges: true})
+		edges := edgesFrom(graph, "aws_instance.web[1]")
+
+		require.Len(t, edges, 2)
+		assert.Equal(t, `aws_subnet.private["a"]`, edges[0].Target)
+		assert.Equal(t, `aws_subnet.private["b"]`, edges[1].Target)
+	})
+
+	t.Run("delta cache builds the same graph", func(t *testing.T) {
+		state := instanceEdgesState()
+		state.Version = 4
+		state.TerraformVersion = "1.5.0"
+		data, err := json.Marshal(state)
+		require.NoError(t, err)
+
+		result, err := NewDeltaCache(1).Parse(data, GraphOptions{InstanceEdges: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, Digest(graph), Digest(result.Graph))
+	})
+}
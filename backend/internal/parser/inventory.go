@@ -0,0 +1,191 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// configItem is a resource as AWS Config describes it, in configuration
+// items, resource identifiers and query results alike.
+type configItem struct {
+	ResourceID   string `json:"resourceId"`
+	ARN          string `json:"arn"`
+	ResourceType string `json:"resourceType"`
+	ResourceName string `json:"resourceName"`
+	AWSRegion    string `json:"awsRegion"`
+	AWSAccountID string `json:"awsAccountId"`
+	AccountID    string `json:"accountId"`
+}
+
+// configExport holds the resources of the AWS Config exports ParseInventory
+// reads.
+type configExport struct {
+	ConfigurationItems  []configItem `json:"configurationItems"`
+	ResourceIdentifiers []configItem `json:"resourceIdentifiers"`
+	Results             []string     `json:"Results"`
+}
+
+// inventoryColumns maps the normalized headers of inventory CSV exports to
+// the fields they fill.
+var inventoryColumns = map[string]string{
+	"id":              "id",
+	"resourceid":      "id",
+	"identifier":      "id",
+	"arn":             "arn",
+	"resourcearn":     "arn",
+	"type":            "type",
+	"resourcetype":    "type",
+	"name":            "name",
+	"resourcename":    "name",
+	"region":          "region",
+	"awsregion":       "region",
+	"account":         "account",
+	"accountid":       "account",
+	"awsaccountid":    "account",
+	"owningaccountid": "account",
+}
+
+// ParseInventory parses a cloud inventory export: an AWS Config snapshot or
+// configuration history (configurationItems), the output of aws configservice
+// list-discovered-resources (resourceIdentifiers) or select-resource-config
+// (Results), or a CSV resource list whose header names an id or arn column,
+// such as a Resource Explorer or Tag Editor export. Identifiers that are
+// ARNs are also used as the ARN of their resource.
+func ParseInventory(data []byte) (*models.Inventory, error) {
+	data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("\xef\xbb\xbf"))
+	if len(data) == 0 {
+		return nil, errors.New("empty inventory")
+	}
+
+	var (
+		resources []models.InventoryResource
+		err       error
+	)
+	if data[0] == '{' {
+		resources, err = parseConfigExport(data)
+	} else {
+		resources, err = parseInventoryCSV(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	inventory := &models.Inventory{Resources: make([]models.InventoryResource, 0, len(resources))}
+	for _, resource := range resources {
+		if resource.ARN == "" && strings.HasPrefix(resource.ID, "arn:") {
+			resource.ARN = resource.ID
+		}
+		if resource.ID != "" || resource.ARN != "" {
+			inventory.Resources = append(inventory.Resources, resource)
+		}
+	}
+
+	return inventory, nil
+}
+
+func parseConfigExport(data []byte) ([]models.InventoryResource, error) {
+	var export configExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("invalid AWS Config export: %w", err)
+	}
+
+	if export.ConfigurationItems == nil && export.ResourceIdentifiers == nil && export.Results == nil {
+		return nil, errors.New("invalid AWS Config export: no configurationItems, resourceIdentifiers or Results")
+	}
+
+	items := append(export.ConfigurationItems, export.ResourceIdentifiers...)
+	for i, result := range export.Results {
+		var item configItem
+		if err := json.Unmarshal([]byte(result), &item); err != nil {
+			return nil, fmt.Errorf("invalid AWS Config result %d: %w", i, err)
+		}
+		items = append(items, item)
+	}
+
+	resources := make([]models.InventoryResource, 0, len(items))
+	for _, item := range items {
+		account := item.AWSAccountID
+		if account == "" {
+			account = item.AccountID
+		}
+
+		resources = append(resources, models.InventoryResource{
+			ID:      item.ResourceID,
+			ARN:     item.ARN,
+			Type:    item.ResourceType,
+			Name:    item.ResourceName,
+			Region:  item.AWSRegion,
+			Account: account,
+		})
+	}
+
+	return resources, nil
+}
+
+func parseInventoryCSV(data []byte) ([]models.InventoryResource, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid inventory CSV: %w", err)
+	}
+
+	fields := make([]string, len(header))
+	identified := false
+	for i, name := range header {
+		normalized := strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+		fields[i] = inventoryColumns[normalized]
+		identified = identified || fields[i] == "id" || fields[i] == "arn"
+	}
+	if !identified {
+		return nil, errors.New("invalid inventory CSV: no id or arn column")
+	}
+
+	var resources []models.InventoryResource
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid inventory CSV: %w", err)
+		}
+
+		var resource models.InventoryResource
+		for i, value := range record {
+			if i >= len(fields) {
+				break
+			}
+
+			value = strings.TrimSpace(value)
+			switch fields[i] {
+			case "id":
+				resource.ID = value
+			case "arn":
+				resource.ARN = value
+			case "type":
+				resource.Type = value
+			case "name":
+				resource.Name = value
+			case "region":
+				resource.Region = value
+			case "account":
+				resource.Account = value
+			}
+		}
+
+		resources = append(resources, resource)
+	}
+
+	return resources, nil
+}
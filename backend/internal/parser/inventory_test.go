@@ -0,0 +1,72 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestParseInventory(t *testing.T) {
+	t.Run("reads AWS Config snapshots", func(t *testing.T) {
+		inventory, err := ParseInventory([]byte(`{"fileVersion": "1.0", "configurationItems": [{
+			"resourceType": "AWS::EC2::Instance", "resourceId": "i-0abc123", "resourceName": "web",
+			"ARN": "arn:aws:ec2:eu-west-1:123456789012:instance/i-0abc123",
+			"awsRegion": "eu-west-1", "awsAccountId": "123456789012"}]}`))
+		require.NoError(t, err)
+
+		assert.Equal(t, []models.InventoryResource{{
+			ID:      "i-0abc123",
+			ARN:     "arn:aws:ec2:eu-west-1:123456789012:instance/i-0abc123",
+			Type:    "AWS::EC2::Instance",
+			Name:    "web",
+			Region:  "eu-west-1",
+			Account: "123456789012",
+		}}, inventory.Resources)
+	})
+
+	t.Run("reads discovered resources", func(t *testing.T) {
+		inventory, err := ParseInventory([]byte(`{"resourceIdentifiers": [
+			{"resourceType": "AWS::S3::Bucket", "resourceId": "logs", "resourceName": "logs"}]}`))
+		require.NoError(t, err)
+
+		assert.Equal(t, []models.InventoryResource{{ID: "logs", Type: "AWS::S3::Bucket", Name: "logs"}}, inventory.Resources)
+	})
+
+	t.Run("reads query results", func(t *testing.T) {
+		inventory, err := ParseInventory([]byte(`{"Results": [
+			"{\"resourceId\": \"vpc-1\", \"resourceType\": \"AWS::EC2::VPC\", \"accountId\": \"123456789012\"}"]}`))
+		require.NoError(t, err)
+
+		assert.Equal(t, []models.InventoryResource{{ID: "vpc-1", Type: "AWS::EC2::VPC", Account: "123456789012"}}, inventory.Resources)
+	})
+
+	t.Run("reads CSV resource lists", func(t *testing.T) {
+		inventory, err := ParseInventory([]byte("\xef\xbb\xbfIdentifier,Resource type,Region,Tags\n" +
+			"arn:aws:sqs:eu-west-1:123456789012:jobs,sqs:queue,eu-west-1,\n" +
+			",,eu-west-1,\n"))
+		require.NoError(t, err)
+
+		assert.Equal(t, []models.InventoryResource{{
+			ID:     "arn:aws:sqs:eu-west-1:123456789012:jobs",
+			ARN:    "arn:aws:sqs:eu-west-1:123456789012:jobs",
+			Type:   "sqs:queue",
+			Region: "eu-west-1",
+		}}, inventory.Resources)
+	})
+
+	t.Run("rejects other documents", func(t *testing.T) {
+		for name, data := range map[string]string{
+			"empty":          " ",
+			"unknown JSON":   `{"resources": []}`,
+			"invalid JSON":   `{"configurationItems": [`,
+			"CSV without id": "name,region\nweb,eu-west-1\n",
+		} {
+			_, err := ParseInventory([]byte(data))
+			assert.Error(t, err, name)
+		}
+	})
+}
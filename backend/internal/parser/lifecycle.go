@@ -0,0 +1,68 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// sdkTimeoutsKey is the key under which the Terraform plugin SDK stores the
+// operation timeouts of a resource in the instance's private data.
+const sdkTimeoutsKey = "e2bfb730-ecaa-11e6-8f88-34363bc7c4c0"
+
+// lifecycleMetadata returns the lifecycle hints recorded with the instance:
+// create_before_destroy, which the state keeps for replacements, and the
+// operation timeouts found in the provider's private data. It returns nil
+// when there are none.
+func lifecycleMetadata(instance models.ResourceInstance) map[string]any {
+	lifecycle := map[string]any{}
+
+	if instance.CreateBeforeDestroy {
+		lifecycle["create_before_destroy"] = true
+	}
+
+	if timeouts := privateTimeouts(instance.Private); len(timeouts) > 0 {
+		lifecycle["timeouts"] = timeouts
+	}
+
+	if len(lifecycle) == 0 {
+		return nil
+	}
+
+	return lifecycle
+}
+
+// privateTimeouts decodes the operation timeouts, such as create or delete,
+// from the base64 JSON private data written by the plugin SDK. Private data
+// is opaque to Terraform, so anything it cannot decode yields no timeouts.
+func privateTimeouts(private string) map[string]string {
+	if private == "" {
+		return nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(private)
+	if err != nil {
+		return nil
+	}
+
+	var blob map[string]json.RawMessage
+	if err := json.Unmarshal(data, &blob); err != nil {
+		return nil
+	}
+
+	var raw map[string]float64
+	if err := json.Unmarshal(blob[sdkTimeoutsKey], &raw); err != nil {
+		return nil
+	}
+
+	timeouts := make(map[string]string, len(raw))
+	for operation, nanoseconds := range raw {
+		timeouts[operation] = time.Duration(nanoseconds).String()
+	}
+
+	return timeouts
+}
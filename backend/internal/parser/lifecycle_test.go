@@ -0,0 +1,68 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/terrascope/core/internal/models"
+)
+
+func privateBlob(json string) string {
+	return base64.StdEncoding.EncodeToString([]byte(json))
+}
+
+func TestLifecycleMetadata(t *testing.T) {
+	timeouts := privateBlob(`{"e2bfb730-ecaa-11e6-8f88-34363bc7c4c0":{"create":600000000000,"delete":1200000000000},"schema_version":"1"}`)
+
+	tests := []struct {
+		name     string
+		instance models.ResourceInstance
+		expected map[string]any
+	}{
+		{"no hints", models.ResourceInstance{}, nil},
+		{
+			"create_before_destroy",
+			models.ResourceInstance{CreateBeforeDestroy: true},
+			map[string]any{"create_before_destroy": true},
+		},
+		{
+			"sdk timeouts",
+			models.ResourceInstance{Private: timeouts, CreateBeforeDestroy: true},
+			map[string]any{
+				"create_before_destroy": true,
+				"timeouts":              map[string]string{"create": "10m0s", "delete": "20m0s"},
+			},
+		},
+		{"private data without timeouts", models.ResourceInstance{Private: privateBlob(`{"schema_version":"1"}`)}, nil},
+		{"opaque private data", models.ResourceInstance{Private: privateBlob("not json")}, nil},
+		{"invalid base64", models.ResourceInstance{Private: "%%%"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, lifecycleMetadata(tt.instance))
+		})
+	}
+
+	t.Run("nodes carry the hints", func(t *testing.T) {
+		state := &models.TerraformState{
+			Resources: []models.ResourceState{{
+				Mode: "managed", Type: "aws_instance", Name: "web",
+				Instances: []models.ResourceInstance{{
+					Attributes:          map[string]any{"id": "i-1"},
+					CreateBeforeDestroy: true,
+					Private:             timeouts,
+				}},
+			}},
+		}
+
+		graph := BuildGraph(state)
+
+		lifecycle := graph.Nodes[0].Metadata["lifecycle"].(map[string]any)
+		assert.Equal(t, true, lifecycle["create_before_destroy"])
+		assert.Equal(t, "10m0s", lifecycle["timeouts"].(map[string]string)["create"])
+	})
+}
@@ -0,0 +1,95 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// lockInfoFile is the lock info Terraform writes next to a locked state
+// (.terraform.tfstate.lock.info) and stores in the Info of lock table items.
+type lockInfoFile struct {
+	ID        string    `json:"ID"`
+	Operation string    `json:"Operation"`
+	Info      string    `json:"Info"`
+	Who       string    `json:"Who"`
+	Version   string    `json:"Version"`
+	Created   time.Time `json:"Created"`
+	Path      string    `json:"Path"`
+}
+
+// ParseLockInfo parses a Terraform lock info document. It also accepts the
+// DynamoDB item the S3 backend uses as lock, either as returned by
+// get-item ({"Item": {"LockID": {"S": ...}, "Info": {"S": ...}}}) or with
+// plain string attributes, and reads the lock info from its Info attribute.
+func ParseLockInfo(data []byte) (*models.LockInfo, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty lock info")
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal lock info: %w", err)
+	}
+
+	if item, ok := fields["Item"]; ok {
+		return ParseLockInfo(item)
+	}
+
+	if _, ok := fields["LockID"]; ok {
+		info, err := dynamoString(fields["Info"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid lock item Info: %w", err)
+		}
+		if info == "" {
+			return nil, fmt.Errorf("lock item has no Info, the state is not locked")
+		}
+
+		return ParseLockInfo([]byte(info))
+	}
+
+	var file lockInfoFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal lock info: %w", err)
+	}
+
+	if file.ID == "" {
+		return nil, fmt.Errorf("missing lock ID")
+	}
+
+	return &models.LockInfo{
+		ID:        file.ID,
+		Operation: file.Operation,
+		Info:      file.Info,
+		Who:       file.Who,
+		Version:   file.Version,
+		Created:   file.Created,
+		Path:      file.Path,
+	}, nil
+}
+
+// dynamoString decodes a string attribute given either as a DynamoDB
+// attribute value ({"S": "..."}) or as a plain JSON string.
+func dynamoString(data json.RawMessage) (string, error) {
+	if len(data) == 0 {
+		return "", nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return s, nil
+	}
+
+	var value struct {
+		S *string `json:"S"`
+	}
+	if err := json.Unmarshal(data, &value); err != nil || value.S == nil {
+		return "", fmt.Errorf("expected a string attribute")
+	}
+
+	return *value.S, nil
+}
@@ -0,0 +1,91 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+const lockInfoJSON = `{
+	"ID": "4f1c0a0e-7a33-2f5c-9f43-5f1ddb0c1a2b",
+	"Operation": "OperationTypeApply",
+	"Info": "",
+	"Who": "runner@ci-runner-42",
+	"Version": "1.5.7",
+	"Created": "2024-03-05T10:15:30.123456Z",
+	"Path": "tfstate-bucket/prod/terraform.tfstate"
+}`
+
+func TestParseLockInfo(t *testing.T) {
+	expected := &models.LockInfo{
+		ID:        "4f1c0a0e-7a33-2f5c-9f43-5f1ddb0c1a2b",
+		Operation: "OperationTypeApply",
+		Who:       "runner@ci-runner-42",
+		Version:   "1.5.7",
+		Created:   time.Date(2024, 3, 5, 10, 15, 30, 123456000, time.UTC),
+		Path:      "tfstate-bucket/prod/terraform.tfstate",
+	}
+
+	t.Run("lock info file", func(t *testing.T) {
+		lock, err := ParseLockInfo([]byte(lockInfoJSON))
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, lock)
+	})
+
+	t.Run("dynamodb get-item output", func(t *testing.T) {
+		item := `{"Item": {"LockID": {"S": "tfstate-bucket/prod/terraform.tfstate"}, "Info": {"S": ` + quoteJSON(lockInfoJSON) + `}}}`
+
+		lock, err := ParseLockInfo([]byte(item))
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, lock)
+	})
+
+	t.Run("dynamodb item with plain attributes", func(t *testing.T) {
+		item := `{"LockID": "tfstate-bucket/prod/terraform.tfstate", "Info": ` + quoteJSON(lockInfoJSON) + `}`
+
+		lock, err := ParseLockInfo([]byte(item))
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, lock)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			input string
+			err   string
+		}{
+			{"empty", "", "empty lock info"},
+			{"invalid json", "{", "failed to unmarshal lock info"},
+			{"missing ID", `{"Who": "me"}`, "missing lock ID"},
+			{"digest item without info", `{"LockID": {"S": "bucket/key-md5"}, "Digest": {"S": "abc"}}`, "the state is not locked"},
+			{"info of the wrong type", `{"LockID": {"S": "bucket/key"}, "Info": {"N": "1"}}`, "invalid lock item Info"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := ParseLockInfo([]byte(tt.input))
+
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+			})
+		}
+	})
+}
+
+func quoteJSON(s string) string {
+	data, err := json.Marshal(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return string(data)
+}
@@ -0,0 +1,119 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// ParseDependencyLock parses a dependency lock file (.terraform.lock.hcl).
+// It reads the provider blocks in the layout terraform init writes them, one
+// attribute per line, rather than arbitrary HCL, and skips other blocks.
+func ParseDependencyLock(data []byte) (*models.DependencyLock, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, fmt.Errorf("empty lock file")
+	}
+
+	lock := &models.DependencyLock{Providers: make(map[string]models.LockedProvider)}
+
+	var (
+		address  string
+		provider models.LockedProvider
+		inBlock  bool
+		inHashes bool
+		skipped  int
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		switch {
+		case skipped > 0:
+			skipped += strings.Count(line, "{") - strings.Count(line, "}")
+		case inHashes:
+			if line == "]" {
+				inHashes = false
+				continue
+			}
+
+			hash, err := strconv.Unquote(strings.TrimSuffix(line, ","))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid hash %s", n, line)
+			}
+			provider.Hashes = append(provider.Hashes, hash)
+		case inBlock:
+			if line == "}" {
+				if provider.Version == "" {
+					return nil, fmt.Errorf("provider %s has no version", address)
+				}
+				lock.Providers[address] = provider
+				inBlock = false
+				continue
+			}
+
+			name, value, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected an attribute, got %s", n, line)
+			}
+			name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+
+			if name == "hashes" {
+				if value != "[" {
+					return nil, fmt.Errorf("line %d: expected a list of hashes, got %s", n, value)
+				}
+				inHashes = true
+				continue
+			}
+
+			text, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid %s %s", n, name, value)
+			}
+
+			switch name {
+			case "version":
+				provider.Version = text
+			case "constraints":
+				provider.Constraints = text
+			}
+		default:
+			block, ok := strings.CutSuffix(line, "{")
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected a block, got %s", n, line)
+			}
+
+			fields := strings.Fields(block)
+			if len(fields) != 2 || fields[0] != "provider" {
+				skipped = 1
+				continue
+			}
+
+			source, err := strconv.Unquote(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid provider address %s", n, fields[1])
+			}
+
+			address, provider, inBlock = NormalizeProviderSource(source), models.LockedProvider{}, true
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read lock file: %w", err)
+	}
+
+	if inBlock || inHashes || skipped > 0 {
+		return nil, fmt.Errorf("unterminated block at end of lock file")
+	}
+
+	return lock, nil
+}
@@ -0,0 +1,77 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+const dependencyLockHCL = `# This file is maintained automatically by "terraform init".
+# Manual edits may be lost in future updates.
+
+provider "registry.terraform.io/hashicorp/aws" {
+  version     = "5.31.0"
+  constraints = ">= 4.0.0, < 6.0.0"
+  hashes = [
+    "h1:ltxyuBWIy9cq0kIKDJH1jeWJy/y7XJLjS4QrsQK4plA=",
+    "zh:0cdb9c2083bf0902442384f7309367791e4640581652dda456f2d6d7abf0de8d",
+  ]
+}
+
+provider "registry.terraform.io/hashicorp/random" {
+  version = "3.6.0"
+}
+`
+
+func TestParseDependencyLock(t *testing.T) {
+	t.Run("reads the provider blocks", func(t *testing.T) {
+		lock, err := ParseDependencyLock([]byte(dependencyLockHCL))
+
+		require.NoError(t, err)
+		assert.Equal(t, &models.DependencyLock{Providers: map[string]models.LockedProvider{
+			"registry.terraform.io/hashicorp/aws": {
+				Version:     "5.31.0",
+				Constraints: ">= 4.0.0, < 6.0.0",
+				Hashes: []string{
+					"h1:ltxyuBWIy9cq0kIKDJH1jeWJy/y7XJLjS4QrsQK4plA=",
+					"zh:0cdb9c2083bf0902442384f7309367791e4640581652dda456f2d6d7abf0de8d",
+				},
+			},
+			"registry.terraform.io/hashicorp/random": {Version: "3.6.0"},
+		}}, lock)
+	})
+
+	t.Run("normalizes provider addresses", func(t *testing.T) {
+		lock, err := ParseDependencyLock([]byte("provider \"hashicorp/aws\" {\n  version = \"5.31.0\"\n}\n"))
+
+		require.NoError(t, err)
+		assert.Contains(t, lock.Providers, "registry.terraform.io/hashicorp/aws")
+	})
+
+	t.Run("skips other blocks", func(t *testing.T) {
+		data := "module \"vpc\" {\n  nested {\n    a = 1\n  }\n}\n" + dependencyLockHCL
+
+		lock, err := ParseDependencyLock([]byte(data))
+
+		require.NoError(t, err)
+		assert.Len(t, lock.Providers, 2)
+	})
+
+	t.Run("rejects invalid lock files", func(t *testing.T) {
+		for name, data := range map[string]string{
+			"empty":            "  \n",
+			"no version":       "provider \"hashicorp/aws\" {\n}\n",
+			"unterminated":     "provider \"hashicorp/aws\" {\n  version = \"5.31.0\"\n",
+			"unquoted value":   "provider \"hashicorp/aws\" {\n  version = 5.31.0\n}\n",
+			"stray attribute":  "version = \"5.31.0\"\n",
+			"unquoted address": "provider hashicorp/aws {\n  version = \"5.31.0\"\n}\n",
+		} {
+			_, err := ParseDependencyLock([]byte(data))
+			assert.Error(t, err, name)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+func ParseModuleManifest(data []byte) (*models.ModuleManifest, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty module manifest")
+	}
+
+	var manifest models.ModuleManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal module manifest: %w", err)
+	}
+
+	return &manifest, nil
+}
+
+// ModuleKey converts a module instance path from the state into the key used
+// by the module manifest, e.g. module.app[0].module.db becomes app.db. The
+// root module has an empty key.
+func ModuleKey(module string) string {
+	if module == "" {
+		return ""
+	}
+
+	parts := strings.Split(moduleInstanceKey.ReplaceAllString(module, ""), ".")
+	names := make([]string, 0, len(parts)/2)
+	for i := 1; i < len(parts); i += 2 {
+		names = append(names, parts[i])
+	}
+
+	return strings.Join(names, ".")
+}
@@ -0,0 +1,57 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseModuleManifest(t *testing.T) {
+	t.Run("parses module entries", func(t *testing.T) {
+		data := `{"Modules": [
+			{"Key": "", "Source": "", "Dir": "."},
+			{"Key": "vpc", "Source": "registry.terraform.io/terraform-aws-modules/vpc/aws", "Version": "5.1.0", "Dir": ".terraform/modules/vpc"}
+		]}`
+
+		manifest, err := ParseModuleManifest([]byte(data))
+
+		require.NoError(t, err)
+		require.Len(t, manifest.Modules, 2)
+		assert.Equal(t, "vpc", manifest.Modules[1].Key)
+		assert.Equal(t, "registry.terraform.io/terraform-aws-modules/vpc/aws", manifest.Modules[1].Source)
+		assert.Equal(t, "5.1.0", manifest.Modules[1].Version)
+	})
+
+	t.Run("empty data", func(t *testing.T) {
+		_, err := ParseModuleManifest(nil)
+
+		assert.EqualError(t, err, "empty module manifest")
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		_, err := ParseModuleManifest([]byte(`{invalid`))
+
+		assert.ErrorContains(t, err, "failed to unmarshal module manifest")
+	})
+}
+
+func TestModuleKey(t *testing.T) {
+	tests := []struct {
+		module   string
+		expected string
+	}{
+		{"", ""},
+		{"module.vpc", "vpc"},
+		{"module.app[0].module.db", "app.db"},
+		{`module.app["blue"].module.compute[1]`, "app.compute"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.module, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ModuleKey(tt.module))
+		})
+	}
+}
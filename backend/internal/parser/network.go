@@ -0,0 +1,295 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"net"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+const (
+	layerNetwork   = "network"
+	layerSubnet    = "subnet"
+	layerRouting   = "routing"
+	layerGateway   = "gateway"
+	layerInterface = "interface"
+	layerCompute   = "compute"
+)
+
+const (
+	edgeContains   = "contains"
+	edgeRoutedBy   = "routed_by"
+	edgeRoutesTo   = "routes_to"
+	edgeAttachedTo = "attached_to"
+)
+
+const (
+	routeAssocType = "aws_route_table_association"
+	routeEntryType = "aws_route"
+)
+
+var networkLayers = map[string]string{
+	"aws_vpc":                          layerNetwork,
+	"aws_default_vpc":                  layerNetwork,
+	"aws_subnet":                       layerSubnet,
+	"aws_default_subnet":               layerSubnet,
+	"aws_route_table":                  layerRouting,
+	"aws_default_route_table":          layerRouting,
+	"aws_internet_gateway":             layerGateway,
+	"aws_egress_only_internet_gateway": layerGateway,
+	"aws_nat_gateway":                  layerGateway,
+	"aws_vpn_gateway":                  layerGateway,
+	"aws_vpc_endpoint":                 layerGateway,
+	"aws_network_interface":            layerInterface,
+	"aws_instance":                     layerCompute,
+}
+
+// routeTargetKeys lists the route attributes that point at a gateway-like resource.
+var routeTargetKeys = []string{
+	"gateway_id",
+	"nat_gateway_id",
+	"egress_only_gateway_id",
+	"vpc_endpoint_id",
+	"network_interface_id",
+}
+
+type networkIndex struct {
+	opts    GraphOptions
+	graph   *models.Graph
+	byID    map[string]string
+	edgeSet map[string]bool
+	vpcs    []vpcCIDR
+}
+
+type vpcCIDR struct {
+	nodeID string
+	cidr   *net.IPNet
+}
+
+// BuildNetworkGraph projects the state onto a hierarchical network topology:
+// VPCs contain subnets, route tables and gateways, subnets contain instances and
+// network interfaces. Relationships are derived from attributes (vpc_id,
+// subnet_id, CIDR blocks) rather than from recorded dependencies.
+func BuildNetworkGraph(state *models.TerraformState) *models.Graph {
+	return BuildNetworkGraphWithOptions(state, GraphOptions{})
+}
+
+// BuildNetworkGraphWithOptions builds the network topology of the state,
+// addressing nodes as selected by opts.
+func BuildNetworkGraphWithOptions(state *models.TerraformState, opts GraphOptions) *models.Graph {
+	idx := &networkIndex{
+		opts: opts,
+		graph: &models.Graph{
+			Nodes: []models.Node{},
+			Edges: []models.Edge{},
+		},
+		byID:    make(map[string]string),
+		edgeSet: make(map[string]bool),
+	}
+	nodeMap := make(map[string]bool)
+
+	for _, res := range state.Resources {
+		layer, ok := networkLayers[res.Type]
+		if !ok || res.Mode != "managed" {
+			continue
+		}
+
+		for i, instance := range res.Instances {
+			nodeID := idx.opts.nodeID(res, instance, i)
+			if nodeMap[nodeID] {
+				continue
+			}
+
+			metadata := idx.opts.nodeMetadata(res, instance)
+			metadata["layer"] = layer
+			if cidr, ok := instance.Attributes["cidr_block"].(string); ok && cidr != "" {
+				metadata["cidr_block"] = cidr
+			}
+
+			idx.graph.Nodes = append(idx.graph.Nodes, models.Node{
+				ID:              nodeID,
+				Type:            res.Type,
+				Mode:            res.Mode,
+				Provider:        extractProviderName(res.Provider),
+				ProviderAddress: ProviderAddress(res.Provider),
+				Module:          res.Module,
+				Metadata:        metadata,
+			})
+			nodeMap[nodeID] = true
+
+			if id, ok := instance.Attributes["id"].(string); ok && id != "" {
+				idx.byID[id] = nodeID
+			}
+
+			if layer == layerNetwork {
+				if _, cidr, err := net.ParseCIDR(stringAttr(instance.Attributes, "cidr_block")); err == nil {
+					idx.vpcs = append(idx.vpcs, vpcCIDR{nodeID: nodeID, cidr: cidr})
+				}
+			}
+		}
+	}
+
+	for _, res := range state.Resources {
+		if res.Mode != "managed" {
+			continue
+		}
+
+		for i, instance := range res.Instances {
+			idx.linkInstance(res, instance, i)
+		}
+	}
+
+	attachAddresses(idx.graph, state, opts)
+
+	return idx.graph
+}
+
+func (idx *networkIndex) linkInstance(res models.ResourceState, instance models.ResourceInstance, instanceIndex int) {
+	attrs := instance.Attributes
+
+	switch res.Type {
+	case routeAssocType:
+		idx.addEdge(attrs["subnet_id"], attrs["route_table_id"], edgeRoutedBy)
+		idx.addEdge(attrs["gateway_id"], attrs["route_table_id"], edgeRoutedBy)
+		return
+	case routeEntryType:
+		for _, key := range routeTargetKeys {
+			idx.addEdge(attrs["route_table_id"], attrs[key], edgeRoutesTo)
+		}
+		return
+	}
+
+	layer, ok := networkLayers[res.Type]
+	if !ok {
+		return
+	}
+
+	nodeID := idx.opts.nodeID(res, instance, instanceIndex)
+
+	switch layer {
+	case layerSubnet:
+		if !idx.addEdgeTo(attrs["vpc_id"], nodeID, edgeContains) {
+			idx.containByCIDR(stringAttr(attrs, "cidr_block"), nodeID)
+		}
+	case layerRouting:
+		idx.addEdgeTo(attrs["vpc_id"], nodeID, edgeContains)
+		for _, route := range objectList(attrs["route"]) {
+			for _, key := range routeTargetKeys {
+				idx.addEdgeFrom(nodeID, route[key], edgeRoutesTo)
+			}
+		}
+	case layerGateway:
+		if !idx.addEdgeTo(attrs["subnet_id"], nodeID, edgeContains) {
+			idx.addEdgeTo(attrs["vpc_id"], nodeID, edgeContains)
+		}
+	case layerInterface:
+		idx.addEdgeTo(attrs["subnet_id"], nodeID, edgeContains)
+		for _, attachment := range objectList(attrs["attachment"]) {
+			idx.addEdgeFrom(nodeID, attachment["instance"], edgeAttachedTo)
+		}
+	case layerCompute:
+		idx.addEdgeTo(attrs["subnet_id"], nodeID, edgeContains)
+	}
+}
+
+// addEdge links two resources identified by their cloud IDs.
+func (idx *networkIndex) addEdge(sourceID, targetID any, edgeType string) bool {
+	source, ok := idx.resolve(sourceID)
+	if !ok {
+		return false
+	}
+
+	return idx.addEdgeFrom(source, targetID, edgeType)
+}
+
+// addEdgeTo links the resource owning parentID to an already known node.
+func (idx *networkIndex) addEdgeTo(parentID any, nodeID string, edgeType string) bool {
+	parent, ok := idx.resolve(parentID)
+	if !ok {
+		return false
+	}
+
+	idx.appendEdge(parent, nodeID, edgeType)
+
+	return true
+}
+
+// addEdgeFrom links an already known node to the resource owning targetID.
+func (idx *networkIndex) addEdgeFrom(nodeID string, targetID any, edgeType string) bool {
+	target, ok := idx.resolve(targetID)
+	if !ok {
+		return false
+	}
+
+	idx.appendEdge(nodeID, target, edgeType)
+
+	return true
+}
+
+func (idx *networkIndex) appendEdge(source, target, edgeType string) {
+	key := edgeKey(source, target, edgeType)
+	if source == target || idx.edgeSet[key] {
+		return
+	}
+
+	idx.edgeSet[key] = true
+	idx.graph.Edges = append(idx.graph.Edges, models.Edge{Source: source, Target: target, Type: edgeType})
+}
+
+func (idx *networkIndex) resolve(value any) (string, bool) {
+	id, ok := value.(string)
+	if !ok || id == "" {
+		return "", false
+	}
+
+	nodeID, ok := idx.byID[id]
+
+	return nodeID, ok
+}
+
+// containByCIDR attaches a subnet to the VPC whose CIDR block covers it when
+// the subnet does not carry a resolvable vpc_id.
+func (idx *networkIndex) containByCIDR(cidr string, nodeID string) {
+	ip, subnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return
+	}
+
+	subnetSize, _ := subnet.Mask.Size()
+	for _, vpc := range idx.vpcs {
+		vpcSize, _ := vpc.cidr.Mask.Size()
+		if vpc.cidr.Contains(ip) && vpcSize <= subnetSize {
+			idx.appendEdge(vpc.nodeID, nodeID, edgeContains)
+			return
+		}
+	}
+}
+
+func edgeKey(parts ...string) string {
+	return strings.Join(parts, "\x00")
+}
+
+func stringAttr(attrs map[string]any, key string) string {
+	value, _ := attrs[key].(string)
+
+	return value
+}
+
+// objectList returns the elements of a nested block attribute that are objects.
+func objectList(value any) []map[string]any {
+	items, ok := value.([]any)
+	if !ok {
+		return nil
+	}
+
+	objects := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		if obj, ok := item.(map[string]any); ok {
+			objects = append(objects, obj)
+		}
+	}
+
+	return objects
+}
@@ -0,0 +1,147 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+const awsProvider = "provider[\"registry.terraform.io/hashicorp/aws\"]"
+
+func managedResource(resType, name string, attrs map[string]any) models.ResourceState {
+	return models.ResourceState{
+		Mode:      "managed",
+		Type:      resType,
+		Name:      name,
+		Provider:  awsProvider,
+		Instances: []models.ResourceInstance{{Attributes: attrs}},
+	}
+}
+
+func hasEdge(graph *models.Graph, source, target, edgeType string) bool {
+	for _, edge := range graph.Edges {
+		if edge.Source == source && edge.Target == target && edge.Type == edgeType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func networkState() *models.TerraformState {
+	return &models.TerraformState{
+		Resources: []models.ResourceState{
+			managedResource("aws_vpc", "main", map[string]any{"id": "vpc-1", "cidr_block": "10.0.0.0/16"}),
+			managedResource("aws_subnet", "public", map[string]any{"id": "subnet-1", "vpc_id": "vpc-1", "cidr_block": "10.0.1.0/24"}),
+			managedResource("aws_subnet", "orphan", map[string]any{"id": "subnet-2", "cidr_block": "10.0.2.0/24"}),
+			managedResource("aws_internet_gateway", "igw", map[string]any{"id": "igw-1", "vpc_id": "vpc-1"}),
+			managedResource("aws_nat_gateway", "nat", map[string]any{"id": "nat-1", "subnet_id": "subnet-1"}),
+			managedResource("aws_route_table", "public", map[string]any{
+				"id":     "rtb-1",
+				"vpc_id": "vpc-1",
+				"route": []any{
+					map[string]any{"cidr_block": "0.0.0.0/0", "gateway_id": "igw-1"},
+				},
+			}),
+			managedResource("aws_route", "nat", map[string]any{"id": "r-1", "route_table_id": "rtb-1", "nat_gateway_id": "nat-1"}),
+			managedResource("aws_route_table_association", "public", map[string]any{"id": "rtbassoc-1", "subnet_id": "subnet-1", "route_table_id": "rtb-1"}),
+			managedResource("aws_instance", "web", map[string]any{"id": "i-1", "subnet_id": "subnet-1"}),
+			managedResource("aws_network_interface", "web", map[string]any{
+				"id":         "eni-1",
+				"subnet_id":  "subnet-1",
+				"attachment": []any{map[string]any{"instance": "i-1"}},
+			}),
+			managedResource("aws_s3_bucket", "assets", map[string]any{"id": "assets"}),
+		},
+	}
+}
+
+func TestBuildNetworkGraph(t *testing.T) {
+	t.Run("empty state returns empty graph", func(t *testing.T) {
+		graph := BuildNetworkGraph(&models.TerraformState{})
+
+		assert.NotNil(t, graph)
+		assert.Empty(t, graph.Nodes)
+		assert.Empty(t, graph.Edges)
+	})
+
+	t.Run("only network resources become nodes", func(t *testing.T) {
+		graph := BuildNetworkGraph(networkState())
+
+		ids := make(map[string]bool)
+		for _, node := range graph.Nodes {
+			ids[node.ID] = true
+		}
+
+		assert.Len(t, graph.Nodes, 8)
+		assert.False(t, ids["aws_s3_bucket.assets"])
+		assert.False(t, ids["aws_route.nat"])
+		assert.False(t, ids["aws_route_table_association.public"])
+	})
+
+	t.Run("nodes carry layer and cidr metadata", func(t *testing.T) {
+		graph := BuildNetworkGraph(networkState())
+
+		require.NotEmpty(t, graph.Nodes)
+		assert.Equal(t, "aws_vpc.main", graph.Nodes[0].ID)
+		assert.Equal(t, "network", graph.Nodes[0].Metadata["layer"])
+		assert.Equal(t, "10.0.0.0/16", graph.Nodes[0].Metadata["cidr_block"])
+	})
+
+	t.Run("vpc contains subnets and gateways", func(t *testing.T) {
+		graph := BuildNetworkGraph(networkState())
+
+		assert.True(t, hasEdge(graph, "aws_vpc.main", "aws_subnet.public", "contains"))
+		assert.True(t, hasEdge(graph, "aws_vpc.main", "aws_internet_gateway.igw", "contains"))
+		assert.True(t, hasEdge(graph, "aws_vpc.main", "aws_route_table.public", "contains"))
+		assert.True(t, hasEdge(graph, "aws_subnet.public", "aws_nat_gateway.nat", "contains"))
+	})
+
+	t.Run("subnet without vpc_id is placed by cidr", func(t *testing.T) {
+		graph := BuildNetworkGraph(networkState())
+
+		assert.True(t, hasEdge(graph, "aws_vpc.main", "aws_subnet.orphan", "contains"))
+	})
+
+	t.Run("route tables link subnets and gateways", func(t *testing.T) {
+		graph := BuildNetworkGraph(networkState())
+
+		assert.True(t, hasEdge(graph, "aws_subnet.public", "aws_route_table.public", "routed_by"))
+		assert.True(t, hasEdge(graph, "aws_route_table.public", "aws_internet_gateway.igw", "routes_to"))
+		assert.True(t, hasEdge(graph, "aws_route_table.public", "aws_nat_gateway.nat", "routes_to"))
+	})
+
+	t.Run("instances and interfaces sit in subnets", func(t *testing.T) {
+		graph := BuildNetworkGraph(networkState())
+
+		assert.True(t, hasEdge(graph, "aws_subnet.public", "aws_instance.web", "contains"))
+		assert.True(t, hasEdge(graph, "aws_subnet.public", "aws_network_interface.web", "contains"))
+		assert.True(t, hasEdge(graph, "aws_network_interface.web", "aws_instance.web", "attached_to"))
+	})
+
+	t.Run("data sources are ignored", func(t *testing.T) {
+		vpc := managedResource("aws_vpc", "default", map[string]any{"id": "vpc-1"})
+		vpc.Mode = "data"
+
+		graph := BuildNetworkGraph(&models.TerraformState{Resources: []models.ResourceState{vpc}})
+
+		assert.Empty(t, graph.Nodes)
+	})
+
+	t.Run("unresolvable references create no edges", func(t *testing.T) {
+		state := &models.TerraformState{
+			Resources: []models.ResourceState{
+				managedResource("aws_subnet", "external", map[string]any{"id": "subnet-9", "vpc_id": "vpc-unknown", "cidr_block": "invalid"}),
+			},
+		}
+
+		graph := BuildNetworkGraph(state)
+
+		assert.Len(t, graph.Nodes, 1)
+		assert.Empty(t, graph.Edges)
+	})
+}
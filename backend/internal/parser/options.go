@@ -0,0 +1,81 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"fmt"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// IDFormat selects how graph nodes are addressed.
+type IDFormat string
+
+const (
+	// IDFormatLegacy is the original addressing: data sources carry no data.
+	// prefix and instance keys are only added when a resource has several
+	// instances, unquoted.
+	IDFormatLegacy IDFormat = "legacy"
+	// IDFormatTerraform addresses nodes exactly as Terraform prints instance
+	// addresses, so IDs can be pasted into terraform state commands.
+	IDFormatTerraform IDFormat = "terraform"
+)
+
+// ParseIDFormat returns the IDFormat with the given name.
+func ParseIDFormat(name string) (IDFormat, error) {
+	switch format := IDFormat(name); format {
+	case IDFormatLegacy, IDFormatTerraform:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown id format %q", name)
+	}
+}
+
+// GraphOptions controls how graphs are built from a state. The zero value
+// builds graphs with legacy node IDs.
+type GraphOptions struct {
+	IDFormat IDFormat
+	// InstanceEdges points dependency edges at every instance of the target
+	// resource instead of at the resource address. It has no effect when
+	// FoldInstances is set.
+	InstanceEdges bool
+	// FoldInstances builds one node per resource, addressed without an
+	// instance key, with the metadata of each instance in an instances list.
+	FoldInstances bool
+	// Schemas, when set, types the attributes of each resource by its
+	// provider schema: attributes the schema marks sensitive are redacted
+	// from node metadata, and configurable attributes that hold the ID or ARN
+	// of another resource become reference edges.
+	Schemas *models.ProviderSchemas
+	// InferReferences adds reference edges for the resources without a
+	// schema, from the attribute values that have a known provider ID format
+	// and are the ID or ARN of another resource.
+	InferReferences bool
+}
+
+func (o GraphOptions) nodeID(res models.ResourceState, instance models.ResourceInstance, instanceIndex int) string {
+	switch {
+	case o.FoldInstances && o.IDFormat == IDFormatTerraform:
+		return ResourceAddress(res)
+	case o.FoldInstances:
+		return legacyResourceID(res)
+	case o.IDFormat == IDFormatTerraform:
+		return InstanceAddress(res, instance) + deposedSuffix(instance)
+	default:
+		return buildNodeID(res, instance, instanceIndex)
+	}
+}
+
+// nodeMetadata returns the metadata of the node built from the instance, or
+// of the whole resource when instances are folded.
+func (o GraphOptions) nodeMetadata(res models.ResourceState, instance models.ResourceInstance) map[string]any {
+	if o.FoldInstances {
+		return foldedMetadata(o.typedResource(res))
+	}
+
+	if block := o.schema(res); block != nil {
+		instance = typedInstance(block, instance)
+	}
+
+	return buildMetadata(res, instance)
+}
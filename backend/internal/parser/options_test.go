@@ -0,0 +1,259 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestParseIDFormat(t *testing.T) {
+	t.Run("known formats", func(t *testing.T) {
+		format, err := ParseIDFormat("legacy")
+		require.NoError(t, err)
+		assert.Equal(t, IDFormatLegacy, format)
+
+		format, err = ParseIDFormat("terraform")
+		require.NoError(t, err)
+		assert.Equal(t, IDFormatTerraform, format)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := ParseIDFormat("canonical")
+		assert.EqualError(t, err, `unknown id format "canonical"`)
+	})
+}
+
+func addressingState() *models.TerraformState {
+	return &models.TerraformState{
+		Resources: []models.ResourceState{
+			{
+				Mode: "data",
+				Type: "aws_ami",
+				Name: "ubuntu",
+				Instances: []models.ResourceInstance{
+					{Attributes: map[string]any{"id": "ami-1"}},
+				},
+			},
+			{
+				Mode: "managed",
+				Type: "aws_subnet",
+				Name: "private",
+				Instances: []models.ResourceInstance{
+					{IndexKey: "a", Attributes: map[string]any{"id": "subnet-a"}},
+					{IndexKey: "b", Attributes: map[string]any{"id": "subnet-b"}},
+				},
+			},
+			{
+				Mode:   "managed",
+				Type:   "aws_instance",
+				Name:   "web",
+				Module: "module.app[0]",
+				Instances: []models.ResourceInstance{
+					{
+						IndexKey:     float64(0),
+						Attributes:   map[string]any{"id": "i-1", "ami": "ami-1"},
+						Dependencies: []string{"data.aws_ami.ubuntu"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func nodeIDs(graph *models.Graph) []string {
+	ids := make([]string, 0, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		ids = append(ids, node.ID)
+	}
+
+	return ids
+}
+
+func TestBuildGraphWithOptions(t *testing.T) {
+	t.Run("zero options keep legacy IDs", func(t *testing.T) {
+		graph := BuildGraphWithOptions(addressingState(), GraphOptions{})
+
+		assert.Equal(t, []string{
+			"aws_ami.ubuntu",
+			"aws_subnet.private[a]",
+			"aws_subnet.private[b]",
+			"module.app[0].aws_instance.web",
+		}, nodeIDs(graph))
+		assert.Equal(t, BuildGraph(addressingState()), graph)
+	})
+
+	t.Run("terraform IDs match instance addresses", func(t *testing.T) {
+		graph := BuildGraphWithOptions(addressingState(), GraphOptions{IDFormat: IDFormatTerraform})
+
+		assert.Equal(t, []string{
+			"data.aws_ami.ubuntu",
+			`aws_subnet.private["a"]`,
+			`aws_subnet.private["b"]`,
+			"module.app[0].aws_instance.web[0]",
+		}, nodeIDs(graph))
+	})
+
+	t.Run("terraform IDs resolve data source dependencies", func(t *testing.T) {
+		graph := BuildGraphWithOptions(addressingState(), GraphOptions{IDFormat: IDFormatTerraform})
+
+		require.Len(t, graph.Edges, 1)
+		assert.Equal(t, "module.app[0].aws_instance.web[0]", graph.Edges[0].Source)
+		assert.Equal(t, "data.aws_ami.ubuntu", graph.Edges[0].Target)
+		assert.Contains(t, nodeIDs(graph), graph.Edges[0].Target)
+	})
+
+	t.Run("data source and managed resource of the same name stay distinct", func(t *testing.T) {
+		state := &models.TerraformState{
+			Resources: []models.ResourceState{
+				{Mode: "managed", Type: "aws_vpc", Name: "main", Instances: []models.ResourceInstance{{}}},
+				{Mode: "data", Type: "aws_vpc", Name: "main", Instances: []models.ResourceInstance{{}}},
+			},
+		}
+
+		assert.Len(t, BuildGraph(state).Nodes, 1)
+		assert.Equal(t, []string{"aws_vpc.main", "data.aws_vpc.main"},
+			nodeIDs(BuildGraphWithOptions(state, GraphOptions{IDFormat: IDFormatTerraform})))
+	})
+
+	t.Run("check results attach to terraform IDs", func(t *testing.T) {
+		state := addressingState()
+		state.CheckResults = []models.CheckResult{
+			{
+				ObjectKind: "resource",
+				ConfigAddr: "aws_subnet.private",
+				Status:     "fail",
+				Objects: []models.CheckObject{
+					{ObjectAddr: `aws_subnet.private["b"]`, Status: "fail", FailureMessages: []string{"bad cidr"}},
+				},
+			},
+		}
+
+		graph := BuildGraphWithOptions(state, GraphOptions{IDFormat: IDFormatTerraform})
+
+		require.Len(t, graph.Nodes[2].Warnings, 1)
+		assert.Equal(t, "bad cidr", graph.Nodes[2].Warnings[0].Message)
+		assert.Empty(t, graph.Warnings)
+	})
+}
+
+func TestViewsWithOptions(t *testing.T) {
+	state := &models.TerraformState{
+		Resources: []models.ResourceState{
+			{
+				Mode: "managed",
+				Type: "aws_vpc",
+				Name: "main",
+				Instances: []models.ResourceInstance{
+					{IndexKey: float64(0), Attributes: map[string]any{"id": "vpc-1", "cidr_block": "10.0.0.0/16"}},
+				},
+			},
+			{
+				Mode: "managed",
+				Type: "aws_security_group",
+				Name: "web",
+				Instances: []models.ResourceInstance{
+					{IndexKey: "blue", Attributes: map[string]any{"id": "sg-1", "vpc_id": "vpc-1"}},
+				},
+			},
+		},
+	}
+	opts := GraphOptions{IDFormat: IDFormatTerraform}
+
+	t.Run("network graph", func(t *testing.T) {
+		assert.Equal(t, []string{"aws_vpc.main[0]"}, nodeIDs(BuildNetworkGraphWithOptions(state, opts)))
+	})
+
+	t.Run("security group graph", func(t *testing.T) {
+		assert.Contains(t, nodeIDs(BuildSecurityGroupGraphWithOptions(state, opts)), `aws_security_group.web["blue"]`)
+	})
+}
+
+func TestFoldInstances(t *testing.T) {
+	opts := GraphOptions{FoldInstances: true}
+
+	t.Run("one node per resource", func(t *testing.T) {
+		graph := BuildGraphWithOptions(addressingState(), opts)
+
+		assert.Equal(t, []string{
+			"aws_ami.ubuntu",
+			"aws_subnet.private",
+			"module.app[0].aws_instance.web",
+		}, nodeIDs(graph))
+	})
+
+	t.Run("instances are listed in metadata", func(t *testing.T) {
+		graph := BuildGraphWithOptions(addressingState(), opts)
+
+		assert.Equal(t, map[string]any{
+			"mode": "managed",
+			"instances": []map[string]any{
+				{"id": "subnet-a", "index_key": "a"},
+				{"id": "subnet-b", "index_key": "b"},
+			},
+		}, graph.Nodes[1].Metadata)
+	})
+
+	t.Run("terraform IDs use resource addresses", func(t *testing.T) {
+		graph := BuildGraphWithOptions(addressingState(), GraphOptions{IDFormat: IDFormatTerraform, FoldInstances: true})
+
+		assert.Equal(t, []string{
+			"data.aws_ami.ubuntu",
+			"aws_subnet.private",
+			"module.app[0].aws_instance.web",
+		}, nodeIDs(graph))
+		assert.Equal(t, models.NodeAddress{Terraform: "aws_subnet.private", StatePath: ".resources[1]"}, graph.Addresses["aws_subnet.private"])
+	})
+
+	t.Run("dependencies of all instances are merged", func(t *testing.T) {
+		state := instanceEdgesState()
+		state.Resources[1].DependsOn = []string{"aws_subnet.private"}
+		state.Resources[1].Instances[1].Dependencies = []string{"aws_subnet.private", "aws_iam_role.web"}
+
+		graph := BuildGraphWithOptions(state, GraphOptions{FoldInstances: true, InstanceEdges: true})
+		edges := edgesFrom(graph, "aws_instance.web")
+
+		require.Len(t, edges, 2)
+		assert.Equal(t, models.Edge{Source: "aws_instance.web", Target: "aws_iam_role.web", Type: "implicit"}, edges[0])
+		assert.Equal(t, models.Edge{Source: "aws_instance.web", Target: "aws_subnet.private", Type: "depends_on"}, edges[1])
+	})
+
+	t.Run("check results attach to the folded node", func(t *testing.T) {
+		state := addressingState()
+		state.CheckResults = []models.CheckResult{
+			{
+				ObjectKind: "resource",
+				ConfigAddr: "aws_subnet.private",
+				Status:     "fail",
+				Objects: []models.CheckObject{
+					{ObjectAddr: `aws_subnet.private["b"]`, Status: "fail", FailureMessages: []string{"bad cidr"}},
+				},
+			},
+		}
+
+		graph := BuildGraphWithOptions(state, opts)
+
+		require.Len(t, graph.Nodes[1].Warnings, 1)
+		assert.Equal(t, "bad cidr", graph.Nodes[1].Warnings[0].Message)
+	})
+
+	t.Run("resources without instances have no node", func(t *testing.T) {
+		state := &models.TerraformState{
+			Resources: []models.ResourceState{{Mode: "managed", Type: "aws_vpc", Name: "main"}},
+		}
+
+		assert.Empty(t, BuildGraphWithOptions(state, opts).Nodes)
+	})
+
+	t.Run("network graph", func(t *testing.T) {
+		graph := BuildNetworkGraphWithOptions(addressingState(), opts)
+
+		require.Len(t, graph.Nodes, 2)
+		assert.Equal(t, "aws_subnet.private", graph.Nodes[0].ID)
+		assert.Equal(t, "subnet", graph.Nodes[0].Metadata["layer"])
+		assert.Len(t, graph.Nodes[0].Metadata["instances"], 2)
+	})
+}
@@ -0,0 +1,151 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"encoding/json"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// DiffGraphs returns the patch that turns the before graph into the after
+// graph. Nodes and edges are compared by their JSON encoding, so a graph
+// read back from JSON compares equal to the graph it was written from.
+func DiffGraphs(before, after *models.Graph) *models.GraphPatch {
+	patch := &models.GraphPatch{Warnings: after.Warnings}
+
+	previous := make(map[string]string, len(before.Nodes))
+	for _, node := range before.Nodes {
+		previous[node.ID] = encoded(node)
+	}
+
+	current := make(map[string]bool, len(after.Nodes))
+	for _, node := range after.Nodes {
+		current[node.ID] = true
+
+		was, existed := previous[node.ID]
+		address, hasAddress := after.Addresses[node.ID]
+		oldAddress, hadAddress := before.Addresses[node.ID]
+
+		// A node that lost its address is replaced, as patches only set
+		// addresses.
+		replaced := !existed || was != encoded(node) || hadAddress && !hasAddress
+		if replaced {
+			patch.Nodes = append(patch.Nodes, node)
+		}
+
+		if hasAddress && (replaced || !hadAddress || address != oldAddress) {
+			if patch.Addresses == nil {
+				patch.Addresses = make(map[string]models.NodeAddress)
+			}
+			patch.Addresses[node.ID] = address
+		}
+	}
+
+	for _, node := range before.Nodes {
+		if !current[node.ID] {
+			patch.RemovedNodes = append(patch.RemovedNodes, node.ID)
+		}
+	}
+
+	previousEdges := make(map[string]bool, len(before.Edges))
+	for _, edge := range before.Edges {
+		previousEdges[encoded(edge)] = true
+	}
+
+	currentEdges := make(map[string]bool, len(after.Edges))
+	for _, edge := range after.Edges {
+		key := encoded(edge)
+		currentEdges[key] = true
+
+		if !previousEdges[key] {
+			patch.Edges = append(patch.Edges, edge)
+		}
+	}
+
+	for _, edge := range before.Edges {
+		if !currentEdges[encoded(edge)] {
+			patch.RemovedEdges = append(patch.RemovedEdges, edge)
+		}
+	}
+
+	return patch
+}
+
+// ApplyPatch returns the graph a patch turns the graph into, leaving the
+// graph unchanged. Replaced nodes keep their position and added nodes come
+// last, so the result may order its nodes unlike a graph built from the
+// state, but it has the same Digest.
+func ApplyPatch(graph *models.Graph, patch *models.GraphPatch) *models.Graph {
+	result := &models.Graph{
+		Nodes:    make([]models.Node, 0, len(graph.Nodes)+len(patch.Nodes)),
+		Edges:    make([]models.Edge, 0, len(graph.Edges)+len(patch.Edges)),
+		Warnings: patch.Warnings,
+	}
+
+	removed := make(map[string]bool, len(patch.RemovedNodes))
+	for _, id := range patch.RemovedNodes {
+		removed[id] = true
+	}
+
+	replacements := make(map[string]models.Node, len(patch.Nodes))
+	for _, node := range patch.Nodes {
+		replacements[node.ID] = node
+	}
+
+	for _, node := range graph.Nodes {
+		if replacement, ok := replacements[node.ID]; ok {
+			result.Nodes = append(result.Nodes, replacement)
+			delete(replacements, node.ID)
+		} else if !removed[node.ID] {
+			result.Nodes = append(result.Nodes, node)
+		}
+	}
+
+	for _, node := range patch.Nodes {
+		if _, ok := replacements[node.ID]; ok {
+			result.Nodes = append(result.Nodes, node)
+		}
+	}
+
+	if len(graph.Addresses) > 0 || len(patch.Addresses) > 0 {
+		result.Addresses = make(map[string]models.NodeAddress, len(graph.Addresses)+len(patch.Addresses))
+		for id, address := range graph.Addresses {
+			result.Addresses[id] = address
+		}
+		for _, id := range patch.RemovedNodes {
+			delete(result.Addresses, id)
+		}
+		for _, node := range patch.Nodes {
+			delete(result.Addresses, node.ID)
+		}
+		for id, address := range patch.Addresses {
+			result.Addresses[id] = address
+		}
+	}
+
+	removedEdges := make(map[string]bool, len(patch.RemovedEdges))
+	for _, edge := range patch.RemovedEdges {
+		removedEdges[encoded(edge)] = true
+	}
+
+	for _, edge := range graph.Edges {
+		if !removedEdges[encoded(edge)] {
+			result.Edges = append(result.Edges, edge)
+		}
+	}
+	result.Edges = append(result.Edges, patch.Edges...)
+
+	return result
+}
+
+// encoded returns the JSON encoding of a node or edge, whose maps encode
+// with sorted keys.
+func encoded(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+
+	return string(data)
+}
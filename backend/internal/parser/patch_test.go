@@ -0,0 +1,71 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestDiffGraphs(t *testing.T) {
+	build := func(data []byte) *models.Graph {
+		state, err := ParseTfstate(data)
+		require.NoError(t, err)
+
+		return BuildGraph(state)
+	}
+
+	before := build(deltaState("prod", 1, "logs-1"))
+	after := build(deltaState("prod", 2, "logs-2"))
+
+	t.Run("keeps what changed", func(t *testing.T) {
+		patch := DiffGraphs(before, after)
+
+		require.Len(t, patch.Nodes, 1)
+		assert.Equal(t, "logs-2", patch.Nodes[0].Metadata["id"])
+		assert.Empty(t, patch.RemovedNodes)
+		assert.Empty(t, patch.Edges)
+		assert.Empty(t, patch.RemovedEdges)
+
+		assert.Empty(t, DiffGraphs(after, after).Nodes)
+	})
+
+	t.Run("applies to the graph it was made from", func(t *testing.T) {
+		patched := ApplyPatch(before, DiffGraphs(before, after))
+
+		assert.Equal(t, Digest(after), Digest(patched))
+		assert.Equal(t, after.Addresses, patched.Addresses)
+		assert.Equal(t, "logs-1", before.Nodes[2].Metadata["id"], "the graph is left unchanged")
+	})
+
+	t.Run("removes nodes and edges", func(t *testing.T) {
+		empty := &models.Graph{Nodes: []models.Node{}, Edges: []models.Edge{}}
+
+		patch := DiffGraphs(before, empty)
+		assert.Len(t, patch.RemovedNodes, 3)
+		assert.Len(t, patch.RemovedEdges, len(before.Edges))
+
+		patched := ApplyPatch(before, patch)
+		assert.Empty(t, patched.Nodes)
+		assert.Empty(t, patched.Edges)
+		assert.Empty(t, patched.Addresses)
+
+		assert.Equal(t, Digest(before), Digest(ApplyPatch(empty, DiffGraphs(empty, before))))
+	})
+
+	t.Run("compares graphs read back from JSON", func(t *testing.T) {
+		data, err := json.Marshal(before)
+		require.NoError(t, err)
+
+		var decoded models.Graph
+		require.NoError(t, json.Unmarshal(data, &decoded))
+
+		patch := DiffGraphs(&decoded, before)
+		assert.Empty(t, patch.Nodes)
+		assert.Empty(t, patch.Edges)
+	})
+}
@@ -0,0 +1,289 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/terrascope/core/internal/codes"
+	"github.com/terrascope/core/internal/models"
+)
+
+const warningTypePlanUnmatched = "plan_unmatched"
+
+// actionSeverity orders pending actions so that merging the changes of
+// folded instances keeps the most disruptive one.
+var actionSeverity = map[string]int{
+	models.ActionNoOp:    0,
+	models.ActionRead:    1,
+	models.ActionUpdate:  2,
+	models.ActionCreate:  3,
+	models.ActionDelete:  4,
+	models.ActionReplace: 5,
+}
+
+// ParsePlan parses the JSON representation of a plan, as printed by
+// `terraform show -json`.
+func ParsePlan(data []byte) (*models.Plan, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty plan data")
+	}
+
+	var plan models.Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal plan: %w", err)
+	}
+
+	if plan.FormatVersion == "" {
+		return nil, fmt.Errorf("invalid plan: missing format_version field")
+	}
+
+	return &plan, nil
+}
+
+// OverlayPlan annotates the nodes of a graph built with opts from the state
+// the plan was made against with their pending change. Resources the plan
+// creates get a new node without edges, since the state holds no dependencies
+// for them yet. Other changes that match no node are reported as warnings.
+func OverlayPlan(graph *models.Graph, plan *models.Plan, opts GraphOptions) {
+	nodes := make(map[string]int, len(graph.Nodes))
+	for i, node := range graph.Nodes {
+		address, ok := graph.Addresses[node.ID]
+		if !ok {
+			continue
+		}
+
+		deposed, _ := node.Metadata["deposed"].(string)
+		nodes[address.Terraform+deposedSuffix(models.ResourceInstance{Deposed: deposed})] = i
+	}
+
+	for _, change := range plan.ResourceChanges {
+		res, instance := plannedInstance(change)
+
+		address := change.Address
+		if opts.FoldInstances {
+			address = ResourceAddress(res)
+		}
+		address += deposedSuffix(instance)
+
+		nodeChange := summarizeChange(change)
+
+		if i, ok := nodes[address]; ok {
+			graph.Nodes[i].Change = mergeChanges(graph.Nodes[i].Change, nodeChange)
+			continue
+		}
+
+		if nodeChange.Action != models.ActionCreate && nodeChange.Action != models.ActionRead {
+			graph.Warnings = append(graph.Warnings, models.Warning{
+				Code:    codes.ForWarning(warningTypePlanUnmatched),
+				Type:    warningTypePlanUnmatched,
+				Address: change.Address,
+				Message: fmt.Sprintf("%s has a pending %s but is not in the state", change.Address, nodeChange.Action),
+			})
+			continue
+		}
+
+		nodes[address] = len(graph.Nodes)
+		graph.Nodes = append(graph.Nodes, models.Node{
+			ID:              plannedNodeID(res, instance, opts),
+			Type:            change.Type,
+			Mode:            change.Mode,
+			Provider:        extractProviderName(change.ProviderName),
+			ProviderAddress: ProviderAddress(change.ProviderName),
+			Module:          change.ModuleAddress,
+			Change:          nodeChange,
+			Metadata:        map[string]any{"mode": change.Mode},
+		})
+	}
+}
+
+// plannedInstance describes the resource instance a change applies to in the
+// terms of the state.
+func plannedInstance(change models.PlanResourceChange) (models.ResourceState, models.ResourceInstance) {
+	res := models.ResourceState{
+		Mode:   change.Mode,
+		Type:   change.Type,
+		Name:   change.Name,
+		Module: change.ModuleAddress,
+	}
+
+	return res, models.ResourceInstance{IndexKey: change.Index, Deposed: change.Deposed}
+}
+
+// plannedNodeID addresses the node of a resource the plan creates the way
+// graph building would.
+func plannedNodeID(res models.ResourceState, instance models.ResourceInstance, opts GraphOptions) string {
+	if opts.IDFormat == IDFormatTerraform || opts.FoldInstances {
+		return opts.nodeID(res, instance, 0)
+	}
+
+	id := legacyResourceID(res)
+	if key := indexKeyValue(instance.IndexKey); key != nil {
+		id = fmt.Sprintf("%s[%v]", id, key)
+	}
+
+	return id
+}
+
+// summarizeChange reduces the change actions to a single pending action and
+// lists the attribute paths the change touches.
+func summarizeChange(change models.PlanResourceChange) *models.NodeChange {
+	actions := change.Change.Actions
+
+	nodeChange := &models.NodeChange{
+		Actions: actions,
+		Reason:  change.ActionReason,
+	}
+
+	switch {
+	case slices.Equal(actions, []string{models.ActionDelete, models.ActionCreate}):
+		nodeChange.Action = models.ActionReplace
+	case slices.Equal(actions, []string{models.ActionCreate, models.ActionDelete}):
+		nodeChange.Action = models.ActionReplace
+		nodeChange.CreateBeforeDestroy = true
+	case len(actions) == 1:
+		nodeChange.Action = actions[0]
+	default:
+		nodeChange.Action = strings.Join(actions, ",")
+	}
+
+	if nodeChange.Action == models.ActionUpdate || nodeChange.Action == models.ActionReplace {
+		nodeChange.ChangedPaths = changedPaths(change.Change.Before, change.Change.After, change.Change.AfterUnknown)
+	}
+
+	for _, path := range change.Change.ReplacePaths {
+		nodeChange.ReplacePaths = append(nodeChange.ReplacePaths, planPath(path))
+	}
+
+	return nodeChange
+}
+
+// mergeChanges combines the changes of instances folded into one node,
+// keeping the most disruptive action and every touched path.
+func mergeChanges(current, next *models.NodeChange) *models.NodeChange {
+	if current == nil {
+		return next
+	}
+
+	merged := *current
+	if actionSeverity[next.Action] > actionSeverity[current.Action] {
+		merged.Action, merged.Actions, merged.Reason = next.Action, next.Actions, next.Reason
+	}
+
+	merged.CreateBeforeDestroy = current.CreateBeforeDestroy || next.CreateBeforeDestroy
+	merged.ChangedPaths = uniqueStrings(append(slices.Clone(current.ChangedPaths), next.ChangedPaths...))
+	merged.ReplacePaths = uniqueStrings(append(slices.Clone(current.ReplacePaths), next.ReplacePaths...))
+
+	return &merged
+}
+
+// changedPaths lists the paths of the values that differ between before and
+// after, descending into objects and equally long lists, plus the paths whose
+// values are only known after apply.
+func changedPaths(before, after, unknown any) []string {
+	paths := make(map[string]bool)
+
+	diffValues("", before, after, paths)
+	unknownPaths("", unknown, paths)
+
+	changed := make([]string, 0, len(paths))
+	for path := range paths {
+		changed = append(changed, path)
+	}
+	sort.Strings(changed)
+
+	return changed
+}
+
+func diffValues(path string, before, after any, paths map[string]bool) {
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+
+	beforeMap, beforeIsMap := before.(map[string]any)
+	afterMap, afterIsMap := after.(map[string]any)
+	if beforeIsMap && afterIsMap {
+		for key, value := range beforeMap {
+			diffValues(joinPath(path, key), value, afterMap[key], paths)
+		}
+		for key, value := range afterMap {
+			if _, ok := beforeMap[key]; !ok {
+				diffValues(joinPath(path, key), nil, value, paths)
+			}
+		}
+		return
+	}
+
+	beforeList, beforeIsList := before.([]any)
+	afterList, afterIsList := after.([]any)
+	if beforeIsList && afterIsList && len(beforeList) == len(afterList) && path != "" {
+		for i := range beforeList {
+			diffValues(fmt.Sprintf("%s[%d]", path, i), beforeList[i], afterList[i], paths)
+		}
+		return
+	}
+
+	if path != "" {
+		paths[path] = true
+	}
+}
+
+func unknownPaths(path string, unknown any, paths map[string]bool) {
+	switch value := unknown.(type) {
+	case bool:
+		if value && path != "" {
+			paths[path] = true
+		}
+	case map[string]any:
+		for key, nested := range value {
+			unknownPaths(joinPath(path, key), nested, paths)
+		}
+	case []any:
+		for i, nested := range value {
+			unknownPaths(fmt.Sprintf("%s[%d]", path, i), nested, paths)
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// planPath renders a plan attribute path, a list of attribute names and list
+// indices, in Terraform's syntax.
+func planPath(steps []any) string {
+	var b strings.Builder
+
+	for _, step := range steps {
+		switch value := step.(type) {
+		case string:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(value)
+		default:
+			fmt.Fprintf(&b, "[%v]", value)
+		}
+	}
+
+	return b.String()
+}
+
+func uniqueStrings(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	sort.Strings(values)
+
+	return slices.Compact(values)
+}
@@ -0,0 +1,175 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+const testPlan = `{
+	"format_version": "1.2",
+	"terraform_version": "1.9.0",
+	"resource_changes": [
+		{
+			"address": "aws_vpc.main",
+			"mode": "managed", "type": "aws_vpc", "name": "main",
+			"provider_name": "registry.terraform.io/hashicorp/aws",
+			"change": {"actions": ["no-op"], "before": {"id": "vpc-1"}, "after": {"id": "vpc-1"}}
+		},
+		{
+			"address": "aws_subnet.private[\"a\"]",
+			"mode": "managed", "type": "aws_subnet", "name": "private", "index": "a",
+			"provider_name": "registry.terraform.io/hashicorp/aws",
+			"change": {
+				"actions": ["update"],
+				"before": {"id": "subnet-a", "tags": {"env": "dev", "team": "core"}, "cidrs": ["10.0.0.0/24"]},
+				"after": {"id": "subnet-a", "tags": {"env": "prod", "team": "core"}, "cidrs": ["10.0.1.0/24"]},
+				"after_unknown": {"arn": true}
+			}
+		},
+		{
+			"address": "aws_subnet.private[\"b\"]",
+			"mode": "managed", "type": "aws_subnet", "name": "private", "index": "b",
+			"provider_name": "registry.terraform.io/hashicorp/aws",
+			"action_reason": "replace_because_cannot_update",
+			"change": {
+				"actions": ["create", "delete"],
+				"before": {"id": "subnet-b", "availability_zone": "eu-west-1a"},
+				"after": {"availability_zone": "eu-west-1b"},
+				"after_unknown": {"id": true},
+				"replace_paths": [["availability_zone"]]
+			}
+		},
+		{
+			"address": "module.app.aws_instance.web[0]",
+			"module_address": "module.app",
+			"mode": "managed", "type": "aws_instance", "name": "web", "index": 0,
+			"provider_name": "registry.terraform.io/hashicorp/aws",
+			"change": {"actions": ["create"], "before": null, "after": {"ami": "ami-1"}}
+		},
+		{
+			"address": "aws_s3_bucket.gone",
+			"mode": "managed", "type": "aws_s3_bucket", "name": "gone",
+			"provider_name": "registry.terraform.io/hashicorp/aws",
+			"change": {"actions": ["delete"], "before": {"id": "gone"}, "after": null}
+		}
+	]
+}`
+
+func planState() *models.TerraformState {
+	provider := `provider["registry.terraform.io/hashicorp/aws"]`
+
+	return &models.TerraformState{
+		Resources: []models.ResourceState{
+			{Mode: "managed", Type: "aws_vpc", Name: "main", Provider: provider, Instances: []models.ResourceInstance{
+				{Attributes: map[string]any{"id": "vpc-1"}},
+			}},
+			{Mode: "managed", Type: "aws_subnet", Name: "private", Provider: provider, Instances: []models.ResourceInstance{
+				{IndexKey: "a", Attributes: map[string]any{"id": "subnet-a"}, Dependencies: []string{"aws_vpc.main"}},
+				{IndexKey: "b", Attributes: map[string]any{"id": "subnet-b"}, Dependencies: []string{"aws_vpc.main"}},
+			}},
+		},
+	}
+}
+
+func nodeByID(graph *models.Graph, id string) *models.Node {
+	for i := range graph.Nodes {
+		if graph.Nodes[i].ID == id {
+			return &graph.Nodes[i]
+		}
+	}
+
+	return nil
+}
+
+func TestParsePlan(t *testing.T) {
+	t.Run("parses resource changes", func(t *testing.T) {
+		plan, err := ParsePlan([]byte(testPlan))
+		require.NoError(t, err)
+
+		assert.Equal(t, "1.2", plan.FormatVersion)
+		require.Len(t, plan.ResourceChanges, 5)
+		assert.Equal(t, []string{"create", "delete"}, plan.ResourceChanges[2].Change.Actions)
+	})
+
+	t.Run("rejects invalid plans", func(t *testing.T) {
+		for _, data := range []string{"", "not json", `{"resource_changes": []}`} {
+			_, err := ParsePlan([]byte(data))
+			assert.Error(t, err, data)
+		}
+	})
+}
+
+func TestOverlayPlan(t *testing.T) {
+	plan, err := ParsePlan([]byte(testPlan))
+	require.NoError(t, err)
+
+	t.Run("annotates nodes with their pending change", func(t *testing.T) {
+		graph := BuildGraph(planState())
+		OverlayPlan(graph, plan, GraphOptions{})
+
+		vpc := nodeByID(graph, "aws_vpc.main")
+		require.NotNil(t, vpc.Change)
+		assert.Equal(t, models.ActionNoOp, vpc.Change.Action)
+		assert.Empty(t, vpc.Change.ChangedPaths)
+
+		updated := nodeByID(graph, "aws_subnet.private[a]")
+		require.NotNil(t, updated.Change)
+		assert.Equal(t, models.ActionUpdate, updated.Change.Action)
+		assert.Equal(t, []string{"arn", "cidrs[0]", "tags.env"}, updated.Change.ChangedPaths)
+
+		replaced := nodeByID(graph, "aws_subnet.private[b]")
+		require.NotNil(t, replaced.Change)
+		assert.Equal(t, models.ActionReplace, replaced.Change.Action)
+		assert.True(t, replaced.Change.CreateBeforeDestroy)
+		assert.Equal(t, []string{"availability_zone"}, replaced.Change.ReplacePaths)
+		assert.Equal(t, "replace_because_cannot_update", replaced.Change.Reason)
+		assert.Equal(t, []string{"availability_zone", "id"}, replaced.Change.ChangedPaths)
+	})
+
+	t.Run("adds nodes for created resources", func(t *testing.T) {
+		graph := BuildGraph(planState())
+		OverlayPlan(graph, plan, GraphOptions{})
+
+		created := nodeByID(graph, "module.app.aws_instance.web[0]")
+		require.NotNil(t, created)
+		assert.Equal(t, "aws", created.Provider)
+		assert.Equal(t, "module.app", created.Module)
+		assert.Equal(t, models.ActionCreate, created.Change.Action)
+	})
+
+	t.Run("warns about changes to resources missing from the state", func(t *testing.T) {
+		graph := BuildGraph(planState())
+		OverlayPlan(graph, plan, GraphOptions{})
+
+		require.Len(t, graph.Warnings, 1)
+		assert.Equal(t, "plan_unmatched", graph.Warnings[0].Type)
+		assert.Equal(t, "TS102", graph.Warnings[0].Code)
+		assert.Equal(t, "aws_s3_bucket.gone", graph.Warnings[0].Address)
+	})
+
+	t.Run("uses terraform addresses", func(t *testing.T) {
+		opts := GraphOptions{IDFormat: IDFormatTerraform}
+		graph := BuildGraphWithOptions(planState(), opts)
+		OverlayPlan(graph, plan, opts)
+
+		assert.NotNil(t, nodeByID(graph, `aws_subnet.private["a"]`).Change)
+		assert.NotNil(t, nodeByID(graph, "module.app.aws_instance.web[0]"))
+	})
+
+	t.Run("folded nodes keep the most disruptive change", func(t *testing.T) {
+		opts := GraphOptions{FoldInstances: true}
+		graph := BuildGraphWithOptions(planState(), opts)
+		OverlayPlan(graph, plan, opts)
+
+		subnet := nodeByID(graph, "aws_subnet.private")
+		require.NotNil(t, subnet.Change)
+		assert.Equal(t, models.ActionReplace, subnet.Change.Action)
+		assert.Equal(t, []string{"arn", "availability_zone", "cidrs[0]", "id", "tags.env"}, subnet.Change.ChangedPaths)
+		assert.NotNil(t, nodeByID(graph, "module.app.aws_instance.web"))
+	})
+}
@@ -0,0 +1,145 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// Present returns a copy of the graph in which every node carries its
+// presentation, computed from what the graph holds once every analysis of the
+// request has run on it:
+//
+//   - drifted: the plan overlay updates or replaces the node;
+//   - risky: the node is tainted or deposed, the plan deletes or replaces it,
+//     a check fails on it, or it is a public CIDR in a security group graph;
+//   - untagged: the node has a tags attribute, but no tags;
+//   - sensitive: Terraform marked some of the node's attributes sensitive.
+//
+// The graph itself is left untouched, since it may be shared with the graph
+// store.
+func Present(graph *models.Graph) *models.Graph {
+	presented := *graph
+	presented.Nodes = make([]models.Node, len(graph.Nodes))
+
+	for i, node := range graph.Nodes {
+		address := node.ID
+		if a, ok := graph.Addresses[node.ID]; ok {
+			address = a.Terraform
+		}
+
+		node.Presentation = &models.NodePresentation{
+			Label:          shortLabel(node, address),
+			SecondaryLabel: secondaryLabel(node.Metadata),
+			Badges:         badges(node),
+		}
+		presented.Nodes[i] = node
+	}
+
+	return &presented
+}
+
+// shortLabel strips the module path, the data. prefix and the type from the
+// node's address, e.g. module.app.aws_subnet.private["a"] becomes
+// private["a"]. Nodes addressed otherwise are labelled by their address.
+func shortLabel(node models.Node, address string) string {
+	label := address
+	if node.Module != "" {
+		label = strings.TrimPrefix(label, node.Module+".")
+	}
+	label = strings.TrimPrefix(label, "data.")
+
+	if rest, ok := strings.CutPrefix(label, node.Type+"."); ok && rest != "" {
+		return rest
+	}
+
+	return address
+}
+
+// secondaryLabel returns the tail of the node's ARN or, failing that, of its
+// ID, e.g. role/app for arn:aws:iam::123456789012:role/app. Folded nodes of a
+// single instance use that instance's; other folded nodes count instances.
+func secondaryLabel(metadata map[string]any) string {
+	if instances, ok := foldedInstances(metadata); ok {
+		if len(instances) == 1 {
+			return secondaryLabel(instances[0])
+		}
+		return fmt.Sprintf("%d instances", len(instances))
+	}
+
+	if arn, ok := metadata["arn"].(string); ok && arn != "" && arn != redactedValue {
+		return arn[strings.LastIndex(arn, ":")+1:]
+	}
+
+	if id, ok := metadata["id"].(string); ok && id != redactedValue {
+		return id[strings.LastIndex(id, "/")+1:]
+	}
+
+	return ""
+}
+
+// badges lists the badges of a node in the order of the Badge constants.
+func badges(node models.Node) []string {
+	found := []string{}
+
+	action := ""
+	if node.Change != nil {
+		action = node.Change.Action
+	}
+
+	if action == models.ActionUpdate || action == models.ActionReplace {
+		found = append(found, models.BadgeDrifted)
+	}
+
+	public, _ := node.Metadata["public"].(bool)
+	if node.Status != "" || action == models.ActionDelete || action == models.ActionReplace || len(node.Warnings) > 0 || public {
+		found = append(found, models.BadgeRisky)
+	}
+
+	instances := []map[string]any{node.Metadata}
+	if folded, ok := foldedInstances(node.Metadata); ok {
+		instances = folded
+	}
+
+	var untagged, sensitive bool
+	for _, metadata := range instances {
+		if tags, ok := metadata["tags"].(map[string]any); ok && len(tags) == 0 {
+			untagged = true
+		}
+		if _, ok := metadata["sensitive_attributes"]; ok {
+			sensitive = true
+		}
+	}
+
+	if untagged {
+		found = append(found, models.BadgeUntagged)
+	}
+
+	if sensitive {
+		found = append(found, models.BadgeSensitive)
+	}
+
+	return found
+}
+
+// foldedInstances returns the instance metadata of a folded node, also when
+// the graph was decoded from JSON, as archived graphs are.
+func foldedInstances(metadata map[string]any) ([]map[string]any, bool) {
+	switch instances := metadata["instances"].(type) {
+	case []map[string]any:
+		return instances, true
+	case []any:
+		maps := make([]map[string]any, 0, len(instances))
+		for _, instance := range instances {
+			if m, ok := instance.(map[string]any); ok {
+				maps = append(maps, m)
+			}
+		}
+		return maps, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,99 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestPresent(t *testing.T) {
+	state := &models.TerraformState{
+		Resources: []models.ResourceState{
+			{Mode: "managed", Type: "aws_iam_role", Name: "app", Module: "module.iam", Instances: []models.ResourceInstance{
+				{
+					Attributes: map[string]any{
+						"id":   "app",
+						"arn":  "arn:aws:iam::123456789012:role/app",
+						"tags": map[string]any{},
+					},
+					Status: "tainted",
+				},
+			}},
+			{Mode: "data", Type: "aws_subnet", Name: "private", Instances: []models.ResourceInstance{
+				{IndexKey: "a", Attributes: map[string]any{"id": "subnet-1", "tags": map[string]any{"Name": "a"}}},
+				{IndexKey: "b", Attributes: map[string]any{"id": "subnet-2", "password": "x"}, SensitiveAttributes: []models.AttributePath{
+					{{Type: "get_attr", Value: "password"}},
+				}},
+			}},
+			{Mode: "managed", Type: "azurerm_resource_group", Name: "main", Instances: []models.ResourceInstance{
+				{Attributes: map[string]any{"id": "/subscriptions/0000/resourceGroups/main"}},
+			}},
+		},
+	}
+
+	presentations := func(graph *models.Graph) map[string]*models.NodePresentation {
+		byID := map[string]*models.NodePresentation{}
+		for _, node := range graph.Nodes {
+			byID[node.ID] = node.Presentation
+		}
+		return byID
+	}
+
+	t.Run("labels and badges", func(t *testing.T) {
+		graph := BuildGraphWithOptions(state, GraphOptions{IDFormat: IDFormatTerraform})
+		presented := Present(graph)
+
+		assert.Equal(t, map[string]*models.NodePresentation{
+			"module.iam.aws_iam_role.app": {
+				Label: "app", SecondaryLabel: "role/app",
+				Badges: []string{models.BadgeRisky, models.BadgeUntagged},
+			},
+			`data.aws_subnet.private["a"]`: {Label: `private["a"]`, SecondaryLabel: "subnet-1", Badges: []string{}},
+			`data.aws_subnet.private["b"]`: {Label: `private["b"]`, SecondaryLabel: "subnet-2", Badges: []string{models.BadgeSensitive}},
+			"azurerm_resource_group.main":  {Label: "main", SecondaryLabel: "main", Badges: []string{}},
+		}, presentations(presented))
+
+		for _, node := range graph.Nodes {
+			assert.Nil(t, node.Presentation, "the graph is copied")
+		}
+	})
+
+	t.Run("legacy IDs", func(t *testing.T) {
+		byID := presentations(Present(BuildGraph(state)))
+
+		assert.Equal(t, "app", byID["module.iam.aws_iam_role.app"].Label)
+		assert.Equal(t, `private["a"]`, byID["aws_subnet.private[a]"].Label)
+	})
+
+	t.Run("folded instances", func(t *testing.T) {
+		byID := presentations(Present(BuildGraphWithOptions(state, GraphOptions{FoldInstances: true})))
+
+		assert.Equal(t, &models.NodePresentation{
+			Label: "private", SecondaryLabel: "2 instances", Badges: []string{models.BadgeSensitive},
+		}, byID["aws_subnet.private"])
+		assert.Equal(t, "role/app", byID["module.iam.aws_iam_role.app"].SecondaryLabel)
+	})
+
+	t.Run("plan changes and checks", func(t *testing.T) {
+		graph := &models.Graph{Nodes: []models.Node{
+			{ID: "aws_instance.web", Type: "aws_instance", Change: &models.NodeChange{Action: models.ActionUpdate}},
+			{ID: "aws_instance.db", Type: "aws_instance", Change: &models.NodeChange{Action: models.ActionReplace}},
+			{ID: "aws_instance.old", Type: "aws_instance", Change: &models.NodeChange{Action: models.ActionDelete}},
+			{ID: "aws_instance.api", Type: "aws_instance", Warnings: []models.Warning{{Type: "check"}}},
+			{ID: "0.0.0.0/0", Type: "cidr", Metadata: map[string]any{"public": true}},
+		}}
+
+		byID := presentations(Present(graph))
+
+		require.Len(t, byID, 5)
+		assert.Equal(t, []string{models.BadgeDrifted}, byID["aws_instance.web"].Badges)
+		assert.Equal(t, []string{models.BadgeDrifted, models.BadgeRisky}, byID["aws_instance.db"].Badges)
+		assert.Equal(t, []string{models.BadgeRisky}, byID["aws_instance.old"].Badges)
+		assert.Equal(t, []string{models.BadgeRisky}, byID["aws_instance.api"].Badges)
+		assert.Equal(t, &models.NodePresentation{Label: "0.0.0.0/0", Badges: []string{models.BadgeRisky}}, byID["0.0.0.0/0"])
+	})
+}
@@ -0,0 +1,87 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"strings"
+)
+
+const (
+	defaultProviderHost      = "registry.terraform.io"
+	defaultProviderNamespace = "hashicorp"
+)
+
+// ProviderSource returns the fully qualified source address of the provider
+// configuration recorded on a resource, e.g. provider["registry.terraform.io/hashicorp/aws"].west
+// becomes registry.terraform.io/hashicorp/aws. Legacy provider.aws strings
+// resolve to the hashicorp namespace like Terraform does.
+func ProviderSource(providerString string) string {
+	if start := strings.Index(providerString, `provider["`); start >= 0 {
+		inner := providerString[start+len(`provider["`):]
+		if end := strings.Index(inner, `"]`); end >= 0 {
+			return NormalizeProviderSource(inner[:end])
+		}
+	}
+
+	if start := strings.Index(providerString, "provider."); start >= 0 {
+		name, _, _ := strings.Cut(providerString[start+len("provider."):], ".")
+		return NormalizeProviderSource(name)
+	}
+
+	return NormalizeProviderSource(providerString)
+}
+
+// ProviderAddress returns the address of the provider configuration recorded
+// on a resource: its full source address, after the module path of a
+// configuration declared in a module and before its alias, e.g.
+// provider["registry.terraform.io/hashicorp/aws"].west becomes
+// registry.terraform.io/hashicorp/aws.west. Unlike ProviderSource, it tells
+// apart the configurations of a provider, and providers of the same type from
+// different namespaces. Provider types cannot contain dots, so the alias is
+// unambiguous.
+func ProviderAddress(providerString string) string {
+	module, source, alias := "", providerString, ""
+
+	if start := strings.Index(providerString, `provider["`); start >= 0 {
+		inner := providerString[start+len(`provider["`):]
+		if end := strings.Index(inner, `"]`); end >= 0 {
+			module = strings.TrimSuffix(providerString[:start], ".")
+			source = inner[:end]
+			alias = strings.TrimPrefix(inner[end+len(`"]`):], ".")
+		}
+	} else if start := strings.Index(providerString, "provider."); start >= 0 {
+		module = strings.TrimSuffix(providerString[:start], ".")
+		source, alias, _ = strings.Cut(providerString[start+len("provider."):], ".")
+	}
+
+	address := NormalizeProviderSource(source)
+	if address == "" {
+		return ""
+	}
+	if alias != "" {
+		address += "." + alias
+	}
+	if module != "" {
+		address = module + "." + address
+	}
+
+	return address
+}
+
+// NormalizeProviderSource expands a short provider source (aws or
+// hashicorp/aws) to its full registry address.
+func NormalizeProviderSource(source string) string {
+	source = strings.ToLower(strings.TrimSpace(source))
+	if source == "" {
+		return ""
+	}
+
+	switch strings.Count(source, "/") {
+	case 0:
+		return defaultProviderHost + "/" + defaultProviderNamespace + "/" + source
+	case 1:
+		return defaultProviderHost + "/" + source
+	default:
+		return source
+	}
+}
@@ -0,0 +1,65 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"full address", `provider["registry.terraform.io/hashicorp/aws"]`, "registry.terraform.io/hashicorp/aws"},
+		{"aliased provider", `provider["registry.terraform.io/hashicorp/aws"].west`, "registry.terraform.io/hashicorp/aws"},
+		{"provider in module", `module.vpc.provider["registry.terraform.io/hashicorp/aws"]`, "registry.terraform.io/hashicorp/aws"},
+		{"custom namespace", `provider["registry.terraform.io/mycorp/custom"]`, "registry.terraform.io/mycorp/custom"},
+		{"private registry", `provider["terraform.example.com/acme/custom"]`, "terraform.example.com/acme/custom"},
+		{"short format", `provider["aws"]`, "registry.terraform.io/hashicorp/aws"},
+		{"legacy format", "provider.aws", "registry.terraform.io/hashicorp/aws"},
+		{"legacy aliased in module", "module.vpc.provider.aws.west", "registry.terraform.io/hashicorp/aws"},
+		{"bare name", "aws", "registry.terraform.io/hashicorp/aws"},
+		{"empty string", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ProviderSource(tt.input))
+		})
+	}
+}
+
+func TestProviderAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"full address", `provider["registry.terraform.io/hashicorp/aws"]`, "registry.terraform.io/hashicorp/aws"},
+		{"aliased provider", `provider["registry.terraform.io/hashicorp/aws"].west`, "registry.terraform.io/hashicorp/aws.west"},
+		{"provider in module", `module.vpc.provider["registry.terraform.io/hashicorp/aws"]`, "module.vpc.registry.terraform.io/hashicorp/aws"},
+		{"custom namespace", `provider["registry.terraform.io/mycorp/custom"]`, "registry.terraform.io/mycorp/custom"},
+		{"other custom namespace", `provider["registry.terraform.io/othercorp/custom"]`, "registry.terraform.io/othercorp/custom"},
+		{"short format", `provider["aws"]`, "registry.terraform.io/hashicorp/aws"},
+		{"legacy format", "provider.aws", "registry.terraform.io/hashicorp/aws"},
+		{"legacy aliased in module", "module.vpc.provider.aws.west", "module.vpc.registry.terraform.io/hashicorp/aws.west"},
+		{"plan provider name", "registry.terraform.io/hashicorp/google", "registry.terraform.io/hashicorp/google"},
+		{"empty string", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ProviderAddress(tt.input))
+		})
+	}
+}
+
+func TestNormalizeProviderSource(t *testing.T) {
+	assert.Equal(t, "registry.terraform.io/hashicorp/aws", NormalizeProviderSource("hashicorp/aws"))
+	assert.Equal(t, "registry.terraform.io/integrations/github", NormalizeProviderSource("Integrations/GitHub"))
+	assert.Equal(t, "terraform.example.com/acme/custom", NormalizeProviderSource("terraform.example.com/acme/custom"))
+}
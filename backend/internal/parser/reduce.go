@@ -0,0 +1,79 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import "github.com/terrascope/core/internal/models"
+
+// ReduceByTransitive is the reduction applied by ReduceTransitive.
+const ReduceByTransitive = "transitive"
+
+// ReduceTransitive returns a copy of the graph without the edges implied by a
+// longer path between the same nodes, of at most maxDepth edges (any length
+// when maxDepth is 0), whatever their types. Edges are considered in order
+// and dropped one at a time, so every node still reaches the same nodes even
+// through cycles. The graph itself is left untouched.
+func ReduceTransitive(graph *models.Graph, maxDepth int) *models.Graph {
+	outgoing := make(map[string][]int)
+	for i, edge := range graph.Edges {
+		outgoing[edge.Source] = append(outgoing[edge.Source], i)
+	}
+
+	removed := make([]bool, len(graph.Edges))
+
+	// implied reports whether target is reachable from source through another
+	// node, without crossing source again or a removed edge.
+	implied := func(source, target string) bool {
+		visited := map[string]bool{source: true}
+		var frontier []string
+
+		for _, i := range outgoing[source] {
+			next := graph.Edges[i].Target
+			if !removed[i] && next != target && !visited[next] {
+				visited[next] = true
+				frontier = append(frontier, next)
+			}
+		}
+
+		for length := 1; len(frontier) > 0 && (maxDepth == 0 || length < maxDepth); length++ {
+			var next []string
+
+			for _, id := range frontier {
+				for _, i := range outgoing[id] {
+					if removed[i] {
+						continue
+					}
+
+					neighbor := graph.Edges[i].Target
+					if neighbor == target {
+						return true
+					}
+					if !visited[neighbor] {
+						visited[neighbor] = true
+						next = append(next, neighbor)
+					}
+				}
+			}
+
+			frontier = next
+		}
+
+		return false
+	}
+
+	reduced := *graph
+	reduced.Edges = []models.Edge{}
+	reduced.Stats = nil
+	reduced.Reduction = &models.Reduction{By: ReduceByTransitive, Depth: maxDepth}
+
+	for i, edge := range graph.Edges {
+		if edge.Source != edge.Target && implied(edge.Source, edge.Target) {
+			removed[i] = true
+			reduced.Reduction.RemovedEdges++
+			continue
+		}
+
+		reduced.Edges = append(reduced.Edges, edge)
+	}
+
+	return &reduced
+}
@@ -0,0 +1,59 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestReduceTransitive(t *testing.T) {
+	edge := func(source, target string) models.Edge {
+		return models.Edge{Source: source, Target: target, Type: "implicit"}
+	}
+
+	t.Run("drops edges implied by longer paths", func(t *testing.T) {
+		graph := &models.Graph{
+			Nodes: []models.Node{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}},
+			Edges: []models.Edge{edge("a", "b"), edge("b", "c"), edge("c", "d"), edge("a", "c"), edge("a", "d")},
+		}
+
+		reduced := ReduceTransitive(graph, 0)
+
+		assert.Equal(t, []models.Edge{edge("a", "b"), edge("b", "c"), edge("c", "d")}, reduced.Edges)
+		assert.Equal(t, &models.Reduction{By: ReduceByTransitive, RemovedEdges: 2}, reduced.Reduction)
+		assert.Len(t, graph.Edges, 5, "the graph is left untouched")
+		assert.Nil(t, graph.Reduction)
+	})
+
+	t.Run("only looks for paths up to the depth", func(t *testing.T) {
+		graph := &models.Graph{
+			Edges: []models.Edge{edge("a", "b"), edge("b", "c"), edge("c", "d"), edge("a", "c"), edge("a", "d")},
+		}
+
+		reduced := ReduceTransitive(graph, 2)
+
+		assert.Equal(t, []models.Edge{edge("a", "b"), edge("b", "c"), edge("c", "d"), edge("a", "d")}, reduced.Edges)
+		assert.Empty(t, ReduceTransitive(graph, 1).Reduction.RemovedEdges)
+	})
+
+	t.Run("keeps parallel edges of other types", func(t *testing.T) {
+		graph := &models.Graph{
+			Edges: []models.Edge{edge("a", "b"), {Source: "a", Target: "b", Type: "explicit"}},
+		}
+
+		assert.Len(t, ReduceTransitive(graph, 0).Edges, 2)
+	})
+
+	t.Run("keeps reachability through cycles", func(t *testing.T) {
+		graph := &models.Graph{
+			Edges: []models.Edge{edge("a", "b"), edge("a", "c"), edge("b", "c"), edge("c", "b"), edge("b", "b")},
+		}
+
+		reduced := ReduceTransitive(graph, 0)
+
+		assert.Equal(t, []models.Edge{edge("a", "c"), edge("b", "c"), edge("c", "b"), edge("b", "b")}, reduced.Edges)
+	})
+}
@@ -0,0 +1,195 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// edgeReference marks an edge inferred from an attribute that holds the ID or
+// ARN of another resource, rather than from a recorded dependency.
+const edgeReference = "reference"
+
+// addReferenceEdges infers an edge from every managed resource instance to
+// each resource whose ID or ARN it holds. With a schema for the resource,
+// only attributes typed as configurable strings are inspected: computed-only
+// attributes are the provider's own values, such as owner IDs. Without one,
+// only values with a known provider ID format are, when opts.InferReferences
+// is set. Sensitive attributes are never inspected, and references already
+// covered by a recorded dependency are skipped.
+func addReferenceEdges(graph *models.Graph, state *models.TerraformState, opts GraphOptions) {
+	refs := instances(state, opts)
+
+	owners := make(map[string]string)
+	addresses := make(map[string][]string)
+
+	for _, ref := range refs {
+		addresses[ref.NodeID] = []string{ref.NodeID, ResourceAddress(ref.Resource), ConfigAddress(ref.Resource)}
+
+		if ref.Instance.Deposed != "" {
+			continue
+		}
+
+		for _, key := range []string{"id", "arn"} {
+			if value := stringAttr(ref.Instance.Attributes, key); value != "" {
+				if _, exists := owners[value]; !exists {
+					owners[value] = ref.NodeID
+				}
+			}
+		}
+	}
+
+	linked := make(map[string]bool, len(graph.Edges))
+	for _, edge := range graph.Edges {
+		linked[edge.Source+" "+edge.Target] = true
+	}
+
+	for _, ref := range refs {
+		if ref.Resource.Mode != "managed" || ref.Instance.Deposed != "" {
+			continue
+		}
+
+		link := func(path, value string) {
+			target, ok := owners[value]
+			if !ok || target == ref.NodeID {
+				return
+			}
+
+			for _, address := range addresses[target] {
+				if linked[ref.NodeID+" "+address] {
+					return
+				}
+			}
+			linked[ref.NodeID+" "+target] = true
+
+			graph.Edges = append(graph.Edges, models.Edge{
+				Source:   ref.NodeID,
+				Target:   target,
+				Type:     edgeReference,
+				Metadata: map[string]any{"attribute": path},
+			})
+		}
+
+		if block := opts.schema(ref.Resource); block != nil {
+			walkReferences(block, ref.Instance.Attributes, "", link)
+		} else if opts.InferReferences {
+			walkProviderIDs(ref.Instance, link)
+		}
+	}
+}
+
+// walkProviderIDs calls fn, in attribute name order, for every string in the
+// instance's attributes that has a known provider ID format, with the name of
+// the top-level attribute holding it. The instance's own id and arn, and its
+// sensitive attributes, are skipped.
+func walkProviderIDs(instance models.ResourceInstance, fn func(path, value string)) {
+	skipped := map[string]bool{"id": true, "arn": true}
+	for _, path := range instance.SensitiveAttributes {
+		if len(path) > 0 && path[0].Type == "get_attr" {
+			if name, ok := path[0].Key().(string); ok {
+				skipped[name] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(instance.Attributes))
+	for name := range instance.Attributes {
+		if !skipped[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		walkStringValues(instance.Attributes[name], func(s string) {
+			if _, ok := IDResourceType(s); ok {
+				fn(name, s)
+			}
+		})
+	}
+}
+
+// walkReferences calls fn, in attribute name order, for every string held by
+// a configurable, non-sensitive string attribute of the block or of its
+// nested blocks, with the attribute's path.
+func walkReferences(block *models.SchemaBlock, attrs map[string]any, prefix string, fn func(path, value string)) {
+	names := make([]string, 0, len(block.Attributes)+len(block.BlockTypes))
+	for name := range block.Attributes {
+		names = append(names, name)
+	}
+	for name := range block.BlockTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value, ok := attrs[name]
+		if !ok {
+			continue
+		}
+
+		if attr, ok := block.Attributes[name]; ok {
+			if attr.Sensitive || !(attr.Required || attr.Optional) || !holdsStrings(attr.Type) {
+				continue
+			}
+
+			walkStringValues(value, func(s string) { fn(prefix+name, s) })
+			continue
+		}
+
+		nested := block.BlockTypes[name]
+		switch v := value.(type) {
+		case map[string]any:
+			walkReferences(&nested.Block, v, prefix+name+".", fn)
+		case []any:
+			for _, item := range v {
+				if obj, ok := item.(map[string]any); ok {
+					walkReferences(&nested.Block, obj, prefix+name+".", fn)
+				}
+			}
+		}
+	}
+}
+
+// holdsStrings reports whether a type constraint is a string or a collection
+// of strings, e.g. "string" or ["set", "string"].
+func holdsStrings(typ any) bool {
+	switch t := typ.(type) {
+	case string:
+		return t == "string"
+	case []any:
+		if len(t) != 2 {
+			return false
+		}
+		kind, _ := t[0].(string)
+		return (kind == "list" || kind == "set" || kind == "map") && holdsStrings(t[1])
+	default:
+		return false
+	}
+}
+
+func walkStringValues(value any, fn func(string)) {
+	switch v := value.(type) {
+	case string:
+		if v = strings.TrimSpace(v); v != "" {
+			fn(v)
+		}
+	case []any:
+		for _, item := range v {
+			walkStringValues(item, fn)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			walkStringValues(v[key], fn)
+		}
+	}
+}
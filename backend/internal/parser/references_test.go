@@ -0,0 +1,56 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestInferredReferenceEdges(t *testing.T) {
+	state := &models.TerraformState{
+		Resources: []models.ResourceState{
+			{Mode: "managed", Type: "aws_default_vpc", Name: "main", Instances: []models.ResourceInstance{
+				{Attributes: map[string]any{"id": "vpc-0a1b2c3d", "name": "main"}},
+			}},
+			{Mode: "data", Type: "aws_ami", Name: "ubuntu", Instances: []models.ResourceInstance{
+				{Attributes: map[string]any{"id": "ami-0123456789abcdef0"}},
+			}},
+			{Mode: "managed", Type: "aws_iam_role", Name: "app", Instances: []models.ResourceInstance{
+				{Attributes: map[string]any{"id": "app", "arn": "arn:aws:iam::123456789012:role/app"}},
+			}},
+			{Mode: "managed", Type: "aws_instance", Name: "web", Instances: []models.ResourceInstance{
+				{
+					Attributes: map[string]any{
+						"id":         "i-0123456789abcdef0",
+						"ami":        "ami-0123456789abcdef0",
+						"tags":       map[string]any{"Network": "vpc-0a1b2c3d", "Role": "app"},
+						"iam_role":   "arn:aws:iam::123456789012:role/app",
+						"user_data":  "vpc-0a1b2c3d",
+						"other_vpcs": []any{"vpc-99999999"},
+					},
+					SensitiveAttributes: []models.AttributePath{{{Type: "get_attr", Value: "user_data"}}},
+					Dependencies:        []string{"aws_iam_role.app"},
+				},
+			}},
+		},
+	}
+
+	t.Run("values with provider ID formats are references", func(t *testing.T) {
+		graph := BuildGraphWithOptions(state, GraphOptions{InferReferences: true})
+
+		assert.Equal(t, []models.Edge{
+			{Source: "aws_instance.web", Target: "aws_ami.ubuntu", Type: edgeReference, Metadata: map[string]any{"attribute": "ami"}},
+			{Source: "aws_instance.web", Target: "aws_default_vpc.main", Type: edgeReference, Metadata: map[string]any{"attribute": "tags"}},
+			{Source: "aws_instance.web", Target: "aws_iam_role.app", Type: "implicit"},
+		}, edgesFrom(graph, "aws_instance.web"))
+	})
+
+	t.Run("references are only inferred on request", func(t *testing.T) {
+		graph := BuildGraph(state)
+
+		assert.Len(t, edgesFrom(graph, "aws_instance.web"), 1)
+	})
+}
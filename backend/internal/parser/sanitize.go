@@ -0,0 +1,228 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// sanitizedValue replaces string values stripped by Sanitize.
+const sanitizedValue = "(redacted)"
+
+// Sanitize returns a copy of the state that is safe to share: attribute and
+// output values are replaced by placeholders of the same JSON type, private
+// data and check failure messages are dropped, and only identifiers (id and
+// *_id/*_ids attributes that are not marked sensitive) keep their values, so
+// that the structure and the graph built from it stay intact. It also returns
+// how many values were redacted.
+func Sanitize(state *models.TerraformState) (*models.TerraformState, int) {
+	s := &sanitizer{}
+
+	clean := &models.TerraformState{
+		Version:          state.Version,
+		TerraformVersion: state.TerraformVersion,
+		Serial:           state.Serial,
+		Lineage:          state.Lineage,
+		Resources:        make([]models.ResourceState, 0, len(state.Resources)),
+	}
+
+	if len(state.Outputs) > 0 {
+		clean.Outputs = make(map[string]models.Output, len(state.Outputs))
+		for name, output := range state.Outputs {
+			output.Value = s.value(output.Value, false, output.Sensitive)
+			clean.Outputs[name] = output
+		}
+	}
+
+	for _, res := range state.Resources {
+		instances := make([]models.ResourceInstance, 0, len(res.Instances))
+		for _, instance := range res.Instances {
+			instances = append(instances, s.instance(instance))
+		}
+
+		res.Instances = instances
+		clean.Resources = append(clean.Resources, res)
+	}
+
+	for _, result := range state.CheckResults {
+		objects := make([]models.CheckObject, 0, len(result.Objects))
+		for _, object := range result.Objects {
+			s.redacted += len(object.FailureMessages)
+			object.FailureMessages = nil
+			objects = append(objects, object)
+		}
+
+		if len(objects) > 0 {
+			result.Objects = objects
+		}
+		clean.CheckResults = append(clean.CheckResults, result)
+	}
+
+	return clean, s.redacted
+}
+
+// structuralMetadata lists the node metadata keys that describe the graph's
+// structure rather than the infrastructure, which SanitizeGraph keeps.
+var structuralMetadata = map[string]bool{
+	"mode":                 true,
+	"index_key":            true,
+	"status":               true,
+	"deposed":              true,
+	"lifecycle":            true,
+	"node_count":           true,
+	"sensitive_attributes": true,
+	"public":               true,
+}
+
+// SanitizeGraph returns a copy of the graph that is safe to share, redacting
+// node metadata the way Sanitize redacts attributes. Check failure messages
+// and the state lock, which names who holds it, are dropped. It also returns
+// how many values were redacted.
+func SanitizeGraph(graph *models.Graph) (*models.Graph, int) {
+	s := &sanitizer{}
+
+	clean := *graph
+	clean.Lock = nil
+	clean.Nodes = make([]models.Node, 0, len(graph.Nodes))
+	clean.Warnings = s.warnings(graph.Warnings)
+
+	for _, node := range graph.Nodes {
+		node.Metadata = s.metadata(node.Metadata)
+		node.Warnings = s.warnings(node.Warnings)
+		clean.Nodes = append(clean.Nodes, node)
+	}
+
+	return &clean, s.redacted
+}
+
+type sanitizer struct {
+	redacted int
+}
+
+func (s *sanitizer) instance(instance models.ResourceInstance) models.ResourceInstance {
+	sensitive := make(map[string]bool, len(instance.SensitiveAttributes))
+	for _, path := range instance.SensitiveAttributes {
+		if len(path) > 0 && path[0].Type == "get_attr" {
+			if name, ok := path[0].Key().(string); ok {
+				sensitive[name] = true
+			}
+		}
+	}
+
+	if instance.Attributes != nil {
+		attributes := make(map[string]any, len(instance.Attributes))
+		for name, value := range instance.Attributes {
+			attributes[name] = s.value(value, isIdentifier(name) && !sensitive[name], sensitive[name])
+		}
+		instance.Attributes = attributes
+	}
+
+	if instance.AttributesFlat != nil {
+		flat := make(map[string]string, len(instance.AttributesFlat))
+		for key, value := range instance.AttributesFlat {
+			name, _, _ := strings.Cut(key, ".")
+			if isIdentifier(name) && !sensitive[name] {
+				flat[key] = value
+				continue
+			}
+
+			s.redacted++
+			flat[key] = sanitizedValue
+		}
+		instance.AttributesFlat = flat
+	}
+
+	if instance.Private != "" {
+		s.redacted++
+		instance.Private = ""
+	}
+
+	return instance
+}
+
+func (s *sanitizer) metadata(metadata map[string]any) map[string]any {
+	if metadata == nil {
+		return nil
+	}
+
+	clean := make(map[string]any, len(metadata))
+	for key, value := range metadata {
+		switch {
+		case structuralMetadata[key]:
+			clean[key] = value
+		case key == "instances":
+			instances, _ := foldedInstances(metadata)
+			cleanInstances := make([]map[string]any, 0, len(instances))
+			for _, instance := range instances {
+				cleanInstances = append(cleanInstances, s.metadata(instance))
+			}
+			clean[key] = cleanInstances
+		default:
+			clean[key] = s.value(value, isIdentifier(key), false)
+		}
+	}
+
+	return clean
+}
+
+func (s *sanitizer) warnings(warnings []models.Warning) []models.Warning {
+	if warnings == nil {
+		return nil
+	}
+
+	clean := make([]models.Warning, 0, len(warnings))
+	for _, warning := range warnings {
+		if warning.Message != "" {
+			s.redacted++
+			warning.Message = ""
+		}
+		clean = append(clean, warning)
+	}
+
+	return clean
+}
+
+// value replaces the leaves of an attribute value by placeholders of the same
+// JSON type, keeping maps and lists so that the value keeps its shape. Unless
+// the value is sensitive, nested keys that name identifiers keep their values.
+func (s *sanitizer) value(value any, keep, sensitive bool) any {
+	switch v := value.(type) {
+	case map[string]any:
+		clean := make(map[string]any, len(v))
+		for key, item := range v {
+			clean[key] = s.value(item, keep || (!sensitive && isIdentifier(key)), sensitive)
+		}
+		return clean
+	case []any:
+		clean := make([]any, len(v))
+		for i, item := range v {
+			clean[i] = s.value(item, keep, sensitive)
+		}
+		return clean
+	case nil:
+		return nil
+	}
+
+	if keep {
+		return value
+	}
+
+	s.redacted++
+
+	switch value.(type) {
+	case bool:
+		return false
+	case float64:
+		return float64(0)
+	default:
+		return sanitizedValue
+	}
+}
+
+// isIdentifier reports whether an attribute holds the ID of the resource or
+// references other resources by ID, which the graph views rely on.
+func isIdentifier(name string) bool {
+	return name == "id" || strings.HasSuffix(name, "_id") || strings.HasSuffix(name, "_ids")
+}
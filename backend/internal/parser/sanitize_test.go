@@ -0,0 +1,157 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestSanitize(t *testing.T) {
+	state, err := ParseTfstate([]byte(`{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"serial": 3,
+		"lineage": "prod",
+		"outputs": {
+			"endpoint": {"value": "db.internal:5432", "type": "string"},
+			"password": {"value": {"user_id": "admin", "secret": "hunter2"}, "type": ["object", {}], "sensitive": true}
+		},
+		"resources": [{
+			"mode": "managed",
+			"type": "aws_db_instance",
+			"name": "main",
+			"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+			"instances": [{
+				"schema_version": 2,
+				"index_key": "primary",
+				"attributes": {
+					"id": "db-1",
+					"vpc_security_group_ids": ["sg-1"],
+					"password": "hunter2",
+					"port": 5432,
+					"multi_az": true,
+					"tags": {"Owner": "alice", "team_id": "t-1"},
+					"master_user_secret": [{"kms_key_id": "key-1"}],
+					"deleted": null
+				},
+				"sensitive_attributes": [[{"type": "get_attr", "value": "password"}], [{"type": "get_attr", "value": "master_user_secret"}]],
+				"private": "eyJzY2hlbWFfdmVyc2lvbiI6IjEifQ==",
+				"dependencies": ["aws_subnet.a"]
+			}]
+		}],
+		"check_results": [{
+			"object_kind": "resource",
+			"config_addr": "aws_db_instance.main",
+			"status": "fail",
+			"objects": [{"object_addr": "aws_db_instance.main", "status": "fail", "failure_messages": ["password is hunter2"]}]
+		}]
+	}`))
+	require.NoError(t, err)
+
+	clean, redacted := Sanitize(state)
+
+	assert.Equal(t, "prod", clean.Lineage)
+	assert.Equal(t, 3, clean.Serial)
+
+	instance := clean.Resources[0].Instances[0]
+	assert.Equal(t, map[string]any{
+		"id":                     "db-1",
+		"vpc_security_group_ids": []any{"sg-1"},
+		"password":               "(redacted)",
+		"port":                   float64(0),
+		"multi_az":               false,
+		"tags":                   map[string]any{"Owner": "(redacted)", "team_id": "t-1"},
+		"master_user_secret":     []any{map[string]any{"kms_key_id": "(redacted)"}},
+		"deleted":                nil,
+	}, instance.Attributes)
+	assert.Equal(t, "primary", instance.IndexKey)
+	assert.Equal(t, []string{"aws_subnet.a"}, instance.Dependencies)
+	assert.Len(t, instance.SensitiveAttributes, 2)
+	assert.Empty(t, instance.Private)
+
+	assert.Equal(t, "(redacted)", clean.Outputs["endpoint"].Value)
+	assert.Equal(t, map[string]any{"user_id": "(redacted)", "secret": "(redacted)"}, clean.Outputs["password"].Value)
+	assert.True(t, clean.Outputs["password"].Sensitive)
+
+	require.Len(t, clean.CheckResults, 1)
+	assert.Equal(t, "fail", clean.CheckResults[0].Objects[0].Status)
+	assert.Empty(t, clean.CheckResults[0].Objects[0].FailureMessages)
+
+	// password, port, multi_az, Owner, kms_key_id, two outputs' three leaves,
+	// private data and the failure message.
+	assert.Equal(t, 10, redacted)
+
+	assert.Equal(t, "hunter2", state.Resources[0].Instances[0].Attributes["password"], "the input must not be modified")
+}
+
+func TestSanitizeFlatAttributes(t *testing.T) {
+	state := &models.TerraformState{
+		Version: 3,
+		Resources: []models.ResourceState{{
+			Mode: "managed",
+			Type: "aws_instance",
+			Name: "web",
+			Instances: []models.ResourceInstance{{
+				AttributesFlat: map[string]string{"id": "i-1", "subnet_id": "subnet-1", "user_data": "#!/bin/sh"},
+			}},
+		}},
+	}
+
+	clean, redacted := Sanitize(state)
+
+	assert.Equal(t, map[string]string{"id": "i-1", "subnet_id": "subnet-1", "user_data": "(redacted)"},
+		clean.Resources[0].Instances[0].AttributesFlat)
+	assert.Equal(t, 1, redacted)
+}
+
+func TestSanitizeGraph(t *testing.T) {
+	graph := &models.Graph{
+		Nodes: []models.Node{
+			{
+				ID:   "aws_iam_role.app",
+				Type: "aws_iam_role",
+				Metadata: map[string]any{
+					"mode":   "managed",
+					"id":     "app",
+					"arn":    "arn:aws:iam::123456789012:role/app",
+					"tags":   map[string]any{"Owner": "alice", "team_id": "t-1"},
+					"status": "tainted",
+				},
+				Warnings: []models.Warning{{Type: "check", Address: "aws_iam_role.app", Message: "owner alice left"}},
+			},
+			{
+				ID:   "aws_subnet.private",
+				Type: "aws_subnet",
+				Metadata: map[string]any{
+					"mode":      "managed",
+					"instances": []map[string]any{{"id": "subnet-1", "index_key": "a", "name": "private-a"}},
+				},
+			},
+		},
+		Edges: []models.Edge{{Source: "aws_iam_role.app", Target: "aws_subnet.private", Type: "implicit"}},
+		Lock:  &models.LockInfo{Who: "alice@laptop"},
+	}
+
+	clean, redacted := SanitizeGraph(graph)
+
+	assert.Equal(t, 4, redacted)
+	assert.Nil(t, clean.Lock)
+	assert.Equal(t, graph.Edges, clean.Edges)
+
+	assert.Equal(t, map[string]any{
+		"mode":   "managed",
+		"id":     "app",
+		"arn":    sanitizedValue,
+		"tags":   map[string]any{"Owner": sanitizedValue, "team_id": "t-1"},
+		"status": "tainted",
+	}, clean.Nodes[0].Metadata)
+	assert.Equal(t, []models.Warning{{Type: "check", Address: "aws_iam_role.app"}}, clean.Nodes[0].Warnings)
+	assert.Equal(t, []map[string]any{{"id": "subnet-1", "index_key": "a", "name": sanitizedValue}}, clean.Nodes[1].Metadata["instances"])
+
+	assert.Equal(t, "arn:aws:iam::123456789012:role/app", graph.Nodes[0].Metadata["arn"], "the graph is copied")
+	assert.NotNil(t, graph.Lock)
+}
@@ -0,0 +1,132 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// ParseProviderSchemas parses the output of `terraform providers schema
+// -json`. Provider addresses are normalized like those of the state.
+func ParseProviderSchemas(data []byte) (*models.ProviderSchemas, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty provider schemas")
+	}
+
+	var schemas models.ProviderSchemas
+	if err := json.Unmarshal(data, &schemas); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal provider schemas: %w", err)
+	}
+
+	if schemas.FormatVersion == "" {
+		return nil, fmt.Errorf("invalid provider schemas: missing format_version field")
+	}
+
+	if schemas.ProviderSchemas == nil {
+		return nil, fmt.Errorf("invalid provider schemas: missing provider_schemas field")
+	}
+
+	normalized := make(map[string]models.ProviderSchema, len(schemas.ProviderSchemas))
+	for source, schema := range schemas.ProviderSchemas {
+		normalized[NormalizeProviderSource(source)] = schema
+	}
+	schemas.ProviderSchemas = normalized
+
+	return &schemas, nil
+}
+
+// SummarizeSchemas lists the providers of the schemas and counts the resource
+// types they define.
+func SummarizeSchemas(schemas *models.ProviderSchemas) models.SchemaSummary {
+	summary := models.SchemaSummary{
+		FormatVersion: schemas.FormatVersion,
+		Providers:     make([]string, 0, len(schemas.ProviderSchemas)),
+	}
+
+	for source, schema := range schemas.ProviderSchemas {
+		summary.Providers = append(summary.Providers, source)
+		summary.ResourceTypes += len(schema.ResourceSchemas)
+	}
+	sort.Strings(summary.Providers)
+
+	return summary
+}
+
+// schema returns the schema of the resource's type, or nil when the options
+// carry no schemas or the provider or type is not among them.
+func (o GraphOptions) schema(res models.ResourceState) *models.SchemaBlock {
+	if o.Schemas == nil {
+		return nil
+	}
+
+	provider, ok := o.Schemas.ProviderSchemas[ProviderSource(res.Provider)]
+	if !ok {
+		return nil
+	}
+
+	schemas := provider.ResourceSchemas
+	if res.Mode == "data" {
+		schemas = provider.DataSourceSchemas
+	}
+
+	schema, ok := schemas[res.Type]
+	if !ok {
+		return nil
+	}
+
+	return &schema.Block
+}
+
+// typedResource returns the resource with the top-level attributes its schema
+// marks sensitive added to the sensitive attributes of each instance, for
+// states written by Terraform versions that did not record them all.
+func (o GraphOptions) typedResource(res models.ResourceState) models.ResourceState {
+	block := o.schema(res)
+	if block == nil {
+		return res
+	}
+
+	instances := make([]models.ResourceInstance, len(res.Instances))
+	for i, instance := range res.Instances {
+		instances[i] = typedInstance(block, instance)
+	}
+	res.Instances = instances
+
+	return res
+}
+
+func typedInstance(block *models.SchemaBlock, instance models.ResourceInstance) models.ResourceInstance {
+	flagged := make(map[string]bool, len(instance.SensitiveAttributes))
+	for _, path := range instance.SensitiveAttributes {
+		if len(path) == 1 && path[0].Type == "get_attr" {
+			if name, ok := path[0].Key().(string); ok {
+				flagged[name] = true
+			}
+		}
+	}
+
+	var names []string
+	for name, attr := range block.Attributes {
+		if _, set := instance.Attributes[name]; attr.Sensitive && set && !flagged[name] {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return instance
+	}
+	sort.Strings(names)
+
+	paths := make([]models.AttributePath, 0, len(instance.SensitiveAttributes)+len(names))
+	paths = append(paths, instance.SensitiveAttributes...)
+	for _, name := range names {
+		paths = append(paths, models.AttributePath{{Type: "get_attr", Value: name}})
+	}
+	instance.SensitiveAttributes = paths
+
+	return instance
+}
@@ -0,0 +1,163 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+const awsSchemas = `{
+	"format_version": "1.0",
+	"provider_schemas": {
+		"registry.terraform.io/hashicorp/aws": {
+			"resource_schemas": {
+				"aws_vpc": {"version": 1, "block": {"attributes": {
+					"id": {"type": "string", "optional": true, "computed": true},
+					"arn": {"type": "string", "computed": true}
+				}}},
+				"aws_subnet": {"version": 1, "block": {"attributes": {
+					"id": {"type": "string", "optional": true, "computed": true},
+					"vpc_id": {"type": "string", "required": true}
+				}}},
+				"aws_security_group": {"version": 1, "block": {"attributes": {
+					"id": {"type": "string", "optional": true, "computed": true},
+					"owner_id": {"type": "string", "computed": true},
+					"vpc_id": {"type": "string", "optional": true, "computed": true}
+				}}},
+				"aws_instance": {"version": 1, "block": {
+					"attributes": {
+						"id": {"type": "string", "optional": true, "computed": true},
+						"subnet_id": {"type": "string", "optional": true, "computed": true},
+						"vpc_security_group_ids": {"type": ["set", "string"], "optional": true, "computed": true},
+						"user_data": {"type": "string", "optional": true, "sensitive": true}
+					},
+					"block_types": {"network_interface": {"nesting_mode": "set", "block": {"attributes": {
+						"network_interface_id": {"type": "string", "required": true}
+					}}}}
+				}}
+			}
+		}
+	}
+}`
+
+func schemaState() *models.TerraformState {
+	aws := `provider["registry.terraform.io/hashicorp/aws"]`
+
+	return &models.TerraformState{
+		Resources: []models.ResourceState{
+			{Mode: "managed", Type: "aws_vpc", Name: "main", Provider: aws, Instances: []models.ResourceInstance{
+				{Attributes: map[string]any{"id": "vpc-1", "arn": "arn:aws:ec2:vpc/vpc-1"}},
+			}},
+			{Mode: "managed", Type: "aws_subnet", Name: "a", Provider: aws, Instances: []models.ResourceInstance{
+				{Attributes: map[string]any{"id": "subnet-1", "vpc_id": "vpc-1"}, Dependencies: []string{"aws_vpc.main"}},
+			}},
+			{Mode: "managed", Type: "aws_security_group", Name: "web", Provider: aws, Instances: []models.ResourceInstance{
+				{Attributes: map[string]any{"id": "sg-1", "vpc_id": "vpc-1", "owner_id": "subnet-1"}},
+			}},
+			{Mode: "managed", Type: "aws_instance", Name: "web", Provider: aws, Instances: []models.ResourceInstance{
+				{Attributes: map[string]any{
+					"id":                     "i-1",
+					"subnet_id":              "subnet-1",
+					"vpc_security_group_ids": []any{"sg-1"},
+					"user_data":              "vpc-1",
+					"network_interface":      []any{map[string]any{"network_interface_id": "subnet-1"}},
+				}},
+			}},
+		},
+	}
+}
+
+func TestParseProviderSchemas(t *testing.T) {
+	schemas, err := ParseProviderSchemas([]byte(awsSchemas))
+	require.NoError(t, err)
+
+	summary := SummarizeSchemas(schemas)
+	assert.Equal(t, []string{"registry.terraform.io/hashicorp/aws"}, summary.Providers)
+	assert.Equal(t, 4, summary.ResourceTypes)
+
+	t.Run("normalizes provider addresses", func(t *testing.T) {
+		schemas, err := ParseProviderSchemas([]byte(`{"format_version": "1.0", "provider_schemas": {"hashicorp/AWS": {}}}`))
+		require.NoError(t, err)
+		assert.Contains(t, schemas.ProviderSchemas, "registry.terraform.io/hashicorp/aws")
+	})
+
+	for name, data := range map[string]string{
+		"empty":                    ``,
+		"invalid json":             `{`,
+		"missing format_version":   `{"provider_schemas": {}}`,
+		"missing provider_schemas": `{"format_version": "1.0"}`,
+	} {
+		t.Run("rejects "+name, func(t *testing.T) {
+			_, err := ParseProviderSchemas([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestReferenceEdges(t *testing.T) {
+	schemas, err := ParseProviderSchemas([]byte(awsSchemas))
+	require.NoError(t, err)
+
+	t.Run("without schemas no references are inferred", func(t *testing.T) {
+		graph := BuildGraph(schemaState())
+
+		for _, edge := range graph.Edges {
+			assert.NotEqual(t, edgeReference, edge.Type)
+		}
+	})
+
+	graph := BuildGraphWithOptions(schemaState(), GraphOptions{Schemas: schemas})
+
+	t.Run("configurable attributes reference other resources", func(t *testing.T) {
+		assert.Equal(t, []models.Edge{
+			{Source: "aws_instance.web", Target: "aws_security_group.web", Type: edgeReference, Metadata: map[string]any{"attribute": "vpc_security_group_ids"}},
+			{Source: "aws_instance.web", Target: "aws_subnet.a", Type: edgeReference, Metadata: map[string]any{"attribute": "network_interface.network_interface_id"}},
+		}, edgesFrom(graph, "aws_instance.web"))
+	})
+
+	t.Run("computed-only attributes are not references", func(t *testing.T) {
+		assert.Equal(t, []models.Edge{
+			{Source: "aws_security_group.web", Target: "aws_vpc.main", Type: edgeReference, Metadata: map[string]any{"attribute": "vpc_id"}},
+		}, edgesFrom(graph, "aws_security_group.web"))
+	})
+
+	t.Run("recorded dependencies are not duplicated", func(t *testing.T) {
+		assert.Equal(t, []models.Edge{
+			{Source: "aws_subnet.a", Target: "aws_vpc.main", Type: "implicit"},
+		}, edgesFrom(graph, "aws_subnet.a"))
+	})
+
+	t.Run("sensitive attributes are flagged", func(t *testing.T) {
+		state := schemaState()
+
+		graph := BuildGraphWithOptions(state, GraphOptions{Schemas: schemas})
+
+		for _, node := range graph.Nodes {
+			if node.ID == "aws_instance.web" {
+				assert.Equal(t, []string{"user_data"}, node.Metadata["sensitive_attributes"])
+			}
+		}
+
+		assert.Nil(t, state.Resources[3].Instances[0].SensitiveAttributes, "the state is not modified")
+	})
+
+	t.Run("the delta cache infers the same references", func(t *testing.T) {
+		data := []byte(`{"version": 4, "terraform_version": "1.5.0", "lineage": "prod", "resources": [
+			{"mode": "managed", "type": "aws_vpc", "name": "main", "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+			 "instances": [{"attributes": {"id": "vpc-1"}}]},
+			{"mode": "managed", "type": "aws_security_group", "name": "web", "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+			 "instances": [{"attributes": {"id": "sg-1", "vpc_id": "vpc-1"}}]}
+		]}`)
+
+		result, err := NewDeltaCache(1).Parse(data, GraphOptions{Schemas: schemas})
+		require.NoError(t, err)
+
+		assert.Equal(t, []models.Edge{
+			{Source: "aws_security_group.web", Target: "aws_vpc.main", Type: edgeReference, Metadata: map[string]any{"attribute": "vpc_id"}},
+		}, result.Graph.Edges)
+	})
+}
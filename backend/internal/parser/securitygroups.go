@@ -0,0 +1,340 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+const (
+	sgModeExternal   = "external"
+	sgTypeCIDR       = "cidr"
+	sgTypePrefix     = "prefix_list"
+	sgDirectionIn    = "ingress"
+	sgDirectionOut   = "egress"
+	sgProtocolAll    = "all"
+	sgRuleTypeLegacy = "aws_security_group_rule"
+	sgRuleTypeIn     = "aws_vpc_security_group_ingress_rule"
+	sgRuleTypeOut    = "aws_vpc_security_group_egress_rule"
+)
+
+var securityGroupTypes = map[string]bool{
+	"aws_security_group":         true,
+	"aws_default_security_group": true,
+}
+
+type sgRule struct {
+	direction string
+	protocol  string
+	fromPort  int
+	toPort    int
+	origin    string
+	peers     []sgPeer
+}
+
+type sgPeer struct {
+	id       string
+	nodeType string
+}
+
+type securityGroupIndex struct {
+	opts    GraphOptions
+	graph   *models.Graph
+	byID    map[string]string
+	nodeSet map[string]bool
+	edgeSet map[string]bool
+}
+
+// BuildSecurityGroupGraph projects security group rules onto a connectivity
+// graph. Edges follow the direction of allowed traffic: an ingress rule on a
+// group produces an edge from the allowed peer to the group, an egress rule an
+// edge from the group to the allowed peer. Peers that are not managed in the
+// state (CIDR blocks, prefix lists, foreign groups) become external nodes.
+func BuildSecurityGroupGraph(state *models.TerraformState) *models.Graph {
+	return BuildSecurityGroupGraphWithOptions(state, GraphOptions{})
+}
+
+// BuildSecurityGroupGraphWithOptions builds the security group connectivity
+// graph of the state, addressing nodes as selected by opts.
+func BuildSecurityGroupGraphWithOptions(state *models.TerraformState, opts GraphOptions) *models.Graph {
+	idx := &securityGroupIndex{
+		opts: opts,
+		graph: &models.Graph{
+			Nodes: []models.Node{},
+			Edges: []models.Edge{},
+		},
+		byID:    make(map[string]string),
+		nodeSet: make(map[string]bool),
+		edgeSet: make(map[string]bool),
+	}
+
+	for _, res := range state.Resources {
+		if !securityGroupTypes[res.Type] || res.Mode != "managed" {
+			continue
+		}
+
+		for i, instance := range res.Instances {
+			nodeID := idx.opts.nodeID(res, instance, i)
+			if idx.nodeSet[nodeID] {
+				continue
+			}
+
+			idx.addNode(models.Node{
+				ID:              nodeID,
+				Type:            res.Type,
+				Mode:            res.Mode,
+				Provider:        extractProviderName(res.Provider),
+				ProviderAddress: ProviderAddress(res.Provider),
+				Module:          res.Module,
+				Metadata:        idx.opts.nodeMetadata(res, instance),
+			})
+
+			if id := stringAttr(instance.Attributes, "id"); id != "" {
+				idx.byID[id] = nodeID
+			}
+		}
+	}
+
+	for _, res := range state.Resources {
+		if res.Mode != "managed" {
+			continue
+		}
+
+		for i, instance := range res.Instances {
+			nodeID := idx.opts.nodeID(res, instance, i)
+
+			for _, rule := range securityGroupRules(res, instance, nodeID) {
+				idx.addRule(rule.group, rule.sgRule)
+			}
+		}
+	}
+
+	attachAddresses(idx.graph, state, opts)
+
+	return idx.graph
+}
+
+type groupRule struct {
+	group string
+	sgRule
+}
+
+// securityGroupRules extracts the rules declared by a resource, whether inline
+// on the group or as standalone rule resources, keyed by the owning group ID.
+func securityGroupRules(res models.ResourceState, instance models.ResourceInstance, nodeID string) []groupRule {
+	attrs := instance.Attributes
+	rules := []groupRule{}
+
+	switch {
+	case securityGroupTypes[res.Type]:
+		groupID := stringAttr(attrs, "id")
+		for _, direction := range []string{sgDirectionIn, sgDirectionOut} {
+			for _, block := range objectList(attrs[direction]) {
+				rule := newSGRule(direction, block, nodeID)
+				rule.peers = blockPeers(block, groupID)
+				rules = append(rules, groupRule{group: groupID, sgRule: rule})
+			}
+		}
+	case res.Type == sgRuleTypeLegacy:
+		groupID := stringAttr(attrs, "security_group_id")
+		rule := newSGRule(stringAttr(attrs, "type"), attrs, nodeID)
+		rule.peers = blockPeers(attrs, groupID)
+		if source := stringAttr(attrs, "source_security_group_id"); source != "" {
+			rule.peers = append(rule.peers, sgPeer{id: source})
+		}
+		rules = append(rules, groupRule{group: groupID, sgRule: rule})
+	case res.Type == sgRuleTypeIn || res.Type == sgRuleTypeOut:
+		direction := sgDirectionIn
+		if res.Type == sgRuleTypeOut {
+			direction = sgDirectionOut
+		}
+
+		rule := newSGRule(direction, attrs, nodeID)
+		rule.protocol = normalizeProtocol(attrs["ip_protocol"])
+		for _, key := range []string{"cidr_ipv4", "cidr_ipv6"} {
+			if cidr := stringAttr(attrs, key); cidr != "" {
+				rule.peers = append(rule.peers, sgPeer{id: cidr, nodeType: sgTypeCIDR})
+			}
+		}
+		if prefix := stringAttr(attrs, "prefix_list_id"); prefix != "" {
+			rule.peers = append(rule.peers, sgPeer{id: prefix, nodeType: sgTypePrefix})
+		}
+		if ref := stringAttr(attrs, "referenced_security_group_id"); ref != "" {
+			rule.peers = append(rule.peers, sgPeer{id: ref})
+		}
+		rules = append(rules, groupRule{group: stringAttr(attrs, "security_group_id"), sgRule: rule})
+	}
+
+	return rules
+}
+
+func newSGRule(direction string, block map[string]any, origin string) sgRule {
+	return sgRule{
+		direction: direction,
+		protocol:  normalizeProtocol(block["protocol"]),
+		fromPort:  intAttr(block, "from_port"),
+		toPort:    intAttr(block, "to_port"),
+		origin:    origin,
+	}
+}
+
+// blockPeers collects the peers of an inline or legacy rule block.
+func blockPeers(block map[string]any, groupID string) []sgPeer {
+	peers := []sgPeer{}
+
+	for _, key := range []string{"cidr_blocks", "ipv6_cidr_blocks"} {
+		for _, cidr := range stringList(block[key]) {
+			peers = append(peers, sgPeer{id: cidr, nodeType: sgTypeCIDR})
+		}
+	}
+
+	for _, prefix := range stringList(block["prefix_list_ids"]) {
+		peers = append(peers, sgPeer{id: prefix, nodeType: sgTypePrefix})
+	}
+
+	for _, group := range stringList(block["security_groups"]) {
+		peers = append(peers, sgPeer{id: group})
+	}
+
+	if self, _ := block["self"].(bool); self && groupID != "" {
+		peers = append(peers, sgPeer{id: groupID})
+	}
+
+	return peers
+}
+
+func (idx *securityGroupIndex) addRule(groupID string, rule sgRule) {
+	group, ok := idx.byID[groupID]
+	if !ok {
+		return
+	}
+
+	for _, peer := range rule.peers {
+		peerNode := idx.peerNode(peer)
+
+		source, target := peerNode, group
+		if rule.direction == sgDirectionOut {
+			source, target = group, peerNode
+		}
+
+		ports := portRange(rule.protocol, rule.fromPort, rule.toPort)
+		key := edgeKey(source, target, rule.direction, rule.protocol, ports)
+		if idx.edgeSet[key] {
+			continue
+		}
+		idx.edgeSet[key] = true
+
+		idx.graph.Edges = append(idx.graph.Edges, models.Edge{
+			Source: source,
+			Target: target,
+			Type:   rule.direction,
+			Metadata: map[string]any{
+				"protocol": rule.protocol,
+				"ports":    ports,
+				"rule":     rule.origin,
+			},
+		})
+	}
+}
+
+// peerNode resolves a rule peer to a node, creating an external node for
+// peers that are not managed security groups.
+func (idx *securityGroupIndex) peerNode(peer sgPeer) string {
+	if nodeID, ok := idx.byID[peer.id]; ok && peer.nodeType == "" {
+		return nodeID
+	}
+
+	nodeType := peer.nodeType
+	if nodeType == "" {
+		nodeType = "aws_security_group"
+	}
+
+	if !idx.nodeSet[peer.id] {
+		metadata := map[string]any{"mode": sgModeExternal, "id": peer.id}
+		if nodeType == sgTypeCIDR {
+			metadata["public"] = isPublicCIDR(peer.id)
+		}
+
+		idx.addNode(models.Node{
+			ID:       peer.id,
+			Type:     nodeType,
+			Mode:     sgModeExternal,
+			Metadata: metadata,
+		})
+	}
+
+	return peer.id
+}
+
+func (idx *securityGroupIndex) addNode(node models.Node) {
+	idx.nodeSet[node.ID] = true
+	idx.graph.Nodes = append(idx.graph.Nodes, node)
+}
+
+func normalizeProtocol(value any) string {
+	protocol := fmt.Sprint(value)
+	switch protocol {
+	case "-1", "all", "<nil>", "":
+		return sgProtocolAll
+	case "6":
+		return "tcp"
+	case "17":
+		return "udp"
+	case "1":
+		return "icmp"
+	}
+
+	return protocol
+}
+
+func portRange(protocol string, from, to int) string {
+	switch {
+	case protocol == sgProtocolAll || (from <= 0 && (to <= 0 || to >= 65535)):
+		return sgProtocolAll
+	case from == to:
+		return fmt.Sprint(from)
+	}
+
+	return fmt.Sprintf("%d-%d", from, to)
+}
+
+// isPublicCIDR reports whether a CIDR block reaches beyond private address space.
+func isPublicCIDR(cidr string) bool {
+	ip, _, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return false
+	}
+
+	return !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast()
+}
+
+func intAttr(attrs map[string]any, key string) int {
+	switch value := attrs[key].(type) {
+	case float64:
+		return int(value)
+	case int:
+		return value
+	}
+
+	return 0
+}
+
+// stringList returns the string elements of a list or set attribute.
+func stringList(value any) []string {
+	items, ok := value.([]any)
+	if !ok {
+		return nil
+	}
+
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok && s != "" {
+			values = append(values, s)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,203 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func findEdge(graph *models.Graph, source, target, edgeType string) (models.Edge, bool) {
+	for _, edge := range graph.Edges {
+		if edge.Source == source && edge.Target == target && edge.Type == edgeType {
+			return edge, true
+		}
+	}
+
+	return models.Edge{}, false
+}
+
+func findNode(graph *models.Graph, id string) (models.Node, bool) {
+	for _, node := range graph.Nodes {
+		if node.ID == id {
+			return node, true
+		}
+	}
+
+	return models.Node{}, false
+}
+
+func securityGroupState() *models.TerraformState {
+	return &models.TerraformState{
+		Resources: []models.ResourceState{
+			managedResource("aws_security_group", "lb", map[string]any{
+				"id": "sg-lb",
+				"ingress": []any{
+					map[string]any{"protocol": "tcp", "from_port": float64(443), "to_port": float64(443), "cidr_blocks": []any{"0.0.0.0/0"}},
+				},
+				"egress": []any{
+					map[string]any{"protocol": "-1", "from_port": float64(0), "to_port": float64(0), "cidr_blocks": []any{"0.0.0.0/0"}},
+				},
+			}),
+			managedResource("aws_security_group", "app", map[string]any{
+				"id": "sg-app",
+				"ingress": []any{
+					map[string]any{"protocol": "tcp", "from_port": float64(8080), "to_port": float64(8080), "security_groups": []any{"sg-lb"}},
+					map[string]any{"protocol": "tcp", "from_port": float64(7000), "to_port": float64(7100), "self": true},
+				},
+			}),
+			managedResource("aws_security_group", "db", map[string]any{"id": "sg-db"}),
+			managedResource("aws_security_group_rule", "db_from_app", map[string]any{
+				"id":                       "sgrule-1",
+				"type":                     "ingress",
+				"security_group_id":        "sg-db",
+				"source_security_group_id": "sg-app",
+				"protocol":                 "tcp",
+				"from_port":                float64(5432),
+				"to_port":                  float64(5432),
+			}),
+			managedResource("aws_vpc_security_group_ingress_rule", "db_vpn", map[string]any{
+				"id":                "sgr-1",
+				"security_group_id": "sg-db",
+				"cidr_ipv4":         "10.8.0.0/16",
+				"ip_protocol":       "tcp",
+				"from_port":         float64(5432),
+				"to_port":           float64(5432),
+			}),
+			managedResource("aws_vpc_security_group_egress_rule", "db_peer", map[string]any{
+				"id":                           "sgr-2",
+				"security_group_id":            "sg-db",
+				"referenced_security_group_id": "sg-foreign",
+				"ip_protocol":                  "-1",
+			}),
+		},
+	}
+}
+
+func TestBuildSecurityGroupGraph(t *testing.T) {
+	t.Run("empty state returns empty graph", func(t *testing.T) {
+		graph := BuildSecurityGroupGraph(&models.TerraformState{})
+
+		assert.NotNil(t, graph)
+		assert.Empty(t, graph.Nodes)
+		assert.Empty(t, graph.Edges)
+	})
+
+	t.Run("inline ingress from cidr points at the group", func(t *testing.T) {
+		graph := BuildSecurityGroupGraph(securityGroupState())
+
+		edge, ok := findEdge(graph, "0.0.0.0/0", "aws_security_group.lb", "ingress")
+		require.True(t, ok)
+		assert.Equal(t, "tcp", edge.Metadata["protocol"])
+		assert.Equal(t, "443", edge.Metadata["ports"])
+		assert.Equal(t, "aws_security_group.lb", edge.Metadata["rule"])
+	})
+
+	t.Run("egress points away from the group", func(t *testing.T) {
+		graph := BuildSecurityGroupGraph(securityGroupState())
+
+		edge, ok := findEdge(graph, "aws_security_group.lb", "0.0.0.0/0", "egress")
+		require.True(t, ok)
+		assert.Equal(t, "all", edge.Metadata["protocol"])
+		assert.Equal(t, "all", edge.Metadata["ports"])
+	})
+
+	t.Run("group references resolve to managed groups", func(t *testing.T) {
+		graph := BuildSecurityGroupGraph(securityGroupState())
+
+		edge, ok := findEdge(graph, "aws_security_group.lb", "aws_security_group.app", "ingress")
+		require.True(t, ok)
+		assert.Equal(t, "8080", edge.Metadata["ports"])
+
+		self, ok := findEdge(graph, "aws_security_group.app", "aws_security_group.app", "ingress")
+		require.True(t, ok)
+		assert.Equal(t, "7000-7100", self.Metadata["ports"])
+	})
+
+	t.Run("standalone rule resources are attributed to their group", func(t *testing.T) {
+		graph := BuildSecurityGroupGraph(securityGroupState())
+
+		legacy, ok := findEdge(graph, "aws_security_group.app", "aws_security_group.db", "ingress")
+		require.True(t, ok)
+		assert.Equal(t, "aws_security_group_rule.db_from_app", legacy.Metadata["rule"])
+
+		vpcRule, ok := findEdge(graph, "10.8.0.0/16", "aws_security_group.db", "ingress")
+		require.True(t, ok)
+		assert.Equal(t, "5432", vpcRule.Metadata["ports"])
+
+		_, ok = findEdge(graph, "aws_security_group.db", "sg-foreign", "egress")
+		assert.True(t, ok)
+	})
+
+	t.Run("external peers become external nodes", func(t *testing.T) {
+		graph := BuildSecurityGroupGraph(securityGroupState())
+
+		anywhere, ok := findNode(graph, "0.0.0.0/0")
+		require.True(t, ok)
+		assert.Equal(t, "cidr", anywhere.Type)
+		assert.Equal(t, "external", anywhere.Mode)
+		assert.Equal(t, true, anywhere.Metadata["public"])
+
+		vpn, ok := findNode(graph, "10.8.0.0/16")
+		require.True(t, ok)
+		assert.Equal(t, false, vpn.Metadata["public"])
+
+		foreign, ok := findNode(graph, "sg-foreign")
+		require.True(t, ok)
+		assert.Equal(t, "aws_security_group", foreign.Type)
+	})
+
+	t.Run("rule resources are not nodes", func(t *testing.T) {
+		graph := BuildSecurityGroupGraph(securityGroupState())
+
+		_, ok := findNode(graph, "aws_security_group_rule.db_from_app")
+		assert.False(t, ok)
+	})
+
+	t.Run("duplicate rules produce a single edge", func(t *testing.T) {
+		rule := map[string]any{"protocol": "tcp", "from_port": float64(22), "to_port": float64(22), "cidr_blocks": []any{"10.0.0.0/8"}}
+		state := &models.TerraformState{
+			Resources: []models.ResourceState{
+				managedResource("aws_security_group", "ssh", map[string]any{
+					"id":      "sg-ssh",
+					"ingress": []any{rule, rule},
+				}),
+			},
+		}
+
+		graph := BuildSecurityGroupGraph(state)
+
+		assert.Len(t, graph.Edges, 1)
+	})
+}
+
+func TestPortRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol string
+		from     int
+		to       int
+		expected string
+	}{
+		{name: "single port", protocol: "tcp", from: 443, to: 443, expected: "443"},
+		{name: "range", protocol: "tcp", from: 1024, to: 2048, expected: "1024-2048"},
+		{name: "all protocols", protocol: "all", from: 0, to: 0, expected: "all"},
+		{name: "full tcp range", protocol: "tcp", from: 0, to: 65535, expected: "all"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, portRange(tt.protocol, tt.from, tt.to))
+		})
+	}
+}
+
+func TestNormalizeProtocol(t *testing.T) {
+	assert.Equal(t, "all", normalizeProtocol("-1"))
+	assert.Equal(t, "all", normalizeProtocol(nil))
+	assert.Equal(t, "tcp", normalizeProtocol("6"))
+	assert.Equal(t, "udp", normalizeProtocol("udp"))
+}
@@ -0,0 +1,29 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"sort"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// SortGraph orders nodes by ID and edges by source, target and type in place,
+// so the same state always serializes to the same bytes.
+func SortGraph(graph *models.Graph) {
+	sort.SliceStable(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].ID < graph.Nodes[j].ID
+	})
+
+	sort.SliceStable(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+
+		return a.Type < b.Type
+	})
+}
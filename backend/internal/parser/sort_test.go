@@ -0,0 +1,79 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestSortGraph(t *testing.T) {
+	t.Run("sorts nodes by id", func(t *testing.T) {
+		graph := &models.Graph{
+			Nodes: []models.Node{{ID: "c"}, {ID: "a"}, {ID: "b"}},
+		}
+
+		SortGraph(graph)
+
+		assert.Equal(t, "a", graph.Nodes[0].ID)
+		assert.Equal(t, "b", graph.Nodes[1].ID)
+		assert.Equal(t, "c", graph.Nodes[2].ID)
+	})
+
+	t.Run("sorts edges by source, target and type", func(t *testing.T) {
+		graph := &models.Graph{
+			Edges: []models.Edge{
+				{Source: "b", Target: "a", Type: "implicit"},
+				{Source: "a", Target: "c", Type: "implicit"},
+				{Source: "a", Target: "b", Type: "implicit"},
+				{Source: "a", Target: "b", Type: "depends_on"},
+			},
+		}
+
+		SortGraph(graph)
+
+		assert.Equal(t, []models.Edge{
+			{Source: "a", Target: "b", Type: "depends_on"},
+			{Source: "a", Target: "b", Type: "implicit"},
+			{Source: "a", Target: "c", Type: "implicit"},
+			{Source: "b", Target: "a", Type: "implicit"},
+		}, graph.Edges)
+	})
+
+	t.Run("output is stable across builds", func(t *testing.T) {
+		state := &models.TerraformState{
+			Resources: []models.ResourceState{
+				{
+					Type:      "aws_instance",
+					Name:      "web",
+					Mode:      "managed",
+					DependsOn: []string{"aws_iam_role.a", "aws_iam_role.b", "aws_iam_role.c"},
+					Instances: []models.ResourceInstance{
+						{
+							Attributes:   map[string]any{"id": "i-1"},
+							Dependencies: []string{"aws_subnet.a", "aws_subnet.b", "aws_vpc.main"},
+						},
+					},
+				},
+			},
+		}
+
+		first := BuildGraph(state)
+		SortGraph(first)
+		expected, err := json.Marshal(first)
+		require.NoError(t, err)
+
+		for i := 0; i < 20; i++ {
+			graph := BuildGraph(state)
+			SortGraph(graph)
+
+			actual, err := json.Marshal(graph)
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), string(actual))
+		}
+	})
+}
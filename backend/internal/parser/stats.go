@@ -0,0 +1,140 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+const digestPrefix = "sha256:"
+
+// statsTopEntries bounds the top types and largest modules lists.
+const statsTopEntries = 10
+
+// ComputeStats summarizes the graph and fingerprints its content.
+func ComputeStats(graph *models.Graph) *models.Stats {
+	stats := &models.Stats{
+		TotalNodes:      len(graph.Nodes),
+		TotalEdges:      len(graph.Edges),
+		ResourcesByType: make(map[string]int),
+		ResourcesByMode: make(map[string]int),
+		Providers:       make(map[string]models.ProviderStats),
+		Digest:          Digest(graph),
+	}
+
+	providers := make(map[string]string, len(graph.Nodes))
+	modules := make(map[string]int)
+
+	for _, node := range graph.Nodes {
+		stats.ResourcesByType[node.Type]++
+		stats.ResourcesByMode[node.Mode]++
+
+		address := providerKey(node)
+		provider := stats.Providers[address]
+		provider.Nodes++
+		stats.Providers[address] = provider
+		providers[node.ID] = address
+
+		if node.Module != "" {
+			modules[node.Module]++
+		}
+
+		switch node.Status {
+		case models.NodeStatusTainted:
+			stats.TaintedInstances++
+		case models.NodeStatusDeposed:
+			stats.DeposedInstances++
+		}
+	}
+
+	for _, edge := range graph.Edges {
+		if name, ok := providers[edge.Source]; ok {
+			provider := stats.Providers[name]
+			provider.Edges++
+			stats.Providers[name] = provider
+		}
+	}
+
+	stats.TopTypes = topTypes(stats.ResourcesByType)
+	stats.LargestModules = largestModules(modules)
+
+	return stats
+}
+
+// providerKey is the key of the provider of a node in the stats: the full
+// address of its provider configuration, so that providers of the same type
+// from different namespaces, and the aliased configurations of a provider,
+// are counted apart. Nodes of graphs built before nodes recorded it fall
+// back to the provider type.
+func providerKey(node models.Node) string {
+	if node.ProviderAddress != "" {
+		return node.ProviderAddress
+	}
+
+	return node.Provider
+}
+
+func topTypes(counts map[string]int) []models.TypeCount {
+	types := make([]models.TypeCount, 0, len(counts))
+	for name, count := range counts {
+		types = append(types, models.TypeCount{Type: name, Count: count})
+	}
+
+	sort.Slice(types, func(i, j int) bool {
+		if types[i].Count != types[j].Count {
+			return types[i].Count > types[j].Count
+		}
+
+		return types[i].Type < types[j].Type
+	})
+
+	return types[:min(len(types), statsTopEntries)]
+}
+
+func largestModules(counts map[string]int) []models.ModuleCount {
+	modules := make([]models.ModuleCount, 0, len(counts))
+	for name, nodes := range counts {
+		modules = append(modules, models.ModuleCount{Module: name, Nodes: nodes})
+	}
+
+	sort.Slice(modules, func(i, j int) bool {
+		if modules[i].Nodes != modules[j].Nodes {
+			return modules[i].Nodes > modules[j].Nodes
+		}
+
+		return modules[i].Module < modules[j].Module
+	})
+
+	return modules[:min(len(modules), statsTopEntries)]
+}
+
+// Digest returns a canonical SHA-256 hash of the graph's nodes and edges.
+// Ordering does not affect the digest, and stats and warnings are excluded,
+// so two graphs share a digest exactly when their topology and node content
+// match.
+func Digest(graph *models.Graph) string {
+	canonical := &models.Graph{
+		Nodes: append([]models.Node(nil), graph.Nodes...),
+		Edges: append([]models.Edge(nil), graph.Edges...),
+	}
+
+	for i := range canonical.Nodes {
+		canonical.Nodes[i].Warnings = nil
+	}
+
+	SortGraph(canonical)
+
+	data, err := json.Marshal(canonical)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(data)
+
+	return digestPrefix + hex.EncodeToString(sum[:])
+}
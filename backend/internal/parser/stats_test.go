@@ -0,0 +1,163 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/terrascope/core/internal/models"
+)
+
+func digestGraph() *models.Graph {
+	return &models.Graph{
+		Nodes: []models.Node{
+			{ID: "aws_vpc.main", Type: "aws_vpc", Mode: "managed", Provider: "aws"},
+			{ID: "aws_subnet.a", Type: "aws_subnet", Mode: "managed", Provider: "aws"},
+			{ID: "data.aws_ami.ubuntu", Type: "aws_ami", Mode: "data", Provider: "aws"},
+		},
+		Edges: []models.Edge{
+			{Source: "aws_subnet.a", Target: "aws_vpc.main", Type: "implicit"},
+		},
+	}
+}
+
+func TestComputeStats(t *testing.T) {
+	t.Run("counts nodes, edges, types and modes", func(t *testing.T) {
+		stats := ComputeStats(digestGraph())
+
+		assert.Equal(t, 3, stats.TotalNodes)
+		assert.Equal(t, 1, stats.TotalEdges)
+		assert.Equal(t, map[string]int{"aws_vpc": 1, "aws_subnet": 1, "aws_ami": 1}, stats.ResourcesByType)
+		assert.Equal(t, map[string]int{"managed": 2, "data": 1}, stats.ResourcesByMode)
+		assert.True(t, strings.HasPrefix(stats.Digest, "sha256:"))
+	})
+
+	t.Run("counts providers, top types and largest modules", func(t *testing.T) {
+		graph := &models.Graph{
+			Nodes: []models.Node{
+				{ID: "aws_vpc.main", Type: "aws_vpc", Provider: "aws"},
+				{ID: "module.net.aws_subnet.a", Type: "aws_subnet", Provider: "aws", Module: "module.net"},
+				{ID: "module.net.aws_subnet.b", Type: "aws_subnet", Provider: "aws", Module: "module.net"},
+				{ID: "module.dns.cloudflare_record.www", Type: "cloudflare_record", Provider: "cloudflare", Module: "module.dns"},
+			},
+			Edges: []models.Edge{
+				{Source: "module.net.aws_subnet.a", Target: "aws_vpc.main"},
+				{Source: "module.net.aws_subnet.b", Target: "aws_vpc.main"},
+				{Source: "module.dns.cloudflare_record.www", Target: "module.net.aws_subnet.a"},
+			},
+		}
+
+		stats := ComputeStats(graph)
+
+		assert.Equal(t, map[string]models.ProviderStats{
+			"aws":        {Nodes: 3, Edges: 2},
+			"cloudflare": {Nodes: 1, Edges: 1},
+		}, stats.Providers)
+		assert.Equal(t, []models.TypeCount{
+			{Type: "aws_subnet", Count: 2},
+			{Type: "aws_vpc", Count: 1},
+			{Type: "cloudflare_record", Count: 1},
+		}, stats.TopTypes)
+		assert.Equal(t, []models.ModuleCount{
+			{Module: "module.net", Nodes: 2},
+			{Module: "module.dns", Nodes: 1},
+		}, stats.LargestModules)
+	})
+
+	t.Run("counts providers by full address", func(t *testing.T) {
+		graph := BuildGraph(&models.TerraformState{Resources: []models.ResourceState{
+			{Mode: "managed", Type: "custom_thing", Name: "a", Provider: `provider["registry.terraform.io/mycorp/custom"]`,
+				Instances: []models.ResourceInstance{{}}},
+			{Mode: "managed", Type: "custom_thing", Name: "b", Provider: `provider["registry.terraform.io/othercorp/custom"]`,
+				Instances: []models.ResourceInstance{{}}},
+			{Mode: "managed", Type: "aws_vpc", Name: "east", Provider: `provider["registry.terraform.io/hashicorp/aws"]`,
+				Instances: []models.ResourceInstance{{}}},
+			{Mode: "managed", Type: "aws_vpc", Name: "west", Provider: `provider["registry.terraform.io/hashicorp/aws"].west`,
+				Instances: []models.ResourceInstance{{}}},
+		}})
+
+		stats := ComputeStats(graph)
+
+		assert.Equal(t, map[string]models.ProviderStats{
+			"registry.terraform.io/mycorp/custom":      {Nodes: 1},
+			"registry.terraform.io/othercorp/custom":   {Nodes: 1},
+			"registry.terraform.io/hashicorp/aws":      {Nodes: 1},
+			"registry.terraform.io/hashicorp/aws.west": {Nodes: 1},
+		}, stats.Providers)
+		assert.Equal(t, "custom", graph.Nodes[0].Provider)
+	})
+
+	t.Run("keeps the ten most common types", func(t *testing.T) {
+		graph := &models.Graph{}
+		for i := range 12 {
+			for range i + 1 {
+				graph.Nodes = append(graph.Nodes, models.Node{Type: fmt.Sprintf("type_%02d", i)})
+			}
+		}
+
+		stats := ComputeStats(graph)
+
+		assert.Len(t, stats.TopTypes, 10)
+		assert.Equal(t, models.TypeCount{Type: "type_11", Count: 12}, stats.TopTypes[0])
+		assert.Equal(t, "type_02", stats.TopTypes[9].Type)
+	})
+
+	t.Run("counts tainted and deposed instances", func(t *testing.T) {
+		graph := digestGraph()
+		graph.Nodes[0].Status = models.NodeStatusTainted
+		graph.Nodes[1].Status = models.NodeStatusDeposed
+		graph.Nodes[2].Status = models.NodeStatusDeposed
+
+		stats := ComputeStats(graph)
+
+		assert.Equal(t, 1, stats.TaintedInstances)
+		assert.Equal(t, 2, stats.DeposedInstances)
+	})
+
+	t.Run("empty graph", func(t *testing.T) {
+		stats := ComputeStats(&models.Graph{})
+
+		assert.Equal(t, 0, stats.TotalNodes)
+		assert.Empty(t, stats.ResourcesByType)
+		assert.NotEmpty(t, stats.Digest)
+	})
+}
+
+func TestDigest(t *testing.T) {
+	t.Run("is independent of ordering", func(t *testing.T) {
+		graph := digestGraph()
+		reordered := digestGraph()
+		reordered.Nodes[0], reordered.Nodes[2] = reordered.Nodes[2], reordered.Nodes[0]
+
+		assert.Equal(t, Digest(graph), Digest(reordered))
+	})
+
+	t.Run("does not reorder the input graph", func(t *testing.T) {
+		graph := digestGraph()
+
+		Digest(graph)
+
+		assert.Equal(t, "aws_vpc.main", graph.Nodes[0].ID)
+	})
+
+	t.Run("changes when topology changes", func(t *testing.T) {
+		graph := digestGraph()
+		changed := digestGraph()
+		changed.Edges[0].Type = "depends_on"
+
+		assert.NotEqual(t, Digest(graph), Digest(changed))
+	})
+
+	t.Run("ignores stats and warnings", func(t *testing.T) {
+		graph := digestGraph()
+		annotated := digestGraph()
+		annotated.Stats = &models.Stats{TotalNodes: 3}
+		annotated.Warnings = []models.Warning{{Type: "check", Message: "failed"}}
+		annotated.Nodes[0].Warnings = []models.Warning{{Type: "check", Message: "failed"}}
+
+		assert.Equal(t, Digest(graph), Digest(annotated))
+	})
+}
@@ -0,0 +1,145 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// Directions in which Neighborhood follows edges. Edges point from a node to
+// its dependencies.
+const (
+	DirectionDependencies = "dependencies"
+	DirectionDependents   = "dependents"
+	DirectionBoth         = "both"
+)
+
+// ModuleSubgraph returns the nodes of the given module, including its
+// instances and nested modules, and the edges between them. "root" selects
+// the resources outside any module, matching the module summary node IDs.
+func ModuleSubgraph(graph *models.Graph, module string) *models.Graph {
+	kept := make(map[string]bool)
+
+	for _, node := range graph.Nodes {
+		if module == rootModuleGroup && node.Module == "" ||
+			node.Module == module ||
+			strings.HasPrefix(node.Module, module+".") ||
+			strings.HasPrefix(node.Module, module+"[") {
+			kept[node.ID] = true
+		}
+	}
+
+	return inducedSubgraph(graph, kept)
+}
+
+// ResolveNodes returns the IDs of the nodes addressed by id: the node with
+// that ID, otherwise the node whose Terraform address it is, otherwise every
+// instance of the resource with that address.
+func ResolveNodes(graph *models.Graph, id string) []string {
+	for _, node := range graph.Nodes {
+		if node.ID == id {
+			return []string{id}
+		}
+	}
+
+	var instances []string
+
+	for nodeID, address := range graph.Addresses {
+		if address.Terraform == id {
+			return []string{nodeID}
+		}
+		if strings.HasPrefix(address.Terraform, id+"[") {
+			instances = append(instances, nodeID)
+		}
+	}
+
+	sort.Strings(instances)
+
+	return instances
+}
+
+// Neighborhood returns the nodes at most depth edges away from the roots,
+// following edges towards dependencies, dependents or both, together with the
+// edges between them.
+func Neighborhood(graph *models.Graph, roots []string, depth int, direction string) *models.Graph {
+	nodes := make(map[string]bool, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		nodes[node.ID] = true
+	}
+
+	adjacent := make(map[string][]string)
+	for _, edge := range graph.Edges {
+		if !nodes[edge.Source] || !nodes[edge.Target] {
+			continue
+		}
+		if direction != DirectionDependents {
+			adjacent[edge.Source] = append(adjacent[edge.Source], edge.Target)
+		}
+		if direction != DirectionDependencies {
+			adjacent[edge.Target] = append(adjacent[edge.Target], edge.Source)
+		}
+	}
+
+	kept := make(map[string]bool)
+	frontier := []string{}
+
+	for _, root := range roots {
+		if nodes[root] && !kept[root] {
+			kept[root] = true
+			frontier = append(frontier, root)
+		}
+	}
+
+	for level := 0; level < depth && len(frontier) > 0; level++ {
+		var next []string
+
+		for _, id := range frontier {
+			for _, neighbor := range adjacent[id] {
+				if !kept[neighbor] {
+					kept[neighbor] = true
+					next = append(next, neighbor)
+				}
+			}
+		}
+
+		frontier = next
+	}
+
+	return inducedSubgraph(graph, kept)
+}
+
+// inducedSubgraph returns the kept nodes, in graph order, with the edges and
+// addresses among them.
+func inducedSubgraph(graph *models.Graph, kept map[string]bool) *models.Graph {
+	subgraph := &models.Graph{
+		Nodes:    []models.Node{},
+		Edges:    []models.Edge{},
+		Warnings: graph.Warnings,
+	}
+
+	for _, node := range graph.Nodes {
+		if kept[node.ID] {
+			subgraph.Nodes = append(subgraph.Nodes, node)
+		}
+	}
+
+	for _, edge := range graph.Edges {
+		if kept[edge.Source] && kept[edge.Target] {
+			subgraph.Edges = append(subgraph.Edges, edge)
+		}
+	}
+
+	if graph.Addresses != nil {
+		subgraph.Addresses = make(map[string]models.NodeAddress, len(kept))
+		for id := range kept {
+			if address, ok := graph.Addresses[id]; ok {
+				subgraph.Addresses[id] = address
+			}
+		}
+	}
+
+	return subgraph
+}
@@ -0,0 +1,195 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestModuleSubgraph(t *testing.T) {
+	ids := func(graph *models.Graph) []string {
+		result := []string{}
+		for _, node := range graph.Nodes {
+			result = append(result, node.ID)
+		}
+
+		return result
+	}
+
+	t.Run("keeps the module's nodes and internal edges", func(t *testing.T) {
+		subgraph := ModuleSubgraph(modularGraph(), "module.app")
+
+		assert.Equal(t, []string{
+			"module.app.aws_instance.web[0]",
+			"module.app.aws_instance.web[1]",
+			"module.app.aws_eip.web",
+		}, ids(subgraph))
+		assert.Equal(t, []models.Edge{
+			{Source: "module.app.aws_eip.web", Target: "module.app.aws_instance.web[0]", Type: "implicit"},
+		}, subgraph.Edges)
+	})
+
+	t.Run("root selects resources outside modules", func(t *testing.T) {
+		assert.Equal(t, []string{"aws_vpc.main"}, ids(ModuleSubgraph(modularGraph(), "root")))
+	})
+
+	t.Run("includes module instances and nested modules", func(t *testing.T) {
+		graph := &models.Graph{
+			Nodes: []models.Node{
+				{ID: "a", Module: `module.app["blue"]`},
+				{ID: "b", Module: "module.app[0].module.db"},
+				{ID: "c", Module: "module.application"},
+			},
+		}
+
+		assert.Equal(t, []string{"a", "b"}, ids(ModuleSubgraph(graph, "module.app")))
+	})
+
+	t.Run("filters addresses", func(t *testing.T) {
+		graph := modularGraph()
+		graph.Addresses = map[string]models.NodeAddress{
+			"aws_vpc.main":                   {Terraform: "aws_vpc.main"},
+			"module.db.aws_db_instance.main": {Terraform: "module.db.aws_db_instance.main"},
+		}
+
+		subgraph := ModuleSubgraph(graph, "module.db")
+
+		assert.Equal(t, map[string]models.NodeAddress{
+			"module.db.aws_db_instance.main": {Terraform: "module.db.aws_db_instance.main"},
+		}, subgraph.Addresses)
+	})
+
+	t.Run("unknown module", func(t *testing.T) {
+		subgraph := ModuleSubgraph(modularGraph(), "module.missing")
+
+		assert.Empty(t, subgraph.Nodes)
+		assert.Empty(t, subgraph.Edges)
+	})
+}
+
+func chainGraph() *models.Graph {
+	return &models.Graph{
+		Nodes: []models.Node{
+			{ID: "aws_eip.web"},
+			{ID: "aws_instance.web[0]"},
+			{ID: "aws_instance.web[1]"},
+			{ID: "aws_subnet.a"},
+			{ID: "aws_vpc.main"},
+			{ID: "aws_s3_bucket.logs"},
+		},
+		Edges: []models.Edge{
+			{Source: "aws_eip.web", Target: "aws_instance.web[0]", Type: "implicit"},
+			{Source: "aws_instance.web[0]", Target: "aws_subnet.a", Type: "implicit"},
+			{Source: "aws_instance.web[1]", Target: "aws_subnet.a", Type: "implicit"},
+			{Source: "aws_subnet.a", Target: "aws_vpc.main", Type: "implicit"},
+			{Source: "aws_subnet.a", Target: "aws_subnet.gone", Type: "implicit"},
+		},
+		Addresses: map[string]models.NodeAddress{
+			"aws_instance.web[0]": {Terraform: "aws_instance.web[0]"},
+			"aws_instance.web[1]": {Terraform: "aws_instance.web[1]"},
+			"aws_subnet.a":        {Terraform: "aws_subnet.a[0]"},
+		},
+	}
+}
+
+func TestResolveNodes(t *testing.T) {
+	graph := chainGraph()
+
+	t.Run("node ID", func(t *testing.T) {
+		assert.Equal(t, []string{"aws_vpc.main"}, ResolveNodes(graph, "aws_vpc.main"))
+	})
+
+	t.Run("terraform instance address", func(t *testing.T) {
+		assert.Equal(t, []string{"aws_subnet.a"}, ResolveNodes(graph, "aws_subnet.a[0]"))
+	})
+
+	t.Run("resource address selects every instance", func(t *testing.T) {
+		assert.Equal(t, []string{"aws_instance.web[0]", "aws_instance.web[1]"}, ResolveNodes(graph, "aws_instance.web"))
+	})
+
+	t.Run("unknown address", func(t *testing.T) {
+		assert.Empty(t, ResolveNodes(graph, "aws_instance.db"))
+	})
+}
+
+func TestNeighborhood(t *testing.T) {
+	tests := []struct {
+		name      string
+		roots     []string
+		depth     int
+		direction string
+		expected  []string
+	}{
+		{
+			name:      "both directions",
+			roots:     []string{"aws_subnet.a"},
+			depth:     1,
+			direction: DirectionBoth,
+			expected:  []string{"aws_instance.web[0]", "aws_instance.web[1]", "aws_subnet.a", "aws_vpc.main"},
+		},
+		{
+			name:      "dependencies only",
+			roots:     []string{"aws_eip.web"},
+			depth:     3,
+			direction: DirectionDependencies,
+			expected:  []string{"aws_eip.web", "aws_instance.web[0]", "aws_subnet.a", "aws_vpc.main"},
+		},
+		{
+			name:      "dependents only",
+			roots:     []string{"aws_subnet.a"},
+			depth:     2,
+			direction: DirectionDependents,
+			expected:  []string{"aws_eip.web", "aws_instance.web[0]", "aws_instance.web[1]", "aws_subnet.a"},
+		},
+		{
+			name:      "depth limits the walk",
+			roots:     []string{"aws_eip.web"},
+			depth:     1,
+			direction: DirectionBoth,
+			expected:  []string{"aws_eip.web", "aws_instance.web[0]"},
+		},
+		{
+			name:      "depth zero keeps the roots",
+			roots:     []string{"aws_instance.web[0]", "aws_instance.web[1]"},
+			depth:     0,
+			direction: DirectionBoth,
+			expected:  []string{"aws_instance.web[0]", "aws_instance.web[1]"},
+		},
+		{
+			name:      "unknown roots are ignored",
+			roots:     []string{"aws_subnet.gone"},
+			depth:     2,
+			direction: DirectionBoth,
+			expected:  []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subgraph := Neighborhood(chainGraph(), tt.roots, tt.depth, tt.direction)
+
+			ids := []string{}
+			for _, node := range subgraph.Nodes {
+				ids = append(ids, node.ID)
+			}
+			sort.Strings(ids)
+
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+
+	t.Run("keeps edges between kept nodes", func(t *testing.T) {
+		subgraph := Neighborhood(chainGraph(), []string{"aws_subnet.a"}, 1, DirectionDependencies)
+
+		assert.Equal(t, []models.Edge{
+			{Source: "aws_subnet.a", Target: "aws_vpc.main", Type: "implicit"},
+		}, subgraph.Edges)
+		assert.Equal(t, map[string]models.NodeAddress{
+			"aws_subnet.a": {Terraform: "aws_subnet.a[0]"},
+		}, subgraph.Addresses)
+	})
+}
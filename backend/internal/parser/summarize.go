@@ -0,0 +1,158 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"fmt"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// Aggregations supported by Summarize.
+const (
+	SummarizeByModule = "module"
+	SummarizeByType   = "type"
+)
+
+const (
+	rootModuleGroup  = "root"
+	summaryNodeMode  = "summary"
+	aggregateEdge    = "aggregate"
+	moduleNodeType   = "module"
+	bundleSamples    = 3
+	summaryFetchHint = "fetch the resources of one module with ?module=<node id> or the neighborhood of a resource from /graph/subgraph?graph=<summary digest>&root=<address>"
+)
+
+// Summarize aggregates the graph into one node per module (by module) or per
+// resource type (by type), with one edge per pair of connected groups. Node
+// metadata counts the nodes of each group and edge metadata the edges between
+// groups, by type, with the first few of them as samples so that clients can
+// show what a bundle stands for. Module aggregation of a graph without
+// modules falls back to types.
+func Summarize(graph *models.Graph, by string, maxNodes int) *models.Graph {
+	if by == SummarizeByModule && !hasModules(graph) {
+		by = SummarizeByType
+	}
+
+	group := func(node models.Node) string {
+		if by == SummarizeByType {
+			return node.Type
+		}
+		if node.Module == "" {
+			return rootModuleGroup
+		}
+
+		return node.Module
+	}
+
+	groups := make(map[string]*models.Node)
+	nodeGroup := make(map[string]string, len(graph.Nodes))
+
+	for _, node := range graph.Nodes {
+		id := group(node)
+		nodeGroup[node.ID] = id
+
+		summary, ok := groups[id]
+		if !ok {
+			summary = &models.Node{
+				ID:       id,
+				Type:     id,
+				Mode:     summaryNodeMode,
+				Metadata: map[string]any{"node_count": 0},
+			}
+			if by == SummarizeByModule {
+				summary.Type = moduleNodeType
+				summary.Module = node.Module
+			}
+			groups[id] = summary
+		}
+
+		summary.Metadata["node_count"] = summary.Metadata["node_count"].(int) + 1
+	}
+
+	type groupEdge struct{ source, target string }
+	type bundle struct {
+		count   int
+		types   map[string]int
+		samples []models.Edge
+	}
+	bundles := make(map[groupEdge]*bundle)
+
+	for _, edge := range graph.Edges {
+		source, ok := nodeGroup[edge.Source]
+		if !ok {
+			continue
+		}
+		target, ok := nodeGroup[edge.Target]
+		if !ok || source == target {
+			continue
+		}
+
+		b, ok := bundles[groupEdge{source, target}]
+		if !ok {
+			b = &bundle{types: make(map[string]int)}
+			bundles[groupEdge{source, target}] = b
+		}
+
+		b.count++
+		b.types[edge.Type]++
+		if len(b.samples) < bundleSamples {
+			b.samples = append(b.samples, models.Edge{Source: edge.Source, Target: edge.Target, Type: edge.Type})
+		}
+	}
+
+	summary := &models.Graph{
+		Nodes:      make([]models.Node, 0, len(groups)),
+		Edges:      make([]models.Edge, 0, len(bundles)),
+		Summarized: true,
+		Summary: &models.Summary{
+			By:         by,
+			TotalNodes: len(graph.Nodes),
+			TotalEdges: len(graph.Edges),
+			MaxNodes:   maxNodes,
+			Message:    summaryMessage(len(graph.Nodes), maxNodes, by),
+		},
+		Warnings: graph.Warnings,
+	}
+
+	for _, node := range groups {
+		summary.Nodes = append(summary.Nodes, *node)
+	}
+
+	for edge, b := range bundles {
+		summary.Edges = append(summary.Edges, models.Edge{
+			Source: edge.source,
+			Target: edge.target,
+			Type:   aggregateEdge,
+			Metadata: map[string]any{
+				"edge_count":   b.count,
+				"edge_types":   b.types,
+				"sample_edges": b.samples,
+			},
+		})
+	}
+
+	SortGraph(summary)
+
+	return summary
+}
+
+func hasModules(graph *models.Graph) bool {
+	for _, node := range graph.Nodes {
+		if node.Module != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func summaryMessage(nodes, maxNodes int, by string) string {
+	hint := summaryFetchHint
+	if by == SummarizeByType {
+		hint = "fetch the neighborhood of a resource from /graph/subgraph?graph=<summary digest>&root=<address>"
+	}
+
+	return fmt.Sprintf("graph has %d nodes, above the limit of %d; nodes are aggregated by %s. To see individual resources, %s, or raise ?max_nodes",
+		nodes, maxNodes, by, hint)
+}
@@ -0,0 +1,119 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func modularGraph() *models.Graph {
+	return &models.Graph{
+		Nodes: []models.Node{
+			{ID: "aws_vpc.main", Type: "aws_vpc", Mode: "managed"},
+			{ID: "module.app.aws_instance.web[0]", Type: "aws_instance", Mode: "managed", Module: "module.app"},
+			{ID: "module.app.aws_instance.web[1]", Type: "aws_instance", Mode: "managed", Module: "module.app"},
+			{ID: "module.app.aws_eip.web", Type: "aws_eip", Mode: "managed", Module: "module.app"},
+			{ID: "module.db.aws_db_instance.main", Type: "aws_db_instance", Mode: "managed", Module: "module.db"},
+		},
+		Edges: []models.Edge{
+			{Source: "module.app.aws_instance.web[0]", Target: "aws_vpc.main", Type: "implicit"},
+			{Source: "module.app.aws_instance.web[1]", Target: "aws_vpc.main", Type: "implicit"},
+			{Source: "module.app.aws_eip.web", Target: "module.app.aws_instance.web[0]", Type: "implicit"},
+			{Source: "module.db.aws_db_instance.main", Target: "aws_vpc.main", Type: "depends_on"},
+			{Source: "module.db.aws_db_instance.main", Target: "aws_subnet.gone", Type: "implicit"},
+		},
+		Warnings: []models.Warning{{Type: "check", Message: "check failed"}},
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	t.Run("by module", func(t *testing.T) {
+		summary := Summarize(modularGraph(), SummarizeByModule, 3)
+
+		assert.True(t, summary.Summarized)
+		assert.Equal(t, []models.Node{
+			{ID: "module.app", Type: "module", Mode: "summary", Module: "module.app", Metadata: map[string]any{"node_count": 3}},
+			{ID: "module.db", Type: "module", Mode: "summary", Module: "module.db", Metadata: map[string]any{"node_count": 1}},
+			{ID: "root", Type: "module", Mode: "summary", Metadata: map[string]any{"node_count": 1}},
+		}, summary.Nodes)
+		assert.Equal(t, []models.Edge{
+			{Source: "module.app", Target: "root", Type: "aggregate", Metadata: map[string]any{
+				"edge_count": 2,
+				"edge_types": map[string]int{"implicit": 2},
+				"sample_edges": []models.Edge{
+					{Source: "module.app.aws_instance.web[0]", Target: "aws_vpc.main", Type: "implicit"},
+					{Source: "module.app.aws_instance.web[1]", Target: "aws_vpc.main", Type: "implicit"},
+				},
+			}},
+			{Source: "module.db", Target: "root", Type: "aggregate", Metadata: map[string]any{
+				"edge_count": 1,
+				"edge_types": map[string]int{"depends_on": 1},
+				"sample_edges": []models.Edge{
+					{Source: "module.db.aws_db_instance.main", Target: "aws_vpc.main", Type: "depends_on"},
+				},
+			}},
+		}, summary.Edges)
+		assert.Equal(t, []models.Warning{{Type: "check", Message: "check failed"}}, summary.Warnings)
+	})
+
+	t.Run("bundles keep a few sample edges", func(t *testing.T) {
+		graph := &models.Graph{
+			Nodes: []models.Node{{ID: "aws_vpc.main"}},
+		}
+		for i := range 5 {
+			id := fmt.Sprintf("module.app.aws_instance.web[%d]", i)
+			graph.Nodes = append(graph.Nodes, models.Node{ID: id, Module: "module.app"})
+			graph.Edges = append(graph.Edges, models.Edge{Source: id, Target: "aws_vpc.main", Type: "implicit"})
+		}
+
+		summary := Summarize(graph, SummarizeByModule, 1)
+
+		require.Len(t, summary.Edges, 1)
+		assert.Equal(t, 5, summary.Edges[0].Metadata["edge_count"])
+		assert.Len(t, summary.Edges[0].Metadata["sample_edges"], bundleSamples)
+	})
+
+	t.Run("summary explains the aggregation", func(t *testing.T) {
+		summary := Summarize(modularGraph(), SummarizeByModule, 3)
+
+		require.NotNil(t, summary.Summary)
+		assert.Equal(t, "module", summary.Summary.By)
+		assert.Equal(t, 5, summary.Summary.TotalNodes)
+		assert.Equal(t, 5, summary.Summary.TotalEdges)
+		assert.Equal(t, 3, summary.Summary.MaxNodes)
+		assert.Contains(t, summary.Summary.Message, "graph has 5 nodes, above the limit of 3")
+		assert.Contains(t, summary.Summary.Message, "?module=")
+	})
+
+	t.Run("by type", func(t *testing.T) {
+		summary := Summarize(modularGraph(), SummarizeByType, 3)
+
+		ids := []string{}
+		for _, node := range summary.Nodes {
+			ids = append(ids, node.ID)
+		}
+
+		assert.Equal(t, []string{"aws_db_instance", "aws_eip", "aws_instance", "aws_vpc"}, ids)
+		assert.Equal(t, map[string]any{"node_count": 2}, summary.Nodes[2].Metadata)
+		assert.Len(t, summary.Edges, 3)
+	})
+
+	t.Run("graphs without modules fall back to types", func(t *testing.T) {
+		graph := &models.Graph{
+			Nodes: []models.Node{
+				{ID: "aws_vpc.main", Type: "aws_vpc"},
+				{ID: "aws_subnet.a", Type: "aws_subnet"},
+			},
+		}
+
+		summary := Summarize(graph, SummarizeByModule, 1)
+
+		assert.Equal(t, "type", summary.Summary.By)
+		assert.Len(t, summary.Nodes, 2)
+	})
+}
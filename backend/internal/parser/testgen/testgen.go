@@ -0,0 +1,238 @@
+// Package testgen fabricates realistic synthetic Terraform states of a chosen
+// size and module layout, for load tests, demos and frontend development
+// without real infrastructure data.
+package testgen
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+const awsProvider = `provider["registry.terraform.io/hashicorp/aws"]`
+
+// Options select the size and topology of a generated state. Resources are
+// spread evenly over the root module and Modules child modules. The same
+// options always generate the same state.
+type Options struct {
+	Resources int
+	Modules   int
+	Seed      uint64
+}
+
+// blueprint describes a resource type in a generated stack: its name, the
+// types of the resources it depends on, and how its attributes reference them.
+type blueprint struct {
+	resType string
+	name    string
+	deps    []string
+	attrs   func(g *generator, refs map[string]string) map[string]any
+}
+
+// stack is the order in which each module creates resources. Every module
+// starts with a network and then repeats the stack, so the dependencies of a
+// resource always exist in its module.
+var stack = []blueprint{
+	{"aws_vpc", "main", nil, func(g *generator, _ map[string]string) map[string]any {
+		return map[string]any{"cidr_block": fmt.Sprintf("10.%d.0.0/16", g.rng.IntN(256))}
+	}},
+	{"aws_subnet", "private", []string{"aws_vpc"}, func(g *generator, refs map[string]string) map[string]any {
+		return map[string]any{
+			"vpc_id":            refs["aws_vpc"],
+			"cidr_block":        fmt.Sprintf("10.%d.%d.0/24", g.rng.IntN(256), g.rng.IntN(256)),
+			"availability_zone": "eu-west-1" + string(rune('a'+g.rng.IntN(3))),
+		}
+	}},
+	{"aws_security_group", "app", []string{"aws_vpc"}, func(_ *generator, refs map[string]string) map[string]any {
+		return map[string]any{"vpc_id": refs["aws_vpc"]}
+	}},
+	{"aws_instance", "app", []string{"aws_subnet", "aws_security_group"}, func(g *generator, refs map[string]string) map[string]any {
+		return map[string]any{
+			"subnet_id":              refs["aws_subnet"],
+			"vpc_security_group_ids": []any{refs["aws_security_group"]},
+			"instance_type":          pick(g, "t3.micro", "t3.small", "t3.medium", "m5.large", "c5.large"),
+			"ami":                    fmt.Sprintf("ami-%017x", g.rng.Uint64()>>4),
+		}
+	}},
+	{"aws_lb", "app", []string{"aws_subnet", "aws_security_group"}, func(g *generator, refs map[string]string) map[string]any {
+		return map[string]any{
+			"subnets":         []any{refs["aws_subnet"]},
+			"security_groups": []any{refs["aws_security_group"]},
+			"internal":        g.rng.IntN(2) == 0,
+		}
+	}},
+	{"aws_db_instance", "main", []string{"aws_subnet", "aws_security_group"}, func(g *generator, refs map[string]string) map[string]any {
+		return map[string]any{
+			"vpc_security_group_ids": []any{refs["aws_security_group"]},
+			"instance_class":         pick(g, "db.t3.micro", "db.t3.medium", "db.m5.large"),
+			"engine":                 pick(g, "postgres", "mysql"),
+			"multi_az":               g.rng.IntN(2) == 0,
+		}
+	}},
+	{"aws_s3_bucket", "assets", nil, func(*generator, map[string]string) map[string]any {
+		return map[string]any{}
+	}},
+	{"aws_iam_role", "app", nil, func(*generator, map[string]string) map[string]any {
+		return map[string]any{"assume_role_policy": `{"Version":"2012-10-17","Statement":[]}`}
+	}},
+	{"aws_iam_role_policy_attachment", "app", []string{"aws_iam_role"}, func(_ *generator, refs map[string]string) map[string]any {
+		return map[string]any{"role": refs["aws_iam_role"]}
+	}},
+	{"aws_cloudwatch_log_group", "app", nil, func(g *generator, _ map[string]string) map[string]any {
+		return map[string]any{"retention_in_days": float64(pick(g, 7, 30, 90))}
+	}},
+}
+
+var moduleNames = []string{
+	"network", "api", "web", "worker", "billing", "auth", "search", "payments",
+	"analytics", "notifications", "reporting", "ingest",
+}
+
+type generator struct {
+	rng *rand.Rand
+}
+
+// pick returns one of the choices at random.
+func pick[T any](g *generator, choices ...T) T {
+	return choices[g.rng.IntN(len(choices))]
+}
+
+// Generate builds a state with opts.Resources managed resources of one
+// instance each, spread over the root module and opts.Modules child modules.
+func Generate(opts Options) *models.TerraformState {
+	g := &generator{rng: rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x9e3779b97f4a7c15))}
+
+	state := &models.TerraformState{
+		Version:          4,
+		TerraformVersion: "1.5.7",
+		Serial:           1,
+		Lineage:          fmt.Sprintf("demo-%016x", g.rng.Uint64()),
+		Resources:        make([]models.ResourceState, 0, opts.Resources),
+	}
+
+	modules := opts.Modules + 1
+	for m := 0; m < modules; m++ {
+		count := opts.Resources / modules
+		if m < opts.Resources%modules {
+			count++
+		}
+
+		state.Resources = append(state.Resources, g.module(moduleAddress(m), count)...)
+	}
+
+	return state
+}
+
+// moduleAddress names the m-th module; module 0 is the root module.
+func moduleAddress(m int) string {
+	if m == 0 {
+		return ""
+	}
+
+	name := moduleNames[(m-1)%len(moduleNames)]
+	if round := (m - 1) / len(moduleNames); round > 0 {
+		name = fmt.Sprintf("%s_%d", name, round+1)
+	}
+
+	return "module." + name
+}
+
+// module generates count resources of the stack in the module, each depending
+// on the latest resources of its dependency types.
+func (g *generator) module(module string, count int) []models.ResourceState {
+	resources := make([]models.ResourceState, 0, count)
+	ids := make(map[string]string)
+	addresses := make(map[string]string)
+
+	for i := 0; i < count; i++ {
+		bp := stack[i%len(stack)]
+
+		name := bp.name
+		if round := i / len(stack); round > 0 {
+			name = fmt.Sprintf("%s_%d", bp.name, round+1)
+		}
+
+		address := bp.resType + "." + name
+		if module != "" {
+			address = module + "." + address
+		}
+
+		refs := make(map[string]string, len(bp.deps))
+		var deps []string
+		for _, dep := range bp.deps {
+			refs[dep] = ids[dep]
+			deps = append(deps, addresses[dep])
+		}
+
+		id := g.resourceID(bp.resType, name)
+		attrs := bp.attrs(g, refs)
+		attrs["id"] = id
+		attrs["arn"] = fmt.Sprintf("arn:aws:%s:eu-west-1:123456789012:%s", service(bp.resType), id)
+		attrs["tags"] = map[string]any{"Environment": "demo", "Module": moduleTag(module)}
+		if _, ok := attrs["name"]; !ok && bp.resType != "aws_iam_role_policy_attachment" {
+			attrs["name"] = fmt.Sprintf("%s-%s", moduleTag(module), name)
+		}
+
+		res := models.ResourceState{
+			Mode:     "managed",
+			Type:     bp.resType,
+			Name:     name,
+			Provider: awsProvider,
+			Module:   module,
+			Instances: []models.ResourceInstance{{
+				SchemaVersion: 1,
+				Attributes:    attrs,
+				Dependencies:  deps,
+			}},
+		}
+
+		ids[bp.resType] = id
+		addresses[bp.resType] = address
+		resources = append(resources, res)
+	}
+
+	return resources
+}
+
+var idPrefixes = map[string]string{
+	"aws_vpc":            "vpc-",
+	"aws_subnet":         "subnet-",
+	"aws_security_group": "sg-",
+	"aws_instance":       "i-",
+}
+
+// resourceID fabricates a cloud ID in the format of the resource type.
+func (g *generator) resourceID(resType, name string) string {
+	if prefix, ok := idPrefixes[resType]; ok {
+		return fmt.Sprintf("%s%017x", prefix, g.rng.Uint64()>>4)
+	}
+
+	return fmt.Sprintf("%s-%08x", name, g.rng.Uint32())
+}
+
+// service returns the ARN service of a resource type, such as ec2 or s3.
+func service(resType string) string {
+	switch resType {
+	case "aws_db_instance":
+		return "rds"
+	case "aws_s3_bucket":
+		return "s3"
+	case "aws_iam_role", "aws_iam_role_policy_attachment":
+		return "iam"
+	case "aws_lb":
+		return "elasticloadbalancing"
+	case "aws_cloudwatch_log_group":
+		return "logs"
+	default:
+		return "ec2"
+	}
+}
+
+func moduleTag(module string) string {
+	if module == "" {
+		return "root"
+	}
+
+	return module[len("module."):]
+}
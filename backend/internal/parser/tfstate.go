@@ -19,13 +19,21 @@ func ParseTfstate(data []byte) (*models.TerraformState, error) {
 		return nil, fmt.Errorf("failed to unmarshal tfstate: %w", err)
 	}
 
-	if state.Version == 0 {
-		return nil, fmt.Errorf("invalid tfstate: missing version field")
+	if err := validateHeader(state.Version, state.TerraformVersion); err != nil {
+		return nil, err
 	}
 
-	if state.TerraformVersion == "" {
-		return nil, fmt.Errorf("invalid tfstate: missing terraform_version field")
+	return &state, nil
+}
+
+func validateHeader(version int, terraformVersion string) error {
+	if version == 0 {
+		return fmt.Errorf("invalid tfstate: missing version field")
 	}
 
-	return &state, nil
+	if terraformVersion == "" {
+		return fmt.Errorf("invalid tfstate: missing terraform_version field")
+	}
+
+	return nil
 }
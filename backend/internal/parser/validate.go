@@ -0,0 +1,149 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/terrascope/core/internal/codes"
+	"github.com/terrascope/core/internal/models"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+
+	supportedStateVersion = 4
+)
+
+// Validate checks the structure of a tfstate document without building a
+// graph: JSON schema, state format version, required resource fields,
+// duplicate resource and instance addresses, and dependencies that point to
+// resources missing from the state. Dependencies may use either the resource
+// address or its configuration address, as Terraform records the latter. The
+// report is valid when no issue has error severity.
+func Validate(data []byte) *models.ValidationReport {
+	report := &models.ValidationReport{Issues: []models.ValidationIssue{}}
+	issue := func(severity, issueType, address, message string) {
+		report.Issues = append(report.Issues, models.ValidationIssue{
+			Severity: severity,
+			Code:     codes.ForValidation(issueType),
+			Type:     issueType,
+			Address:  address,
+			Message:  message,
+		})
+	}
+
+	if len(data) == 0 {
+		issue(SeverityError, "empty_document", "", "tfstate data is empty")
+		return report
+	}
+
+	var state models.TerraformState
+	if err := json.Unmarshal(data, &state); err != nil {
+		issue(SeverityError, "invalid_json", "", err.Error())
+		return report
+	}
+
+	report.Version = state.Version
+	report.TerraformVersion = state.TerraformVersion
+	report.Serial = state.Serial
+	report.Lineage = state.Lineage
+	report.Resources = len(state.Resources)
+
+	switch {
+	case state.Version == 0:
+		issue(SeverityError, "missing_version", "", "missing version field")
+	case state.Version != supportedStateVersion:
+		issue(SeverityError, "unsupported_version", "", fmt.Sprintf("state format version %d is not supported (expected %d)", state.Version, supportedStateVersion))
+	}
+
+	if state.TerraformVersion == "" {
+		issue(SeverityError, "missing_terraform_version", "", "missing terraform_version field")
+	}
+
+	if state.Lineage == "" {
+		issue(SeverityWarning, "missing_lineage", "", "missing lineage field; successive versions of this state cannot be correlated")
+	}
+
+	addresses := make(map[string]bool, len(state.Resources))
+	targets := make(map[string]bool, len(state.Resources))
+	for _, res := range state.Resources {
+		address := ResourceAddress(res)
+		report.Instances += len(res.Instances)
+
+		if res.Mode != "managed" && res.Mode != "data" {
+			issue(SeverityError, "invalid_mode", address, fmt.Sprintf("mode %q is neither managed nor data", res.Mode))
+		}
+
+		if res.Type == "" || res.Name == "" {
+			issue(SeverityError, "missing_field", address, "resource is missing its type or name")
+		}
+
+		if res.Provider == "" {
+			issue(SeverityError, "missing_provider", address, "resource is missing its provider")
+		}
+
+		if addresses[address] {
+			issue(SeverityError, "duplicate_address", address, "resource address appears more than once")
+		}
+		addresses[address] = true
+		targets[address] = true
+		targets[ConfigAddress(res)] = true
+
+		if len(res.Instances) == 0 {
+			issue(SeverityWarning, "no_instances", address, "resource has no instances")
+		}
+
+		validateInstances(res, issue)
+	}
+
+	for _, res := range state.Resources {
+		address := ResourceAddress(res)
+		deps := append([]string{}, res.DependsOn...)
+		for _, instance := range res.Instances {
+			deps = append(deps, instance.Dependencies...)
+		}
+
+		reported := make(map[string]bool)
+		for _, dep := range deps {
+			if !targets[dep] && !reported[dep] {
+				reported[dep] = true
+				issue(SeverityWarning, "dangling_reference", address, fmt.Sprintf("depends on %s, which is not in the state", dep))
+			}
+		}
+	}
+
+	report.Valid = true
+	for _, i := range report.Issues {
+		if i.Severity == SeverityError {
+			report.Valid = false
+			break
+		}
+	}
+
+	return report
+}
+
+func validateInstances(res models.ResourceState, issue func(severity, issueType, address, message string)) {
+	seen := make(map[string]bool, len(res.Instances))
+
+	for _, instance := range res.Instances {
+		address := InstanceAddress(res, instance) + deposedSuffix(instance)
+
+		if currentInstances(res) > 1 && instance.Deposed == "" && indexKeyValue(instance.IndexKey) == nil {
+			issue(SeverityError, "missing_index_key", address, "resource has several instances but this one has no index_key")
+			continue
+		}
+
+		if seen[address] {
+			issue(SeverityError, "duplicate_address", address, "instance address appears more than once")
+		}
+		seen[address] = true
+
+		if instance.Attributes == nil && instance.AttributesFlat == nil {
+			issue(SeverityWarning, "missing_attributes", address, "instance has no attributes")
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Package parser provides utilities for parsing and transforming input data.
+// It handles data normalization, validation, and conversion between formats.
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func issueTypes(report *models.ValidationReport) []string {
+	types := []string{}
+	for _, issue := range report.Issues {
+		types = append(types, issue.Type)
+	}
+
+	return types
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("valid state", func(t *testing.T) {
+		data := `{
+			"version": 4,
+			"terraform_version": "1.5.0",
+			"serial": 3,
+			"lineage": "abc-123",
+			"resources": [
+				{
+					"mode": "managed",
+					"type": "aws_vpc",
+					"name": "main",
+					"module": "module.network[0]",
+					"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+					"instances": [{"attributes": {"id": "vpc-1"}}]
+				},
+				{
+					"mode": "managed",
+					"type": "aws_subnet",
+					"name": "a",
+					"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+					"instances": [
+						{"index_key": 0, "attributes": {"id": "subnet-0"}, "dependencies": ["module.network.aws_vpc.main"]},
+						{"index_key": 1, "attributes": {"id": "subnet-1"}, "dependencies": ["module.network[0].aws_vpc.main"]}
+					]
+				}
+			]
+		}`
+
+		report := Validate([]byte(data))
+
+		assert.True(t, report.Valid)
+		assert.Empty(t, report.Issues)
+		assert.Equal(t, 4, report.Version)
+		assert.Equal(t, 3, report.Serial)
+		assert.Equal(t, 2, report.Resources)
+		assert.Equal(t, 3, report.Instances)
+	})
+
+	t.Run("empty document", func(t *testing.T) {
+		report := Validate(nil)
+
+		assert.False(t, report.Valid)
+		assert.Equal(t, []string{"empty_document"}, issueTypes(report))
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		report := Validate([]byte(`{"version": "four"}`))
+
+		assert.False(t, report.Valid)
+		assert.Equal(t, []string{"invalid_json"}, issueTypes(report))
+	})
+
+	t.Run("missing header fields", func(t *testing.T) {
+		report := Validate([]byte(`{"resources": []}`))
+
+		assert.False(t, report.Valid)
+		assert.Equal(t, []string{"missing_version", "missing_terraform_version", "missing_lineage"}, issueTypes(report))
+		assert.Equal(t, "TS603", report.Issues[0].Code, "issues carry the code of their type")
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		report := Validate([]byte(`{"version": 3, "terraform_version": "0.11.14", "lineage": "x"}`))
+
+		assert.False(t, report.Valid)
+		assert.Equal(t, []string{"unsupported_version"}, issueTypes(report))
+	})
+
+	t.Run("missing lineage is only a warning", func(t *testing.T) {
+		report := Validate([]byte(`{"version": 4, "terraform_version": "1.5.0"}`))
+
+		assert.True(t, report.Valid)
+		require.Len(t, report.Issues, 1)
+		assert.Equal(t, "warning", report.Issues[0].Severity)
+	})
+
+	t.Run("resource field errors", func(t *testing.T) {
+		data := `{
+			"version": 4,
+			"terraform_version": "1.5.0",
+			"lineage": "x",
+			"resources": [
+				{"mode": "import", "type": "aws_vpc", "name": "main", "provider": "p", "instances": [{"attributes": {}}]},
+				{"mode": "managed", "type": "", "name": "", "instances": [{"attributes": {}}]},
+				{"mode": "managed", "type": "aws_s3_bucket", "name": "empty", "provider": "p", "instances": []}
+			]
+		}`
+
+		report := Validate([]byte(data))
+
+		assert.False(t, report.Valid)
+		assert.Equal(t, []string{"invalid_mode", "missing_field", "missing_provider", "no_instances"}, issueTypes(report))
+		assert.Equal(t, "aws_vpc.main", report.Issues[0].Address)
+	})
+
+	t.Run("duplicate addresses", func(t *testing.T) {
+		data := `{
+			"version": 4,
+			"terraform_version": "1.5.0",
+			"lineage": "x",
+			"resources": [
+				{"mode": "managed", "type": "aws_vpc", "name": "main", "provider": "p", "instances": [{"attributes": {}}]},
+				{"mode": "managed", "type": "aws_vpc", "name": "main", "provider": "p", "instances": [{"attributes": {}}]},
+				{"mode": "managed", "type": "aws_subnet", "name": "a", "provider": "p", "instances": [
+					{"index_key": "a", "attributes": {}},
+					{"index_key": "a", "attributes": {}},
+					{"attributes": {}}
+				]}
+			]
+		}`
+
+		report := Validate([]byte(data))
+
+		assert.False(t, report.Valid)
+		assert.Equal(t, []string{"duplicate_address", "duplicate_address", "missing_index_key"}, issueTypes(report))
+		assert.Equal(t, `aws_subnet.a["a"]`, report.Issues[1].Address)
+	})
+
+	t.Run("dangling references and missing attributes are warnings", func(t *testing.T) {
+		data := `{
+			"version": 4,
+			"terraform_version": "1.5.0",
+			"lineage": "x",
+			"resources": [
+				{"mode": "managed", "type": "aws_subnet", "name": "a", "provider": "p", "depends_on": ["aws_vpc.gone"],
+				 "instances": [{"dependencies": ["aws_vpc.gone", "data.aws_ami.gone"]}]}
+			]
+		}`
+
+		report := Validate([]byte(data))
+
+		assert.True(t, report.Valid)
+		assert.Equal(t, []string{"missing_attributes", "dangling_reference", "dangling_reference"}, issueTypes(report))
+		assert.Contains(t, report.Issues[1].Message, "aws_vpc.gone")
+	})
+}
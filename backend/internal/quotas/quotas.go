@@ -0,0 +1,305 @@
+// Package quotas accounts for the usage of each project and checks it against
+// the configured quotas.
+package quotas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// countersTimeout bounds the calls to shared counters.
+const countersTimeout = 2 * time.Second
+
+// Meter records the monthly usage of projects. Parses and job minutes reset at
+// the start of each month, in UTC; stored bytes are measured by the caller,
+// since they only go down when states are deleted.
+type Meter struct {
+	mu       sync.Mutex
+	config   models.QuotaConfig
+	usage    map[string]*monthlyUsage
+	counters Counters
+}
+
+// Counters hold the usage of every replica of a deployment, such as a
+// cache.Shared.
+type Counters interface {
+	// Incr adds delta to the counter at key and returns its value. A new
+	// counter starts at 0 and expires at expiresAt.
+	Incr(ctx context.Context, key string, delta int64, expiresAt time.Time) (int64, error)
+}
+
+type monthlyUsage struct {
+	period string
+	usage  models.Usage
+}
+
+// New returns a meter that enforces the config.
+func New(config models.QuotaConfig) *Meter {
+	return &Meter{config: config, usage: make(map[string]*monthlyUsage)}
+}
+
+// NewShared returns a meter that enforces the config on the usage of every
+// replica, kept in the counters. Should they fail, the usage of a project
+// reads as zero until they are back, rather than blocking every request.
+func NewShared(config models.QuotaConfig, counters Counters) *Meter {
+	meter := New(config)
+	meter.counters = counters
+
+	return meter
+}
+
+// FromEnv loads the config file named by TERRASCOPE_QUOTAS_CONFIG, and keeps
+// usage in the counters unless they are nil. Usage is unlimited when the
+// variable is unset; an invalid file stops the server rather than lifting
+// every quota.
+func FromEnv(counters Counters) *Meter {
+	file := os.Getenv("TERRASCOPE_QUOTAS_CONFIG")
+	if file == "" {
+		return NewShared(models.QuotaConfig{}, counters)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		log.Fatalf("Reading TERRASCOPE_QUOTAS_CONFIG: %v", err)
+	}
+
+	var config models.QuotaConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		log.Fatalf("Reading TERRASCOPE_QUOTAS_CONFIG: %v", err)
+	}
+
+	return NewShared(config, counters)
+}
+
+// Period names the month of the time, e.g. 2026-10.
+func Period(now time.Time) string {
+	return now.UTC().Format("2006-01")
+}
+
+// ResetsAt returns when the monthly usage of the time resets.
+func ResetsAt(now time.Time) time.Time {
+	year, month, _ := now.UTC().Date()
+	return time.Date(year, month+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Quota returns the limits of the project.
+func (m *Meter) Quota(project string) models.Usage {
+	if quota, ok := m.config.Projects[project]; ok {
+		return quota
+	}
+
+	return m.config.Default
+}
+
+// Known reports whether the project has a quota of its own.
+func (m *Meter) Known(project string) bool {
+	_, ok := m.config.Projects[project]
+	return ok
+}
+
+// ExceededError reports the quota a request would exceed.
+type ExceededError struct {
+	Project string
+	// Resource is parses, stored bytes or job minutes.
+	Resource string
+	Used     string
+	Limit    string
+	// ResetsAt is when the usage resets, or zero for stored bytes.
+	ResetsAt time.Time
+}
+
+func (e *ExceededError) Error() string {
+	msg := fmt.Sprintf("quota exceeded for project %s: %s of %s %s used", e.Project, e.Used, e.Limit, e.Resource)
+	if !e.ResetsAt.IsZero() {
+		msg += fmt.Sprintf(" this month, resets at %s", e.ResetsAt.Format(time.RFC3339))
+	}
+
+	return msg
+}
+
+// Check returns an *ExceededError when the project cannot use what it needs
+// on top of its usage, with stored the bytes it stores now. Job minutes are
+// only known once a job has run, so jobs are refused once the project has
+// used up its minutes.
+func (m *Meter) Check(project string, need models.Usage, stored int64, now time.Time) error {
+	return m.exceeded(project, need, m.current(project, now), stored, now)
+}
+
+// Reserve checks the project's usage as Check does and, when the project may
+// use what it needs, records the parses at once, so that concurrent requests
+// cannot all pass the check before any of them is recorded.
+func (m *Meter) Reserve(project string, need models.Usage, stored int64, now time.Time) error {
+	reserved := models.Usage{Parses: need.Parses}
+
+	if m.counters == nil {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if err := m.exceeded(project, need, m.local(project, now), stored, now); err != nil {
+			return err
+		}
+
+		m.add(project, reserved, now)
+		return nil
+	}
+
+	// The parses are counted before the check, so that concurrent requests
+	// on every replica see each other's, and taken back when they do not
+	// fit.
+	used := m.count(project, reserved, now)
+	used.Parses -= reserved.Parses
+
+	if err := m.exceeded(project, need, used, stored, now); err != nil {
+		m.count(project, models.Usage{Parses: -reserved.Parses}, now)
+		return err
+	}
+
+	m.mu.Lock()
+	m.add(project, reserved, now)
+	m.mu.Unlock()
+
+	return nil
+}
+
+// Record adds the parses and job minutes to the project's usage this month.
+func (m *Meter) Record(project string, used models.Usage, now time.Time) {
+	m.mu.Lock()
+	m.add(project, used, now)
+	m.mu.Unlock()
+
+	if m.counters != nil {
+		m.count(project, used, now)
+	}
+}
+
+// Usage returns the usage of the project this month against its quota, with
+// stored the bytes it stores now.
+func (m *Meter) Usage(project string, stored int64, now time.Time) models.ProjectUsage {
+	usage := m.current(project, now)
+	usage.StoredBytes = stored
+
+	return models.ProjectUsage{
+		Project:  project,
+		Period:   Period(now),
+		Usage:    usage,
+		Quota:    m.Quota(project),
+		ResetsAt: ResetsAt(now),
+	}
+}
+
+// exceeded returns an *ExceededError when the project cannot use what it
+// needs on top of what it used.
+func (m *Meter) exceeded(project string, need, used models.Usage, stored int64, now time.Time) error {
+	quota := m.Quota(project)
+	resets := ResetsAt(now)
+
+	switch {
+	case quota.Parses > 0 && need.Parses > 0 && used.Parses+need.Parses > quota.Parses:
+		return &ExceededError{project, "parses", fmt.Sprint(used.Parses), fmt.Sprint(quota.Parses), resets}
+	case quota.StoredBytes > 0 && need.StoredBytes > 0 && stored+need.StoredBytes > quota.StoredBytes:
+		return &ExceededError{project, "stored bytes", fmt.Sprint(stored), fmt.Sprint(quota.StoredBytes), time.Time{}}
+	case quota.JobMinutes > 0 && need.JobMinutes > 0 && used.JobMinutes >= quota.JobMinutes:
+		return &ExceededError{project, "job minutes", fmt.Sprintf("%.1f", used.JobMinutes), fmt.Sprintf("%.1f", quota.JobMinutes), resets}
+	}
+
+	return nil
+}
+
+// add adds the parses and job minutes to the project's usage this month on
+// this replica. The caller holds m.mu.
+func (m *Meter) add(project string, used models.Usage, now time.Time) {
+	monthly, ok := m.usage[project]
+	if !ok || monthly.period != Period(now) {
+		monthly = &monthlyUsage{period: Period(now)}
+		m.usage[project] = monthly
+	}
+
+	monthly.usage.Parses += used.Parses
+	monthly.usage.JobMinutes += used.JobMinutes
+}
+
+// Projects returns the projects with a quota or usage this month, and the
+// given ones, ordered by name.
+func (m *Meter) Projects(now time.Time, others ...string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seen := make(map[string]bool)
+	for project := range m.config.Projects {
+		seen[project] = true
+	}
+	for project, monthly := range m.usage {
+		if monthly.period == Period(now) {
+			seen[project] = true
+		}
+	}
+	for _, project := range others {
+		seen[project] = true
+	}
+
+	projects := make([]string, 0, len(seen))
+	for project := range seen {
+		projects = append(projects, project)
+	}
+	sort.Strings(projects)
+
+	return projects
+}
+
+// current returns the usage of the project this month, which is zero until
+// the project uses something. The counters are read without holding m.mu,
+// so that a slow call does not hold up the requests of other projects.
+func (m *Meter) current(project string, now time.Time) models.Usage {
+	if m.counters != nil {
+		return m.count(project, models.Usage{}, now)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.local(project, now)
+}
+
+// local returns the usage of the project this month on this replica. The
+// caller holds m.mu.
+func (m *Meter) local(project string, now time.Time) models.Usage {
+	if monthly, ok := m.usage[project]; ok && monthly.period == Period(now) {
+		return monthly.usage
+	}
+
+	return models.Usage{}
+}
+
+// count adds the parses and job minutes to the project's counters this month
+// and returns its usage. Job minutes are counted in milliseconds. It does not
+// hold m.mu.
+func (m *Meter) count(project string, used models.Usage, now time.Time) models.Usage {
+	ctx, cancel := context.WithTimeout(context.Background(), countersTimeout)
+	defer cancel()
+
+	key := "terrascope:usage:" + Period(now) + ":" + project + ":"
+	resets := ResetsAt(now)
+
+	parses, err := m.counters.Incr(ctx, key+"parses", used.Parses, resets)
+	if err != nil {
+		log.Printf("Counting the usage of %s: %v", project, err)
+		return models.Usage{}
+	}
+
+	jobMillis, err := m.counters.Incr(ctx, key+"job-ms", int64(math.Round(used.JobMinutes*60000)), resets)
+	if err != nil {
+		log.Printf("Counting the usage of %s: %v", project, err)
+		return models.Usage{}
+	}
+
+	return models.Usage{Parses: parses, JobMinutes: float64(jobMillis) / 60000}
+}
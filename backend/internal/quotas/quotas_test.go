@@ -0,0 +1,187 @@
+// Package quotas accounts for the usage of each project and checks it against
+// the configured quotas.
+package quotas
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestMeter(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	config := models.QuotaConfig{
+		Default: models.Usage{Parses: 2, StoredBytes: 100, JobMinutes: 1},
+		Projects: map[string]models.Usage{
+			"platform": {},
+		},
+	}
+
+	t.Run("parses are limited per month", func(t *testing.T) {
+		m := New(config)
+
+		for range 2 {
+			require.NoError(t, m.Check("web", models.Usage{Parses: 1}, 0, now))
+			m.Record("web", models.Usage{Parses: 1}, now)
+		}
+
+		err := m.Check("web", models.Usage{Parses: 1}, 0, now)
+		var exceeded *ExceededError
+		require.ErrorAs(t, err, &exceeded)
+		assert.Equal(t, "quota exceeded for project web: 2 of 2 parses used this month, resets at 2026-11-01T00:00:00Z", err.Error())
+
+		assert.NoError(t, m.Check("web", models.Usage{Parses: 1}, 0, ResetsAt(now)))
+		assert.NoError(t, m.Check("api", models.Usage{Parses: 1}, 0, now), "projects have their own usage")
+	})
+
+	t.Run("projects with a quota of their own are known", func(t *testing.T) {
+		m := New(config)
+
+		assert.True(t, m.Known("platform"))
+		assert.False(t, m.Known("web"))
+	})
+
+	t.Run("stored bytes are limited at any time", func(t *testing.T) {
+		m := New(config)
+
+		assert.NoError(t, m.Check("web", models.Usage{StoredBytes: 40}, 60, now))
+
+		err := m.Check("web", models.Usage{StoredBytes: 41}, 60, now)
+		require.Error(t, err)
+		assert.Equal(t, "quota exceeded for project web: 60 of 100 stored bytes used", err.Error())
+	})
+
+	t.Run("jobs run until the minutes are used up", func(t *testing.T) {
+		m := New(config)
+
+		require.NoError(t, m.Check("web", models.Usage{JobMinutes: 1}, 0, now))
+		m.Record("web", models.Usage{JobMinutes: 1.5}, now)
+
+		assert.Error(t, m.Check("web", models.Usage{JobMinutes: 1}, 0, now))
+		assert.NoError(t, m.Check("web", models.Usage{Parses: 1}, 0, now))
+	})
+
+	t.Run("project overrides replace the default", func(t *testing.T) {
+		m := New(config)
+		m.Record("platform", models.Usage{Parses: 10}, now)
+
+		assert.NoError(t, m.Check("platform", models.Usage{Parses: 1, StoredBytes: 1000, JobMinutes: 1}, 1000, now))
+	})
+
+	t.Run("usage and projects", func(t *testing.T) {
+		m := New(config)
+		m.Record("web", models.Usage{Parses: 1, JobMinutes: 0.5}, now)
+		m.Record("old", models.Usage{Parses: 1}, now.AddDate(0, -1, 0))
+
+		usage := m.Usage("web", 60, now)
+		assert.Equal(t, models.ProjectUsage{
+			Project:  "web",
+			Period:   "2026-10",
+			Usage:    models.Usage{Parses: 1, StoredBytes: 60, JobMinutes: 0.5},
+			Quota:    config.Default,
+			ResetsAt: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		}, usage)
+
+		assert.Equal(t, []string{"api", "platform", "web"}, m.Projects(now, "api"))
+	})
+}
+
+// fakeCounters are counters in a map, which fail once broken.
+type fakeCounters struct {
+	mu      sync.Mutex
+	values  map[string]int64
+	expires map[string]time.Time
+	broken  bool
+}
+
+func (f *fakeCounters) Incr(_ context.Context, key string, delta int64, expiresAt time.Time) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.broken {
+		return 0, errors.New("connection refused")
+	}
+
+	if _, ok := f.values[key]; !ok {
+		f.expires[key] = expiresAt
+	}
+	f.values[key] += delta
+
+	return f.values[key], nil
+}
+
+func TestSharedMeter(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	config := models.QuotaConfig{Default: models.Usage{Parses: 2, JobMinutes: 1}}
+	counters := &fakeCounters{values: map[string]int64{}, expires: map[string]time.Time{}}
+
+	// Two replicas count against the same quota.
+	first, second := NewShared(config, counters), NewShared(config, counters)
+
+	first.Record("web", models.Usage{Parses: 1, JobMinutes: 0.25}, now)
+	second.Record("web", models.Usage{Parses: 1, JobMinutes: 0.5}, now)
+
+	assert.Error(t, first.Check("web", models.Usage{Parses: 1}, 0, now))
+	assert.Equal(t, models.Usage{Parses: 2, JobMinutes: 0.75}, second.Usage("web", 0, now).Usage)
+
+	assert.Equal(t, int64(45000), counters.values["terrascope:usage:2026-10:web:job-ms"])
+	assert.Equal(t, ResetsAt(now), counters.expires["terrascope:usage:2026-10:web:parses"])
+
+	t.Run("lets requests through when the counters fail", func(t *testing.T) {
+		counters.broken = true
+
+		assert.NoError(t, first.Check("web", models.Usage{Parses: 1}, 0, now))
+		assert.Equal(t, models.Usage{}, first.Usage("web", 0, now).Usage)
+	})
+}
+
+func TestReserve(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	config := models.QuotaConfig{Default: models.Usage{Parses: 10, StoredBytes: 100}}
+
+	reserve := func(m *Meter) int {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		allowed := 0
+
+		for range 50 {
+			wg.Go(func() {
+				if m.Reserve("web", models.Usage{Parses: 1}, 0, now) == nil {
+					mu.Lock()
+					allowed++
+					mu.Unlock()
+				}
+			})
+		}
+		wg.Wait()
+
+		return allowed
+	}
+
+	t.Run("concurrent requests do not overshoot the quota", func(t *testing.T) {
+		m := New(config)
+
+		assert.Equal(t, 10, reserve(m))
+		assert.EqualValues(t, 10, m.Usage("web", 0, now).Usage.Parses)
+	})
+
+	t.Run("nor do those of several replicas", func(t *testing.T) {
+		counters := &fakeCounters{values: map[string]int64{}, expires: map[string]time.Time{}}
+
+		assert.Equal(t, 10, reserve(NewShared(config, counters))+reserve(NewShared(config, counters)))
+		assert.Equal(t, int64(10), counters.values["terrascope:usage:2026-10:web:parses"], "refused parses are taken back")
+	})
+
+	t.Run("refused requests use nothing", func(t *testing.T) {
+		m := New(config)
+
+		assert.Error(t, m.Reserve("web", models.Usage{Parses: 1, StoredBytes: 50}, 60, now))
+		assert.Zero(t, m.Usage("web", 0, now).Usage.Parses)
+	})
+}
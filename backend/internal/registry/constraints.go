@@ -0,0 +1,95 @@
+// Package registry queries the Terraform registry protocol for published
+// module and provider versions and evaluates version constraints.
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+var constraintOperators = []string{">=", "<=", "!=", "~>", ">", "<", "="}
+
+// MatchesConstraint reports whether version satisfies a Terraform version
+// constraint string such as ">= 4.0, < 6.0" or "~> 5.1".
+func MatchesConstraint(version, constraint string) (bool, error) {
+	for _, part := range strings.Split(constraint, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		op := "="
+		for _, candidate := range constraintOperators {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				part = strings.TrimSpace(strings.TrimPrefix(part, candidate))
+				break
+			}
+		}
+
+		if !isVersion(part) {
+			return false, fmt.Errorf("invalid version constraint %q", constraint)
+		}
+
+		if !matches(version, op, part) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func matches(version, op, bound string) bool {
+	cmp := CompareVersions(version, bound)
+
+	switch op {
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	case "!=":
+		return cmp != 0
+	case "~>":
+		return cmp >= 0 && CompareVersions(version, pessimisticUpperBound(bound)) < 0
+	default:
+		return cmp == 0
+	}
+}
+
+// pessimisticUpperBound returns the exclusive upper bound of ~> bound: the
+// rightmost given component may grow, so ~> 1.2 allows < 2.0 and ~> 1.2.3
+// allows < 1.3.0.
+func pessimisticUpperBound(bound string) string {
+	fields := strings.Split(strings.TrimPrefix(bound, "v"), ".")
+	parts := versionParts(bound)
+
+	if len(fields) <= 2 {
+		return fmt.Sprintf("%d.0.0", parts[0]+1)
+	}
+
+	return fmt.Sprintf("%d.%d.0", parts[0], parts[1]+1)
+}
+
+func isVersion(s string) bool {
+	s = strings.TrimPrefix(s, "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+
+	fields := strings.Split(s, ".")
+	if len(fields) > 3 {
+		return false
+	}
+
+	for _, field := range fields {
+		if field == "" || strings.Trim(field, "0123456789") != "" {
+			return false
+		}
+	}
+
+	return true
+}
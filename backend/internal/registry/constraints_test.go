@@ -0,0 +1,55 @@
+// Package registry queries the Terraform registry protocol for published
+// module and provider versions and evaluates version constraints.
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchesConstraint(t *testing.T) {
+	tests := []struct {
+		version    string
+		constraint string
+		expected   bool
+	}{
+		{"5.31.0", ">= 4.0", true},
+		{"3.9.0", ">= 4.0", false},
+		{"5.31.0", ">= 4.0, < 5.0", false},
+		{"4.67.0", ">= 4.0, < 5.0", true},
+		{"5.31.0", "~> 5.0", true},
+		{"6.0.0", "~> 5.0", false},
+		{"5.1.9", "~> 5.1.2", true},
+		{"5.2.0", "~> 5.1.2", false},
+		{"5.1.1", "~> 5.1.2", false},
+		{"5.31.0", "5.31.0", true},
+		{"5.31.0", "= 5.30.0", false},
+		{"5.31.0", "!= 5.31.0", false},
+		{"5.31.0", "> 5.31.0", false},
+		{"5.31.0", "<= 5.31.0", true},
+		{"5.31.0", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version+" "+tt.constraint, func(t *testing.T) {
+			ok, err := MatchesConstraint(tt.version, tt.constraint)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ok)
+		})
+	}
+
+	t.Run("rejects malformed constraints", func(t *testing.T) {
+		_, err := MatchesConstraint("5.31.0", ">= five")
+
+		assert.ErrorContains(t, err, "invalid version constraint")
+	})
+}
+
+func TestPessimisticUpperBound(t *testing.T) {
+	assert.Equal(t, "2.0.0", pessimisticUpperBound("1"))
+	assert.Equal(t, "2.0.0", pessimisticUpperBound("1.2"))
+	assert.Equal(t, "1.3.0", pessimisticUpperBound("1.2.3"))
+}
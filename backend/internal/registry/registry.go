@@ -0,0 +1,321 @@
+// Package registry queries the Terraform registry protocol for published
+// module and provider versions and evaluates version constraints.
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	publicRegistryHost = "registry.terraform.io"
+	defaultBaseURL     = "https://" + publicRegistryHost
+	requestTimeout     = 10 * time.Second
+)
+
+// Lookups of published versions are cached across requests, failed ones
+// briefly so that an unreachable registry is retried soon.
+const (
+	lookupTTL        = time.Hour
+	failedLookupTTL  = time.Minute
+	maxCachedLookups = 10000
+)
+
+// ErrNotRegistrySource is returned for module sources that are not addresses
+// in the public registry, such as local paths, git URLs or private registries.
+var ErrNotRegistrySource = errors.New("not a public registry module source")
+
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+
+	mu      sync.Mutex
+	lookups map[string]lookup
+}
+
+// lookup is the cached result of listing the versions of a module or
+// provider.
+type lookup struct {
+	versions  []string
+	err       error
+	expiresAt time.Time
+}
+
+// NewClient returns a client for the public registry. TERRASCOPE_REGISTRY_URL
+// points it at a mirror instead.
+func NewClient() *Client {
+	baseURL := os.Getenv("TERRASCOPE_REGISTRY_URL")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+type moduleVersionsResponse struct {
+	Modules []versionsResponse `json:"modules"`
+}
+
+type versionsResponse struct {
+	Versions []struct {
+		Version string `json:"version"`
+	} `json:"versions"`
+}
+
+// LatestModuleVersion returns the newest stable version published for a
+// registry module source such as terraform-aws-modules/vpc/aws.
+func (c *Client) LatestModuleVersion(ctx context.Context, source string) (string, error) {
+	address, ok := ModuleAddress(source)
+	if !ok {
+		return "", ErrNotRegistrySource
+	}
+
+	versions, err := c.cached(ctx, "/v1/modules/"+address+"/versions", func() ([]string, error) {
+		var body moduleVersionsResponse
+		if err := c.get(ctx, "/v1/modules/"+address+"/versions", &body); err != nil {
+			return nil, err
+		}
+
+		var versions []string
+		for _, module := range body.Modules {
+			versions = append(versions, module.versions()...)
+		}
+
+		return versions, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return latestStable(address, versions)
+}
+
+// LatestProviderVersion returns the newest stable version published for a
+// provider address such as registry.terraform.io/hashicorp/aws.
+func (c *Client) LatestProviderVersion(ctx context.Context, address string) (string, error) {
+	namespaceType, ok := ProviderAddress(address)
+	if !ok {
+		return "", ErrNotRegistrySource
+	}
+
+	versions, err := c.cached(ctx, "/v1/providers/"+namespaceType+"/versions", func() ([]string, error) {
+		var body versionsResponse
+		if err := c.get(ctx, "/v1/providers/"+namespaceType+"/versions", &body); err != nil {
+			return nil, err
+		}
+
+		return body.versions(), nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return latestStable(namespaceType, versions)
+}
+
+// cached returns the versions listed at the registry path, fetching them
+// unless an earlier lookup of the path has not expired yet. Lookups cut short
+// by the caller's context are not cached.
+func (c *Client) cached(ctx context.Context, path string, fetch func() ([]string, error)) ([]string, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	cached, ok := c.lookups[path]
+	c.mu.Unlock()
+
+	if ok && now.Before(cached.expiresAt) {
+		return cached.versions, cached.err
+	}
+
+	versions, err := fetch()
+	if ctx.Err() != nil {
+		return versions, err
+	}
+
+	ttl := lookupTTL
+	if err != nil {
+		ttl = failedLookupTTL
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.lookups == nil {
+		c.lookups = make(map[string]lookup)
+	}
+	if len(c.lookups) >= maxCachedLookups {
+		for cachedPath, cached := range c.lookups {
+			if !now.Before(cached.expiresAt) {
+				delete(c.lookups, cachedPath)
+			}
+		}
+	}
+	if len(c.lookups) < maxCachedLookups {
+		c.lookups[path] = lookup{versions: versions, err: err, expiresAt: now.Add(ttl)}
+	}
+
+	return versions, err
+}
+
+func (c *Client) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build registry request: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("registry request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("failed to close registry response body: %v", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("registry returned %s for %s", resp.Status, path)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode registry response: %w", err)
+	}
+
+	return nil
+}
+
+func (r versionsResponse) versions() []string {
+	versions := make([]string, 0, len(r.Versions))
+	for _, v := range r.Versions {
+		versions = append(versions, v.Version)
+	}
+
+	return versions
+}
+
+func latestStable(address string, versions []string) (string, error) {
+	latest := ""
+	for _, version := range versions {
+		if isStable(version) && (latest == "" || CompareVersions(version, latest) > 0) {
+			latest = version
+		}
+	}
+
+	if latest == "" {
+		return "", fmt.Errorf("registry lists no stable versions for %s", address)
+	}
+
+	return latest, nil
+}
+
+// ModuleAddress extracts namespace/name/provider from a public registry module
+// source, dropping the optional host prefix and any //subdirectory.
+func ModuleAddress(source string) (string, bool) {
+	source, _, _ = strings.Cut(source, "//")
+	parts := strings.Split(source, "/")
+
+	if len(parts) == 4 {
+		if parts[0] != publicRegistryHost {
+			return "", false
+		}
+		parts = parts[1:]
+	}
+
+	if len(parts) != 3 {
+		return "", false
+	}
+
+	for _, part := range parts {
+		if !validSegment(part) {
+			return "", false
+		}
+	}
+
+	return strings.Join(parts, "/"), true
+}
+
+// ProviderAddress extracts namespace/type from a provider source address in
+// the public registry, e.g. registry.terraform.io/hashicorp/aws or
+// hashicorp/aws.
+func ProviderAddress(address string) (string, bool) {
+	parts := strings.Split(address, "/")
+
+	if len(parts) == 3 {
+		if parts[0] != publicRegistryHost {
+			return "", false
+		}
+		parts = parts[1:]
+	}
+
+	if len(parts) != 2 {
+		return "", false
+	}
+
+	for _, part := range parts {
+		if !validSegment(part) {
+			return "", false
+		}
+	}
+
+	return strings.Join(parts, "/"), true
+}
+
+// validSegment reports whether a part of a module or provider address can be
+// put in a registry URL path as is: it must not be empty, climb directories,
+// or end the path early.
+func validSegment(part string) bool {
+	return part != "" && part != "." && part != ".." && !strings.ContainsAny(part, ":.?#%\\")
+}
+
+// CompareVersions compares two semantic versions by their numeric
+// components, returning -1, 0 or 1. A leading v and any pre-release or build
+// suffix are ignored.
+func CompareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+
+	for i := 0; i < 3; i++ {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// MajorVersion returns the major component of a semantic version.
+func MajorVersion(version string) int {
+	return versionParts(version)[0]
+}
+
+func versionParts(version string) [3]int {
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+
+	var parts [3]int
+	for i, field := range strings.SplitN(version, ".", 3) {
+		parts[i], _ = strconv.Atoi(field)
+	}
+
+	return parts
+}
+
+func isStable(version string) bool {
+	return version != "" && !strings.ContainsAny(version, "-+")
+}
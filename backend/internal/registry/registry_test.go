@@ -0,0 +1,216 @@
+// Package registry queries the Terraform registry protocol for published
+// module and provider versions and evaluates version constraints.
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testServer(t *testing.T, status int, body string) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/modules/terraform-aws-modules/vpc/aws/versions", r.URL.Path)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return &Client{BaseURL: server.URL, HTTPClient: server.Client()}
+}
+
+func TestLatestModuleVersion(t *testing.T) {
+	t.Run("returns the newest stable version", func(t *testing.T) {
+		client := testServer(t, http.StatusOK, `{"modules": [{"versions": [
+			{"version": "5.1.0"}, {"version": "5.10.0"}, {"version": "5.2.3"}, {"version": "6.0.0-beta1"}
+		]}]}`)
+
+		latest, err := client.LatestModuleVersion(context.Background(), "registry.terraform.io/terraform-aws-modules/vpc/aws")
+
+		require.NoError(t, err)
+		assert.Equal(t, "5.10.0", latest)
+	})
+
+	t.Run("reports registry errors", func(t *testing.T) {
+		client := testServer(t, http.StatusNotFound, `{"errors": ["Not Found"]}`)
+
+		_, err := client.LatestModuleVersion(context.Background(), "terraform-aws-modules/vpc/aws")
+
+		assert.ErrorContains(t, err, "404")
+	})
+
+	t.Run("reports modules without stable versions", func(t *testing.T) {
+		client := testServer(t, http.StatusOK, `{"modules": [{"versions": [{"version": "1.0.0-rc1"}]}]}`)
+
+		_, err := client.LatestModuleVersion(context.Background(), "terraform-aws-modules/vpc/aws")
+
+		assert.ErrorContains(t, err, "no stable versions")
+	})
+
+	t.Run("rejects non-registry sources without a request", func(t *testing.T) {
+		client := &Client{BaseURL: "http://127.0.0.1:0", HTTPClient: http.DefaultClient}
+
+		_, err := client.LatestModuleVersion(context.Background(), "./modules/vpc")
+
+		assert.ErrorIs(t, err, ErrNotRegistrySource)
+	})
+}
+
+func TestLatestProviderVersion(t *testing.T) {
+	t.Run("returns the newest stable version", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v1/providers/hashicorp/aws/versions", r.URL.Path)
+			_, _ = w.Write([]byte(`{"versions": [{"version": "4.67.0"}, {"version": "5.31.0"}, {"version": "6.0.0-beta1"}]}`))
+		}))
+		defer server.Close()
+
+		client := &Client{BaseURL: server.URL, HTTPClient: server.Client()}
+		latest, err := client.LatestProviderVersion(context.Background(), "registry.terraform.io/hashicorp/aws")
+
+		require.NoError(t, err)
+		assert.Equal(t, "5.31.0", latest)
+	})
+
+	t.Run("rejects providers outside the public registry", func(t *testing.T) {
+		client := &Client{BaseURL: "http://127.0.0.1:0", HTTPClient: http.DefaultClient}
+
+		_, err := client.LatestProviderVersion(context.Background(), "terraform.example.com/acme/custom")
+
+		assert.ErrorIs(t, err, ErrNotRegistrySource)
+	})
+}
+
+func TestLookupCache(t *testing.T) {
+	calls := 0
+	status := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"versions": [{"version": "5.31.0"}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := &Client{BaseURL: server.URL, HTTPClient: server.Client()}
+
+	for range 2 {
+		_, err := client.LatestProviderVersion(context.Background(), "hashicorp/aws")
+		assert.ErrorContains(t, err, "500")
+	}
+	assert.Equal(t, 1, calls, "failed lookups are cached briefly")
+
+	status = http.StatusOK
+	client.lookups["/v1/providers/hashicorp/aws/versions"] = lookup{}
+
+	for range 2 {
+		latest, err := client.LatestProviderVersion(context.Background(), "hashicorp/aws")
+		require.NoError(t, err)
+		assert.Equal(t, "5.31.0", latest)
+	}
+	assert.Equal(t, 2, calls, "lookups are cached across calls")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.LatestProviderVersion(ctx, "hashicorp/google")
+	require.Error(t, err)
+	assert.NotContains(t, client.lookups, "/v1/providers/hashicorp/google/versions", "canceled lookups are not cached")
+}
+
+func TestProviderAddress(t *testing.T) {
+	tests := []struct {
+		address  string
+		expected string
+		ok       bool
+	}{
+		{"registry.terraform.io/hashicorp/aws", "hashicorp/aws", true},
+		{"hashicorp/aws", "hashicorp/aws", true},
+		{"terraform.example.com/acme/custom", "", false},
+		{"aws", "", false},
+		{"hashicorp/..", "", false},
+		{"../aws", "", false},
+		{"hashicorp/aws?page=2", "", false},
+		{"hashicorp/aws#latest", "", false},
+		{"hashicorp/%2e%2e", "", false},
+		{"localhost:8080/aws", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			address, ok := ProviderAddress(tt.address)
+
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, address)
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	t.Run("defaults to the public registry", func(t *testing.T) {
+		t.Setenv("TERRASCOPE_REGISTRY_URL", "")
+
+		assert.Equal(t, "https://registry.terraform.io", NewClient().BaseURL)
+	})
+
+	t.Run("honours a mirror URL", func(t *testing.T) {
+		t.Setenv("TERRASCOPE_REGISTRY_URL", "https://mirror.example.com/")
+
+		assert.Equal(t, "https://mirror.example.com", NewClient().BaseURL)
+	})
+}
+
+func TestModuleAddress(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+		ok       bool
+	}{
+		{"terraform-aws-modules/vpc/aws", "terraform-aws-modules/vpc/aws", true},
+		{"registry.terraform.io/terraform-aws-modules/vpc/aws", "terraform-aws-modules/vpc/aws", true},
+		{"terraform-aws-modules/iam/aws//modules/iam-role", "terraform-aws-modules/iam/aws", true},
+		{"app.terraform.io/acme/vpc/aws", "", false},
+		{"./modules/vpc", "", false},
+		{"../shared/vpc", "", false},
+		{"git::https://example.com/vpc.git", "", false},
+		{"github.com/acme/terraform-vpc", "", false},
+		{"terraform-aws-modules/vpc/aws#latest", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			address, ok := ModuleAddress(tt.source)
+
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, address)
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.2.0", "1.10.0", -1},
+		{"2.0.0", "1.99.99", 1},
+		{"v3.1", "3.1.0", 0},
+		{"1.0.0-beta", "1.0.0", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CompareVersions(tt.a, tt.b))
+		})
+	}
+}
+
+func TestMajorVersion(t *testing.T) {
+	assert.Equal(t, 5, MajorVersion("5.31.0"))
+	assert.Equal(t, 3, MajorVersion("v3.0.0-beta1"))
+	assert.Equal(t, 0, MajorVersion(""))
+}
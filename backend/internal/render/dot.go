@@ -0,0 +1,78 @@
+// Package render turns dependency graphs into static artifacts such as DOT
+// sources, standalone HTML pages, SVG images and SQLite databases.
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// DOT renders the graph as a Graphviz digraph, grouping the resources of each
+// module into a cluster. Explicit depends_on edges are drawn solid and
+// implicit references dashed.
+func DOT(graph *models.Graph) ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteString("digraph terraform {\n")
+	buf.WriteString("  rankdir=LR;\n")
+	buf.WriteString("  node [shape=box, style=rounded, fontname=\"Helvetica\"];\n")
+
+	byModule := make(map[string][]models.Node)
+	for _, node := range graph.Nodes {
+		byModule[node.Module] = append(byModule[node.Module], node)
+	}
+
+	modules := make([]string, 0, len(byModule))
+	for module := range byModule {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+
+	for i, module := range modules {
+		indent := "  "
+		if module != "" {
+			fmt.Fprintf(&buf, "  subgraph cluster_%d {\n", i)
+			fmt.Fprintf(&buf, "    label=%s;\n", dotQuote(module))
+			indent = "    "
+		}
+
+		for _, node := range byModule[module] {
+			fmt.Fprintf(&buf, "%s%s [label=%s];\n", indent, dotQuote(node.ID), dotQuote(node.Type+"\n"+nodeName(node)))
+		}
+
+		if module != "" {
+			buf.WriteString("  }\n")
+		}
+	}
+
+	for _, edge := range graph.Edges {
+		style := "solid"
+		if edge.Type != "depends_on" {
+			style = "dashed"
+		}
+
+		fmt.Fprintf(&buf, "  %s -> %s [style=%s];\n", dotQuote(edge.Source), dotQuote(edge.Target), style)
+	}
+
+	buf.WriteString("}\n")
+
+	return buf.Bytes(), nil
+}
+
+// nodeName returns the part of the node ID after its module and type.
+func nodeName(node models.Node) string {
+	name := strings.TrimPrefix(node.ID, node.Module)
+	name = strings.TrimPrefix(name, ".")
+
+	return strings.TrimPrefix(name, node.Type+".")
+}
+
+func dotQuote(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+	return `"` + replacer.Replace(s) + `"`
+}
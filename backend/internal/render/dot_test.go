@@ -0,0 +1,44 @@
+// Package render turns dependency graphs into static artifacts such as DOT
+// sources, standalone HTML pages, SVG images and SQLite databases.
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestDOT(t *testing.T) {
+	t.Run("renders nodes, module clusters and edges", func(t *testing.T) {
+		data, err := DOT(sampleGraph())
+		require.NoError(t, err)
+
+		dot := string(data)
+		assert.Contains(t, dot, "digraph terraform {")
+		assert.Contains(t, dot, `"aws_s3_bucket.logs" [label="aws_s3_bucket\nlogs"];`)
+		assert.Contains(t, dot, `label="module.network";`)
+		assert.Contains(t, dot, `    "module.network.aws_vpc.main" [label="aws_vpc\nmain"];`)
+		assert.Contains(t, dot, `"module.network.aws_subnet.a" -> "module.network.aws_vpc.main" [style=dashed];`)
+		assert.Contains(t, dot, `"aws_s3_bucket.logs" -> "module.network.aws_vpc.main" [style=solid];`)
+	})
+
+	t.Run("output is deterministic", func(t *testing.T) {
+		first, err := DOT(sampleGraph())
+		require.NoError(t, err)
+		second, err := DOT(sampleGraph())
+		require.NoError(t, err)
+
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("escapes quotes in IDs", func(t *testing.T) {
+		graph := &models.Graph{Nodes: []models.Node{{ID: `aws_subnet.private["a"]`, Type: "aws_subnet"}}}
+
+		data, err := DOT(graph)
+		require.NoError(t, err)
+
+		assert.Contains(t, string(data), `"aws_subnet.private[\"a\"]" [label="aws_subnet\nprivate[\"a\"]"];`)
+	})
+}
@@ -0,0 +1,51 @@
+// Package render turns dependency graphs into static artifacts such as DOT
+// sources, standalone HTML pages, SVG images and SQLite databases.
+package render
+
+import (
+	"bytes"
+	"html/template"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+var htmlTemplate = template.Must(template.New("graph").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Terraform dependency graph</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; margin: 2rem; color: #1f2933; }
+table { border-collapse: collapse; margin-bottom: 2rem; }
+th, td { border: 1px solid #cbd2d9; padding: 0.25rem 0.75rem; text-align: left; }
+th { background: #f5f7fa; }
+</style>
+</head>
+<body>
+<h1>Terraform dependency graph</h1>
+<p>{{len .Nodes}} resources, {{len .Edges}} dependencies</p>
+<h2>Resources</h2>
+<table>
+<tr><th>ID</th><th>Type</th><th>Provider</th><th>Module</th></tr>
+{{range .Nodes}}<tr><td>{{.ID}}</td><td>{{.Type}}</td><td>{{.Provider}}</td><td>{{.Module}}</td></tr>
+{{end}}</table>
+<h2>Dependencies</h2>
+<table>
+<tr><th>From</th><th>To</th><th>Type</th></tr>
+{{range .Edges}}<tr><td>{{.Source}}</td><td>{{.Target}}</td><td>{{.Type}}</td></tr>
+{{end}}</table>
+<script type="application/json" id="graph">{{.}}</script>
+</body>
+</html>
+`))
+
+// HTML renders a standalone page listing the resources and dependencies, with
+// the graph JSON embedded for scripts that want to draw it.
+func HTML(graph *models.Graph) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, graph); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,33 @@
+// Package render turns dependency graphs into static artifacts such as DOT
+// sources, standalone HTML pages, SVG images and SQLite databases.
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestHTML(t *testing.T) {
+	t.Run("lists resources and dependencies", func(t *testing.T) {
+		data, err := HTML(sampleGraph())
+		require.NoError(t, err)
+
+		page := string(data)
+		assert.Contains(t, page, "<p>3 resources, 2 dependencies</p>")
+		assert.Contains(t, page, "<td>module.network.aws_vpc.main</td>")
+		assert.Contains(t, page, `<script type="application/json" id="graph">{"nodes":`)
+	})
+
+	t.Run("escapes IDs", func(t *testing.T) {
+		graph := &models.Graph{Nodes: []models.Node{{ID: "x</script><b>", Type: "x"}}, Edges: []models.Edge{}}
+
+		data, err := HTML(graph)
+		require.NoError(t, err)
+
+		assert.NotContains(t, string(data), "x</script><b>")
+		assert.Contains(t, string(data), "<td>x&lt;/script&gt;&lt;b&gt;</td>")
+	})
+}
@@ -0,0 +1,240 @@
+// Package render turns dependency graphs into static artifacts such as DOT
+// sources, standalone HTML pages, SVG images and SQLite databases.
+package render
+
+import (
+	"sort"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+const (
+	charWidth      = 7
+	nodePadding    = 24
+	nodeHeight     = 40
+	minNodeWidth   = 80
+	layerGap       = 80
+	rowGap         = 20
+	margin         = 20
+	orderingPasses = 4
+)
+
+// Layout places the nodes of a graph in layers from left to right, with every
+// resource to the left of the resources that depend on it.
+type Layout struct {
+	Width  int
+	Height int
+	Nodes  []PlacedNode
+	Edges  []PlacedEdge
+}
+
+type PlacedNode struct {
+	Node   models.Node
+	X, Y   int
+	Width  int
+	Height int
+	Layer  int
+}
+
+type PlacedEdge struct {
+	Edge     models.Edge
+	From, To int
+}
+
+// LayoutGraph computes a layered layout: nodes are assigned to layers by
+// longest dependency path, ordered within each layer by the barycenter of
+// their neighbours to reduce crossings, then given coordinates. Edges to
+// nodes missing from the graph are dropped and cycles are broken arbitrarily
+// but deterministically.
+func LayoutGraph(graph *models.Graph) *Layout {
+	index := make(map[string]int, len(graph.Nodes))
+	ids := make([]string, 0, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		if _, ok := index[node.ID]; !ok {
+			index[node.ID] = len(ids)
+			ids = append(ids, node.ID)
+		}
+	}
+
+	order := make([]int, len(ids))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return ids[order[a]] < ids[order[b]] })
+
+	layout := &Layout{Nodes: make([]PlacedNode, len(ids))}
+	for _, node := range graph.Nodes {
+		if i := index[node.ID]; layout.Nodes[i].Node.ID == "" {
+			layout.Nodes[i].Node = node
+		}
+	}
+
+	dependencies := make([][]int, len(ids))
+	dependents := make([][]int, len(ids))
+	for _, edge := range graph.Edges {
+		from, okFrom := index[edge.Source]
+		to, okTo := index[edge.Target]
+		if !okFrom || !okTo || from == to {
+			continue
+		}
+
+		layout.Edges = append(layout.Edges, PlacedEdge{Edge: edge, From: from, To: to})
+		dependencies[from] = append(dependencies[from], to)
+		dependents[to] = append(dependents[to], from)
+	}
+
+	layers := assignLayers(order, dependencies)
+	rows := orderLayers(order, layers, dependencies, dependents)
+	layout.place(rows)
+
+	return layout
+}
+
+// assignLayers puts every node one layer after its deepest dependency. A
+// depth-first walk in ID order ignores edges that close a cycle.
+func assignLayers(order []int, dependencies [][]int) []int {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+
+	layers := make([]int, len(order))
+	states := make([]int, len(order))
+
+	var visit func(int)
+	visit = func(n int) {
+		states[n] = visiting
+		for _, dep := range dependencies[n] {
+			switch states[dep] {
+			case unvisited:
+				visit(dep)
+			case visiting:
+				continue
+			}
+			if layers[dep]+1 > layers[n] {
+				layers[n] = layers[dep] + 1
+			}
+		}
+		states[n] = done
+	}
+
+	for _, n := range order {
+		if states[n] == unvisited {
+			visit(n)
+		}
+	}
+
+	return layers
+}
+
+// orderLayers groups nodes by layer and sweeps back and forth, sorting each
+// layer by the average position of its neighbours in the previous one.
+func orderLayers(order, layers []int, dependencies, dependents [][]int) [][]int {
+	if len(order) == 0 {
+		return nil
+	}
+
+	maxLayer := 0
+	for _, layer := range layers {
+		if layer > maxLayer {
+			maxLayer = layer
+		}
+	}
+
+	rows := make([][]int, maxLayer+1)
+	for _, n := range order {
+		rows[layers[n]] = append(rows[layers[n]], n)
+	}
+
+	position := make([]float64, len(layers))
+	record := func(row []int) {
+		for i, n := range row {
+			position[n] = float64(i)
+		}
+	}
+	for _, row := range rows {
+		record(row)
+	}
+
+	for pass := 0; pass < orderingPasses; pass++ {
+		if pass%2 == 0 {
+			for l := 1; l < len(rows); l++ {
+				sortByBarycenter(rows[l], dependencies, position)
+				record(rows[l])
+			}
+		} else {
+			for l := len(rows) - 2; l >= 0; l-- {
+				sortByBarycenter(rows[l], dependents, position)
+				record(rows[l])
+			}
+		}
+	}
+
+	return rows
+}
+
+func sortByBarycenter(row []int, neighbours [][]int, position []float64) {
+	centers := make(map[int]float64, len(row))
+	for _, n := range row {
+		if len(neighbours[n]) == 0 {
+			centers[n] = position[n]
+			continue
+		}
+
+		sum := 0.0
+		for _, m := range neighbours[n] {
+			sum += position[m]
+		}
+		centers[n] = sum / float64(len(neighbours[n]))
+	}
+
+	sort.SliceStable(row, func(a, b int) bool {
+		return centers[row[a]] < centers[row[b]]
+	})
+}
+
+func (l *Layout) place(rows [][]int) {
+	x := margin
+	for layer, row := range rows {
+		columnWidth := minNodeWidth
+		for _, n := range row {
+			if w := nodeWidth(l.Nodes[n].Node); w > columnWidth {
+				columnWidth = w
+			}
+		}
+
+		y := margin
+		for _, n := range row {
+			l.Nodes[n].Layer = layer
+			l.Nodes[n].X = x
+			l.Nodes[n].Y = y
+			l.Nodes[n].Width = columnWidth
+			l.Nodes[n].Height = nodeHeight
+			y += nodeHeight + rowGap
+		}
+
+		if y-rowGap+margin > l.Height {
+			l.Height = y - rowGap + margin
+		}
+		x += columnWidth + layerGap
+	}
+
+	l.Width = x - layerGap + margin
+	if len(rows) == 0 {
+		l.Width, l.Height = 2*margin, 2*margin
+	}
+}
+
+func nodeWidth(node models.Node) int {
+	chars := len(node.Type)
+	if name := nodeName(node); len(name) > chars {
+		chars = len(name)
+	}
+
+	if w := chars*charWidth + nodePadding; w > minNodeWidth {
+		return w
+	}
+
+	return minNodeWidth
+}
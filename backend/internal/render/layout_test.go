@@ -0,0 +1,95 @@
+// Package render turns dependency graphs into static artifacts such as DOT
+// sources, standalone HTML pages, SVG images and SQLite databases.
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func placed(layout *Layout, id string) PlacedNode {
+	for _, node := range layout.Nodes {
+		if node.Node.ID == id {
+			return node
+		}
+	}
+
+	return PlacedNode{}
+}
+
+func TestLayoutGraph(t *testing.T) {
+	t.Run("places dependencies in earlier layers", func(t *testing.T) {
+		layout := LayoutGraph(sampleGraph())
+
+		vpc := placed(layout, "module.network.aws_vpc.main")
+		subnet := placed(layout, "module.network.aws_subnet.a")
+		bucket := placed(layout, "aws_s3_bucket.logs")
+
+		assert.Equal(t, 0, vpc.Layer)
+		assert.Equal(t, 1, subnet.Layer)
+		assert.Equal(t, 1, bucket.Layer)
+		assert.Less(t, vpc.X, subnet.X)
+		assert.Equal(t, subnet.X, bucket.X)
+		assert.NotEqual(t, subnet.Y, bucket.Y)
+		assert.Len(t, layout.Edges, 2)
+	})
+
+	t.Run("canvas contains every node", func(t *testing.T) {
+		layout := LayoutGraph(sampleGraph())
+
+		for _, node := range layout.Nodes {
+			assert.LessOrEqual(t, node.X+node.Width, layout.Width)
+			assert.LessOrEqual(t, node.Y+node.Height, layout.Height)
+		}
+	})
+
+	t.Run("layout does not depend on input order", func(t *testing.T) {
+		graph := sampleGraph()
+		reversed := sampleGraph()
+		reversed.Nodes[0], reversed.Nodes[2] = reversed.Nodes[2], reversed.Nodes[0]
+
+		a, b := LayoutGraph(graph), LayoutGraph(reversed)
+
+		for _, node := range a.Nodes {
+			other := placed(b, node.Node.ID)
+			assert.Equal(t, node.X, other.X, node.Node.ID)
+			assert.Equal(t, node.Y, other.Y, node.Node.ID)
+		}
+	})
+
+	t.Run("survives cycles and dangling edges", func(t *testing.T) {
+		graph := &models.Graph{
+			Nodes: []models.Node{{ID: "a.x", Type: "a"}, {ID: "a.y", Type: "a"}},
+			Edges: []models.Edge{
+				{Source: "a.x", Target: "a.y", Type: "implicit"},
+				{Source: "a.y", Target: "a.x", Type: "implicit"},
+				{Source: "a.x", Target: "missing.z", Type: "implicit"},
+			},
+		}
+
+		layout := LayoutGraph(graph)
+
+		require.Len(t, layout.Nodes, 2)
+		assert.Len(t, layout.Edges, 2)
+		assert.NotEqual(t, placed(layout, "a.x").Layer, placed(layout, "a.y").Layer)
+	})
+
+	t.Run("empty graph", func(t *testing.T) {
+		layout := LayoutGraph(&models.Graph{})
+
+		assert.Empty(t, layout.Nodes)
+		assert.Equal(t, 40, layout.Width)
+		assert.Equal(t, 40, layout.Height)
+	})
+
+	t.Run("widens columns for long names", func(t *testing.T) {
+		graph := &models.Graph{Nodes: []models.Node{{ID: "aws_iam_role_policy_attachment.very_long_attachment_name", Type: "aws_iam_role_policy_attachment"}}}
+
+		layout := LayoutGraph(graph)
+
+		assert.Greater(t, layout.Nodes[0].Width, len("very_long_attachment_name")*charWidth)
+	})
+}
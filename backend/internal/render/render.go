@@ -0,0 +1,55 @@
+// Package render turns dependency graphs into static artifacts such as DOT
+// sources, standalone HTML pages, SVG images and SQLite databases.
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+type Format struct {
+	Name        string
+	Extension   string
+	ContentType string
+	Render      func(graph *models.Graph) ([]byte, error)
+	// Attachment formats are served for download rather than display.
+	Attachment bool
+}
+
+// Formats lists the available renderers by name.
+var Formats = map[string]Format{
+	"json":   {Name: "json", Extension: "json", ContentType: "application/json", Render: JSON},
+	"dot":    {Name: "dot", Extension: "dot", ContentType: "text/vnd.graphviz; charset=utf-8", Render: DOT},
+	"html":   {Name: "html", Extension: "html", ContentType: "text/html; charset=utf-8", Render: HTML},
+	"svg":    {Name: "svg", Extension: "svg", ContentType: "image/svg+xml", Render: SVG},
+	"sqlite": {Name: "sqlite", Extension: "sqlite", ContentType: "application/vnd.sqlite3", Render: SQLite, Attachment: true},
+}
+
+// FormatNames returns the names of the available formats in sorted order.
+func FormatNames() []string {
+	names := make([]string, 0, len(Formats))
+	for name := range Formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Lookup returns the named format or an error listing the supported ones.
+func Lookup(name string) (Format, error) {
+	format, ok := Formats[name]
+	if !ok {
+		return Format{}, fmt.Errorf("unknown format %q (supported: %s)", name, strings.Join(FormatNames(), ", "))
+	}
+
+	return format, nil
+}
+
+func JSON(graph *models.Graph) ([]byte, error) {
+	return json.MarshalIndent(graph, "", "  ")
+}
@@ -0,0 +1,55 @@
+// Package render turns dependency graphs into static artifacts such as DOT
+// sources, standalone HTML pages, SVG images and SQLite databases.
+package render
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func sampleGraph() *models.Graph {
+	return &models.Graph{
+		Nodes: []models.Node{
+			{ID: "aws_s3_bucket.logs", Type: "aws_s3_bucket", Mode: "managed", Provider: "aws"},
+			{ID: "module.network.aws_vpc.main", Type: "aws_vpc", Mode: "managed", Provider: "aws", Module: "module.network"},
+			{ID: "module.network.aws_subnet.a", Type: "aws_subnet", Mode: "managed", Provider: "aws", Module: "module.network"},
+		},
+		Edges: []models.Edge{
+			{Source: "module.network.aws_subnet.a", Target: "module.network.aws_vpc.main", Type: "implicit"},
+			{Source: "aws_s3_bucket.logs", Target: "module.network.aws_vpc.main", Type: "depends_on"},
+		},
+	}
+}
+
+func TestLookup(t *testing.T) {
+	t.Run("known format", func(t *testing.T) {
+		format, err := Lookup("dot")
+
+		require.NoError(t, err)
+		assert.Equal(t, "dot", format.Extension)
+	})
+
+	t.Run("unknown format lists supported ones", func(t *testing.T) {
+		_, err := Lookup("pdf")
+
+		assert.EqualError(t, err, `unknown format "pdf" (supported: dot, html, json, sqlite, svg)`)
+	})
+}
+
+func TestFormatNames(t *testing.T) {
+	assert.Equal(t, []string{"dot", "html", "json", "sqlite", "svg"}, FormatNames())
+}
+
+func TestJSON(t *testing.T) {
+	data, err := JSON(sampleGraph())
+	require.NoError(t, err)
+
+	var graph models.Graph
+	require.NoError(t, json.Unmarshal(data, &graph))
+	assert.Len(t, graph.Nodes, 3)
+	assert.Len(t, graph.Edges, 2)
+}
@@ -0,0 +1,196 @@
+// Package render turns dependency graphs into static artifacts such as DOT
+// sources, standalone HTML pages, SVG images and SQLite databases.
+package render
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/terrascope/core/internal/models"
+	_ "modernc.org/sqlite"
+)
+
+// sqliteTables are the tables of the SQLite export, in the order of the
+// schema.
+var sqliteTables = []struct {
+	name string
+	sql  string
+}{
+	{"nodes", "CREATE TABLE nodes (id TEXT NOT NULL, type TEXT NOT NULL, name TEXT NOT NULL, mode TEXT, provider TEXT, module TEXT, status TEXT)"},
+	{"edges", "CREATE TABLE edges (source TEXT NOT NULL, target TEXT NOT NULL, type TEXT)"},
+	{"attributes", "CREATE TABLE attributes (node_id TEXT NOT NULL, key TEXT NOT NULL, value)"},
+	{"stats", "CREATE TABLE stats (key TEXT NOT NULL, value)"},
+}
+
+// SQLite renders the graph as a SQLite database with a row per node in
+// nodes, per edge in edges, per metadata attribute of a node in attributes
+// and per statistic in stats. Nested attributes and statistics are
+// flattened to dotted keys, and lists are stored as JSON.
+func SQLite(graph *models.Graph) ([]byte, error) {
+	rows := make([][][]any, len(sqliteTables))
+
+	for _, node := range graph.Nodes {
+		rows[0] = append(rows[0], []any{node.ID, node.Type, nodeName(node), node.Mode, node.Provider, node.Module, node.Status})
+
+		err := flatten("", node.Metadata, func(key string, value any) {
+			rows[2] = append(rows[2], []any{node.ID, key, value})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to flatten attributes of %s: %w", node.ID, err)
+		}
+	}
+
+	for _, edge := range graph.Edges {
+		rows[1] = append(rows[1], []any{edge.Source, edge.Target, edge.Type})
+	}
+
+	if graph.Stats != nil {
+		encoded, err := json.Marshal(graph.Stats)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode stats: %w", err)
+		}
+
+		var stats map[string]any
+		if err := json.Unmarshal(encoded, &stats); err != nil {
+			return nil, fmt.Errorf("failed to decode stats: %w", err)
+		}
+
+		err = flatten("", stats, func(key string, value any) {
+			rows[3] = append(rows[3], []any{key, value})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to flatten stats: %w", err)
+		}
+	}
+
+	// The database is written by the driver to a file of its own, which is
+	// then read whole.
+	dir, err := os.MkdirTemp("", "terrascope-sqlite-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the database: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "graph.sqlite")
+	if err := writeSQLite(path, rows); err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(path)
+}
+
+// writeSQLite creates the database at path with the rows of each table.
+func writeSQLite(path string, rows [][][]any) (err error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("failed to create the database: %w", err)
+	}
+	defer func() {
+		if closeErr := db.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to write the database: %w", closeErr)
+		}
+	}()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to write the database: %w", err)
+	}
+	defer tx.Rollback()
+
+	for i, table := range sqliteTables {
+		if err := insertRows(tx, table.name, table.sql, rows[i]); err != nil {
+			return fmt.Errorf("failed to write table %s: %w", table.name, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// insertRows creates the table and inserts the rows, numbered from 1.
+func insertRows(tx *sql.Tx, name, schema string, rows [][]any) error {
+	if _, err := tx.Exec(schema); err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(rows[0])), ", ")
+	insert, err := tx.Prepare("INSERT INTO " + name + " VALUES (" + placeholders + ")")
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+
+	for _, row := range rows {
+		values := make([]any, len(row))
+		for i, value := range row {
+			values[i] = sqliteValue(value)
+		}
+
+		if _, err := insert.Exec(values...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sqliteValue stores whole numbers, which JSON decodes as floats, and
+// booleans as integers, as SQLite has no boolean type.
+func sqliteValue(value any) any {
+	switch v := value.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+	case bool:
+		if v {
+			return int64(1)
+		}
+		return int64(0)
+	}
+
+	return value
+}
+
+// flatten calls emit for every scalar in value, keyed by its dotted path
+// under prefix. Maps are walked in key order and lists are encoded as JSON.
+func flatten(prefix string, value any, emit func(key string, value any)) error {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+
+			if err := flatten(path, v[key], emit); err != nil {
+				return err
+			}
+		}
+	case nil, string, bool, int, int64, float64:
+		emit(prefix, v)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+
+		emit(prefix, string(encoded))
+	}
+
+	return nil
+}
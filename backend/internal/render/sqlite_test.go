@@ -0,0 +1,137 @@
+// Package render turns dependency graphs into static artifacts such as DOT
+// sources, standalone HTML pages, SVG images and SQLite databases.
+package render
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+// readDatabase opens the database with the SQLite driver, checks its
+// integrity and returns the rows of every table by name, in rowid order.
+func readDatabase(t *testing.T, data []byte) map[string][][]any {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "graph.sqlite")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	db, err := sql.Open("sqlite", path+"?mode=ro")
+	require.NoError(t, err)
+	defer db.Close()
+
+	var integrity string
+	require.NoError(t, db.QueryRow("PRAGMA integrity_check").Scan(&integrity))
+	require.Equal(t, "ok", integrity)
+
+	names, err := db.Query("SELECT name FROM sqlite_schema WHERE type = 'table' ORDER BY rowid")
+	require.NoError(t, err)
+
+	var tables []string
+	for names.Next() {
+		var name string
+		require.NoError(t, names.Scan(&name))
+		tables = append(tables, name)
+	}
+	require.NoError(t, names.Err())
+
+	rows := make(map[string][][]any)
+	for _, table := range tables {
+		result, err := db.Query("SELECT * FROM " + table + " ORDER BY rowid")
+		require.NoError(t, err)
+
+		columns, err := result.Columns()
+		require.NoError(t, err)
+
+		rows[table] = [][]any{}
+		for result.Next() {
+			row := make([]any, len(columns))
+			pointers := make([]any, len(columns))
+			for i := range row {
+				pointers[i] = &row[i]
+			}
+			require.NoError(t, result.Scan(pointers...))
+			rows[table] = append(rows[table], row)
+		}
+		require.NoError(t, result.Err())
+	}
+
+	return rows
+}
+
+func TestSQLite(t *testing.T) {
+	t.Run("writes nodes, edges, attributes and stats", func(t *testing.T) {
+		graph := sampleGraph()
+		graph.Nodes[0].Metadata = map[string]any{
+			"id":        "logs",
+			"tags":      map[string]any{"Team": "data"},
+			"index_key": float64(2),
+			"ratio":     0.5,
+			"public":    false,
+			"cidrs":     []any{"10.0.0.0/8"},
+		}
+		graph.Stats = &models.Stats{TotalNodes: 3, TotalEdges: 2, ResourcesByType: map[string]int{"aws_vpc": 1}}
+
+		data, err := SQLite(graph)
+		require.NoError(t, err)
+
+		tables := readDatabase(t, data)
+
+		require.Len(t, tables["nodes"], 3)
+		assert.Equal(t, []any{"module.network.aws_vpc.main", "aws_vpc", "main", "managed", "aws", "module.network", ""}, tables["nodes"][1])
+		assert.Equal(t, [][]any{
+			{"module.network.aws_subnet.a", "module.network.aws_vpc.main", "implicit"},
+			{"aws_s3_bucket.logs", "module.network.aws_vpc.main", "depends_on"},
+		}, tables["edges"])
+		assert.Equal(t, [][]any{
+			{"aws_s3_bucket.logs", "cidrs", `["10.0.0.0/8"]`},
+			{"aws_s3_bucket.logs", "id", "logs"},
+			{"aws_s3_bucket.logs", "index_key", int64(2)},
+			{"aws_s3_bucket.logs", "public", int64(0)},
+			{"aws_s3_bucket.logs", "ratio", 0.5},
+			{"aws_s3_bucket.logs", "tags.Team", "data"},
+		}, tables["attributes"])
+		assert.Contains(t, tables["stats"], []any{"resources_by_type.aws_vpc", int64(1)})
+		assert.Contains(t, tables["stats"], []any{"total_edges", int64(2)})
+	})
+
+	t.Run("spans pages", func(t *testing.T) {
+		graph := &models.Graph{}
+		for i := range 2000 {
+			graph.Nodes = append(graph.Nodes, models.Node{
+				ID:       fmt.Sprintf("aws_instance.web[%d]", i),
+				Type:     "aws_instance",
+				Metadata: map[string]any{"user_data": strings.Repeat("x", i%5*3000)},
+			})
+		}
+
+		data, err := SQLite(graph)
+		require.NoError(t, err)
+
+		tables := readDatabase(t, data)
+
+		require.Len(t, tables["nodes"], 2000)
+		assert.Equal(t, "aws_instance.web[1999]", tables["nodes"][1999][0])
+		require.Len(t, tables["attributes"], 2000)
+		for i, row := range tables["attributes"] {
+			require.Len(t, row[2], i%5*3000, "large values are read back whole")
+		}
+	})
+
+	t.Run("empty graph", func(t *testing.T) {
+		data, err := SQLite(&models.Graph{})
+		require.NoError(t, err)
+
+		tables := readDatabase(t, data)
+
+		assert.Len(t, tables, len(sqliteTables))
+		assert.Empty(t, tables["nodes"])
+	})
+}
@@ -0,0 +1,62 @@
+// Package render turns dependency graphs into static artifacts such as DOT
+// sources, standalone HTML pages, SVG images and SQLite databases.
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// SVG lays the graph out and draws it as a standalone SVG image: one box per
+// resource labelled with its type and name, and a curved arrow from every
+// resource to each of its dependencies. Implicit references are dashed.
+func SVG(graph *models.Graph) ([]byte, error) {
+	layout := LayoutGraph(graph)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Helvetica, Arial, sans-serif" font-size="12">`+"\n",
+		layout.Width, layout.Height, layout.Width, layout.Height)
+	buf.WriteString(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z" fill="#52606d"/></marker></defs>` + "\n")
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", layout.Width, layout.Height)
+
+	for _, edge := range layout.Edges {
+		from, to := layout.Nodes[edge.From], layout.Nodes[edge.To]
+
+		x1, y1 := from.X, from.Y+from.Height/2
+		x2, y2 := to.X+to.Width, to.Y+to.Height/2
+		if from.Layer <= to.Layer {
+			x1, x2 = from.X+from.Width, to.X
+		}
+		mid := (x1 + x2) / 2
+
+		dash := ""
+		if edge.Edge.Type != "depends_on" {
+			dash = ` stroke-dasharray="4 3"`
+		}
+
+		fmt.Fprintf(&buf, `<path d="M %d %d C %d %d, %d %d, %d %d" fill="none" stroke="#52606d"%s marker-end="url(#arrow)"><title>%s</title></path>`+"\n",
+			x1, y1, mid, y1, mid, y2, x2, y2, dash, html.EscapeString(edge.Edge.Source+" → "+edge.Edge.Target))
+	}
+
+	for _, node := range layout.Nodes {
+		fill := "#e4f7fb"
+		if node.Node.Mode == "data" {
+			fill = "#f5f7fa"
+		}
+
+		fmt.Fprintf(&buf, `<g><title>%s</title>`, html.EscapeString(node.Node.ID))
+		fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d" rx="6" fill="%s" stroke="#3e4c59"/>`,
+			node.X, node.Y, node.Width, node.Height, fill)
+		fmt.Fprintf(&buf, `<text x="%d" y="%d" text-anchor="middle" font-weight="bold">%s</text>`,
+			node.X+node.Width/2, node.Y+16, html.EscapeString(node.Node.Type))
+		fmt.Fprintf(&buf, `<text x="%d" y="%d" text-anchor="middle">%s</text></g>`+"\n",
+			node.X+node.Width/2, node.Y+32, html.EscapeString(nodeName(node.Node)))
+	}
+
+	buf.WriteString("</svg>\n")
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,45 @@
+// Package render turns dependency graphs into static artifacts such as DOT
+// sources, standalone HTML pages, SVG images and SQLite databases.
+package render
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestSVG(t *testing.T) {
+	t.Run("draws nodes and edges", func(t *testing.T) {
+		data, err := SVG(sampleGraph())
+		require.NoError(t, err)
+
+		svg := string(data)
+		assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg"`))
+		assert.Equal(t, 3, strings.Count(svg, "<rect x="))
+		assert.Equal(t, 2, strings.Count(svg, `marker-end="url(#arrow)"`))
+		assert.Equal(t, 1, strings.Count(svg, "stroke-dasharray"))
+		assert.Contains(t, svg, ">aws_vpc</text>")
+		assert.Contains(t, svg, ">main</text>")
+	})
+
+	t.Run("produces well-formed XML", func(t *testing.T) {
+		graph := sampleGraph()
+		graph.Nodes = append(graph.Nodes, models.Node{ID: `aws_subnet.private["a&b"]`, Type: "aws_subnet"})
+
+		data, err := SVG(graph)
+		require.NoError(t, err)
+
+		decoder := xml.NewDecoder(strings.NewReader(string(data)))
+		for {
+			_, err := decoder.Token()
+			if err != nil {
+				assert.EqualError(t, err, "EOF")
+				break
+			}
+		}
+	})
+}
@@ -0,0 +1,94 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// AlertStore keeps the alert rules and the most recent alerts they triggered.
+type AlertStore struct {
+	mu     sync.Mutex
+	rules  map[string]models.AlertRule
+	alerts []models.Alert
+	limit  int
+}
+
+// NewAlertStore returns an empty store that remembers up to limit alerts.
+func NewAlertStore(limit int) *AlertStore {
+	return &AlertStore{
+		rules: make(map[string]models.AlertRule),
+		limit: limit,
+	}
+}
+
+// PutRule adds the rule, replacing a rule with the same ID.
+func (s *AlertStore) PutRule(rule models.AlertRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rules[rule.ID] = rule
+}
+
+// Rule returns the rule with the given ID.
+func (s *AlertStore) Rule(id string) (models.AlertRule, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, ok := s.rules[id]
+
+	return rule, ok
+}
+
+// Rules returns every rule ordered by ID.
+func (s *AlertStore) Rules() []models.AlertRule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rules := make([]models.AlertRule, 0, len(s.rules))
+	for _, rule := range s.rules {
+		rules = append(rules, rule)
+	}
+
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	return rules
+}
+
+// DeleteRule removes the rule and reports whether it existed.
+func (s *AlertStore) DeleteRule(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.rules[id]
+	delete(s.rules, id)
+
+	return ok
+}
+
+// Record remembers triggered alerts, dropping the oldest beyond the limit.
+func (s *AlertStore) Record(alerts ...models.Alert) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.alerts = append(s.alerts, alerts...)
+	if over := len(s.alerts) - s.limit; over > 0 {
+		s.alerts = append([]models.Alert(nil), s.alerts[over:]...)
+	}
+}
+
+// Alerts returns the remembered alerts, newest first.
+func (s *AlertStore) Alerts() []models.Alert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	alerts := make([]models.Alert, len(s.alerts))
+	for i, alert := range s.alerts {
+		alerts[len(s.alerts)-1-i] = alert
+	}
+
+	return alerts
+}
@@ -0,0 +1,42 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestAlertStore(t *testing.T) {
+	t.Run("put, list and delete rules", func(t *testing.T) {
+		s := NewAlertStore(10)
+		s.PutRule(models.AlertRule{ID: "b", Name: "second"})
+		s.PutRule(models.AlertRule{ID: "a", Name: "first"})
+
+		rules := s.Rules()
+		require.Len(t, rules, 2)
+		assert.Equal(t, "a", rules[0].ID)
+
+		rule, ok := s.Rule("b")
+		require.True(t, ok)
+		assert.Equal(t, "second", rule.Name)
+
+		assert.True(t, s.DeleteRule("a"))
+		assert.False(t, s.DeleteRule("a"))
+		assert.Len(t, s.Rules(), 1)
+	})
+
+	t.Run("keeps the newest alerts up to the limit", func(t *testing.T) {
+		s := NewAlertStore(2)
+		s.Record(models.Alert{Serial: 1}, models.Alert{Serial: 2})
+		s.Record(models.Alert{Serial: 3})
+
+		alerts := s.Alerts()
+		require.Len(t, alerts, 2)
+		assert.Equal(t, 3, alerts[0].Serial)
+		assert.Equal(t, 2, alerts[1].Serial)
+	})
+}
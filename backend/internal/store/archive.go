@@ -0,0 +1,189 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+// ArchiveVersion is the archive format written by WriteArchive. ReadArchive
+// rejects archives from newer versions. Archives of version 1 have no states.
+const ArchiveVersion = 2
+
+// WriteArchive writes every stored state version with its document, and every
+// stored graph and lock, as gzip-compressed JSON. Versions archived to cold
+// storage are written without their document, and lineages in the trash are
+// left out. The versions are written one at a time, so that their documents
+// are never all held in memory.
+func WriteArchive(w io.Writer, states StateBackend, graphs GraphBackend, locks LockBackend) error {
+	lineages, err := states.Lineages()
+	if err != nil {
+		return fmt.Errorf("failed to list stored states: %w", err)
+	}
+
+	header, err := json.Marshal(models.Archive{
+		Version:   ArchiveVersion,
+		CreatedAt: time.Now().UTC(),
+		Graphs:    graphs.All(),
+		Locks:     locks.All(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode archive: %w", err)
+	}
+
+	zw := gzip.NewWriter(w)
+
+	// The states follow the rest of the archive, in place of its closing
+	// brace.
+	if _, err := zw.Write(append(bytes.TrimSuffix(header, []byte("}")), `,"states":[`...)); err != nil {
+		return err
+	}
+
+	separator := ""
+	for _, history := range lineages {
+		versions, err := states.Versions(history.Lineage)
+		if err != nil {
+			return fmt.Errorf("failed to list versions of %s: %w", history.Lineage, err)
+		}
+
+		for _, version := range versions {
+			state, err := archivedState(states, version)
+			if err != nil {
+				return err
+			}
+
+			data, err := json.Marshal(state)
+			if err != nil {
+				return fmt.Errorf("failed to encode %s/%d: %w", version.Lineage, version.Serial, err)
+			}
+
+			if _, err := io.WriteString(zw, separator); err != nil {
+				return err
+			}
+			if _, err := zw.Write(data); err != nil {
+				return err
+			}
+			separator = ","
+		}
+	}
+
+	if _, err := io.WriteString(zw, "]}\n"); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// archivedState reads a stored version and its document for the archive.
+func archivedState(states StateBackend, version models.StateVersion) (models.ArchivedState, error) {
+	stored, data, err := states.Get(version.Lineage, version.Serial)
+	switch {
+	case errors.Is(err, ErrArchived):
+		return models.ArchivedState{Version: stored}, nil
+	case err != nil:
+		return models.ArchivedState{}, fmt.Errorf("failed to read %s/%d: %w", version.Lineage, version.Serial, err)
+	}
+
+	return models.ArchivedState{Version: stored, Document: data}, nil
+}
+
+// ReadArchive reads an archive written by WriteArchive, compressed or not,
+// and checks that every graph and state document still matches its digest.
+func ReadArchive(r io.Reader) (*models.Archive, error) {
+	br := bufio.NewReader(r)
+
+	var reader io.Reader = br
+	if magic, err := br.Peek(2); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress archive: %w", err)
+		}
+		defer func() {
+			if err := zr.Close(); err != nil {
+				log.Printf("failed to close archive reader: %v", err)
+			}
+		}()
+		reader = zr
+	}
+
+	var archive models.Archive
+	if err := json.NewDecoder(reader).Decode(&archive); err != nil {
+		return nil, fmt.Errorf("failed to decode archive: %w", err)
+	}
+
+	if archive.Version < 1 || archive.Version > ArchiveVersion {
+		return nil, fmt.Errorf("unsupported archive version %d", archive.Version)
+	}
+
+	for _, entry := range archive.Graphs {
+		if entry.Graph == nil {
+			return nil, fmt.Errorf("graph %s is empty", entry.Digest)
+		}
+		if digest := parser.Digest(entry.Graph); digest != entry.Digest {
+			return nil, fmt.Errorf("graph %s does not match its digest", entry.Digest)
+		}
+	}
+
+	for _, entry := range archive.Locks {
+		if entry.Lineage == "" {
+			return nil, fmt.Errorf("lock %s has no lineage", entry.Lock.ID)
+		}
+	}
+
+	for _, entry := range archive.States {
+		version := entry.Version
+		if version.Lineage == "" {
+			return nil, fmt.Errorf("state version %d has no lineage", version.Serial)
+		}
+		if version.Archived {
+			continue
+		}
+		if sum := sha256.Sum256(entry.Document); hex.EncodeToString(sum[:]) != version.Digest {
+			return nil, fmt.Errorf("state %s/%d does not match its digest", version.Lineage, version.Serial)
+		}
+	}
+
+	return &archive, nil
+}
+
+// Restore adds the archived state versions, graphs and locks to the stores,
+// replacing entries with the same serial, digest or lineage. Versions archived
+// to cold storage are restored without their document, which is read back
+// from cold storage when it is next needed. It fails with ErrTrashed when a
+// lineage of the archive is in the trash.
+func Restore(archive *models.Archive, states StateBackend, graphs GraphBackend, locks LockBackend) (*models.ImportResult, error) {
+	for _, entry := range archive.States {
+		version := entry.Version
+		if err := states.Put(version, entry.Document); err != nil {
+			return nil, fmt.Errorf("failed to restore %s/%d: %w", version.Lineage, version.Serial, err)
+		}
+		if version.Archived {
+			if err := states.Archive(version.Lineage, version.Serial); err != nil {
+				return nil, fmt.Errorf("failed to restore %s/%d: %w", version.Lineage, version.Serial, err)
+			}
+		}
+	}
+
+	for _, entry := range archive.Graphs {
+		graphs.Put(entry.Digest, entry.Graph)
+	}
+
+	for _, entry := range archive.Locks {
+		locks.Put(entry.Lineage, entry.Lock)
+	}
+
+	return &models.ImportResult{States: len(archive.States), Graphs: len(archive.Graphs), Locks: len(archive.Locks)}, nil
+}
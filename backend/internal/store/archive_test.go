@@ -0,0 +1,202 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+func archiveGraph() *models.Graph {
+	graph := &models.Graph{
+		Nodes: []models.Node{
+			{ID: "aws_vpc.main", Type: "aws_vpc", Mode: "managed", Provider: "aws", Metadata: map[string]any{"id": "vpc-1"}},
+			{ID: "aws_subnet.a", Type: "aws_subnet", Mode: "managed", Provider: "aws", Metadata: map[string]any{"id": "subnet-1", "index_key": float64(0)}},
+		},
+		Edges: []models.Edge{{Source: "aws_subnet.a", Target: "aws_vpc.main", Type: "implicit"}},
+	}
+	graph.Stats = parser.ComputeStats(graph)
+
+	return graph
+}
+
+// archiveVersion returns a stored version of a document.
+func archiveVersion(lineage string, serial int, document []byte) models.StateVersion {
+	sum := sha256.Sum256(document)
+
+	return models.StateVersion{
+		Lineage:  lineage,
+		Serial:   serial,
+		Digest:   hex.EncodeToString(sum[:]),
+		Size:     len(document),
+		StoredAt: time.Date(2024, 3, 5, 10, 0, serial, 0, time.UTC),
+		Project:  "payments",
+	}
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	states, graphs, locks := NewStateStore(time.Hour), NewGraphStore(4), NewLockStore()
+	graph := archiveGraph()
+	graphs.Put(graph.Stats.Digest, graph)
+	locks.Put("lineage-1", models.LockInfo{ID: "a", Who: "ci-runner-42", Created: time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)})
+	document := testState(1, "main")
+	require.NoError(t, states.Put(archiveVersion("lineage-1", 1, document), document))
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteArchive(&buf, states, graphs, locks))
+
+	archive, err := ReadArchive(&buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, ArchiveVersion, archive.Version)
+	require.Len(t, archive.States, 1)
+	require.Len(t, archive.Graphs, 1)
+	require.Len(t, archive.Locks, 1)
+
+	restoredStates, restoredGraphs, restoredLocks := NewStateStore(time.Hour), NewGraphStore(4), NewLockStore()
+	result, err := Restore(archive, restoredStates, restoredGraphs, restoredLocks)
+	require.NoError(t, err)
+
+	assert.Equal(t, &models.ImportResult{States: 1, Graphs: 1, Locks: 1}, result)
+
+	_, restoredDocument, err := restoredStates.Get("lineage-1", 1)
+	require.NoError(t, err)
+	assert.Equal(t, document, restoredDocument)
+
+	restored, ok := restoredGraphs.Get(graph.Stats.Digest)
+	require.True(t, ok)
+	assert.Equal(t, graph.Nodes, restored.Nodes)
+
+	lock, ok := restoredLocks.Get("lineage-1")
+	require.True(t, ok)
+	assert.Equal(t, "ci-runner-42", lock.Who)
+}
+
+func TestArchiveRoundTripSQL(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *sql.DB, dialect Dialect) {
+		states := NewSQLStateStore(db, dialect, nil, time.Hour)
+
+		documents := map[int][]byte{1: testState(1, "a"), 2: testState(2, "a", "b"), 3: testState(3, "c")}
+		for serial, document := range documents {
+			require.NoError(t, states.Put(archiveVersion("prod", serial, document), document))
+		}
+		other := testState(7, "z")
+		require.NoError(t, states.Put(archiveVersion("staging", 7, other), other))
+		require.NoError(t, states.Archive("prod", 1))
+
+		var buf bytes.Buffer
+		require.NoError(t, WriteArchive(&buf, states, NewGraphStore(4), NewLockStore()))
+
+		archive, err := ReadArchive(&buf)
+		require.NoError(t, err)
+		require.Len(t, archive.States, 4)
+
+		// The archive is restored into the same database, emptied, as
+		// another instance would.
+		for _, lineage := range []string{"prod", "staging"} {
+			_, err := states.Delete(lineage, time.Now())
+			require.NoError(t, err)
+		}
+		_, err = states.Trash(time.Now().Add(2 * time.Hour))
+		require.NoError(t, err)
+
+		lineages, err := states.Lineages()
+		require.NoError(t, err)
+		require.Empty(t, lineages)
+
+		result, err := Restore(archive, states, NewGraphStore(4), NewLockStore())
+		require.NoError(t, err)
+		assert.Equal(t, 4, result.States)
+
+		versions, err := states.Versions("prod")
+		require.NoError(t, err)
+		require.Len(t, versions, 3)
+		assert.True(t, versions[0].Archived, "archived versions stay archived")
+		assert.Equal(t, archiveVersion("prod", 2, documents[2]), versions[1])
+
+		_, _, err = states.Get("prod", 1)
+		assert.ErrorIs(t, err, ErrArchived)
+
+		for serial := 2; serial <= 3; serial++ {
+			_, document, err := states.Get("prod", serial)
+			require.NoError(t, err)
+			assert.Equal(t, documents[serial], document)
+		}
+
+		_, document, err := states.Get("staging", 7)
+		require.NoError(t, err)
+		assert.Equal(t, other, document)
+	})
+}
+
+func TestReadArchive(t *testing.T) {
+	encode := func(archive models.Archive) []byte {
+		data, err := json.Marshal(archive)
+		require.NoError(t, err)
+
+		return data
+	}
+
+	graph := archiveGraph()
+
+	t.Run("accepts uncompressed archives", func(t *testing.T) {
+		data := encode(models.Archive{Version: 1, Graphs: []models.ArchivedGraph{{Digest: graph.Stats.Digest, Graph: graph}}})
+
+		archive, err := ReadArchive(bytes.NewReader(data))
+
+		require.NoError(t, err)
+		assert.Len(t, archive.Graphs, 1)
+	})
+
+	tests := []struct {
+		name    string
+		archive models.Archive
+		err     string
+	}{
+		{"newer version", models.Archive{Version: ArchiveVersion + 1}, "unsupported archive version 3"},
+		{"missing version", models.Archive{}, "unsupported archive version 0"},
+		{"tampered graph", models.Archive{Version: 1, Graphs: []models.ArchivedGraph{{Digest: "sha256:00", Graph: graph}}}, "does not match its digest"},
+		{"empty graph", models.Archive{Version: 1, Graphs: []models.ArchivedGraph{{Digest: "sha256:00"}}}, "graph sha256:00 is empty"},
+		{"lock without lineage", models.Archive{Version: 1, Locks: []models.ArchivedLock{{Lock: models.LockInfo{ID: "a"}}}}, "lock a has no lineage"},
+		{"state without lineage", models.Archive{Version: 2, States: []models.ArchivedState{{Version: models.StateVersion{Serial: 3}}}}, "state version 3 has no lineage"},
+		{"tampered state", models.Archive{Version: 2, States: []models.ArchivedState{{Version: archiveVersion("prod", 1, []byte("{}")), Document: []byte("[]")}}}, "state prod/1 does not match its digest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadArchive(bytes.NewReader(encode(tt.archive)))
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+
+	t.Run("invalid json", func(t *testing.T) {
+		_, err := ReadArchive(bytes.NewReader([]byte("{")))
+
+		assert.ErrorContains(t, err, "failed to decode archive")
+	})
+
+	t.Run("corrupt gzip", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, err := zw.Write([]byte(`{"version": 1`))
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+
+		_, err = ReadArchive(&buf)
+
+		assert.ErrorContains(t, err, "failed to decode archive")
+	})
+}
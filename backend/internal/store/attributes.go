@@ -0,0 +1,236 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+// attributeValue is a leaf value of an attribute of an instance, which the
+// StateBackends index when they store a version so that Search finds it.
+type attributeValue struct {
+	address      string
+	module       string
+	resourceType string
+	attribute    string
+	value        string
+}
+
+// identifiers are the attributes by which providers identify a resource in
+// the cloud: AWS IDs and ARNs, and Google Cloud self links.
+var identifiers = map[string]bool{
+	"id":        true,
+	"arn":       true,
+	"self_link": true,
+}
+
+// stateAttributes returns the attribute values of the instances of a
+// document, with the digest of each. Attributes that Terraform marked as
+// sensitive are left out, and so is everything when the document does not
+// parse, since it then has nothing to index.
+func stateAttributes(data []byte) ([]attributeValue, []string) {
+	state, err := parser.ParseTfstate(data)
+	if err != nil {
+		return nil, nil
+	}
+
+	var values []attributeValue
+	var digests []string
+	seen := make(map[string]bool)
+
+	for _, res := range state.Resources {
+		for _, instance := range res.Instances {
+			address := parser.InstanceAddress(res, instance)
+			sensitive := sensitiveAttributes(instance)
+
+			names := make([]string, 0, len(instance.Attributes))
+			for name := range instance.Attributes {
+				if !sensitive[name] {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				flatten(name, instance.Attributes[name], func(attribute, value string) {
+					v := attributeValue{address: address, module: res.Module, resourceType: res.Type, attribute: attribute, value: value}
+					if digest := v.digest(); !seen[digest] {
+						seen[digest] = true
+						values = append(values, v)
+						digests = append(digests, digest)
+					}
+				})
+			}
+		}
+	}
+
+	return values, digests
+}
+
+// digest identifies an attribute value across versions and lineages.
+func (v attributeValue) digest() string {
+	data, _ := json.Marshal([]string{v.address, v.module, v.resourceType, v.attribute, v.value})
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// before orders the values of a version by address, attribute and value.
+func (v attributeValue) before(other attributeValue) bool {
+	if v.address != other.address {
+		return v.address < other.address
+	}
+	if v.attribute != other.attribute {
+		return v.attribute < other.attribute
+	}
+	return v.value < other.value
+}
+
+// terms returns the distinct search terms of the attribute key and value.
+func (v attributeValue) terms() []string {
+	terms := []string{}
+	seen := make(map[string]bool)
+	for _, term := range append(attributeTerms(v.attribute), tokenize(v.value)...) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+
+	return terms
+}
+
+// searchHits groups the stored versions that have an attribute value into the
+// hits of a search, in the order they are added, up to a limit.
+type searchHits struct {
+	results models.SearchResults
+	limit   int
+}
+
+func newSearchHits(query string, limit int) *searchHits {
+	return &searchHits{results: models.SearchResults{Query: query, Hits: []models.SearchHit{}}, limit: limit}
+}
+
+// add adds a version that has the value to its hit, following the previous
+// one when they are the same. It returns false once the limit is reached, when
+// the hit is left out and the results are truncated.
+func (h *searchHits) add(lineage string, serial int, v attributeValue) bool {
+	if n := len(h.results.Hits); n > 0 {
+		last := &h.results.Hits[n-1]
+		if last.Lineage == lineage && last.Address == v.address && last.Attribute == v.attribute && last.Value == v.value {
+			last.Serials = append(last.Serials, serial)
+			return true
+		}
+
+		if h.limit > 0 && n == h.limit {
+			h.results.Truncated = true
+			return false
+		}
+	}
+
+	h.results.Hits = append(h.results.Hits, models.SearchHit{
+		Lineage:   lineage,
+		Address:   v.address,
+		Attribute: v.attribute,
+		Value:     v.value,
+		Serials:   []int{serial},
+	})
+
+	return true
+}
+
+// lookupMatch returns the match of an attribute value of a version when the
+// value is one of the identifiers of its instance and is the ID.
+func lookupMatch(version models.StateVersion, v attributeValue, id string) (models.LookupMatch, bool) {
+	if !identifiers[v.attribute] || !strings.EqualFold(v.value, id) {
+		return models.LookupMatch{}, false
+	}
+
+	return models.LookupMatch{
+		Lineage:   version.Lineage,
+		Serial:    version.Serial,
+		Project:   version.Project,
+		Address:   v.address,
+		Module:    v.module,
+		Type:      v.resourceType,
+		Attribute: v.attribute,
+	}, true
+}
+
+// sensitiveAttributes returns the top-level attributes of an instance that
+// hold a value Terraform marked as sensitive.
+func sensitiveAttributes(instance models.ResourceInstance) map[string]bool {
+	sensitive := make(map[string]bool, len(instance.SensitiveAttributes))
+	for _, path := range instance.SensitiveAttributes {
+		if len(path) > 0 && path[0].Type == "get_attr" {
+			if name, ok := path[0].Key().(string); ok {
+				sensitive[name] = true
+			}
+		}
+	}
+
+	return sensitive
+}
+
+// flatten calls emit with each leaf of an attribute value and its path, with
+// map keys joined by dots and list indices in brackets, such as
+// ingress[0].cidr_blocks[1]. Nulls and empty strings are left out.
+func flatten(path string, value any, emit func(path, value string)) {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			flatten(path+"."+key, v[key], emit)
+		}
+	case []any:
+		for i, item := range v {
+			flatten(path+"["+strconv.Itoa(i)+"]", item, emit)
+		}
+	case string:
+		if v != "" {
+			emit(path, v)
+		}
+	case float64:
+		emit(path, strconv.FormatFloat(v, 'f', -1, 64))
+	case bool:
+		emit(path, strconv.FormatBool(v))
+	}
+}
+
+// attributeTerms returns the terms of an attribute path, along with the name
+// of each map key in it, so that tags.Name is found by searching for name.
+func attributeTerms(path string) []string {
+	return append(tokenize(path), tokenize(strings.ReplaceAll(path, ".", " "))...)
+}
+
+// tokenize splits text into lowercase terms: runs of letters, digits, dots,
+// dashes and underscores, without leading or trailing dots. Other characters
+// separate terms, so an ARN yields the instance ID at its end.
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != '-' && r != '_'
+	})
+
+	terms := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if term := strings.Trim(field, "."); term != "" {
+			terms = append(terms, term)
+		}
+	}
+
+	return terms
+}
@@ -0,0 +1,246 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+// forEachStateBackend runs the test against a StateStore and a SQLStateStore
+// on each database forEachDatabase runs against.
+func forEachStateBackend(t *testing.T, test func(t *testing.T, states StateBackend)) {
+	t.Helper()
+
+	t.Run("memory", func(t *testing.T) {
+		test(t, NewStateStore(time.Hour))
+	})
+
+	forEachDatabase(t, func(t *testing.T, db *sql.DB, dialect Dialect) {
+		test(t, NewSQLStateStore(db, dialect, nil, time.Hour))
+	})
+}
+
+func putAttributes(t *testing.T, states StateBackend, lineage string, serial int, resources string) {
+	t.Helper()
+
+	data := fmt.Sprintf(`{"version": 4, "terraform_version": "1.9.0", "lineage": %q, "serial": %d, "resources": [%s]}`, lineage, serial, resources)
+	require.NoError(t, states.Put(models.StateVersion{Lineage: lineage, Serial: serial, StoredAt: time.Now()}, []byte(data)))
+}
+
+const (
+	vpcResource = `{"mode": "managed", "type": "aws_vpc", "name": "main", "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+		"instances": [{"attributes": {"id": "vpc-0a1b", "cidr_block": "10.42.0.0/16", "tags": {"Name": "main"}}}]}`
+	instanceResource = `{"mode": "managed", "type": "aws_instance", "name": "web", "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+		"instances": [{"attributes": {"id": "i-0123abcd", "arn": "arn:aws:ec2:eu-west-1:123456789012:instance/i-0123abcd",
+		"user_data": "secret", "private_ip": "10.42.1.7"},
+		"sensitive_attributes": [[{"type": "get_attr", "value": "user_data"}]]}]}`
+	subnetResource = `{"mode": "managed", "type": "aws_subnet", "name": "private", "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+		"instances": [{"index_key": 0, "attributes": {"id": "subnet-1", "cidr_block": "10.42.0.0/24"}}]}`
+)
+
+func TestSearch(t *testing.T) {
+	forEachStateBackend(t, func(t *testing.T, states StateBackend) {
+		putAttributes(t, states, "prod", 1, vpcResource)
+		putAttributes(t, states, "prod", 2, vpcResource+","+instanceResource)
+		putAttributes(t, states, "staging", 1, subnetResource)
+
+		t.Run("finds a CIDR block in every version", func(t *testing.T) {
+			results, err := states.Search("10.42.0.0/16", "", 0)
+			require.NoError(t, err)
+
+			assert.Equal(t, []models.SearchHit{{
+				Lineage:   "prod",
+				Address:   "aws_vpc.main",
+				Attribute: "cidr_block",
+				Value:     "10.42.0.0/16",
+				Serials:   []int{1, 2},
+			}}, results.Hits)
+		})
+
+		t.Run("finds an instance ID in attributes that embed it", func(t *testing.T) {
+			results, err := states.Search("I-0123ABCD", "", 0)
+			require.NoError(t, err)
+
+			attributes := []string{}
+			for _, hit := range results.Hits {
+				attributes = append(attributes, hit.Attribute)
+				assert.Equal(t, []int{2}, hit.Serials)
+			}
+			assert.Equal(t, []string{"arn", "id"}, attributes)
+		})
+
+		t.Run("matches attribute keys", func(t *testing.T) {
+			results, err := states.Search("name main", "", 0)
+			require.NoError(t, err)
+
+			require.Len(t, results.Hits, 1)
+			assert.Equal(t, "tags.Name", results.Hits[0].Attribute)
+		})
+
+		t.Run("addresses instances and list elements", func(t *testing.T) {
+			results, err := states.Search("subnet-1", "", 0)
+			require.NoError(t, err)
+
+			require.Len(t, results.Hits, 1)
+			assert.Equal(t, "aws_subnet.private[0]", results.Hits[0].Address)
+		})
+
+		t.Run("skips sensitive attributes", func(t *testing.T) {
+			results, err := states.Search("secret", "", 0)
+			require.NoError(t, err)
+
+			assert.Empty(t, results.Hits)
+		})
+
+		t.Run("filters by lineage", func(t *testing.T) {
+			results, err := states.Search("cidr_block", "staging", 0)
+			require.NoError(t, err)
+
+			require.Len(t, results.Hits, 1)
+			assert.Equal(t, "staging", results.Hits[0].Lineage)
+		})
+
+		t.Run("truncates to the limit", func(t *testing.T) {
+			results, err := states.Search("cidr_block", "", 1)
+			require.NoError(t, err)
+
+			assert.Len(t, results.Hits, 1)
+			assert.Equal(t, []int{1, 2}, results.Hits[0].Serials)
+			assert.True(t, results.Truncated)
+		})
+
+		t.Run("indexes versions stored again", func(t *testing.T) {
+			putAttributes(t, states, "prod", 3, instanceResource)
+			putAttributes(t, states, "prod", 2, vpcResource)
+
+			results, err := states.Search("10.42.1.7", "", 0)
+			require.NoError(t, err)
+
+			require.Len(t, results.Hits, 1)
+			assert.Equal(t, []int{3}, results.Hits[0].Serials)
+		})
+
+		t.Run("keeps archived versions searchable", func(t *testing.T) {
+			require.NoError(t, states.Archive("prod", 1))
+
+			results, err := states.Search("10.42.0.0/16", "", 0)
+			require.NoError(t, err)
+
+			require.Len(t, results.Hits, 1)
+			assert.Equal(t, []int{1, 2}, results.Hits[0].Serials)
+		})
+
+		t.Run("leaves out lineages in the trash", func(t *testing.T) {
+			_, err := states.Delete("staging", time.Now())
+			require.NoError(t, err)
+
+			results, err := states.Search("subnet-1", "", 0)
+			require.NoError(t, err)
+			assert.Empty(t, results.Hits)
+		})
+
+		t.Run("ignores queries without terms", func(t *testing.T) {
+			results, err := states.Search(" / ", "", 0)
+			require.NoError(t, err)
+			assert.Empty(t, results.Hits)
+		})
+
+		t.Run("reads operators as terms", func(t *testing.T) {
+			results, err := states.Search("cidr_block NOT", "", 0)
+			require.NoError(t, err)
+			assert.Empty(t, results.Hits)
+		})
+	})
+}
+
+func TestLookup(t *testing.T) {
+	forEachStateBackend(t, func(t *testing.T, states StateBackend) {
+		putAttributes(t, states, "prod", 1, instanceResource)
+		putAttributes(t, states, "prod", 2, vpcResource)
+		putAttributes(t, states, "staging", 1, `{"mode": "managed", "type": "aws_instance", "name": "web", "module": "module.app",
+			"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+			"instances": [{"attributes": {"id": "i-0123abcd", "description": "copy of i-0123abcd"}}]}`)
+
+		t.Run("finds the resources managed under an ID", func(t *testing.T) {
+			matches, err := states.Lookup("i-0123abcd")
+			require.NoError(t, err)
+
+			assert.Equal(t, []models.LookupMatch{{
+				Lineage:   "staging",
+				Serial:    1,
+				Address:   "module.app.aws_instance.web",
+				Module:    "module.app",
+				Type:      "aws_instance",
+				Attribute: "id",
+			}}, matches)
+		})
+
+		t.Run("matches ARNs", func(t *testing.T) {
+			putAttributes(t, states, "prod", 3, instanceResource)
+
+			matches, err := states.Lookup("ARN:aws:ec2:eu-west-1:123456789012:instance/i-0123abcd")
+			require.NoError(t, err)
+
+			require.Len(t, matches, 1)
+			assert.Equal(t, "prod", matches[0].Lineage)
+			assert.Equal(t, 3, matches[0].Serial)
+			assert.Equal(t, "arn", matches[0].Attribute)
+		})
+
+		t.Run("ignores other attributes", func(t *testing.T) {
+			matches, err := states.Lookup("10.42.1.7")
+			require.NoError(t, err)
+
+			assert.Empty(t, matches)
+		})
+	})
+}
+
+func TestSQLStateStoreCollectsValues(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *sql.DB, dialect Dialect) {
+		states := NewSQLStateStore(db, dialect, nil, time.Hour)
+		now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+		putAttributes(t, states, "prod", 1, vpcResource)
+		putAttributes(t, states, "prod", 2, vpcResource)
+		assert.Equal(t, 3, countRows(t, db, "state_attribute_values"), "versions share their values")
+
+		putAttributes(t, states, "prod", 2, subnetResource)
+		assert.Equal(t, 5, countRows(t, db, "state_attribute_values"))
+
+		_, err := states.Delete("prod", now)
+		require.NoError(t, err)
+		_, err = states.Trash(now.Add(2 * time.Hour))
+		require.NoError(t, err)
+
+		assert.Zero(t, countRows(t, db, "state_attribute_values"))
+		assert.Zero(t, countRows(t, db, "state_version_attributes"))
+	})
+}
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"10.42.0.0", "16"}, tokenize("10.42.0.0/16"))
+	assert.Equal(t, []string{"arn", "aws", "ec2", "eu-west-1", "instance", "i-0abc"}, tokenize("arn:aws:ec2:eu-west-1:instance/i-0abc"))
+	assert.Equal(t, []string{"example.com"}, tokenize("Example.com."))
+	assert.Empty(t, tokenize(""))
+}
+
+func TestFlatten(t *testing.T) {
+	values := map[string]string{}
+	flatten("ingress", []any{
+		map[string]any{"cidr_blocks": []any{"0.0.0.0/0"}, "from_port": float64(443), "self": false, "description": ""},
+	}, func(path, value string) { values[path] = value })
+
+	assert.Equal(t, map[string]string{
+		"ingress[0].cidr_blocks[0]": "0.0.0.0/0",
+		"ingress[0].from_port":      "443",
+		"ingress[0].self":           "false",
+	}, values)
+}
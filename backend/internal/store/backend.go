@@ -0,0 +1,171 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+var (
+	// ErrNotFound is returned for lineages and versions that are not stored,
+	// or not in the trash when restoring.
+	ErrNotFound = errors.New("not found")
+	// ErrTrashed is returned when storing a version of a lineage in the trash.
+	ErrTrashed = errors.New("lineage is in the trash")
+	// ErrArchived is returned when reading a version whose document was moved
+	// to cold storage.
+	ErrArchived = errors.New("state version is archived")
+	// ErrRehydrating is returned when reading an archived version that cold
+	// storage has yet to restore.
+	ErrRehydrating = errors.New("state version is being rehydrated")
+)
+
+// StateBackend stores the history of uploaded states per lineage. Deleting a
+// lineage moves its history to the trash, where it can be restored until the
+// retention window has passed. StateStore keeps it in memory; SQLStateStore
+// in SQLite or Postgres, optionally with the documents in a BlobStore.
+type StateBackend interface {
+	// Put stores a state version, replacing a stored version with the same
+	// serial.
+	Put(version models.StateVersion, data []byte) error
+	// Lineages summarizes every lineage with stored versions, ordered by
+	// lineage.
+	Lineages() ([]models.StateHistory, error)
+	// Versions returns the stored versions of a lineage ordered by serial.
+	Versions(lineage string) ([]models.StateVersion, error)
+	// Get returns a stored state version and its raw document, or
+	// ErrArchived with the version when its document is in cold storage.
+	Get(lineage string, serial int) (models.StateVersion, []byte, error)
+	// Archive drops the document of a version copied to cold storage,
+	// keeping its metadata, until Put stores the document again.
+	Archive(lineage string, serial int) error
+	// PutGraph stores the graph of a stored version, until Put replaces the
+	// version.
+	PutGraph(lineage string, serial int, graph models.StoredGraph) error
+	// Graph returns the stored graph of a version, or ErrNotFound when the
+	// version or its graph is not stored.
+	Graph(lineage string, serial int) (models.StoredGraph, error)
+	// Delete moves a lineage and its history to the trash.
+	Delete(lineage string, now time.Time) (models.TrashEntry, error)
+	// Trash lists the deleted lineages that can still be restored, most
+	// recently deleted first, dropping those past the retention window.
+	Trash(now time.Time) ([]models.TrashEntry, error)
+	// Restore moves a lineage back from the trash.
+	Restore(lineage string, now time.Time) (models.StateHistory, error)
+	// StoredBytes sums the size of the stored versions per project, those in
+	// the trash included until they are purged and those archived to cold
+	// storage excluded.
+	StoredBytes(now time.Time) (map[string]int64, error)
+	// ProjectStoredBytes sums the size of the stored versions of a project
+	// as StoredBytes does, reading only that project's versions. Lineages
+	// in the trash past the retention window are left out rather than
+	// purged.
+	ProjectStoredBytes(project string, now time.Time) (int64, error)
+	// Search returns the attribute values of the stored versions whose key
+	// and value together contain every term of the query, in the lineage
+	// when it is not empty, up to limit hits. Terms are runs of letters,
+	// digits, dots, dashes and underscores, compared regardless of case.
+	// Values are indexed when Put stores a version and stay searchable once
+	// it is archived.
+	Search(query, lineage string, limit int) (models.SearchResults, error)
+	// Lookup returns the instances of the latest version of each lineage
+	// whose id, arn or self_link is the ID, compared regardless of case.
+	Lookup(id string) ([]models.LookupMatch, error)
+}
+
+// UserBackend holds the users allowed to sign in, with their password hashes
+// or their pending invitation: a UserStore or, to keep them across restarts
+// and replicas, SQLUsers.
+type UserBackend interface {
+	// Add stores a user who signs in with the password hash. It returns false
+	// when the username is taken.
+	Add(user models.User, hash string) (bool, error)
+	// Seed stores a user of the users file, or updates their password hash,
+	// unless the user was removed.
+	Seed(user models.User, hash string) error
+	// Invite stores a user who may choose a password with the token until it
+	// expires. It returns false when the username is taken.
+	Invite(user models.User, token string, expiresAt time.Time) (bool, error)
+	// Accept sets the password hash of the user invited with the token,
+	// unless the invitation has expired.
+	Accept(token, hash string, now time.Time) (models.User, bool, error)
+	// Get returns the user with the username.
+	Get(username string) (models.User, bool, error)
+	// Password returns the password hash of the user, or an empty hash for
+	// unknown and invited users.
+	Password(username string) (string, error)
+	// SetRole assigns the role to the user.
+	SetRole(username, role string) (models.User, bool, error)
+	// Delete removes the user, who Seed then leaves out, and reports whether
+	// there was one.
+	Delete(username string) (bool, error)
+	// List returns the users ordered by username.
+	List() ([]models.User, error)
+	// Len returns the number of users, invited ones included.
+	Len() (int, error)
+	// Admins returns the number of admins who can sign in, leaving out
+	// invited ones.
+	Admins() (int, error)
+}
+
+// KeyBackend holds API keys and records their usage: a KeyStore or, to keep
+// them across restarts and replicas, SQLKeys.
+type KeyBackend interface {
+	// Create stores the key, which clients present as the secret.
+	Create(key models.APIKey, secret string) error
+	// Use returns the unrevoked key presented as the secret and records the
+	// request in its usage.
+	Use(secret string, now time.Time) (models.APIKey, bool, error)
+	// Get returns the key with the ID.
+	Get(id string) (models.APIKey, bool, error)
+	// Revoke stops the key with the ID from authenticating. Revoked keys are
+	// kept with their usage.
+	Revoke(id string, now time.Time) (models.APIKey, bool, error)
+	// List returns the keys, oldest first.
+	List() ([]models.APIKey, error)
+}
+
+// BlobStore holds documents by key, such as an S3 bucket.
+type BlobStore interface {
+	Put(ctx context.Context, key string, body []byte, contentType string) (string, error)
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// The stores below are kept in memory by each server, or in a cache.Shared by
+// their Shared counterparts when replicas must see the same entries.
+
+// SessionBackend holds login sessions by token: a SessionStore or
+// SharedSessions.
+type SessionBackend interface {
+	Create(token, username string, now time.Time) models.Session
+	Get(token string, now time.Time) (models.Session, bool)
+	Delete(token string) bool
+}
+
+// LockBackend records the current lock of each state lineage: a LockStore or
+// SharedLocks.
+type LockBackend interface {
+	Put(lineage string, lock models.LockInfo)
+	Get(lineage string) (*models.LockInfo, bool)
+	Delete(lineage string) bool
+	All() []models.ArchivedLock
+}
+
+// GraphBackend holds graphs by digest: a GraphStore or SharedGraphs.
+type GraphBackend interface {
+	Put(digest string, graph *models.Graph)
+	Get(digest string) (*models.Graph, bool)
+	All() []models.ArchivedGraph
+}
+
+// SchemaBackend holds provider schemas documents by digest: a SchemaStore or
+// SharedSchemas.
+type SchemaBackend interface {
+	Put(digest string, schemas *models.ProviderSchemas)
+	Get(digest string) (*models.ProviderSchemas, bool)
+}
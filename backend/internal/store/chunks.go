@@ -0,0 +1,88 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// splitChunks cuts a state document into the chunks its versions are stored
+// as: the document up to its resources, each resource with the separator
+// before it, and the rest of the document. Consecutive versions of a state
+// mostly differ by a few resources, so they share most of their chunks.
+// Documents without a resources array are a single chunk. The chunks
+// concatenate back to the document.
+func splitChunks(data []byte) [][]byte {
+	bounds := resourceBounds(data)
+	if bounds == nil {
+		return [][]byte{data}
+	}
+
+	chunks := make([][]byte, 0, len(bounds)+1)
+	start := 0
+	for _, end := range bounds {
+		chunks = append(chunks, data[start:end])
+		start = end
+	}
+
+	return append(chunks, data[start:])
+}
+
+// resourceBounds returns the offsets of the ends of the chunks of a document
+// but the last: right after the opening bracket of its resources array, and
+// right after each resource. It returns nil when the document is not an
+// object with a resources array.
+func resourceBounds(data []byte) []int {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil
+	}
+
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil
+		}
+
+		if key != "resources" {
+			var skipped json.RawMessage
+			if err := dec.Decode(&skipped); err != nil {
+				return nil
+			}
+			continue
+		}
+
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+			return nil
+		}
+
+		bounds := []int{int(dec.InputOffset())}
+		for dec.More() {
+			var resource json.RawMessage
+			if err := dec.Decode(&resource); err != nil {
+				return nil
+			}
+			bounds = append(bounds, int(dec.InputOffset()))
+		}
+
+		return bounds
+	}
+
+	return nil
+}
+
+// chunkDigest addresses a chunk by the hex-encoded SHA-256 of its content.
+func chunkDigest(chunk []byte) string {
+	sum := sha256.Sum256(chunk)
+	return hex.EncodeToString(sum[:])
+}
+
+// chunkKey is where a chunk is kept in the blob store, spread over prefixes
+// so that no listing holds every chunk.
+func chunkKey(digest string) string {
+	return "chunks/" + digest[:2] + "/" + digest
+}
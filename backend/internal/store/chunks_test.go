@@ -0,0 +1,77 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitChunks(t *testing.T) {
+	document := `{
+  "serial": 3,
+  "resources": [
+    {"type": "aws_vpc", "name": "main"},
+    {"type": "aws_subnet", "name": "a"}
+  ],
+  "check_results": null
+}`
+
+	t.Run("cuts around each resource", func(t *testing.T) {
+		chunks := splitChunks([]byte(document))
+
+		assert.Equal(t, []string{
+			"{\n  \"serial\": 3,\n  \"resources\": [",
+			"\n    {\"type\": \"aws_vpc\", \"name\": \"main\"}",
+			",\n    {\"type\": \"aws_subnet\", \"name\": \"a\"}",
+			"\n  ],\n  \"check_results\": null\n}",
+		}, chunkStrings(chunks))
+		assert.Equal(t, document, string(bytes.Join(chunks, nil)))
+	})
+
+	t.Run("shares the unchanged resources of the next version", func(t *testing.T) {
+		next := bytes.Replace([]byte(document), []byte(`"serial": 3`), []byte(`"serial": 4`), 1)
+		next = bytes.Replace(next, []byte(`"name": "a"`), []byte(`"name": "b"`), 1)
+
+		previous, chunks := splitChunks([]byte(document)), splitChunks(next)
+
+		assert.Equal(t, previous[1], chunks[1])
+		assert.Equal(t, previous[3], chunks[3])
+		assert.NotEqual(t, previous[2], chunks[2])
+	})
+
+	tests := []struct {
+		name     string
+		document string
+	}{
+		{"not json", "not json"},
+		{"no resources", `{"serial": 1}`},
+		{"resources not an array", `{"resources": {}}`},
+		{"array", `[1, 2]`},
+		{"truncated", `{"resources": [{"type": "aws_vpc"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, []string{tt.document}, chunkStrings(splitChunks([]byte(tt.document))))
+		})
+	}
+}
+
+func TestChunkKey(t *testing.T) {
+	digest := chunkDigest([]byte("{}"))
+
+	assert.Len(t, digest, 64)
+	assert.Equal(t, "chunks/44/"+digest, chunkKey(digest))
+}
+
+func chunkStrings(chunks [][]byte) []string {
+	strs := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		strs[i] = string(chunk)
+	}
+
+	return strs
+}
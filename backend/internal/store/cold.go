@@ -0,0 +1,109 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// ColdStore holds the documents of archived state versions in an archival
+// storage class, such as S3 Glacier, whose objects must be restored before
+// they can be read.
+type ColdStore interface {
+	Put(ctx context.Context, key string, body []byte, contentType string) (string, error)
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Restore requests a readable copy of an object for the given number of
+	// days, and reports whether the copy is readable yet.
+	Restore(ctx context.Context, key string, days int) (bool, error)
+}
+
+// Archiver moves the documents of the state versions stored more than After
+// ago to a ColdStore, keeping their metadata in the StateBackend, and reads
+// them back from there on demand. The latest version of each lineage stays in
+// the backend. Archived documents are kept in cold storage after their
+// lineage is purged from the trash, for the bucket's own lifecycle rules to
+// expire.
+type Archiver struct {
+	States      StateBackend
+	Cold        ColdStore
+	After       time.Duration
+	RestoreDays int
+}
+
+// Archive moves the documents of the versions due for cold storage and
+// returns the versions it archived. It stops at the first failure, leaving
+// the versions it did not archive in the backend.
+func (a *Archiver) Archive(ctx context.Context, now time.Time) ([]models.StateVersion, error) {
+	histories, err := a.States.Lineages()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := now.Add(-a.After)
+	archived := []models.StateVersion{}
+
+	for _, history := range histories {
+		versions, err := a.States.Versions(history.Lineage)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		} else if err != nil {
+			return archived, err
+		}
+
+		for _, version := range versions[:len(versions)-1] {
+			if version.Archived || version.StoredAt.After(cutoff) {
+				continue
+			}
+
+			if err := a.archive(ctx, version); err != nil {
+				return archived, fmt.Errorf("failed to archive %s/%d: %w", version.Lineage, version.Serial, err)
+			}
+
+			version.Archived = true
+			archived = append(archived, version)
+		}
+	}
+
+	return archived, nil
+}
+
+func (a *Archiver) archive(ctx context.Context, version models.StateVersion) error {
+	_, data, err := a.States.Get(version.Lineage, version.Serial)
+	if err != nil {
+		return err
+	}
+
+	if _, err := a.Cold.Put(ctx, blobKey(version.Lineage, version.Serial), data, "application/json"); err != nil {
+		return err
+	}
+
+	return a.States.Archive(version.Lineage, version.Serial)
+}
+
+// Rehydrate returns the document of a stored version, reading archived ones
+// from cold storage. When cold storage has yet to restore the document, it
+// requests the restore and returns ErrRehydrating, for the caller to retry
+// later. Restored copies stay readable for RestoreDays.
+func (a *Archiver) Rehydrate(ctx context.Context, lineage string, serial int) ([]byte, error) {
+	_, data, err := a.States.Get(lineage, serial)
+	if !errors.Is(err, ErrArchived) {
+		return data, err
+	}
+
+	key := blobKey(lineage, serial)
+
+	restored, err := a.Cold.Restore(ctx, key, a.RestoreDays)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, ErrRehydrating
+	}
+
+	return a.Cold.Get(ctx, key)
+}
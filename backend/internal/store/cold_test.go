@@ -0,0 +1,118 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+// memoryCold is a cold store whose objects are restored on the second
+// request.
+type memoryCold struct {
+	objects  map[string][]byte
+	restores map[string]int
+	err      error
+}
+
+func (c *memoryCold) Put(_ context.Context, key string, body []byte, _ string) (string, error) {
+	if c.err != nil {
+		return "", c.err
+	}
+	c.objects[key] = body
+
+	return key, nil
+}
+
+func (c *memoryCold) Get(_ context.Context, key string) ([]byte, error) {
+	body, ok := c.objects[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return body, nil
+}
+
+func (c *memoryCold) Restore(_ context.Context, key string, _ int) (bool, error) {
+	c.restores[key]++
+
+	return c.restores[key] > 1, nil
+}
+
+func TestArchiver(t *testing.T) {
+	now := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+
+	newArchiver := func() *Archiver {
+		s := NewStateStore(time.Hour)
+		s.Put(models.StateVersion{Lineage: "prod", Serial: 1, StoredAt: now.AddDate(0, 0, -100)}, []byte(`{"serial": 1}`))
+		s.Put(models.StateVersion{Lineage: "prod", Serial: 2, StoredAt: now.AddDate(0, 0, -95)}, []byte(`{"serial": 2}`))
+		s.Put(models.StateVersion{Lineage: "prod", Serial: 3, StoredAt: now.AddDate(0, 0, -10)}, []byte(`{"serial": 3}`))
+		s.Put(models.StateVersion{Lineage: "dev", Serial: 1, StoredAt: now.AddDate(0, 0, -200)}, []byte(`{"serial": 1}`))
+
+		return &Archiver{
+			States:      s,
+			Cold:        &memoryCold{objects: make(map[string][]byte), restores: make(map[string]int)},
+			After:       90 * 24 * time.Hour,
+			RestoreDays: 7,
+		}
+	}
+
+	t.Run("archives old versions but the latest", func(t *testing.T) {
+		a := newArchiver()
+
+		archived, err := a.Archive(context.Background(), now)
+		require.NoError(t, err)
+		require.Len(t, archived, 2)
+		assert.Equal(t, 1, archived[0].Serial)
+		assert.Equal(t, 2, archived[1].Serial)
+		assert.True(t, archived[0].Archived)
+
+		assert.Equal(t, []byte(`{"serial": 1}`), a.Cold.(*memoryCold).objects[blobKey("prod", 1)])
+		_, _, err = a.States.Get("prod", 1)
+		assert.ErrorIs(t, err, ErrArchived)
+		_, _, err = a.States.Get("dev", 1)
+		assert.NoError(t, err, "the latest version stays")
+
+		archived, err = a.Archive(context.Background(), now)
+		require.NoError(t, err)
+		assert.Empty(t, archived, "archived versions are skipped")
+	})
+
+	t.Run("keeps versions when cold storage fails", func(t *testing.T) {
+		a := newArchiver()
+		a.Cold.(*memoryCold).err = errors.New("bucket unreachable")
+
+		archived, err := a.Archive(context.Background(), now)
+		assert.ErrorContains(t, err, "failed to archive prod/1: bucket unreachable")
+		assert.Empty(t, archived)
+
+		_, _, err = a.States.Get("prod", 1)
+		assert.NoError(t, err)
+	})
+
+	t.Run("rehydrates archived versions once restored", func(t *testing.T) {
+		a := newArchiver()
+		_, err := a.Archive(context.Background(), now)
+		require.NoError(t, err)
+
+		_, err = a.Rehydrate(context.Background(), "prod", 1)
+		assert.ErrorIs(t, err, ErrRehydrating)
+
+		data, err := a.Rehydrate(context.Background(), "prod", 1)
+		require.NoError(t, err)
+		assert.Equal(t, `{"serial": 1}`, string(data))
+
+		data, err = a.Rehydrate(context.Background(), "prod", 3)
+		require.NoError(t, err)
+		assert.Equal(t, `{"serial": 3}`, string(data), "versions in the backend are read directly")
+
+		_, err = a.Rehydrate(context.Background(), "prod", 9)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
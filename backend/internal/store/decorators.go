@@ -0,0 +1,65 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// DecoratorStore keeps the uploaded decorator scripts by name.
+type DecoratorStore struct {
+	mu         sync.Mutex
+	decorators map[string]models.Decorator
+}
+
+// NewDecoratorStore returns an empty store.
+func NewDecoratorStore() *DecoratorStore {
+	return &DecoratorStore{decorators: make(map[string]models.Decorator)}
+}
+
+// Put adds the decorator, replacing a decorator with the same name.
+func (s *DecoratorStore) Put(decorator models.Decorator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.decorators[decorator.Name] = decorator
+}
+
+// Get returns the decorator with the given name.
+func (s *DecoratorStore) Get(name string) (models.Decorator, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	decorator, ok := s.decorators[name]
+
+	return decorator, ok
+}
+
+// List returns every decorator ordered by name.
+func (s *DecoratorStore) List() []models.Decorator {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	decorators := make([]models.Decorator, 0, len(s.decorators))
+	for _, decorator := range s.decorators {
+		decorators = append(decorators, decorator)
+	}
+
+	sort.Slice(decorators, func(i, j int) bool { return decorators[i].Name < decorators[j].Name })
+
+	return decorators
+}
+
+// Delete removes the decorator and reports whether it existed.
+func (s *DecoratorStore) Delete(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.decorators[name]
+	delete(s.decorators, name)
+
+	return ok
+}
@@ -0,0 +1,31 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestDecoratorStore(t *testing.T) {
+	s := NewDecoratorStore()
+	s.Put(models.Decorator{Name: "tags", Script: "v1"})
+	s.Put(models.Decorator{Name: "owners", Script: "v1"})
+	s.Put(models.Decorator{Name: "tags", Script: "v2"})
+
+	decorators := s.List()
+	require.Len(t, decorators, 2)
+	assert.Equal(t, "owners", decorators[0].Name)
+
+	decorator, ok := s.Get("tags")
+	require.True(t, ok)
+	assert.Equal(t, "v2", decorator.Script, "a decorator is replaced by name")
+
+	assert.True(t, s.Delete("tags"))
+	assert.False(t, s.Delete("tags"))
+	_, ok = s.Get("tags")
+	assert.False(t, ok)
+}
@@ -0,0 +1,65 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// GoldenModuleStore keeps the registered golden modules by name.
+type GoldenModuleStore struct {
+	mu      sync.Mutex
+	modules map[string]models.GoldenModule
+}
+
+// NewGoldenModuleStore returns an empty store.
+func NewGoldenModuleStore() *GoldenModuleStore {
+	return &GoldenModuleStore{modules: make(map[string]models.GoldenModule)}
+}
+
+// Put adds the golden module, replacing a golden module with the same name.
+func (s *GoldenModuleStore) Put(module models.GoldenModule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.modules[module.Name] = module
+}
+
+// Get returns the golden module with the given name.
+func (s *GoldenModuleStore) Get(name string) (models.GoldenModule, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	module, ok := s.modules[name]
+
+	return module, ok
+}
+
+// List returns every golden module ordered by name.
+func (s *GoldenModuleStore) List() []models.GoldenModule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	modules := make([]models.GoldenModule, 0, len(s.modules))
+	for _, module := range s.modules {
+		modules = append(modules, module)
+	}
+
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Name < modules[j].Name })
+
+	return modules
+}
+
+// Delete removes the golden module and reports whether it existed.
+func (s *GoldenModuleStore) Delete(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.modules[name]
+	delete(s.modules, name)
+
+	return ok
+}
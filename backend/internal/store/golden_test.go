@@ -0,0 +1,31 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestGoldenModuleStore(t *testing.T) {
+	s := NewGoldenModuleStore()
+	s.Put(models.GoldenModule{Name: "s3", Module: "bucket", Resources: []string{"aws_s3_bucket.this"}})
+	s.Put(models.GoldenModule{Name: "rds", Module: "db", Resources: []string{"aws_db_instance.this"}})
+	s.Put(models.GoldenModule{Name: "s3", Module: "bucket", Resources: []string{"aws_s3_bucket.this", "aws_s3_bucket_policy.this"}})
+
+	modules := s.List()
+	require.Len(t, modules, 2)
+	assert.Equal(t, "rds", modules[0].Name)
+
+	module, ok := s.Get("s3")
+	require.True(t, ok)
+	assert.Len(t, module.Resources, 2, "a golden module is replaced by name")
+
+	assert.True(t, s.Delete("s3"))
+	assert.False(t, s.Delete("s3"))
+	_, ok = s.Get("s3")
+	assert.False(t, ok)
+}
@@ -0,0 +1,122 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// ParsedGraph is a parsed state and the graph built from it.
+type ParsedGraph struct {
+	State *models.TerraformState
+	Graph *models.Graph
+}
+
+// GraphCache keeps the states parsed and graphs built from recently posted
+// documents, keyed by the hash of the document and of how the graph was
+// built, so that requests posting the same document again skip the work. It
+// evicts the least recently used entries beyond maxBytes, counting each
+// entry as the size of its document, which the memory it takes grows with.
+// Cached states and graphs are shared between callers and must not be
+// modified.
+type GraphCache struct {
+	mu        sync.Mutex
+	maxBytes  int64
+	bytes     int64
+	order     *list.List
+	entries   map[string]*list.Element
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+type graphCacheEntry struct {
+	key    string
+	parsed ParsedGraph
+	size   int64
+}
+
+// NewGraphCache returns a cache of up to maxBytes of documents. A cache of 0
+// bytes keeps nothing but still counts its misses.
+func NewGraphCache(maxBytes int64) *GraphCache {
+	return &GraphCache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Enabled reports whether the cache keeps anything.
+func (c *GraphCache) Enabled() bool {
+	return c.maxBytes > 0
+}
+
+// Get returns the entry cached under the key, making it the most recently
+// used.
+func (c *GraphCache) Get(key string) (ParsedGraph, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return ParsedGraph{}, false
+	}
+
+	c.hits++
+	c.order.MoveToFront(element)
+
+	return element.Value.(*graphCacheEntry).parsed, true
+}
+
+// Put caches the state and graph built from a document of size bytes under
+// the key, evicting the least recently used entries to make room. Documents
+// larger than the cache are not cached.
+func (c *GraphCache) Put(key string, parsed ParsedGraph, size int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.maxBytes == 0 || size > c.maxBytes {
+		return
+	}
+
+	if element, ok := c.entries[key]; ok {
+		c.bytes -= element.Value.(*graphCacheEntry).size
+		c.order.Remove(element)
+	}
+
+	c.entries[key] = c.order.PushFront(&graphCacheEntry{key: key, parsed: parsed, size: size})
+	c.bytes += size
+
+	for c.bytes > c.maxBytes {
+		oldest := c.order.Back()
+		entry := oldest.Value.(*graphCacheEntry)
+		c.order.Remove(oldest)
+		delete(c.entries, entry.key)
+		c.bytes -= entry.size
+		c.evictions++
+	}
+}
+
+// Stats reports the size of the cache and how often it was hit.
+func (c *GraphCache) Stats() models.GraphCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := models.GraphCacheStats{
+		Entries:   len(c.entries),
+		Bytes:     c.bytes,
+		MaxBytes:  c.maxBytes,
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+	if lookups := c.hits + c.misses; lookups > 0 {
+		stats.HitRate = float64(c.hits) / float64(lookups)
+	}
+
+	return stats
+}
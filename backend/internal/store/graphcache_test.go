@@ -0,0 +1,119 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func parsedGraph(lineage string) ParsedGraph {
+	return ParsedGraph{State: &models.TerraformState{Lineage: lineage}, Graph: &models.Graph{}}
+}
+
+func TestGraphCache(t *testing.T) {
+	t.Run("get and put", func(t *testing.T) {
+		c := NewGraphCache(100)
+
+		_, ok := c.Get("a")
+		assert.False(t, ok)
+
+		c.Put("a", parsedGraph("lineage-a"), 10)
+
+		parsed, ok := c.Get("a")
+		require.True(t, ok)
+		assert.Equal(t, "lineage-a", parsed.State.Lineage)
+
+		assert.Equal(t, models.GraphCacheStats{
+			Entries:  1,
+			Bytes:    10,
+			MaxBytes: 100,
+			Hits:     1,
+			Misses:   1,
+			HitRate:  0.5,
+		}, c.Stats())
+	})
+
+	t.Run("put replaces the entry", func(t *testing.T) {
+		c := NewGraphCache(100)
+		c.Put("a", parsedGraph("first"), 10)
+		c.Put("a", parsedGraph("second"), 20)
+
+		parsed, ok := c.Get("a")
+		require.True(t, ok)
+		assert.Equal(t, "second", parsed.State.Lineage)
+		assert.Equal(t, 1, c.Stats().Entries)
+		assert.Equal(t, int64(20), c.Stats().Bytes)
+	})
+
+	t.Run("evicts the least recently used entries", func(t *testing.T) {
+		c := NewGraphCache(30)
+		c.Put("a", parsedGraph("a"), 10)
+		c.Put("b", parsedGraph("b"), 10)
+		c.Put("c", parsedGraph("c"), 10)
+
+		_, ok := c.Get("a")
+		require.True(t, ok)
+
+		c.Put("d", parsedGraph("d"), 15)
+
+		_, ok = c.Get("b")
+		assert.False(t, ok)
+		_, ok = c.Get("c")
+		assert.False(t, ok)
+		_, ok = c.Get("a")
+		assert.True(t, ok)
+
+		stats := c.Stats()
+		assert.Equal(t, 2, stats.Entries)
+		assert.Equal(t, int64(25), stats.Bytes)
+		assert.Equal(t, int64(2), stats.Evictions)
+	})
+
+	t.Run("skips documents larger than the cache", func(t *testing.T) {
+		c := NewGraphCache(10)
+		c.Put("a", parsedGraph("a"), 11)
+
+		_, ok := c.Get("a")
+		assert.False(t, ok)
+		assert.Zero(t, c.Stats().Entries)
+	})
+
+	t.Run("a cache of 0 bytes keeps nothing", func(t *testing.T) {
+		c := NewGraphCache(0)
+		assert.False(t, c.Enabled())
+
+		c.Put("a", parsedGraph("a"), 0)
+
+		_, ok := c.Get("a")
+		assert.False(t, ok)
+		assert.Equal(t, int64(1), c.Stats().Misses)
+	})
+
+	t.Run("is safe for concurrent use", func(t *testing.T) {
+		c := NewGraphCache(50)
+
+		var wg sync.WaitGroup
+		for i := range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := range 100 {
+					key := string(rune('a' + (i+j)%10))
+					if _, ok := c.Get(key); !ok {
+						c.Put(key, parsedGraph(key), 10)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		stats := c.Stats()
+		assert.Equal(t, int64(800), stats.Hits+stats.Misses)
+		assert.LessOrEqual(t, stats.Bytes, int64(50))
+	})
+}
@@ -0,0 +1,105 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"crypto/sha256"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// KeyStore is the KeyBackend that keeps API keys in memory. Secrets are kept
+// hashed, so that the store holds nothing a client could present.
+type KeyStore struct {
+	mu      sync.Mutex
+	keys    map[string]*models.APIKey
+	secrets map[[sha256.Size]byte]string
+}
+
+// NewKeyStore returns an empty key store.
+func NewKeyStore() *KeyStore {
+	return &KeyStore{
+		keys:    make(map[string]*models.APIKey),
+		secrets: make(map[[sha256.Size]byte]string),
+	}
+}
+
+func (s *KeyStore) Create(key models.APIKey, secret string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key.ID] = &key
+	s.secrets[sha256.Sum256([]byte(secret))] = key.ID
+
+	return nil
+}
+
+func (s *KeyStore) Use(secret string, now time.Time) (models.APIKey, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, ok := s.secrets[sha256.Sum256([]byte(secret))]
+	if !ok {
+		return models.APIKey{}, false, nil
+	}
+
+	key := s.keys[id]
+	if key.RevokedAt != nil {
+		return models.APIKey{}, false, nil
+	}
+
+	key.Requests++
+	key.LastUsedAt = &now
+
+	return *key, true, nil
+}
+
+func (s *KeyStore) Get(id string) (models.APIKey, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[id]
+	if !ok {
+		return models.APIKey{}, false, nil
+	}
+
+	return *key, true, nil
+}
+
+func (s *KeyStore) Revoke(id string, now time.Time) (models.APIKey, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[id]
+	if !ok {
+		return models.APIKey{}, false, nil
+	}
+
+	if key.RevokedAt == nil {
+		key.RevokedAt = &now
+	}
+
+	return *key, true, nil
+}
+
+func (s *KeyStore) List() ([]models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]models.APIKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, *key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+
+	return keys, nil
+}
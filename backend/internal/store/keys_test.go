@@ -0,0 +1,92 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestKeyStore(t *testing.T) {
+	testKeyBackend(t, func(t *testing.T) KeyBackend { return NewKeyStore() })
+}
+
+func TestSQLKeys(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *sql.DB, dialect Dialect) {
+		testKeyBackend(t, func(t *testing.T) KeyBackend {
+			_, err := db.Exec(`DELETE FROM api_keys`)
+			require.NoError(t, err)
+			return NewSQLKeys(db, dialect)
+		})
+	})
+}
+
+func testKeyBackend(t *testing.T, newBackend func(t *testing.T) KeyBackend) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	t.Run("keys record their usage", func(t *testing.T) {
+		s := newBackend(t)
+		require.NoError(t, s.Create(models.APIKey{ID: "k1", Name: "ci", Role: models.RoleMember, Project: "network", CreatedAt: now}, "tsk_secret"))
+
+		_, ok, err := s.Use("tsk_other", now)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		s.Use("tsk_secret", now)
+		key, ok, err := s.Use("tsk_secret", now.Add(time.Minute))
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, "ci", key.Name)
+		assert.Equal(t, "network", key.Project)
+		assert.EqualValues(t, 2, key.Requests)
+		assert.Equal(t, now.Add(time.Minute), *key.LastUsedAt)
+
+		stored, ok, err := s.Get("k1")
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, key, stored)
+	})
+
+	t.Run("revoked keys are kept but rejected", func(t *testing.T) {
+		s := newBackend(t)
+		s.Create(models.APIKey{ID: "k1", CreatedAt: now}, "tsk_secret")
+
+		key, ok, err := s.Revoke("k1", now)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, now, *key.RevokedAt)
+
+		again, _, err := s.Revoke("k1", now.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, now, *again.RevokedAt, "revoking twice keeps the first time")
+
+		_, ok, err = s.Use("tsk_secret", now)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		keys, err := s.List()
+		require.NoError(t, err)
+		assert.Len(t, keys, 1)
+
+		_, ok, err = s.Revoke("missing", now)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("keys are listed oldest first", func(t *testing.T) {
+		s := newBackend(t)
+		s.Create(models.APIKey{ID: "b", CreatedAt: now.Add(time.Hour)}, "tsk_b")
+		s.Create(models.APIKey{ID: "c", CreatedAt: now}, "tsk_c")
+		s.Create(models.APIKey{ID: "a", CreatedAt: now}, "tsk_a")
+
+		keys, err := s.List()
+
+		require.NoError(t, err)
+		require.Len(t, keys, 3)
+		assert.Equal(t, []string{"a", "c", "b"}, []string{keys[0].ID, keys[1].ID, keys[2].ID})
+	})
+}
@@ -0,0 +1,70 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// LockStore records the current lock of each state lineage.
+type LockStore struct {
+	mu    sync.Mutex
+	locks map[string]models.LockInfo
+}
+
+// NewLockStore returns an empty lock store.
+func NewLockStore() *LockStore {
+	return &LockStore{locks: make(map[string]models.LockInfo)}
+}
+
+// Put records the lock held on the lineage, replacing any previous one.
+func (s *LockStore) Put(lineage string, lock models.LockInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.locks[lineage] = lock
+}
+
+// Get returns the lock held on the lineage, if any.
+func (s *LockStore) Get(lineage string) (*models.LockInfo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, ok := s.locks[lineage]
+	if !ok {
+		return nil, false
+	}
+
+	return &lock, true
+}
+
+// Delete forgets the lock on the lineage and reports whether there was one.
+func (s *LockStore) Delete(lineage string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.locks[lineage]
+	delete(s.locks, lineage)
+
+	return ok
+}
+
+// All returns the recorded locks ordered by lineage.
+func (s *LockStore) All() []models.ArchivedLock {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	locks := make([]models.ArchivedLock, 0, len(s.locks))
+	for lineage, lock := range s.locks {
+		locks = append(locks, models.ArchivedLock{Lineage: lineage, Lock: lock})
+	}
+
+	sort.Slice(locks, func(i, j int) bool {
+		return locks[i].Lineage < locks[j].Lineage
+	})
+
+	return locks
+}
@@ -0,0 +1,49 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestLockStore(t *testing.T) {
+	t.Run("put, get and delete", func(t *testing.T) {
+		s := NewLockStore()
+		s.Put("lineage-1", models.LockInfo{ID: "a", Who: "ci-runner-42"})
+
+		lock, ok := s.Get("lineage-1")
+		require.True(t, ok)
+		assert.Equal(t, "ci-runner-42", lock.Who)
+
+		assert.True(t, s.Delete("lineage-1"))
+		assert.False(t, s.Delete("lineage-1"))
+
+		_, ok = s.Get("lineage-1")
+		assert.False(t, ok)
+	})
+
+	t.Run("put replaces the lock", func(t *testing.T) {
+		s := NewLockStore()
+		s.Put("lineage-1", models.LockInfo{ID: "a"})
+		s.Put("lineage-1", models.LockInfo{ID: "b"})
+
+		lock, ok := s.Get("lineage-1")
+		require.True(t, ok)
+		assert.Equal(t, "b", lock.ID)
+	})
+
+	t.Run("returned locks are copies", func(t *testing.T) {
+		s := NewLockStore()
+		s.Put("lineage-1", models.LockInfo{ID: "a"})
+
+		lock, _ := s.Get("lineage-1")
+		lock.ID = "changed"
+
+		stored, _ := s.Get("lineage-1")
+		assert.Equal(t, "a", stored.ID)
+	})
+}
@@ -0,0 +1,225 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationFiles holds the schema migrations of each dialect, as
+// NNNN_name.up.sql and NNNN_name.down.sql pairs numbered from 1.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// migrationLockID names the Postgres advisory lock held while migrating.
+const migrationLockID = 0x7465727261 // "terra"
+
+// Migration upgrades the schema of a SQLStateStore to its version, or
+// downgrades it back to the previous one.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Migrations returns the migrations of the dialect ordered by version.
+func Migrations(dialect Dialect) ([]Migration, error) {
+	dir := path.Join("migrations", string(dialect))
+
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("unknown dialect %q", dialect)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		base, direction, ok := strings.Cut(strings.TrimSuffix(entry.Name(), ".sql"), ".")
+		number, name, found := strings.Cut(base, "_")
+		version, err := strconv.Atoi(number)
+		if !ok || !found || err != nil || version < 1 {
+			return nil, fmt.Errorf("migration %s: expected NNNN_name.up.sql or NNNN_name.down.sql", entry.Name())
+		}
+
+		data, err := fs.ReadFile(migrationFiles, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+
+		switch direction {
+		case "up":
+			migration.Up = string(data)
+		case "down":
+			migration.Down = string(data)
+		default:
+			return nil, fmt.Errorf("migration %s: expected NNNN_name.up.sql or NNNN_name.down.sql", entry.Name())
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("migration %d is missing", i+1)
+		}
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d: expected both an up and a down file", migration.Version)
+		}
+	}
+
+	return migrations, nil
+}
+
+// SchemaVersion returns the version of the database schema, 0 for a database
+// that was never migrated. It creates the schema_version table, where each
+// applied migration is recorded, unless it exists.
+func SchemaVersion(ctx context.Context, db *sql.DB) (int, error) {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_version (
+		version BIGINT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return 0, fmt.Errorf("creating schema_version: %w", err)
+	}
+
+	var version sql.NullInt64
+	if err := db.QueryRowContext(ctx, `SELECT MAX(version) FROM schema_version`).Scan(&version); err != nil {
+		return 0, err
+	}
+
+	return int(version.Int64), nil
+}
+
+// Migrate upgrades or downgrades the database schema to the target version,
+// one migration per transaction, and returns the migrations it ran. A
+// database whose schema is newer than every known migration is left alone
+// when upgrading, since an older release cannot know how to handle it. On
+// Postgres, replicas starting together take turns through an advisory lock.
+func Migrate(ctx context.Context, db *sql.DB, dialect Dialect, target int) ([]Migration, error) {
+	migrations, err := Migrations(dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	if dialect == DialectPostgres {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+			return nil, fmt.Errorf("locking migrations: %w", err)
+		}
+		defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+	}
+
+	if target < 0 || target > len(migrations) {
+		return nil, fmt.Errorf("unknown schema version %d: the latest is %d", target, len(migrations))
+	}
+
+	current, err := SchemaVersion(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if current > len(migrations) {
+		return nil, fmt.Errorf("schema version %d is newer than this release, which knows up to %d", current, len(migrations))
+	}
+
+	ran := []Migration{}
+
+	for version := current + 1; version <= target; version++ {
+		migration := migrations[version-1]
+		err := runMigration(ctx, db, dialect, migration.Up,
+			`INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)`,
+			migration.Version, migration.Name, time.Now().UnixNano())
+		if err != nil {
+			return ran, fmt.Errorf("migration %d_%s up: %w", migration.Version, migration.Name, err)
+		}
+		ran = append(ran, migration)
+	}
+
+	for version := current; version > target; version-- {
+		migration := migrations[version-1]
+		err := runMigration(ctx, db, dialect, migration.Down,
+			`DELETE FROM schema_version WHERE version = ?`, migration.Version)
+		if err != nil {
+			return ran, fmt.Errorf("migration %d_%s down: %w", migration.Version, migration.Name, err)
+		}
+		ran = append(ran, migration)
+	}
+
+	return ran, nil
+}
+
+// runMigration runs the statements of a migration file and records it in
+// schema_version in a single transaction.
+func runMigration(ctx context.Context, db *sql.DB, dialect Dialect, script, record string, args ...any) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range splitStatements(script) {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, dialect.rebind(record), args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// splitStatements splits a migration file into statements that end with a
+// semicolon at the end of a line, dropping comment lines.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+
+	for line := range strings.SplitSeq(script, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+
+		current.WriteString(line + "\n")
+
+		if strings.HasSuffix(strings.TrimSpace(line), ";") {
+			if statement := strings.TrimSuffix(strings.TrimSpace(current.String()), ";"); statement != "" {
+				statements = append(statements, statement)
+			}
+			current.Reset()
+		}
+	}
+
+	if statement := strings.TrimSpace(current.String()); statement != "" {
+		statements = append(statements, statement)
+	}
+
+	return statements
+}
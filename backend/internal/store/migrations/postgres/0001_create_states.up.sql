@@ -0,0 +1,20 @@
+-- Stored state versions and the trash. Times are Unix nanoseconds, which
+-- both dialects compare and sort alike. IF NOT EXISTS adopts the tables of
+-- stores created before migrations.
+CREATE TABLE IF NOT EXISTS state_versions (
+	lineage TEXT NOT NULL,
+	serial BIGINT NOT NULL,
+	terraform_version TEXT NOT NULL,
+	resources BIGINT NOT NULL,
+	digest TEXT NOT NULL,
+	size BIGINT NOT NULL,
+	stored_at BIGINT NOT NULL,
+	project TEXT NOT NULL,
+	data BYTEA,
+	PRIMARY KEY (lineage, serial)
+);
+
+CREATE TABLE IF NOT EXISTS trashed_lineages (
+	lineage TEXT PRIMARY KEY,
+	deleted_at BIGINT NOT NULL
+);
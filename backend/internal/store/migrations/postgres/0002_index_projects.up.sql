@@ -0,0 +1,2 @@
+-- Stored bytes are summed per project on every quota check.
+CREATE INDEX state_versions_project ON state_versions (project);
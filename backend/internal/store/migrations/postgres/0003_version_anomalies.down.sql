@@ -0,0 +1 @@
+ALTER TABLE state_versions DROP COLUMN anomalies;
@@ -0,0 +1,2 @@
+-- Versions whose document was moved to cold storage, which keeps no data.
+ALTER TABLE state_versions ADD COLUMN archived BOOLEAN NOT NULL DEFAULT FALSE;
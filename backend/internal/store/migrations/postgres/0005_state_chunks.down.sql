@@ -0,0 +1,15 @@
+-- Moves the documents back into state_versions. Chunks kept in the blob
+-- store are not moved back.
+UPDATE state_versions SET data = (
+	SELECT string_agg(c.data, ''::BYTEA ORDER BY vc.position)
+	FROM state_version_chunks vc JOIN state_chunks c ON c.digest = vc.digest
+	WHERE vc.lineage = state_versions.lineage AND vc.serial = state_versions.serial
+)
+WHERE EXISTS (
+	SELECT 1 FROM state_version_chunks vc
+	WHERE vc.lineage = state_versions.lineage AND vc.serial = state_versions.serial
+);
+
+DROP TABLE state_version_chunks;
+
+DROP TABLE state_chunks;
@@ -0,0 +1,19 @@
+-- Documents are stored as content-addressed chunks, shared by the versions
+-- that contain them. Versions stored before keep their document in
+-- state_versions.data or in the blob store.
+CREATE TABLE state_chunks (
+	digest TEXT PRIMARY KEY,
+	size BIGINT NOT NULL,
+	data BYTEA
+);
+
+CREATE TABLE state_version_chunks (
+	lineage TEXT NOT NULL,
+	serial BIGINT NOT NULL,
+	position INTEGER NOT NULL,
+	digest TEXT NOT NULL REFERENCES state_chunks (digest),
+	PRIMARY KEY (lineage, serial, position)
+);
+
+-- Unused chunks are looked up by digest when versions are dropped.
+CREATE INDEX state_version_chunks_digest ON state_version_chunks (digest);
@@ -0,0 +1,8 @@
+-- Graphs of the stored versions, as the JSON of a StoredGraph: a full graph
+-- or a patch of the graph of an earlier version.
+CREATE TABLE state_graphs (
+	lineage TEXT NOT NULL,
+	serial BIGINT NOT NULL,
+	graph TEXT NOT NULL,
+	PRIMARY KEY (lineage, serial)
+);
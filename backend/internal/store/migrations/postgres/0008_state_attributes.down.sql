@@ -0,0 +1,3 @@
+DROP TABLE state_version_attributes;
+
+DROP TABLE state_attribute_values;
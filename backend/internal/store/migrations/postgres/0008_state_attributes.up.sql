@@ -0,0 +1,25 @@
+-- Attribute values of the instances of the stored versions, shared by the
+-- versions that have them, for searching. terms holds the search terms of
+-- the attribute key and value separated by spaces, indexed as an array.
+-- Versions stored before are indexed when they are stored again.
+CREATE TABLE state_attribute_values (
+	digest TEXT PRIMARY KEY,
+	address TEXT NOT NULL,
+	module TEXT NOT NULL,
+	type TEXT NOT NULL,
+	attribute TEXT NOT NULL,
+	value TEXT NOT NULL,
+	terms TEXT NOT NULL
+);
+
+CREATE INDEX state_attribute_values_terms ON state_attribute_values USING GIN (string_to_array(terms, ' '));
+
+CREATE TABLE state_version_attributes (
+	lineage TEXT NOT NULL,
+	serial BIGINT NOT NULL,
+	digest TEXT NOT NULL REFERENCES state_attribute_values (digest),
+	PRIMARY KEY (lineage, serial, digest)
+);
+
+-- Unused values are looked up by digest when versions are dropped.
+CREATE INDEX state_version_attributes_digest ON state_version_attributes (digest);
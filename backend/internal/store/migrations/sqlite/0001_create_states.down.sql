@@ -0,0 +1,2 @@
+DROP TABLE trashed_lineages;
+DROP TABLE state_versions;
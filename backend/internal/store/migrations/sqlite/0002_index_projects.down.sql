@@ -0,0 +1 @@
+DROP INDEX state_versions_project;
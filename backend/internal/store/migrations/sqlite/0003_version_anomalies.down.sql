@@ -0,0 +1,22 @@
+-- Rebuilds the table, since older SQLite releases cannot drop columns.
+CREATE TABLE state_versions_previous (
+	lineage TEXT NOT NULL,
+	serial BIGINT NOT NULL,
+	terraform_version TEXT NOT NULL,
+	resources BIGINT NOT NULL,
+	digest TEXT NOT NULL,
+	size BIGINT NOT NULL,
+	stored_at BIGINT NOT NULL,
+	project TEXT NOT NULL,
+	data BLOB,
+	PRIMARY KEY (lineage, serial)
+);
+
+INSERT INTO state_versions_previous
+SELECT lineage, serial, terraform_version, resources, digest, size, stored_at, project, data FROM state_versions;
+
+DROP TABLE state_versions;
+
+ALTER TABLE state_versions_previous RENAME TO state_versions;
+
+CREATE INDEX state_versions_project ON state_versions (project);
@@ -0,0 +1,2 @@
+-- Anomalies flagged against the previous version, as a JSON array of alerts.
+ALTER TABLE state_versions ADD COLUMN anomalies TEXT;
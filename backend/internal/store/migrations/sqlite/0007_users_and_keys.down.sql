@@ -0,0 +1,5 @@
+DROP TABLE api_keys;
+
+DROP TABLE removed_users;
+
+DROP TABLE users;
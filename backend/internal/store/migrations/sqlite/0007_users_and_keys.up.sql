@@ -0,0 +1,30 @@
+-- Users allowed to sign in, with their password hash or, for invited users,
+-- the hash of their invitation token.
+CREATE TABLE users (
+	username TEXT PRIMARY KEY,
+	role TEXT NOT NULL,
+	created_at BIGINT NOT NULL,
+	password_hash TEXT NOT NULL DEFAULT '',
+	invitation_hash TEXT UNIQUE,
+	invitation_expires_at BIGINT
+);
+
+-- Users removed through the admin API, which the users file does not add
+-- back.
+CREATE TABLE removed_users (
+	username TEXT PRIMARY KEY,
+	removed_at BIGINT NOT NULL
+);
+
+-- API keys with the hash of their secret and their usage.
+CREATE TABLE api_keys (
+	id TEXT PRIMARY KEY,
+	secret_hash TEXT NOT NULL UNIQUE,
+	name TEXT NOT NULL,
+	role TEXT NOT NULL,
+	project TEXT NOT NULL DEFAULT '',
+	created_at BIGINT NOT NULL,
+	revoked_at BIGINT,
+	last_used_at BIGINT,
+	requests BIGINT NOT NULL DEFAULT 0
+);
@@ -0,0 +1,9 @@
+DROP TRIGGER state_attribute_values_delete;
+
+DROP TRIGGER state_attribute_values_insert;
+
+DROP TABLE state_attribute_terms;
+
+DROP TABLE state_version_attributes;
+
+DROP TABLE state_attribute_values;
@@ -0,0 +1,42 @@
+-- Attribute values of the instances of the stored versions, shared by the
+-- versions that have them, for searching. terms holds the search terms of
+-- the attribute key and value separated by spaces, indexed by the
+-- state_attribute_terms full-text index. Versions stored before are indexed
+-- when they are stored again.
+CREATE TABLE state_attribute_values (
+	id INTEGER PRIMARY KEY,
+	digest TEXT NOT NULL UNIQUE,
+	address TEXT NOT NULL,
+	module TEXT NOT NULL,
+	type TEXT NOT NULL,
+	attribute TEXT NOT NULL,
+	value TEXT NOT NULL,
+	terms TEXT NOT NULL
+);
+
+CREATE TABLE state_version_attributes (
+	lineage TEXT NOT NULL,
+	serial BIGINT NOT NULL,
+	digest TEXT NOT NULL REFERENCES state_attribute_values (digest),
+	PRIMARY KEY (lineage, serial, digest)
+);
+
+-- Unused values are looked up by digest when versions are dropped.
+CREATE INDEX state_version_attributes_digest ON state_version_attributes (digest);
+
+-- Terms are runs of letters, digits, dots, dashes and underscores, so those
+-- are the only characters that do not separate them.
+CREATE VIRTUAL TABLE state_attribute_terms USING fts5 (
+	terms,
+	content = 'state_attribute_values',
+	content_rowid = 'id',
+	tokenize = "unicode61 remove_diacritics 0 tokenchars '.-_'"
+);
+
+-- The triggers keep the index in step with the values. Their bodies stay on
+-- one line, since statements end with a semicolon at the end of a line.
+CREATE TRIGGER state_attribute_values_insert AFTER INSERT ON state_attribute_values
+BEGIN INSERT INTO state_attribute_terms (rowid, terms) VALUES (new.id, new.terms); END;
+
+CREATE TRIGGER state_attribute_values_delete AFTER DELETE ON state_attribute_values
+BEGIN INSERT INTO state_attribute_terms (state_attribute_terms, rowid, terms) VALUES ('delete', old.id, old.terms); END;
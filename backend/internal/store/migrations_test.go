@@ -0,0 +1,52 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrations(t *testing.T) {
+	var names []string
+	for _, dialect := range []Dialect{DialectSQLite, DialectPostgres} {
+		migrations, err := Migrations(dialect)
+		require.NoError(t, err, dialect)
+		require.NotEmpty(t, migrations, dialect)
+
+		var dialectNames []string
+		for i, migration := range migrations {
+			assert.Equal(t, i+1, migration.Version)
+			assert.NotEmpty(t, splitStatements(migration.Up), "%s %d up", dialect, migration.Version)
+			assert.NotEmpty(t, splitStatements(migration.Down), "%s %d down", dialect, migration.Version)
+			dialectNames = append(dialectNames, migration.Name)
+		}
+
+		// Both dialects must go through the same versions.
+		if names == nil {
+			names = dialectNames
+		}
+		assert.Equal(t, names, dialectNames, dialect)
+	}
+
+	_, err := Migrations("mysql")
+	assert.EqualError(t, err, `unknown dialect "mysql"`)
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- Two tables.
+CREATE TABLE a (
+	id TEXT -- inline comments stay
+);
+
+CREATE TABLE b (id TEXT);
+DROP TABLE c`
+
+	assert.Equal(t, []string{
+		"CREATE TABLE a (\n\tid TEXT -- inline comments stay\n)",
+		"CREATE TABLE b (id TEXT)",
+		"DROP TABLE c",
+	}, splitStatements(script))
+}
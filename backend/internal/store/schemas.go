@@ -0,0 +1,61 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"sync"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// SchemaStore holds up to a fixed number of provider schemas documents keyed
+// by digest, evicting the least recently stored. Stored schemas are shared
+// between callers and must not be modified.
+type SchemaStore struct {
+	mu         sync.Mutex
+	schemas    map[string]*models.ProviderSchemas
+	order      []string
+	maxEntries int
+}
+
+// NewSchemaStore returns a store that keeps up to maxEntries documents.
+func NewSchemaStore(maxEntries int) *SchemaStore {
+	return &SchemaStore{
+		schemas:    make(map[string]*models.ProviderSchemas),
+		maxEntries: maxEntries,
+	}
+}
+
+// Put stores the schemas under their digest. Storing a digest again replaces
+// the schemas and makes them the most recently stored.
+func (s *SchemaStore) Put(digest string, schemas *models.ProviderSchemas) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.schemas[digest]; exists {
+		for i, stored := range s.order {
+			if stored == digest {
+				s.order = append(s.order[:i], s.order[i+1:]...)
+				break
+			}
+		}
+	}
+
+	s.schemas[digest] = schemas
+	s.order = append(s.order, digest)
+
+	for len(s.order) > s.maxEntries {
+		delete(s.schemas, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+// Get returns the schemas stored under the digest.
+func (s *SchemaStore) Get(digest string) (*models.ProviderSchemas, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schemas, ok := s.schemas[digest]
+
+	return schemas, ok
+}
@@ -0,0 +1,29 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestSchemaStore(t *testing.T) {
+	s := NewSchemaStore(2)
+
+	s.Put("a", &models.ProviderSchemas{FormatVersion: "1.0"})
+	s.Put("b", &models.ProviderSchemas{FormatVersion: "1.0"})
+	s.Put("a", &models.ProviderSchemas{FormatVersion: "1.1"})
+	s.Put("c", &models.ProviderSchemas{FormatVersion: "1.0"})
+
+	_, ok := s.Get("b")
+	assert.False(t, ok, "the least recently stored digest is evicted")
+
+	schemas, ok := s.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "1.1", schemas.FormatVersion)
+
+	_, ok = s.Get("c")
+	assert.True(t, ok)
+}
@@ -0,0 +1,77 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// SessionStore holds login sessions by token until they expire. Tokens are
+// kept hashed, so that the store holds nothing a client could present.
+type SessionStore struct {
+	mu       sync.Mutex
+	sessions map[[sha256.Size]byte]models.Session
+	ttl      time.Duration
+}
+
+// NewSessionStore returns a store whose sessions last for the given duration.
+func NewSessionStore(ttl time.Duration) *SessionStore {
+	return &SessionStore{
+		sessions: make(map[[sha256.Size]byte]models.Session),
+		ttl:      ttl,
+	}
+}
+
+// Create starts a session of the user under the token.
+func (s *SessionStore) Create(token, username string, now time.Time) models.Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(now)
+
+	session := models.Session{Username: username, CreatedAt: now, ExpiresAt: now.Add(s.ttl)}
+	s.sessions[sha256.Sum256([]byte(token))] = session
+
+	return session
+}
+
+// Get returns the session of the token, unless it has expired.
+func (s *SessionStore) Get(token string, now time.Time) (models.Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := sha256.Sum256([]byte(token))
+
+	session, ok := s.sessions[key]
+	if !ok || !now.Before(session.ExpiresAt) {
+		delete(s.sessions, key)
+		return models.Session{}, false
+	}
+
+	return session, true
+}
+
+// Delete ends the session of the token and reports whether there was one.
+func (s *SessionStore) Delete(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := sha256.Sum256([]byte(token))
+
+	_, ok := s.sessions[key]
+	delete(s.sessions, key)
+
+	return ok
+}
+
+func (s *SessionStore) expire(now time.Time) {
+	for key, session := range s.sessions {
+		if !now.Before(session.ExpiresAt) {
+			delete(s.sessions, key)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestSessionStore(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	t.Run("sessions last until they expire", func(t *testing.T) {
+		s := NewSessionStore(time.Hour)
+
+		created := s.Create("token", "alice", now)
+		assert.Equal(t, models.Session{Username: "alice", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}, created)
+
+		session, ok := s.Get("token", now.Add(59*time.Minute))
+		require.True(t, ok)
+		assert.Equal(t, created, session)
+
+		_, ok = s.Get("token", now.Add(time.Hour))
+		assert.False(t, ok)
+		assert.Empty(t, s.sessions)
+	})
+
+	t.Run("unknown tokens have no session", func(t *testing.T) {
+		s := NewSessionStore(time.Hour)
+		s.Create("token", "alice", now)
+
+		_, ok := s.Get("other", now)
+		assert.False(t, ok)
+	})
+
+	t.Run("delete ends the session", func(t *testing.T) {
+		s := NewSessionStore(time.Hour)
+		s.Create("token", "alice", now)
+
+		assert.True(t, s.Delete("token"))
+		assert.False(t, s.Delete("token"))
+
+		_, ok := s.Get("token", now)
+		assert.False(t, ok)
+	})
+
+	t.Run("creating a session drops expired ones", func(t *testing.T) {
+		s := NewSessionStore(time.Hour)
+		s.Create("old", "alice", now)
+		s.Create("new", "bob", now.Add(2*time.Hour))
+
+		assert.Len(t, s.sessions, 1)
+	})
+}
@@ -0,0 +1,220 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/terrascope/core/internal/cache"
+	"github.com/terrascope/core/internal/models"
+)
+
+// sharedDigestTTL is how long shared graphs and schemas are kept after they
+// were last stored, in place of the size bound of the memory stores.
+const sharedDigestTTL = 24 * time.Hour
+
+// Keys of the shared stores.
+const (
+	sessionPrefix = "terrascope:session:"
+	lockPrefix    = "terrascope:state-lock:"
+	graphPrefix   = "terrascope:stored-graph:"
+	schemasPrefix = "terrascope:stored-schemas:"
+)
+
+// The Shared stores log the errors of their cache: a failed write is lost and
+// a failed read finds nothing, as after the restart of a server keeping them
+// in memory.
+
+// SharedSessions is a SessionBackend in a shared cache, so that a user signed
+// in on one replica is signed in on all of them.
+type SharedSessions struct {
+	cache cache.Shared
+	ttl   time.Duration
+}
+
+// NewSharedSessions returns a store whose sessions last for the given
+// duration.
+func NewSharedSessions(c cache.Shared, ttl time.Duration) *SharedSessions {
+	return &SharedSessions{cache: c, ttl: ttl}
+}
+
+// sessionKey keeps tokens hashed, like SessionStore.
+func sessionKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return sessionPrefix + hex.EncodeToString(sum[:])
+}
+
+func (s *SharedSessions) Create(token, username string, now time.Time) models.Session {
+	session := models.Session{Username: username, CreatedAt: now, ExpiresAt: now.Add(s.ttl)}
+	putShared(s.cache, sessionKey(token), session, s.ttl)
+
+	return session
+}
+
+func (s *SharedSessions) Get(token string, now time.Time) (models.Session, bool) {
+	var session models.Session
+	if !getShared(s.cache, sessionKey(token), &session) || !now.Before(session.ExpiresAt) {
+		return models.Session{}, false
+	}
+
+	return session, true
+}
+
+func (s *SharedSessions) Delete(token string) bool {
+	return deleteShared(s.cache, sessionKey(token))
+}
+
+// SharedLocks is a LockBackend in a shared cache.
+type SharedLocks struct {
+	cache cache.Shared
+}
+
+// NewSharedLocks returns a lock store in the cache.
+func NewSharedLocks(c cache.Shared) *SharedLocks {
+	return &SharedLocks{cache: c}
+}
+
+func (s *SharedLocks) Put(lineage string, lock models.LockInfo) {
+	putShared(s.cache, lockPrefix+lineage, lock, 0)
+}
+
+func (s *SharedLocks) Get(lineage string) (*models.LockInfo, bool) {
+	var lock models.LockInfo
+	if !getShared(s.cache, lockPrefix+lineage, &lock) {
+		return nil, false
+	}
+
+	return &lock, true
+}
+
+func (s *SharedLocks) Delete(lineage string) bool {
+	return deleteShared(s.cache, lockPrefix+lineage)
+}
+
+// All returns the recorded locks ordered by lineage.
+func (s *SharedLocks) All() []models.ArchivedLock {
+	locks := []models.ArchivedLock{}
+	for _, key := range sharedKeys(s.cache, lockPrefix) {
+		var lock models.LockInfo
+		if getShared(s.cache, key, &lock) {
+			locks = append(locks, models.ArchivedLock{Lineage: strings.TrimPrefix(key, lockPrefix), Lock: lock})
+		}
+	}
+
+	sort.Slice(locks, func(i, j int) bool {
+		return locks[i].Lineage < locks[j].Lineage
+	})
+
+	return locks
+}
+
+// SharedGraphs is a GraphBackend in a shared cache, so that a graph built by
+// one replica can be queried by digest on all of them.
+type SharedGraphs struct {
+	cache cache.Shared
+}
+
+// NewSharedGraphs returns a graph store in the cache.
+func NewSharedGraphs(c cache.Shared) *SharedGraphs {
+	return &SharedGraphs{cache: c}
+}
+
+func (s *SharedGraphs) Put(digest string, graph *models.Graph) {
+	putShared(s.cache, graphPrefix+digest, graph, sharedDigestTTL)
+}
+
+func (s *SharedGraphs) Get(digest string) (*models.Graph, bool) {
+	var graph models.Graph
+	if !getShared(s.cache, graphPrefix+digest, &graph) {
+		return nil, false
+	}
+
+	return &graph, true
+}
+
+// All returns the stored graphs ordered by digest.
+func (s *SharedGraphs) All() []models.ArchivedGraph {
+	keys := sharedKeys(s.cache, graphPrefix)
+	sort.Strings(keys)
+
+	graphs := []models.ArchivedGraph{}
+	for _, key := range keys {
+		var graph models.Graph
+		if getShared(s.cache, key, &graph) {
+			graphs = append(graphs, models.ArchivedGraph{Digest: strings.TrimPrefix(key, graphPrefix), Graph: &graph})
+		}
+	}
+
+	return graphs
+}
+
+// SharedSchemas is a SchemaBackend in a shared cache.
+type SharedSchemas struct {
+	cache cache.Shared
+}
+
+// NewSharedSchemas returns a schemas store in the cache.
+func NewSharedSchemas(c cache.Shared) *SharedSchemas {
+	return &SharedSchemas{cache: c}
+}
+
+func (s *SharedSchemas) Put(digest string, schemas *models.ProviderSchemas) {
+	putShared(s.cache, schemasPrefix+digest, schemas, sharedDigestTTL)
+}
+
+func (s *SharedSchemas) Get(digest string) (*models.ProviderSchemas, bool) {
+	var schemas models.ProviderSchemas
+	if !getShared(s.cache, schemasPrefix+digest, &schemas) {
+		return nil, false
+	}
+
+	return &schemas, true
+}
+
+func putShared(c cache.Shared, key string, v any, ttl time.Duration) {
+	value, err := json.Marshal(v)
+	if err == nil {
+		err = c.Put(context.Background(), key, value, ttl)
+	}
+	if err != nil {
+		log.Printf("Writing %s: %v", key, err)
+	}
+}
+
+func getShared(c cache.Shared, key string, v any) bool {
+	value, ok, err := c.Get(context.Background(), key)
+	if err == nil && ok {
+		err = json.Unmarshal(value, v)
+	}
+	if err != nil {
+		log.Printf("Reading %s: %v", key, err)
+		return false
+	}
+
+	return ok
+}
+
+func deleteShared(c cache.Shared, key string) bool {
+	ok, err := c.Delete(context.Background(), key)
+	if err != nil {
+		log.Printf("Deleting %s: %v", key, err)
+	}
+
+	return ok
+}
+
+func sharedKeys(c cache.Shared, prefix string) []string {
+	keys, err := c.Keys(context.Background(), prefix)
+	if err != nil {
+		log.Printf("Listing %s: %v", prefix, err)
+	}
+
+	return keys
+}
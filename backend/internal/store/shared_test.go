@@ -0,0 +1,148 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+// fakeShared is a shared cache in a map, recording the TTL of every value.
+type fakeShared struct {
+	mu     sync.Mutex
+	values map[string][]byte
+	ttls   map[string]time.Duration
+}
+
+func newFakeShared() *fakeShared {
+	return &fakeShared{values: map[string][]byte{}, ttls: map[string]time.Duration{}}
+}
+
+func (f *fakeShared) Get(_ context.Context, key string) ([]byte, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	value, ok := f.values[key]
+	return value, ok, nil
+}
+
+func (f *fakeShared) Set(ctx context.Context, key string, value []byte) error {
+	return f.Put(ctx, key, value, time.Hour)
+}
+
+func (f *fakeShared) Put(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.values[key], f.ttls[key] = value, ttl
+	return nil
+}
+
+func (f *fakeShared) Delete(_ context.Context, key string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, ok := f.values[key]
+	delete(f.values, key)
+	return ok, nil
+}
+
+func (f *fakeShared) Keys(_ context.Context, prefix string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var keys []string
+	for key := range f.values {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (f *fakeShared) Incr(context.Context, string, int64, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeShared) Lock(context.Context, string, time.Duration) (func(), bool, error) {
+	return func() {}, true, nil
+}
+
+func TestSharedSessions(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cache := newFakeShared()
+
+	// Sessions started on one replica are found on another.
+	session := NewSharedSessions(cache, time.Hour).Create("token", "alice", now)
+	sessions := NewSharedSessions(cache, time.Hour)
+
+	got, ok := sessions.Get("token", now.Add(time.Minute))
+	require.True(t, ok)
+	assert.Equal(t, session, got)
+
+	for key, ttl := range cache.ttls {
+		assert.NotContains(t, key, "token", "tokens are kept hashed")
+		assert.Equal(t, time.Hour, ttl)
+	}
+
+	_, ok = sessions.Get("token", now.Add(time.Hour))
+	assert.False(t, ok, "expired")
+
+	assert.True(t, sessions.Delete("token"))
+	assert.False(t, sessions.Delete("token"))
+	_, ok = sessions.Get("token", now)
+	assert.False(t, ok)
+}
+
+func TestSharedLocks(t *testing.T) {
+	locks := NewSharedLocks(newFakeShared())
+
+	locks.Put("prod", models.LockInfo{ID: "2", Who: "ci"})
+	locks.Put("dev", models.LockInfo{ID: "1", Who: "me"})
+
+	lock, ok := locks.Get("prod")
+	require.True(t, ok)
+	assert.Equal(t, "ci", lock.Who)
+
+	assert.Equal(t, []models.ArchivedLock{
+		{Lineage: "dev", Lock: models.LockInfo{ID: "1", Who: "me"}},
+		{Lineage: "prod", Lock: models.LockInfo{ID: "2", Who: "ci"}},
+	}, locks.All())
+
+	assert.True(t, locks.Delete("prod"))
+	_, ok = locks.Get("prod")
+	assert.False(t, ok)
+}
+
+func TestSharedGraphsAndSchemas(t *testing.T) {
+	cache := newFakeShared()
+	graphs, schemas := NewSharedGraphs(cache), NewSharedSchemas(cache)
+
+	graph := &models.Graph{Nodes: []models.Node{{ID: "aws_vpc.main", Type: "aws_vpc"}}, Edges: []models.Edge{}}
+	graphs.Put("abc", graph)
+
+	got, ok := graphs.Get("abc")
+	require.True(t, ok)
+	assert.Equal(t, graph, got)
+
+	_, ok = graphs.Get("missing")
+	assert.False(t, ok)
+
+	assert.Equal(t, []models.ArchivedGraph{{Digest: "abc", Graph: graph}}, graphs.All())
+
+	schemas.Put("def", &models.ProviderSchemas{})
+	_, ok = schemas.Get("def")
+	assert.True(t, ok)
+
+	assert.Len(t, graphs.All(), 1, "schemas are not graphs")
+	for _, ttl := range cache.ttls {
+		assert.Equal(t, sharedDigestTTL, ttl)
+	}
+}
@@ -0,0 +1,114 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// keyColumns are the columns scanned by scanKey.
+const keyColumns = "id, name, role, project, created_at, revoked_at, last_used_at, requests"
+
+// SQLKeys is the KeyBackend that keeps API keys in the database of a
+// SQLStateStore, so that a key works on every replica and its usage adds up
+// across them.
+type SQLKeys struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewSQLKeys returns a key store over the database, whose schema Migrate must
+// have brought to the latest version.
+func NewSQLKeys(db *sql.DB, dialect Dialect) *SQLKeys {
+	return &SQLKeys{db: db, dialect: dialect}
+}
+
+func (s *SQLKeys) Create(key models.APIKey, secret string) error {
+	_, err := s.db.ExecContext(context.Background(), s.dialect.rebind(`
+		INSERT INTO api_keys (id, secret_hash, name, role, project, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`),
+		key.ID, tokenHash(secret), key.Name, key.Role, key.Project, key.CreatedAt.UnixNano())
+
+	return err
+}
+
+func (s *SQLKeys) Use(secret string, now time.Time) (models.APIKey, bool, error) {
+	row := s.db.QueryRowContext(context.Background(), s.dialect.rebind(`
+		UPDATE api_keys SET requests = requests + 1, last_used_at = ?
+		WHERE secret_hash = ? AND revoked_at IS NULL
+		RETURNING `+keyColumns),
+		now.UnixNano(), tokenHash(secret))
+
+	return scanKey(row)
+}
+
+func (s *SQLKeys) Get(id string) (models.APIKey, bool, error) {
+	row := s.db.QueryRowContext(context.Background(), s.dialect.rebind(`
+		SELECT `+keyColumns+` FROM api_keys WHERE id = ?`), id)
+
+	return scanKey(row)
+}
+
+func (s *SQLKeys) Revoke(id string, now time.Time) (models.APIKey, bool, error) {
+	row := s.db.QueryRowContext(context.Background(), s.dialect.rebind(`
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, ?) WHERE id = ?
+		RETURNING `+keyColumns),
+		now.UnixNano(), id)
+
+	return scanKey(row)
+}
+
+func (s *SQLKeys) List() ([]models.APIKey, error) {
+	rows, err := s.db.QueryContext(context.Background(), `SELECT `+keyColumns+` FROM api_keys ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, _, err := scanKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// scanKey scans the keyColumns of a row, reporting false when there is none.
+func scanKey(row interface{ Scan(...any) error }) (models.APIKey, bool, error) {
+	var key models.APIKey
+	var createdAt int64
+	var revokedAt, lastUsedAt sql.NullInt64
+
+	err := row.Scan(&key.ID, &key.Name, &key.Role, &key.Project, &createdAt, &revokedAt, &lastUsedAt, &key.Requests)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.APIKey{}, false, nil
+	}
+	if err != nil {
+		return models.APIKey{}, false, err
+	}
+
+	key.CreatedAt = time.Unix(0, createdAt).UTC()
+	key.RevokedAt = nullTime(revokedAt)
+	key.LastUsedAt = nullTime(lastUsedAt)
+
+	return key, true, nil
+}
+
+// nullTime converts a nullable Unix time in nanoseconds.
+func nullTime(n sql.NullInt64) *time.Time {
+	if !n.Valid {
+		return nil
+	}
+
+	t := time.Unix(0, n.Int64).UTC()
+	return &t
+}
@@ -0,0 +1,1150 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// Dialect is the SQL database a SQLStateStore runs on.
+type Dialect string
+
+const (
+	DialectSQLite   Dialect = "sqlite"
+	DialectPostgres Dialect = "postgres"
+)
+
+// ParseDialect returns the Dialect with the given name.
+func ParseDialect(name string) (Dialect, error) {
+	switch dialect := Dialect(name); dialect {
+	case DialectSQLite, DialectPostgres:
+		return dialect, nil
+	default:
+		return "", fmt.Errorf("unknown dialect %q", name)
+	}
+}
+
+// rebind rewrites the ? placeholders of a query into the dialect's.
+func (d Dialect) rebind(query string) string {
+	if d != DialectPostgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// sqlitePragmas are set on every connection to a SQLite database, unless its
+// DSN sets them already: foreign keys are enforced, writers wait for each
+// other instead of failing, and readers do not block them.
+var sqlitePragmas = []string{"foreign_keys(1)", "busy_timeout(10000)", "journal_mode(WAL)"}
+
+// SQLiteDSN returns the DSN of a SQLite database with the pragmas the store
+// relies on. Transactions take the write lock when they begin, so that one
+// that reads before writing cannot fail to upgrade its lock.
+func SQLiteDSN(dsn string) string {
+	params := []string{}
+	for _, pragma := range sqlitePragmas {
+		name, _, _ := strings.Cut(pragma, "(")
+		if !strings.Contains(dsn, "_pragma="+name) {
+			params = append(params, "_pragma="+pragma)
+		}
+	}
+	if !strings.Contains(dsn, "_txlock=") {
+		params = append(params, "_txlock=immediate")
+	}
+
+	if len(params) == 0 {
+		return dsn
+	}
+
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+
+	return dsn + separator + strings.Join(params, "&")
+}
+
+// versionColumns are the columns scanned by scanVersion.
+const versionColumns = "lineage, serial, terraform_version, resources, digest, size, stored_at, project, anomalies, archived"
+
+// SQLStateStore is the StateBackend that keeps states in a SQLite or Postgres
+// database, so that they survive restarts and can be shared by several
+// servers. Documents are kept as chunks shared by every version that contains
+// them, see splitChunks. With a BlobStore, chunks are kept there and the
+// database only holds their digests.
+type SQLStateStore struct {
+	db        *sql.DB
+	dialect   Dialect
+	blobs     BlobStore
+	retention time.Duration
+}
+
+// NewSQLStateStore returns a store over the database, whose schema Migrate
+// must have brought to the latest version. Blobs may be nil to keep documents
+// in the database.
+func NewSQLStateStore(db *sql.DB, dialect Dialect, blobs BlobStore, retention time.Duration) *SQLStateStore {
+	return &SQLStateStore{db: db, dialect: dialect, blobs: blobs, retention: retention}
+}
+
+// chunkBatch bounds the rows a statement reads or writes at once, within the
+// 999 parameters older SQLite releases accept.
+const chunkBatch = 200
+
+// valueBatch bounds the attribute values a statement writes at once, which
+// take seven parameters each.
+const valueBatch = 100
+
+// blobFetches bounds the chunks Get reads from the blob store at once.
+const blobFetches = 16
+
+// blobKey is where the document of a version stored before chunking is kept
+// in the blob store.
+func blobKey(lineage string, serial int) string {
+	return "states/" + url.PathEscape(lineage) + "/" + strconv.Itoa(serial) + ".json"
+}
+
+func (s *SQLStateStore) Put(version models.StateVersion, data []byte) error {
+	ctx := context.Background()
+
+	trashed, err := s.trashed(ctx, version.Lineage)
+	if err != nil {
+		return err
+	}
+	if trashed {
+		return ErrTrashed
+	}
+
+	anomalies, err := anomaliesColumn(version.Anomalies)
+	if err != nil {
+		return err
+	}
+
+	values, valueDigests := stateAttributes(data)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	digests, err := s.putChunks(ctx, tx, splitChunks(data))
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, s.dialect.rebind(`DELETE FROM state_version_chunks WHERE lineage = ? AND serial = ?`),
+		version.Lineage, version.Serial)
+	if err != nil {
+		return err
+	}
+	replaced, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, s.dialect.rebind(`DELETE FROM state_graphs WHERE lineage = ? AND serial = ?`),
+		version.Lineage, version.Serial)
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(digests); start += chunkBatch {
+		batch := digests[start:min(start+chunkBatch, len(digests))]
+
+		args := make([]any, 0, 4*len(batch))
+		for i, digest := range batch {
+			args = append(args, version.Lineage, version.Serial, start+i, digest)
+		}
+
+		_, err := tx.ExecContext(ctx, s.dialect.rebind(`
+			INSERT INTO state_version_chunks (lineage, serial, position, digest)
+			VALUES `+placeholders(len(batch), 4)), args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, s.dialect.rebind(`
+		INSERT INTO state_versions (`+versionColumns+`, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (lineage, serial) DO UPDATE SET
+			terraform_version = excluded.terraform_version,
+			resources = excluded.resources,
+			digest = excluded.digest,
+			size = excluded.size,
+			stored_at = excluded.stored_at,
+			project = excluded.project,
+			anomalies = excluded.anomalies,
+			archived = excluded.archived,
+			data = excluded.data`),
+		version.Lineage, version.Serial, version.TerraformVersion, version.Resources,
+		version.Digest, version.Size, version.StoredAt.UnixNano(), version.Project, anomalies, version.Archived, nil)
+	if err != nil {
+		return err
+	}
+
+	unlinked, err := s.putValues(ctx, tx, version, values, valueDigests)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if unlinked {
+		if err := s.collectValues(ctx); err != nil {
+			return err
+		}
+	}
+
+	if replaced > 0 {
+		return s.collectChunks(ctx)
+	}
+
+	return nil
+}
+
+// putChunks stores the chunks that are not stored yet, in the blob store
+// when there is one, and returns the digests of every chunk in order.
+func (s *SQLStateStore) putChunks(ctx context.Context, tx *sql.Tx, chunks [][]byte) ([]string, error) {
+	digests := make([]string, len(chunks))
+	unique := make(map[string][]byte, len(chunks))
+	for i, chunk := range chunks {
+		digests[i] = chunkDigest(chunk)
+		unique[digests[i]] = chunk
+	}
+
+	keys := make([]string, 0, len(unique))
+	for digest := range unique {
+		keys = append(keys, digest)
+	}
+	sort.Strings(keys)
+
+	for start := 0; start < len(keys); start += chunkBatch {
+		batch := keys[start:min(start+chunkBatch, len(keys))]
+
+		stored, err := s.storedDigests(ctx, tx, "state_chunks", batch)
+		if err != nil {
+			return nil, err
+		}
+
+		args := make([]any, 0, 3*len(batch))
+		for _, digest := range batch {
+			if stored[digest] {
+				continue
+			}
+
+			chunk := unique[digest]
+			if s.blobs != nil {
+				if _, err := s.blobs.Put(ctx, chunkKey(digest), chunk, "application/octet-stream"); err != nil {
+					return nil, err
+				}
+				chunk = nil
+			}
+			args = append(args, digest, len(unique[digest]), chunk)
+		}
+
+		if len(args) == 0 {
+			continue
+		}
+
+		_, err = tx.ExecContext(ctx, s.dialect.rebind(`
+			INSERT INTO state_chunks (digest, size, data)
+			VALUES `+placeholders(len(args)/3, 3)+`
+			ON CONFLICT (digest) DO NOTHING`), args...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return digests, nil
+}
+
+// putValues replaces the attribute values of a version with the values of its
+// document, storing those that are not stored yet, and reports whether the
+// version had values before.
+func (s *SQLStateStore) putValues(ctx context.Context, tx *sql.Tx, version models.StateVersion, values []attributeValue, digests []string) (bool, error) {
+	result, err := tx.ExecContext(ctx, s.dialect.rebind(`DELETE FROM state_version_attributes WHERE lineage = ? AND serial = ?`),
+		version.Lineage, version.Serial)
+	if err != nil {
+		return false, err
+	}
+	unlinked, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	byDigest := make(map[string]attributeValue, len(digests))
+	for i, digest := range digests {
+		byDigest[digest] = values[i]
+	}
+
+	keys := slices.Sorted(maps.Keys(byDigest))
+	for start := 0; start < len(keys); start += valueBatch {
+		batch := keys[start:min(start+valueBatch, len(keys))]
+
+		stored, err := s.storedDigests(ctx, tx, "state_attribute_values", batch)
+		if err != nil {
+			return false, err
+		}
+
+		args := make([]any, 0, 7*len(batch))
+		for _, digest := range batch {
+			if stored[digest] {
+				continue
+			}
+
+			v := byDigest[digest]
+			args = append(args, digest, v.address, v.module, v.resourceType, v.attribute, v.value, strings.Join(v.terms(), " "))
+		}
+
+		if len(args) == 0 {
+			continue
+		}
+
+		_, err = tx.ExecContext(ctx, s.dialect.rebind(`
+			INSERT INTO state_attribute_values (digest, address, module, type, attribute, value, terms)
+			VALUES `+placeholders(len(args)/7, 7)+`
+			ON CONFLICT (digest) DO NOTHING`), args...)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	for start := 0; start < len(digests); start += chunkBatch {
+		batch := digests[start:min(start+chunkBatch, len(digests))]
+
+		args := make([]any, 0, 3*len(batch))
+		for _, digest := range batch {
+			args = append(args, version.Lineage, version.Serial, digest)
+		}
+
+		_, err := tx.ExecContext(ctx, s.dialect.rebind(`
+			INSERT INTO state_version_attributes (lineage, serial, digest)
+			VALUES `+placeholders(len(batch), 3)), args...)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return unlinked > 0, nil
+}
+
+// storedDigests reports which of the digests are stored already in the table
+// of chunks or attribute values. On Postgres, their rows stay locked until the
+// transaction ends, so that collectChunks and collectValues cannot remove
+// them before the version that uses them is committed. SQLite transactions
+// hold the write lock from their start, see SQLiteDSN.
+func (s *SQLStateStore) storedDigests(ctx context.Context, tx *sql.Tx, table string, digests []string) (map[string]bool, error) {
+	args := make([]any, len(digests))
+	for i, digest := range digests {
+		args[i] = digest
+	}
+
+	query := `SELECT digest FROM ` + table + ` WHERE digest IN ` + placeholders(1, len(digests))
+	if s.dialect == DialectPostgres {
+		query += ` FOR SHARE`
+	}
+
+	rows, err := tx.QueryContext(ctx, s.dialect.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stored := make(map[string]bool)
+	for rows.Next() {
+		var digest string
+		if err := rows.Scan(&digest); err != nil {
+			return nil, err
+		}
+		stored[digest] = true
+	}
+
+	return stored, rows.Err()
+}
+
+// placeholders returns the placeholders of a VALUES list of rows of the given
+// number of columns, such as "(?, ?), (?, ?)".
+func placeholders(rows, columns int) string {
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", columns), ", ") + ")"
+	return strings.TrimSuffix(strings.Repeat(row+", ", rows), ", ")
+}
+
+func (s *SQLStateStore) Lineages() ([]models.StateHistory, error) {
+	versions, err := s.queryVersions(context.Background(), `
+		SELECT `+versionColumns+` FROM state_versions
+		WHERE lineage NOT IN (SELECT lineage FROM trashed_lineages)
+		ORDER BY lineage, serial`)
+	if err != nil {
+		return nil, err
+	}
+
+	histories := []models.StateHistory{}
+	for _, version := range versions {
+		if n := len(histories); n > 0 && histories[n-1].Lineage == version.Lineage {
+			histories[n-1].Versions++
+			histories[n-1].Latest = version
+			continue
+		}
+		histories = append(histories, models.StateHistory{Lineage: version.Lineage, Versions: 1, Latest: version})
+	}
+
+	return histories, nil
+}
+
+func (s *SQLStateStore) Versions(lineage string) ([]models.StateVersion, error) {
+	versions, err := s.queryVersions(context.Background(), `
+		SELECT `+versionColumns+` FROM state_versions
+		WHERE lineage = ? AND lineage NOT IN (SELECT lineage FROM trashed_lineages)
+		ORDER BY serial`, lineage)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(versions) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return versions, nil
+}
+
+func (s *SQLStateStore) Get(lineage string, serial int) (models.StateVersion, []byte, error) {
+	ctx := context.Background()
+
+	row := s.db.QueryRowContext(ctx, s.dialect.rebind(`
+		SELECT `+versionColumns+`, data FROM state_versions
+		WHERE lineage = ? AND serial = ? AND lineage NOT IN (SELECT lineage FROM trashed_lineages)`),
+		lineage, serial)
+
+	var data []byte
+	version, err := scanVersion(row, &data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.StateVersion{}, nil, ErrNotFound
+	}
+	if err != nil {
+		return models.StateVersion{}, nil, err
+	}
+
+	if version.Archived {
+		return version, nil, ErrArchived
+	}
+
+	chunked, err := s.getChunks(ctx, lineage, serial)
+	if err != nil {
+		return models.StateVersion{}, nil, err
+	}
+
+	switch {
+	case chunked != nil:
+		data = chunked
+	case s.blobs != nil:
+		data, err = s.blobs.Get(ctx, blobKey(lineage, serial))
+		if err != nil {
+			return models.StateVersion{}, nil, err
+		}
+	}
+
+	return version, data, nil
+}
+
+// getChunks reassembles the document of a version from its chunks, reading
+// those kept in the blob store concurrently. It returns nil for versions
+// stored before chunking.
+func (s *SQLStateStore) getChunks(ctx context.Context, lineage string, serial int) ([]byte, error) {
+	rows, err := s.db.QueryContext(ctx, s.dialect.rebind(`
+		SELECT c.digest, c.size, c.data
+		FROM state_version_chunks vc JOIN state_chunks c ON c.digest = vc.digest
+		WHERE vc.lineage = ? AND vc.serial = ?
+		ORDER BY vc.position`),
+		lineage, serial)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var digests []string
+	var chunks [][]byte
+	size := 0
+	for rows.Next() {
+		var digest string
+		var chunkSize int
+		var chunk []byte
+		if err := rows.Scan(&digest, &chunkSize, &chunk); err != nil {
+			return nil, err
+		}
+		digests = append(digests, digest)
+		chunks = append(chunks, chunk)
+		size += chunkSize
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(digests) == 0 {
+		return nil, nil
+	}
+
+	if s.blobs != nil {
+		if err := s.fetchChunks(ctx, digests, chunks); err != nil {
+			return nil, err
+		}
+	}
+
+	data := make([]byte, 0, size)
+	for _, chunk := range chunks {
+		data = append(data, chunk...)
+	}
+
+	return data, nil
+}
+
+// fetchChunks reads the chunks of the digests from the blob store into
+// chunks, blobFetches at a time, and returns the first failure.
+func (s *SQLStateStore) fetchChunks(ctx context.Context, digests []string, chunks [][]byte) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var failure error
+	slots := make(chan struct{}, blobFetches)
+
+	for i, digest := range digests {
+		slots <- struct{}{}
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			chunk, err := s.blobs.Get(ctx, chunkKey(digest))
+			if err != nil {
+				once.Do(func() {
+					failure = fmt.Errorf("chunk %s: %w", digest, err)
+					cancel()
+				})
+				return
+			}
+			chunks[i] = chunk
+		}()
+	}
+
+	wg.Wait()
+
+	return failure
+}
+
+func (s *SQLStateStore) Archive(lineage string, serial int) error {
+	ctx := context.Background()
+
+	result, err := s.db.ExecContext(ctx, s.dialect.rebind(`
+		UPDATE state_versions SET archived = ?, data = NULL
+		WHERE lineage = ? AND serial = ? AND lineage NOT IN (SELECT lineage FROM trashed_lineages)`),
+		true, lineage, serial)
+	if err != nil {
+		return err
+	}
+
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+
+	result, err = s.db.ExecContext(ctx, s.dialect.rebind(`DELETE FROM state_version_chunks WHERE lineage = ? AND serial = ?`),
+		lineage, serial)
+	if err != nil {
+		return err
+	}
+
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n > 0 {
+		return s.collectChunks(ctx)
+	}
+
+	if s.blobs != nil {
+		return s.blobs.Delete(ctx, blobKey(lineage, serial))
+	}
+
+	return nil
+}
+
+func (s *SQLStateStore) PutGraph(lineage string, serial int, graph models.StoredGraph) error {
+	ctx := context.Background()
+
+	var n int
+	err := s.db.QueryRowContext(ctx, s.dialect.rebind(`
+		SELECT COUNT(*) FROM state_versions
+		WHERE lineage = ? AND serial = ? AND lineage NOT IN (SELECT lineage FROM trashed_lineages)`),
+		lineage, serial).Scan(&n)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	data, err := json.Marshal(graph)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, s.dialect.rebind(`
+		INSERT INTO state_graphs (lineage, serial, graph) VALUES (?, ?, ?)
+		ON CONFLICT (lineage, serial) DO UPDATE SET graph = excluded.graph`),
+		lineage, serial, string(data))
+
+	return err
+}
+
+func (s *SQLStateStore) Graph(lineage string, serial int) (models.StoredGraph, error) {
+	var data string
+	err := s.db.QueryRowContext(context.Background(), s.dialect.rebind(`
+		SELECT graph FROM state_graphs
+		WHERE lineage = ? AND serial = ? AND lineage NOT IN (SELECT lineage FROM trashed_lineages)`),
+		lineage, serial).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.StoredGraph{}, ErrNotFound
+	} else if err != nil {
+		return models.StoredGraph{}, err
+	}
+
+	var graph models.StoredGraph
+	if err := json.Unmarshal([]byte(data), &graph); err != nil {
+		return models.StoredGraph{}, fmt.Errorf("graph of %s/%d: %w", lineage, serial, err)
+	}
+
+	return graph, nil
+}
+
+func (s *SQLStateStore) Delete(lineage string, now time.Time) (models.TrashEntry, error) {
+	ctx := context.Background()
+
+	if err := s.purge(ctx, now); err != nil {
+		return models.TrashEntry{}, err
+	}
+
+	versions, err := s.Versions(lineage)
+	if err != nil {
+		return models.TrashEntry{}, err
+	}
+
+	_, err = s.db.ExecContext(ctx, s.dialect.rebind(`
+		INSERT INTO trashed_lineages (lineage, deleted_at) VALUES (?, ?)`),
+		lineage, now.UnixNano())
+	if err != nil {
+		return models.TrashEntry{}, err
+	}
+
+	return models.TrashEntry{
+		Lineage:   lineage,
+		Versions:  len(versions),
+		DeletedAt: now,
+		ExpiresAt: now.Add(s.retention),
+	}, nil
+}
+
+func (s *SQLStateStore) Trash(now time.Time) ([]models.TrashEntry, error) {
+	ctx := context.Background()
+
+	if err := s.purge(ctx, now); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT t.lineage, t.deleted_at, COUNT(v.serial)
+		FROM trashed_lineages t JOIN state_versions v ON v.lineage = t.lineage
+		GROUP BY t.lineage, t.deleted_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.TrashEntry{}
+	for rows.Next() {
+		var entry models.TrashEntry
+		var deletedAt int64
+		if err := rows.Scan(&entry.Lineage, &deletedAt, &entry.Versions); err != nil {
+			return nil, err
+		}
+		entry.DeletedAt = time.Unix(0, deletedAt).UTC()
+		entry.ExpiresAt = entry.DeletedAt.Add(s.retention)
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].DeletedAt.Equal(entries[j].DeletedAt) {
+			return entries[i].DeletedAt.After(entries[j].DeletedAt)
+		}
+
+		return entries[i].Lineage < entries[j].Lineage
+	})
+
+	return entries, nil
+}
+
+func (s *SQLStateStore) Restore(lineage string, now time.Time) (models.StateHistory, error) {
+	ctx := context.Background()
+
+	if err := s.purge(ctx, now); err != nil {
+		return models.StateHistory{}, err
+	}
+
+	result, err := s.db.ExecContext(ctx, s.dialect.rebind(`DELETE FROM trashed_lineages WHERE lineage = ?`), lineage)
+	if err != nil {
+		return models.StateHistory{}, err
+	}
+
+	if n, err := result.RowsAffected(); err != nil {
+		return models.StateHistory{}, err
+	} else if n == 0 {
+		return models.StateHistory{}, ErrNotFound
+	}
+
+	versions, err := s.Versions(lineage)
+	if err != nil {
+		return models.StateHistory{}, err
+	}
+
+	return models.StateHistory{Lineage: lineage, Versions: len(versions), Latest: versions[len(versions)-1]}, nil
+}
+
+func (s *SQLStateStore) Search(query, lineage string, limit int) (models.SearchResults, error) {
+	hits := newSearchHits(query, limit)
+
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return hits.results, nil
+	}
+
+	match, arg := s.matchTerms(terms)
+	statement := `
+		SELECT va.lineage, va.serial, a.address, a.attribute, a.value
+		FROM state_attribute_values a JOIN state_version_attributes va ON va.digest = a.digest
+		WHERE ` + match + ` AND va.lineage NOT IN (SELECT lineage FROM trashed_lineages)`
+	args := []any{arg}
+	if lineage != "" {
+		statement += ` AND va.lineage = ?`
+		args = append(args, lineage)
+	}
+	statement += ` ORDER BY va.lineage, a.address, a.attribute, a.value, va.serial`
+
+	rows, err := s.db.QueryContext(context.Background(), s.dialect.rebind(statement), args...)
+	if err != nil {
+		return hits.results, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var lineage string
+		var serial int
+		var v attributeValue
+		if err := rows.Scan(&lineage, &serial, &v.address, &v.attribute, &v.value); err != nil {
+			return hits.results, err
+		}
+
+		if !hits.add(lineage, serial, v) {
+			break
+		}
+	}
+
+	return hits.results, rows.Err()
+}
+
+func (s *SQLStateStore) Lookup(id string) ([]models.LookupMatch, error) {
+	matches := []models.LookupMatch{}
+
+	terms := tokenize(id)
+	if len(terms) == 0 {
+		return matches, nil
+	}
+
+	match, arg := s.matchTerms(terms)
+	args := []any{arg}
+	for _, attribute := range slices.Sorted(maps.Keys(identifiers)) {
+		args = append(args, attribute)
+	}
+
+	rows, err := s.db.QueryContext(context.Background(), s.dialect.rebind(`
+		SELECT v.lineage, v.serial, v.project, a.address, a.module, a.type, a.attribute, a.value
+		FROM state_versions v
+		JOIN state_version_attributes va ON va.lineage = v.lineage AND va.serial = v.serial
+		JOIN state_attribute_values a ON a.digest = va.digest
+		WHERE `+match+` AND a.attribute IN `+placeholders(1, len(identifiers))+`
+		AND v.serial = (SELECT MAX(serial) FROM state_versions latest WHERE latest.lineage = v.lineage)
+		AND v.lineage NOT IN (SELECT lineage FROM trashed_lineages)
+		ORDER BY v.lineage, a.address, a.attribute`), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version models.StateVersion
+		var v attributeValue
+		err := rows.Scan(&version.Lineage, &version.Serial, &version.Project,
+			&v.address, &v.module, &v.resourceType, &v.attribute, &v.value)
+		if err != nil {
+			return nil, err
+		}
+
+		if match, ok := lookupMatch(version, v, id); ok {
+			matches = append(matches, match)
+		}
+	}
+
+	return matches, rows.Err()
+}
+
+// matchTerms returns the condition that the attribute value a has every term,
+// with its argument: a query of the full-text index on SQLite, and an array
+// containment on Postgres.
+func (s *SQLStateStore) matchTerms(terms []string) (string, any) {
+	if s.dialect == DialectPostgres {
+		return `string_to_array(a.terms, ' ') @> string_to_array(?, ' ')`, strings.Join(terms, " ")
+	}
+
+	// Terms hold no quotes, and quoting them keeps FTS5 from reading
+	// operators such as NOT or column filters into them.
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `"` + term + `"`
+	}
+
+	return `a.id IN (SELECT rowid FROM state_attribute_terms WHERE state_attribute_terms MATCH ?)`, strings.Join(quoted, " ")
+}
+
+func (s *SQLStateStore) StoredBytes(now time.Time) (map[string]int64, error) {
+	ctx := context.Background()
+
+	if err := s.purge(ctx, now); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT project, SUM(size) FROM state_versions WHERE NOT archived GROUP BY project`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stored := make(map[string]int64)
+	for rows.Next() {
+		var project string
+		var size int64
+		if err := rows.Scan(&project, &size); err != nil {
+			return nil, err
+		}
+		stored[project] = size
+	}
+
+	return stored, rows.Err()
+}
+
+func (s *SQLStateStore) ProjectStoredBytes(project string, now time.Time) (int64, error) {
+	var stored int64
+	err := s.db.QueryRowContext(context.Background(), s.dialect.rebind(`
+		SELECT COALESCE(SUM(size), 0) FROM state_versions
+		WHERE project = ? AND NOT archived
+		AND lineage NOT IN (SELECT lineage FROM trashed_lineages WHERE deleted_at <= ?)`),
+		project, now.Add(-s.retention).UnixNano()).Scan(&stored)
+
+	return stored, err
+}
+
+// trashed reports whether the lineage is in the trash.
+func (s *SQLStateStore) trashed(ctx context.Context, lineage string) (bool, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, s.dialect.rebind(`SELECT COUNT(*) FROM trashed_lineages WHERE lineage = ?`), lineage).Scan(&n)
+
+	return n > 0, err
+}
+
+// purge removes the lineages that have been in the trash past the retention
+// window, with their documents.
+func (s *SQLStateStore) purge(ctx context.Context, now time.Time) error {
+	expired, err := s.queryVersions(ctx, `
+		SELECT `+versionColumns+` FROM state_versions
+		WHERE lineage IN (SELECT lineage FROM trashed_lineages WHERE deleted_at <= ?)`,
+		now.Add(-s.retention).UnixNano())
+	if err != nil || len(expired) == 0 {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	purged := map[string]bool{}
+	for _, version := range expired {
+		if purged[version.Lineage] {
+			continue
+		}
+		purged[version.Lineage] = true
+
+		for _, statement := range []string{
+			`DELETE FROM state_version_chunks WHERE lineage = ?`,
+			`DELETE FROM state_version_attributes WHERE lineage = ?`,
+			`DELETE FROM state_graphs WHERE lineage = ?`,
+			`DELETE FROM state_versions WHERE lineage = ?`,
+			`DELETE FROM trashed_lineages WHERE lineage = ?`,
+		} {
+			if _, err := tx.ExecContext(ctx, s.dialect.rebind(statement), version.Lineage); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if s.blobs != nil {
+		for _, version := range expired {
+			if err := s.blobs.Delete(ctx, blobKey(version.Lineage, version.Serial)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := s.collectValues(ctx); err != nil {
+		return err
+	}
+
+	return s.collectChunks(ctx)
+}
+
+// collectChunks removes the chunks no stored version uses anymore, with their
+// copy in the blob store.
+func (s *SQLStateStore) collectChunks(ctx context.Context) error {
+	unused, err := s.queryDigests(ctx, `
+		SELECT digest FROM state_chunks c
+		WHERE NOT EXISTS (SELECT 1 FROM state_version_chunks vc WHERE vc.digest = c.digest)`)
+	if err != nil {
+		return err
+	}
+
+	for _, digest := range unused {
+		if err := s.collectChunk(ctx, digest); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// collectChunk removes a chunk unless a version uses it again. On Postgres,
+// it first waits for the Put transactions that locked the chunk, see
+// storedDigests, so that the check that follows sees the versions they stored.
+// The copy in the blob store is removed while the chunk is locked: a Put
+// waiting for it then finds the chunk missing and uploads it again.
+func (s *SQLStateStore) collectChunk(ctx context.Context, digest string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if s.dialect == DialectPostgres {
+		var locked string
+		err := tx.QueryRowContext(ctx, s.dialect.rebind(`SELECT digest FROM state_chunks WHERE digest = ? FOR UPDATE`), digest).Scan(&locked)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, s.dialect.rebind(`
+		DELETE FROM state_chunks
+		WHERE digest = ? AND NOT EXISTS (SELECT 1 FROM state_version_chunks vc WHERE vc.digest = state_chunks.digest)`),
+		digest)
+	if err != nil {
+		return err
+	}
+
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n > 0 && s.blobs != nil {
+		if err := s.blobs.Delete(ctx, chunkKey(digest)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// collectValues removes the attribute values no stored version has anymore,
+// chunkBatch at a time. On Postgres, it first waits for the Put transactions
+// that locked the values, see storedDigests, so that the check that follows
+// sees the versions they stored.
+func (s *SQLStateStore) collectValues(ctx context.Context) error {
+	unused, err := s.queryDigests(ctx, `
+		SELECT digest FROM state_attribute_values a
+		WHERE NOT EXISTS (SELECT 1 FROM state_version_attributes va WHERE va.digest = a.digest)`)
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(unused); start += chunkBatch {
+		batch := unused[start:min(start+chunkBatch, len(unused))]
+
+		args := make([]any, len(batch))
+		for i, digest := range batch {
+			args[i] = digest
+		}
+
+		if err := s.collectValueBatch(ctx, args); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *SQLStateStore) collectValueBatch(ctx context.Context, digests []any) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if s.dialect == DialectPostgres {
+		rows, err := tx.QueryContext(ctx, s.dialect.rebind(`
+			SELECT digest FROM state_attribute_values WHERE digest IN `+placeholders(1, len(digests))+` FOR UPDATE`), digests...)
+		if err != nil {
+			return err
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, s.dialect.rebind(`
+		DELETE FROM state_attribute_values
+		WHERE digest IN `+placeholders(1, len(digests))+`
+		AND NOT EXISTS (SELECT 1 FROM state_version_attributes va WHERE va.digest = state_attribute_values.digest)`),
+		digests...)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// queryDigests returns the digests a query selects.
+func (s *SQLStateStore) queryDigests(ctx context.Context, query string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var digests []string
+	for rows.Next() {
+		var digest string
+		if err := rows.Scan(&digest); err != nil {
+			return nil, err
+		}
+		digests = append(digests, digest)
+	}
+
+	return digests, rows.Err()
+}
+
+func (s *SQLStateStore) queryVersions(ctx context.Context, query string, args ...any) ([]models.StateVersion, error) {
+	rows, err := s.db.QueryContext(ctx, s.dialect.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []models.StateVersion{}
+	for rows.Next() {
+		version, err := scanVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
+
+// anomaliesColumn encodes the anomalies of a version as a JSON array, or NULL
+// when there are none.
+func anomaliesColumn(anomalies []models.Alert) (sql.NullString, error) {
+	if len(anomalies) == 0 {
+		return sql.NullString{}, nil
+	}
+
+	data, err := json.Marshal(anomalies)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// scanVersion scans the versionColumns of a row, followed by extra columns.
+func scanVersion(row interface{ Scan(...any) error }, extra ...any) (models.StateVersion, error) {
+	var version models.StateVersion
+	var storedAt int64
+	var anomalies sql.NullString
+
+	dest := append([]any{
+		&version.Lineage, &version.Serial, &version.TerraformVersion, &version.Resources,
+		&version.Digest, &version.Size, &storedAt, &version.Project, &anomalies, &version.Archived,
+	}, extra...)
+
+	if err := row.Scan(dest...); err != nil {
+		return models.StateVersion{}, err
+	}
+
+	version.StoredAt = time.Unix(0, storedAt).UTC()
+
+	if anomalies.Valid {
+		if err := json.Unmarshal([]byte(anomalies.String), &version.Anomalies); err != nil {
+			return models.StateVersion{}, fmt.Errorf("anomalies of %s/%d: %w", version.Lineage, version.Serial, err)
+		}
+	}
+
+	return version, nil
+}
@@ -0,0 +1,258 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	_ "modernc.org/sqlite"
+)
+
+// forEachDatabase runs the test against a migrated SQLite database, and
+// against the Postgres database of TERRASCOPE_TEST_POSTGRES_DSN when it is
+// set. The Postgres schema is migrated down to nothing before and after.
+func forEachDatabase(t *testing.T, test func(t *testing.T, db *sql.DB, dialect Dialect)) {
+	t.Helper()
+
+	open := func(t *testing.T, driver, dsn string, dialect Dialect) *sql.DB {
+		db, err := sql.Open(driver, dsn)
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		_, err = Migrate(context.Background(), db, dialect, 0)
+		require.NoError(t, err)
+		t.Cleanup(func() { Migrate(context.Background(), db, dialect, 0) })
+
+		migrations, err := Migrations(dialect)
+		require.NoError(t, err)
+		_, err = Migrate(context.Background(), db, dialect, len(migrations))
+		require.NoError(t, err)
+
+		return db
+	}
+
+	t.Run("sqlite", func(t *testing.T) {
+		dsn := SQLiteDSN(filepath.Join(t.TempDir(), "terrascope.db"))
+		test(t, open(t, "sqlite", dsn, DialectSQLite), DialectSQLite)
+	})
+
+	t.Run("postgres", func(t *testing.T) {
+		dsn := os.Getenv("TERRASCOPE_TEST_POSTGRES_DSN")
+		if dsn == "" {
+			t.Skip("TERRASCOPE_TEST_POSTGRES_DSN is not set")
+		}
+		test(t, open(t, "pgx", dsn, DialectPostgres), DialectPostgres)
+	})
+}
+
+// testState returns a state document with the given resources, which become
+// chunks of their own.
+func testState(serial int, resources ...string) []byte {
+	items := make([]string, len(resources))
+	for i, name := range resources {
+		items[i] = fmt.Sprintf(`{"mode": "managed", "type": "aws_vpc", "name": %q, "instances": []}`, name)
+	}
+
+	return []byte(fmt.Sprintf(`{"version": 4, "serial": %d, "resources": [%s]}`, serial, strings.Join(items, ", ")))
+}
+
+// memoryBlobs is a BlobStore over a map.
+type memoryBlobs struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (m *memoryBlobs) Put(_ context.Context, key string, body []byte, _ string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.objects[key] = body
+
+	return key, nil
+}
+
+func (m *memoryBlobs) Get(_ context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	body, ok := m.objects[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return body, nil
+}
+
+func (m *memoryBlobs) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.objects, key)
+
+	return nil
+}
+
+func countRows(t *testing.T, db *sql.DB, table string) int {
+	t.Helper()
+
+	var n int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM `+table).Scan(&n))
+
+	return n
+}
+
+func TestParseDialect(t *testing.T) {
+	dialect, err := ParseDialect("postgres")
+	require.NoError(t, err)
+	assert.Equal(t, DialectPostgres, dialect)
+
+	_, err = ParseDialect("mysql")
+	assert.EqualError(t, err, `unknown dialect "mysql"`)
+}
+
+func TestSQLiteDSN(t *testing.T) {
+	assert.Equal(t, "terrascope.db?_pragma=foreign_keys(1)&_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)&_txlock=immediate", SQLiteDSN("terrascope.db"))
+	assert.Equal(t, "file:t.db?_pragma=busy_timeout(50)&_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_txlock=immediate", SQLiteDSN("file:t.db?_pragma=busy_timeout(50)"))
+
+	dsn := "t.db?_pragma=foreign_keys(1)&_pragma=busy_timeout(1)&_pragma=journal_mode(DELETE)&_txlock=deferred"
+	assert.Equal(t, dsn, SQLiteDSN(dsn))
+}
+
+func TestRebind(t *testing.T) {
+	query := `SELECT data FROM state_versions WHERE lineage = ? AND serial = ?`
+
+	assert.Equal(t, query, DialectSQLite.rebind(query))
+	assert.Equal(t, `SELECT data FROM state_versions WHERE lineage = $1 AND serial = $2`, DialectPostgres.rebind(query))
+}
+
+func TestBlobKey(t *testing.T) {
+	assert.Equal(t, "states/prod/3.json", blobKey("prod", 3))
+	assert.Equal(t, "states/team%2Fprod%20eu/3.json", blobKey("team/prod eu", 3))
+}
+
+func TestAnomaliesColumn(t *testing.T) {
+	column, err := anomaliesColumn(nil)
+	require.NoError(t, err)
+	assert.False(t, column.Valid)
+
+	column, err = anomaliesColumn([]models.Alert{{Condition: models.AnomalySerialJump, Serial: 200}})
+	require.NoError(t, err)
+	assert.True(t, column.Valid)
+	assert.Contains(t, column.String, `"condition":"serial_jump"`)
+}
+
+func TestSQLStateStore(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *sql.DB, dialect Dialect) {
+		states := NewSQLStateStore(db, dialect, nil, time.Hour)
+		now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+		first := testState(1, "a", "b", "c")
+		second := testState(2, "a", "b", "d")
+
+		require.NoError(t, states.Put(models.StateVersion{Lineage: "network", Serial: 1, Resources: 3, StoredAt: now}, first))
+		require.NoError(t, states.Put(models.StateVersion{Lineage: "network", Serial: 2, Resources: 3, StoredAt: now}, second))
+
+		t.Run("returns the documents it stored", func(t *testing.T) {
+			version, data, err := states.Get("network", 2)
+			require.NoError(t, err)
+			assert.Equal(t, 3, version.Resources)
+			assert.Equal(t, now, version.StoredAt)
+			assert.Equal(t, string(second), string(data))
+
+			_, _, err = states.Get("network", 3)
+			assert.ErrorIs(t, err, ErrNotFound)
+		})
+
+		t.Run("shares the chunks of versions", func(t *testing.T) {
+			// The heads differ by their serial, and the versions share a,
+			// b and the tail.
+			assert.Equal(t, 7, countRows(t, db, "state_chunks"))
+		})
+
+		t.Run("collects the chunks no version uses after a replace", func(t *testing.T) {
+			require.NoError(t, states.Put(models.StateVersion{Lineage: "network", Serial: 2, StoredAt: now}, testState(2, "a", "b", "c")))
+
+			_, data, err := states.Get("network", 2)
+			require.NoError(t, err)
+			assert.Equal(t, string(testState(2, "a", "b", "c")), string(data))
+			assert.Equal(t, 6, countRows(t, db, "state_chunks"))
+		})
+
+		t.Run("purges trashed lineages after the retention", func(t *testing.T) {
+			_, err := states.Delete("network", now)
+			require.NoError(t, err)
+
+			_, _, err = states.Get("network", 1)
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			trash, err := states.Trash(now.Add(2 * time.Hour))
+			require.NoError(t, err)
+			assert.Empty(t, trash)
+			assert.Zero(t, countRows(t, db, "state_chunks"))
+			assert.Zero(t, countRows(t, db, "state_versions"))
+		})
+	})
+}
+
+func TestSQLStateStoreConcurrentChunks(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *sql.DB, dialect Dialect) {
+		blobs := &memoryBlobs{objects: map[string][]byte{}}
+		states := NewSQLStateStore(db, dialect, blobs, time.Hour)
+
+		// Every writer replaces its version with documents that alternate
+		// between two resources, which the other writers keep freeing and
+		// storing again, so that chunks are collected while others reuse
+		// them.
+		const writers, rounds = 6, 15
+		var wg sync.WaitGroup
+		errs := make(chan error, writers*rounds)
+		for i := range writers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				lineage := fmt.Sprintf("lineage-%d", i)
+				for round := range rounds {
+					data := testState(1, "shared", fmt.Sprintf("r%d", (i+round)%2))
+					if err := states.Put(models.StateVersion{Lineage: lineage, Serial: 1}, data); err != nil {
+						errs <- err
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			require.NoError(t, err)
+		}
+
+		for i := range writers {
+			_, data, err := states.Get(fmt.Sprintf("lineage-%d", i), 1)
+			require.NoError(t, err)
+			assert.Equal(t, string(testState(1, "shared", fmt.Sprintf("r%d", (i+rounds-1)%2))), string(data))
+		}
+
+		rows, err := db.Query(`SELECT digest FROM state_chunks`)
+		require.NoError(t, err)
+		defer rows.Close()
+		for rows.Next() {
+			var digest string
+			require.NoError(t, rows.Scan(&digest))
+			_, err := blobs.Get(context.Background(), chunkKey(digest))
+			assert.NoError(t, err, "chunk %s", digest)
+		}
+		require.NoError(t, rows.Err())
+	})
+}
@@ -0,0 +1,217 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// userColumns are the columns scanned by scanUser.
+const userColumns = "username, role, created_at, invitation_hash"
+
+// SQLUsers is the UserBackend that keeps users in the database of a
+// SQLStateStore, so that they survive restarts and every replica signs in
+// the same users.
+type SQLUsers struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewSQLUsers returns a user store over the database, whose schema Migrate
+// must have brought to the latest version.
+func NewSQLUsers(db *sql.DB, dialect Dialect) *SQLUsers {
+	return &SQLUsers{db: db, dialect: dialect}
+}
+
+// tokenHash is how invitation tokens and API key secrets are kept, so that
+// the database holds nothing a client could present.
+func tokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *SQLUsers) Add(user models.User, hash string) (bool, error) {
+	return s.insert(user, hash, nil, time.Time{})
+}
+
+func (s *SQLUsers) Invite(user models.User, token string, expiresAt time.Time) (bool, error) {
+	invitation := tokenHash(token)
+	return s.insert(user, "", &invitation, expiresAt)
+}
+
+// insert stores a new user, who is no longer removed.
+func (s *SQLUsers) insert(user models.User, hash string, invitation *string, expiresAt time.Time) (bool, error) {
+	ctx := context.Background()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var expires sql.NullInt64
+	if invitation != nil {
+		expires = sql.NullInt64{Int64: expiresAt.UnixNano(), Valid: true}
+	}
+
+	result, err := tx.ExecContext(ctx, s.dialect.rebind(`
+		INSERT INTO users (username, role, created_at, password_hash, invitation_hash, invitation_expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (username) DO NOTHING`),
+		user.Username, user.Role, user.CreatedAt.UnixNano(), hash, invitation, expires)
+	if err != nil {
+		return false, err
+	}
+
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, s.dialect.rebind(`DELETE FROM removed_users WHERE username = ?`), user.Username)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (s *SQLUsers) Seed(user models.User, hash string) error {
+	_, err := s.db.ExecContext(context.Background(), s.dialect.rebind(`
+		INSERT INTO users (username, role, created_at, password_hash)
+		SELECT CAST(? AS TEXT), CAST(? AS TEXT), CAST(? AS BIGINT), CAST(? AS TEXT)
+		WHERE NOT EXISTS (SELECT 1 FROM removed_users WHERE username = ?)
+		ON CONFLICT (username) DO UPDATE SET
+			password_hash = excluded.password_hash,
+			invitation_hash = NULL,
+			invitation_expires_at = NULL`),
+		user.Username, user.Role, user.CreatedAt.UnixNano(), hash, user.Username)
+
+	return err
+}
+
+func (s *SQLUsers) Accept(token, hash string, now time.Time) (models.User, bool, error) {
+	row := s.db.QueryRowContext(context.Background(), s.dialect.rebind(`
+		UPDATE users SET password_hash = ?, invitation_hash = NULL, invitation_expires_at = NULL
+		WHERE invitation_hash = ? AND invitation_expires_at > ?
+		RETURNING `+userColumns),
+		hash, tokenHash(token), now.UnixNano())
+
+	return scanUser(row)
+}
+
+func (s *SQLUsers) Get(username string) (models.User, bool, error) {
+	row := s.db.QueryRowContext(context.Background(), s.dialect.rebind(`
+		SELECT `+userColumns+` FROM users WHERE username = ?`), username)
+
+	return scanUser(row)
+}
+
+func (s *SQLUsers) Password(username string) (string, error) {
+	var hash string
+	err := s.db.QueryRowContext(context.Background(), s.dialect.rebind(`
+		SELECT password_hash FROM users WHERE username = ?`), username).Scan(&hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+
+	return hash, err
+}
+
+func (s *SQLUsers) SetRole(username, role string) (models.User, bool, error) {
+	row := s.db.QueryRowContext(context.Background(), s.dialect.rebind(`
+		UPDATE users SET role = ? WHERE username = ?
+		RETURNING `+userColumns), role, username)
+
+	return scanUser(row)
+}
+
+func (s *SQLUsers) Delete(username string) (bool, error) {
+	ctx := context.Background()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, s.dialect.rebind(`DELETE FROM users WHERE username = ?`), username)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, s.dialect.rebind(`
+		INSERT INTO removed_users (username, removed_at) VALUES (?, ?)
+		ON CONFLICT (username) DO UPDATE SET removed_at = excluded.removed_at`),
+		username, time.Now().UnixNano())
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, tx.Commit()
+}
+
+func (s *SQLUsers) List() ([]models.User, error) {
+	rows, err := s.db.QueryContext(context.Background(), `SELECT `+userColumns+` FROM users ORDER BY username`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, _, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+func (s *SQLUsers) Len() (int, error) {
+	var n int
+	err := s.db.QueryRowContext(context.Background(), `SELECT COUNT(*) FROM users`).Scan(&n)
+
+	return n, err
+}
+
+func (s *SQLUsers) Admins() (int, error) {
+	var n int
+	err := s.db.QueryRowContext(context.Background(), s.dialect.rebind(`
+		SELECT COUNT(*) FROM users WHERE role = ? AND invitation_hash IS NULL`), models.RoleAdmin).Scan(&n)
+
+	return n, err
+}
+
+// scanUser scans the userColumns of a row, reporting false when there is
+// none.
+func scanUser(row interface{ Scan(...any) error }) (models.User, bool, error) {
+	var user models.User
+	var createdAt int64
+	var invitation sql.NullString
+
+	err := row.Scan(&user.Username, &user.Role, &createdAt, &invitation)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, false, nil
+	}
+	if err != nil {
+		return models.User{}, false, err
+	}
+
+	user.CreatedAt = time.Unix(0, createdAt).UTC()
+	user.Invited = invitation.Valid
+
+	return user, true, nil
+}
@@ -0,0 +1,149 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+// GraphIndex keeps the dependency graph of the stored state versions in the
+// StateBackend, so that queries on stored states need not parse them again.
+// A graph is stored as the patch of the graph of the previous version, and
+// in full every SnapshotEvery versions, so a version costs what changed
+// since the previous one. Graphs are built with Options.
+type GraphIndex struct {
+	States        StateBackend
+	SnapshotEvery int
+	Options       parser.GraphOptions
+}
+
+// Update stores the graph of a stored version.
+func (x *GraphIndex) Update(version models.StateVersion, graph *models.Graph) error {
+	stored := models.StoredGraph{Digest: parser.Digest(graph), Graph: graph}
+
+	if previous, ok := x.previous(version); ok {
+		stored = models.StoredGraph{
+			Base:       previous.Serial,
+			BaseDigest: previous.Digest,
+			Depth:      previous.Depth + 1,
+			Digest:     stored.Digest,
+			Patch:      parser.DiffGraphs(previous.Graph, graph),
+		}
+	}
+
+	return x.States.PutGraph(version.Lineage, version.Serial, stored)
+}
+
+// previousGraph is the graph of the version before the one being stored.
+type previousGraph struct {
+	Serial int
+	Depth  int
+	Digest string
+	Graph  *models.Graph
+}
+
+// previous returns the graph of the latest version of the lineage older than
+// version, unless it is too many patches away from a full graph or cannot be
+// read.
+func (x *GraphIndex) previous(version models.StateVersion) (previousGraph, bool) {
+	versions, err := x.States.Versions(version.Lineage)
+	if err != nil {
+		return previousGraph{}, false
+	}
+
+	for i := len(versions) - 1; i >= 0; i-- {
+		serial := versions[i].Serial
+		if serial >= version.Serial {
+			continue
+		}
+
+		stored, err := x.States.Graph(version.Lineage, serial)
+		if err != nil || stored.Depth+1 >= x.SnapshotEvery {
+			return previousGraph{}, false
+		}
+
+		graph, err := x.Graph(version.Lineage, serial)
+		if err != nil {
+			return previousGraph{}, false
+		}
+
+		return previousGraph{Serial: serial, Depth: stored.Depth, Digest: stored.Digest, Graph: graph}, true
+	}
+
+	return previousGraph{}, false
+}
+
+// Graph returns the graph of a stored version, applying its patches to the
+// last full graph before it. Graphs that are not stored, such as those of
+// versions stored before the index, or whose patches no longer apply, are
+// built from the document and stored in full. It returns ErrArchived when
+// that document is in cold storage.
+func (x *GraphIndex) Graph(lineage string, serial int) (*models.Graph, error) {
+	stored, err := x.States.Graph(lineage, serial)
+	if errors.Is(err, ErrNotFound) {
+		return x.rebuild(lineage, serial)
+	} else if err != nil {
+		return nil, err
+	}
+
+	depth := stored.Depth
+	patches := []*models.GraphPatch{}
+	for stored.Graph == nil {
+		if stored.Patch == nil || len(patches) == depth {
+			return x.rebuild(lineage, serial)
+		}
+		patches = append(patches, stored.Patch)
+
+		// The base may have been stored again since, with another graph.
+		baseDigest := stored.BaseDigest
+		stored, err = x.States.Graph(lineage, stored.Base)
+		if errors.Is(err, ErrNotFound) || err == nil && stored.Digest != baseDigest {
+			return x.rebuild(lineage, serial)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	graph := copyGraph(stored.Graph)
+	for i := len(patches) - 1; i >= 0; i-- {
+		graph = parser.ApplyPatch(graph, patches[i])
+	}
+
+	return graph, nil
+}
+
+// rebuild builds the graph of a stored version from its document, and
+// stores it in full.
+func (x *GraphIndex) rebuild(lineage string, serial int) (*models.Graph, error) {
+	_, data, err := x.States.Get(lineage, serial)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := parser.ParseTfstate(data)
+	if err != nil {
+		return nil, fmt.Errorf("stored state %s/%d: %w", lineage, serial, err)
+	}
+
+	graph := parser.BuildGraphWithOptions(state, x.Options)
+	if err := x.States.PutGraph(lineage, serial, models.StoredGraph{Digest: parser.Digest(graph), Graph: graph}); err != nil {
+		return nil, err
+	}
+
+	return copyGraph(graph), nil
+}
+
+// copyGraph copies the nodes, edges, addresses and warnings of a stored
+// graph, for callers to change without changing the stored graph.
+func copyGraph(graph *models.Graph) *models.Graph {
+	return &models.Graph{
+		Nodes:     append([]models.Node(nil), graph.Nodes...),
+		Edges:     append([]models.Edge(nil), graph.Edges...),
+		Addresses: graph.Addresses,
+		Warnings:  graph.Warnings,
+	}
+}
@@ -0,0 +1,115 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/internal/parser"
+)
+
+func indexState(serial int) []byte {
+	return []byte(fmt.Sprintf(`{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"serial": %d,
+		"lineage": "prod",
+		"resources": [
+			{"mode": "managed", "type": "aws_vpc", "name": "main", "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+			 "instances": [{"attributes": {"id": "vpc-1"}}]},
+			{"mode": "managed", "type": "aws_s3_bucket", "name": "logs", "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+			 "instances": [{"attributes": {"id": "logs-%d"}}]}
+		]
+	}`, serial, serial))
+}
+
+func TestGraphIndex(t *testing.T) {
+	newIndex := func() *GraphIndex {
+		return &GraphIndex{States: NewStateStore(time.Hour), SnapshotEvery: 3}
+	}
+
+	store := func(t *testing.T, x *GraphIndex, serial int) *models.Graph {
+		data := indexState(serial)
+		require.NoError(t, x.States.Put(models.StateVersion{Lineage: "prod", Serial: serial}, data))
+
+		state, err := parser.ParseTfstate(data)
+		require.NoError(t, err)
+		graph := parser.BuildGraph(state)
+		require.NoError(t, x.Update(models.StateVersion{Lineage: "prod", Serial: serial}, graph))
+
+		return graph
+	}
+
+	t.Run("patches the previous graph with a full graph every few versions", func(t *testing.T) {
+		x := newIndex()
+
+		graphs := map[int]*models.Graph{}
+		for serial := 1; serial <= 4; serial++ {
+			graphs[serial] = store(t, x, serial)
+		}
+
+		for serial, depth := range map[int]int{1: 0, 2: 1, 3: 2, 4: 0} {
+			stored, err := x.States.Graph("prod", serial)
+			require.NoError(t, err)
+			assert.Equal(t, depth, stored.Depth, "serial %d", serial)
+			assert.Equal(t, depth == 0, stored.Graph != nil, "serial %d", serial)
+		}
+
+		stored, _ := x.States.Graph("prod", 3)
+		assert.Len(t, stored.Patch.Nodes, 1, "only the changed bucket is stored")
+
+		for serial, graph := range graphs {
+			indexed, err := x.Graph("prod", serial)
+			require.NoError(t, err)
+			assert.Equal(t, parser.Digest(graph), parser.Digest(indexed), "serial %d", serial)
+		}
+	})
+
+	t.Run("builds the graphs it does not have", func(t *testing.T) {
+		x := newIndex()
+		require.NoError(t, x.States.Put(models.StateVersion{Lineage: "prod", Serial: 1}, indexState(1)))
+
+		graph, err := x.Graph("prod", 1)
+		require.NoError(t, err)
+		assert.Len(t, graph.Nodes, 2)
+
+		stored, err := x.States.Graph("prod", 1)
+		require.NoError(t, err)
+		assert.NotNil(t, stored.Graph)
+
+		_, err = x.Graph("prod", 2)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("rebuilds graphs whose base changed", func(t *testing.T) {
+		x := newIndex()
+		store(t, x, 1)
+		second := store(t, x, 2)
+
+		// Storing the first version again replaces its graph.
+		require.NoError(t, x.States.Put(models.StateVersion{Lineage: "prod", Serial: 1}, indexState(5)))
+		_, err := x.Graph("prod", 1)
+		require.NoError(t, err)
+
+		graph, err := x.Graph("prod", 2)
+		require.NoError(t, err)
+		assert.Equal(t, parser.Digest(second), parser.Digest(graph))
+
+		stored, _ := x.States.Graph("prod", 2)
+		assert.NotNil(t, stored.Graph, "the rebuilt graph is stored in full")
+	})
+
+	t.Run("does not build archived versions", func(t *testing.T) {
+		x := newIndex()
+		require.NoError(t, x.States.Put(models.StateVersion{Lineage: "prod", Serial: 1}, indexState(1)))
+		require.NoError(t, x.States.Archive("prod", 1))
+
+		_, err := x.Graph("prod", 1)
+		assert.ErrorIs(t, err, ErrArchived)
+	})
+}
@@ -0,0 +1,529 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// StateStore is the StateBackend that keeps states in memory, which is lost
+// on restart. Documents are kept as chunks shared by every version that
+// contains them, see splitChunks.
+type StateStore struct {
+	mu        sync.Mutex
+	lineages  map[string]*lineageHistory
+	trash     map[string]*trashedLineage
+	chunks    map[string]*storedChunk
+	values    map[string]*storedValue
+	retention time.Duration
+}
+
+type lineageHistory struct {
+	versions map[int]*storedState
+}
+
+type storedState struct {
+	version models.StateVersion
+	chunks  []string
+	size    int64
+	graph   *models.StoredGraph
+	// values holds the digests of the attribute values of the version.
+	values []string
+}
+
+// storedChunk is a chunk with the number of stored versions that use it.
+type storedChunk struct {
+	data []byte
+	refs int
+}
+
+// storedValue is an attribute value with the number of stored versions that
+// have it.
+type storedValue struct {
+	attributeValue
+	terms map[string]bool
+	refs  int
+}
+
+type trashedLineage struct {
+	history   *lineageHistory
+	deletedAt time.Time
+}
+
+// NewStateStore returns an empty store that keeps deleted lineages in the
+// trash for the given retention window.
+func NewStateStore(retention time.Duration) *StateStore {
+	return &StateStore{
+		lineages:  make(map[string]*lineageHistory),
+		trash:     make(map[string]*trashedLineage),
+		chunks:    make(map[string]*storedChunk),
+		values:    make(map[string]*storedValue),
+		retention: retention,
+	}
+}
+
+// Put stores a state version, replacing a stored version with the same
+// serial.
+func (s *StateStore) Put(version models.StateVersion, data []byte) error {
+	values, digests := stateAttributes(data)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, trashed := s.trash[version.Lineage]; trashed {
+		return ErrTrashed
+	}
+
+	history, ok := s.lineages[version.Lineage]
+	if !ok {
+		history = &lineageHistory{versions: make(map[int]*storedState)}
+		s.lineages[version.Lineage] = history
+	}
+
+	if previous, ok := history.versions[version.Serial]; ok {
+		s.release(previous.chunks)
+		s.releaseValues(previous.values)
+	}
+
+	history.versions[version.Serial] = &storedState{
+		version: version,
+		chunks:  s.hold(data),
+		size:    int64(len(data)),
+		values:  s.holdValues(values, digests),
+	}
+
+	return nil
+}
+
+// Lineages summarizes every lineage with stored versions, ordered by lineage.
+func (s *StateStore) Lineages() ([]models.StateHistory, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	histories := make([]models.StateHistory, 0, len(s.lineages))
+	for lineage, history := range s.lineages {
+		versions := history.sorted()
+		histories = append(histories, models.StateHistory{
+			Lineage:  lineage,
+			Versions: len(versions),
+			Latest:   versions[len(versions)-1],
+		})
+	}
+
+	sort.Slice(histories, func(i, j int) bool {
+		return histories[i].Lineage < histories[j].Lineage
+	})
+
+	return histories, nil
+}
+
+// Versions returns the stored versions of a lineage ordered by serial.
+func (s *StateStore) Versions(lineage string) ([]models.StateVersion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history, ok := s.lineages[lineage]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return history.sorted(), nil
+}
+
+// Get returns a stored state version and its raw document.
+func (s *StateStore) Get(lineage string, serial int) (models.StateVersion, []byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.state(lineage, serial)
+	if !ok {
+		return models.StateVersion{}, nil, ErrNotFound
+	}
+
+	if state.version.Archived {
+		return state.version, nil, ErrArchived
+	}
+
+	data := make([]byte, 0, state.size)
+	for _, digest := range state.chunks {
+		data = append(data, s.chunks[digest].data...)
+	}
+
+	return state.version, data, nil
+}
+
+// Archive drops the document of a version copied to cold storage, keeping its
+// metadata, until Put stores the document again.
+func (s *StateStore) Archive(lineage string, serial int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.state(lineage, serial)
+	if !ok {
+		return ErrNotFound
+	}
+
+	state.version.Archived = true
+	s.release(state.chunks)
+	state.chunks, state.size = nil, 0
+
+	return nil
+}
+
+// PutGraph stores the graph of a stored version, until Put replaces the
+// version.
+func (s *StateStore) PutGraph(lineage string, serial int, graph models.StoredGraph) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.state(lineage, serial)
+	if !ok {
+		return ErrNotFound
+	}
+
+	state.graph = &graph
+
+	return nil
+}
+
+// Graph returns the stored graph of a version.
+func (s *StateStore) Graph(lineage string, serial int) (models.StoredGraph, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.state(lineage, serial)
+	if !ok || state.graph == nil {
+		return models.StoredGraph{}, ErrNotFound
+	}
+
+	return *state.graph, nil
+}
+
+// state returns a stored version of a lineage that is not in the trash.
+func (s *StateStore) state(lineage string, serial int) (*storedState, bool) {
+	history, ok := s.lineages[lineage]
+	if !ok {
+		return nil, false
+	}
+
+	state, ok := history.versions[serial]
+
+	return state, ok
+}
+
+// Delete moves a lineage and its history to the trash.
+func (s *StateStore) Delete(lineage string, now time.Time) (models.TrashEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purge(now)
+
+	history, ok := s.lineages[lineage]
+	if !ok {
+		return models.TrashEntry{}, ErrNotFound
+	}
+
+	delete(s.lineages, lineage)
+	trashed := &trashedLineage{history: history, deletedAt: now}
+	s.trash[lineage] = trashed
+
+	return s.entry(lineage, trashed), nil
+}
+
+// Trash lists the deleted lineages that can still be restored, most recently
+// deleted first, dropping those past the retention window.
+func (s *StateStore) Trash(now time.Time) ([]models.TrashEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purge(now)
+
+	entries := make([]models.TrashEntry, 0, len(s.trash))
+	for lineage, trashed := range s.trash {
+		entries = append(entries, s.entry(lineage, trashed))
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].DeletedAt.Equal(entries[j].DeletedAt) {
+			return entries[i].DeletedAt.After(entries[j].DeletedAt)
+		}
+
+		return entries[i].Lineage < entries[j].Lineage
+	})
+
+	return entries, nil
+}
+
+// Restore moves a lineage back from the trash.
+func (s *StateStore) Restore(lineage string, now time.Time) (models.StateHistory, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purge(now)
+
+	trashed, ok := s.trash[lineage]
+	if !ok {
+		return models.StateHistory{}, ErrNotFound
+	}
+
+	delete(s.trash, lineage)
+	s.lineages[lineage] = trashed.history
+
+	versions := trashed.history.sorted()
+
+	return models.StateHistory{Lineage: lineage, Versions: len(versions), Latest: versions[len(versions)-1]}, nil
+}
+
+// StoredBytes sums the size of the stored versions per project, those in the
+// trash included until they are purged and those archived to cold storage
+// excluded.
+func (s *StateStore) StoredBytes(now time.Time) (map[string]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purge(now)
+
+	stored := make(map[string]int64)
+	add := func(history *lineageHistory) {
+		for _, state := range history.versions {
+			stored[state.version.Project] += state.size
+		}
+	}
+
+	for _, history := range s.lineages {
+		add(history)
+	}
+	for _, trashed := range s.trash {
+		add(trashed.history)
+	}
+
+	return stored, nil
+}
+
+// ProjectStoredBytes sums the size of the stored versions of a project, as
+// StoredBytes does.
+func (s *StateStore) ProjectStoredBytes(project string, now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var stored int64
+	add := func(history *lineageHistory) {
+		for _, state := range history.versions {
+			if state.version.Project == project {
+				stored += state.size
+			}
+		}
+	}
+
+	for _, history := range s.lineages {
+		add(history)
+	}
+	for _, trashed := range s.trash {
+		if now.Before(trashed.deletedAt.Add(s.retention)) {
+			add(trashed.history)
+		}
+	}
+
+	return stored, nil
+}
+
+// Search returns the attribute values of the stored versions that have every
+// term of the query, in the lineage when it is not empty, up to limit hits.
+func (s *StateStore) Search(query, lineage string, limit int) (models.SearchResults, error) {
+	hits := newSearchHits(query, limit)
+
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return hits.results, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matches := make(map[string]bool)
+	matching := func(digest string) bool {
+		matched, ok := matches[digest]
+		if !ok {
+			matched = true
+			for _, term := range terms {
+				matched = matched && s.values[digest].terms[term]
+			}
+			matches[digest] = matched
+		}
+
+		return matched
+	}
+
+	for _, name := range s.sortedLineages() {
+		if lineage != "" && name != lineage {
+			continue
+		}
+
+		serials := make(map[string][]int)
+		for _, version := range s.lineages[name].sorted() {
+			for _, digest := range s.lineages[name].versions[version.Serial].values {
+				if matching(digest) {
+					serials[digest] = append(serials[digest], version.Serial)
+				}
+			}
+		}
+
+		digests := make([]string, 0, len(serials))
+		for digest := range serials {
+			digests = append(digests, digest)
+		}
+		sort.Slice(digests, func(i, j int) bool {
+			return s.values[digests[i]].before(s.values[digests[j]].attributeValue)
+		})
+
+		for _, digest := range digests {
+			for _, serial := range serials[digest] {
+				if !hits.add(name, serial, s.values[digest].attributeValue) {
+					return hits.results, nil
+				}
+			}
+		}
+	}
+
+	return hits.results, nil
+}
+
+// Lookup returns the instances of the latest version of each lineage whose
+// id, arn or self_link is the ID.
+func (s *StateStore) Lookup(id string) ([]models.LookupMatch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matches := []models.LookupMatch{}
+	for _, name := range s.sortedLineages() {
+		versions := s.lineages[name].sorted()
+		latest := versions[len(versions)-1]
+
+		var found []models.LookupMatch
+		for _, digest := range s.lineages[name].versions[latest.Serial].values {
+			if match, ok := lookupMatch(latest, s.values[digest].attributeValue, id); ok {
+				found = append(found, match)
+			}
+		}
+		sort.Slice(found, func(i, j int) bool {
+			if found[i].Address != found[j].Address {
+				return found[i].Address < found[j].Address
+			}
+			return found[i].Attribute < found[j].Attribute
+		})
+
+		matches = append(matches, found...)
+	}
+
+	return matches, nil
+}
+
+func (s *StateStore) sortedLineages() []string {
+	names := make([]string, 0, len(s.lineages))
+	for name := range s.lineages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (s *StateStore) purge(now time.Time) {
+	for lineage, trashed := range s.trash {
+		if !now.Before(trashed.deletedAt.Add(s.retention)) {
+			for _, state := range trashed.history.versions {
+				s.release(state.chunks)
+				s.releaseValues(state.values)
+			}
+			delete(s.trash, lineage)
+		}
+	}
+}
+
+// hold stores the chunks of a document that are not stored yet, and returns
+// their digests in order.
+func (s *StateStore) hold(data []byte) []string {
+	chunks := splitChunks(data)
+	digests := make([]string, len(chunks))
+
+	for i, chunk := range chunks {
+		digests[i] = chunkDigest(chunk)
+
+		stored, ok := s.chunks[digests[i]]
+		if !ok {
+			// Copy the chunk so that it does not keep the whole upload alive.
+			stored = &storedChunk{data: bytes.Clone(chunk)}
+			s.chunks[digests[i]] = stored
+		}
+		stored.refs++
+	}
+
+	return digests
+}
+
+// release drops the chunks no longer used by any stored version.
+func (s *StateStore) release(digests []string) {
+	for _, digest := range digests {
+		stored := s.chunks[digest]
+		if stored.refs--; stored.refs == 0 {
+			delete(s.chunks, digest)
+		}
+	}
+}
+
+// holdValues stores the attribute values of a version that are not stored
+// yet, and returns their digests.
+func (s *StateStore) holdValues(values []attributeValue, digests []string) []string {
+	for i, digest := range digests {
+		stored, ok := s.values[digest]
+		if !ok {
+			terms := make(map[string]bool)
+			for _, term := range values[i].terms() {
+				terms[term] = true
+			}
+			stored = &storedValue{attributeValue: values[i], terms: terms}
+			s.values[digest] = stored
+		}
+		stored.refs++
+	}
+
+	return digests
+}
+
+// releaseValues drops the attribute values no longer had by any stored
+// version.
+func (s *StateStore) releaseValues(digests []string) {
+	for _, digest := range digests {
+		stored := s.values[digest]
+		if stored.refs--; stored.refs == 0 {
+			delete(s.values, digest)
+		}
+	}
+}
+
+func (s *StateStore) entry(lineage string, trashed *trashedLineage) models.TrashEntry {
+	return models.TrashEntry{
+		Lineage:   lineage,
+		Versions:  len(trashed.history.versions),
+		DeletedAt: trashed.deletedAt,
+		ExpiresAt: trashed.deletedAt.Add(s.retention),
+	}
+}
+
+func (h *lineageHistory) sorted() []models.StateVersion {
+	versions := make([]models.StateVersion, 0, len(h.versions))
+	for _, state := range h.versions {
+		versions = append(versions, state.version)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Serial < versions[j].Serial
+	})
+
+	return versions
+}
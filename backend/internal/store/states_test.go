@@ -0,0 +1,196 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestStateStore(t *testing.T) {
+	now := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+
+	newStore := func() *StateStore {
+		s := NewStateStore(24 * time.Hour)
+		s.Put(models.StateVersion{Lineage: "prod", Serial: 2}, []byte(`{"serial": 2}`))
+		s.Put(models.StateVersion{Lineage: "prod", Serial: 1}, []byte(`{"serial": 1}`))
+		s.Put(models.StateVersion{Lineage: "dev", Serial: 7}, []byte(`{"serial": 7}`))
+
+		return s
+	}
+
+	t.Run("keeps versions ordered by serial", func(t *testing.T) {
+		s := newStore()
+
+		versions, err := s.Versions("prod")
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		assert.Equal(t, 1, versions[0].Serial)
+		assert.Equal(t, 2, versions[1].Serial)
+
+		_, data, err := s.Get("prod", 1)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"serial": 1}`, string(data))
+
+		lineages, err := s.Lineages()
+		require.NoError(t, err)
+		require.Len(t, lineages, 2)
+		assert.Equal(t, "dev", lineages[0].Lineage)
+		assert.Equal(t, 2, lineages[1].Versions)
+		assert.Equal(t, 2, lineages[1].Latest.Serial)
+	})
+
+	t.Run("delete moves the lineage to the trash", func(t *testing.T) {
+		s := newStore()
+
+		entry, err := s.Delete("prod", now)
+		require.NoError(t, err)
+		assert.Equal(t, 2, entry.Versions)
+		assert.Equal(t, now.Add(24*time.Hour), entry.ExpiresAt)
+
+		_, err = s.Versions("prod")
+		assert.ErrorIs(t, err, ErrNotFound)
+		lineages, _ := s.Lineages()
+		assert.Len(t, lineages, 1)
+
+		trash, err := s.Trash(now.Add(time.Hour))
+		require.NoError(t, err)
+		require.Len(t, trash, 1)
+		assert.Equal(t, "prod", trash[0].Lineage)
+
+		_, err = s.Delete("prod", now)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("trashed lineages reject new versions", func(t *testing.T) {
+		s := newStore()
+		s.Delete("prod", now)
+
+		assert.ErrorIs(t, s.Put(models.StateVersion{Lineage: "prod", Serial: 3}, nil), ErrTrashed)
+	})
+
+	t.Run("restore brings the history back", func(t *testing.T) {
+		s := newStore()
+		s.Delete("prod", now)
+
+		history, err := s.Restore("prod", now.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 2, history.Versions)
+		assert.Equal(t, 2, history.Latest.Serial)
+
+		_, _, err = s.Get("prod", 2)
+		assert.NoError(t, err)
+		trash, _ := s.Trash(now)
+		assert.Empty(t, trash)
+	})
+
+	t.Run("expired lineages are purged", func(t *testing.T) {
+		s := newStore()
+		s.Delete("prod", now)
+
+		_, err := s.Restore("prod", now.Add(24*time.Hour))
+		assert.ErrorIs(t, err, ErrNotFound)
+		trash, _ := s.Trash(now)
+		assert.Empty(t, trash)
+	})
+
+	t.Run("trash lists the latest deletions first", func(t *testing.T) {
+		s := newStore()
+		s.Delete("prod", now)
+		s.Delete("dev", now.Add(time.Minute))
+
+		trash, err := s.Trash(now.Add(time.Hour))
+		require.NoError(t, err)
+		require.Len(t, trash, 2)
+		assert.Equal(t, "dev", trash[0].Lineage)
+		assert.Equal(t, "prod", trash[1].Lineage)
+	})
+
+	t.Run("sums stored bytes per project", func(t *testing.T) {
+		s := newStore()
+		s.Put(models.StateVersion{Lineage: "web", Serial: 1, Project: "web"}, []byte(`{"serial": 1}`))
+		s.Delete("prod", now)
+
+		stored, err := s.StoredBytes(now)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{"": 39, "web": 13}, stored)
+
+		stored, _ = s.StoredBytes(now.Add(24 * time.Hour))
+		assert.Equal(t, map[string]int64{"": 13, "web": 13}, stored)
+	})
+
+	t.Run("versions share their unchanged resources", func(t *testing.T) {
+		s := NewStateStore(time.Hour)
+		tfstate := func(serial string) []byte {
+			return []byte(`{"serial": ` + serial + `, "resources": [{"name": "a"}, {"name": "b"}]}`)
+		}
+
+		s.Put(models.StateVersion{Lineage: "prod", Serial: 1}, tfstate("1"))
+		s.Put(models.StateVersion{Lineage: "prod", Serial: 2}, tfstate("2"))
+		assert.Len(t, s.chunks, 5, "only the heads differ")
+
+		_, data, err := s.Get("prod", 2)
+		require.NoError(t, err)
+		assert.Equal(t, tfstate("2"), data)
+
+		s.Put(models.StateVersion{Lineage: "prod", Serial: 2}, tfstate("1"))
+		assert.Len(t, s.chunks, 4, "replaced versions release their chunks")
+
+		s.Delete("prod", now)
+		s.Trash(now.Add(time.Hour))
+		assert.Empty(t, s.chunks, "purged versions release their chunks")
+	})
+
+	t.Run("archive drops the document", func(t *testing.T) {
+		s := newStore()
+
+		require.NoError(t, s.Archive("prod", 1))
+
+		version, data, err := s.Get("prod", 1)
+		assert.ErrorIs(t, err, ErrArchived)
+		assert.Nil(t, data)
+		assert.True(t, version.Archived)
+
+		versions, _ := s.Versions("prod")
+		assert.True(t, versions[0].Archived)
+		stored, _ := s.StoredBytes(now)
+		assert.Equal(t, map[string]int64{"": 26}, stored)
+
+		assert.ErrorIs(t, s.Archive("prod", 9), ErrNotFound)
+	})
+}
+
+func TestProjectStoredBytes(t *testing.T) {
+	now := time.Now().UTC()
+
+	forEachStateBackend(t, func(t *testing.T, states StateBackend) {
+		put := func(lineage, project string, serial int) {
+			require.NoError(t, states.Put(models.StateVersion{Lineage: lineage, Serial: serial, Project: project, Size: 13, StoredAt: now}, []byte(`{"serial": `+strconv.Itoa(serial)+`}`)))
+		}
+		put("web", "web", 1)
+		put("web", "web", 2)
+		put("api", "api", 1)
+		put("old", "web", 1)
+
+		stored, err := states.ProjectStoredBytes("web", now)
+		require.NoError(t, err)
+		assert.Equal(t, int64(39), stored)
+
+		_, err = states.Delete("old", now)
+		require.NoError(t, err)
+
+		stored, _ = states.ProjectStoredBytes("web", now)
+		assert.Equal(t, int64(39), stored, "the trash counts until it is purged")
+
+		stored, _ = states.ProjectStoredBytes("web", now.Add(2*time.Hour))
+		assert.Equal(t, int64(26), stored)
+
+		stored, _ = states.ProjectStoredBytes("unknown", now)
+		assert.Zero(t, stored)
+	})
+}
@@ -0,0 +1,74 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"sync"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// GraphStore holds up to a fixed number of graphs keyed by digest, evicting
+// the least recently stored. Stored graphs are shared between callers and
+// must not be modified.
+type GraphStore struct {
+	mu         sync.Mutex
+	graphs     map[string]*models.Graph
+	order      []string
+	maxEntries int
+}
+
+// NewGraphStore returns a store that keeps up to maxEntries graphs.
+func NewGraphStore(maxEntries int) *GraphStore {
+	return &GraphStore{
+		graphs:     make(map[string]*models.Graph),
+		maxEntries: maxEntries,
+	}
+}
+
+// Put stores the graph under its digest. Storing a digest again replaces the
+// graph and makes it the most recently stored.
+func (s *GraphStore) Put(digest string, graph *models.Graph) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.graphs[digest]; exists {
+		for i, stored := range s.order {
+			if stored == digest {
+				s.order = append(s.order[:i], s.order[i+1:]...)
+				break
+			}
+		}
+	}
+
+	s.graphs[digest] = graph
+	s.order = append(s.order, digest)
+
+	for len(s.order) > s.maxEntries {
+		delete(s.graphs, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+// Get returns the graph stored under the digest.
+func (s *GraphStore) Get(digest string) (*models.Graph, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	graph, ok := s.graphs[digest]
+
+	return graph, ok
+}
+
+// All returns the stored graphs keyed by digest, least recently stored first.
+func (s *GraphStore) All() []models.ArchivedGraph {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	graphs := make([]models.ArchivedGraph, 0, len(s.order))
+	for _, digest := range s.order {
+		graphs = append(graphs, models.ArchivedGraph{Digest: digest, Graph: s.graphs[digest]})
+	}
+
+	return graphs
+}
@@ -0,0 +1,65 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestGraphStore(t *testing.T) {
+	graph := func(id string) *models.Graph {
+		return &models.Graph{Nodes: []models.Node{{ID: id}}}
+	}
+
+	t.Run("get returns stored graphs", func(t *testing.T) {
+		s := NewGraphStore(2)
+		s.Put("sha256:a", graph("a"))
+
+		stored, ok := s.Get("sha256:a")
+		require.True(t, ok)
+		assert.Equal(t, "a", stored.Nodes[0].ID)
+
+		_, ok = s.Get("sha256:b")
+		assert.False(t, ok)
+	})
+
+	t.Run("evicts the least recently stored", func(t *testing.T) {
+		s := NewGraphStore(2)
+		s.Put("a", graph("a"))
+		s.Put("b", graph("b"))
+		s.Put("a", graph("a"))
+		s.Put("c", graph("c"))
+
+		_, ok := s.Get("b")
+		assert.False(t, ok)
+		_, ok = s.Get("a")
+		assert.True(t, ok)
+		_, ok = s.Get("c")
+		assert.True(t, ok)
+	})
+
+	t.Run("concurrent access", func(t *testing.T) {
+		s := NewGraphStore(8)
+
+		var wg sync.WaitGroup
+		for i := range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				digest := fmt.Sprintf("d%d", i%10)
+				s.Put(digest, graph(digest))
+				s.Get(digest)
+			}()
+		}
+		wg.Wait()
+
+		assert.LessOrEqual(t, len(s.graphs), 8)
+		assert.Len(t, s.order, len(s.graphs))
+	})
+}
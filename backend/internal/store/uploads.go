@@ -0,0 +1,319 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// Errors returned by UploadStore.PutPart and UploadStore.Assemble.
+var (
+	ErrUploadNotFound = errors.New("upload not found")
+	ErrUploadTooLarge = errors.New("upload too large")
+)
+
+// UploadStore holds the parts of chunked uploads until they are assembled.
+// Parts are spooled to files in a directory rather than kept in memory.
+// Uploads that receive no part for longer than the idle timeout expire, and
+// at most a fixed number of uploads are in progress at once, fewer for each
+// client.
+type UploadStore struct {
+	mu           sync.Mutex
+	uploads      map[string]*pendingUpload
+	dir          string
+	maxActive    int
+	maxPerClient int
+	idle         time.Duration
+}
+
+type pendingUpload struct {
+	upload models.Upload
+	client string
+	// parts holds the size of each part received.
+	parts map[int]int64
+}
+
+// NewUploadStore returns a store that spools parts to files in dir, which is
+// created when needed, and keeps up to maxActive uploads, maxPerClient of
+// any client, each until it has been idle for the given duration.
+func NewUploadStore(dir string, maxActive, maxPerClient int, idle time.Duration) *UploadStore {
+	return &UploadStore{
+		uploads:      make(map[string]*pendingUpload),
+		dir:          dir,
+		maxActive:    maxActive,
+		maxPerClient: maxPerClient,
+		idle:         idle,
+	}
+}
+
+// Create starts the upload of the client, such as its project, and returns it
+// with its expiry set. It returns false when the maximum number of uploads,
+// overall or of the client, is already in progress.
+func (s *UploadStore) Create(upload models.Upload, client string, now time.Time) (models.Upload, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(now)
+	if len(s.uploads) >= s.maxActive {
+		return models.Upload{}, false
+	}
+
+	active := 0
+	for _, pending := range s.uploads {
+		if pending.client == client {
+			active++
+		}
+	}
+	if active >= s.maxPerClient {
+		return models.Upload{}, false
+	}
+
+	upload.CreatedAt = now
+	upload.ExpiresAt = now.Add(s.idle)
+	upload.Parts = []int{}
+	s.uploads[upload.ID] = &pendingUpload{upload: upload, client: client, parts: make(map[int]int64)}
+
+	return upload, true
+}
+
+// Get returns the upload with the parts received so far.
+func (s *UploadStore) Get(id string, now time.Time) (models.Upload, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(now)
+
+	pending, ok := s.uploads[id]
+	if !ok {
+		return models.Upload{}, false
+	}
+
+	return pending.snapshot(), true
+}
+
+// PutPart stores the numbered part of the upload, replacing a part sent
+// before under the same number, and extends the upload's expiry. It fails
+// when the upload does not exist, the part would make it exceed maxSize or
+// the part cannot be spooled.
+func (s *UploadStore) PutPart(id string, part int, data []byte, maxSize int64, now time.Time) (models.Upload, error) {
+	if err := s.checkPart(id, part, int64(len(data)), maxSize, now); err != nil {
+		return models.Upload{}, err
+	}
+
+	spooled, err := s.spool(id, data)
+	if err != nil {
+		return models.Upload{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The upload may have been completed, deleted or grown by other parts
+	// while this one was written.
+	pending, received, err := s.receivedLocked(id, part, int64(len(data)), maxSize)
+	if err != nil {
+		os.Remove(spooled)
+		return models.Upload{}, err
+	}
+
+	if err := os.Rename(spooled, s.partPath(id, part)); err != nil {
+		os.Remove(spooled)
+		return models.Upload{}, err
+	}
+
+	pending.parts[part] = int64(len(data))
+	pending.upload.Received = received
+	pending.upload.ExpiresAt = now.Add(s.idle)
+
+	return pending.snapshot(), nil
+}
+
+// checkPart checks that the part may be stored, after expiring idle uploads.
+func (s *UploadStore) checkPart(id string, part int, size, maxSize int64, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(now)
+
+	_, _, err := s.receivedLocked(id, part, size, maxSize)
+	return err
+}
+
+// receivedLocked returns the upload and its size once the part is stored. The
+// caller holds the lock.
+func (s *UploadStore) receivedLocked(id string, part int, size, maxSize int64) (*pendingUpload, int64, error) {
+	pending, ok := s.uploads[id]
+	if !ok {
+		return nil, 0, ErrUploadNotFound
+	}
+
+	received := pending.upload.Received - pending.parts[part] + size
+	if received > maxSize {
+		return nil, 0, ErrUploadTooLarge
+	}
+
+	return pending, received, nil
+}
+
+// spool writes the data of a part of the upload to a new file and returns its
+// path.
+func (s *UploadStore) spool(id string, data []byte) (string, error) {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return "", err
+	}
+
+	file, err := os.CreateTemp(s.dir, id+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+
+	return file.Name(), nil
+}
+
+// AssembledUpload reads the parts of an upload in order from their spooled
+// files, so that the document is never held in memory as a whole.
+type AssembledUpload struct {
+	io.Reader
+	files []*os.File
+}
+
+// Rewind starts reading the document over.
+func (a *AssembledUpload) Rewind() error {
+	readers := make([]io.Reader, len(a.files))
+	for i, file := range a.files {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		readers[i] = file
+	}
+
+	a.Reader = io.MultiReader(readers...)
+	return nil
+}
+
+// Close closes the files of the parts.
+func (a *AssembledUpload) Close() error {
+	var err error
+	for _, file := range a.files {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+// Assemble opens the parts of the upload to be read in order. The parts must
+// be numbered from 1 without gaps. Parts sent again later do not change what
+// is read. The upload is kept until it is deleted, and the caller closes the
+// AssembledUpload.
+func (s *UploadStore) Assemble(id string, now time.Time) (*AssembledUpload, models.Upload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(now)
+
+	pending, ok := s.uploads[id]
+	if !ok {
+		return nil, models.Upload{}, ErrUploadNotFound
+	}
+
+	if len(pending.parts) == 0 {
+		return nil, models.Upload{}, fmt.Errorf("no parts received")
+	}
+
+	for part := 1; part <= len(pending.parts); part++ {
+		if _, ok := pending.parts[part]; !ok {
+			return nil, models.Upload{}, fmt.Errorf("missing part %d", part)
+		}
+	}
+
+	assembled := &AssembledUpload{}
+	for part := 1; part <= len(pending.parts); part++ {
+		file, err := os.Open(s.partPath(id, part))
+		if err != nil {
+			assembled.Close()
+			return nil, models.Upload{}, fmt.Errorf("reading part %d: %w", part, err)
+		}
+		assembled.files = append(assembled.files, file)
+	}
+
+	if err := assembled.Rewind(); err != nil {
+		assembled.Close()
+		return nil, models.Upload{}, err
+	}
+
+	return assembled, pending.snapshot(), nil
+}
+
+// Delete drops the upload and its parts, and reports whether it existed.
+func (s *UploadStore) Delete(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending, ok := s.uploads[id]
+	if ok {
+		s.drop(id, pending)
+	}
+
+	return ok
+}
+
+// expire drops the uploads that have been idle for too long.
+func (s *UploadStore) expire(now time.Time) {
+	for id, pending := range s.uploads {
+		if !now.Before(pending.upload.ExpiresAt) {
+			s.drop(id, pending)
+		}
+	}
+}
+
+// drop forgets the upload and removes its spooled parts.
+func (s *UploadStore) drop(id string, pending *pendingUpload) {
+	delete(s.uploads, id)
+
+	for part := range pending.parts {
+		if err := os.Remove(s.partPath(id, part)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("Removing part %d of upload %s: %v", part, id, err)
+		}
+	}
+}
+
+// partPath is the file a part of the upload is spooled to.
+func (s *UploadStore) partPath(id string, part int) string {
+	return filepath.Join(s.dir, id+"."+strconv.Itoa(part))
+}
+
+// snapshot returns a copy of the upload with its part numbers in order.
+func (p *pendingUpload) snapshot() models.Upload {
+	upload := p.upload
+
+	upload.Parts = make([]int, 0, len(p.parts))
+	for part := range p.parts {
+		upload.Parts = append(upload.Parts, part)
+	}
+	sort.Ints(upload.Parts)
+
+	return upload
+}
@@ -0,0 +1,146 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestUploadStore(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	t.Run("assembles parts in order", func(t *testing.T) {
+		s := NewUploadStore(t.TempDir(), 2, 2, time.Hour)
+		upload, ok := s.Create(models.Upload{ID: "u1", Target: "parse"}, "", now)
+		require.True(t, ok)
+		assert.Equal(t, now.Add(time.Hour), upload.ExpiresAt)
+
+		_, err := s.PutPart("u1", 2, []byte("world"), 100, now)
+		require.NoError(t, err)
+		_, err = s.PutPart("u1", 1, []byte("hello "), 100, now)
+		require.NoError(t, err)
+
+		document, upload, err := s.Assemble("u1", now)
+		require.NoError(t, err)
+		assert.Equal(t, "hello world", readAssembled(t, document))
+		assert.Equal(t, []int{1, 2}, upload.Parts)
+		assert.Equal(t, int64(11), upload.Received)
+
+		require.NoError(t, document.Rewind())
+		_, err = s.PutPart("u1", 2, []byte("there"), 100, now)
+		require.NoError(t, err)
+		assert.Equal(t, "hello world", readAssembled(t, document), "parts sent again are not read")
+		require.NoError(t, document.Close())
+
+		assert.True(t, s.Delete("u1"))
+		assert.False(t, s.Delete("u1"))
+	})
+
+	t.Run("spools parts to files until the upload is dropped", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "uploads")
+		s := NewUploadStore(dir, 2, 2, time.Hour)
+		s.Create(models.Upload{ID: "u1"}, "", now)
+		s.Create(models.Upload{ID: "u2"}, "", now)
+
+		_, err := s.PutPart("u1", 1, []byte("hello"), 100, now)
+		require.NoError(t, err)
+		_, err = s.PutPart("u2", 1, []byte("world"), 100, now.Add(time.Minute))
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(filepath.Join(dir, "u1.1"))
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(data))
+
+		s.Delete("u1")
+		assert.NoFileExists(t, filepath.Join(dir, "u1.1"))
+
+		s.Get("u2", now.Add(2*time.Hour))
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries, "expired uploads are removed")
+	})
+
+	t.Run("resent parts replace the previous ones", func(t *testing.T) {
+		s := NewUploadStore(t.TempDir(), 2, 2, time.Hour)
+		s.Create(models.Upload{ID: "u1"}, "", now)
+
+		s.PutPart("u1", 1, []byte("truncat"), 100, now)
+		upload, err := s.PutPart("u1", 1, []byte("complete"), 100, now)
+		require.NoError(t, err)
+		assert.Equal(t, int64(8), upload.Received)
+
+		document, _, err := s.Assemble("u1", now)
+		require.NoError(t, err)
+		defer document.Close()
+		assert.Equal(t, "complete", readAssembled(t, document))
+	})
+
+	t.Run("rejects gaps and oversized uploads", func(t *testing.T) {
+		s := NewUploadStore(t.TempDir(), 2, 2, time.Hour)
+		s.Create(models.Upload{ID: "u1"}, "", now)
+
+		_, _, err := s.Assemble("u1", now)
+		assert.EqualError(t, err, "no parts received")
+
+		s.PutPart("u1", 1, []byte("abc"), 5, now)
+		s.PutPart("u1", 3, []byte("ab"), 5, now)
+
+		_, _, err = s.Assemble("u1", now)
+		assert.EqualError(t, err, "missing part 2")
+
+		_, err = s.PutPart("u1", 2, []byte("a"), 5, now)
+		assert.ErrorIs(t, err, ErrUploadTooLarge)
+
+		_, err = s.PutPart("u2", 1, []byte("a"), 5, now)
+		assert.ErrorIs(t, err, ErrUploadNotFound)
+	})
+
+	t.Run("limits uploads in progress and expires idle ones", func(t *testing.T) {
+		s := NewUploadStore(t.TempDir(), 1, 1, time.Hour)
+
+		_, ok := s.Create(models.Upload{ID: "u1"}, "", now)
+		require.True(t, ok)
+		_, ok = s.Create(models.Upload{ID: "u2"}, "", now)
+		assert.False(t, ok)
+
+		_, err := s.PutPart("u1", 1, []byte("a"), 5, now.Add(50*time.Minute))
+		require.NoError(t, err)
+
+		_, ok = s.Get("u1", now.Add(90*time.Minute))
+		assert.True(t, ok, "parts extend the expiry")
+
+		_, ok = s.Create(models.Upload{ID: "u2"}, "", now.Add(2*time.Hour))
+		assert.True(t, ok)
+
+		_, ok = s.Get("u1", now.Add(2*time.Hour))
+		assert.False(t, ok)
+	})
+
+	t.Run("limits uploads in progress per client", func(t *testing.T) {
+		s := NewUploadStore(t.TempDir(), 3, 1, time.Hour)
+
+		_, ok := s.Create(models.Upload{ID: "u1", Project: "web"}, "web", now)
+		require.True(t, ok)
+		_, ok = s.Create(models.Upload{ID: "u2", Project: "web"}, "web", now)
+		assert.False(t, ok)
+		_, ok = s.Create(models.Upload{ID: "u3", Project: "api"}, "api", now)
+		assert.True(t, ok)
+	})
+}
+
+func readAssembled(t *testing.T, document *AssembledUpload) string {
+	t.Helper()
+
+	data, err := io.ReadAll(document)
+	require.NoError(t, err)
+
+	return string(data)
+}
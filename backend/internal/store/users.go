@@ -0,0 +1,192 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"crypto/sha256"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/terrascope/core/internal/models"
+)
+
+// UserStore is the UserBackend that keeps users in memory: the users allowed
+// to sign in, with their password hashes or, for invited users, their pending
+// invitation. Invitation tokens are kept hashed.
+type UserStore struct {
+	mu      sync.Mutex
+	users   map[string]*account
+	removed map[string]bool
+}
+
+type account struct {
+	user       models.User
+	hash       string
+	invitation [sha256.Size]byte
+	expiresAt  time.Time
+}
+
+// NewUserStore returns an empty user store.
+func NewUserStore() *UserStore {
+	return &UserStore{users: make(map[string]*account), removed: make(map[string]bool)}
+}
+
+func (s *UserStore) Add(user models.User, hash string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.users[user.Username]; exists {
+		return false, nil
+	}
+
+	user.Invited = false
+	s.users[user.Username] = &account{user: user, hash: hash}
+	delete(s.removed, user.Username)
+
+	return true, nil
+}
+
+func (s *UserStore) Seed(user models.User, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.removed[user.Username] {
+		return nil
+	}
+
+	if account, exists := s.users[user.Username]; exists {
+		account.user.Invited = false
+		account.hash = hash
+		account.invitation = [sha256.Size]byte{}
+		return nil
+	}
+
+	user.Invited = false
+	s.users[user.Username] = &account{user: user, hash: hash}
+
+	return nil
+}
+
+func (s *UserStore) Invite(user models.User, token string, expiresAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.users[user.Username]; exists {
+		return false, nil
+	}
+
+	user.Invited = true
+	s.users[user.Username] = &account{
+		user:       user,
+		invitation: sha256.Sum256([]byte(token)),
+		expiresAt:  expiresAt,
+	}
+	delete(s.removed, user.Username)
+
+	return true, nil
+}
+
+func (s *UserStore) Accept(token, hash string, now time.Time) (models.User, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := sha256.Sum256([]byte(token))
+
+	for _, account := range s.users {
+		if !account.user.Invited || account.invitation != key || !now.Before(account.expiresAt) {
+			continue
+		}
+
+		account.user.Invited = false
+		account.hash = hash
+		account.invitation = [sha256.Size]byte{}
+
+		return account.user, true, nil
+	}
+
+	return models.User{}, false, nil
+}
+
+func (s *UserStore) Get(username string) (models.User, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.users[username]
+	if !ok {
+		return models.User{}, false, nil
+	}
+
+	return account.user, true, nil
+}
+
+func (s *UserStore) Password(username string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if account, ok := s.users[username]; ok {
+		return account.hash, nil
+	}
+
+	return "", nil
+}
+
+func (s *UserStore) SetRole(username, role string) (models.User, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.users[username]
+	if !ok {
+		return models.User{}, false, nil
+	}
+
+	account.user.Role = role
+
+	return account.user, true, nil
+}
+
+func (s *UserStore) Delete(username string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.users[username]
+	delete(s.users, username)
+	s.removed[username] = true
+
+	return ok, nil
+}
+
+func (s *UserStore) List() ([]models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := make([]models.User, 0, len(s.users))
+	for _, account := range s.users {
+		users = append(users, account.user)
+	}
+
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+
+	return users, nil
+}
+
+func (s *UserStore) Len() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.users), nil
+}
+
+func (s *UserStore) Admins() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for _, account := range s.users {
+		if account.user.Role == models.RoleAdmin && !account.user.Invited {
+			n++
+		}
+	}
+
+	return n, nil
+}
@@ -0,0 +1,181 @@
+// Package store keeps recently built graphs, state locks and uploaded state
+// versions in memory so that follow-up requests can query them.
+package store
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terrascope/core/internal/models"
+)
+
+func TestUserStore(t *testing.T) {
+	testUserBackend(t, func(t *testing.T) UserBackend { return NewUserStore() })
+}
+
+func TestSQLUsers(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *sql.DB, dialect Dialect) {
+		testUserBackend(t, func(t *testing.T) UserBackend {
+			for _, table := range []string{"users", "removed_users"} {
+				_, err := db.Exec(`DELETE FROM ` + table)
+				require.NoError(t, err)
+			}
+			return NewSQLUsers(db, dialect)
+		})
+	})
+}
+
+func testUserBackend(t *testing.T, newBackend func(t *testing.T) UserBackend) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	password := func(t *testing.T, s UserBackend, username string) string {
+		t.Helper()
+
+		hash, err := s.Password(username)
+		require.NoError(t, err)
+
+		return hash
+	}
+
+	count := func(t *testing.T, s UserBackend) int {
+		t.Helper()
+
+		n, err := s.Len()
+		require.NoError(t, err)
+
+		return n
+	}
+
+	t.Run("added users sign in with their hash", func(t *testing.T) {
+		s := newBackend(t)
+
+		added, err := s.Add(models.User{Username: "alice", Role: models.RoleMember, CreatedAt: now}, "hash")
+		require.NoError(t, err)
+		require.True(t, added)
+		added, err = s.Add(models.User{Username: "alice", CreatedAt: now}, "other")
+		require.NoError(t, err)
+		assert.False(t, added)
+
+		assert.Equal(t, "hash", password(t, s, "alice"))
+		assert.Empty(t, password(t, s, "bob"))
+		assert.Equal(t, 1, count(t, s))
+	})
+
+	t.Run("invited users choose a password before the invitation expires", func(t *testing.T) {
+		s := newBackend(t)
+
+		invited, err := s.Invite(models.User{Username: "bob", Role: models.RoleAdmin, CreatedAt: now}, "token", now.Add(time.Hour))
+		require.NoError(t, err)
+		require.True(t, invited)
+		user, ok, err := s.Get("bob")
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.True(t, user.Invited)
+		assert.Empty(t, password(t, s, "bob"))
+
+		_, ok, err = s.Accept("wrong", "hash", now)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		user, ok, err = s.Accept("token", "hash", now)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, models.User{Username: "bob", Role: models.RoleAdmin, CreatedAt: now}, user)
+		assert.Equal(t, "hash", password(t, s, "bob"))
+
+		_, ok, err = s.Accept("token", "again", now)
+		require.NoError(t, err)
+		assert.False(t, ok, "invitations are accepted once")
+	})
+
+	t.Run("expired invitations cannot be accepted", func(t *testing.T) {
+		s := newBackend(t)
+		_, err := s.Invite(models.User{Username: "bob", CreatedAt: now}, "token", now)
+		require.NoError(t, err)
+
+		_, ok, err := s.Accept("token", "hash", now)
+
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("roles and removal", func(t *testing.T) {
+		s := newBackend(t)
+		s.Add(models.User{Username: "carol", Role: models.RoleMember, CreatedAt: now}, "hash")
+		s.Add(models.User{Username: "alice", Role: models.RoleMember, CreatedAt: now}, "hash")
+
+		user, ok, err := s.SetRole("carol", models.RoleAdmin)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, models.RoleAdmin, user.Role)
+
+		_, ok, err = s.SetRole("bob", models.RoleAdmin)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		users, err := s.List()
+		require.NoError(t, err)
+		require.Len(t, users, 2)
+		assert.Equal(t, "alice", users[0].Username)
+		assert.Equal(t, "carol", users[1].Username)
+
+		removed, err := s.Delete("carol")
+		require.NoError(t, err)
+		assert.True(t, removed)
+		removed, err = s.Delete("carol")
+		require.NoError(t, err)
+		assert.False(t, removed)
+		assert.Equal(t, 1, count(t, s))
+	})
+
+	t.Run("counts the admins who can sign in", func(t *testing.T) {
+		s := newBackend(t)
+		s.Add(models.User{Username: "alice", Role: models.RoleAdmin, CreatedAt: now}, "hash")
+		s.Add(models.User{Username: "carol", Role: models.RoleMember, CreatedAt: now}, "hash")
+		s.Invite(models.User{Username: "bob", Role: models.RoleAdmin, CreatedAt: now}, "token", now.Add(time.Hour))
+
+		admins := func() int {
+			t.Helper()
+
+			n, err := s.Admins()
+			require.NoError(t, err)
+
+			return n
+		}
+
+		assert.Equal(t, 1, admins(), "invited admins cannot sign in yet")
+
+		s.Accept("token", "hash", now)
+		s.SetRole("carol", models.RoleAdmin)
+		assert.Equal(t, 3, admins())
+
+		s.Delete("alice")
+		s.SetRole("bob", models.RoleMember)
+		assert.Equal(t, 1, admins())
+	})
+
+	t.Run("seeded users are updated but not added back once removed", func(t *testing.T) {
+		s := newBackend(t)
+
+		require.NoError(t, s.Seed(models.User{Username: "alice", Role: models.RoleMember, CreatedAt: now}, "hash"))
+		s.SetRole("alice", models.RoleAdmin)
+		require.NoError(t, s.Seed(models.User{Username: "alice", Role: models.RoleMember, CreatedAt: now.Add(time.Hour)}, "rotated"))
+
+		user, ok, err := s.Get("alice")
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, models.User{Username: "alice", Role: models.RoleAdmin, CreatedAt: now}, user)
+		assert.Equal(t, "rotated", password(t, s, "alice"))
+
+		s.Delete("alice")
+		require.NoError(t, s.Seed(models.User{Username: "alice", Role: models.RoleMember, CreatedAt: now}, "hash"))
+		assert.Zero(t, count(t, s))
+
+		invited, err := s.Invite(models.User{Username: "alice", CreatedAt: now}, "token", now.Add(time.Hour))
+		require.NoError(t, err)
+		assert.True(t, invited, "removed users can be invited again")
+	})
+}
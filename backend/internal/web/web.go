@@ -0,0 +1,36 @@
+// Package web embeds the frontend single-page application in the API binary,
+// so a single container can host both the UI and the API.
+package web
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// dist holds the production build of the frontend. The committed index.html
+// is a placeholder that the root Dockerfile replaces with the output of
+// `npm run build` before compiling the API.
+//
+//go:embed dist
+var dist embed.FS
+
+// loginPage is the sign-in form shown instead of the UI when login is
+// enabled and the browser has no session.
+//
+//go:embed login.html
+var loginPage []byte
+
+// Files returns the embedded frontend build.
+func Files() fs.FS {
+	files, err := fs.Sub(dist, "dist")
+	if err != nil {
+		panic(err)
+	}
+
+	return files
+}
+
+// LoginPage returns the sign-in form of the UI.
+func LoginPage() []byte {
+	return loginPage
+}
@@ -0,0 +1,25 @@
+// Package web embeds the frontend single-page application in the API binary,
+// so a single container can host both the UI and the API.
+package web
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFiles(t *testing.T) {
+	index, err := fs.ReadFile(Files(), "index.html")
+	require.NoError(t, err)
+
+	assert.Contains(t, string(index), "Terrascope")
+}
+
+func TestLoginPage(t *testing.T) {
+	page := string(LoginPage())
+
+	assert.Contains(t, page, "<title>Sign in · Terrascope</title>")
+	assert.Contains(t, page, "/api/v1/auth/login")
+}
@@ -0,0 +1,202 @@
+// Package client calls the Terrascope API from Go programs. A Client wraps
+// the HTTP endpoints in typed methods, retries the requests the server could
+// not serve and streams the events of stored states.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// apiPrefix is the path prefix of the API version the client speaks.
+	apiPrefix = "/api/v1"
+
+	defaultMaxRetries = 3
+	defaultRetryWait  = 500 * time.Millisecond
+	maxRetryWait      = 30 * time.Second
+	requestTimeout    = 60 * time.Second
+	userAgent         = "terrascope-go-client"
+)
+
+// Client calls the API of a Terrascope server. Token, when set, is sent as
+// the bearer token of every request, typically an API key.
+//
+// Requests that fail to reach the server, or that it answers with 429, 502,
+// 503 or 504, are retried up to MaxRetries times, waiting RetryWait and then
+// twice as long after each attempt, or as long as the server asks with
+// Retry-After.
+type Client struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+	MaxRetries int
+	RetryWait  time.Duration
+}
+
+// New returns a client for the server at baseURL, such as
+// https://terrascope.example.com.
+func New(baseURL, token string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: requestTimeout},
+		MaxRetries: defaultMaxRetries,
+		RetryWait:  defaultRetryWait,
+	}
+}
+
+// Error is the error of a request the server answered with an error status.
+// Message is the text of the response.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("terrascope returned %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// IsNotFound reports whether err is an error the server answered with 404.
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// Do sends a request to path, relative to the API prefix, with the body
+// encoded as JSON unless it is a []byte, sent as is, and decodes the JSON
+// response into v unless v is nil. It gives access to the endpoints without
+// a typed method.
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, body, v any) error {
+	var data []byte
+	switch body := body.(type) {
+	case nil:
+	case []byte:
+		data = body
+	default:
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode terrascope request: %w", err)
+		}
+	}
+
+	resp, err := c.send(ctx, method, path, query, data)
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	if v == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode terrascope response: %w", err)
+	}
+
+	return nil
+}
+
+// send sends a request, retrying it as the Client documents, and returns the
+// successful response, whose body the caller closes.
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body []byte) (*http.Response, error) {
+	target := c.BaseURL + apiPrefix + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	wait := c.RetryWait
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to build terrascope request: %w", err)
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("User-Agent", userAgent)
+		if c.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.Token)
+		}
+
+		resp, err := c.HTTPClient.Do(req)
+		if err == nil && resp.StatusCode < http.StatusBadRequest {
+			return resp, nil
+		}
+
+		var failure error
+		retryAfter := time.Duration(0)
+		if err != nil {
+			failure = fmt.Errorf("terrascope request failed: %w", err)
+		} else {
+			failure = responseError(resp)
+			if !retryable(resp.StatusCode) {
+				return nil, failure
+			}
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+		}
+
+		if attempt >= c.MaxRetries || ctx.Err() != nil {
+			return nil, failure
+		}
+
+		delay := min(max(wait, retryAfter), maxRetryWait)
+		select {
+		case <-ctx.Done():
+			return nil, failure
+		case <-time.After(delay):
+		}
+		wait *= 2
+	}
+}
+
+// responseError reads the message of an error response and closes it.
+func responseError(resp *http.Response) error {
+	defer closeBody(resp)
+
+	message, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return fmt.Errorf("failed to read terrascope response: %w", err)
+	}
+
+	return &Error{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(message))}
+}
+
+// retryable reports whether a request answered with status may succeed if
+// it is sent again: the server was rate limiting or unavailable.
+func retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// parseRetryAfter returns the delay of a Retry-After header in seconds, or
+// zero when it is missing or a date.
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+func closeBody(resp *http.Response) {
+	if err := resp.Body.Close(); err != nil {
+		log.Printf("failed to close terrascope response body: %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+// Package client calls the Terrascope API from Go programs. A Client wraps
+// the HTTP endpoints in typed methods, retries the requests the server could
+// not serve and streams the events of stored states.
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testClient returns a client of server that retries without waiting.
+func testClient(server *httptest.Server) *Client {
+	client := New(server.URL+"/", "tsk_secret")
+	client.RetryWait = time.Millisecond
+
+	return client
+}
+
+func TestClientDo(t *testing.T) {
+	t.Run("sends the token and decodes the response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v1/things", r.URL.Path)
+			assert.Equal(t, "a", r.URL.Query().Get("q"))
+			assert.Equal(t, "Bearer tsk_secret", r.Header.Get("Authorization"))
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"name":"thing"}`))
+		}))
+		defer server.Close()
+
+		var v struct{ Name string }
+		err := testClient(server).Do(context.Background(), http.MethodPost, "/things", map[string][]string{"q": {"a"}}, map[string]string{"a": "b"}, &v)
+
+		require.NoError(t, err)
+		assert.Equal(t, "thing", v.Name)
+	})
+
+	t.Run("retries unavailable servers", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls < 3 {
+				http.Error(w, "Storage unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{}`))
+		}))
+		defer server.Close()
+
+		require.NoError(t, testClient(server).Do(context.Background(), http.MethodPost, "/parse", nil, []byte(`{}`), nil))
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("gives up after MaxRetries", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			http.Error(w, "Quota exceeded", http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		client := testClient(server)
+		client.MaxRetries = 2
+
+		err := client.Do(context.Background(), http.MethodGet, "/health", nil, nil, nil)
+
+		var apiErr *Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+		assert.Equal(t, "Quota exceeded", apiErr.Message)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			http.Error(w, "Lineage not found: a", http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		err := testClient(server).Do(context.Background(), http.MethodGet, "/states/a", nil, nil, nil)
+
+		assert.True(t, IsNotFound(err))
+		assert.EqualError(t, err, "terrascope returned 404 Not Found: Lineage not found: a")
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("stops retrying when the context is done", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "10")
+			http.Error(w, "Unavailable", http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := testClient(server).Do(ctx, http.MethodGet, "/health", nil, nil, nil)
+
+		assert.Error(t, err)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert.Equal(t, 3*time.Second, parseRetryAfter("3"))
+	assert.Zero(t, parseRetryAfter(""))
+	assert.Zero(t, parseRetryAfter("Wed, 21 Oct 2026 07:28:00 GMT"))
+}
@@ -0,0 +1,169 @@
+// Package client calls the Terrascope API from Go programs. A Client wraps
+// the HTTP endpoints in typed methods, retries the requests the server could
+// not serve and streams the events of stored states.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ParseOptions selects how Parse builds the graph. The zero value builds the
+// default graph.
+type ParseOptions struct {
+	// IDFormat names how nodes are identified, such as address.
+	IDFormat string
+	// InstanceEdges points dependency edges at every instance of the target.
+	InstanceEdges bool
+	// References adds the edges inferred from attribute values.
+	References bool
+	// MaxNodes summarizes graphs with more nodes, when positive.
+	MaxNodes int
+}
+
+func (o ParseOptions) query() url.Values {
+	query := url.Values{}
+	if o.IDFormat != "" {
+		query.Set("id_format", o.IDFormat)
+	}
+	if o.InstanceEdges {
+		query.Set("instance_edges", "true")
+	}
+	if o.References {
+		query.Set("references", "true")
+	}
+	if o.MaxNodes > 0 {
+		query.Set("max_nodes", strconv.Itoa(o.MaxNodes))
+	}
+
+	return query
+}
+
+// AnalyzeOptions selects the analyzers Analyze runs, all of them by default,
+// and the baseline whose findings it leaves out: "previous" for the version
+// stored before the state, or the serial of a stored version.
+type AnalyzeOptions struct {
+	Analyzers []string
+	Baseline  string
+}
+
+// Health checks that the server is up.
+func (c *Client) Health(ctx context.Context) error {
+	return c.Do(ctx, http.MethodGet, "/health", nil, nil, nil)
+}
+
+// Parse builds the dependency graph of a tfstate document.
+func (c *Client) Parse(ctx context.Context, state []byte, opts ParseOptions) (*Graph, error) {
+	var graph Graph
+	if err := c.Do(ctx, http.MethodPost, "/parse", opts.query(), state, &graph); err != nil {
+		return nil, err
+	}
+
+	return &graph, nil
+}
+
+// Diff lists the resources added, removed, modified and renamed between two
+// tfstate documents.
+func (c *Client) Diff(ctx context.Context, before, after []byte) (*StateDiff, error) {
+	req := struct {
+		Before json.RawMessage `json:"before"`
+		After  json.RawMessage `json:"after"`
+	}{before, after}
+
+	var diff StateDiff
+	if err := c.Do(ctx, http.MethodPost, "/diff", nil, req, &diff); err != nil {
+		return nil, err
+	}
+
+	return &diff, nil
+}
+
+// Analyze runs analyzers over a tfstate document.
+func (c *Client) Analyze(ctx context.Context, state []byte, opts AnalyzeOptions) (*AnalysisReport, error) {
+	query := url.Values{}
+	if len(opts.Analyzers) > 0 {
+		query.Set("analyzers", strings.Join(opts.Analyzers, ","))
+	}
+	if opts.Baseline != "" {
+		query.Set("baseline", opts.Baseline)
+	}
+
+	var report AnalysisReport
+	if err := c.Do(ctx, http.MethodPost, "/analyze", query, state, &report); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// Analyzers lists the analyzers Analyze can run.
+func (c *Client) Analyzers(ctx context.Context) (*AnalyzerManifest, error) {
+	var manifest AnalyzerManifest
+	if err := c.Do(ctx, http.MethodGet, "/analyzers", nil, nil, &manifest); err != nil {
+		return nil, err
+	}
+
+	return &manifest, nil
+}
+
+// States lists the stored lineages.
+func (c *Client) States(ctx context.Context) ([]StateHistory, error) {
+	var lineages []StateHistory
+	if err := c.Do(ctx, http.MethodGet, "/states", nil, nil, &lineages); err != nil {
+		return nil, err
+	}
+
+	return lineages, nil
+}
+
+// StoreState stores a version of a tfstate document under its lineage.
+func (c *Client) StoreState(ctx context.Context, state []byte) (*StateVersion, error) {
+	var version StateVersion
+	if err := c.Do(ctx, http.MethodPost, "/states", nil, state, &version); err != nil {
+		return nil, err
+	}
+
+	return &version, nil
+}
+
+// StateVersions lists the stored versions of a lineage.
+func (c *Client) StateVersions(ctx context.Context, lineage string) ([]StateVersion, error) {
+	var versions []StateVersion
+	if err := c.Do(ctx, http.MethodGet, "/states/"+url.PathEscape(lineage), nil, nil, &versions); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// StateDocument returns the raw tfstate document of a stored version.
+func (c *Client) StateDocument(ctx context.Context, lineage string, serial int) ([]byte, error) {
+	resp, err := c.send(ctx, http.MethodGet, "/states/"+url.PathEscape(lineage)+"/"+strconv.Itoa(serial), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read terrascope response: %w", err)
+	}
+
+	return data, nil
+}
+
+// DeleteState moves a lineage to the trash.
+func (c *Client) DeleteState(ctx context.Context, lineage string) (*TrashEntry, error) {
+	var entry TrashEntry
+	if err := c.Do(ctx, http.MethodDelete, "/states/"+url.PathEscape(lineage), nil, nil, &entry); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
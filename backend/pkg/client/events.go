@@ -0,0 +1,71 @@
+// Package client calls the Terrascope API from Go programs. A Client wraps
+// the HTTP endpoints in typed methods, retries the requests the server could
+// not serve and streams the events of stored states.
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// StateEvents follows the events of a lineage, calling fn with each one as
+// the server sends it: when a version is stored and when an analysis of one
+// of its states completes. It returns when ctx is done, with its error, when
+// fn fails, with fn's error, or when the stream ends. Unlike the other
+// requests, the stream is not retried once it has started, and it is not
+// subject to the timeout of HTTPClient.
+func (c *Client) StateEvents(ctx context.Context, lineage string, fn func(StateEvent) error) error {
+	stream := *c
+	stream.HTTPClient = &http.Client{Transport: c.HTTPClient.Transport, Jar: c.HTTPClient.Jar}
+
+	resp, err := stream.send(ctx, http.MethodGet, "/states/"+url.PathEscape(lineage)+"/events", nil, nil)
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			if data.Len() == 0 {
+				continue
+			}
+
+			var event StateEvent
+			if err := json.Unmarshal([]byte(data.String()), &event); err != nil {
+				return fmt.Errorf("failed to decode terrascope event: %w", err)
+			}
+			data.Reset()
+
+			if err := fn(event); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, context.Canceled) {
+		return fmt.Errorf("terrascope event stream failed: %w", err)
+	}
+
+	return nil
+}
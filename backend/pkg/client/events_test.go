@@ -0,0 +1,54 @@
+// Package client calls the Terrascope API from Go programs. A Client wraps
+// the HTTP endpoints in typed methods, retries the requests the server could
+// not serve and streams the events of stored states.
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/states/prod/events", r.URL.Path)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		fmt.Fprint(w, "event: version\ndata: {\"type\":\"version\",\"lineage\":\"prod\",\"serial\":2}\n\n")
+		fmt.Fprint(w, "event: analysis\ndata: {\"type\":\"analysis\",\"lineage\":\"prod\",\"serial\":2,\"analysis\":\"analyze\"}\n\n")
+	}))
+	defer server.Close()
+
+	t.Run("calls fn with every event", func(t *testing.T) {
+		var events []StateEvent
+		err := testClient(server).StateEvents(context.Background(), "prod", func(event StateEvent) error {
+			events = append(events, event)
+			return nil
+		})
+
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, "version", events[0].Type)
+		assert.Equal(t, 2, events[0].Serial)
+		assert.Equal(t, "analyze", events[1].Analysis)
+	})
+
+	t.Run("stops when fn fails", func(t *testing.T) {
+		stop := errors.New("stop")
+		calls := 0
+		err := testClient(server).StateEvents(context.Background(), "prod", func(StateEvent) error {
+			calls++
+			return stop
+		})
+
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+	})
+}
@@ -0,0 +1,25 @@
+// Package client calls the Terrascope API from Go programs. A Client wraps
+// the HTTP endpoints in typed methods, retries the requests the server could
+// not serve and streams the events of stored states.
+package client
+
+import (
+	"github.com/terrascope/core/internal/models"
+	"github.com/terrascope/core/pkg/terrascope/model"
+)
+
+// The types of the API responses, shared with the server. Graphs, diffs and
+// findings are those of package model.
+type (
+	Graph            = model.Graph
+	Node             = model.Node
+	Edge             = model.Edge
+	StateDiff        = model.StateDiff
+	AnalysisReport   = models.AnalysisReport
+	Finding          = model.Finding
+	AnalyzerManifest = models.AnalyzerManifest
+	StateHistory     = models.StateHistory
+	StateVersion     = models.StateVersion
+	TrashEntry       = models.TrashEntry
+	StateEvent       = models.StateEvent
+)
@@ -0,0 +1,84 @@
+// Package terrascope embeds Terrascope in other Go programs: it parses
+// Terraform states, builds their dependency graphs, compares them, runs the
+// analyzers over them and renders the graphs, as the HTTP service does. It is
+// the stable API over the internal packages, which may change between
+// releases.
+package terrascope
+
+import (
+	"context"
+
+	"github.com/terrascope/core/internal/analysis"
+	"github.com/terrascope/core/pkg/terrascope/model"
+)
+
+// Analyzer is an analysis that reports findings about the nodes of a graph,
+// which it can correlate with the state the graph was built from. Analyzers
+// register under their name, by which SelectAnalyzers picks them. Analyze
+// should return early once the context is done, since the service gives up on
+// an analysis that runs past its timeout.
+type Analyzer interface {
+	Name() string
+	Description() string
+	Analyze(ctx context.Context, graph *Graph, state *State) []Finding
+}
+
+// Finding is an issue an Analyzer found about a node.
+type Finding = model.Finding
+
+// The severities of findings, from the least to the most severe.
+const (
+	SeverityInfo     = model.SeverityInfo
+	SeverityWarning  = model.SeverityWarning
+	SeverityError    = model.SeverityError
+	SeverityCritical = model.SeverityCritical
+)
+
+// RegisterAnalyzer makes an analyzer available to Analyze and
+// SelectAnalyzers by its name, replacing the analyzer registered under the
+// same name, including the built-in ones.
+func RegisterAnalyzer(analyzer Analyzer) {
+	analysis.Register(analyzer)
+}
+
+// Analyzers returns every registered analyzer ordered by name.
+func Analyzers() []Analyzer {
+	return public(analysis.Analyzers())
+}
+
+// SelectAnalyzers picks analyzers from a comma-separated list of names, such
+// as security,tags. Names prefixed with - are left out; an empty list selects
+// every analyzer.
+func SelectAnalyzers(list string) ([]Analyzer, error) {
+	selected, err := analysis.SelectAnalyzers(list)
+	if err != nil {
+		return nil, err
+	}
+
+	return public(selected), nil
+}
+
+// Analyze runs the analyzers over a state and its graph, every registered
+// analyzer when none is given, and returns their findings with stable IDs.
+func Analyze(graph *Graph, state *State, analyzers ...Analyzer) []Finding {
+	selected := make([]analysis.Analyzer, len(analyzers))
+	for i, analyzer := range analyzers {
+		selected[i] = analyzer
+	}
+
+	if len(selected) == 0 {
+		selected = analysis.Analyzers()
+	}
+
+	return analysis.RunAnalyzers(selected, graph, state)
+}
+
+// public returns the analyzers as the Analyzer of this package.
+func public(analyzers []analysis.Analyzer) []Analyzer {
+	converted := make([]Analyzer, len(analyzers))
+	for i, analyzer := range analyzers {
+		converted[i] = analyzer
+	}
+
+	return converted
+}
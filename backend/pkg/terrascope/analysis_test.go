@@ -0,0 +1,67 @@
+// Package terrascope embeds Terrascope in other Go programs: it parses
+// Terraform states, builds their dependency graphs, compares them, runs the
+// analyzers over them and renders the graphs, as the HTTP service does. It is
+// the stable API over the internal packages, which may change between
+// releases.
+package terrascope
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type vpcAnalyzer struct{}
+
+func (vpcAnalyzer) Name() string        { return "library-vpcs" }
+func (vpcAnalyzer) Description() string { return "Every VPC" }
+
+func (vpcAnalyzer) Analyze(_ context.Context, graph *Graph, _ *State) []Finding {
+	var findings []Finding
+	for _, node := range graph.Nodes {
+		if node.Type == "aws_vpc" {
+			findings = append(findings, Finding{NodeID: node.ID, Check: "vpc", Severity: SeverityInfo, Message: "is a VPC"})
+		}
+	}
+
+	return findings
+}
+
+func TestAnalyze(t *testing.T) {
+	state := parseTestState(t)
+	graph := BuildGraph(state, GraphOptions{})
+
+	t.Run("runs the selected analyzers", func(t *testing.T) {
+		selected, err := SelectAnalyzers("secrets")
+		require.NoError(t, err)
+
+		findings := Analyze(graph, state, selected...)
+
+		require.Len(t, findings, 1)
+		assert.Equal(t, "secrets", findings[0].Analyzer)
+		assert.NotEmpty(t, findings[0].ID)
+	})
+
+	t.Run("runs registered analyzers", func(t *testing.T) {
+		RegisterAnalyzer(vpcAnalyzer{})
+
+		names := []string{}
+		for _, analyzer := range Analyzers() {
+			names = append(names, analyzer.Name())
+		}
+		assert.Contains(t, names, "library-vpcs")
+
+		findings := Analyze(graph, state, vpcAnalyzer{})
+
+		require.Len(t, findings, 1)
+		assert.Equal(t, "aws_vpc.main", findings[0].NodeID)
+		assert.Equal(t, "library-vpcs", findings[0].Analyzer)
+	})
+
+	t.Run("unknown analyzer", func(t *testing.T) {
+		_, err := SelectAnalyzers("bogus")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,72 @@
+// Package model defines the documents Terrascope reads and produces: parsed
+// Terraform states, their dependency graphs, the differences between two
+// states and the findings of the analyzers. They are part of the public API
+// of package terrascope, and are shared with the internal packages.
+package model
+
+// StateDiff lists the resources added, removed, modified and renamed
+// between two states.
+type StateDiff struct {
+	Added    []string         `json:"added"`
+	Removed  []string         `json:"removed"`
+	Modified []ResourceChange `json:"modified"`
+	Renamed  []Rename         `json:"renamed"`
+}
+
+// ResourceChange names a resource and its attributes that changed.
+type ResourceChange struct {
+	ID                string   `json:"id"`
+	ChangedAttributes []string `json:"changed_attributes"`
+}
+
+// Rename pairs a removed resource with the added one it became, matched by
+// its cloud ID or by identical attributes.
+type Rename struct {
+	From              string   `json:"from"`
+	To                string   `json:"to"`
+	Type              string   `json:"type"`
+	MatchedBy         string   `json:"matched_by"`
+	ChangedAttributes []string `json:"changed_attributes,omitempty"`
+}
+
+// Changes of the nodes and edges of a DiffGraph.
+const (
+	ChangeAdded     = "added"
+	ChangeRemoved   = "removed"
+	ChangeModified  = "modified"
+	ChangeUnchanged = "unchanged"
+)
+
+// DiffGraph merges the dependency graphs of two states into one, in which
+// every node and edge carries its change between them.
+type DiffGraph struct {
+	Nodes []DiffNode `json:"nodes"`
+	Edges []DiffEdge `json:"edges"`
+}
+
+// DiffNode is a node of either state. Its Change shadows the plan change of
+// the embedded node, which diffs never set. Renamed nodes are modified and
+// keep their ID in the later state. Source names the state the node was
+// taken from: the earlier one for removed nodes, the later one otherwise.
+type DiffNode struct {
+	Node
+	Change            string     `json:"change"`
+	ChangedAttributes []string   `json:"changed_attributes,omitempty"`
+	RenamedFrom       string     `json:"renamed_from,omitempty"`
+	Source            NodeSource `json:"source"`
+}
+
+// NodeSource names the state a node of a merged graph comes from, with the
+// label the client gave it, such as its file name.
+type NodeSource struct {
+	Lineage string `json:"lineage"`
+	Serial  int    `json:"serial"`
+	Label   string `json:"label,omitempty"`
+}
+
+// DiffEdge is an edge of either state, between the IDs its nodes have in the
+// merged graph.
+type DiffEdge struct {
+	Edge
+	Change string `json:"change"`
+}
@@ -0,0 +1,30 @@
+// Package model defines the documents Terrascope reads and produces: parsed
+// Terraform states, their dependency graphs, the differences between two
+// states and the findings of the analyzers. They are part of the public API
+// of package terrascope, and are shared with the internal packages.
+package model
+
+// Finding severities, from the least to the most severe.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityError    = "error"
+	SeverityCritical = "critical"
+)
+
+// Finding is a problem an analyzer found with a node of the graph. ID stays
+// the same from one run to the next as long as the analyzer reports the same
+// problem with the same node. Check names the rule that failed, Code is its
+// stable code, listed by GET /codes, and Remediation tells how to fix the
+// node when the check knows.
+type Finding struct {
+	ID          string `json:"id"`
+	Analyzer    string `json:"analyzer"`
+	Check       string `json:"check"`
+	Code        string `json:"code,omitempty"`
+	Severity    string `json:"severity"`
+	NodeID      string `json:"node_id"`
+	Address     string `json:"address"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation,omitempty"`
+}
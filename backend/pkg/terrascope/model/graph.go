@@ -0,0 +1,183 @@
+// Package model defines the documents Terrascope reads and produces: parsed
+// Terraform states, their dependency graphs, the differences between two
+// states and the findings of the analyzers. They are part of the public API
+// of package terrascope, and are shared with the internal packages.
+package model
+
+import "time"
+
+// Graph is the dependency graph of a state: a node per resource instance
+// and an edge from every instance to each of its dependencies.
+type Graph struct {
+	Nodes      []Node                 `json:"nodes"`
+	Edges      []Edge                 `json:"edges"`
+	Addresses  map[string]NodeAddress `json:"addresses,omitempty"`
+	Stats      *Stats                 `json:"stats,omitempty"`
+	Summarized bool                   `json:"summarized,omitempty"`
+	Summary    *Summary               `json:"summary,omitempty"`
+	Reduction  *Reduction             `json:"reduction,omitempty"`
+	Lock       *LockInfo              `json:"lock,omitempty"`
+	Warnings   []Warning              `json:"warnings,omitempty"`
+}
+
+// Node is a resource instance of a Graph.
+type Node struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Mode     string `json:"mode"`
+	Provider string `json:"provider"`
+	// ProviderAddress is the full address of the provider configuration,
+	// namespace and alias included, where Provider is only its type.
+	ProviderAddress string            `json:"provider_address,omitempty"`
+	Module          string            `json:"module,omitempty"`
+	Status          string            `json:"status,omitempty"`
+	Change          *NodeChange       `json:"change,omitempty"`
+	Metadata        map[string]any    `json:"metadata,omitempty"`
+	Warnings        []Warning         `json:"warnings,omitempty"`
+	Presentation    *NodePresentation `json:"presentation,omitempty"`
+	Degrees         *NodeDegrees      `json:"degrees,omitempty"`
+}
+
+// Node statuses mark instances Terraform will replace: tainted instances and
+// deposed objects left behind by a create_before_destroy replacement.
+const (
+	NodeStatusTainted = "tainted"
+	NodeStatusDeposed = "deposed"
+)
+
+// NodePresentation tells clients how to display a node: a short label, the
+// tail of its cloud ID or ARN, and badges summarizing what the analyses of
+// the request found about it.
+type NodePresentation struct {
+	Label          string   `json:"label"`
+	SecondaryLabel string   `json:"secondary_label,omitempty"`
+	Badges         []string `json:"badges"`
+}
+
+// NodeDegrees counts the edges into and out of a node in the full graph, and
+// the nodes that depend on it directly or through other nodes.
+type NodeDegrees struct {
+	InDegree                  int `json:"in_degree"`
+	OutDegree                 int `json:"out_degree"`
+	TransitiveDependentsCount int `json:"transitive_dependents_count"`
+}
+
+// Node badges, in the order they are listed.
+const (
+	BadgeDrifted   = "drifted"
+	BadgeRisky     = "risky"
+	BadgeUntagged  = "untagged"
+	BadgeSensitive = "sensitive"
+)
+
+// NodeAddress cross-references a node with the resource instance it was built
+// from: its Terraform address and its jq path within the state document.
+type NodeAddress struct {
+	Terraform string `json:"terraform"`
+	StatePath string `json:"state_path"`
+}
+
+// Edge is a dependency between two nodes of a Graph, from the dependent to
+// its dependency.
+type Edge struct {
+	Source   string         `json:"source"`
+	Target   string         `json:"target"`
+	Type     string         `json:"type"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// Stats counts the nodes and edges of a Graph.
+type Stats struct {
+	TotalNodes       int                      `json:"total_nodes"`
+	TotalEdges       int                      `json:"total_edges"`
+	ResourcesByType  map[string]int           `json:"resources_by_type,omitempty"`
+	ResourcesByMode  map[string]int           `json:"resources_by_mode,omitempty"`
+	Providers        map[string]ProviderStats `json:"providers,omitempty"`
+	TopTypes         []TypeCount              `json:"top_types,omitempty"`
+	LargestModules   []ModuleCount            `json:"largest_modules,omitempty"`
+	TaintedInstances int                      `json:"tainted_instances"`
+	DeposedInstances int                      `json:"deposed_instances"`
+	Digest           string                   `json:"digest,omitempty"`
+}
+
+// ProviderStats counts the nodes of a provider and the edges leaving them.
+type ProviderStats struct {
+	Nodes int `json:"nodes"`
+	Edges int `json:"edges"`
+}
+
+// TypeCount counts the nodes of a resource type.
+type TypeCount struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+// ModuleCount counts the nodes of a module.
+type ModuleCount struct {
+	Module string `json:"module"`
+	Nodes  int    `json:"nodes"`
+}
+
+// Reduction tells how many edges a transitive reduction left out, as implied
+// by paths of at most Depth edges (any length when 0). Digest identifies the
+// full graph, with every edge, for subgraph requests.
+type Reduction struct {
+	By           string `json:"by"`
+	Depth        int    `json:"depth,omitempty"`
+	RemovedEdges int    `json:"removed_edges"`
+	Digest       string `json:"digest,omitempty"`
+}
+
+// Summary explains why a graph was aggregated and how large the full graph is.
+// Digest identifies the full graph for subgraph requests.
+type Summary struct {
+	By         string `json:"by"`
+	Digest     string `json:"digest,omitempty"`
+	TotalNodes int    `json:"total_nodes"`
+	TotalEdges int    `json:"total_edges"`
+	MaxNodes   int    `json:"max_nodes"`
+	Message    string `json:"message"`
+}
+
+// Warning is a problem found while building a graph or a report. Code is
+// the stable code of its Type, listed by GET /codes.
+type Warning struct {
+	Code    string `json:"code,omitempty"`
+	Type    string `json:"type"`
+	Address string `json:"address,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message"`
+}
+
+// LockInfo describes who holds the lock on a state, as recorded by Terraform
+// in its lock info file or lock table.
+type LockInfo struct {
+	ID        string    `json:"id"`
+	Operation string    `json:"operation,omitempty"`
+	Info      string    `json:"info,omitempty"`
+	Who       string    `json:"who"`
+	Version   string    `json:"version,omitempty"`
+	Created   time.Time `json:"created"`
+	Path      string    `json:"path,omitempty"`
+}
+
+// Pending actions of a node, as summarized from a plan's change actions.
+const (
+	ActionNoOp    = "no-op"
+	ActionCreate  = "create"
+	ActionRead    = "read"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionReplace = "replace"
+)
+
+// NodeChange is the pending change of a node in a plan overlay. Attribute
+// paths use Terraform's syntax, e.g. tags.env or ingress[0].cidr_blocks.
+type NodeChange struct {
+	Action              string   `json:"action"`
+	Actions             []string `json:"actions"`
+	ChangedPaths        []string `json:"changed_paths,omitempty"`
+	ReplacePaths        []string `json:"replace_paths,omitempty"`
+	CreateBeforeDestroy bool     `json:"create_before_destroy,omitempty"`
+	Reason              string   `json:"reason,omitempty"`
+}
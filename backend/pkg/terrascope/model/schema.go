@@ -0,0 +1,49 @@
+// Package model defines the documents Terrascope reads and produces: parsed
+// Terraform states, their dependency graphs, the differences between two
+// states and the findings of the analyzers. They are part of the public API
+// of package terrascope, and are shared with the internal packages.
+package model
+
+// ProviderSchemas is the output of `terraform providers schema -json`: the
+// schema of every resource type and data source of each provider, keyed by
+// provider source address.
+type ProviderSchemas struct {
+	FormatVersion   string                    `json:"format_version"`
+	ProviderSchemas map[string]ProviderSchema `json:"provider_schemas"`
+}
+
+// ProviderSchema holds the schemas of the resource types and data sources
+// of a provider.
+type ProviderSchema struct {
+	ResourceSchemas   map[string]Schema `json:"resource_schemas,omitempty"`
+	DataSourceSchemas map[string]Schema `json:"data_source_schemas,omitempty"`
+}
+
+// Schema is the schema of a resource type or data source.
+type Schema struct {
+	Version int         `json:"version"`
+	Block   SchemaBlock `json:"block"`
+}
+
+// SchemaBlock describes the attributes of a resource and its nested blocks.
+type SchemaBlock struct {
+	Attributes map[string]SchemaAttribute `json:"attributes,omitempty"`
+	BlockTypes map[string]SchemaBlockType `json:"block_types,omitempty"`
+}
+
+// SchemaAttribute types an attribute. Type is a Terraform type constraint in
+// its JSON form, e.g. "string" or ["list", "string"]. Attributes that are
+// computed but neither required nor optional are set by the provider.
+type SchemaAttribute struct {
+	Type      any  `json:"type,omitempty"`
+	Required  bool `json:"required,omitempty"`
+	Optional  bool `json:"optional,omitempty"`
+	Computed  bool `json:"computed,omitempty"`
+	Sensitive bool `json:"sensitive,omitempty"`
+}
+
+// SchemaBlockType describes a nested block and how it repeats.
+type SchemaBlockType struct {
+	NestingMode string      `json:"nesting_mode"`
+	Block       SchemaBlock `json:"block"`
+}
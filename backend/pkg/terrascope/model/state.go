@@ -0,0 +1,133 @@
+// Package model defines the documents Terrascope reads and produces: parsed
+// Terraform states, their dependency graphs, the differences between two
+// states and the findings of the analyzers. They are part of the public API
+// of package terrascope, and are shared with the internal packages.
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TerraformState is a parsed tfstate document of format version 4.
+type TerraformState struct {
+	Version          int               `json:"version"`
+	TerraformVersion string            `json:"terraform_version"`
+	Serial           int               `json:"serial"`
+	Lineage          string            `json:"lineage"`
+	Outputs          map[string]Output `json:"outputs,omitempty"`
+	Resources        []ResourceState   `json:"resources"`
+	CheckResults     []CheckResult     `json:"check_results,omitempty"`
+}
+
+// Output is a root module output value of a state.
+type Output struct {
+	Value     any  `json:"value"`
+	Type      any  `json:"type"`
+	Sensitive bool `json:"sensitive,omitempty"`
+}
+
+// ResourceState is a resource of a state, with its instances: one per
+// count or for_each key, or a single one.
+type ResourceState struct {
+	Mode      string             `json:"mode"`
+	Type      string             `json:"type"`
+	Name      string             `json:"name"`
+	Provider  string             `json:"provider"`
+	Module    string             `json:"module,omitempty"`
+	Instances []ResourceInstance `json:"instances"`
+	DependsOn []string           `json:"depends_on,omitempty"`
+}
+
+// ResourceInstance is an object of a resource, with its attributes as the
+// provider last reported them.
+type ResourceInstance struct {
+	SchemaVersion       int               `json:"schema_version"`
+	Attributes          map[string]any    `json:"attributes"`
+	AttributesFlat      map[string]string `json:"attributes_flat,omitempty"`
+	Private             string            `json:"private,omitempty"`
+	Dependencies        []string          `json:"dependencies,omitempty"`
+	IndexKey            any               `json:"index_key,omitempty"`
+	Status              string            `json:"status,omitempty"`
+	Deposed             string            `json:"deposed,omitempty"`
+	CreateBeforeDestroy bool              `json:"create_before_destroy,omitempty"`
+	SensitiveAttributes []AttributePath   `json:"sensitive_attributes,omitempty"`
+}
+
+// NodeStatus returns the status of the node built from the instance: deposed
+// for objects awaiting destruction after a create_before_destroy replacement,
+// tainted for instances marked for replacement, or empty.
+func (i ResourceInstance) NodeStatus() string {
+	switch {
+	case i.Deposed != "":
+		return NodeStatusDeposed
+	case i.Status == NodeStatusTainted:
+		return NodeStatusTainted
+	default:
+		return ""
+	}
+}
+
+// AttributePath addresses a value nested inside an instance's attributes, as
+// recorded by Terraform in sensitive_attributes.
+type AttributePath []PathStep
+
+// PathStep is a step of an AttributePath: an attribute name for get_attr
+// steps, or a list index or map key for index steps.
+type PathStep struct {
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+}
+
+// Key returns the attribute name or index key addressed by the step. Index
+// steps wrap their key in a typed value object ({"value": 0, "type": "number"}).
+func (s PathStep) Key() any {
+	if wrapped, ok := s.Value.(map[string]any); ok {
+		return wrapped["value"]
+	}
+
+	return s.Value
+}
+
+// String renders the path in Terraform's attribute syntax, e.g. tags["owner"]
+// or ingress[0].cidr_blocks.
+func (p AttributePath) String() string {
+	var b strings.Builder
+
+	for _, step := range p {
+		key := step.Key()
+
+		if step.Type == "get_attr" {
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			fmt.Fprint(&b, key)
+			continue
+		}
+
+		if s, ok := key.(string); ok {
+			fmt.Fprintf(&b, "[%q]", s)
+		} else {
+			fmt.Fprintf(&b, "[%v]", key)
+		}
+	}
+
+	return b.String()
+}
+
+// CheckResult is the status of a check block, precondition or postcondition
+// of the configuration, as of the run that wrote the state.
+type CheckResult struct {
+	ObjectKind string        `json:"object_kind"`
+	ConfigAddr string        `json:"config_addr"`
+	Status     string        `json:"status"`
+	Objects    []CheckObject `json:"objects,omitempty"`
+}
+
+// CheckObject is the status of a checked object, with the messages of its
+// failed conditions.
+type CheckObject struct {
+	ObjectAddr      string   `json:"object_addr"`
+	Status          string   `json:"status"`
+	FailureMessages []string `json:"failure_messages,omitempty"`
+}
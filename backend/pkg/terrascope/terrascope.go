@@ -0,0 +1,123 @@
+// Package terrascope embeds Terrascope in other Go programs: it parses
+// Terraform states, builds their dependency graphs, compares them, runs the
+// analyzers over them and renders the graphs, as the HTTP service does. It is
+// the stable API over the internal packages, which may change between
+// releases.
+package terrascope
+
+import (
+	"github.com/terrascope/core/internal/diff"
+	"github.com/terrascope/core/internal/parser"
+	"github.com/terrascope/core/internal/render"
+	"github.com/terrascope/core/pkg/terrascope/model"
+)
+
+// The documents the functions below take and return are defined in package
+// model, along with the types they nest, such as ResourceState and Stats.
+
+// State is a parsed tfstate document.
+type State = model.TerraformState
+
+// Graph is the dependency graph of a state: a node per resource instance and
+// an edge from every instance to each of its dependencies.
+type Graph = model.Graph
+
+// Node is a resource instance of a Graph.
+type Node = model.Node
+
+// Edge is a dependency between two nodes of a Graph, from the dependent to
+// its dependency.
+type Edge = model.Edge
+
+// StateDiff lists the resources added, removed, modified and renamed between
+// two states.
+type StateDiff = model.StateDiff
+
+// DiffGraph is the merged graph of two states, with the change of every node
+// and edge.
+type DiffGraph = model.DiffGraph
+
+// IDFormat selects how graph nodes are addressed.
+type IDFormat string
+
+const (
+	// IDFormatLegacy is the original addressing: data sources carry no data.
+	// prefix and instance keys are only added when a resource has several
+	// instances, unquoted.
+	IDFormatLegacy IDFormat = "legacy"
+	// IDFormatTerraform addresses nodes exactly as Terraform prints instance
+	// addresses, so IDs can be pasted into terraform state commands.
+	IDFormatTerraform IDFormat = "terraform"
+)
+
+// GraphOptions controls how BuildGraph addresses nodes and which edges it
+// adds. The zero value builds graphs with legacy node IDs.
+type GraphOptions struct {
+	IDFormat IDFormat
+	// InstanceEdges points dependency edges at every instance of the target
+	// resource instead of at the resource address. It has no effect when
+	// FoldInstances is set.
+	InstanceEdges bool
+	// FoldInstances builds one node per resource, addressed without an
+	// instance key, with the metadata of each instance in an instances list.
+	FoldInstances bool
+	// Schemas, when set, types the attributes of each resource by its
+	// provider schema: attributes the schema marks sensitive are redacted
+	// from node metadata, and configurable attributes that hold the ID or ARN
+	// of another resource become reference edges.
+	Schemas *model.ProviderSchemas
+	// InferReferences adds reference edges for the resources without a
+	// schema, from the attribute values that have a known provider ID format
+	// and are the ID or ARN of another resource.
+	InferReferences bool
+}
+
+// ParseState parses a tfstate document of format version 4.
+func ParseState(data []byte) (*State, error) {
+	return parser.ParseTfstate(data)
+}
+
+// BuildGraph builds the dependency graph of a state, with its statistics.
+func BuildGraph(state *State, opts GraphOptions) *Graph {
+	graph := parser.BuildGraphWithOptions(state, parser.GraphOptions{
+		IDFormat:        parser.IDFormat(opts.IDFormat),
+		InstanceEdges:   opts.InstanceEdges,
+		FoldInstances:   opts.FoldInstances,
+		Schemas:         opts.Schemas,
+		InferReferences: opts.InferReferences,
+	})
+	graph.Stats = parser.ComputeStats(graph)
+
+	return graph
+}
+
+// Diff compares two states by resource.
+func Diff(before, after *State) *StateDiff {
+	return diff.States(before, after)
+}
+
+// DiffGraphs merges the graphs of two states.
+func DiffGraphs(before, after *State) *DiffGraph {
+	return diff.Graph(before, after)
+}
+
+// Formats returns the names of the formats Render supports, sorted.
+func Formats() []string {
+	return render.FormatNames()
+}
+
+// Render renders a graph in the named format, such as dot or svg, and
+// returns it with its content type.
+func Render(graph *Graph, format string) ([]byte, string, error) {
+	renderer, err := render.Lookup(format)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, err := renderer.Render(graph)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, renderer.ContentType, nil
+}
@@ -1,5 +1,6 @@
 import { BrowserRouter, Routes, Route } from 'react-router-dom';
 
+import HistoryBrowser from './pages/History';
 import Landing from './pages/Landing';
 import Visualization from './pages/Visualization';
 
@@ -9,6 +10,7 @@ export default function App() {
             <Routes>
                 <Route path="/" element={<Landing />} />
                 <Route path="/visualize" element={<Visualization />} />
+                <Route path="/history" element={<HistoryBrowser />} />
             </Routes>
         </BrowserRouter>
     );
@@ -319,6 +319,11 @@ export default function ConstellationView({
     );
 }
 
+const STATUS_COLORS: Record<string, number> = {
+    tainted: 0xf97316,
+    deposed: 0xef4444,
+};
+
 function createNodeObject(
     node: Node,
     index: number,
@@ -348,11 +353,13 @@ function createNodeObject(
     mesh.position.set(x, y, z);
     mesh.userData = { nodeId: node.id };
 
+    // Tainted and deposed instances glow in a warning color instead.
+    const statusColor = node.status ? STATUS_COLORS[node.status] : undefined;
     const glowGeometry = new THREE.SphereGeometry(size * 1.3, 16, 16);
     const glowMaterial = new THREE.MeshBasicMaterial({
-        color,
+        color: statusColor ?? color,
         transparent: true,
-        opacity: 0.2,
+        opacity: statusColor ? 0.5 : 0.2,
     });
     const glow = new THREE.Mesh(glowGeometry, glowMaterial);
     mesh.add(glow);
@@ -46,6 +46,19 @@ export default function ResourcePanel({ node, onClose }: ResourcePanelProps) {
                                 </span>
                             </span>
                         </div>
+                        {node.status && (
+                            <div className="flex justify-between items-start">
+                                <span className="text-sm text-gray-400">Status</span>
+                                <span
+                                    className={`inline-block px-2 py-1 rounded text-xs font-medium ${node.status === 'tainted'
+                                        ? 'bg-orange-500/20 text-orange-400'
+                                        : 'bg-red-500/20 text-red-400'
+                                        }`}
+                                >
+                                    {node.status}
+                                </span>
+                            </div>
+                        )}
                         {node.module && (
                             <div className="flex justify-between items-start">
                                 <span className="text-sm text-gray-400">Module</span>
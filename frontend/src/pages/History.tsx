@@ -0,0 +1,149 @@
+import { useEffect, useState } from 'react';
+import { useNavigate } from 'react-router-dom';
+import { AlertTriangle, ArrowLeft, History, Eye } from 'lucide-react';
+import { StateHistory, StateVersion } from '../types/api';
+import { followStateEvents, listStates, listStateVersions, parseStoredState } from '../services/api';
+
+export default function HistoryBrowser() {
+    const navigate = useNavigate();
+    const [states, setStates] = useState<StateHistory[]>([]);
+    const [selectedLineage, setSelectedLineage] = useState<string | null>(null);
+    const [versions, setVersions] = useState<StateVersion[]>([]);
+    const [error, setError] = useState<string | null>(null);
+    const [loading, setLoading] = useState(true);
+
+    useEffect(() => {
+        listStates()
+            .then(setStates)
+            .catch((err: Error) => setError(err.message))
+            .finally(() => setLoading(false));
+    }, []);
+
+    // Versions stored while a lineage is selected show up without reloading.
+    useEffect(() => {
+        if (!selectedLineage) return;
+
+        return followStateEvents(selectedLineage, (event) => {
+            if (event.type !== 'version') return;
+
+            listStateVersions(event.lineage).then(setVersions).catch(() => {});
+            listStates().then(setStates).catch(() => {});
+        });
+    }, [selectedLineage]);
+
+    const handleSelectLineage = async (lineage: string) => {
+        setSelectedLineage(lineage);
+        setError(null);
+
+        try {
+            setVersions(await listStateVersions(lineage));
+        } catch (err) {
+            setError(err instanceof Error ? err.message : 'Failed to list state versions');
+        }
+    };
+
+    const handleVisualize = async (version: StateVersion) => {
+        setError(null);
+
+        try {
+            const graph = await parseStoredState(version.lineage, version.serial);
+            sessionStorage.setItem('terraformGraph', JSON.stringify(graph));
+            sessionStorage.setItem('fileName', `${version.lineage} #${version.serial}`);
+            navigate('/visualize');
+        } catch (err) {
+            setError(err instanceof Error ? err.message : 'Failed to parse state');
+        }
+    };
+
+    return (
+        <div className="min-h-screen bg-black text-white">
+            <header className="fixed top-0 left-0 right-0 z-50 bg-black/80 backdrop-blur-md border-b border-gray-800">
+                <div className="max-w-full px-6 py-4 flex items-center space-x-4">
+                    <button
+                        onClick={() => navigate('/')}
+                        className="flex items-center space-x-2 text-gray-400 hover:text-white transition-colors"
+                    >
+                        <ArrowLeft className="w-5 h-5" />
+                        <span>Back</span>
+                    </button>
+                    <div className="h-6 w-px bg-gray-700" />
+                    <div className="flex items-center space-x-2">
+                        <History className="w-5 h-5 text-violet-500" />
+                        <span className="font-semibold">State history</span>
+                    </div>
+                </div>
+            </header>
+
+            <main className="pt-24 px-6 max-w-6xl mx-auto grid grid-cols-1 md:grid-cols-2 gap-6">
+                {error && (
+                    <div className="md:col-span-2 p-4 bg-red-500/10 border border-red-500/30 rounded-lg text-red-400 text-sm">
+                        {error}
+                    </div>
+                )}
+
+                <section className="bg-gray-900/50 border border-gray-800 rounded-lg p-4">
+                    <h2 className="text-lg font-semibold mb-4">Lineages</h2>
+                    {loading && <p className="text-gray-400 text-sm">Loading...</p>}
+                    {!loading && states.length === 0 && (
+                        <p className="text-gray-400 text-sm">No stored states yet.</p>
+                    )}
+                    <ul className="space-y-2">
+                        {states.map((state) => (
+                            <li key={state.lineage}>
+                                <button
+                                    onClick={() => handleSelectLineage(state.lineage)}
+                                    className={`w-full text-left px-3 py-2 rounded-lg transition-colors ${
+                                        state.lineage === selectedLineage
+                                            ? 'bg-violet-500/20 text-white'
+                                            : 'text-gray-300 hover:bg-gray-800'
+                                    }`}
+                                >
+                                    <div className="font-mono text-sm truncate">{state.lineage}</div>
+                                    <div className="text-xs text-gray-500">
+                                        {state.versions} versions, latest serial {state.latest.serial}
+                                    </div>
+                                </button>
+                            </li>
+                        ))}
+                    </ul>
+                </section>
+
+                <section className="bg-gray-900/50 border border-gray-800 rounded-lg p-4">
+                    <h2 className="text-lg font-semibold mb-4">Versions</h2>
+                    {!selectedLineage && (
+                        <p className="text-gray-400 text-sm">Select a lineage to browse its versions.</p>
+                    )}
+                    <ul className="space-y-2">
+                        {versions.map((version) => (
+                            <li
+                                key={version.serial}
+                                className="flex items-center justify-between px-3 py-2 rounded-lg bg-gray-800/50"
+                            >
+                                <div>
+                                    <div className="text-sm">Serial {version.serial}</div>
+                                    <div className="text-xs text-gray-500">
+                                        {version.resources} resources, Terraform {version.terraform_version},{' '}
+                                        {new Date(version.stored_at).toLocaleString()}
+                                    </div>
+                                    {version.anomalies?.map((anomaly) => (
+                                        <div key={anomaly.condition} className="flex items-center space-x-1 text-xs text-amber-400">
+                                            <AlertTriangle className="w-3 h-3" />
+                                            <span>{anomaly.message}</span>
+                                        </div>
+                                    ))}
+                                </div>
+                                <button
+                                    onClick={() => handleVisualize(version)}
+                                    className="flex items-center space-x-1 text-violet-400 hover:text-violet-300 text-sm"
+                                >
+                                    <Eye className="w-4 h-4" />
+                                    <span>View</span>
+                                </button>
+                            </li>
+                        ))}
+                    </ul>
+                </section>
+            </main>
+        </div>
+    );
+}
@@ -1,7 +1,7 @@
 import React, { useEffect, useRef, useState } from 'react';
 import { Upload, FileJson, Network, Eye, Sparkles, Server, Lock, Zap } from 'lucide-react';
 
-import { parseTerraformState } from '../services/api';
+import { demoStateURL, parseTerraformState } from '../services/api';
 
 const ParticleBackground = () => {
     const canvasRef = useRef<HTMLCanvasElement>(null);
@@ -105,6 +105,9 @@ const Navigation = () => {
                     <a href="#docs" className="text-gray-300 hover:text-white transition-colors">
                         Docs
                     </a>
+                    <a href="/history" className="text-gray-300 hover:text-white transition-colors">
+                        History
+                    </a>
                 </div>
 
                 <div className="flex justify-end items-center">
@@ -312,7 +315,7 @@ const FileUploadZone = () => {
             <div className="mt-6 text-center">
                 <p className="text-sm text-gray-500">
                     Need a sample file?{' '}
-                    <a href="#" className="text-violet-400 hover:text-violet-300 underline">
+                    <a href={demoStateURL()} download="terraform.tfstate" className="text-violet-400 hover:text-violet-300 underline">
                         Download example terraform.tfstate
                     </a>
                 </p>
@@ -1,5 +1,5 @@
 import { describe, it, expect, beforeEach, vi } from 'vitest';
-import { checkHealth, parseTerraformState, ApiError } from './api';
+import { checkHealth, demoStateURL, followStateEvents, parseTerraformState, ApiError, CHUNKED_UPLOAD_THRESHOLD } from './api';
 import { Graph } from '../types/api';
 
 class MockFile {
@@ -34,7 +34,7 @@ describe('API Service', () => {
 
             const result = await checkHealth();
             expect(result).toEqual(mockResponse);
-            expect(fetch).toHaveBeenCalledWith('http://localhost:8080/health');
+            expect(fetch).toHaveBeenCalledWith('http://localhost:8080/api/v1/health');
         });
 
         it('should throw ApiError on failed request', async () => {
@@ -130,6 +130,32 @@ describe('API Service', () => {
                 'Invalid response: missing nodes array'
             );
         });
+
+        it('should upload large states in parts', async () => {
+            const content = JSON.stringify(validTfstate);
+            const file = new MockFile(content, 'terraform.tfstate', 'application/json');
+            Object.defineProperty(file, 'size', { value: CHUNKED_UPLOAD_THRESHOLD + 1 });
+
+            globalThis.fetch = vi.fn()
+                .mockResolvedValueOnce({
+                    ok: true,
+                    json: async () => ({ id: 'abc', target: 'parse', max_part_size: 32, parts: [] }),
+                })
+                .mockResolvedValueOnce({ ok: false, status: 502, text: async () => 'Bad gateway' })
+                .mockResolvedValue({ ok: true, json: async () => mockGraph });
+
+            const result = await parseTerraformState(file as unknown as File);
+            expect(result).toEqual(mockGraph);
+
+            const urls = vi.mocked(fetch).mock.calls.map(([url]) => url);
+            expect(urls).toEqual([
+                'http://localhost:8080/api/v1/uploads',
+                'http://localhost:8080/api/v1/uploads/abc/parts/1',
+                'http://localhost:8080/api/v1/uploads/abc/parts/1',
+                'http://localhost:8080/api/v1/uploads/abc/parts/2',
+                'http://localhost:8080/api/v1/uploads/abc/complete',
+            ]);
+        });
     });
 
     describe('ApiError', () => {
@@ -146,4 +172,40 @@ describe('API Service', () => {
             expect(error.response).toEqual(response);
         });
     });
+
+    describe('followStateEvents', () => {
+        it('should pass the events of the lineage until closed', () => {
+            const listeners: Record<string, (message: { data: string }) => void> = {};
+            const close = vi.fn();
+            const EventSourceMock = vi.fn().mockImplementation(() => ({
+                addEventListener: (type: string, listener: (message: { data: string }) => void) => {
+                    listeners[type] = listener;
+                },
+                close,
+            }));
+            vi.stubGlobal('EventSource', EventSourceMock);
+
+            const onEvent = vi.fn();
+            const stop = followStateEvents('a/b', onEvent);
+
+            expect(EventSourceMock).toHaveBeenCalledWith('http://localhost:8080/api/v1/states/a%2Fb/events', {
+                withCredentials: true,
+            });
+
+            const event = { type: 'version', lineage: 'a/b', serial: 2, at: '2024-01-01T00:00:00Z' };
+            listeners.version({ data: JSON.stringify(event) });
+            expect(onEvent).toHaveBeenCalledWith(event);
+
+            stop();
+            expect(close).toHaveBeenCalled();
+
+            vi.unstubAllGlobals();
+        });
+    });
+
+    describe('demoStateURL', () => {
+        it('should point at the demo state generator', () => {
+            expect(demoStateURL(500, 10)).toBe('http://localhost:8080/api/v1/demo/state?resources=500&modules=10');
+        });
+    });
 });
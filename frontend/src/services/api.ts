@@ -1,6 +1,21 @@
-import { type Graph, type HealthResponse } from '../types/api';
+import {
+    type Graph,
+    type HealthResponse,
+    type StateEvent,
+    type StateHistory,
+    type StateVersion,
+    type Upload,
+} from '../types/api';
 
-const API_BASE_URL = import.meta.env.VITE_API_URL || 'http://localhost:8080';
+// An empty VITE_API_URL targets the serving origin, as in the single-container build.
+const API_BASE_URL = `${import.meta.env.VITE_API_URL ?? 'http://localhost:8080'}/api/v1`;
+
+// csrfHeaders echoes the CSRF cookie set by the API, which it requires on
+// mutating requests that carry cookies.
+function csrfHeaders(): Record<string, string> {
+    const match = document.cookie.match(/(?:^|;\s*)terrascope_csrf=([^;]+)/);
+    return match ? { 'X-CSRF-Token': match[1] } : {};
+}
 
 export class ApiError extends Error {
     constructor(
@@ -27,6 +42,76 @@ export async function checkHealth(): Promise<HealthResponse> {
     }
 }
 
+// States above this size are sent in parts through the chunked upload API,
+// because proxies tend to cut long single-request uploads.
+export const CHUNKED_UPLOAD_THRESHOLD = 32 * 1024 * 1024;
+
+// Attempts per part before a chunked upload gives up.
+const PART_ATTEMPTS = 3;
+
+// uploadInParts sends the document in parts and completes the upload, which
+// the API answers as the target endpoint would have answered the document.
+async function uploadInParts(document: Blob, target: string): Promise<Response> {
+    const response = await fetch(`${API_BASE_URL}/uploads`, {
+        method: 'POST',
+        headers: {
+            'Content-Type': 'application/json',
+            ...csrfHeaders(),
+        },
+        body: JSON.stringify({ target, size: document.size }),
+    });
+
+    if (!response.ok) {
+        const errorText = await response.text();
+        throw new ApiError(`Failed to start upload: ${errorText}`, response.status, errorText);
+    }
+
+    const upload: Upload = await response.json();
+
+    for (let part = 1, offset = 0; offset < document.size; part++, offset += upload.max_part_size) {
+        await uploadPart(upload.id, part, document.slice(offset, offset + upload.max_part_size));
+    }
+
+    return fetch(`${API_BASE_URL}/uploads/${upload.id}/complete`, {
+        method: 'POST',
+        headers: csrfHeaders(),
+    });
+}
+
+async function uploadPart(id: string, part: number, data: Blob): Promise<void> {
+    let lastError: unknown;
+
+    for (let attempt = 0; attempt < PART_ATTEMPTS; attempt++) {
+        try {
+            const response = await fetch(`${API_BASE_URL}/uploads/${id}/parts/${part}`, {
+                method: 'PUT',
+                headers: {
+                    'Content-Type': 'application/octet-stream',
+                    ...csrfHeaders(),
+                },
+                body: data,
+            });
+
+            if (response.ok) {
+                return;
+            }
+
+            const errorText = await response.text();
+            lastError = new ApiError(`Failed to upload part ${part}: ${errorText}`, response.status, errorText);
+            if (response.status < 500) {
+                break;
+            }
+        } catch (error) {
+            lastError = error;
+        }
+    }
+
+    if (lastError instanceof ApiError) {
+        throw lastError;
+    }
+    throw new ApiError(`Failed to upload part ${part}`, undefined, lastError);
+}
+
 export async function parseTerraformState(file: File): Promise<Graph> {
     try {
         const fileContent = await file.text();
@@ -36,13 +121,16 @@ export async function parseTerraformState(file: File): Promise<Graph> {
             throw new Error('Invalid JSON file');
         }
 
-        const response = await fetch(`${API_BASE_URL}/parse`, {
-            method: 'POST',
-            headers: {
-                'Content-Type': 'application/json',
-            },
-            body: fileContent,
-        });
+        const response = file.size > CHUNKED_UPLOAD_THRESHOLD
+            ? await uploadInParts(new Blob([fileContent]), 'parse')
+            : await fetch(`${API_BASE_URL}/parse`, {
+                method: 'POST',
+                headers: {
+                    'Content-Type': 'application/json',
+                    ...csrfHeaders(),
+                },
+                body: fileContent,
+            });
 
         if (!response.ok) {
             const errorText = await response.text();
@@ -84,6 +172,7 @@ export async function parseTerraformStatePretty(file: File): Promise<Graph> {
         method: 'POST',
         headers: {
             'Content-Type': 'application/json',
+            ...csrfHeaders(),
         },
         body: fileContent,
     });
@@ -95,3 +184,69 @@ export async function parseTerraformStatePretty(file: File): Promise<Graph> {
 
     return await response.json();
 }
+
+// demoStateURL points at a synthetic state generated by the API, for trying
+// the visualization without real infrastructure data.
+export function demoStateURL(resources = 60, modules = 4): string {
+    return `${API_BASE_URL}/demo/state?resources=${resources}&modules=${modules}`;
+}
+
+async function getJSON<T>(path: string, failure: string): Promise<T> {
+    const response = await fetch(`${API_BASE_URL}${path}`);
+    if (!response.ok) {
+        const errorText = await response.text();
+        throw new ApiError(`${failure}: ${errorText}`, response.status, errorText);
+    }
+
+    return await response.json();
+}
+
+export async function listStates(): Promise<StateHistory[]> {
+    return getJSON<StateHistory[]>('/states', 'Failed to list states');
+}
+
+export async function listStateVersions(lineage: string): Promise<StateVersion[]> {
+    return getJSON<StateVersion[]>(
+        `/states/${encodeURIComponent(lineage)}`,
+        'Failed to list state versions'
+    );
+}
+
+// followStateEvents calls onEvent with the events of the lineage until the
+// returned function is called. The browser reconnects dropped streams.
+export function followStateEvents(lineage: string, onEvent: (event: StateEvent) => void): () => void {
+    const source = new EventSource(`${API_BASE_URL}/states/${encodeURIComponent(lineage)}/events`, {
+        withCredentials: true,
+    });
+
+    const listener = (message: MessageEvent<string>) => onEvent(JSON.parse(message.data));
+    source.addEventListener('version', listener);
+    source.addEventListener('analysis', listener);
+
+    return () => source.close();
+}
+
+export async function parseStoredState(lineage: string, serial: number): Promise<Graph> {
+    const stateResponse = await fetch(
+        `${API_BASE_URL}/states/${encodeURIComponent(lineage)}/${serial}`
+    );
+    if (!stateResponse.ok) {
+        throw new ApiError('Failed to load state', stateResponse.status);
+    }
+
+    const response = await fetch(`${API_BASE_URL}/parse`, {
+        method: 'POST',
+        headers: {
+            'Content-Type': 'application/json',
+            ...csrfHeaders(),
+        },
+        body: await stateResponse.text(),
+    });
+
+    if (!response.ok) {
+        const errorText = await response.text();
+        throw new ApiError(`Failed to parse state: ${errorText}`, response.status, errorText);
+    }
+
+    return await response.json();
+}
@@ -9,8 +9,34 @@ export interface Node {
     type: string;
     mode: string;
     provider: string;
+    provider_address?: string;
     module?: string;
+    status?: 'tainted' | 'deposed';
+    change?: NodeChange;
     metadata?: Record<string, unknown>;
+    presentation?: NodePresentation;
+    degrees?: NodeDegrees;
+}
+
+export interface NodeDegrees {
+    in_degree: number;
+    out_degree: number;
+    transitive_dependents_count: number;
+}
+
+export interface NodePresentation {
+    label: string;
+    secondary_label?: string;
+    badges: Array<'drifted' | 'risky' | 'untagged' | 'sensitive'>;
+}
+
+export interface NodeChange {
+    action: string;
+    actions: string[];
+    changed_paths?: string[];
+    replace_paths?: string[];
+    create_before_destroy?: boolean;
+    reason?: string;
 }
 
 export interface Edge {
@@ -24,6 +50,11 @@ export interface Stats {
     total_edges: number;
     resources_by_type?: Record<string, number>;
     resources_by_mode?: Record<string, number>;
+    providers?: Record<string, { nodes: number; edges: number }>;
+    top_types?: { type: string; count: number }[];
+    largest_modules?: { module: string; nodes: number }[];
+    tainted_instances?: number;
+    deposed_instances?: number;
 }
 
 export interface HealthResponse {
@@ -33,3 +64,53 @@ export interface HealthResponse {
     uptime?: string;
     details?: Record<string, string>;
 }
+
+export interface StateVersion {
+    lineage: string;
+    serial: number;
+    terraform_version: string;
+    resources: number;
+    digest: string;
+    size: number;
+    stored_at: string;
+    project?: string;
+    anomalies?: StateAnomaly[];
+}
+
+// StateAnomaly is a suspicious change from the previous version flagged when
+// the version was stored, such as a mass deletion.
+export interface StateAnomaly {
+    condition: 'mass_deletion' | 'provider_swap' | 'serial_jump' | 'lineage_change';
+    severity: string;
+    message: string;
+    previous_serial: number;
+    resources?: string[];
+}
+
+// StateEvent is streamed by /states/{lineage}/events when a version of the
+// lineage is stored or an analysis of one of its states completes.
+export interface StateEvent {
+    type: 'version' | 'analysis';
+    lineage: string;
+    serial: number;
+    analysis?: string;
+    at: string;
+}
+
+export interface StateHistory {
+    lineage: string;
+    versions: number;
+    latest: StateVersion;
+}
+
+export interface Upload {
+    id: string;
+    target: string;
+    size?: number;
+    sha256?: string;
+    max_part_size: number;
+    parts: number[];
+    received: number;
+    created_at: string;
+    expires_at: string;
+}
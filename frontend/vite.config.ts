@@ -32,8 +32,7 @@ export default defineConfig({
         proxy: {
             '/api': {
                 target: 'http://backend:8080',
-                changeOrigin: true,
-                rewrite: (path) => path.replace(/^\/api/, '')
+                changeOrigin: true
             }
         }
     },